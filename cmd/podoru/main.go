@@ -34,15 +34,31 @@ import (
 	"github.com/podoru/spinner-podoru/internal/adapter/http/handler"
 	"github.com/podoru/spinner-podoru/internal/adapter/http/middleware"
 	"github.com/podoru/spinner-podoru/internal/adapter/repository/postgres"
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/domain/repository"
+	domainSecret "github.com/podoru/spinner-podoru/internal/domain/secret"
 	"github.com/podoru/spinner-podoru/internal/infrastructure/config"
 	"github.com/podoru/spinner-podoru/internal/infrastructure/database"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/dns"
 	"github.com/podoru/spinner-podoru/internal/infrastructure/docker"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/git"
 	"github.com/podoru/spinner-podoru/internal/infrastructure/logger"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/oidc"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/secret"
+	"github.com/podoru/spinner-podoru/internal/usecase/apitoken"
 	"github.com/podoru/spinner-podoru/internal/usecase/auth"
+	"github.com/podoru/spinner-podoru/internal/usecase/certificate"
 	"github.com/podoru/spinner-podoru/internal/usecase/deployment"
+	"github.com/podoru/spinner-podoru/internal/usecase/deploytoken"
+	"github.com/podoru/spinner-podoru/internal/usecase/envgroup"
+	"github.com/podoru/spinner-podoru/internal/usecase/mfa"
+	"github.com/podoru/spinner-podoru/internal/usecase/network"
 	"github.com/podoru/spinner-podoru/internal/usecase/project"
 	"github.com/podoru/spinner-podoru/internal/usecase/service"
+	"github.com/podoru/spinner-podoru/internal/usecase/session"
+	"github.com/podoru/spinner-podoru/internal/usecase/sso"
 	"github.com/podoru/spinner-podoru/internal/usecase/team"
+	"github.com/podoru/spinner-podoru/internal/usecase/traefik"
 	"github.com/podoru/spinner-podoru/internal/usecase/user"
 	"github.com/podoru/spinner-podoru/pkg/crypto"
 	"github.com/podoru/spinner-podoru/pkg/validator"
@@ -93,11 +109,18 @@ func main() {
 	}
 	log.Info("Database migrations completed")
 
-	encryptor, err := crypto.NewEncryptor(cfg.Encryption.Key)
+	encryptor, err := crypto.NewEncryptor(cfg.Encryption.Key, cfg.Encryption.PreviousKeys...)
 	if err != nil {
 		log.Fatalf("Failed to create encryptor: %v", err)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "secrets" {
+		if err := runSecretsCommand(ctx, os.Args[2:], db, encryptor, log); err != nil {
+			log.Fatalf("%v", err)
+		}
+		return
+	}
+
 	v, err := validator.New()
 	if err != nil {
 		log.Fatalf("Failed to create validator: %v", err)
@@ -117,40 +140,149 @@ func main() {
 	}
 
 	containerManager := docker.NewContainerManager(dockerClient)
+	cloner := git.NewCloner()
+	resolver := dns.NewResolver(cfg.Domains.DNSResolver)
 
 	userRepo := postgres.NewUserRepository(db.Pool)
 	refreshTokenRepo := postgres.NewRefreshTokenRepository(db.Pool)
-	teamRepo := postgres.NewTeamRepository(db.Pool)
-	teamMemberRepo := postgres.NewTeamMemberRepository(db.Pool)
-	projectRepo := postgres.NewProjectRepository(db.Pool)
+	var teamRepo repository.TeamRepository = postgres.NewTeamRepository(db.Pool)
+	var teamMemberRepo repository.TeamMemberRepository = postgres.NewTeamMemberRepository(db.Pool)
+	var projectRepo repository.ProjectRepository = postgres.NewProjectRepository(db.Pool)
 	serviceRepo := postgres.NewServiceRepository(db.Pool)
 	deploymentRepo := postgres.NewDeploymentRepository(db.Pool)
 	domainRepo := postgres.NewDomainRepository(db.Pool)
+	portRepo := postgres.NewPortMappingRepository(db.Pool)
+	networkRepo := postgres.NewNetworkRepository(db.Pool)
+	certificateRepo := postgres.NewCertificateRepository(db.Pool)
+	envGroupRepo := postgres.NewEnvGroupRepository(db.Pool)
+	envVarRepo := postgres.NewServiceEnvVarRepository(db.Pool)
+
+	apiTokenRepo := postgres.NewAPITokenRepository(db.Pool)
+	deployTokenRepo := postgres.NewDeployTokenRepository(db.Pool)
+
+	mfaUseCase := mfa.NewUseCase(postgres.NewMFARepository(db.Pool), postgres.NewMFAChallengeRepository(db.Pool), postgres.NewMFAPolicyRepository(db.Pool), userRepo, teamRepo, encryptor, cfg.App.Name)
+	authUseCase := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, &cfg.JWT, &cfg.App, mfaUseCase)
+	apiTokenUseCase := apitoken.NewUseCase(apiTokenRepo, userRepo, projectRepo, teamMemberRepo)
+
+	var oidcHandler *handler.OIDCHandler
+	if cfg.OIDC.Enabled {
+		mappings := make([]entity.OIDCGroupMapping, 0, len(cfg.OIDC.GroupMappings))
+		for _, m := range cfg.OIDC.GroupMappings {
+			mapping, err := entity.ParseOIDCGroupMapping(m)
+			if err != nil {
+				log.Fatalf("Invalid OIDC_GROUP_MAPPINGS: %v", err)
+			}
+			mappings = append(mappings, mapping)
+		}
+		ssoUseCase := sso.NewUseCase(oidc.NewProvider(&cfg.OIDC), postgres.NewOIDCStateRepository(db.Pool), userRepo, teamRepo, teamMemberRepo, authUseCase, mappings)
+		oidcHandler = handler.NewOIDCHandler(ssoUseCase, cfg.OIDC.FrontendURL)
+		log.Infof("Single sign-on enabled with %s", cfg.OIDC.IssuerURL)
+	} else if cfg.App.PasswordLoginDisabled {
+		log.Warn("PASSWORD_LOGIN_DISABLED is set without OIDC_ENABLED, nobody can log in")
+	}
+
+	// Requests authenticated by an API token only see its team or project
+	teamRepo = apitoken.RestrictTeams(teamRepo)
+	teamMemberRepo = apitoken.RestrictTeamMembers(teamMemberRepo)
+	projectRepo = apitoken.RestrictProjects(projectRepo)
 
-	authUseCase := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, &cfg.JWT, &cfg.App)
+	deployTokenUseCase := deploytoken.NewUseCase(deployTokenRepo, projectRepo, teamMemberRepo)
 	userUseCase := user.NewUseCase(userRepo)
 	teamUseCase := team.NewUseCase(teamRepo, teamMemberRepo, userRepo)
 	projectUseCase := project.NewUseCase(projectRepo, teamMemberRepo, encryptor)
-	serviceUseCase := service.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, domainRepo, encryptor)
-	deploymentUseCase := deployment.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, deploymentRepo, domainRepo, containerManager, &cfg.Traefik)
+	traefikUseCase := traefik.NewUseCase(serviceRepo, projectRepo, domainRepo, portRepo, certificateRepo, containerManager, encryptor, &cfg.Traefik)
+	serviceUseCase := service.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, domainRepo, portRepo, certificateRepo, envVarRepo, encryptor, traefikUseCase, resolver, &cfg.Domains)
+	certificateUseCase := certificate.NewUseCase(certificateRepo, domainRepo, teamMemberRepo, encryptor, traefikUseCase)
+	networkUseCase := network.NewUseCase(networkRepo, projectRepo, serviceRepo, teamMemberRepo, containerManager, &cfg.Docker)
+	envGroupUseCase := envgroup.NewUseCase(envGroupRepo, envVarRepo, projectRepo, serviceRepo, teamMemberRepo, encryptor)
+	deploymentUseCase := deployment.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, deploymentRepo, portRepo, networkRepo, containerManager, cloner, encryptor, &cfg.Docker, &cfg.Traefik, &cfg.Build, traefikUseCase, envGroupUseCase, secretProviders(&cfg.Secrets, encryptor, log), &cfg.Secrets)
+
+	if imported, err := serviceUseCase.ImportLegacyEnvVars(ctx); err != nil {
+		log.Fatalf("Failed to import service env vars: %v", err)
+	} else if imported > 0 {
+		log.Infof("Imported the env vars of %d services as secrets", imported)
+	}
+
+	workerCtx, stopWorkers := context.WithCancel(ctx)
+	defer stopWorkers()
+
+	if cfg.ImageWatcher.Enabled && dockerClient != nil {
+		imageWatcher := deployment.NewImageWatcher(deploymentUseCase, &cfg.ImageWatcher, log)
+		go imageWatcher.Run(workerCtx)
+		log.Infof("Image watcher started (poll interval %s)", cfg.ImageWatcher.PollInterval)
+	}
 
-	authMiddleware := middleware.NewAuthMiddleware(authUseCase)
+	if dockerClient != nil {
+		scheduler := deployment.NewScheduler(deploymentUseCase, &cfg.Scheduler, log)
+		go scheduler.Run(workerCtx)
+		log.Infof("Deployment scheduler started (poll interval %s)", cfg.Scheduler.PollInterval)
+	}
+
+	var adminHandler *handler.AdminHandler
+	if dockerClient != nil {
+		traefikManager := traefik.NewManager(containerManager, userRepo, &cfg.Traefik, log)
+		adminHandler = handler.NewAdminHandler(traefikManager)
+		if cfg.Traefik.Enabled && cfg.Traefik.Managed {
+			go func() {
+				if err := traefikManager.Ensure(workerCtx); err != nil {
+					log.Errorf("Failed to start Traefik: %v", err)
+				}
+			}()
+		}
+	}
+
+	sessionCleaner := session.NewCleaner(refreshTokenRepo, &cfg.Session, log)
+	go sessionCleaner.Run(workerCtx)
+
+	if cfg.Traefik.ConfigFile != "" {
+		fileWriter := traefik.NewFileWriter(traefikUseCase, &cfg.Traefik, log)
+		go fileWriter.Run(workerCtx)
+		log.Infof("Writing Traefik configuration to %s", cfg.Traefik.ConfigFile)
+	}
+
+	authMiddleware := middleware.NewAuthMiddleware(authUseCase, apiTokenUseCase, deployTokenUseCase)
 	authHandler := handler.NewAuthHandler(authUseCase, v)
+	mfaHandler := handler.NewMFAHandler(mfaUseCase, authUseCase, v)
+	sessionHandler := handler.NewSessionHandler(session.NewUseCase(refreshTokenRepo))
 	userHandler := handler.NewUserHandler(userUseCase, v)
 	teamHandler := handler.NewTeamHandler(teamUseCase, v)
-	projectHandler := handler.NewProjectHandler(projectUseCase, v)
+	projectHandler := handler.NewProjectHandler(projectUseCase, deploymentUseCase, v)
 	serviceHandler := handler.NewServiceHandler(serviceUseCase, deploymentUseCase, v)
+	networkHandler := handler.NewNetworkHandler(networkUseCase, v)
+	envGroupHandler := handler.NewEnvGroupHandler(envGroupUseCase, v)
+	apiTokenHandler := handler.NewAPITokenHandler(apiTokenUseCase, v)
+	deployTokenHandler := handler.NewDeployTokenHandler(deployTokenUseCase, v)
+	certificateHandler := handler.NewCertificateHandler(certificateUseCase, v)
+	webhookHandler := handler.NewWebhookHandler(deploymentUseCase)
 	docsHandler := handler.NewDocsHandler()
 
+	var traefikHandler *handler.TraefikHandler
+	if cfg.Traefik.ProviderToken != "" {
+		traefikHandler = handler.NewTraefikHandler(traefikUseCase, cfg.Traefik.ProviderToken)
+	} else if cfg.Traefik.Enabled && cfg.Traefik.ConfigFile == "" {
+		log.Warn("Neither TRAEFIK_PROVIDER_TOKEN nor TRAEFIK_CONFIG_FILE is set, Traefik will not receive any routes")
+	}
+
 	router := httpAdapter.NewRouter(&httpAdapter.RouterConfig{
-		Logger:         log,
-		AuthMiddleware: authMiddleware,
-		AuthHandler:    authHandler,
-		UserHandler:    userHandler,
-		TeamHandler:    teamHandler,
-		ProjectHandler: projectHandler,
-		ServiceHandler: serviceHandler,
-		DocsHandler:    docsHandler,
+		Logger:             log,
+		AuthMiddleware:     authMiddleware,
+		AuthHandler:        authHandler,
+		OIDCHandler:        oidcHandler,
+		MFAHandler:         mfaHandler,
+		SessionHandler:     sessionHandler,
+		UserHandler:        userHandler,
+		TeamHandler:        teamHandler,
+		ProjectHandler:     projectHandler,
+		ServiceHandler:     serviceHandler,
+		NetworkHandler:     networkHandler,
+		EnvGroupHandler:    envGroupHandler,
+		APITokenHandler:    apiTokenHandler,
+		DeployTokenHandler: deployTokenHandler,
+		CertificateHandler: certificateHandler,
+		WebhookHandler:     webhookHandler,
+		TraefikHandler:     traefikHandler,
+		AdminHandler:       adminHandler,
+		DocsHandler:        docsHandler,
 	})
 
 	engine := router.Setup(cfg.App.Env)
@@ -175,6 +307,7 @@ func main() {
 	<-quit
 
 	log.Info("Shutting down server...")
+	stopWorkers()
 
 	shutdownCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
@@ -186,6 +319,21 @@ func main() {
 	log.Info("Server exited properly")
 }
 
+// secretProviders returns the providers env values may reference: sealed
+// values always, Vault and files when configured
+func secretProviders(cfg *config.SecretsConfig, encryptor *crypto.Encryptor, log *logger.Logger) []domainSecret.Provider {
+	providers := []domainSecret.Provider{encryptor}
+	if cfg.VaultAddress != "" {
+		providers = append(providers, secret.NewVaultProvider(cfg.VaultAddress, cfg.VaultToken, cfg.VaultNamespace))
+		log.Infof("Resolving vault: env references against %s below %s", cfg.VaultAddress, cfg.VaultPathPrefix)
+	}
+	if cfg.FilesDir != "" {
+		providers = append(providers, secret.NewFileProvider(cfg.FilesDir))
+		log.Infof("Resolving file: env references in %s below %s", cfg.FilesDir, cfg.FilesPrefix)
+	}
+	return providers
+}
+
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/podoru/spinner-podoru/internal/adapter/repository/postgres"
+	domainSecret "github.com/podoru/spinner-podoru/internal/domain/secret"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/database"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/logger"
+	"github.com/podoru/spinner-podoru/internal/usecase/secrets"
+	"github.com/podoru/spinner-podoru/pkg/crypto"
+)
+
+var errUnknownCommand = errors.New("usage: podoru secrets rotate [-batch-size N] | podoru secrets encrypt < value")
+
+// runSecretsCommand runs `podoru secrets <command>` instead of the server
+func runSecretsCommand(ctx context.Context, args []string, db *database.Database, encryptor *crypto.Encryptor, log *logger.Logger) error {
+	if len(args) == 0 {
+		return errUnknownCommand
+	}
+	switch args[0] {
+	case "rotate":
+		return rotateSecrets(ctx, args[1:], db, encryptor, log)
+	case "encrypt":
+		return encryptSecret(encryptor)
+	}
+	return errUnknownCommand
+}
+
+// encryptSecret seals stdin into an "${{encrypted:...}}" env value, which can be kept
+// where plain secrets should not be, such as a compose file in git
+func encryptSecret(encryptor *crypto.Encryptor) error {
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+	value, err := encryptor.EncryptReference(strings.TrimSuffix(string(data), "\n"))
+	if err != nil {
+		return err
+	}
+	fmt.Println(domainSecret.FormatReference(encryptor.Scheme(), value))
+	return nil
+}
+
+func rotateSecrets(ctx context.Context, args []string, db *database.Database, encryptor *crypto.Encryptor, log *logger.Logger) error {
+	flags := flag.NewFlagSet("secrets rotate", flag.ContinueOnError)
+	batchSize := flags.Int("batch-size", secrets.DefaultBatchSize, "rows to re-encrypt per query")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	log.Infof("Re-encrypting stored secrets with key %s", encryptor.KeyID())
+	secretsUseCase := secrets.NewUseCase(postgres.NewCiphertextRepository(db.Pool), encryptor)
+	results, err := secretsUseCase.Rotate(ctx, *batchSize)
+	for _, r := range results {
+		log.Infof("%s: %d rotated, %d already current, %d changed while rotating", r.Column, r.Rotated, r.Current, r.Changed)
+	}
+	if err != nil {
+		return fmt.Errorf("rotation stopped: %w", err)
+	}
+
+	log.Info("All secrets use the current key")
+	// References live inside env values and outside Podoru, such as in
+	// compose files, so rotating cannot re-seal them
+	log.Warn("${{encrypted:...}} references are not re-encrypted; seal them again with `podoru secrets encrypt` before removing previous keys")
+	return nil
+}
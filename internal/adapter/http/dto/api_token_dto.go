@@ -0,0 +1,66 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+// APITokenResponse represents a personal API token in API responses. The
+// token itself is only returned once, when it is created.
+type APITokenResponse struct {
+	ID          uuid.UUID  `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name        string     `json:"name" example:"GitHub Actions"`
+	TokenPrefix string     `json:"token_prefix" example:"pod_x7Kq2mPa"`
+	Scopes      []string   `json:"scopes" example:"deploy"`
+	TeamID      *uuid.UUID `json:"team_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440001"`
+	ProjectID   *uuid.UUID `json:"project_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440002"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" example:"2025-01-15T10:30:00Z"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty" example:"2024-02-01T08:00:00Z"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" example:"2024-01-15T10:30:00Z"`
+}
+
+// CreateAPITokenRequest represents the API token creation payload
+type CreateAPITokenRequest struct {
+	Name      string     `json:"name" validate:"required,min=1,max=100" example:"GitHub Actions"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=read deploy admin" example:"deploy"`
+	TeamID    *uuid.UUID `json:"team_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440001"`
+	ProjectID *uuid.UUID `json:"project_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440002"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2025-01-15T10:30:00Z"`
+}
+
+// CreatedAPITokenResponse carries the only copy of a new token
+type CreatedAPITokenResponse struct {
+	APITokenResponse
+	Token string `json:"token" example:"pod_x7Kq2mPaR4vN8sLw1yZ3bC6dE9fG0hJ2kM5nQ7tU"`
+}
+
+func ToAPITokenResponse(token *entity.APIToken) APITokenResponse {
+	scopes := make([]string, len(token.Scopes))
+	for i, s := range token.Scopes {
+		scopes[i] = string(s)
+	}
+	return APITokenResponse{
+		ID:          token.ID,
+		Name:        token.Name,
+		TokenPrefix: token.TokenPrefix,
+		Scopes:      scopes,
+		TeamID:      token.TeamID,
+		ProjectID:   token.ProjectID,
+		ExpiresAt:   token.ExpiresAt,
+		LastUsedAt:  token.LastUsedAt,
+		RevokedAt:   token.RevokedAt,
+		CreatedAt:   token.CreatedAt,
+	}
+}
+
+func ToAPITokensResponse(tokens []entity.APIToken) []APITokenResponse {
+	responses := make([]APITokenResponse, len(tokens))
+	for i, t := range tokens {
+		responses[i] = ToAPITokenResponse(&t)
+	}
+	return responses
+}
@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+// DeployTokenResponse represents a project deploy token in API responses.
+// The token itself is only returned once, when it is created.
+type DeployTokenResponse struct {
+	ID          uuid.UUID  `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ProjectID   uuid.UUID  `json:"project_id" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Name        string     `json:"name" example:"GitLab CI"`
+	TokenPrefix string     `json:"token_prefix" example:"pdk_Vb3nR8qT"`
+	CreatedBy   *uuid.UUID `json:"created_by,omitempty" example:"550e8400-e29b-41d4-a716-446655440002"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" example:"2025-01-15T10:30:00Z"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty" example:"2024-02-01T08:00:00Z"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" example:"2024-01-15T10:30:00Z"`
+}
+
+// CreateDeployTokenRequest represents the deploy token creation payload
+type CreateDeployTokenRequest struct {
+	Name      string     `json:"name" validate:"required,min=1,max=100" example:"GitLab CI"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2025-01-15T10:30:00Z"`
+}
+
+// CreatedDeployTokenResponse carries the only copy of a new deploy token
+type CreatedDeployTokenResponse struct {
+	DeployTokenResponse
+	Token string `json:"token" example:"pdk_Vb3nR8qTw2Xy5zA7cD9fH1jK4mN6pQ8sU0vW3xY"`
+}
+
+func ToDeployTokenResponse(token *entity.DeployToken) DeployTokenResponse {
+	return DeployTokenResponse{
+		ID:          token.ID,
+		ProjectID:   token.ProjectID,
+		Name:        token.Name,
+		TokenPrefix: token.TokenPrefix,
+		CreatedBy:   token.CreatedBy,
+		ExpiresAt:   token.ExpiresAt,
+		LastUsedAt:  token.LastUsedAt,
+		RevokedAt:   token.RevokedAt,
+		CreatedAt:   token.CreatedAt,
+	}
+}
+
+func ToDeployTokensResponse(tokens []entity.DeployToken) []DeployTokenResponse {
+	responses := make([]DeployTokenResponse, len(tokens))
+	for i, t := range tokens {
+		responses[i] = ToDeployTokenResponse(&t)
+	}
+	return responses
+}
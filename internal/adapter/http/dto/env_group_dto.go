@@ -0,0 +1,98 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+// EnvGroupResponse represents an env group in API responses. Only the
+// variable names are returned, never their values.
+type EnvGroupResponse struct {
+	ID        uuid.UUID  `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TeamID    uuid.UUID  `json:"team_id" example:"550e8400-e29b-41d4-a716-446655440001"`
+	ProjectID *uuid.UUID `json:"project_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440002"`
+	Layer     string     `json:"layer" example:"team"`
+	Name      string     `json:"name" example:"observability"`
+	Keys      []string   `json:"keys" example:"SENTRY_DSN,OTEL_ENDPOINT"`
+	CreatedAt time.Time  `json:"created_at" example:"2024-01-15T10:30:00Z"`
+	UpdatedAt time.Time  `json:"updated_at" example:"2024-01-15T10:30:00Z"`
+}
+
+// CreateEnvGroupRequest represents the env group creation payload
+type CreateEnvGroupRequest struct {
+	Name string            `json:"name" validate:"required,min=2,max=100" example:"observability"`
+	Vars map[string]string `json:"vars" validate:"required"`
+}
+
+// UpdateEnvGroupRequest represents the env group update payload. Vars, when
+// given, replace all of the group's variables.
+type UpdateEnvGroupRequest struct {
+	Name *string           `json:"name,omitempty" validate:"omitempty,min=2,max=100" example:"observability"`
+	Vars map[string]string `json:"vars,omitempty"`
+}
+
+// EnvSourceResponse represents the layer, and group, a variable comes from
+type EnvSourceResponse struct {
+	Layer     string     `json:"layer" example:"project"`
+	GroupID   *uuid.UUID `json:"group_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	GroupName string     `json:"group_name,omitempty" example:"observability"`
+}
+
+// ResolvedEnvVarResponse represents one variable of a service's resolved env
+type ResolvedEnvVarResponse struct {
+	Key       string              `json:"key" example:"SENTRY_DSN"`
+	Value     string              `json:"value" example:"htt********"`
+	Source    EnvSourceResponse   `json:"source"`
+	Overrides []EnvSourceResponse `json:"overrides,omitempty"`
+}
+
+func ToEnvGroupResponse(group *entity.EnvGroup) EnvGroupResponse {
+	keys := group.Keys
+	if keys == nil {
+		keys = []string{}
+	}
+	return EnvGroupResponse{
+		ID:        group.ID,
+		TeamID:    group.TeamID,
+		ProjectID: group.ProjectID,
+		Layer:     string(group.Layer()),
+		Name:      group.Name,
+		Keys:      keys,
+		CreatedAt: group.CreatedAt,
+		UpdatedAt: group.UpdatedAt,
+	}
+}
+
+func ToEnvGroupsResponse(groups []entity.EnvGroup) []EnvGroupResponse {
+	responses := make([]EnvGroupResponse, len(groups))
+	for i, g := range groups {
+		responses[i] = ToEnvGroupResponse(&g)
+	}
+	return responses
+}
+
+func toEnvSourceResponse(source entity.EnvSource) EnvSourceResponse {
+	return EnvSourceResponse{
+		Layer:     string(source.Layer),
+		GroupID:   source.GroupID,
+		GroupName: source.GroupName,
+	}
+}
+
+func ToResolvedEnvResponse(vars []entity.ResolvedEnvVar) []ResolvedEnvVarResponse {
+	responses := make([]ResolvedEnvVarResponse, len(vars))
+	for i, v := range vars {
+		responses[i] = ResolvedEnvVarResponse{
+			Key:    v.Key,
+			Value:  v.Value,
+			Source: toEnvSourceResponse(v.Source),
+		}
+		for _, o := range v.Overrides {
+			responses[i].Overrides = append(responses[i].Overrides, toEnvSourceResponse(o))
+		}
+	}
+	return responses
+}
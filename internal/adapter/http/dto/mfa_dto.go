@@ -0,0 +1,118 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+// MFAPendingLoginResponse is returned by a login that needs a second factor
+type MFAPendingLoginResponse struct {
+	MFARequired        bool      `json:"mfa_required" example:"true"`
+	MFAToken           string    `json:"mfa_token" example:"Zk3xQ9vB2mN7pL4wR8tY1cH6jD0sF5gA"`
+	ExpiresAt          time.Time `json:"expires_at" example:"2024-01-15T10:35:00Z"`
+	EnrollmentRequired bool      `json:"enrollment_required" example:"false"`
+}
+
+// MFATokenRequest carries the MFA token of a pending login
+type MFATokenRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required" example:"Zk3xQ9vB2mN7pL4wR8tY1cH6jD0sF5gA"`
+}
+
+// MFAVerifyRequest finishes a pending login with a TOTP code or a recovery code
+type MFAVerifyRequest struct {
+	MFAToken     string `json:"mfa_token" validate:"required" example:"Zk3xQ9vB2mN7pL4wR8tY1cH6jD0sF5gA"`
+	Code         string `json:"code,omitempty" validate:"required_without=RecoveryCode" example:"123456"`
+	RecoveryCode string `json:"recovery_code,omitempty" example:"k7m2p-x9qrt"`
+}
+
+// MFARecoverRequest resets two-factor authentication with a recovery code
+type MFARecoverRequest struct {
+	MFAToken     string `json:"mfa_token" validate:"required" example:"Zk3xQ9vB2mN7pL4wR8tY1cH6jD0sF5gA"`
+	RecoveryCode string `json:"recovery_code" validate:"required" example:"k7m2p-x9qrt"`
+}
+
+// MFACodeRequest carries a code from the user's authenticator
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required" example:"123456"`
+}
+
+// MFADisableRequest turns two-factor authentication off with a TOTP code or
+// a recovery code
+type MFADisableRequest struct {
+	Code         string `json:"code,omitempty" validate:"required_without=RecoveryCode" example:"123456"`
+	RecoveryCode string `json:"recovery_code,omitempty" example:"k7m2p-x9qrt"`
+}
+
+// TOTPEnrollmentResponse carries a new authenticator secret. The recovery
+// codes are only returned here.
+type TOTPEnrollmentResponse struct {
+	Secret          string   `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	ProvisioningURI string   `json:"provisioning_uri" example:"otpauth://totp/podoru:user@example.com?algorithm=SHA1&digits=6&issuer=podoru&period=30&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	RecoveryCodes   []string `json:"recovery_codes" example:"k7m2p-x9qrt"`
+}
+
+// MFAStatusResponse represents the current user's two-factor status
+type MFAStatusResponse struct {
+	Enabled                bool       `json:"enabled" example:"true"`
+	ConfirmedAt            *time.Time `json:"confirmed_at,omitempty" example:"2024-01-15T10:30:00Z"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining" example:"10"`
+	Required               bool       `json:"required" example:"false"`
+}
+
+// RecoveryCodesResponse carries new recovery codes
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"k7m2p-x9qrt"`
+}
+
+// MFAPolicyResponse represents the instance-wide two-factor policy
+type MFAPolicyResponse struct {
+	Required  string     `json:"required" example:"owners"`
+	UpdatedBy *uuid.UUID `json:"updated_by,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty" example:"2024-01-15T10:30:00Z"`
+}
+
+// UpdateMFAPolicyRequest sets who must use two-factor authentication
+type UpdateMFAPolicyRequest struct {
+	Required string `json:"required" validate:"required,oneof=none owners all" example:"owners"`
+}
+
+func (r *UpdateMFAPolicyRequest) ToEntity() *entity.MFAPolicyUpdate {
+	return &entity.MFAPolicyUpdate{Required: entity.MFARequirement(r.Required)}
+}
+
+func ToMFAPendingLoginResponse(pending *entity.MFAPendingLogin) MFAPendingLoginResponse {
+	return MFAPendingLoginResponse{
+		MFARequired:        true,
+		MFAToken:           pending.Token,
+		ExpiresAt:          pending.ExpiresAt,
+		EnrollmentRequired: pending.EnrollmentRequired,
+	}
+}
+
+func ToTOTPEnrollmentResponse(enrollment *entity.TOTPEnrollment) TOTPEnrollmentResponse {
+	return TOTPEnrollmentResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningURI: enrollment.ProvisioningURI,
+		RecoveryCodes:   enrollment.RecoveryCodes,
+	}
+}
+
+func ToMFAStatusResponse(status *entity.MFAStatus) MFAStatusResponse {
+	return MFAStatusResponse{
+		Enabled:                status.Enabled,
+		ConfirmedAt:            status.ConfirmedAt,
+		RecoveryCodesRemaining: status.RecoveryCodesRemaining,
+		Required:               status.Required,
+	}
+}
+
+func ToMFAPolicyResponse(policy *entity.MFAPolicy) MFAPolicyResponse {
+	return MFAPolicyResponse{
+		Required:  string(policy.Required),
+		UpdatedBy: policy.UpdatedBy,
+		UpdatedAt: policy.UpdatedAt,
+	}
+}
@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+// NetworkResponse represents project network data in API responses
+type NetworkResponse struct {
+	ID              uuid.UUID   `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ProjectID       *uuid.UUID  `json:"project_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Name            string      `json:"name" example:"backend"`
+	DockerNetworkID *string     `json:"docker_network_id,omitempty" example:"3c9f1b2a7d4e"`
+	Driver          string      `json:"driver" example:"bridge"`
+	Subnet          *string     `json:"subnet,omitempty" example:"10.20.0.0/24"`
+	Gateway         *string     `json:"gateway,omitempty" example:"10.20.0.1"`
+	IsDefault       bool        `json:"is_default" example:"false"`
+	ServiceIDs      []uuid.UUID `json:"service_ids,omitempty"`
+	CreatedAt       time.Time   `json:"created_at" example:"2024-01-15T10:30:00Z"`
+}
+
+// CreateNetworkRequest represents the project network creation payload
+type CreateNetworkRequest struct {
+	Name    string  `json:"name" validate:"required,min=2,max=63,slug" example:"backend"`
+	Driver  *string `json:"driver,omitempty" validate:"omitempty,oneof=bridge overlay" example:"bridge"`
+	Subnet  *string `json:"subnet,omitempty" validate:"omitempty,cidr" example:"10.20.0.0/24"`
+	Gateway *string `json:"gateway,omitempty" validate:"omitempty,ip" example:"10.20.0.1"`
+}
+
+func ToNetworkResponse(network *entity.Network) NetworkResponse {
+	return NetworkResponse{
+		ID:              network.ID,
+		ProjectID:       network.ProjectID,
+		Name:            network.Name,
+		DockerNetworkID: network.DockerNetworkID,
+		Driver:          string(network.Driver),
+		Subnet:          network.Subnet,
+		Gateway:         network.Gateway,
+		IsDefault:       network.IsDefault,
+		ServiceIDs:      network.ServiceIDs,
+		CreatedAt:       network.CreatedAt,
+	}
+}
+
+func ToNetworksResponse(networks []entity.Network) []NetworkResponse {
+	responses := make([]NetworkResponse, len(networks))
+	for i, n := range networks {
+		responses[i] = ToNetworkResponse(&n)
+	}
+	return responses
+}
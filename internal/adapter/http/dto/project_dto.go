@@ -10,51 +10,71 @@ import (
 
 // ProjectResponse represents project data in API responses
 type ProjectResponse struct {
-	ID           uuid.UUID `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	TeamID       uuid.UUID `json:"team_id" example:"550e8400-e29b-41d4-a716-446655440001"`
-	Name         string    `json:"name" example:"My Web App"`
-	Slug         string    `json:"slug" example:"my-web-app"`
-	Description  *string   `json:"description,omitempty" example:"A production web application"`
-	GithubRepo   *string   `json:"github_repo,omitempty" example:"https://github.com/user/repo"`
-	GithubBranch string    `json:"github_branch" example:"main"`
-	AutoDeploy   bool      `json:"auto_deploy" example:"true"`
-	CreatedAt    time.Time `json:"created_at" example:"2024-01-15T10:30:00Z"`
-	UpdatedAt    time.Time `json:"updated_at" example:"2024-01-15T10:30:00Z"`
+	ID                uuid.UUID          `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TeamID            uuid.UUID          `json:"team_id" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Name              string             `json:"name" example:"My Web App"`
+	Slug              string             `json:"slug" example:"my-web-app"`
+	Description       *string            `json:"description,omitempty" example:"A production web application"`
+	GithubRepo        *string            `json:"github_repo,omitempty" example:"https://github.com/user/repo"`
+	GithubBranch      string             `json:"github_branch" example:"main"`
+	AutoDeploy        bool               `json:"auto_deploy" example:"true"`
+	MaintenanceWindow *MaintenanceWindow `json:"maintenance_window,omitempty"`
+	CreatedAt         time.Time          `json:"created_at" example:"2024-01-15T10:30:00Z"`
+	UpdatedAt         time.Time          `json:"updated_at" example:"2024-01-15T10:30:00Z"`
 }
 
 // CreateProjectRequest represents the project creation payload
 type CreateProjectRequest struct {
-	Name         string  `json:"name" validate:"required,min=2,max=100" example:"My Web App"`
-	Slug         string  `json:"slug" validate:"required,slug,min=2,max=100" example:"my-web-app"`
-	Description  *string `json:"description,omitempty" validate:"omitempty,max=500" example:"A production web application"`
-	GithubRepo   *string `json:"github_repo,omitempty" validate:"omitempty,url" example:"https://github.com/user/repo"`
-	GithubBranch *string `json:"github_branch,omitempty" example:"main"`
-	GithubToken  *string `json:"github_token,omitempty" example:"ghp_xxxxxxxxxxxxxxxxxxxx"`
-	AutoDeploy   *bool   `json:"auto_deploy,omitempty" example:"false"`
+	Name              string             `json:"name" validate:"required,min=2,max=100" example:"My Web App"`
+	Slug              string             `json:"slug" validate:"required,slug,min=2,max=100" example:"my-web-app"`
+	Description       *string            `json:"description,omitempty" validate:"omitempty,max=500" example:"A production web application"`
+	GithubRepo        *string            `json:"github_repo,omitempty" validate:"omitempty,url" example:"https://github.com/user/repo"`
+	GithubBranch      *string            `json:"github_branch,omitempty" example:"main"`
+	GithubToken       *string            `json:"github_token,omitempty" example:"ghp_xxxxxxxxxxxxxxxxxxxx"`
+	AutoDeploy        *bool              `json:"auto_deploy,omitempty" example:"false"`
+	MaintenanceWindow *MaintenanceWindow `json:"maintenance_window,omitempty"`
 }
 
 // UpdateProjectRequest represents the project update payload
 type UpdateProjectRequest struct {
-	Name         *string `json:"name,omitempty" validate:"omitempty,min=2,max=100" example:"Updated Project Name"`
-	Description  *string `json:"description,omitempty" validate:"omitempty,max=500" example:"Updated description"`
-	GithubRepo   *string `json:"github_repo,omitempty" validate:"omitempty,url" example:"https://github.com/user/new-repo"`
-	GithubBranch *string `json:"github_branch,omitempty" example:"develop"`
-	GithubToken  *string `json:"github_token,omitempty" example:"ghp_xxxxxxxxxxxxxxxxxxxx"`
-	AutoDeploy   *bool   `json:"auto_deploy,omitempty" example:"true"`
+	Name              *string            `json:"name,omitempty" validate:"omitempty,min=2,max=100" example:"Updated Project Name"`
+	Description       *string            `json:"description,omitempty" validate:"omitempty,max=500" example:"Updated description"`
+	GithubRepo        *string            `json:"github_repo,omitempty" validate:"omitempty,url" example:"https://github.com/user/new-repo"`
+	GithubBranch      *string            `json:"github_branch,omitempty" example:"develop"`
+	GithubToken       *string            `json:"github_token,omitempty" example:"ghp_xxxxxxxxxxxxxxxxxxxx"`
+	AutoDeploy        *bool              `json:"auto_deploy,omitempty" example:"true"`
+	MaintenanceWindow *MaintenanceWindow `json:"maintenance_window,omitempty"`
+}
+
+// MaintenanceWindow restricts deployments to certain days and hours.
+// Send an empty days list on update to remove it.
+type MaintenanceWindow struct {
+	Days     []string `json:"days" example:"mon,tue,wed,thu,fri"`
+	Start    string   `json:"start" example:"02:00"`
+	End      string   `json:"end" example:"04:00"`
+	Timezone string   `json:"timezone,omitempty" example:"UTC"`
+}
+
+// ProjectWebhookResponse represents the push webhook configuration of a project
+type ProjectWebhookResponse struct {
+	ProjectID uuid.UUID `json:"project_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Path      string    `json:"path" example:"/api/v1/webhooks/github/550e8400-e29b-41d4-a716-446655440000"`
+	Secret    string    `json:"secret" example:"Jx3kP9..."`
 }
 
 func ToProjectResponse(project *entity.Project) ProjectResponse {
 	return ProjectResponse{
-		ID:           project.ID,
-		TeamID:       project.TeamID,
-		Name:         project.Name,
-		Slug:         project.Slug,
-		Description:  project.Description,
-		GithubRepo:   project.GithubRepo,
-		GithubBranch: project.GithubBranch,
-		AutoDeploy:   project.AutoDeploy,
-		CreatedAt:    project.CreatedAt,
-		UpdatedAt:    project.UpdatedAt,
+		ID:                project.ID,
+		TeamID:            project.TeamID,
+		Name:              project.Name,
+		Slug:              project.Slug,
+		Description:       project.Description,
+		GithubRepo:        project.GithubRepo,
+		GithubBranch:      project.GithubBranch,
+		AutoDeploy:        project.AutoDeploy,
+		MaintenanceWindow: toMaintenanceWindow(project.MaintenanceWindow),
+		CreatedAt:         project.CreatedAt,
+		UpdatedAt:         project.UpdatedAt,
 	}
 }
 
@@ -65,3 +85,15 @@ func ToProjectsResponse(projects []entity.Project) []ProjectResponse {
 	}
 	return responses
 }
+
+func toMaintenanceWindow(w *entity.MaintenanceWindow) *MaintenanceWindow {
+	if w == nil {
+		return nil
+	}
+	return &MaintenanceWindow{
+		Days:     w.Days,
+		Start:    w.Start,
+		End:      w.End,
+		Timezone: w.Timezone,
+	}
+}
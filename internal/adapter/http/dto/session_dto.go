@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+// SessionResponse represents a login session in API responses
+type SessionResponse struct {
+	ID         uuid.UUID `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserAgent  string    `json:"user_agent" example:"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) AppleWebKit/605.1.15"`
+	IPAddress  string    `json:"ip_address" example:"203.0.113.7"`
+	Current    bool      `json:"current" example:"true"`
+	ExpiresAt  time.Time `json:"expires_at" example:"2024-01-22T10:30:00Z"`
+	LastUsedAt time.Time `json:"last_used_at" example:"2024-01-15T10:30:00Z"`
+	CreatedAt  time.Time `json:"created_at" example:"2024-01-15T09:00:00Z"`
+}
+
+func ToSessionResponse(session *entity.Session) SessionResponse {
+	return SessionResponse{
+		ID:         session.ID,
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IPAddress,
+		Current:    session.Current,
+		ExpiresAt:  session.ExpiresAt,
+		LastUsedAt: session.LastUsedAt,
+		CreatedAt:  session.CreatedAt,
+	}
+}
+
+func ToSessionsResponse(sessions []entity.Session) []SessionResponse {
+	responses := make([]SessionResponse, len(sessions))
+	for i, s := range sessions {
+		responses[i] = ToSessionResponse(&s)
+	}
+	return responses
+}
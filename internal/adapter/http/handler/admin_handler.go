@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/podoru/spinner-podoru/internal/adapter/http/middleware"
+	"github.com/podoru/spinner-podoru/internal/usecase/traefik"
+	"github.com/podoru/spinner-podoru/pkg/response"
+)
+
+type AdminHandler struct {
+	traefikManager *traefik.Manager
+}
+
+func NewAdminHandler(traefikManager *traefik.Manager) *AdminHandler {
+	return &AdminHandler{traefikManager: traefikManager}
+}
+
+// TraefikStatus godoc
+// @Summary      Traefik status
+// @Description  Report the Traefik network and container Podoru routes through, and the outcome of the last startup check. Requires the superadmin role.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} response.Response{data=traefik.ProxyStatus} "Traefik status"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Requires superadmin role"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /admin/traefik [get]
+func (h *AdminHandler) TraefikStatus(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	status, err := h.traefikManager.Status(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, traefik.ErrNotSuperAdmin) {
+			response.Forbidden(c, err.Error())
+			return
+		}
+		response.InternalError(c, "Failed to get Traefik status")
+		return
+	}
+
+	response.Success(c, status)
+}
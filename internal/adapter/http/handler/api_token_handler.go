@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/adapter/http/dto"
+	"github.com/podoru/spinner-podoru/internal/adapter/http/middleware"
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/usecase/apitoken"
+	"github.com/podoru/spinner-podoru/pkg/response"
+	"github.com/podoru/spinner-podoru/pkg/validator"
+)
+
+type APITokenHandler struct {
+	apiTokenUseCase *apitoken.UseCase
+	validator       *validator.Validator
+}
+
+func NewAPITokenHandler(apiTokenUseCase *apitoken.UseCase, validator *validator.Validator) *APITokenHandler {
+	return &APITokenHandler{
+		apiTokenUseCase: apiTokenUseCase,
+		validator:       validator,
+	}
+}
+
+// List godoc
+// @Summary      List API tokens
+// @Description  Get the personal API tokens of the current user, revoked and expired ones included. Requires a login session, not an API token.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} response.Response{data=[]dto.APITokenResponse} "List of API tokens"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not allowed with an API token"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /users/me/tokens [get]
+func (h *APITokenHandler) List(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	tokens, err := h.apiTokenUseCase.List(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalError(c, "Failed to list API tokens")
+		return
+	}
+
+	response.Success(c, dto.ToAPITokensResponse(tokens))
+}
+
+// Create godoc
+// @Summary      Create API token
+// @Description  Issue a personal API token for CI and scripts. The token is only returned in this response. Scopes: read allows GET requests, deploy also deploys, starts, stops and restarts services, admin allows everything the user can do. team_id or project_id limit the token to one team or project. Requires a login session, not an API token.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body dto.CreateAPITokenRequest true "Token data"
+// @Success      201 {object} response.Response{data=dto.CreatedAPITokenResponse} "Token created"
+// @Failure      400 {object} response.Response "Invalid request body, expiry or project"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member, or not allowed with an API token"
+// @Failure      404 {object} response.Response "Project not found"
+// @Failure      422 {object} response.Response "Validation error"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /users/me/tokens [post]
+func (h *APITokenHandler) Create(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req entity.APITokenCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if err := h.validator.Validate(&req); err != nil {
+		response.ValidationError(c, validator.FormatValidationErrors(err))
+		return
+	}
+
+	token, plaintext, err := h.apiTokenUseCase.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, apitoken.ErrInvalidExpiry), errors.Is(err, apitoken.ErrTeamMismatch):
+			response.BadRequest(c, err.Error())
+		case errors.Is(err, apitoken.ErrProjectNotFound):
+			response.NotFound(c, "Project not found")
+		case errors.Is(err, apitoken.ErrNotTeamMember):
+			response.Forbidden(c, "Not a team member")
+		default:
+			response.InternalError(c, "Failed to create API token")
+		}
+		return
+	}
+
+	response.Created(c, dto.CreatedAPITokenResponse{
+		APITokenResponse: dto.ToAPITokenResponse(token),
+		Token:            plaintext,
+	})
+}
+
+// Revoke godoc
+// @Summary      Revoke API token
+// @Description  Stop one of the current user's API tokens from working. Requires a login session, not an API token.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        tokenId path string true "Token ID" format(uuid)
+// @Success      204 "Token revoked"
+// @Failure      400 {object} response.Response "Invalid token ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not allowed with an API token"
+// @Failure      404 {object} response.Response "API token not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /users/me/tokens/{tokenId} [delete]
+func (h *APITokenHandler) Revoke(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	tokenID, err := uuid.Parse(c.Param("tokenId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid token ID")
+		return
+	}
+
+	if err := h.apiTokenUseCase.Revoke(c.Request.Context(), userID, tokenID); err != nil {
+		if errors.Is(err, apitoken.ErrAPITokenNotFound) {
+			response.NotFound(c, "API token not found")
+			return
+		}
+		response.InternalError(c, "Failed to revoke API token")
+		return
+	}
+
+	response.NoContent(c)
+}
@@ -32,7 +32,7 @@ func NewAuthHandler(authUseCase *auth.UseCase, validator *validator.Validator) *
 // @Param        request body dto.RegisterRequest true "Registration details"
 // @Success      201 {object} response.Response{data=dto.AuthResponse} "User registered successfully"
 // @Failure      400 {object} response.Response "Invalid request body or validation error"
-// @Failure      403 {object} response.Response "Registration is disabled"
+// @Failure      403 {object} response.Response "Registration or password login is disabled"
 // @Failure      409 {object} response.Response "Email already exists"
 // @Failure      500 {object} response.Response "Internal server error"
 // @Router       /auth/register [post]
@@ -48,7 +48,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	user, tokens, err := h.authUseCase.Register(c.Request.Context(), req.ToEntity())
+	user, tokens, err := h.authUseCase.Register(clientContext(c), req.ToEntity())
 	if err != nil {
 		if errors.Is(err, auth.ErrEmailAlreadyExists) {
 			response.Conflict(c, "Email already exists")
@@ -58,6 +58,10 @@ func (h *AuthHandler) Register(c *gin.Context) {
 			response.Forbidden(c, "Registration is disabled")
 			return
 		}
+		if errors.Is(err, auth.ErrPasswordLoginDisabled) {
+			response.Forbidden(c, "Password login is disabled, sign in with single sign-on")
+			return
+		}
 		response.InternalError(c, "Failed to register user")
 		return
 	}
@@ -67,15 +71,16 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 // Login godoc
 // @Summary      Login user
-// @Description  Authenticate user with email and password
+// @Description  Authenticate user with email and password. Users with two-factor authentication, or whom the policy requires to set it up, get an MFA token to finish the login at /auth/mfa/verify instead of tokens.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        request body dto.LoginRequest true "Login credentials"
 // @Success      200 {object} response.Response{data=dto.AuthResponse} "Login successful"
+// @Success      202 {object} response.Response{data=dto.MFAPendingLoginResponse} "Second factor required"
 // @Failure      400 {object} response.Response "Invalid request body or validation error"
 // @Failure      401 {object} response.Response "Invalid email or password"
-// @Failure      403 {object} response.Response "Account is inactive"
+// @Failure      403 {object} response.Response "Account is inactive or password login is disabled"
 // @Failure      500 {object} response.Response "Internal server error"
 // @Router       /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -90,7 +95,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	user, tokens, err := h.authUseCase.Login(c.Request.Context(), req.ToEntity())
+	user, tokens, pending, err := h.authUseCase.Login(clientContext(c), req.ToEntity())
 	if err != nil {
 		if errors.Is(err, auth.ErrInvalidCredentials) {
 			response.Unauthorized(c, "Invalid email or password")
@@ -100,23 +105,32 @@ func (h *AuthHandler) Login(c *gin.Context) {
 			response.Forbidden(c, "Account is inactive")
 			return
 		}
+		if errors.Is(err, auth.ErrPasswordLoginDisabled) {
+			response.Forbidden(c, "Password login is disabled, sign in with single sign-on")
+			return
+		}
 		response.InternalError(c, "Failed to login")
 		return
 	}
 
+	if pending != nil {
+		respondMFAPending(c, pending)
+		return
+	}
+
 	response.Success(c, dto.ToAuthResponse(user, tokens))
 }
 
 // Refresh godoc
 // @Summary      Refresh access token
-// @Description  Get new access token using refresh token
+// @Description  Get new access token using refresh token. The refresh token is replaced by the one returned and works only once; presenting it again revokes the session.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        request body dto.RefreshRequest true "Refresh token"
 // @Success      200 {object} response.Response{data=dto.TokenResponse} "Token refreshed successfully"
 // @Failure      400 {object} response.Response "Invalid request body or validation error"
-// @Failure      401 {object} response.Response "Invalid or expired refresh token"
+// @Failure      401 {object} response.Response "Invalid, expired or reused refresh token"
 // @Failure      403 {object} response.Response "Account is inactive"
 // @Failure      500 {object} response.Response "Internal server error"
 // @Router       /auth/refresh [post]
@@ -132,12 +146,16 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 		return
 	}
 
-	tokens, err := h.authUseCase.RefreshToken(c.Request.Context(), req.RefreshToken)
+	tokens, err := h.authUseCase.RefreshToken(clientContext(c), req.RefreshToken)
 	if err != nil {
 		if errors.Is(err, auth.ErrInvalidToken) || errors.Is(err, auth.ErrTokenExpired) {
 			response.Unauthorized(c, "Invalid or expired refresh token")
 			return
 		}
+		if errors.Is(err, auth.ErrTokenReused) {
+			response.Unauthorized(c, "Refresh token was already used, the session has been revoked")
+			return
+		}
 		if errors.Is(err, auth.ErrUserInactive) {
 			response.Forbidden(c, "Account is inactive")
 			return
@@ -151,7 +169,7 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 
 // Logout godoc
 // @Summary      Logout user
-// @Description  Invalidate refresh token
+// @Description  End the session of a refresh token
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -38,7 +38,7 @@ func setupAuthHandler(t *testing.T) (*handler.AuthHandler, *gin.Engine) {
 	}
 
 	refreshTokenRepo := &mocks.MockRefreshTokenRepository{
-		CreateFunc: func(ctx context.Context, token *entity.RefreshToken) error {
+		CreateSessionFunc: func(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error {
 			return nil
 		},
 	}
@@ -65,7 +65,7 @@ func setupAuthHandler(t *testing.T) (*handler.AuthHandler, *gin.Engine) {
 		RegistrationEnabled: true,
 	}
 
-	authUseCase := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig)
+	authUseCase := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig, nil)
 
 	v, err := validator.New()
 	if err != nil {
@@ -204,7 +204,7 @@ func setupLoginHandler(t *testing.T, testUser *entity.User) (*handler.AuthHandle
 	}
 
 	refreshTokenRepo := &mocks.MockRefreshTokenRepository{
-		CreateFunc: func(ctx context.Context, token *entity.RefreshToken) error {
+		CreateSessionFunc: func(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error {
 			return nil
 		},
 	}
@@ -220,7 +220,7 @@ func setupLoginHandler(t *testing.T, testUser *entity.User) (*handler.AuthHandle
 
 	appConfig := &config.AppConfig{}
 
-	authUseCase := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig)
+	authUseCase := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig, nil)
 
 	v, err := validator.New()
 	if err != nil {
@@ -0,0 +1,307 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/adapter/http/dto"
+	"github.com/podoru/spinner-podoru/internal/adapter/http/middleware"
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/usecase/certificate"
+	"github.com/podoru/spinner-podoru/pkg/response"
+	"github.com/podoru/spinner-podoru/pkg/validator"
+)
+
+const (
+	defaultExpiringDays = 30
+	maxExpiringDays     = 365
+)
+
+type CertificateHandler struct {
+	certificateUseCase *certificate.UseCase
+	validator          *validator.Validator
+}
+
+func NewCertificateHandler(certificateUseCase *certificate.UseCase, validator *validator.Validator) *CertificateHandler {
+	return &CertificateHandler{
+		certificateUseCase: certificateUseCase,
+		validator:          validator,
+	}
+}
+
+// List godoc
+// @Summary      List team certificates
+// @Description  Get the custom TLS certificates uploaded to a team. Private keys are never returned.
+// @Tags         certificates
+// @Produce      json
+// @Security     BearerAuth
+// @Param        teamId path string true "Team ID" format(uuid)
+// @Success      200 {object} response.Response{data=[]dto.CertificateResponse} "List of certificates"
+// @Failure      400 {object} response.Response "Invalid team ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /teams/{teamId}/certificates [get]
+func (h *CertificateHandler) List(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	teamID, err := uuid.Parse(c.Param("teamId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid team ID")
+		return
+	}
+
+	certificates, err := h.certificateUseCase.List(c.Request.Context(), userID, teamID)
+	if err != nil {
+		h.handleError(c, err, "Failed to list certificates")
+		return
+	}
+
+	response.Success(c, dto.ToCertificatesResponse(certificates))
+}
+
+// ListExpiring godoc
+// @Summary      List expiring certificates
+// @Description  Get the team certificates that expire within the given number of days, soonest first. Already expired certificates are included.
+// @Tags         certificates
+// @Produce      json
+// @Security     BearerAuth
+// @Param        teamId path string true "Team ID" format(uuid)
+// @Param        days query int false "Expiry window in days (1-365)" default(30)
+// @Success      200 {object} response.Response{data=[]dto.CertificateResponse} "List of expiring certificates"
+// @Failure      400 {object} response.Response "Invalid team ID or days"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /teams/{teamId}/certificates/expiring [get]
+func (h *CertificateHandler) ListExpiring(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	teamID, err := uuid.Parse(c.Param("teamId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid team ID")
+		return
+	}
+
+	days := defaultExpiringDays
+	if raw := c.Query("days"); raw != "" {
+		days, err = strconv.Atoi(raw)
+		if err != nil || days < 1 || days > maxExpiringDays {
+			response.BadRequest(c, "days must be between 1 and 365")
+			return
+		}
+	}
+
+	certificates, err := h.certificateUseCase.ListExpiring(c.Request.Context(), userID, teamID, days)
+	if err != nil {
+		h.handleError(c, err, "Failed to list certificates")
+		return
+	}
+
+	response.Success(c, dto.ToCertificatesResponse(certificates))
+}
+
+// Upload godoc
+// @Summary      Upload certificate
+// @Description  Upload a PEM certificate chain and private key. The chain order, validity dates and key pair are verified before storing; the private key is encrypted at rest. Requires admin or owner role.
+// @Tags         certificates
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        teamId path string true "Team ID" format(uuid)
+// @Param        request body dto.CreateCertificateRequest true "Certificate data"
+// @Success      201 {object} response.Response{data=dto.CertificateResponse} "Certificate uploaded"
+// @Failure      400 {object} response.Response "Invalid request body, validation error or invalid certificate"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Requires admin or owner role"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /teams/{teamId}/certificates [post]
+func (h *CertificateHandler) Upload(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	teamID, err := uuid.Parse(c.Param("teamId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid team ID")
+		return
+	}
+
+	var req entity.CertificateCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if err := h.validator.Validate(&req); err != nil {
+		response.ValidationError(c, validator.FormatValidationErrors(err))
+		return
+	}
+
+	created, err := h.certificateUseCase.Upload(c.Request.Context(), userID, teamID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to upload certificate")
+		return
+	}
+
+	response.Created(c, dto.ToCertificateResponse(created))
+}
+
+// Get godoc
+// @Summary      Get certificate
+// @Description  Get the metadata of an uploaded certificate
+// @Tags         certificates
+// @Produce      json
+// @Security     BearerAuth
+// @Param        teamId path string true "Team ID" format(uuid)
+// @Param        certificateId path string true "Certificate ID" format(uuid)
+// @Success      200 {object} response.Response{data=dto.CertificateResponse} "Certificate details"
+// @Failure      400 {object} response.Response "Invalid team or certificate ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      404 {object} response.Response "Certificate not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /teams/{teamId}/certificates/{certificateId} [get]
+func (h *CertificateHandler) Get(c *gin.Context) {
+	userID, teamID, certificateID, ok := h.parseParams(c)
+	if !ok {
+		return
+	}
+
+	found, err := h.certificateUseCase.Get(c.Request.Context(), userID, teamID, certificateID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get certificate")
+		return
+	}
+
+	response.Success(c, dto.ToCertificateResponse(found))
+}
+
+// Update godoc
+// @Summary      Update certificate
+// @Description  Rename a certificate or replace it with a renewed one. A replacement needs both certificate and private_key and must still cover every domain using it; routes pick it up immediately. Requires admin or owner role.
+// @Tags         certificates
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        teamId path string true "Team ID" format(uuid)
+// @Param        certificateId path string true "Certificate ID" format(uuid)
+// @Param        request body dto.UpdateCertificateRequest true "Certificate update data"
+// @Success      200 {object} response.Response{data=dto.CertificateResponse} "Certificate updated"
+// @Failure      400 {object} response.Response "Invalid request body, invalid certificate or domain not covered"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Requires admin or owner role"
+// @Failure      404 {object} response.Response "Certificate not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /teams/{teamId}/certificates/{certificateId} [put]
+func (h *CertificateHandler) Update(c *gin.Context) {
+	userID, teamID, certificateID, ok := h.parseParams(c)
+	if !ok {
+		return
+	}
+
+	var req entity.CertificateUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if err := h.validator.Validate(&req); err != nil {
+		response.ValidationError(c, validator.FormatValidationErrors(err))
+		return
+	}
+
+	updated, err := h.certificateUseCase.Update(c.Request.Context(), userID, teamID, certificateID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update certificate")
+		return
+	}
+
+	response.Success(c, dto.ToCertificateResponse(updated))
+}
+
+// Delete godoc
+// @Summary      Delete certificate
+// @Description  Delete an uploaded certificate. Certificates still referenced by domains cannot be deleted. Requires admin or owner role.
+// @Tags         certificates
+// @Produce      json
+// @Security     BearerAuth
+// @Param        teamId path string true "Team ID" format(uuid)
+// @Param        certificateId path string true "Certificate ID" format(uuid)
+// @Success      204 "Certificate deleted"
+// @Failure      400 {object} response.Response "Invalid team or certificate ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Requires admin or owner role"
+// @Failure      404 {object} response.Response "Certificate not found"
+// @Failure      409 {object} response.Response "Certificate is used by domains"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /teams/{teamId}/certificates/{certificateId} [delete]
+func (h *CertificateHandler) Delete(c *gin.Context) {
+	userID, teamID, certificateID, ok := h.parseParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.certificateUseCase.Delete(c.Request.Context(), userID, teamID, certificateID); err != nil {
+		h.handleError(c, err, "Failed to delete certificate")
+		return
+	}
+
+	response.NoContent(c)
+}
+
+func (h *CertificateHandler) parseParams(c *gin.Context) (uuid.UUID, uuid.UUID, uuid.UUID, bool) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	teamID, err := uuid.Parse(c.Param("teamId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid team ID")
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	certificateID, err := uuid.Parse(c.Param("certificateId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid certificate ID")
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, teamID, certificateID, true
+}
+
+func (h *CertificateHandler) handleError(c *gin.Context, err error, failure string) {
+	switch {
+	case errors.Is(err, certificate.ErrCertificateNotFound):
+		response.NotFound(c, "Certificate not found")
+	case errors.Is(err, certificate.ErrNotTeamMember):
+		response.Forbidden(c, "Not a team member")
+	case errors.Is(err, certificate.ErrNotTeamAdmin):
+		response.Forbidden(c, "Requires admin or owner role")
+	case errors.Is(err, certificate.ErrProjectLimitedToken):
+		response.Forbidden(c, "Token is limited to a project")
+	case errors.Is(err, certificate.ErrInvalidCertificate),
+		errors.Is(err, certificate.ErrIncompleteReplacement),
+		errors.Is(err, certificate.ErrCertificateMismatch):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, certificate.ErrCertificateInUse):
+		response.Conflict(c, "Certificate is used by domains")
+	default:
+		response.InternalError(c, failure)
+	}
+}
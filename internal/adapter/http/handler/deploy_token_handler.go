@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/adapter/http/dto"
+	"github.com/podoru/spinner-podoru/internal/adapter/http/middleware"
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/usecase/deploytoken"
+	"github.com/podoru/spinner-podoru/pkg/response"
+	"github.com/podoru/spinner-podoru/pkg/validator"
+)
+
+type DeployTokenHandler struct {
+	deployTokenUseCase *deploytoken.UseCase
+	validator          *validator.Validator
+}
+
+func NewDeployTokenHandler(deployTokenUseCase *deploytoken.UseCase, validator *validator.Validator) *DeployTokenHandler {
+	return &DeployTokenHandler{
+		deployTokenUseCase: deployTokenUseCase,
+		validator:          validator,
+	}
+}
+
+// List godoc
+// @Summary      List deploy tokens
+// @Description  Get the project's deploy tokens, revoked and expired ones included
+// @Tags         projects
+// @Produce      json
+// @Security     BearerAuth
+// @Param        projectId path string true "Project ID" format(uuid)
+// @Success      200 {object} response.Response{data=[]dto.DeployTokenResponse} "List of deploy tokens"
+// @Failure      400 {object} response.Response "Invalid project ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      404 {object} response.Response "Project not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /projects/{projectId}/deploy-tokens [get]
+func (h *DeployTokenHandler) List(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid project ID")
+		return
+	}
+
+	tokens, err := h.deployTokenUseCase.List(c.Request.Context(), userID, projectID)
+	if err != nil {
+		handleDeployTokenError(c, err, "Failed to list deploy tokens")
+		return
+	}
+
+	response.Success(c, dto.ToDeployTokensResponse(tokens))
+}
+
+// Create godoc
+// @Summary      Create deploy token
+// @Description  Issue a deploy token that belongs to the project rather than a person. It may only deploy, start, stop and restart the project's services, read their logs and call the deploy hook. The token is only returned in this response. Requires admin or owner role.
+// @Tags         projects
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        projectId path string true "Project ID" format(uuid)
+// @Param        request body dto.CreateDeployTokenRequest true "Token data"
+// @Success      201 {object} response.Response{data=dto.CreatedDeployTokenResponse} "Token created"
+// @Failure      400 {object} response.Response "Invalid project ID, request body or expiry"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member or insufficient role"
+// @Failure      404 {object} response.Response "Project not found"
+// @Failure      422 {object} response.Response "Validation error"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /projects/{projectId}/deploy-tokens [post]
+func (h *DeployTokenHandler) Create(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid project ID")
+		return
+	}
+
+	var req entity.DeployTokenCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if err := h.validator.Validate(&req); err != nil {
+		response.ValidationError(c, validator.FormatValidationErrors(err))
+		return
+	}
+
+	token, plaintext, err := h.deployTokenUseCase.Create(c.Request.Context(), userID, projectID, &req)
+	if err != nil {
+		handleDeployTokenError(c, err, "Failed to create deploy token")
+		return
+	}
+
+	response.Created(c, dto.CreatedDeployTokenResponse{
+		DeployTokenResponse: dto.ToDeployTokenResponse(token),
+		Token:               plaintext,
+	})
+}
+
+// Revoke godoc
+// @Summary      Revoke deploy token
+// @Description  Stop one of the project's deploy tokens from working. Requires admin or owner role.
+// @Tags         projects
+// @Produce      json
+// @Security     BearerAuth
+// @Param        projectId path string true "Project ID" format(uuid)
+// @Param        tokenId path string true "Token ID" format(uuid)
+// @Success      204 "Token revoked"
+// @Failure      400 {object} response.Response "Invalid project or token ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member or insufficient role"
+// @Failure      404 {object} response.Response "Project or deploy token not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /projects/{projectId}/deploy-tokens/{tokenId} [delete]
+func (h *DeployTokenHandler) Revoke(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid project ID")
+		return
+	}
+
+	tokenID, err := uuid.Parse(c.Param("tokenId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid token ID")
+		return
+	}
+
+	if err := h.deployTokenUseCase.Revoke(c.Request.Context(), userID, projectID, tokenID); err != nil {
+		handleDeployTokenError(c, err, "Failed to revoke deploy token")
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// handleDeployTokenError maps deploy token errors to responses, falling back
+// to an internal error with the failure message
+func handleDeployTokenError(c *gin.Context, err error, failure string) {
+	switch {
+	case errors.Is(err, deploytoken.ErrProjectNotFound):
+		response.NotFound(c, "Project not found")
+	case errors.Is(err, deploytoken.ErrDeployTokenNotFound):
+		response.NotFound(c, "Deploy token not found")
+	case errors.Is(err, deploytoken.ErrNotTeamMember):
+		response.Forbidden(c, "Not a team member")
+	case errors.Is(err, deploytoken.ErrNotTeamAdmin):
+		response.Forbidden(c, "Requires admin or owner role")
+	case errors.Is(err, deploytoken.ErrInvalidExpiry):
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalError(c, failure)
+	}
+}
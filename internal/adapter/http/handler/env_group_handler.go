@@ -0,0 +1,431 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/adapter/http/dto"
+	"github.com/podoru/spinner-podoru/internal/adapter/http/middleware"
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/usecase/envgroup"
+	"github.com/podoru/spinner-podoru/pkg/response"
+	"github.com/podoru/spinner-podoru/pkg/validator"
+)
+
+type EnvGroupHandler struct {
+	envGroupUseCase *envgroup.UseCase
+	validator       *validator.Validator
+}
+
+func NewEnvGroupHandler(envGroupUseCase *envgroup.UseCase, validator *validator.Validator) *EnvGroupHandler {
+	return &EnvGroupHandler{
+		envGroupUseCase: envGroupUseCase,
+		validator:       validator,
+	}
+}
+
+// ListByTeam godoc
+// @Summary      List team env groups
+// @Description  Get the team-level env groups of a team. Only variable names are returned.
+// @Tags         env-groups
+// @Produce      json
+// @Security     BearerAuth
+// @Param        teamId path string true "Team ID" format(uuid)
+// @Success      200 {object} response.Response{data=[]dto.EnvGroupResponse} "List of env groups"
+// @Failure      400 {object} response.Response "Invalid team ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /teams/{teamId}/env-groups [get]
+func (h *EnvGroupHandler) ListByTeam(c *gin.Context) {
+	h.list(c, "teamId", "Invalid team ID", h.envGroupUseCase.ListByTeam)
+}
+
+// ListByProject godoc
+// @Summary      List project env groups
+// @Description  Get the env groups of a project. Only variable names are returned.
+// @Tags         env-groups
+// @Produce      json
+// @Security     BearerAuth
+// @Param        projectId path string true "Project ID" format(uuid)
+// @Success      200 {object} response.Response{data=[]dto.EnvGroupResponse} "List of env groups"
+// @Failure      400 {object} response.Response "Invalid project ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      404 {object} response.Response "Project not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /projects/{projectId}/env-groups [get]
+func (h *EnvGroupHandler) ListByProject(c *gin.Context) {
+	h.list(c, "projectId", "Invalid project ID", h.envGroupUseCase.ListByProject)
+}
+
+// ListByService godoc
+// @Summary      List service env groups
+// @Description  Get the env groups attached to a service, team groups first, in increasing precedence.
+// @Tags         env-groups
+// @Produce      json
+// @Security     BearerAuth
+// @Param        serviceId path string true "Service ID" format(uuid)
+// @Success      200 {object} response.Response{data=[]dto.EnvGroupResponse} "List of env groups"
+// @Failure      400 {object} response.Response "Invalid service ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      404 {object} response.Response "Service not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /services/{serviceId}/env-groups [get]
+func (h *EnvGroupHandler) ListByService(c *gin.Context) {
+	h.list(c, "serviceId", "Invalid service ID", h.envGroupUseCase.ListByService)
+}
+
+type listEnvGroupsFunc func(ctx context.Context, userID, id uuid.UUID) ([]entity.EnvGroup, error)
+
+func (h *EnvGroupHandler) list(c *gin.Context, param, invalid string, fn listEnvGroupsFunc) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		response.BadRequest(c, invalid)
+		return
+	}
+
+	groups, err := fn(c.Request.Context(), userID, id)
+	if err != nil {
+		h.handleError(c, err, "Failed to list env groups")
+		return
+	}
+
+	response.Success(c, dto.ToEnvGroupsResponse(groups))
+}
+
+// CreateForTeam godoc
+// @Summary      Create team env group
+// @Description  Create an env group any service of the team can be attached to. Requires admin or owner role.
+// @Tags         env-groups
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        teamId path string true "Team ID" format(uuid)
+// @Param        request body dto.CreateEnvGroupRequest true "Env group data"
+// @Success      201 {object} response.Response{data=dto.EnvGroupResponse} "Env group created"
+// @Failure      400 {object} response.Response "Invalid request body or variable name"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Requires admin or owner role"
+// @Failure      409 {object} response.Response "Env group name already exists"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /teams/{teamId}/env-groups [post]
+func (h *EnvGroupHandler) CreateForTeam(c *gin.Context) {
+	h.create(c, "teamId", "Invalid team ID", h.envGroupUseCase.CreateForTeam)
+}
+
+// CreateForProject godoc
+// @Summary      Create project env group
+// @Description  Create an env group the services of the project can be attached to. Requires admin or owner role.
+// @Tags         env-groups
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        projectId path string true "Project ID" format(uuid)
+// @Param        request body dto.CreateEnvGroupRequest true "Env group data"
+// @Success      201 {object} response.Response{data=dto.EnvGroupResponse} "Env group created"
+// @Failure      400 {object} response.Response "Invalid request body or variable name"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Requires admin or owner role"
+// @Failure      404 {object} response.Response "Project not found"
+// @Failure      409 {object} response.Response "Env group name already exists"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /projects/{projectId}/env-groups [post]
+func (h *EnvGroupHandler) CreateForProject(c *gin.Context) {
+	h.create(c, "projectId", "Invalid project ID", h.envGroupUseCase.CreateForProject)
+}
+
+type createEnvGroupFunc func(ctx context.Context, userID, id uuid.UUID, input *entity.EnvGroupCreate) (*entity.EnvGroup, error)
+
+func (h *EnvGroupHandler) create(c *gin.Context, param, invalid string, fn createEnvGroupFunc) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		response.BadRequest(c, invalid)
+		return
+	}
+
+	var req entity.EnvGroupCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if err := h.validator.Validate(&req); err != nil {
+		response.ValidationError(c, validator.FormatValidationErrors(err))
+		return
+	}
+
+	created, err := fn(c.Request.Context(), userID, id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create env group")
+		return
+	}
+
+	response.Created(c, dto.ToEnvGroupResponse(created))
+}
+
+// Get godoc
+// @Summary      Get env group
+// @Description  Get an env group with its variable names
+// @Tags         env-groups
+// @Produce      json
+// @Security     BearerAuth
+// @Param        groupId path string true "Env group ID" format(uuid)
+// @Success      200 {object} response.Response{data=dto.EnvGroupResponse} "Env group details"
+// @Failure      400 {object} response.Response "Invalid env group ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      404 {object} response.Response "Env group not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /env-groups/{groupId} [get]
+func (h *EnvGroupHandler) Get(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	groupID, err := uuid.Parse(c.Param("groupId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid env group ID")
+		return
+	}
+
+	group, err := h.envGroupUseCase.Get(c.Request.Context(), userID, groupID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get env group")
+		return
+	}
+
+	response.Success(c, dto.ToEnvGroupResponse(group))
+}
+
+// Update godoc
+// @Summary      Update env group
+// @Description  Rename an env group or replace its variables. Attached services pick up the change on their next deploy. Requires admin or owner role.
+// @Tags         env-groups
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        groupId path string true "Env group ID" format(uuid)
+// @Param        request body dto.UpdateEnvGroupRequest true "Env group update data"
+// @Success      200 {object} response.Response{data=dto.EnvGroupResponse} "Env group updated"
+// @Failure      400 {object} response.Response "Invalid request body or variable name"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Requires admin or owner role"
+// @Failure      404 {object} response.Response "Env group not found"
+// @Failure      409 {object} response.Response "Env group name already exists"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /env-groups/{groupId} [put]
+func (h *EnvGroupHandler) Update(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	groupID, err := uuid.Parse(c.Param("groupId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid env group ID")
+		return
+	}
+
+	var req entity.EnvGroupUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if err := h.validator.Validate(&req); err != nil {
+		response.ValidationError(c, validator.FormatValidationErrors(err))
+		return
+	}
+
+	updated, err := h.envGroupUseCase.Update(c.Request.Context(), userID, groupID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update env group")
+		return
+	}
+
+	response.Success(c, dto.ToEnvGroupResponse(updated))
+}
+
+// Delete godoc
+// @Summary      Delete env group
+// @Description  Delete an env group no service is attached to. Requires admin or owner role.
+// @Tags         env-groups
+// @Produce      json
+// @Security     BearerAuth
+// @Param        groupId path string true "Env group ID" format(uuid)
+// @Success      204 "Env group deleted"
+// @Failure      400 {object} response.Response "Invalid env group ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Requires admin or owner role"
+// @Failure      404 {object} response.Response "Env group not found"
+// @Failure      409 {object} response.Response "Env group is attached to services"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /env-groups/{groupId} [delete]
+func (h *EnvGroupHandler) Delete(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	groupID, err := uuid.Parse(c.Param("groupId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid env group ID")
+		return
+	}
+
+	if err := h.envGroupUseCase.Delete(c.Request.Context(), userID, groupID); err != nil {
+		h.handleError(c, err, "Failed to delete env group")
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// AttachService godoc
+// @Summary      Attach env group to service
+// @Description  Add an env group's variables to a service from its next deploy. Team groups attach to any service of the team, project groups to services of their project.
+// @Tags         env-groups
+// @Produce      json
+// @Security     BearerAuth
+// @Param        serviceId path string true "Service ID" format(uuid)
+// @Param        groupId path string true "Env group ID" format(uuid)
+// @Success      204 "Env group attached"
+// @Failure      400 {object} response.Response "Invalid ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      404 {object} response.Response "Service or env group not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /services/{serviceId}/env-groups/{groupId} [post]
+func (h *EnvGroupHandler) AttachService(c *gin.Context) {
+	h.changeAttachment(c, h.envGroupUseCase.AttachService, "Failed to attach env group")
+}
+
+// DetachService godoc
+// @Summary      Detach env group from service
+// @Description  Remove an env group's variables from a service from its next deploy
+// @Tags         env-groups
+// @Produce      json
+// @Security     BearerAuth
+// @Param        serviceId path string true "Service ID" format(uuid)
+// @Param        groupId path string true "Env group ID" format(uuid)
+// @Success      204 "Env group detached"
+// @Failure      400 {object} response.Response "Invalid ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      404 {object} response.Response "Service or env group not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /services/{serviceId}/env-groups/{groupId} [delete]
+func (h *EnvGroupHandler) DetachService(c *gin.Context) {
+	h.changeAttachment(c, h.envGroupUseCase.DetachService, "Failed to detach env group")
+}
+
+type envGroupAttachmentFunc func(ctx context.Context, userID, serviceID, groupID uuid.UUID) error
+
+func (h *EnvGroupHandler) changeAttachment(c *gin.Context, fn envGroupAttachmentFunc, failure string) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	serviceID, err := uuid.Parse(c.Param("serviceId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid service ID")
+		return
+	}
+
+	groupID, err := uuid.Parse(c.Param("groupId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid env group ID")
+		return
+	}
+
+	if err := fn(c.Request.Context(), userID, serviceID, groupID); err != nil {
+		h.handleError(c, err, failure)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// Preview godoc
+// @Summary      Preview service env
+// @Description  Resolve the env a service would be deployed with. Service variables override project groups, which override team groups. Each key shows the layer it comes from and the layers it overrides; values are masked.
+// @Tags         env-groups
+// @Produce      json
+// @Security     BearerAuth
+// @Param        serviceId path string true "Service ID" format(uuid)
+// @Success      200 {object} response.Response{data=[]dto.ResolvedEnvVarResponse} "Resolved env"
+// @Failure      400 {object} response.Response "Invalid service ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      404 {object} response.Response "Service not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /services/{serviceId}/env [get]
+func (h *EnvGroupHandler) Preview(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	serviceID, err := uuid.Parse(c.Param("serviceId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid service ID")
+		return
+	}
+
+	resolved, err := h.envGroupUseCase.Preview(c.Request.Context(), userID, serviceID)
+	if err != nil {
+		h.handleError(c, err, "Failed to resolve env")
+		return
+	}
+
+	response.Success(c, dto.ToResolvedEnvResponse(resolved))
+}
+
+func (h *EnvGroupHandler) handleError(c *gin.Context, err error, failure string) {
+	switch {
+	case errors.Is(err, envgroup.ErrEnvGroupNotFound):
+		response.NotFound(c, "Env group not found")
+	case errors.Is(err, envgroup.ErrProjectNotFound):
+		response.NotFound(c, "Project not found")
+	case errors.Is(err, envgroup.ErrServiceNotFound):
+		response.NotFound(c, "Service not found")
+	case errors.Is(err, envgroup.ErrNotTeamMember):
+		response.Forbidden(c, "Not a team member")
+	case errors.Is(err, envgroup.ErrNotTeamAdmin):
+		response.Forbidden(c, "Requires admin or owner role")
+	case errors.Is(err, envgroup.ErrProjectLimitedToken):
+		response.Forbidden(c, "Token is limited to a project")
+	case errors.Is(err, envgroup.ErrEnvGroupNameTaken):
+		response.Conflict(c, "Env group name already exists")
+	case errors.Is(err, envgroup.ErrEnvGroupInUse):
+		response.Conflict(c, "Env group is attached to services")
+	case errors.Is(err, envgroup.ErrInvalidVarName):
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalError(c, failure)
+	}
+}
@@ -0,0 +1,385 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/podoru/spinner-podoru/internal/adapter/http/dto"
+	"github.com/podoru/spinner-podoru/internal/adapter/http/middleware"
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/usecase/auth"
+	"github.com/podoru/spinner-podoru/internal/usecase/mfa"
+	"github.com/podoru/spinner-podoru/pkg/response"
+	"github.com/podoru/spinner-podoru/pkg/validator"
+)
+
+type MFAHandler struct {
+	mfaUseCase  *mfa.UseCase
+	authUseCase *auth.UseCase
+	validator   *validator.Validator
+}
+
+func NewMFAHandler(mfaUseCase *mfa.UseCase, authUseCase *auth.UseCase, validator *validator.Validator) *MFAHandler {
+	return &MFAHandler{
+		mfaUseCase:  mfaUseCase,
+		authUseCase: authUseCase,
+		validator:   validator,
+	}
+}
+
+// Verify godoc
+// @Summary      Finish login with a second factor
+// @Description  Finish a login that returned an MFA token, with a code from the user's authenticator or one of their recovery codes. For a user the policy required to enrol, the code also confirms the new authenticator. Five wrong codes end the login.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.MFAVerifyRequest true "MFA token and code"
+// @Success      200 {object} response.Response{data=dto.AuthResponse} "Login successful"
+// @Failure      400 {object} response.Response "Invalid request body"
+// @Failure      401 {object} response.Response "Invalid code, or invalid or expired MFA token"
+// @Failure      403 {object} response.Response "Account is inactive"
+// @Failure      422 {object} response.Response "Validation error"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /auth/mfa/verify [post]
+func (h *MFAHandler) Verify(c *gin.Context) {
+	var req dto.MFAVerifyRequest
+	if !h.bind(c, &req) {
+		return
+	}
+
+	user, tokens, err := h.authUseCase.VerifyMFA(clientContext(c), req.MFAToken, req.Code, req.RecoveryCode)
+	if err != nil {
+		respondMFAError(c, err, "Failed to verify code")
+		return
+	}
+
+	response.Success(c, dto.ToAuthResponse(user, tokens))
+}
+
+// Enroll godoc
+// @Summary      Set up two-factor during login
+// @Description  For a login whose MFA token says enrollment_required, create an authenticator secret and recovery codes. Add the secret to an authenticator app, e.g. by showing provisioning_uri as a QR code, then finish the login at /auth/mfa/verify with a code from it.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.MFATokenRequest true "MFA token"
+// @Success      200 {object} response.Response{data=dto.TOTPEnrollmentResponse} "Authenticator secret and recovery codes"
+// @Failure      400 {object} response.Response "Invalid request body"
+// @Failure      401 {object} response.Response "Invalid or expired MFA token"
+// @Failure      409 {object} response.Response "Two-factor authentication is already enabled"
+// @Failure      422 {object} response.Response "Validation error"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /auth/mfa/enroll [post]
+func (h *MFAHandler) Enroll(c *gin.Context) {
+	var req dto.MFATokenRequest
+	if !h.bind(c, &req) {
+		return
+	}
+
+	enrollment, err := h.mfaUseCase.EnrollForLogin(c.Request.Context(), req.MFAToken)
+	if err != nil {
+		respondMFAError(c, err, "Failed to set up two-factor authentication")
+		return
+	}
+
+	response.Success(c, dto.ToTOTPEnrollmentResponse(enrollment))
+}
+
+// Recover godoc
+// @Summary      Reset two-factor with a recovery code
+// @Description  For a user who lost their authenticator: use a recovery code to remove it and their other recovery codes, then finish the login. If the policy requires two-factor, a new MFA token to enrol again is returned instead of tokens.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.MFARecoverRequest true "MFA token and recovery code"
+// @Success      200 {object} response.Response{data=dto.AuthResponse} "Login successful"
+// @Success      202 {object} response.Response{data=dto.MFAPendingLoginResponse} "Enrolment required"
+// @Failure      400 {object} response.Response "Invalid request body"
+// @Failure      401 {object} response.Response "Invalid recovery code, or invalid or expired MFA token"
+// @Failure      403 {object} response.Response "Account is inactive"
+// @Failure      422 {object} response.Response "Validation error"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /auth/mfa/recover [post]
+func (h *MFAHandler) Recover(c *gin.Context) {
+	var req dto.MFARecoverRequest
+	if !h.bind(c, &req) {
+		return
+	}
+
+	user, tokens, pending, err := h.authUseCase.ResetMFA(clientContext(c), req.MFAToken, req.RecoveryCode)
+	if err != nil {
+		respondMFAError(c, err, "Failed to reset two-factor authentication")
+		return
+	}
+
+	if pending != nil {
+		respondMFAPending(c, pending)
+		return
+	}
+
+	response.Success(c, dto.ToAuthResponse(user, tokens))
+}
+
+// Status godoc
+// @Summary      Get two-factor status
+// @Description  Report whether the current user has two-factor authentication, how many recovery codes are left, and whether the policy requires it
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} response.Response{data=dto.MFAStatusResponse} "Two-factor status"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /users/me/mfa [get]
+func (h *MFAHandler) Status(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	status, err := h.mfaUseCase.Status(c.Request.Context(), userID)
+	if err != nil {
+		respondMFAError(c, err, "Failed to get two-factor status")
+		return
+	}
+
+	response.Success(c, dto.ToMFAStatusResponse(status))
+}
+
+// StartEnrollment godoc
+// @Summary      Set up two-factor
+// @Description  Create an authenticator secret and recovery codes for the current user. Add the secret to an authenticator app, e.g. by showing provisioning_uri as a QR code, then confirm it with a code. Starting again replaces an unconfirmed secret. Requires a login session, not an API token.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} response.Response{data=dto.TOTPEnrollmentResponse} "Authenticator secret and recovery codes"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not allowed with an API token"
+// @Failure      409 {object} response.Response "Two-factor authentication is already enabled"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /users/me/mfa/totp [post]
+func (h *MFAHandler) StartEnrollment(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	enrollment, err := h.mfaUseCase.StartEnrollment(c.Request.Context(), userID)
+	if err != nil {
+		respondMFAError(c, err, "Failed to set up two-factor authentication")
+		return
+	}
+
+	response.Success(c, dto.ToTOTPEnrollmentResponse(enrollment))
+}
+
+// ConfirmEnrollment godoc
+// @Summary      Confirm two-factor setup
+// @Description  Turn two-factor authentication on with a code from the new authenticator. Requires a login session, not an API token.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body dto.MFACodeRequest true "Code from the authenticator"
+// @Success      204 "Two-factor authentication enabled"
+// @Failure      400 {object} response.Response "Invalid request body or setup not started"
+// @Failure      401 {object} response.Response "User not authenticated or invalid code"
+// @Failure      403 {object} response.Response "Not allowed with an API token"
+// @Failure      409 {object} response.Response "Two-factor authentication is already enabled"
+// @Failure      422 {object} response.Response "Validation error"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /users/me/mfa/totp/confirm [post]
+func (h *MFAHandler) ConfirmEnrollment(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.MFACodeRequest
+	if !h.bind(c, &req) {
+		return
+	}
+
+	if err := h.mfaUseCase.ConfirmEnrollment(c.Request.Context(), userID, req.Code); err != nil {
+		respondMFAError(c, err, "Failed to enable two-factor authentication")
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// Disable godoc
+// @Summary      Turn two-factor off
+// @Description  Remove the current user's authenticator and recovery codes, given a code from the authenticator or a recovery code. Not allowed when the policy requires two-factor from the user. Requires a login session, not an API token.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body dto.MFADisableRequest true "Code or recovery code"
+// @Success      204 "Two-factor authentication disabled"
+// @Failure      400 {object} response.Response "Invalid request body or two-factor not set up"
+// @Failure      401 {object} response.Response "User not authenticated or invalid code"
+// @Failure      403 {object} response.Response "Required by policy, or not allowed with an API token"
+// @Failure      422 {object} response.Response "Validation error"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /users/me/mfa/disable [post]
+func (h *MFAHandler) Disable(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.MFADisableRequest
+	if !h.bind(c, &req) {
+		return
+	}
+
+	if err := h.mfaUseCase.Disable(c.Request.Context(), userID, req.Code, req.RecoveryCode); err != nil {
+		respondMFAError(c, err, "Failed to disable two-factor authentication")
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary      Replace recovery codes
+// @Description  Replace the current user's recovery codes with new ones, given a code from the authenticator. The new codes are only returned in this response. Requires a login session, not an API token.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body dto.MFACodeRequest true "Code from the authenticator"
+// @Success      200 {object} response.Response{data=dto.RecoveryCodesResponse} "New recovery codes"
+// @Failure      400 {object} response.Response "Invalid request body or two-factor not set up"
+// @Failure      401 {object} response.Response "User not authenticated or invalid code"
+// @Failure      403 {object} response.Response "Not allowed with an API token"
+// @Failure      422 {object} response.Response "Validation error"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /users/me/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.MFACodeRequest
+	if !h.bind(c, &req) {
+		return
+	}
+
+	codes, err := h.mfaUseCase.RegenerateRecoveryCodes(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		respondMFAError(c, err, "Failed to replace recovery codes")
+		return
+	}
+
+	response.Success(c, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// GetPolicy godoc
+// @Summary      Get two-factor policy
+// @Description  Get who must use two-factor authentication: none, owners (users who own a team) or all. Requires the superadmin role.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} response.Response{data=dto.MFAPolicyResponse} "Two-factor policy"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Requires superadmin role"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /admin/mfa-policy [get]
+func (h *MFAHandler) GetPolicy(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	policy, err := h.mfaUseCase.GetPolicy(c.Request.Context(), userID)
+	if err != nil {
+		respondMFAError(c, err, "Failed to get two-factor policy")
+		return
+	}
+
+	response.Success(c, dto.ToMFAPolicyResponse(policy))
+}
+
+// UpdatePolicy godoc
+// @Summary      Update two-factor policy
+// @Description  Set who must use two-factor authentication: none, owners (users who own a team) or all. Users it covers without an authenticator are asked to set one up at their next login. Requires the superadmin role.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body dto.UpdateMFAPolicyRequest true "Policy"
+// @Success      200 {object} response.Response{data=dto.MFAPolicyResponse} "Two-factor policy"
+// @Failure      400 {object} response.Response "Invalid request body"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Requires superadmin role"
+// @Failure      422 {object} response.Response "Validation error"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /admin/mfa-policy [put]
+func (h *MFAHandler) UpdatePolicy(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.UpdateMFAPolicyRequest
+	if !h.bind(c, &req) {
+		return
+	}
+
+	policy, err := h.mfaUseCase.UpdatePolicy(c.Request.Context(), userID, req.ToEntity())
+	if err != nil {
+		respondMFAError(c, err, "Failed to update two-factor policy")
+		return
+	}
+
+	response.Success(c, dto.ToMFAPolicyResponse(policy))
+}
+
+func (h *MFAHandler) bind(c *gin.Context, req any) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return false
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		response.ValidationError(c, validator.FormatValidationErrors(err))
+		return false
+	}
+	return true
+}
+
+// respondMFAPending answers a login that needs a second factor
+func respondMFAPending(c *gin.Context, pending *entity.MFAPendingLogin) {
+	response.Accepted(c, dto.ToMFAPendingLoginResponse(pending))
+}
+
+func respondMFAError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, mfa.ErrInvalidChallenge):
+		response.Unauthorized(c, "Invalid or expired MFA token")
+	case errors.Is(err, mfa.ErrInvalidCode):
+		response.Unauthorized(c, "Invalid two-factor code")
+	case errors.Is(err, mfa.ErrNotEnrolled):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, mfa.ErrAlreadyEnabled):
+		response.Conflict(c, err.Error())
+	case errors.Is(err, mfa.ErrRequiredByPolicy), errors.Is(err, mfa.ErrNotSuperAdmin):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, auth.ErrUserInactive):
+		response.Forbidden(c, "Account is inactive")
+	case errors.Is(err, mfa.ErrUserNotFound), errors.Is(err, auth.ErrUserNotFound):
+		response.NotFound(c, "User not found")
+	default:
+		response.InternalError(c, fallback)
+	}
+}
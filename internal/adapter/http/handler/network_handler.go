@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/adapter/http/dto"
+	"github.com/podoru/spinner-podoru/internal/adapter/http/middleware"
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/usecase/network"
+	"github.com/podoru/spinner-podoru/pkg/response"
+	"github.com/podoru/spinner-podoru/pkg/validator"
+)
+
+type NetworkHandler struct {
+	networkUseCase *network.UseCase
+	validator      *validator.Validator
+}
+
+func NewNetworkHandler(networkUseCase *network.UseCase, validator *validator.Validator) *NetworkHandler {
+	return &NetworkHandler{
+		networkUseCase: networkUseCase,
+		validator:      validator,
+	}
+}
+
+// List godoc
+// @Summary      List project networks
+// @Description  Get the networks of a project and the services attached to each. Every service joins the default network on deploy.
+// @Tags         networks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        projectId path string true "Project ID" format(uuid)
+// @Success      200 {object} response.Response{data=[]dto.NetworkResponse} "List of networks"
+// @Failure      400 {object} response.Response "Invalid project ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      404 {object} response.Response "Project not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /projects/{projectId}/networks [get]
+func (h *NetworkHandler) List(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid project ID")
+		return
+	}
+
+	networks, err := h.networkUseCase.List(c.Request.Context(), userID, projectID)
+	if err != nil {
+		h.handleError(c, err, "Failed to list networks")
+		return
+	}
+
+	response.Success(c, dto.ToNetworksResponse(networks))
+}
+
+// Create godoc
+// @Summary      Create project network
+// @Description  Create a custom network in a project. Requires admin or owner role.
+// @Tags         networks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        projectId path string true "Project ID" format(uuid)
+// @Param        request body dto.CreateNetworkRequest true "Network data"
+// @Success      201 {object} response.Response{data=dto.NetworkResponse} "Network created"
+// @Failure      400 {object} response.Response "Invalid request body or validation error"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Requires admin or owner role"
+// @Failure      404 {object} response.Response "Project not found"
+// @Failure      409 {object} response.Response "Network name already exists"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /projects/{projectId}/networks [post]
+func (h *NetworkHandler) Create(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid project ID")
+		return
+	}
+
+	var req entity.NetworkCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if err := h.validator.Validate(&req); err != nil {
+		response.ValidationError(c, validator.FormatValidationErrors(err))
+		return
+	}
+
+	created, err := h.networkUseCase.Create(c.Request.Context(), userID, projectID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create network")
+		return
+	}
+
+	response.Created(c, dto.ToNetworkResponse(created))
+}
+
+// Delete godoc
+// @Summary      Delete project network
+// @Description  Delete a custom network, disconnecting attached containers. Requires admin or owner role.
+// @Tags         networks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        projectId path string true "Project ID" format(uuid)
+// @Param        networkId path string true "Network ID" format(uuid)
+// @Success      204 "Network deleted"
+// @Failure      400 {object} response.Response "Invalid ID or default network"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Requires admin or owner role"
+// @Failure      404 {object} response.Response "Project or network not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /projects/{projectId}/networks/{networkId} [delete]
+func (h *NetworkHandler) Delete(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid project ID")
+		return
+	}
+
+	networkID, err := uuid.Parse(c.Param("networkId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid network ID")
+		return
+	}
+
+	if err := h.networkUseCase.Delete(c.Request.Context(), userID, projectID, networkID); err != nil {
+		h.handleError(c, err, "Failed to delete network")
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// AttachService godoc
+// @Summary      Attach service to network
+// @Description  Join a service to a custom network under its slug. Running containers are connected immediately.
+// @Tags         networks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        projectId path string true "Project ID" format(uuid)
+// @Param        networkId path string true "Network ID" format(uuid)
+// @Param        serviceId path string true "Service ID" format(uuid)
+// @Success      204 "Service attached"
+// @Failure      400 {object} response.Response "Invalid ID or default network"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      404 {object} response.Response "Project, network or service not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /projects/{projectId}/networks/{networkId}/services/{serviceId} [post]
+func (h *NetworkHandler) AttachService(c *gin.Context) {
+	h.changeAttachment(c, h.networkUseCase.AttachService, "Failed to attach service")
+}
+
+// DetachService godoc
+// @Summary      Detach service from network
+// @Description  Remove a service from a custom network. Running containers are disconnected immediately.
+// @Tags         networks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        projectId path string true "Project ID" format(uuid)
+// @Param        networkId path string true "Network ID" format(uuid)
+// @Param        serviceId path string true "Service ID" format(uuid)
+// @Success      204 "Service detached"
+// @Failure      400 {object} response.Response "Invalid ID or default network"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      404 {object} response.Response "Project, network or service not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /projects/{projectId}/networks/{networkId}/services/{serviceId} [delete]
+func (h *NetworkHandler) DetachService(c *gin.Context) {
+	h.changeAttachment(c, h.networkUseCase.DetachService, "Failed to detach service")
+}
+
+type attachmentFunc func(ctx context.Context, userID, projectID, networkID, serviceID uuid.UUID) error
+
+func (h *NetworkHandler) changeAttachment(c *gin.Context, fn attachmentFunc, failure string) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid project ID")
+		return
+	}
+
+	networkID, err := uuid.Parse(c.Param("networkId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid network ID")
+		return
+	}
+
+	serviceID, err := uuid.Parse(c.Param("serviceId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid service ID")
+		return
+	}
+
+	if err := fn(c.Request.Context(), userID, projectID, networkID, serviceID); err != nil {
+		h.handleError(c, err, failure)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+func (h *NetworkHandler) handleError(c *gin.Context, err error, failure string) {
+	switch {
+	case errors.Is(err, network.ErrProjectNotFound):
+		response.NotFound(c, "Project not found")
+	case errors.Is(err, network.ErrNetworkNotFound):
+		response.NotFound(c, "Network not found")
+	case errors.Is(err, network.ErrServiceNotFound):
+		response.NotFound(c, "Service not found")
+	case errors.Is(err, network.ErrNotTeamMember):
+		response.Forbidden(c, "Not a team member")
+	case errors.Is(err, network.ErrNotTeamAdmin):
+		response.Forbidden(c, "Requires admin or owner role")
+	case errors.Is(err, network.ErrNetworkNameTaken):
+		response.Conflict(c, "Network name already exists")
+	case errors.Is(err, network.ErrDefaultNetwork):
+		response.BadRequest(c, "The default project network cannot be changed")
+	case errors.Is(err, network.ErrGatewayWithoutSubnet):
+		response.BadRequest(c, "Gateway requires a subnet")
+	default:
+		response.InternalError(c, failure)
+	}
+}
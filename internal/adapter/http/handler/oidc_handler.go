@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/podoru/spinner-podoru/internal/usecase/sso"
+	"github.com/podoru/spinner-podoru/pkg/response"
+)
+
+const (
+	// oidcStateCookie ties a sign-in to the browser that started it
+	oidcStateCookie = "podoru_oidc_state"
+	oidcCookiePath  = "/api/v1/auth/oidc"
+)
+
+type OIDCHandler struct {
+	ssoUseCase  *sso.UseCase
+	frontendURL string
+}
+
+func NewOIDCHandler(ssoUseCase *sso.UseCase, frontendURL string) *OIDCHandler {
+	return &OIDCHandler{
+		ssoUseCase:  ssoUseCase,
+		frontendURL: frontendURL,
+	}
+}
+
+// Login godoc
+// @Summary      Start single sign-on
+// @Description  Redirect to the OpenID Connect provider to sign in and set the podoru_oidc_state cookie. The provider redirects back to the callback endpoint.
+// @Tags         auth
+// @Success      302 "Redirect to the identity provider"
+// @Failure      502 {object} response.Response "Identity provider unavailable"
+// @Router       /auth/oidc/login [get]
+func (h *OIDCHandler) Login(c *gin.Context) {
+	authURL, state, err := h.ssoUseCase.LoginURL(c.Request.Context())
+	if err != nil {
+		response.BadGateway(c, "Identity provider unavailable")
+		return
+	}
+
+	setStateCookie(c, state, int(sso.StateExpiry.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback godoc
+// @Summary      Finish single sign-on
+// @Description  Where the OpenID Connect provider redirects after sign-in. The state must match the podoru_oidc_state cookie set by the login endpoint. The user is created on first sign-in and their team memberships follow the configured group mappings. Redirects to the frontend with access_token, refresh_token, expires_in and token_type in the URL fragment.
+// @Tags         auth
+// @Produce      json
+// @Param        code query string true "Authorization code"
+// @Param        state query string true "State from the login redirect"
+// @Success      302 "Redirect to the frontend with the tokens"
+// @Failure      401 {object} response.Response "Sign-in failed, expired or started in another browser"
+// @Failure      403 {object} response.Response "Account is inactive"
+// @Failure      409 {object} response.Response "Email belongs to an account that cannot be linked"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /auth/oidc/callback [get]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	if c.Query("error") != "" {
+		response.Unauthorized(c, "Sign-in was denied by the identity provider")
+		return
+	}
+
+	// The state is single use, so the cookie is cleared whatever the outcome
+	browserState, _ := c.Cookie(oidcStateCookie)
+	setStateCookie(c, "", -1)
+
+	_, tokens, err := h.ssoUseCase.Callback(clientContext(c), c.Query("code"), c.Query("state"), browserState)
+	if err != nil {
+		switch {
+		case errors.Is(err, sso.ErrInvalidState):
+			response.Unauthorized(c, "Sign-in attempt is unknown or has expired")
+		case errors.Is(err, sso.ErrSignInFailed), errors.Is(err, sso.ErrNoEmail):
+			response.Unauthorized(c, "Single sign-on failed")
+		case errors.Is(err, sso.ErrEmailInUse):
+			response.Conflict(c, "Email belongs to an account that cannot be linked")
+		case errors.Is(err, sso.ErrUserInactive):
+			response.Forbidden(c, "Account is inactive")
+		default:
+			response.InternalError(c, "Failed to sign in")
+		}
+		return
+	}
+
+	// The fragment stays in the browser: it is neither sent to the frontend's
+	// server nor included in the Referer header
+	fragment := url.Values{
+		"access_token":  {tokens.AccessToken},
+		"refresh_token": {tokens.RefreshToken},
+		"expires_in":    {strconv.FormatInt(tokens.ExpiresIn, 10)},
+		"token_type":    {tokens.TokenType},
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, h.frontendURL+"#"+fragment.Encode())
+}
+
+// setStateCookie keeps the sign-in state in the browser. SameSite=Lax still
+// sends it on the provider's top-level redirect back to the callback.
+func setStateCookie(c *gin.Context, state string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     oidcCookiePath,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/podoru/spinner-podoru/internal/adapter/http/dto"
 	"github.com/podoru/spinner-podoru/internal/adapter/http/middleware"
 	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/usecase/deployment"
 	"github.com/podoru/spinner-podoru/internal/usecase/project"
 	"github.com/podoru/spinner-podoru/pkg/response"
 	"github.com/podoru/spinner-podoru/pkg/validator"
@@ -18,14 +19,16 @@ import (
 var _ = dto.ProjectResponse{}
 
 type ProjectHandler struct {
-	projectUseCase *project.UseCase
-	validator      *validator.Validator
+	projectUseCase    *project.UseCase
+	deploymentUseCase *deployment.UseCase
+	validator         *validator.Validator
 }
 
-func NewProjectHandler(projectUseCase *project.UseCase, validator *validator.Validator) *ProjectHandler {
+func NewProjectHandler(projectUseCase *project.UseCase, deploymentUseCase *deployment.UseCase, validator *validator.Validator) *ProjectHandler {
 	return &ProjectHandler{
-		projectUseCase: projectUseCase,
-		validator:      validator,
+		projectUseCase:    projectUseCase,
+		deploymentUseCase: deploymentUseCase,
+		validator:         validator,
 	}
 }
 
@@ -257,6 +260,22 @@ func (h *ProjectHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	// First, remove the project's containers and networks
+	if err := h.deploymentUseCase.DestroyProject(c.Request.Context(), userID, projectID); err != nil {
+		switch {
+		case errors.Is(err, deployment.ErrProjectNotFound):
+			response.NotFound(c, "Project not found")
+		case errors.Is(err, deployment.ErrNotTeamMember):
+			response.Forbidden(c, "Not a team member")
+		case errors.Is(err, deployment.ErrNotTeamAdmin):
+			response.Forbidden(c, "Requires admin or owner role")
+		default:
+			response.InternalError(c, "Failed to remove project containers and networks")
+		}
+		return
+	}
+
+	// Then delete from database
 	if err := h.projectUseCase.Delete(c.Request.Context(), userID, projectID); err != nil {
 		if errors.Is(err, project.ErrProjectNotFound) {
 			response.NotFound(c, "Project not found")
@@ -277,6 +296,54 @@ func (h *ProjectHandler) Delete(c *gin.Context) {
 	response.NoContent(c)
 }
 
+// GetWebhook godoc
+// @Summary      Get project webhook
+// @Description  Get the GitHub push webhook path and signing secret. Requires admin or owner role.
+// @Tags         projects
+// @Produce      json
+// @Security     BearerAuth
+// @Param        projectId path string true "Project ID" format(uuid)
+// @Success      200 {object} response.Response{data=dto.ProjectWebhookResponse} "Webhook details"
+// @Failure      400 {object} response.Response "Invalid project ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member or insufficient role"
+// @Failure      404 {object} response.Response "Project not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /projects/{projectId}/webhook [get]
+func (h *ProjectHandler) GetWebhook(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid project ID")
+		return
+	}
+
+	webhook, err := h.projectUseCase.GetWebhook(c.Request.Context(), userID, projectID)
+	if err != nil {
+		if errors.Is(err, project.ErrProjectNotFound) {
+			response.NotFound(c, "Project not found")
+			return
+		}
+		if errors.Is(err, project.ErrNotTeamMember) {
+			response.Forbidden(c, "Not a team member")
+			return
+		}
+		if errors.Is(err, project.ErrNotTeamAdmin) {
+			response.Forbidden(c, "Requires admin or owner role")
+			return
+		}
+		response.InternalError(c, "Failed to get project webhook")
+		return
+	}
+
+	response.Success(c, webhook)
+}
+
 func (h *ProjectHandler) Deploy(c *gin.Context) {
 	response.Success(c, gin.H{"message": "Deployment triggered"})
 }
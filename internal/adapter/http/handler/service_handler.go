@@ -2,6 +2,8 @@ package handler
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -136,6 +138,14 @@ func (h *ServiceHandler) Create(c *gin.Context) {
 			response.BadRequest(c, "Image is required for image deploy type")
 			return
 		}
+		if errors.Is(err, service.ErrRepositoryRequired) {
+			response.BadRequest(c, "Project has no repository to build from")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidExportName) || errors.Is(err, service.ErrInvalidEnvVarName) {
+			response.BadRequest(c, err.Error())
+			return
+		}
 		response.InternalError(c, "Failed to create service")
 		return
 	}
@@ -145,12 +155,12 @@ func (h *ServiceHandler) Create(c *gin.Context) {
 
 // Get godoc
 // @Summary      Get service
-// @Description  Get service details by ID
+// @Description  Get service details by ID, including its domains, port mappings and the hostname generated under the platform base domain
 // @Tags         services
 // @Produce      json
 // @Security     BearerAuth
 // @Param        serviceId path string true "Service ID" format(uuid)
-// @Success      200 {object} response.Response{data=dto.ServiceResponse} "Service details"
+// @Success      200 {object} response.Response{data=dto.ServiceDetailsResponse} "Service details"
 // @Failure      400 {object} response.Response "Invalid service ID"
 // @Failure      401 {object} response.Response "User not authenticated"
 // @Failure      403 {object} response.Response "Not a team member"
@@ -237,6 +247,10 @@ func (h *ServiceHandler) Update(c *gin.Context) {
 			response.Forbidden(c, "Not a team member")
 			return
 		}
+		if errors.Is(err, service.ErrInvalidExportName) || errors.Is(err, service.ErrInvalidEnvVarName) {
+			response.BadRequest(c, err.Error())
+			return
+		}
 		response.InternalError(c, "Failed to update service")
 		return
 	}
@@ -364,21 +378,22 @@ func (h *ServiceHandler) Scale(c *gin.Context) {
 
 // Deploy godoc
 // @Summary      Deploy service
-// @Description  Trigger a deployment for the service
+// @Description  Trigger a deployment for the service. Also accepts a project deploy token.
 // @Tags         services
 // @Produce      json
 // @Security     BearerAuth
 // @Param        serviceId path string true "Service ID" format(uuid)
+// @Param        request body dto.DeployRequest false "Deploy options"
 // @Success      200 {object} response.Response{data=dto.DeploymentResponse} "Deployment triggered"
-// @Failure      400 {object} response.Response "Invalid service ID"
+// @Failure      400 {object} response.Response "Invalid service ID, missing image or repository"
 // @Failure      401 {object} response.Response "User not authenticated"
-// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      403 {object} response.Response "Not a team member, or not allowed to ignore the maintenance window"
 // @Failure      404 {object} response.Response "Service not found"
 // @Failure      409 {object} response.Response "Deployment already in progress"
 // @Failure      500 {object} response.Response "Internal server error"
 // @Router       /services/{serviceId}/deploy [post]
 func (h *ServiceHandler) Deploy(c *gin.Context) {
-	userID, ok := middleware.GetUserID(c)
+	userID, ok := middleware.GetActorID(c)
 	if !ok {
 		response.Unauthorized(c, "User not authenticated")
 		return
@@ -390,34 +405,44 @@ func (h *ServiceHandler) Deploy(c *gin.Context) {
 		return
 	}
 
-	dep, err := h.deploymentUseCase.Deploy(c.Request.Context(), userID, serviceID)
+	// The body is optional; a bare POST deploys with the defaults
+	var req entity.DeployOptions
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request body")
+			return
+		}
+	}
+
+	dep, err := h.deploymentUseCase.Deploy(c.Request.Context(), userID, serviceID, &req)
 	if err != nil {
 		switch {
 		case errors.Is(err, deployment.ErrServiceNotFound):
 			response.NotFound(c, "Service not found")
 		case errors.Is(err, deployment.ErrNotTeamMember):
 			response.Forbidden(c, "Not a team member")
+		case errors.Is(err, deployment.ErrWindowOverrideDenied):
+			response.Forbidden(c, "Only team admins can deploy outside the maintenance window")
 		case errors.Is(err, deployment.ErrAlreadyDeploying):
 			response.Conflict(c, "Deployment already in progress")
 		case errors.Is(err, deployment.ErrNoImageSpecified):
 			response.BadRequest(c, "No image specified for deployment")
+		case errors.Is(err, deployment.ErrNoRepository):
+			response.BadRequest(c, "Project has no repository to build from")
+		case errors.Is(err, deployment.ErrUnsupportedDeployType):
+			response.BadRequest(c, "Deploy type not supported yet")
 		default:
 			response.InternalError(c, "Failed to deploy service")
 		}
 		return
 	}
 
-	response.Success(c, dto.DeploymentResponse{
-		ID:        dep.ID,
-		ServiceID: dep.ServiceID,
-		Status:    string(dep.Status),
-		StartedAt: dep.StartedAt,
-	})
+	response.Success(c, dto.ToDeploymentResponse(dep))
 }
 
 // Start godoc
 // @Summary      Start service
-// @Description  Start a stopped service
+// @Description  Start a stopped service. Also accepts a project deploy token.
 // @Tags         services
 // @Produce      json
 // @Security     BearerAuth
@@ -430,7 +455,7 @@ func (h *ServiceHandler) Deploy(c *gin.Context) {
 // @Failure      500 {object} response.Response "Internal server error"
 // @Router       /services/{serviceId}/start [post]
 func (h *ServiceHandler) Start(c *gin.Context) {
-	userID, ok := middleware.GetUserID(c)
+	userID, ok := middleware.GetActorID(c)
 	if !ok {
 		response.Unauthorized(c, "User not authenticated")
 		return
@@ -461,7 +486,7 @@ func (h *ServiceHandler) Start(c *gin.Context) {
 
 // Stop godoc
 // @Summary      Stop service
-// @Description  Stop a running service
+// @Description  Stop a running service. Also accepts a project deploy token.
 // @Tags         services
 // @Produce      json
 // @Security     BearerAuth
@@ -474,7 +499,7 @@ func (h *ServiceHandler) Start(c *gin.Context) {
 // @Failure      500 {object} response.Response "Internal server error"
 // @Router       /services/{serviceId}/stop [post]
 func (h *ServiceHandler) Stop(c *gin.Context) {
-	userID, ok := middleware.GetUserID(c)
+	userID, ok := middleware.GetActorID(c)
 	if !ok {
 		response.Unauthorized(c, "User not authenticated")
 		return
@@ -505,7 +530,7 @@ func (h *ServiceHandler) Stop(c *gin.Context) {
 
 // Restart godoc
 // @Summary      Restart service
-// @Description  Restart a running service
+// @Description  Restart a running service. Also accepts a project deploy token.
 // @Tags         services
 // @Produce      json
 // @Security     BearerAuth
@@ -518,7 +543,7 @@ func (h *ServiceHandler) Stop(c *gin.Context) {
 // @Failure      500 {object} response.Response "Internal server error"
 // @Router       /services/{serviceId}/restart [post]
 func (h *ServiceHandler) Restart(c *gin.Context) {
-	userID, ok := middleware.GetUserID(c)
+	userID, ok := middleware.GetActorID(c)
 	if !ok {
 		response.Unauthorized(c, "User not authenticated")
 		return
@@ -549,7 +574,7 @@ func (h *ServiceHandler) Restart(c *gin.Context) {
 
 // Logs godoc
 // @Summary      Get service logs
-// @Description  Get logs from a service
+// @Description  Get logs from a service. Also accepts a project deploy token.
 // @Tags         services
 // @Produce      json
 // @Security     BearerAuth
@@ -564,7 +589,7 @@ func (h *ServiceHandler) Restart(c *gin.Context) {
 // @Failure      500 {object} response.Response "Internal server error"
 // @Router       /services/{serviceId}/logs [get]
 func (h *ServiceHandler) Logs(c *gin.Context) {
-	userID, ok := middleware.GetUserID(c)
+	userID, ok := middleware.GetActorID(c)
 	if !ok {
 		response.Unauthorized(c, "User not authenticated")
 		return
@@ -601,6 +626,58 @@ func (h *ServiceHandler) Logs(c *gin.Context) {
 	})
 }
 
+// CheckImageUpdate godoc
+// @Summary      Check for image updates
+// @Description  Compare the digest the service is running against the digest its image tag currently resolves to in the registry
+// @Tags         services
+// @Produce      json
+// @Security     BearerAuth
+// @Param        serviceId path string true "Service ID" format(uuid)
+// @Success      200 {object} response.Response{data=dto.ImageStatusResponse} "Image status"
+// @Failure      400 {object} response.Response "Invalid service ID or service does not deploy a registry image"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      404 {object} response.Response "Service not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /services/{serviceId}/image/check [post]
+func (h *ServiceHandler) CheckImageUpdate(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	serviceID, err := uuid.Parse(c.Param("serviceId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid service ID")
+		return
+	}
+
+	status, err := h.deploymentUseCase.CheckImageUpdate(c.Request.Context(), userID, serviceID)
+	if err != nil {
+		switch {
+		case errors.Is(err, deployment.ErrServiceNotFound):
+			response.NotFound(c, "Service not found")
+		case errors.Is(err, deployment.ErrNotTeamMember):
+			response.Forbidden(c, "Not a team member")
+		case errors.Is(err, deployment.ErrNotImageService):
+			response.BadRequest(c, "Service does not deploy a registry image")
+		default:
+			response.InternalError(c, "Failed to check image for updates")
+		}
+		return
+	}
+
+	response.Success(c, dto.ImageStatusResponse{
+		ServiceID:            status.ServiceID,
+		Image:                status.Image,
+		CurrentDigest:        status.CurrentDigest,
+		LatestDigest:         status.LatestDigest,
+		ImageUpdateAvailable: status.ImageUpdateAvailable,
+		CheckedAt:            status.CheckedAt,
+	})
+}
+
 // ListDomains godoc
 // @Summary      List service domains
 // @Description  Get all domains for a service
@@ -646,7 +723,7 @@ func (h *ServiceHandler) ListDomains(c *gin.Context) {
 
 // AddDomain godoc
 // @Summary      Add domain to service
-// @Description  Add a domain to a service for Traefik routing
+// @Description  Add a domain to a service for Traefik routing. HTTP domains may be limited to a path prefix and protected by middlewares; tcp and udp domains are routed on a Traefik entrypoint instead of a host port
 // @Tags         services
 // @Accept       json
 // @Produce      json
@@ -654,11 +731,11 @@ func (h *ServiceHandler) ListDomains(c *gin.Context) {
 // @Param        serviceId path string true "Service ID" format(uuid)
 // @Param        request body dto.CreateDomainRequest true "Domain data"
 // @Success      201 {object} response.Response{data=dto.DomainResponse} "Domain added"
-// @Failure      400 {object} response.Response "Invalid request body or validation error"
+// @Failure      400 {object} response.Response "Invalid request body, validation error, unusable certificate or option unsupported by the routing mode"
 // @Failure      401 {object} response.Response "User not authenticated"
 // @Failure      403 {object} response.Response "Not a team member"
 // @Failure      404 {object} response.Response "Service not found"
-// @Failure      409 {object} response.Response "Domain already in use"
+// @Failure      409 {object} response.Response "Domain and path already in use, conflicting www redirect, or entry point taken"
 // @Failure      500 {object} response.Response "Internal server error"
 // @Router       /services/{serviceId}/domains [post]
 func (h *ServiceHandler) AddDomain(c *gin.Context) {
@@ -694,6 +771,22 @@ func (h *ServiceHandler) AddDomain(c *gin.Context) {
 			response.Forbidden(c, "Not a team member")
 		case errors.Is(err, service.ErrDomainAlreadyInUse):
 			response.Conflict(c, "Domain already in use")
+		case errors.Is(err, service.ErrDomainConflict):
+			response.Conflict(c, "Domain conflicts with an existing www redirect")
+		case errors.Is(err, service.ErrStripWithoutPath):
+			response.BadRequest(c, "strip_prefix requires a path_prefix")
+		case errors.Is(err, service.ErrCertificateNotFound):
+			response.BadRequest(c, "Certificate not found")
+		case errors.Is(err, service.ErrCertificateExpired):
+			response.BadRequest(c, "Certificate has expired")
+		case errors.Is(err, service.ErrCertificateMismatch):
+			response.BadRequest(c, "Certificate does not cover this domain")
+		case errors.Is(err, service.ErrUnsupportedOption),
+			errors.Is(err, service.ErrEntryPointRequired),
+			errors.Is(err, service.ErrUnknownEntryPoint):
+			response.BadRequest(c, err.Error())
+		case errors.Is(err, service.ErrEntryPointInUse):
+			response.Conflict(c, "Entry point already routed to another domain")
 		default:
 			response.InternalError(c, "Failed to add domain")
 		}
@@ -753,3 +846,519 @@ func (h *ServiceHandler) DeleteDomain(c *gin.Context) {
 
 	response.NoContent(c)
 }
+
+// VerifyDomain godoc
+// @Summary      Verify domain ownership
+// @Description  Look up the domain's verification TXT record and start routing the domain once it holds the token returned when the domain was added
+// @Tags         services
+// @Produce      json
+// @Security     BearerAuth
+// @Param        serviceId path string true "Service ID" format(uuid)
+// @Param        domainId path string true "Domain ID" format(uuid)
+// @Success      200 {object} response.Response{data=dto.DomainResponse} "Domain verified"
+// @Failure      400 {object} response.Response "Invalid ID or verification record not found"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      404 {object} response.Response "Service or domain not found"
+// @Failure      409 {object} response.Response "Domain was verified by another service first"
+// @Failure      502 {object} response.Response "DNS lookup failed"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /services/{serviceId}/domains/{domainId}/verify [post]
+func (h *ServiceHandler) VerifyDomain(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	serviceID, err := uuid.Parse(c.Param("serviceId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid service ID")
+		return
+	}
+
+	domainID, err := uuid.Parse(c.Param("domainId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid domain ID")
+		return
+	}
+
+	domain, err := h.serviceUseCase.VerifyDomain(c.Request.Context(), userID, serviceID, domainID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrServiceNotFound):
+			response.NotFound(c, "Service not found")
+		case errors.Is(err, service.ErrNotTeamMember):
+			response.Forbidden(c, "Not a team member")
+		case errors.Is(err, service.ErrDomainNotFound):
+			response.NotFound(c, "Domain not found")
+		case errors.Is(err, service.ErrVerificationRecordMissing):
+			response.BadRequest(c, "Verification TXT record not found")
+		case errors.Is(err, service.ErrDNSLookupFailed):
+			response.BadGateway(c, "DNS lookup failed")
+		case errors.Is(err, service.ErrDomainAlreadyInUse):
+			response.Conflict(c, "Domain already in use")
+		case errors.Is(err, service.ErrDomainConflict):
+			response.Conflict(c, "Domain conflicts with an existing www redirect")
+		case errors.Is(err, service.ErrEntryPointInUse):
+			response.Conflict(c, "Entry point already routed to another domain")
+		default:
+			response.InternalError(c, "Failed to verify domain")
+		}
+		return
+	}
+
+	response.Success(c, dto.ToDomainResponse(domain))
+}
+
+// ListPorts godoc
+// @Summary      List service ports
+// @Description  Get the container ports exposed by a service
+// @Tags         services
+// @Produce      json
+// @Security     BearerAuth
+// @Param        serviceId path string true "Service ID" format(uuid)
+// @Success      200 {object} response.Response{data=[]dto.PortMappingResponse} "List of port mappings"
+// @Failure      400 {object} response.Response "Invalid service ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      404 {object} response.Response "Service not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /services/{serviceId}/ports [get]
+func (h *ServiceHandler) ListPorts(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	serviceID, err := uuid.Parse(c.Param("serviceId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid service ID")
+		return
+	}
+
+	portMappings, err := h.serviceUseCase.ListPorts(c.Request.Context(), userID, serviceID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrServiceNotFound):
+			response.NotFound(c, "Service not found")
+		case errors.Is(err, service.ErrNotTeamMember):
+			response.Forbidden(c, "Not a team member")
+		default:
+			response.InternalError(c, "Failed to list ports")
+		}
+		return
+	}
+
+	response.Success(c, dto.ToPortMappingsResponse(portMappings))
+}
+
+// AddPort godoc
+// @Summary      Add port to service
+// @Description  Expose a container port, optionally published on the host. The first port is the default Traefik target for domains without a port.
+// @Tags         services
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        serviceId path string true "Service ID" format(uuid)
+// @Param        request body dto.CreatePortMappingRequest true "Port mapping data"
+// @Success      201 {object} response.Response{data=dto.PortMappingResponse} "Port added"
+// @Failure      400 {object} response.Response "Invalid request body or validation error"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      404 {object} response.Response "Service not found"
+// @Failure      409 {object} response.Response "Container port already mapped"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /services/{serviceId}/ports [post]
+func (h *ServiceHandler) AddPort(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	serviceID, err := uuid.Parse(c.Param("serviceId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid service ID")
+		return
+	}
+
+	var req entity.PortMappingCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if err := h.validator.Validate(&req); err != nil {
+		response.ValidationError(c, validator.FormatValidationErrors(err))
+		return
+	}
+
+	portMapping, err := h.serviceUseCase.AddPort(c.Request.Context(), userID, serviceID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrServiceNotFound):
+			response.NotFound(c, "Service not found")
+		case errors.Is(err, service.ErrNotTeamMember):
+			response.Forbidden(c, "Not a team member")
+		case errors.Is(err, service.ErrPortAlreadyMapped):
+			response.Conflict(c, "Container port already mapped")
+		default:
+			response.InternalError(c, "Failed to add port")
+		}
+		return
+	}
+
+	response.Created(c, dto.ToPortMappingResponse(portMapping))
+}
+
+// DeletePort godoc
+// @Summary      Delete port from service
+// @Description  Stop exposing a container port. Takes effect on the next deploy.
+// @Tags         services
+// @Produce      json
+// @Security     BearerAuth
+// @Param        serviceId path string true "Service ID" format(uuid)
+// @Param        portId path string true "Port mapping ID" format(uuid)
+// @Success      204 "Port deleted"
+// @Failure      400 {object} response.Response "Invalid ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      404 {object} response.Response "Service or port not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /services/{serviceId}/ports/{portId} [delete]
+func (h *ServiceHandler) DeletePort(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	serviceID, err := uuid.Parse(c.Param("serviceId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid service ID")
+		return
+	}
+
+	portID, err := uuid.Parse(c.Param("portId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid port ID")
+		return
+	}
+
+	if err := h.serviceUseCase.DeletePort(c.Request.Context(), userID, serviceID, portID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrServiceNotFound):
+			response.NotFound(c, "Service not found")
+		case errors.Is(err, service.ErrNotTeamMember):
+			response.Forbidden(c, "Not a team member")
+		case errors.Is(err, service.ErrPortNotFound):
+			response.NotFound(c, "Port not found")
+		default:
+			response.InternalError(c, "Failed to delete port")
+		}
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// ListEnvVars godoc
+// @Summary      List service env vars
+// @Description  Get the env vars of a service. Secret values are masked.
+// @Tags         services
+// @Produce      json
+// @Security     BearerAuth
+// @Param        serviceId path string true "Service ID" format(uuid)
+// @Success      200 {object} response.Response{data=[]dto.ServiceEnvVarResponse} "List of env vars"
+// @Failure      400 {object} response.Response "Invalid service ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      404 {object} response.Response "Service not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /services/{serviceId}/env-vars [get]
+func (h *ServiceHandler) ListEnvVars(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	serviceID, err := uuid.Parse(c.Param("serviceId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid service ID")
+		return
+	}
+
+	envVars, err := h.serviceUseCase.ListEnvVars(c.Request.Context(), userID, serviceID)
+	if err != nil {
+		h.handleEnvVarError(c, err, "Failed to list env vars")
+		return
+	}
+
+	response.Success(c, dto.ToServiceEnvVarsResponse(envVars))
+}
+
+// SetEnvVar godoc
+// @Summary      Set service env var
+// @Description  Create or change one env var. Omit the value to only mark an existing var secret or plain. Turning a secret back into a plain var requires admin or owner role. Takes effect on the next deploy.
+// @Tags         services
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        serviceId path string true "Service ID" format(uuid)
+// @Param        key path string true "Variable name"
+// @Param        request body dto.SetEnvVarRequest true "Env var data"
+// @Success      200 {object} response.Response{data=dto.ServiceEnvVarResponse} "Env var set"
+// @Failure      400 {object} response.Response "Invalid request body, variable name or missing value"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member or requires admin role"
+// @Failure      404 {object} response.Response "Service not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /services/{serviceId}/env-vars/{key} [put]
+func (h *ServiceHandler) SetEnvVar(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	serviceID, err := uuid.Parse(c.Param("serviceId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid service ID")
+		return
+	}
+
+	var req entity.ServiceEnvVarUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	envVar, err := h.serviceUseCase.SetEnvVar(c.Request.Context(), userID, serviceID, c.Param("key"), &req)
+	if err != nil {
+		h.handleEnvVarError(c, err, "Failed to set env var")
+		return
+	}
+
+	response.Success(c, dto.ToServiceEnvVarResponse(envVar))
+}
+
+// DeleteEnvVar godoc
+// @Summary      Delete service env var
+// @Description  Remove one env var from the service. Takes effect on the next deploy.
+// @Tags         services
+// @Produce      json
+// @Security     BearerAuth
+// @Param        serviceId path string true "Service ID" format(uuid)
+// @Param        key path string true "Variable name"
+// @Success      204 "Env var deleted"
+// @Failure      400 {object} response.Response "Invalid service ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      404 {object} response.Response "Service or env var not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /services/{serviceId}/env-vars/{key} [delete]
+func (h *ServiceHandler) DeleteEnvVar(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	serviceID, err := uuid.Parse(c.Param("serviceId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid service ID")
+		return
+	}
+
+	if err := h.serviceUseCase.DeleteEnvVar(c.Request.Context(), userID, serviceID, c.Param("key")); err != nil {
+		h.handleEnvVarError(c, err, "Failed to delete env var")
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// RevealEnvVar godoc
+// @Summary      Reveal service env var
+// @Description  Get the unmasked value of one env var. Requires admin or owner role; revealing a secret is recorded in the history.
+// @Tags         services
+// @Produce      json
+// @Security     BearerAuth
+// @Param        serviceId path string true "Service ID" format(uuid)
+// @Param        key path string true "Variable name"
+// @Success      200 {object} response.Response{data=dto.ServiceEnvVarResponse} "Env var with its value"
+// @Failure      400 {object} response.Response "Invalid service ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Requires admin or owner role"
+// @Failure      404 {object} response.Response "Service or env var not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /services/{serviceId}/env-vars/{key}/reveal [post]
+func (h *ServiceHandler) RevealEnvVar(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	serviceID, err := uuid.Parse(c.Param("serviceId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid service ID")
+		return
+	}
+
+	envVar, err := h.serviceUseCase.RevealEnvVar(c.Request.Context(), userID, serviceID, c.Param("key"))
+	if err != nil {
+		h.handleEnvVarError(c, err, "Failed to reveal env var")
+		return
+	}
+
+	response.Success(c, dto.ToServiceEnvVarResponse(envVar))
+}
+
+// EnvVarHistory godoc
+// @Summary      Service env var history
+// @Description  Get who created, updated, deleted or revealed which env var and when, latest first. Values are never recorded.
+// @Tags         services
+// @Produce      json
+// @Security     BearerAuth
+// @Param        serviceId path string true "Service ID" format(uuid)
+// @Success      200 {object} response.Response{data=[]dto.EnvVarChangeResponse} "Env var history"
+// @Failure      400 {object} response.Response "Invalid service ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      404 {object} response.Response "Service not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /services/{serviceId}/env-vars/history [get]
+func (h *ServiceHandler) EnvVarHistory(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	serviceID, err := uuid.Parse(c.Param("serviceId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid service ID")
+		return
+	}
+
+	changes, err := h.serviceUseCase.ListEnvVarHistory(c.Request.Context(), userID, serviceID)
+	if err != nil {
+		h.handleEnvVarError(c, err, "Failed to list env var history")
+		return
+	}
+
+	response.Success(c, dto.ToEnvVarChangesResponse(changes))
+}
+
+// ImportEnvVars godoc
+// @Summary      Import .env file
+// @Description  Load a .env file into the service's env vars. mode merge (default) keeps keys missing from the file, replace deletes them. Keys the file adds become secrets when secret is set; existing keys stay secret or plain. With dry_run the changes are returned without being applied. Takes effect on the next deploy.
+// @Tags         services
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        serviceId path string true "Service ID" format(uuid)
+// @Param        request body dto.ImportEnvVarsRequest true ".env file and import options"
+// @Success      200 {object} response.Response{data=dto.ImportEnvVarsResponse} "Changes made, or that would be made"
+// @Failure      400 {object} response.Response "Invalid request body, .env syntax or variable name"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not a team member"
+// @Failure      404 {object} response.Response "Service not found"
+// @Failure      422 {object} response.Response "Validation error"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /services/{serviceId}/env-vars/import [post]
+func (h *ServiceHandler) ImportEnvVars(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	serviceID, err := uuid.Parse(c.Param("serviceId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid service ID")
+		return
+	}
+
+	var req entity.EnvImport
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if err := h.validator.Validate(&req); err != nil {
+		response.ValidationError(c, validator.FormatValidationErrors(err))
+		return
+	}
+
+	result, err := h.serviceUseCase.ImportEnvVars(c.Request.Context(), userID, serviceID, &req)
+	if err != nil {
+		h.handleEnvVarError(c, err, "Failed to import env vars")
+		return
+	}
+
+	response.Success(c, dto.ToImportEnvVarsResponse(result))
+}
+
+// ExportEnvVars godoc
+// @Summary      Export .env file
+// @Description  Download the service's env vars as a .env file, secrets included. Requires admin or owner role; every exported secret is recorded as revealed in the history.
+// @Tags         services
+// @Produce      plain
+// @Security     BearerAuth
+// @Param        serviceId path string true "Service ID" format(uuid)
+// @Success      200 {string} string ".env file"
+// @Failure      400 {object} response.Response "Invalid service ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Requires admin or owner role"
+// @Failure      404 {object} response.Response "Service not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /services/{serviceId}/env-vars/export [get]
+func (h *ServiceHandler) ExportEnvVars(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	serviceID, err := uuid.Parse(c.Param("serviceId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid service ID")
+		return
+	}
+
+	svc, content, err := h.serviceUseCase.ExportEnvVars(c.Request.Context(), userID, serviceID)
+	if err != nil {
+		h.handleEnvVarError(c, err, "Failed to export env vars")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.env"`, svc.Slug))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(content))
+}
+
+func (h *ServiceHandler) handleEnvVarError(c *gin.Context, err error, failure string) {
+	switch {
+	case errors.Is(err, service.ErrServiceNotFound):
+		response.NotFound(c, "Service not found")
+	case errors.Is(err, service.ErrEnvVarNotFound):
+		response.NotFound(c, "Env var not found")
+	case errors.Is(err, service.ErrNotTeamMember):
+		response.Forbidden(c, "Not a team member")
+	case errors.Is(err, service.ErrNotTeamAdmin):
+		response.Forbidden(c, "Requires admin or owner role")
+	case errors.Is(err, service.ErrInvalidEnvVarName):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, service.ErrEnvVarValueRequired):
+		response.BadRequest(c, "Value is required for a new env var")
+	case errors.Is(err, service.ErrInvalidDotenv):
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalError(c, failure)
+	}
+}
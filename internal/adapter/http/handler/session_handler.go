@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/adapter/http/dto"
+	"github.com/podoru/spinner-podoru/internal/adapter/http/middleware"
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/usecase/auth"
+	"github.com/podoru/spinner-podoru/internal/usecase/session"
+	"github.com/podoru/spinner-podoru/pkg/response"
+)
+
+type SessionHandler struct {
+	sessionUseCase *session.UseCase
+}
+
+func NewSessionHandler(sessionUseCase *session.UseCase) *SessionHandler {
+	return &SessionHandler{
+		sessionUseCase: sessionUseCase,
+	}
+}
+
+// List godoc
+// @Summary      List sessions
+// @Description  Get the current user's active login sessions with the client that last refreshed each. current marks the session of the request. Requires a login session, not an API token.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} response.Response{data=[]dto.SessionResponse} "List of sessions"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not allowed with an API token"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /users/me/sessions [get]
+func (h *SessionHandler) List(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	currentID, _ := middleware.GetSessionID(c)
+
+	sessions, err := h.sessionUseCase.List(c.Request.Context(), userID, currentID)
+	if err != nil {
+		response.InternalError(c, "Failed to list sessions")
+		return
+	}
+
+	response.Success(c, dto.ToSessionsResponse(sessions))
+}
+
+// Revoke godoc
+// @Summary      Revoke session
+// @Description  Log out one of the current user's sessions. Its refresh token stops working at once; access tokens already issued to it work until they expire. Requires a login session, not an API token.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        sessionId path string true "Session ID" format(uuid)
+// @Success      204 "Session revoked"
+// @Failure      400 {object} response.Response "Invalid session ID"
+// @Failure      401 {object} response.Response "User not authenticated"
+// @Failure      403 {object} response.Response "Not allowed with an API token"
+// @Failure      404 {object} response.Response "Session not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /users/me/sessions/{sessionId} [delete]
+func (h *SessionHandler) Revoke(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("sessionId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid session ID")
+		return
+	}
+
+	if err := h.sessionUseCase.Revoke(c.Request.Context(), userID, sessionID); err != nil {
+		if errors.Is(err, session.ErrSessionNotFound) {
+			response.NotFound(c, "Session not found")
+			return
+		}
+		response.InternalError(c, "Failed to revoke session")
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// clientContext returns the request context carrying the client's user
+// agent and IP address, for requests that start or refresh a session
+func clientContext(c *gin.Context) context.Context {
+	return auth.WithClient(c.Request.Context(), entity.SessionClient{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	})
+}
@@ -89,6 +89,10 @@ func (h *TeamHandler) Create(c *gin.Context) {
 
 	t, err := h.teamUseCase.Create(c.Request.Context(), userID, &req)
 	if err != nil {
+		if errors.Is(err, team.ErrProjectLimitedToken) {
+			response.Forbidden(c, "Token is limited to a project")
+			return
+		}
 		if errors.Is(err, team.ErrSlugAlreadyExists) {
 			response.Conflict(c, "Slug already exists")
 			return
@@ -186,6 +190,10 @@ func (h *TeamHandler) Update(c *gin.Context) {
 
 	t, err := h.teamUseCase.Update(c.Request.Context(), userID, teamID, &req)
 	if err != nil {
+		if errors.Is(err, team.ErrProjectLimitedToken) {
+			response.Forbidden(c, "Token is limited to a project")
+			return
+		}
 		if errors.Is(err, team.ErrTeamNotFound) {
 			response.NotFound(c, "Team not found")
 			return
@@ -233,6 +241,10 @@ func (h *TeamHandler) Delete(c *gin.Context) {
 	}
 
 	if err := h.teamUseCase.Delete(c.Request.Context(), userID, teamID); err != nil {
+		if errors.Is(err, team.ErrProjectLimitedToken) {
+			response.Forbidden(c, "Token is limited to a project")
+			return
+		}
 		if errors.Is(err, team.ErrTeamNotFound) {
 			response.NotFound(c, "Team not found")
 			return
@@ -334,6 +346,10 @@ func (h *TeamHandler) AddMember(c *gin.Context) {
 
 	member, err := h.teamUseCase.AddMember(c.Request.Context(), userID, teamID, &req)
 	if err != nil {
+		if errors.Is(err, team.ErrProjectLimitedToken) {
+			response.Forbidden(c, "Token is limited to a project")
+			return
+		}
 		if errors.Is(err, team.ErrNotTeamMember) {
 			response.Forbidden(c, "Not a team member")
 			return
@@ -405,6 +421,10 @@ func (h *TeamHandler) UpdateMember(c *gin.Context) {
 
 	member, err := h.teamUseCase.UpdateMember(c.Request.Context(), userID, teamID, targetUserID, &req)
 	if err != nil {
+		if errors.Is(err, team.ErrProjectLimitedToken) {
+			response.Forbidden(c, "Token is limited to a project")
+			return
+		}
 		if errors.Is(err, team.ErrNotTeamMember) {
 			response.Forbidden(c, "Not a team member")
 			return
@@ -458,6 +478,10 @@ func (h *TeamHandler) RemoveMember(c *gin.Context) {
 	}
 
 	if err := h.teamUseCase.RemoveMember(c.Request.Context(), userID, teamID, targetUserID); err != nil {
+		if errors.Is(err, team.ErrProjectLimitedToken) {
+			response.Forbidden(c, "Token is limited to a project")
+			return
+		}
 		if errors.Is(err, team.ErrNotTeamMember) {
 			response.Forbidden(c, "Not a team member")
 			return
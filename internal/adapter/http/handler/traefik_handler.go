@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/podoru/spinner-podoru/internal/usecase/traefik"
+	"github.com/podoru/spinner-podoru/pkg/response"
+)
+
+type TraefikHandler struct {
+	traefikUseCase *traefik.UseCase
+	providerToken  string
+}
+
+func NewTraefikHandler(traefikUseCase *traefik.UseCase, providerToken string) *TraefikHandler {
+	return &TraefikHandler{
+		traefikUseCase: traefikUseCase,
+		providerToken:  providerToken,
+	}
+}
+
+// Config godoc
+// @Summary      Traefik dynamic configuration
+// @Description  Routing for every deployed service with domains, in Traefik's dynamic configuration format. Point Traefik's HTTP provider here; the body is the bare configuration, not wrapped in the usual response envelope. Requests are authenticated with the provider token.
+// @Tags         traefik
+// @Produce      json
+// @Param        Authorization header string true "Bearer provider token"
+// @Success      200 {object} traefik.DynamicConfig "Dynamic configuration"
+// @Failure      401 {object} response.Response "Invalid provider token"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /traefik/config [get]
+func (h *TraefikHandler) Config(c *gin.Context) {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.providerToken)) != 1 {
+		response.Unauthorized(c, "Invalid provider token")
+		return
+	}
+
+	cfg, err := h.traefikUseCase.Config(c.Request.Context())
+	if err != nil {
+		response.InternalError(c, "Failed to build Traefik configuration")
+		return
+	}
+
+	c.JSON(http.StatusOK, cfg)
+}
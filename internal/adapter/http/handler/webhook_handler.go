@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"errors"
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/adapter/http/dto"
+	"github.com/podoru/spinner-podoru/internal/usecase/deployment"
+	"github.com/podoru/spinner-podoru/internal/usecase/deploytoken"
+	"github.com/podoru/spinner-podoru/pkg/response"
+)
+
+// maxWebhookPayload matches the largest payload GitHub delivers
+const maxWebhookPayload = 25 << 20
+
+type WebhookHandler struct {
+	deploymentUseCase *deployment.UseCase
+}
+
+func NewWebhookHandler(deploymentUseCase *deployment.UseCase) *WebhookHandler {
+	return &WebhookHandler{
+		deploymentUseCase: deploymentUseCase,
+	}
+}
+
+// GitHub godoc
+// @Summary      GitHub webhook
+// @Description  Receive GitHub push events for a project. Requests are authenticated with the project's webhook secret via X-Hub-Signature-256. Pushes to the tracked branch deploy all dockerfile and auto services when auto-deploy is on.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        projectId path string true "Project ID" format(uuid)
+// @Param        X-GitHub-Event header string true "GitHub event name"
+// @Param        X-Hub-Signature-256 header string true "HMAC-SHA256 signature of the payload"
+// @Success      200 {object} response.Response{data=[]dto.DeploymentResponse} "Deployments started or scheduled"
+// @Failure      400 {object} response.Response "Invalid project ID or payload"
+// @Failure      401 {object} response.Response "Invalid signature"
+// @Failure      404 {object} response.Response "Project not found"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /webhooks/github/{projectId} [post]
+func (h *WebhookHandler) GitHub(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid project ID")
+		return
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookPayload))
+	if err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	deployments, err := h.deploymentUseCase.HandleGithubPush(
+		c.Request.Context(),
+		projectID,
+		c.GetHeader("X-GitHub-Event"),
+		c.GetHeader("X-Hub-Signature-256"),
+		payload,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, deployment.ErrProjectNotFound):
+			response.NotFound(c, "Project not found")
+		case errors.Is(err, deployment.ErrInvalidSignature):
+			response.Unauthorized(c, "Invalid signature")
+		case errors.Is(err, deployment.ErrInvalidPayload):
+			response.BadRequest(c, "Invalid payload")
+		default:
+			response.InternalError(c, "Failed to handle webhook")
+		}
+		return
+	}
+
+	result := make([]dto.DeploymentResponse, 0, len(deployments))
+	for i := range deployments {
+		result = append(result, dto.ToDeploymentResponse(&deployments[i]))
+	}
+
+	response.Success(c, result)
+}
+
+// DeployHook godoc
+// @Summary      Deploy hook
+// @Description  Deploy the services of a project from any CI system. Requests are authenticated with a project deploy token, which picks the project. Without the service parameter every service of the project is deployed, skipping those already deploying. Deployments respect the project's maintenance window.
+// @Tags         webhooks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        service query string false "Slug of the only service to deploy"
+// @Success      200 {object} response.Response{data=[]dto.DeploymentResponse} "Deployments started or scheduled"
+// @Failure      400 {object} response.Response "Missing image or repository"
+// @Failure      401 {object} response.Response "Missing or invalid deploy token"
+// @Failure      404 {object} response.Response "Service not found"
+// @Failure      409 {object} response.Response "Deployment already in progress"
+// @Failure      500 {object} response.Response "Internal server error"
+// @Router       /webhooks/deploy [post]
+func (h *WebhookHandler) DeployHook(c *gin.Context) {
+	deployments, err := h.deploymentUseCase.HandleDeployHook(c.Request.Context(), c.Query("service"))
+	if err != nil {
+		switch {
+		case errors.Is(err, deploytoken.ErrInvalidDeployToken):
+			response.Unauthorized(c, "A deploy token is required")
+		case errors.Is(err, deployment.ErrServiceNotFound):
+			response.NotFound(c, "Service not found")
+		case errors.Is(err, deployment.ErrAlreadyDeploying):
+			response.Conflict(c, "Deployment already in progress")
+		case errors.Is(err, deployment.ErrNoImageSpecified):
+			response.BadRequest(c, "No image specified for deployment")
+		case errors.Is(err, deployment.ErrNoRepository):
+			response.BadRequest(c, "Project has no repository to build from")
+		case errors.Is(err, deployment.ErrUnsupportedDeployType):
+			response.BadRequest(c, "Deploy type not supported yet")
+		default:
+			response.InternalError(c, "Failed to deploy")
+		}
+		return
+	}
+
+	result := make([]dto.DeploymentResponse, 0, len(deployments))
+	for i := range deployments {
+		result = append(result, dto.ToDeploymentResponse(&deployments[i]))
+	}
+
+	response.Success(c, result)
+}
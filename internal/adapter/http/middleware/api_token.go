@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+// tokenScopes are the routes that need another API token scope than the
+// default: read for GET and admin for everything else
+var tokenScopes = map[string]entity.APITokenScope{
+	"POST /api/v1/projects/:projectId/deploy":         entity.APITokenScopeDeploy,
+	"POST /api/v1/services/:serviceId/deploy":         entity.APITokenScopeDeploy,
+	"POST /api/v1/services/:serviceId/start":          entity.APITokenScopeDeploy,
+	"POST /api/v1/services/:serviceId/stop":           entity.APITokenScopeDeploy,
+	"POST /api/v1/services/:serviceId/restart":        entity.APITokenScopeDeploy,
+	"POST /api/v1/services/:serviceId/image/check":    entity.APITokenScopeDeploy,
+	"GET /api/v1/projects/:projectId/webhook":         entity.APITokenScopeAdmin,
+	"GET /api/v1/services/:serviceId/env-vars/export": entity.APITokenScopeAdmin,
+}
+
+// requiredScope is the API token scope a request to the route needs
+func requiredScope(method, route string) entity.APITokenScope {
+	if scope, ok := tokenScopes[method+" "+route]; ok {
+		return scope
+	}
+	if method == http.MethodGet || method == http.MethodHead {
+		return entity.APITokenScopeRead
+	}
+	return entity.APITokenScopeAdmin
+}
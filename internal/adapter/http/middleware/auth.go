@@ -6,7 +6,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/usecase/apitoken"
 	"github.com/podoru/spinner-podoru/internal/usecase/auth"
+	"github.com/podoru/spinner-podoru/internal/usecase/deploytoken"
 	"github.com/podoru/spinner-podoru/pkg/response"
 )
 
@@ -15,14 +18,25 @@ const (
 	BearerPrefix        = "Bearer "
 	UserIDKey           = "user_id"
 	UserEmailKey        = "user_email"
+	SessionIDKey        = "session_id"
+	APITokenKey         = "api_token"
+	DeployTokenKey      = "deploy_token"
 )
 
 type AuthMiddleware struct {
-	authUseCase *auth.UseCase
+	authUseCase        *auth.UseCase
+	apiTokenUseCase    *apitoken.UseCase
+	deployTokenUseCase *deploytoken.UseCase
 }
 
-func NewAuthMiddleware(authUseCase *auth.UseCase) *AuthMiddleware {
-	return &AuthMiddleware{authUseCase: authUseCase}
+// NewAuthMiddleware accepts JWTs, personal API tokens when apiTokenUseCase
+// is set and project deploy tokens when deployTokenUseCase is set
+func NewAuthMiddleware(authUseCase *auth.UseCase, apiTokenUseCase *apitoken.UseCase, deployTokenUseCase *deploytoken.UseCase) *AuthMiddleware {
+	return &AuthMiddleware{
+		authUseCase:        authUseCase,
+		apiTokenUseCase:    apiTokenUseCase,
+		deployTokenUseCase: deployTokenUseCase,
+	}
 }
 
 func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
@@ -47,6 +61,40 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 			return
 		}
 
+		if strings.HasPrefix(token, entity.APITokenPrefix) {
+			apiToken, user, err := m.authenticateAPIToken(c, token)
+			if err != nil {
+				response.Unauthorized(c, "Invalid, expired or revoked API token")
+				c.Abort()
+				return
+			}
+			if scope := requiredScope(c.Request.Method, c.FullPath()); !apiToken.Allows(scope) {
+				response.Forbidden(c, "API token lacks the "+string(scope)+" scope")
+				c.Abort()
+				return
+			}
+			setAPIToken(c, apiToken, user)
+			c.Next()
+			return
+		}
+
+		if strings.HasPrefix(token, entity.DeployTokenPrefix) {
+			deployToken, err := m.authenticateDeployToken(c, token)
+			if err != nil {
+				response.Unauthorized(c, "Invalid, expired or revoked deploy token")
+				c.Abort()
+				return
+			}
+			if !allowsDeployToken(c.Request.Method, c.FullPath()) {
+				response.Forbidden(c, "Not allowed with a deploy token")
+				c.Abort()
+				return
+			}
+			setDeployToken(c, deployToken)
+			c.Next()
+			return
+		}
+
 		claims, err := m.authUseCase.ValidateAccessToken(token)
 		if err != nil {
 			response.Unauthorized(c, "Invalid or expired token")
@@ -56,6 +104,7 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 
 		c.Set(UserIDKey, claims.UserID)
 		c.Set(UserEmailKey, claims.Email)
+		c.Set(SessionIDKey, claims.SessionID)
 
 		c.Next()
 	}
@@ -80,6 +129,15 @@ func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 			return
 		}
 
+		if strings.HasPrefix(token, entity.APITokenPrefix) {
+			apiToken, user, err := m.authenticateAPIToken(c, token)
+			if err == nil && apiToken.Allows(requiredScope(c.Request.Method, c.FullPath())) {
+				setAPIToken(c, apiToken, user)
+			}
+			c.Next()
+			return
+		}
+
 		claims, err := m.authUseCase.ValidateAccessToken(token)
 		if err != nil {
 			c.Next()
@@ -88,11 +146,79 @@ func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 
 		c.Set(UserIDKey, claims.UserID)
 		c.Set(UserEmailKey, claims.Email)
+		c.Set(SessionIDKey, claims.SessionID)
+
+		c.Next()
+	}
+}
+
+// RequireSession rejects requests authenticated by an API token, for what
+// only a logged in user may do, such as managing API tokens
+func (m *AuthMiddleware) RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := GetAPIToken(c); ok {
+			response.Forbidden(c, "Not allowed with an API token")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireDeployToken only accepts project deploy tokens, for endpoints such
+// as the deploy hook that act for a project rather than a user
+func (m *AuthMiddleware) RequireDeployToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader(AuthorizationHeader), BearerPrefix)
+		if !ok || !strings.HasPrefix(token, entity.DeployTokenPrefix) {
+			response.Unauthorized(c, "A deploy token is required")
+			c.Abort()
+			return
+		}
 
+		deployToken, err := m.authenticateDeployToken(c, token)
+		if err != nil {
+			response.Unauthorized(c, "Invalid, expired or revoked deploy token")
+			c.Abort()
+			return
+		}
+		setDeployToken(c, deployToken)
 		c.Next()
 	}
 }
 
+// authenticateAPIToken returns the active personal API token and its user
+func (m *AuthMiddleware) authenticateAPIToken(c *gin.Context, token string) (*entity.APIToken, *entity.User, error) {
+	if m.apiTokenUseCase == nil {
+		return nil, nil, apitoken.ErrInvalidAPIToken
+	}
+	return m.apiTokenUseCase.Authenticate(c.Request.Context(), token)
+}
+
+// setAPIToken sets the token's user on the request, and the token on its
+// context so the use cases stay within its team or project
+func setAPIToken(c *gin.Context, apiToken *entity.APIToken, user *entity.User) {
+	c.Set(UserIDKey, user.ID)
+	c.Set(UserEmailKey, user.Email)
+	c.Set(APITokenKey, apiToken)
+	c.Request = c.Request.WithContext(apitoken.WithToken(c.Request.Context(), apiToken))
+}
+
+// authenticateDeployToken returns the active project deploy token
+func (m *AuthMiddleware) authenticateDeployToken(c *gin.Context, token string) (*entity.DeployToken, error) {
+	if m.deployTokenUseCase == nil {
+		return nil, deploytoken.ErrInvalidDeployToken
+	}
+	return m.deployTokenUseCase.Authenticate(c.Request.Context(), token)
+}
+
+// setDeployToken sets the token on the request and on its context, where the
+// deployment use case finds it in place of a user
+func setDeployToken(c *gin.Context, deployToken *entity.DeployToken) {
+	c.Set(DeployTokenKey, deployToken)
+	c.Request = c.Request.WithContext(deploytoken.WithToken(c.Request.Context(), deployToken))
+}
+
 func GetUserID(c *gin.Context) (uuid.UUID, bool) {
 	userID, exists := c.Get(UserIDKey)
 	if !exists {
@@ -112,3 +238,47 @@ func GetUserEmail(c *gin.Context) (string, bool) {
 	e, ok := email.(string)
 	return e, ok
 }
+
+// GetSessionID returns the session of the access token the request was
+// authenticated by, if any
+func GetSessionID(c *gin.Context) (uuid.UUID, bool) {
+	sessionID, exists := c.Get(SessionIDKey)
+	if !exists {
+		return uuid.UUID{}, false
+	}
+
+	id, ok := sessionID.(uuid.UUID)
+	return id, ok
+}
+
+// GetAPIToken returns the API token the request was authenticated by, if any
+func GetAPIToken(c *gin.Context) (*entity.APIToken, bool) {
+	token, exists := c.Get(APITokenKey)
+	if !exists {
+		return nil, false
+	}
+
+	t, ok := token.(*entity.APIToken)
+	return t, ok
+}
+
+// GetDeployToken returns the deploy token the request was authenticated by, if any
+func GetDeployToken(c *gin.Context) (*entity.DeployToken, bool) {
+	token, exists := c.Get(DeployTokenKey)
+	if !exists {
+		return nil, false
+	}
+
+	t, ok := token.(*entity.DeployToken)
+	return t, ok
+}
+
+// GetActorID returns the ID of the user making the request, or uuid.Nil when
+// a deploy token authenticated it. The use cases then act for the token
+// found in the request context.
+func GetActorID(c *gin.Context) (uuid.UUID, bool) {
+	if _, ok := GetDeployToken(c); ok {
+		return uuid.Nil, true
+	}
+	return GetUserID(c)
+}
@@ -0,0 +1,16 @@
+package middleware
+
+// deployTokenRoutes are the only routes behind RequireAuth that accept a
+// project deploy token
+var deployTokenRoutes = map[string]bool{
+	"POST /api/v1/services/:serviceId/deploy":  true,
+	"POST /api/v1/services/:serviceId/start":   true,
+	"POST /api/v1/services/:serviceId/stop":    true,
+	"POST /api/v1/services/:serviceId/restart": true,
+	"GET /api/v1/services/:serviceId/logs":     true,
+}
+
+// allowsDeployToken reports whether a deploy token may call the route
+func allowsDeployToken(method, route string) bool {
+	return deployTokenRoutes[method+" "+route]
+}
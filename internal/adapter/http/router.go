@@ -9,38 +9,71 @@ import (
 )
 
 type Router struct {
-	engine         *gin.Engine
-	log            *logger.Logger
-	authMiddleware *middleware.AuthMiddleware
-	authHandler    *handler.AuthHandler
-	userHandler    *handler.UserHandler
-	teamHandler    *handler.TeamHandler
-	projectHandler *handler.ProjectHandler
-	serviceHandler *handler.ServiceHandler
-	docsHandler    *handler.DocsHandler
+	engine             *gin.Engine
+	log                *logger.Logger
+	authMiddleware     *middleware.AuthMiddleware
+	authHandler        *handler.AuthHandler
+	oidcHandler        *handler.OIDCHandler
+	mfaHandler         *handler.MFAHandler
+	sessionHandler     *handler.SessionHandler
+	userHandler        *handler.UserHandler
+	teamHandler        *handler.TeamHandler
+	projectHandler     *handler.ProjectHandler
+	serviceHandler     *handler.ServiceHandler
+	networkHandler     *handler.NetworkHandler
+	envGroupHandler    *handler.EnvGroupHandler
+	apiTokenHandler    *handler.APITokenHandler
+	deployTokenHandler *handler.DeployTokenHandler
+	certificateHandler *handler.CertificateHandler
+	webhookHandler     *handler.WebhookHandler
+	traefikHandler     *handler.TraefikHandler
+	adminHandler       *handler.AdminHandler
+	docsHandler        *handler.DocsHandler
 }
 
 type RouterConfig struct {
-	Logger         *logger.Logger
-	AuthMiddleware *middleware.AuthMiddleware
-	AuthHandler    *handler.AuthHandler
-	UserHandler    *handler.UserHandler
-	TeamHandler    *handler.TeamHandler
-	ProjectHandler *handler.ProjectHandler
-	ServiceHandler *handler.ServiceHandler
-	DocsHandler    *handler.DocsHandler
+	Logger             *logger.Logger
+	AuthMiddleware     *middleware.AuthMiddleware
+	AuthHandler        *handler.AuthHandler
+	OIDCHandler        *handler.OIDCHandler
+	MFAHandler         *handler.MFAHandler
+	SessionHandler     *handler.SessionHandler
+	UserHandler        *handler.UserHandler
+	TeamHandler        *handler.TeamHandler
+	ProjectHandler     *handler.ProjectHandler
+	ServiceHandler     *handler.ServiceHandler
+	NetworkHandler     *handler.NetworkHandler
+	EnvGroupHandler    *handler.EnvGroupHandler
+	APITokenHandler    *handler.APITokenHandler
+	DeployTokenHandler *handler.DeployTokenHandler
+	CertificateHandler *handler.CertificateHandler
+	WebhookHandler     *handler.WebhookHandler
+	TraefikHandler     *handler.TraefikHandler
+	AdminHandler       *handler.AdminHandler
+	DocsHandler        *handler.DocsHandler
 }
 
 func NewRouter(cfg *RouterConfig) *Router {
 	return &Router{
-		log:            cfg.Logger,
-		authMiddleware: cfg.AuthMiddleware,
-		authHandler:    cfg.AuthHandler,
-		userHandler:    cfg.UserHandler,
-		teamHandler:    cfg.TeamHandler,
-		projectHandler: cfg.ProjectHandler,
-		serviceHandler: cfg.ServiceHandler,
-		docsHandler:    cfg.DocsHandler,
+		log:                cfg.Logger,
+		authMiddleware:     cfg.AuthMiddleware,
+		authHandler:        cfg.AuthHandler,
+		oidcHandler:        cfg.OIDCHandler,
+		mfaHandler:         cfg.MFAHandler,
+		sessionHandler:     cfg.SessionHandler,
+		userHandler:        cfg.UserHandler,
+		teamHandler:        cfg.TeamHandler,
+		projectHandler:     cfg.ProjectHandler,
+		serviceHandler:     cfg.ServiceHandler,
+		networkHandler:     cfg.NetworkHandler,
+		envGroupHandler:    cfg.EnvGroupHandler,
+		apiTokenHandler:    cfg.APITokenHandler,
+		deployTokenHandler: cfg.DeployTokenHandler,
+		certificateHandler: cfg.CertificateHandler,
+		webhookHandler:     cfg.WebhookHandler,
+		traefikHandler:     cfg.TraefikHandler,
+		adminHandler:       cfg.AdminHandler,
+		docsHandler:        cfg.DocsHandler,
 	}
 }
 
@@ -75,6 +108,10 @@ func (r *Router) setupRoutes() {
 	r.setupTeamRoutes(api)
 	r.setupProjectRoutes(api)
 	r.setupServiceRoutes(api)
+	r.setupEnvGroupRoutes(api)
+	r.setupWebhookRoutes(api)
+	r.setupTraefikRoutes(api)
+	r.setupAdminRoutes(api)
 }
 
 func (r *Router) setupDocsRoutes(api *gin.RouterGroup) {
@@ -101,6 +138,17 @@ func (r *Router) setupAuthRoutes(api *gin.RouterGroup) {
 		auth.POST("/refresh", r.authHandler.Refresh)
 		auth.POST("/logout", r.authHandler.Logout)
 	}
+
+	if r.oidcHandler != nil {
+		auth.GET("/oidc/login", r.oidcHandler.Login)
+		auth.GET("/oidc/callback", r.oidcHandler.Callback)
+	}
+
+	if r.mfaHandler != nil {
+		auth.POST("/mfa/verify", r.mfaHandler.Verify)
+		auth.POST("/mfa/enroll", r.mfaHandler.Enroll)
+		auth.POST("/mfa/recover", r.mfaHandler.Recover)
+	}
 }
 
 func (r *Router) setupUserRoutes(api *gin.RouterGroup) {
@@ -112,8 +160,32 @@ func (r *Router) setupUserRoutes(api *gin.RouterGroup) {
 	users.Use(r.authMiddleware.RequireAuth())
 	{
 		users.GET("/me", r.userHandler.GetMe)
-		users.PUT("/me", r.userHandler.UpdateMe)
-		users.PUT("/me/password", r.userHandler.UpdatePassword)
+
+		profile := users.Group("/me", r.authMiddleware.RequireSession())
+		profile.PUT("", r.userHandler.UpdateMe)
+		profile.PUT("/password", r.userHandler.UpdatePassword)
+
+		if r.apiTokenHandler != nil {
+			tokens := users.Group("/me/tokens", r.authMiddleware.RequireSession())
+			tokens.GET("", r.apiTokenHandler.List)
+			tokens.POST("", r.apiTokenHandler.Create)
+			tokens.DELETE("/:tokenId", r.apiTokenHandler.Revoke)
+		}
+
+		if r.sessionHandler != nil {
+			sessions := users.Group("/me/sessions", r.authMiddleware.RequireSession())
+			sessions.GET("", r.sessionHandler.List)
+			sessions.DELETE("/:sessionId", r.sessionHandler.Revoke)
+		}
+
+		if r.mfaHandler != nil {
+			users.GET("/me/mfa", r.mfaHandler.Status)
+			mfa := users.Group("/me/mfa", r.authMiddleware.RequireSession())
+			mfa.POST("/totp", r.mfaHandler.StartEnrollment)
+			mfa.POST("/totp/confirm", r.mfaHandler.ConfirmEnrollment)
+			mfa.POST("/disable", r.mfaHandler.Disable)
+			mfa.POST("/recovery-codes", r.mfaHandler.RegenerateRecoveryCodes)
+		}
 	}
 }
 
@@ -137,6 +209,20 @@ func (r *Router) setupTeamRoutes(api *gin.RouterGroup) {
 
 		teams.GET("/:teamId/projects", r.projectHandler.ListByTeam)
 		teams.POST("/:teamId/projects", r.projectHandler.Create)
+
+		if r.certificateHandler != nil {
+			teams.GET("/:teamId/certificates", r.certificateHandler.List)
+			teams.POST("/:teamId/certificates", r.certificateHandler.Upload)
+			teams.GET("/:teamId/certificates/expiring", r.certificateHandler.ListExpiring)
+			teams.GET("/:teamId/certificates/:certificateId", r.certificateHandler.Get)
+			teams.PUT("/:teamId/certificates/:certificateId", r.certificateHandler.Update)
+			teams.DELETE("/:teamId/certificates/:certificateId", r.certificateHandler.Delete)
+		}
+
+		if r.envGroupHandler != nil {
+			teams.GET("/:teamId/env-groups", r.envGroupHandler.ListByTeam)
+			teams.POST("/:teamId/env-groups", r.envGroupHandler.CreateForTeam)
+		}
 	}
 }
 
@@ -152,9 +238,29 @@ func (r *Router) setupProjectRoutes(api *gin.RouterGroup) {
 		projects.PUT("/:projectId", r.projectHandler.Update)
 		projects.DELETE("/:projectId", r.projectHandler.Delete)
 		projects.POST("/:projectId/deploy", r.projectHandler.Deploy)
+		projects.GET("/:projectId/webhook", r.projectHandler.GetWebhook)
+
+		if r.deployTokenHandler != nil {
+			projects.GET("/:projectId/deploy-tokens", r.deployTokenHandler.List)
+			projects.POST("/:projectId/deploy-tokens", r.deployTokenHandler.Create)
+			projects.DELETE("/:projectId/deploy-tokens/:tokenId", r.deployTokenHandler.Revoke)
+		}
 
 		projects.GET("/:projectId/services", r.serviceHandler.ListByProject)
 		projects.POST("/:projectId/services", r.serviceHandler.Create)
+
+		if r.networkHandler != nil {
+			projects.GET("/:projectId/networks", r.networkHandler.List)
+			projects.POST("/:projectId/networks", r.networkHandler.Create)
+			projects.DELETE("/:projectId/networks/:networkId", r.networkHandler.Delete)
+			projects.POST("/:projectId/networks/:networkId/services/:serviceId", r.networkHandler.AttachService)
+			projects.DELETE("/:projectId/networks/:networkId/services/:serviceId", r.networkHandler.DetachService)
+		}
+
+		if r.envGroupHandler != nil {
+			projects.GET("/:projectId/env-groups", r.envGroupHandler.ListByProject)
+			projects.POST("/:projectId/env-groups", r.envGroupHandler.CreateForProject)
+		}
 	}
 }
 
@@ -175,10 +281,90 @@ func (r *Router) setupServiceRoutes(api *gin.RouterGroup) {
 		services.POST("/:serviceId/restart", r.serviceHandler.Restart)
 		services.POST("/:serviceId/scale", r.serviceHandler.Scale)
 		services.GET("/:serviceId/logs", r.serviceHandler.Logs)
+		services.POST("/:serviceId/image/check", r.serviceHandler.CheckImageUpdate)
 
 		// Domain routes
 		services.GET("/:serviceId/domains", r.serviceHandler.ListDomains)
 		services.POST("/:serviceId/domains", r.serviceHandler.AddDomain)
 		services.DELETE("/:serviceId/domains/:domainId", r.serviceHandler.DeleteDomain)
+		services.POST("/:serviceId/domains/:domainId/verify", r.serviceHandler.VerifyDomain)
+
+		// Port routes
+		services.GET("/:serviceId/ports", r.serviceHandler.ListPorts)
+		services.POST("/:serviceId/ports", r.serviceHandler.AddPort)
+		services.DELETE("/:serviceId/ports/:portId", r.serviceHandler.DeletePort)
+
+		// Env var routes
+		services.GET("/:serviceId/env-vars", r.serviceHandler.ListEnvVars)
+		services.GET("/:serviceId/env-vars/history", r.serviceHandler.EnvVarHistory)
+		services.GET("/:serviceId/env-vars/export", r.serviceHandler.ExportEnvVars)
+		services.POST("/:serviceId/env-vars/import", r.serviceHandler.ImportEnvVars)
+		services.PUT("/:serviceId/env-vars/:key", r.serviceHandler.SetEnvVar)
+		services.DELETE("/:serviceId/env-vars/:key", r.serviceHandler.DeleteEnvVar)
+		services.POST("/:serviceId/env-vars/:key/reveal", r.serviceHandler.RevealEnvVar)
+
+		// Env group routes
+		if r.envGroupHandler != nil {
+			services.GET("/:serviceId/env", r.envGroupHandler.Preview)
+			services.GET("/:serviceId/env-groups", r.envGroupHandler.ListByService)
+			services.POST("/:serviceId/env-groups/:groupId", r.envGroupHandler.AttachService)
+			services.DELETE("/:serviceId/env-groups/:groupId", r.envGroupHandler.DetachService)
+		}
+	}
+}
+
+func (r *Router) setupEnvGroupRoutes(api *gin.RouterGroup) {
+	if r.envGroupHandler == nil {
+		return
+	}
+
+	groups := api.Group("/env-groups")
+	groups.Use(r.authMiddleware.RequireAuth())
+	{
+		groups.GET("/:groupId", r.envGroupHandler.Get)
+		groups.PUT("/:groupId", r.envGroupHandler.Update)
+		groups.DELETE("/:groupId", r.envGroupHandler.Delete)
+	}
+}
+
+func (r *Router) setupWebhookRoutes(api *gin.RouterGroup) {
+	if r.webhookHandler == nil {
+		return
+	}
+
+	// Webhooks authenticate with a per-project signature or deploy token,
+	// not a user token
+	webhooks := api.Group("/webhooks")
+	{
+		webhooks.POST("/github/:projectId", r.webhookHandler.GitHub)
+		webhooks.POST("/deploy", r.authMiddleware.RequireDeployToken(), r.webhookHandler.DeployHook)
+	}
+}
+
+func (r *Router) setupTraefikRoutes(api *gin.RouterGroup) {
+	if r.traefikHandler == nil {
+		return
+	}
+
+	// Polled by Traefik's HTTP provider, which sends the provider token
+	api.GET("/traefik/config", r.traefikHandler.Config)
+}
+
+func (r *Router) setupAdminRoutes(api *gin.RouterGroup) {
+	if r.adminHandler == nil && r.mfaHandler == nil {
+		return
+	}
+
+	admin := api.Group("/admin")
+	admin.Use(r.authMiddleware.RequireAuth())
+	{
+		if r.adminHandler != nil {
+			admin.GET("/traefik", r.adminHandler.TraefikStatus)
+		}
+
+		if r.mfaHandler != nil {
+			admin.GET("/mfa-policy", r.mfaHandler.GetPolicy)
+			admin.PUT("/mfa-policy", r.mfaHandler.UpdatePolicy)
+		}
 	}
 }
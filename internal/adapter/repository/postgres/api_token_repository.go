@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+type APITokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAPITokenRepository(pool *pgxpool.Pool) *APITokenRepository {
+	return &APITokenRepository{pool: pool}
+}
+
+const apiTokenColumns = `
+	id, user_id, name, token_hash, token_prefix, scopes, team_id, project_id,
+	expires_at, last_used_at, revoked_at, created_at`
+
+func scanAPIToken(row rowScanner, t *entity.APIToken) error {
+	var scopes []string
+	if err := row.Scan(
+		&t.ID, &t.UserID, &t.Name, &t.TokenHash, &t.TokenPrefix, &scopes, &t.TeamID, &t.ProjectID,
+		&t.ExpiresAt, &t.LastUsedAt, &t.RevokedAt, &t.CreatedAt,
+	); err != nil {
+		return err
+	}
+	t.Scopes = make([]entity.APITokenScope, len(scopes))
+	for i, s := range scopes {
+		t.Scopes[i] = entity.APITokenScope(s)
+	}
+	return nil
+}
+
+func (r *APITokenRepository) Create(ctx context.Context, token *entity.APIToken) error {
+	scopes := make([]string, len(token.Scopes))
+	for i, s := range token.Scopes {
+		scopes[i] = string(s)
+	}
+
+	query := `
+		INSERT INTO api_tokens (id, user_id, name, token_hash, token_prefix, scopes, team_id, project_id,
+			expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	_, err := r.pool.Exec(ctx, query,
+		token.ID, token.UserID, token.Name, token.TokenHash, token.TokenPrefix, scopes, token.TeamID, token.ProjectID,
+		token.ExpiresAt, token.CreatedAt,
+	)
+	return err
+}
+
+func (r *APITokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE id = $1`
+
+	token := &entity.APIToken{}
+	err := scanAPIToken(r.pool.QueryRow(ctx, query, id), token)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (r *APITokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE token_hash = $1`
+
+	token := &entity.APIToken{}
+	err := scanAPIToken(r.pool.QueryRow(ctx, query, tokenHash), token)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (r *APITokenRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]entity.APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE user_id = $1 ORDER BY created_at DESC`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []entity.APIToken
+	for rows.Next() {
+		var t entity.APIToken
+		if err := scanAPIToken(rows, &t); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+
+	return tokens, rows.Err()
+}
+
+func (r *APITokenRepository) Revoke(ctx context.Context, id uuid.UUID, revokedAt time.Time) error {
+	query := `UPDATE api_tokens SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL`
+	_, err := r.pool.Exec(ctx, query, id, revokedAt)
+	return err
+}
+
+func (r *APITokenRepository) UpdateLastUsed(ctx context.Context, id uuid.UUID, lastUsedAt time.Time) error {
+	query := `UPDATE api_tokens SET last_used_at = $2 WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, id, lastUsedAt)
+	return err
+}
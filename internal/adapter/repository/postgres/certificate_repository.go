@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+type CertificateRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCertificateRepository(pool *pgxpool.Pool) *CertificateRepository {
+	return &CertificateRepository{pool: pool}
+}
+
+const certificateColumns = `
+	id, team_id, name, certificate_pem, private_key_encrypted, dns_names, subject, issuer,
+	not_before, not_after, created_at, updated_at`
+
+func scanCertificate(row rowScanner, c *entity.Certificate) error {
+	return row.Scan(
+		&c.ID, &c.TeamID, &c.Name, &c.CertificatePEM, &c.KeyEncrypted, &c.DNSNames, &c.Subject, &c.Issuer,
+		&c.NotBefore, &c.NotAfter, &c.CreatedAt, &c.UpdatedAt,
+	)
+}
+
+func (r *CertificateRepository) Create(ctx context.Context, certificate *entity.Certificate) error {
+	query := `
+		INSERT INTO certificates (id, team_id, name, certificate_pem, private_key_encrypted, dns_names,
+			subject, issuer, not_before, not_after, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	_, err := r.pool.Exec(ctx, query,
+		certificate.ID, certificate.TeamID, certificate.Name, certificate.CertificatePEM,
+		certificate.KeyEncrypted, certificate.DNSNames, certificate.Subject, certificate.Issuer,
+		certificate.NotBefore, certificate.NotAfter, certificate.CreatedAt, certificate.UpdatedAt,
+	)
+	return err
+}
+
+func (r *CertificateRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Certificate, error) {
+	query := `SELECT ` + certificateColumns + ` FROM certificates WHERE id = $1`
+	certificate := &entity.Certificate{}
+	err := scanCertificate(r.pool.QueryRow(ctx, query, id), certificate)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return certificate, nil
+}
+
+func (r *CertificateRepository) Update(ctx context.Context, certificate *entity.Certificate) error {
+	query := `
+		UPDATE certificates SET name = $1, certificate_pem = $2, private_key_encrypted = $3, dns_names = $4,
+			subject = $5, issuer = $6, not_before = $7, not_after = $8, updated_at = $9
+		WHERE id = $10
+	`
+	_, err := r.pool.Exec(ctx, query,
+		certificate.Name, certificate.CertificatePEM, certificate.KeyEncrypted, certificate.DNSNames,
+		certificate.Subject, certificate.Issuer, certificate.NotBefore, certificate.NotAfter,
+		certificate.UpdatedAt, certificate.ID,
+	)
+	return err
+}
+
+func (r *CertificateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM certificates WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, id)
+	return err
+}
+
+func (r *CertificateRepository) ListByTeamID(ctx context.Context, teamID uuid.UUID) ([]entity.Certificate, error) {
+	query := `SELECT ` + certificateColumns + ` FROM certificates WHERE team_id = $1 ORDER BY name`
+	return r.list(ctx, query, teamID)
+}
+
+func (r *CertificateRepository) ListExpiringByTeamID(ctx context.Context, teamID uuid.UUID, before time.Time) ([]entity.Certificate, error) {
+	query := `SELECT ` + certificateColumns + ` FROM certificates WHERE team_id = $1 AND not_after < $2 ORDER BY not_after`
+	return r.list(ctx, query, teamID, before)
+}
+
+func (r *CertificateRepository) ListByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.Certificate, error) {
+	query := `SELECT ` + certificateColumns + ` FROM certificates WHERE id = ANY($1) ORDER BY created_at`
+	return r.list(ctx, query, ids)
+}
+
+func (r *CertificateRepository) list(ctx context.Context, query string, args ...any) ([]entity.Certificate, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var certificates []entity.Certificate
+	for rows.Next() {
+		var c entity.Certificate
+		if err := scanCertificate(rows, &c); err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, c)
+	}
+	return certificates, rows.Err()
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+type CiphertextRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCiphertextRepository(pool *pgxpool.Pool) *CiphertextRepository {
+	return &CiphertextRepository{pool: pool}
+}
+
+func (r *CiphertextRepository) List(ctx context.Context, column entity.EncryptedColumn, after uuid.UUID, limit int) ([]entity.Ciphertext, error) {
+	table, col := identifiers(column)
+	query := fmt.Sprintf(`
+		SELECT id, %[2]s FROM %[1]s
+		WHERE %[2]s IS NOT NULL AND id > $1
+		ORDER BY id
+		LIMIT $2
+	`, table, col)
+
+	rows, err := r.pool.Query(ctx, query, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ciphertexts []entity.Ciphertext
+	for rows.Next() {
+		var c entity.Ciphertext
+		if err := rows.Scan(&c.ID, &c.Data); err != nil {
+			return nil, err
+		}
+		ciphertexts = append(ciphertexts, c)
+	}
+
+	return ciphertexts, rows.Err()
+}
+
+func (r *CiphertextRepository) Replace(ctx context.Context, column entity.EncryptedColumn, id uuid.UUID, from, to []byte) (bool, error) {
+	table, col := identifiers(column)
+	query := fmt.Sprintf(`UPDATE %[1]s SET %[2]s = $3 WHERE id = $1 AND %[2]s = $2`, table, col)
+
+	result, err := r.pool.Exec(ctx, query, id, from, to)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+func identifiers(column entity.EncryptedColumn) (string, string) {
+	return pgx.Identifier{column.Table}.Sanitize(), pgx.Identifier{column.Column}.Sanitize()
+}
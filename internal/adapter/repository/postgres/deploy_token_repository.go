@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+type DeployTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewDeployTokenRepository(pool *pgxpool.Pool) *DeployTokenRepository {
+	return &DeployTokenRepository{pool: pool}
+}
+
+const deployTokenColumns = `
+	id, project_id, name, token_hash, token_prefix, created_by,
+	expires_at, last_used_at, revoked_at, created_at`
+
+func scanDeployToken(row rowScanner, t *entity.DeployToken) error {
+	return row.Scan(
+		&t.ID, &t.ProjectID, &t.Name, &t.TokenHash, &t.TokenPrefix, &t.CreatedBy,
+		&t.ExpiresAt, &t.LastUsedAt, &t.RevokedAt, &t.CreatedAt,
+	)
+}
+
+func (r *DeployTokenRepository) Create(ctx context.Context, token *entity.DeployToken) error {
+	query := `
+		INSERT INTO deploy_tokens (id, project_id, name, token_hash, token_prefix, created_by,
+			expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := r.pool.Exec(ctx, query,
+		token.ID, token.ProjectID, token.Name, token.TokenHash, token.TokenPrefix, token.CreatedBy,
+		token.ExpiresAt, token.CreatedAt,
+	)
+	return err
+}
+
+func (r *DeployTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.DeployToken, error) {
+	query := `SELECT ` + deployTokenColumns + ` FROM deploy_tokens WHERE id = $1`
+
+	token := &entity.DeployToken{}
+	err := scanDeployToken(r.pool.QueryRow(ctx, query, id), token)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (r *DeployTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.DeployToken, error) {
+	query := `SELECT ` + deployTokenColumns + ` FROM deploy_tokens WHERE token_hash = $1`
+
+	token := &entity.DeployToken{}
+	err := scanDeployToken(r.pool.QueryRow(ctx, query, tokenHash), token)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (r *DeployTokenRepository) ListByProjectID(ctx context.Context, projectID uuid.UUID) ([]entity.DeployToken, error) {
+	query := `SELECT ` + deployTokenColumns + ` FROM deploy_tokens WHERE project_id = $1 ORDER BY created_at DESC`
+
+	rows, err := r.pool.Query(ctx, query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []entity.DeployToken
+	for rows.Next() {
+		var t entity.DeployToken
+		if err := scanDeployToken(rows, &t); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+
+	return tokens, rows.Err()
+}
+
+func (r *DeployTokenRepository) Revoke(ctx context.Context, id uuid.UUID, revokedAt time.Time) error {
+	query := `UPDATE deploy_tokens SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL`
+	_, err := r.pool.Exec(ctx, query, id, revokedAt)
+	return err
+}
+
+func (r *DeployTokenRepository) UpdateLastUsed(ctx context.Context, id uuid.UUID, lastUsedAt time.Time) error {
+	query := `UPDATE deploy_tokens SET last_used_at = $2 WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, id, lastUsedAt)
+	return err
+}
@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+type EnvGroupRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewEnvGroupRepository(pool *pgxpool.Pool) *EnvGroupRepository {
+	return &EnvGroupRepository{pool: pool}
+}
+
+const envGroupColumns = `
+	g.id, g.team_id, g.project_id, g.name, g.vars_encrypted, g.created_at, g.updated_at`
+
+func scanEnvGroup(row rowScanner, g *entity.EnvGroup) error {
+	return row.Scan(&g.ID, &g.TeamID, &g.ProjectID, &g.Name, &g.VarsEncrypted, &g.CreatedAt, &g.UpdatedAt)
+}
+
+func (r *EnvGroupRepository) Create(ctx context.Context, group *entity.EnvGroup) error {
+	query := `
+		INSERT INTO env_groups (id, team_id, project_id, name, vars_encrypted, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.pool.Exec(ctx, query,
+		group.ID, group.TeamID, group.ProjectID, group.Name, group.VarsEncrypted,
+		group.CreatedAt, group.UpdatedAt,
+	)
+	return err
+}
+
+func (r *EnvGroupRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.EnvGroup, error) {
+	group := &entity.EnvGroup{}
+	query := `SELECT ` + envGroupColumns + ` FROM env_groups g WHERE g.id = $1`
+	err := scanEnvGroup(r.pool.QueryRow(ctx, query, id), group)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (r *EnvGroupRepository) Update(ctx context.Context, group *entity.EnvGroup) error {
+	query := `UPDATE env_groups SET name = $1, vars_encrypted = $2, updated_at = $3 WHERE id = $4`
+	_, err := r.pool.Exec(ctx, query, group.Name, group.VarsEncrypted, group.UpdatedAt, group.ID)
+	return err
+}
+
+func (r *EnvGroupRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM env_groups WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, id)
+	return err
+}
+
+// ListByTeamID returns the team-level groups of a team, not its projects' groups
+func (r *EnvGroupRepository) ListByTeamID(ctx context.Context, teamID uuid.UUID) ([]entity.EnvGroup, error) {
+	query := `SELECT ` + envGroupColumns + ` FROM env_groups g WHERE g.team_id = $1 AND g.project_id IS NULL ORDER BY g.name`
+	return r.list(ctx, query, teamID)
+}
+
+func (r *EnvGroupRepository) ListByProjectID(ctx context.Context, projectID uuid.UUID) ([]entity.EnvGroup, error) {
+	query := `SELECT ` + envGroupColumns + ` FROM env_groups g WHERE g.project_id = $1 ORDER BY g.name`
+	return r.list(ctx, query, projectID)
+}
+
+// ListByServiceID returns the groups attached to a service in increasing
+// precedence: team groups before project groups, each in attach order
+func (r *EnvGroupRepository) ListByServiceID(ctx context.Context, serviceID uuid.UUID) ([]entity.EnvGroup, error) {
+	query := `
+		SELECT ` + envGroupColumns + ` FROM env_groups g
+		JOIN service_env_groups sg ON sg.env_group_id = g.id
+		WHERE sg.service_id = $1
+		ORDER BY g.project_id IS NOT NULL, sg.attached_at, g.name
+	`
+	return r.list(ctx, query, serviceID)
+}
+
+func (r *EnvGroupRepository) AttachService(ctx context.Context, groupID, serviceID uuid.UUID) error {
+	query := `
+		INSERT INTO service_env_groups (service_id, env_group_id) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+	_, err := r.pool.Exec(ctx, query, serviceID, groupID)
+	return err
+}
+
+func (r *EnvGroupRepository) DetachService(ctx context.Context, groupID, serviceID uuid.UUID) error {
+	query := `DELETE FROM service_env_groups WHERE service_id = $1 AND env_group_id = $2`
+	_, err := r.pool.Exec(ctx, query, serviceID, groupID)
+	return err
+}
+
+func (r *EnvGroupRepository) ListServiceIDs(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error) {
+	query := `SELECT service_id FROM service_env_groups WHERE env_group_id = $1 ORDER BY attached_at`
+	rows, err := r.pool.Query(ctx, query, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *EnvGroupRepository) list(ctx context.Context, query string, args ...any) ([]entity.EnvGroup, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groups []entity.EnvGroup
+	for rows.Next() {
+		var g entity.EnvGroup
+		if err := scanEnvGroup(rows, &g); err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+	return groups, rows.Err()
+}
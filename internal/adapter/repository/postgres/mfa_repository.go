@@ -0,0 +1,214 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+type MFARepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewMFARepository(pool *pgxpool.Pool) *MFARepository {
+	return &MFARepository{pool: pool}
+}
+
+func (r *MFARepository) GetTOTP(ctx context.Context, userID uuid.UUID) (*entity.UserTOTP, error) {
+	query := `
+		SELECT id, user_id, secret_encrypted, confirmed_at, last_used_step, created_at
+		FROM user_totp WHERE user_id = $1
+	`
+	t := &entity.UserTOTP{}
+	err := r.pool.QueryRow(ctx, query, userID).Scan(
+		&t.ID, &t.UserID, &t.SecretEncrypted, &t.ConfirmedAt, &t.LastUsedStep, &t.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *MFARepository) SaveTOTP(ctx context.Context, totp *entity.UserTOTP, codes []entity.MFARecoveryCode) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM user_totp WHERE user_id = $1`, totp.UserID); err != nil {
+			return err
+		}
+		query := `
+			INSERT INTO user_totp (id, user_id, secret_encrypted, confirmed_at, last_used_step, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`
+		if _, err := tx.Exec(ctx, query,
+			totp.ID, totp.UserID, totp.SecretEncrypted, totp.ConfirmedAt, totp.LastUsedStep, totp.CreatedAt,
+		); err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(ctx, tx, totp.UserID, codes)
+	})
+}
+
+func (r *MFARepository) ConfirmTOTP(ctx context.Context, userID uuid.UUID, confirmedAt time.Time) error {
+	query := `UPDATE user_totp SET confirmed_at = $2 WHERE user_id = $1`
+	_, err := r.pool.Exec(ctx, query, userID, confirmedAt)
+	return err
+}
+
+func (r *MFARepository) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	query := `UPDATE user_totp SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2`
+	tag, err := r.pool.Exec(ctx, query, userID, step)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *MFARepository) DeleteTOTP(ctx context.Context, userID uuid.UUID) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID)
+		return err
+	})
+}
+
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string, usedAt time.Time) (bool, error) {
+	query := `
+		UPDATE mfa_recovery_codes SET used_at = $3
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+	tag, err := r.pool.Exec(ctx, query, userID, codeHash, usedAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []entity.MFARecoveryCode) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return replaceRecoveryCodes(ctx, tx, userID, codes)
+	})
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID uuid.UUID, codes []entity.MFARecoveryCode) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO mfa_recovery_codes (id, user_id, code_hash, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	for _, c := range codes {
+		if _, err := tx.Exec(ctx, query, c.ID, c.UserID, c.CodeHash, c.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *MFARepository) CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM mfa_recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+	var count int
+	err := r.pool.QueryRow(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+type MFAChallengeRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewMFAChallengeRepository(pool *pgxpool.Pool) *MFAChallengeRepository {
+	return &MFAChallengeRepository{pool: pool}
+}
+
+func (r *MFAChallengeRepository) Create(ctx context.Context, challenge *entity.MFAChallenge) error {
+	query := `
+		INSERT INTO mfa_challenges (id, user_id, token_hash, enrollment, attempts, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.pool.Exec(ctx, query,
+		challenge.ID, challenge.UserID, challenge.TokenHash, challenge.Enrollment, challenge.Attempts,
+		challenge.ExpiresAt, challenge.CreatedAt,
+	)
+	return err
+}
+
+func (r *MFAChallengeRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.MFAChallenge, error) {
+	query := `
+		SELECT id, user_id, token_hash, enrollment, attempts, expires_at, created_at
+		FROM mfa_challenges WHERE token_hash = $1
+	`
+	c := &entity.MFAChallenge{}
+	err := r.pool.QueryRow(ctx, query, tokenHash).Scan(
+		&c.ID, &c.UserID, &c.TokenHash, &c.Enrollment, &c.Attempts, &c.ExpiresAt, &c.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (r *MFAChallengeRepository) AddAttempt(ctx context.Context, id uuid.UUID) (int, error) {
+	query := `UPDATE mfa_challenges SET attempts = attempts + 1 WHERE id = $1 RETURNING attempts`
+	var attempts int
+	err := r.pool.QueryRow(ctx, query, id).Scan(&attempts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	return attempts, err
+}
+
+func (r *MFAChallengeRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM mfa_challenges WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, id)
+	return err
+}
+
+func (r *MFAChallengeRepository) DeleteExpired(ctx context.Context) error {
+	query := `DELETE FROM mfa_challenges WHERE expires_at < NOW()`
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
+
+type MFAPolicyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewMFAPolicyRepository(pool *pgxpool.Pool) *MFAPolicyRepository {
+	return &MFAPolicyRepository{pool: pool}
+}
+
+func (r *MFAPolicyRepository) Get(ctx context.Context) (*entity.MFAPolicy, error) {
+	query := `SELECT required, updated_by, updated_at FROM mfa_policy`
+	p := &entity.MFAPolicy{}
+	err := r.pool.QueryRow(ctx, query).Scan(&p.Required, &p.UpdatedBy, &p.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &entity.MFAPolicy{Required: entity.MFARequiredNone}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *MFAPolicyRepository) Update(ctx context.Context, policy *entity.MFAPolicy) error {
+	query := `
+		INSERT INTO mfa_policy (id, required, updated_by, updated_at)
+		VALUES (TRUE, $1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET required = $1, updated_by = $2, updated_at = $3
+	`
+	_, err := r.pool.Exec(ctx, query, policy.Required, policy.UpdatedBy, policy.UpdatedAt)
+	return err
+}
@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+type NetworkRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewNetworkRepository(pool *pgxpool.Pool) *NetworkRepository {
+	return &NetworkRepository{pool: pool}
+}
+
+const networkColumns = `
+	n.id, n.project_id, n.name, n.docker_network_id, n.driver, n.subnet, n.gateway,
+	n.is_default, n.created_at`
+
+func scanNetwork(row rowScanner, n *entity.Network) error {
+	return row.Scan(
+		&n.ID, &n.ProjectID, &n.Name, &n.DockerNetworkID, &n.Driver, &n.Subnet, &n.Gateway,
+		&n.IsDefault, &n.CreatedAt,
+	)
+}
+
+func (r *NetworkRepository) Create(ctx context.Context, network *entity.Network) error {
+	query := `
+		INSERT INTO networks (id, project_id, name, docker_network_id, driver, subnet, gateway,
+			is_default, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := r.pool.Exec(ctx, query,
+		network.ID, network.ProjectID, network.Name, network.DockerNetworkID, network.Driver,
+		network.Subnet, network.Gateway, network.IsDefault, network.CreatedAt,
+	)
+	return err
+}
+
+func (r *NetworkRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Network, error) {
+	return r.get(ctx, `SELECT `+networkColumns+` FROM networks n WHERE n.id = $1`, id)
+}
+
+func (r *NetworkRepository) GetByName(ctx context.Context, name string) (*entity.Network, error) {
+	return r.get(ctx, `SELECT `+networkColumns+` FROM networks n WHERE n.name = $1 LIMIT 1`, name)
+}
+
+func (r *NetworkRepository) GetByProjectAndName(ctx context.Context, projectID uuid.UUID, name string) (*entity.Network, error) {
+	query := `SELECT ` + networkColumns + ` FROM networks n WHERE n.project_id = $1 AND n.name = $2`
+	return r.get(ctx, query, projectID, name)
+}
+
+func (r *NetworkRepository) GetDefaultByProjectID(ctx context.Context, projectID uuid.UUID) (*entity.Network, error) {
+	query := `SELECT ` + networkColumns + ` FROM networks n WHERE n.project_id = $1 AND n.is_default`
+	return r.get(ctx, query, projectID)
+}
+
+func (r *NetworkRepository) Update(ctx context.Context, network *entity.Network) error {
+	query := `
+		UPDATE networks SET name = $1, docker_network_id = $2, driver = $3, subnet = $4, gateway = $5
+		WHERE id = $6
+	`
+	_, err := r.pool.Exec(ctx, query,
+		network.Name, network.DockerNetworkID, network.Driver, network.Subnet, network.Gateway, network.ID,
+	)
+	return err
+}
+
+func (r *NetworkRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM networks WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, id)
+	return err
+}
+
+func (r *NetworkRepository) ListByProjectID(ctx context.Context, projectID uuid.UUID) ([]entity.Network, error) {
+	query := `SELECT ` + networkColumns + ` FROM networks n WHERE n.project_id = $1 ORDER BY n.is_default DESC, n.name`
+	return r.list(ctx, query, projectID)
+}
+
+func (r *NetworkRepository) ListByServiceID(ctx context.Context, serviceID uuid.UUID) ([]entity.Network, error) {
+	query := `
+		SELECT ` + networkColumns + ` FROM networks n
+		JOIN service_networks sn ON sn.network_id = n.id
+		WHERE sn.service_id = $1
+		ORDER BY n.name
+	`
+	return r.list(ctx, query, serviceID)
+}
+
+func (r *NetworkRepository) ListAll(ctx context.Context) ([]entity.Network, error) {
+	return r.list(ctx, `SELECT `+networkColumns+` FROM networks n ORDER BY n.created_at`)
+}
+
+func (r *NetworkRepository) AttachService(ctx context.Context, networkID, serviceID uuid.UUID) error {
+	query := `
+		INSERT INTO service_networks (service_id, network_id) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+	_, err := r.pool.Exec(ctx, query, serviceID, networkID)
+	return err
+}
+
+func (r *NetworkRepository) DetachService(ctx context.Context, networkID, serviceID uuid.UUID) error {
+	query := `DELETE FROM service_networks WHERE service_id = $1 AND network_id = $2`
+	_, err := r.pool.Exec(ctx, query, serviceID, networkID)
+	return err
+}
+
+func (r *NetworkRepository) ListServiceIDs(ctx context.Context, networkID uuid.UUID) ([]uuid.UUID, error) {
+	query := `SELECT service_id FROM service_networks WHERE network_id = $1`
+	rows, err := r.pool.Query(ctx, query, networkID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *NetworkRepository) get(ctx context.Context, query string, args ...any) (*entity.Network, error) {
+	network := &entity.Network{}
+	err := scanNetwork(r.pool.QueryRow(ctx, query, args...), network)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return network, nil
+}
+
+func (r *NetworkRepository) list(ctx context.Context, query string, args ...any) ([]entity.Network, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var networks []entity.Network
+	for rows.Next() {
+		var n entity.Network
+		if err := scanNetwork(rows, &n); err != nil {
+			return nil, err
+		}
+		networks = append(networks, n)
+	}
+	return networks, rows.Err()
+}
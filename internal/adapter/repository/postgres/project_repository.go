@@ -19,32 +19,40 @@ func NewProjectRepository(pool *pgxpool.Pool) *ProjectRepository {
 	return &ProjectRepository{pool: pool}
 }
 
+const projectColumns = `
+	id, team_id, name, slug, description, github_repo, github_branch,
+	github_token_encrypted, auto_deploy, webhook_secret, maintenance_window,
+	created_at, updated_at`
+
+func scanProject(row rowScanner, p *entity.Project) error {
+	return row.Scan(
+		&p.ID, &p.TeamID, &p.Name, &p.Slug, &p.Description,
+		&p.GithubRepo, &p.GithubBranch, &p.GithubTokenEncrypted,
+		&p.AutoDeploy, &p.WebhookSecret, &p.MaintenanceWindow,
+		&p.CreatedAt, &p.UpdatedAt,
+	)
+}
+
 func (r *ProjectRepository) Create(ctx context.Context, project *entity.Project) error {
 	query := `
 		INSERT INTO projects (id, team_id, name, slug, description, github_repo, github_branch,
-			github_token_encrypted, auto_deploy, webhook_secret, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			github_token_encrypted, auto_deploy, webhook_secret, maintenance_window,
+			created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 	`
 	_, err := r.pool.Exec(ctx, query,
 		project.ID, project.TeamID, project.Name, project.Slug, project.Description,
 		project.GithubRepo, project.GithubBranch, project.GithubTokenEncrypted,
-		project.AutoDeploy, project.WebhookSecret, project.CreatedAt, project.UpdatedAt,
+		project.AutoDeploy, project.WebhookSecret, project.MaintenanceWindow,
+		project.CreatedAt, project.UpdatedAt,
 	)
 	return err
 }
 
 func (r *ProjectRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
-	query := `
-		SELECT id, team_id, name, slug, description, github_repo, github_branch,
-			github_token_encrypted, auto_deploy, webhook_secret, created_at, updated_at
-		FROM projects WHERE id = $1
-	`
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE id = $1`
 	project := &entity.Project{}
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&project.ID, &project.TeamID, &project.Name, &project.Slug, &project.Description,
-		&project.GithubRepo, &project.GithubBranch, &project.GithubTokenEncrypted,
-		&project.AutoDeploy, &project.WebhookSecret, &project.CreatedAt, &project.UpdatedAt,
-	)
+	err := scanProject(r.pool.QueryRow(ctx, query, id), project)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
@@ -55,17 +63,9 @@ func (r *ProjectRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.
 }
 
 func (r *ProjectRepository) GetByTeamAndSlug(ctx context.Context, teamID uuid.UUID, slug string) (*entity.Project, error) {
-	query := `
-		SELECT id, team_id, name, slug, description, github_repo, github_branch,
-			github_token_encrypted, auto_deploy, webhook_secret, created_at, updated_at
-		FROM projects WHERE team_id = $1 AND slug = $2
-	`
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE team_id = $1 AND slug = $2`
 	project := &entity.Project{}
-	err := r.pool.QueryRow(ctx, query, teamID, slug).Scan(
-		&project.ID, &project.TeamID, &project.Name, &project.Slug, &project.Description,
-		&project.GithubRepo, &project.GithubBranch, &project.GithubTokenEncrypted,
-		&project.AutoDeploy, &project.WebhookSecret, &project.CreatedAt, &project.UpdatedAt,
-	)
+	err := scanProject(r.pool.QueryRow(ctx, query, teamID, slug), project)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
@@ -78,12 +78,13 @@ func (r *ProjectRepository) GetByTeamAndSlug(ctx context.Context, teamID uuid.UU
 func (r *ProjectRepository) Update(ctx context.Context, project *entity.Project) error {
 	query := `
 		UPDATE projects SET name = $1, description = $2, github_repo = $3, github_branch = $4,
-			github_token_encrypted = $5, auto_deploy = $6, updated_at = $7
-		WHERE id = $8
+			github_token_encrypted = $5, auto_deploy = $6, maintenance_window = $7, updated_at = $8
+		WHERE id = $9
 	`
 	_, err := r.pool.Exec(ctx, query,
 		project.Name, project.Description, project.GithubRepo, project.GithubBranch,
-		project.GithubTokenEncrypted, project.AutoDeploy, project.UpdatedAt, project.ID,
+		project.GithubTokenEncrypted, project.AutoDeploy, project.MaintenanceWindow,
+		project.UpdatedAt, project.ID,
 	)
 	return err
 }
@@ -95,12 +96,7 @@ func (r *ProjectRepository) Delete(ctx context.Context, id uuid.UUID) error {
 }
 
 func (r *ProjectRepository) ListByTeamID(ctx context.Context, teamID uuid.UUID) ([]entity.Project, error) {
-	query := `
-		SELECT id, team_id, name, slug, description, github_repo, github_branch,
-			github_token_encrypted, auto_deploy, webhook_secret, created_at, updated_at
-		FROM projects WHERE team_id = $1
-		ORDER BY created_at DESC
-	`
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE team_id = $1 ORDER BY created_at DESC`
 	rows, err := r.pool.Query(ctx, query, teamID)
 	if err != nil {
 		return nil, err
@@ -110,12 +106,7 @@ func (r *ProjectRepository) ListByTeamID(ctx context.Context, teamID uuid.UUID)
 	var projects []entity.Project
 	for rows.Next() {
 		var p entity.Project
-		err := rows.Scan(
-			&p.ID, &p.TeamID, &p.Name, &p.Slug, &p.Description,
-			&p.GithubRepo, &p.GithubBranch, &p.GithubTokenEncrypted,
-			&p.AutoDeploy, &p.WebhookSecret, &p.CreatedAt, &p.UpdatedAt,
-		)
-		if err != nil {
+		if err := scanProject(rows, &p); err != nil {
 			return nil, err
 		}
 		projects = append(projects, p)
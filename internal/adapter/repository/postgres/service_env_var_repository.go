@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+type ServiceEnvVarRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewServiceEnvVarRepository(pool *pgxpool.Pool) *ServiceEnvVarRepository {
+	return &ServiceEnvVarRepository{pool: pool}
+}
+
+const serviceEnvVarColumns = `id, service_id, key, value_encrypted, is_secret, created_at, updated_at`
+
+func scanServiceEnvVar(row rowScanner, v *entity.ServiceEnvVar) error {
+	return row.Scan(&v.ID, &v.ServiceID, &v.Key, &v.ValueEncrypted, &v.IsSecret, &v.CreatedAt, &v.UpdatedAt)
+}
+
+// Upsert creates the service's var with the key, or replaces its value
+func (r *ServiceEnvVarRepository) Upsert(ctx context.Context, envVar *entity.ServiceEnvVar) error {
+	query := `
+		INSERT INTO service_env_vars (id, service_id, key, value_encrypted, is_secret, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (service_id, key) DO UPDATE SET
+			value_encrypted = EXCLUDED.value_encrypted,
+			is_secret = EXCLUDED.is_secret,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at
+	`
+	return r.pool.QueryRow(ctx, query,
+		envVar.ID, envVar.ServiceID, envVar.Key, envVar.ValueEncrypted, envVar.IsSecret,
+		envVar.CreatedAt, envVar.UpdatedAt,
+	).Scan(&envVar.ID, &envVar.CreatedAt)
+}
+
+func (r *ServiceEnvVarRepository) GetByServiceAndKey(ctx context.Context, serviceID uuid.UUID, key string) (*entity.ServiceEnvVar, error) {
+	envVar := &entity.ServiceEnvVar{}
+	query := `SELECT ` + serviceEnvVarColumns + ` FROM service_env_vars WHERE service_id = $1 AND key = $2`
+	err := scanServiceEnvVar(r.pool.QueryRow(ctx, query, serviceID, key), envVar)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return envVar, nil
+}
+
+func (r *ServiceEnvVarRepository) Delete(ctx context.Context, serviceID uuid.UUID, key string) error {
+	query := `DELETE FROM service_env_vars WHERE service_id = $1 AND key = $2`
+	_, err := r.pool.Exec(ctx, query, serviceID, key)
+	return err
+}
+
+func (r *ServiceEnvVarRepository) ListByServiceID(ctx context.Context, serviceID uuid.UUID) ([]entity.ServiceEnvVar, error) {
+	query := `SELECT ` + serviceEnvVarColumns + ` FROM service_env_vars WHERE service_id = $1 ORDER BY key`
+	rows, err := r.pool.Query(ctx, query, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var envVars []entity.ServiceEnvVar
+	for rows.Next() {
+		var v entity.ServiceEnvVar
+		if err := scanServiceEnvVar(rows, &v); err != nil {
+			return nil, err
+		}
+		envVars = append(envVars, v)
+	}
+	return envVars, rows.Err()
+}
+
+func (r *ServiceEnvVarRepository) CreateChange(ctx context.Context, change *entity.ServiceEnvVarChange) error {
+	query := `
+		INSERT INTO service_env_var_history (id, service_id, key, action, is_secret, user_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.pool.Exec(ctx, query,
+		change.ID, change.ServiceID, change.Key, change.Action, change.IsSecret, change.UserID, change.CreatedAt,
+	)
+	return err
+}
+
+// ListChanges returns the service's most recent env var changes first, with
+// the email of the user who made each
+func (r *ServiceEnvVarRepository) ListChanges(ctx context.Context, serviceID uuid.UUID, limit int) ([]entity.ServiceEnvVarChange, error) {
+	query := `
+		SELECT h.id, h.service_id, h.key, h.action, h.is_secret, h.user_id, u.email, h.created_at
+		FROM service_env_var_history h
+		LEFT JOIN users u ON u.id = h.user_id
+		WHERE h.service_id = $1
+		ORDER BY h.created_at DESC
+		LIMIT $2
+	`
+	rows, err := r.pool.Query(ctx, query, serviceID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []entity.ServiceEnvVarChange
+	for rows.Next() {
+		var c entity.ServiceEnvVarChange
+		if err := rows.Scan(&c.ID, &c.ServiceID, &c.Key, &c.Action, &c.IsSecret, &c.UserID, &c.UserEmail, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -19,40 +20,57 @@ func NewServiceRepository(pool *pgxpool.Pool) *ServiceRepository {
 	return &ServiceRepository{pool: pool}
 }
 
+const serviceColumns = `
+	id, project_id, name, slug, deploy_type, image, dockerfile_path, build_context,
+	build_args_encrypted, build_target, compose_file, env_vars_encrypted, exports_encrypted, replicas, cpu_limit, memory_limit, health_check_path,
+	health_check_interval, restart_policy, status, container_id, swarm_service_id,
+	image_digest, latest_image_digest, image_update_available, image_checked_at,
+	auto_update, auto_update_interval, created_at, updated_at`
+
+// rowScanner is satisfied by both pgx.Row and pgx.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanService(row rowScanner, s *entity.Service) error {
+	return row.Scan(
+		&s.ID, &s.ProjectID, &s.Name, &s.Slug, &s.DeployType,
+		&s.Image, &s.DockerfilePath, &s.BuildContext, &s.BuildArgsEncrypted,
+		&s.BuildTarget, &s.ComposeFile,
+		&s.EnvVarsEncrypted, &s.ExportsEncrypted, &s.Replicas, &s.CPULimit, &s.MemoryLimit,
+		&s.HealthCheckPath, &s.HealthCheckInterval, &s.RestartPolicy,
+		&s.Status, &s.ContainerID, &s.SwarmServiceID,
+		&s.ImageDigest, &s.LatestImageDigest, &s.ImageUpdateAvailable, &s.ImageCheckedAt,
+		&s.AutoUpdate, &s.AutoUpdateInterval, &s.CreatedAt, &s.UpdatedAt,
+	)
+}
+
 func (r *ServiceRepository) Create(ctx context.Context, service *entity.Service) error {
 	query := `
 		INSERT INTO services (id, project_id, name, slug, deploy_type, image, dockerfile_path,
-			build_context, compose_file, env_vars_encrypted, replicas, cpu_limit, memory_limit,
-			health_check_path, health_check_interval, restart_policy, status, container_id,
-			swarm_service_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+			build_context, build_args_encrypted, build_target, compose_file, env_vars_encrypted,
+			exports_encrypted, replicas, cpu_limit, memory_limit, health_check_path, health_check_interval,
+			restart_policy, status, container_id, swarm_service_id, auto_update,
+			auto_update_interval, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
+			$21, $22, $23, $24, $25, $26)
 	`
 	_, err := r.pool.Exec(ctx, query,
 		service.ID, service.ProjectID, service.Name, service.Slug, service.DeployType,
-		service.Image, service.DockerfilePath, service.BuildContext, service.ComposeFile,
-		service.EnvVarsEncrypted, service.Replicas, service.CPULimit, service.MemoryLimit,
+		service.Image, service.DockerfilePath, service.BuildContext, service.BuildArgsEncrypted,
+		service.BuildTarget, service.ComposeFile,
+		service.EnvVarsEncrypted, service.ExportsEncrypted, service.Replicas, service.CPULimit, service.MemoryLimit,
 		service.HealthCheckPath, service.HealthCheckInterval, service.RestartPolicy,
-		service.Status, service.ContainerID, service.SwarmServiceID, service.CreatedAt, service.UpdatedAt,
+		service.Status, service.ContainerID, service.SwarmServiceID, service.AutoUpdate,
+		service.AutoUpdateInterval, service.CreatedAt, service.UpdatedAt,
 	)
 	return err
 }
 
 func (r *ServiceRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
-	query := `
-		SELECT id, project_id, name, slug, deploy_type, image, dockerfile_path, build_context,
-			compose_file, env_vars_encrypted, replicas, cpu_limit, memory_limit, health_check_path,
-			health_check_interval, restart_policy, status, container_id, swarm_service_id,
-			created_at, updated_at
-		FROM services WHERE id = $1
-	`
+	query := `SELECT ` + serviceColumns + ` FROM services WHERE id = $1`
 	service := &entity.Service{}
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&service.ID, &service.ProjectID, &service.Name, &service.Slug, &service.DeployType,
-		&service.Image, &service.DockerfilePath, &service.BuildContext, &service.ComposeFile,
-		&service.EnvVarsEncrypted, &service.Replicas, &service.CPULimit, &service.MemoryLimit,
-		&service.HealthCheckPath, &service.HealthCheckInterval, &service.RestartPolicy,
-		&service.Status, &service.ContainerID, &service.SwarmServiceID, &service.CreatedAt, &service.UpdatedAt,
-	)
+	err := scanService(r.pool.QueryRow(ctx, query, id), service)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
@@ -63,21 +81,9 @@ func (r *ServiceRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.
 }
 
 func (r *ServiceRepository) GetByProjectAndSlug(ctx context.Context, projectID uuid.UUID, slug string) (*entity.Service, error) {
-	query := `
-		SELECT id, project_id, name, slug, deploy_type, image, dockerfile_path, build_context,
-			compose_file, env_vars_encrypted, replicas, cpu_limit, memory_limit, health_check_path,
-			health_check_interval, restart_policy, status, container_id, swarm_service_id,
-			created_at, updated_at
-		FROM services WHERE project_id = $1 AND slug = $2
-	`
+	query := `SELECT ` + serviceColumns + ` FROM services WHERE project_id = $1 AND slug = $2`
 	service := &entity.Service{}
-	err := r.pool.QueryRow(ctx, query, projectID, slug).Scan(
-		&service.ID, &service.ProjectID, &service.Name, &service.Slug, &service.DeployType,
-		&service.Image, &service.DockerfilePath, &service.BuildContext, &service.ComposeFile,
-		&service.EnvVarsEncrypted, &service.Replicas, &service.CPULimit, &service.MemoryLimit,
-		&service.HealthCheckPath, &service.HealthCheckInterval, &service.RestartPolicy,
-		&service.Status, &service.ContainerID, &service.SwarmServiceID, &service.CreatedAt, &service.UpdatedAt,
-	)
+	err := scanService(r.pool.QueryRow(ctx, query, projectID, slug), service)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
@@ -90,16 +96,18 @@ func (r *ServiceRepository) GetByProjectAndSlug(ctx context.Context, projectID u
 func (r *ServiceRepository) Update(ctx context.Context, service *entity.Service) error {
 	query := `
 		UPDATE services SET name = $1, image = $2, dockerfile_path = $3, build_context = $4,
-			compose_file = $5, env_vars_encrypted = $6, replicas = $7, cpu_limit = $8,
-			memory_limit = $9, health_check_path = $10, health_check_interval = $11,
-			restart_policy = $12, updated_at = $13
-		WHERE id = $14
+			build_args_encrypted = $5, build_target = $6, compose_file = $7,
+			env_vars_encrypted = $8, exports_encrypted = $9, replicas = $10, cpu_limit = $11,
+			memory_limit = $12, health_check_path = $13, health_check_interval = $14,
+			restart_policy = $15, auto_update = $16, auto_update_interval = $17, updated_at = $18
+		WHERE id = $19
 	`
 	_, err := r.pool.Exec(ctx, query,
 		service.Name, service.Image, service.DockerfilePath, service.BuildContext,
-		service.ComposeFile, service.EnvVarsEncrypted, service.Replicas, service.CPULimit,
+		service.BuildArgsEncrypted, service.BuildTarget, service.ComposeFile, service.EnvVarsEncrypted, service.ExportsEncrypted, service.Replicas, service.CPULimit,
 		service.MemoryLimit, service.HealthCheckPath, service.HealthCheckInterval,
-		service.RestartPolicy, service.UpdatedAt, service.ID,
+		service.RestartPolicy, service.AutoUpdate, service.AutoUpdateInterval,
+		service.UpdatedAt, service.ID,
 	)
 	return err
 }
@@ -111,15 +119,32 @@ func (r *ServiceRepository) Delete(ctx context.Context, id uuid.UUID) error {
 }
 
 func (r *ServiceRepository) ListByProjectID(ctx context.Context, projectID uuid.UUID) ([]entity.Service, error) {
-	query := `
-		SELECT id, project_id, name, slug, deploy_type, image, dockerfile_path, build_context,
-			compose_file, env_vars_encrypted, replicas, cpu_limit, memory_limit, health_check_path,
-			health_check_interval, restart_policy, status, container_id, swarm_service_id,
-			created_at, updated_at
-		FROM services WHERE project_id = $1
-		ORDER BY created_at DESC
-	`
-	rows, err := r.pool.Query(ctx, query, projectID)
+	query := `SELECT ` + serviceColumns + ` FROM services WHERE project_id = $1 ORDER BY created_at DESC`
+	return r.list(ctx, query, projectID)
+}
+
+func (r *ServiceRepository) ListByDeployType(ctx context.Context, deployType entity.DeployType) ([]entity.Service, error) {
+	query := `SELECT ` + serviceColumns + ` FROM services WHERE deploy_type = $1 ORDER BY created_at`
+	return r.list(ctx, query, deployType)
+}
+
+// ListDeployed returns the services with a container that is not stopped
+func (r *ServiceRepository) ListDeployed(ctx context.Context) ([]entity.Service, error) {
+	query := `SELECT ` + serviceColumns + ` FROM services
+		WHERE container_id IS NOT NULL AND container_id <> '' AND status <> $1 ORDER BY created_at`
+	return r.list(ctx, query, entity.ServiceStatusStopped)
+}
+
+// ListWithLegacyEnvVars returns the services whose env vars are still stored
+// as a single encrypted map
+func (r *ServiceRepository) ListWithLegacyEnvVars(ctx context.Context) ([]entity.Service, error) {
+	query := `SELECT ` + serviceColumns + ` FROM services
+		WHERE env_vars_encrypted IS NOT NULL ORDER BY created_at`
+	return r.list(ctx, query)
+}
+
+func (r *ServiceRepository) list(ctx context.Context, query string, args ...any) ([]entity.Service, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -128,14 +153,7 @@ func (r *ServiceRepository) ListByProjectID(ctx context.Context, projectID uuid.
 	var services []entity.Service
 	for rows.Next() {
 		var s entity.Service
-		err := rows.Scan(
-			&s.ID, &s.ProjectID, &s.Name, &s.Slug, &s.DeployType,
-			&s.Image, &s.DockerfilePath, &s.BuildContext, &s.ComposeFile,
-			&s.EnvVarsEncrypted, &s.Replicas, &s.CPULimit, &s.MemoryLimit,
-			&s.HealthCheckPath, &s.HealthCheckInterval, &s.RestartPolicy,
-			&s.Status, &s.ContainerID, &s.SwarmServiceID, &s.CreatedAt, &s.UpdatedAt,
-		)
-		if err != nil {
+		if err := scanService(rows, &s); err != nil {
 			return nil, err
 		}
 		services = append(services, s)
@@ -168,6 +186,25 @@ func (r *ServiceRepository) UpdateSwarmServiceID(ctx context.Context, id uuid.UU
 	return err
 }
 
+func (r *ServiceRepository) UpdateImageDigest(ctx context.Context, id uuid.UUID, digest *string) error {
+	query := `
+		UPDATE services SET image_digest = $1, latest_image_digest = $1,
+			image_update_available = false, updated_at = NOW()
+		WHERE id = $2
+	`
+	_, err := r.pool.Exec(ctx, query, digest, id)
+	return err
+}
+
+func (r *ServiceRepository) UpdateImageCheck(ctx context.Context, id uuid.UUID, latestDigest *string, updateAvailable bool, checkedAt time.Time) error {
+	query := `
+		UPDATE services SET latest_image_digest = $1, image_update_available = $2, image_checked_at = $3
+		WHERE id = $4
+	`
+	_, err := r.pool.Exec(ctx, query, latestDigest, updateAvailable, checkedAt, id)
+	return err
+}
+
 type DomainRepository struct {
 	pool *pgxpool.Pool
 }
@@ -176,23 +213,52 @@ func NewDomainRepository(pool *pgxpool.Pool) *DomainRepository {
 	return &DomainRepository{pool: pool}
 }
 
+const domainColumns = `
+	id, service_id, domain, ssl_enabled, ssl_auto, port, path_prefix, strip_prefix,
+	www_redirect, basic_auth_users, ip_allow_list, response_headers, rate_limit, certificate_id,
+	verification_status, verification_token, verified_at, routing_mode, entry_point, tls_passthrough,
+	created_at`
+
+func scanDomain(row rowScanner, d *entity.Domain) error {
+	return row.Scan(
+		&d.ID, &d.ServiceID, &d.Domain, &d.SSLEnabled, &d.SSLAuto, &d.Port, &d.PathPrefix, &d.StripPrefix,
+		&d.WWWRedirect, &d.BasicAuthUsers, &d.IPAllowList, &d.ResponseHeaders, &d.RateLimit, &d.CertificateID,
+		&d.Status, &d.VerificationToken, &d.VerifiedAt, &d.RoutingMode, &d.EntryPoint, &d.TLSPassthrough,
+		&d.CreatedAt,
+	)
+}
+
 func (r *DomainRepository) Create(ctx context.Context, domain *entity.Domain) error {
 	query := `
-		INSERT INTO domains (id, service_id, domain, ssl_enabled, ssl_auto, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO domains (id, service_id, domain, ssl_enabled, ssl_auto, port, path_prefix, strip_prefix,
+			www_redirect, basic_auth_users, ip_allow_list, response_headers, rate_limit, certificate_id,
+			verification_status, verification_token, verified_at, routing_mode, entry_point, tls_passthrough,
+			created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
 	`
 	_, err := r.pool.Exec(ctx, query,
-		domain.ID, domain.ServiceID, domain.Domain, domain.SSLEnabled, domain.SSLAuto, domain.CreatedAt,
+		domain.ID, domain.ServiceID, domain.Domain, domain.SSLEnabled, domain.SSLAuto, domain.Port,
+		domain.PathPrefix, domain.StripPrefix, domain.WWWRedirect, domain.BasicAuthUsers,
+		domain.IPAllowList, domain.ResponseHeaders, domain.RateLimit, domain.CertificateID,
+		domain.Status, domain.VerificationToken, domain.VerifiedAt, domain.RoutingMode, domain.EntryPoint,
+		domain.TLSPassthrough, domain.CreatedAt,
 	)
 	return err
 }
 
+func (r *DomainRepository) MarkVerified(ctx context.Context, id uuid.UUID, verifiedAt time.Time) error {
+	query := `
+		UPDATE domains SET verification_status = $2, verification_token = '', verified_at = $3
+		WHERE id = $1
+	`
+	_, err := r.pool.Exec(ctx, query, id, entity.DomainStatusVerified, verifiedAt)
+	return err
+}
+
 func (r *DomainRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Domain, error) {
-	query := `SELECT id, service_id, domain, ssl_enabled, ssl_auto, created_at FROM domains WHERE id = $1`
+	query := `SELECT ` + domainColumns + ` FROM domains WHERE id = $1`
 	domain := &entity.Domain{}
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&domain.ID, &domain.ServiceID, &domain.Domain, &domain.SSLEnabled, &domain.SSLAuto, &domain.CreatedAt,
-	)
+	err := scanDomain(r.pool.QueryRow(ctx, query, id), domain)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
@@ -203,11 +269,9 @@ func (r *DomainRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.D
 }
 
 func (r *DomainRepository) GetByDomain(ctx context.Context, domainName string) (*entity.Domain, error) {
-	query := `SELECT id, service_id, domain, ssl_enabled, ssl_auto, created_at FROM domains WHERE domain = $1`
+	query := `SELECT ` + domainColumns + ` FROM domains WHERE domain = $1 ORDER BY path_prefix LIMIT 1`
 	domain := &entity.Domain{}
-	err := r.pool.QueryRow(ctx, query, domainName).Scan(
-		&domain.ID, &domain.ServiceID, &domain.Domain, &domain.SSLEnabled, &domain.SSLAuto, &domain.CreatedAt,
-	)
+	err := scanDomain(r.pool.QueryRow(ctx, query, domainName), domain)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
@@ -224,8 +288,32 @@ func (r *DomainRepository) Delete(ctx context.Context, id uuid.UUID) error {
 }
 
 func (r *DomainRepository) ListByServiceID(ctx context.Context, serviceID uuid.UUID) ([]entity.Domain, error) {
-	query := `SELECT id, service_id, domain, ssl_enabled, ssl_auto, created_at FROM domains WHERE service_id = $1`
-	rows, err := r.pool.Query(ctx, query, serviceID)
+	query := `SELECT ` + domainColumns + ` FROM domains WHERE service_id = $1 ORDER BY created_at`
+	return r.list(ctx, query, serviceID)
+}
+
+func (r *DomainRepository) ListByDomains(ctx context.Context, domains []string) ([]entity.Domain, error) {
+	query := `SELECT ` + domainColumns + ` FROM domains WHERE domain = ANY($1) ORDER BY created_at`
+	return r.list(ctx, query, domains)
+}
+
+func (r *DomainRepository) ListByCertificateID(ctx context.Context, certificateID uuid.UUID) ([]entity.Domain, error) {
+	query := `SELECT ` + domainColumns + ` FROM domains WHERE certificate_id = $1 ORDER BY domain`
+	return r.list(ctx, query, certificateID)
+}
+
+func (r *DomainRepository) ListByEntryPoint(ctx context.Context, entryPoint string) ([]entity.Domain, error) {
+	query := `SELECT ` + domainColumns + ` FROM domains WHERE entry_point = $1 ORDER BY created_at`
+	return r.list(ctx, query, entryPoint)
+}
+
+func (r *DomainRepository) ListAll(ctx context.Context) ([]entity.Domain, error) {
+	query := `SELECT ` + domainColumns + ` FROM domains ORDER BY service_id, created_at`
+	return r.list(ctx, query)
+}
+
+func (r *DomainRepository) list(ctx context.Context, query string, args ...any) ([]entity.Domain, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -234,8 +322,7 @@ func (r *DomainRepository) ListByServiceID(ctx context.Context, serviceID uuid.U
 	var domains []entity.Domain
 	for rows.Next() {
 		var d entity.Domain
-		err := rows.Scan(&d.ID, &d.ServiceID, &d.Domain, &d.SSLEnabled, &d.SSLAuto, &d.CreatedAt)
-		if err != nil {
+		if err := scanDomain(rows, &d); err != nil {
 			return nil, err
 		}
 		domains = append(domains, d)
@@ -250,6 +337,61 @@ func (r *DomainRepository) ExistsByDomain(ctx context.Context, domain string) (b
 	return exists, err
 }
 
+type PortMappingRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPortMappingRepository(pool *pgxpool.Pool) *PortMappingRepository {
+	return &PortMappingRepository{pool: pool}
+}
+
+func (r *PortMappingRepository) Create(ctx context.Context, portMapping *entity.PortMapping) error {
+	query := `
+		INSERT INTO port_mappings (id, service_id, container_port, host_port, protocol, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := r.pool.Exec(ctx, query,
+		portMapping.ID, portMapping.ServiceID, portMapping.ContainerPort, portMapping.HostPort,
+		portMapping.Protocol, portMapping.CreatedAt,
+	)
+	return err
+}
+
+func (r *PortMappingRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM port_mappings WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, id)
+	return err
+}
+
+func (r *PortMappingRepository) ListByServiceID(ctx context.Context, serviceID uuid.UUID) ([]entity.PortMapping, error) {
+	query := `
+		SELECT id, service_id, container_port, host_port, protocol, created_at
+		FROM port_mappings WHERE service_id = $1 ORDER BY created_at
+	`
+	rows, err := r.pool.Query(ctx, query, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var portMappings []entity.PortMapping
+	for rows.Next() {
+		var pm entity.PortMapping
+		err := rows.Scan(&pm.ID, &pm.ServiceID, &pm.ContainerPort, &pm.HostPort, &pm.Protocol, &pm.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		portMappings = append(portMappings, pm)
+	}
+	return portMappings, rows.Err()
+}
+
+func (r *PortMappingRepository) DeleteByServiceID(ctx context.Context, serviceID uuid.UUID) error {
+	query := `DELETE FROM port_mappings WHERE service_id = $1`
+	_, err := r.pool.Exec(ctx, query, serviceID)
+	return err
+}
+
 type DeploymentRepository struct {
 	pool *pgxpool.Pool
 }
@@ -258,28 +400,36 @@ func NewDeploymentRepository(pool *pgxpool.Pool) *DeploymentRepository {
 	return &DeploymentRepository{pool: pool}
 }
 
+const deploymentColumns = `
+	id, service_id, triggered_by, triggered_by_token, commit_sha, commit_message, image_digest,
+	status, logs, no_cache, scheduled_for, started_at, finished_at`
+
+func scanDeployment(row rowScanner, d *entity.Deployment) error {
+	return row.Scan(
+		&d.ID, &d.ServiceID, &d.TriggeredBy, &d.TriggeredByToken, &d.CommitSHA, &d.CommitMessage,
+		&d.ImageDigest, &d.Status, &d.Logs, &d.NoCache, &d.ScheduledFor,
+		&d.StartedAt, &d.FinishedAt,
+	)
+}
+
 func (r *DeploymentRepository) Create(ctx context.Context, deployment *entity.Deployment) error {
 	query := `
-		INSERT INTO deployments (id, service_id, triggered_by, commit_sha, commit_message, status, logs, started_at, finished_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO deployments (id, service_id, triggered_by, triggered_by_token, commit_sha, commit_message,
+			image_digest, status, logs, no_cache, scheduled_for, started_at, finished_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 	`
 	_, err := r.pool.Exec(ctx, query,
-		deployment.ID, deployment.ServiceID, deployment.TriggeredBy, deployment.CommitSHA,
-		deployment.CommitMessage, deployment.Status, deployment.Logs, deployment.StartedAt, deployment.FinishedAt,
+		deployment.ID, deployment.ServiceID, deployment.TriggeredBy, deployment.TriggeredByToken, deployment.CommitSHA,
+		deployment.CommitMessage, deployment.ImageDigest, deployment.Status, deployment.Logs,
+		deployment.NoCache, deployment.ScheduledFor, deployment.StartedAt, deployment.FinishedAt,
 	)
 	return err
 }
 
 func (r *DeploymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Deployment, error) {
-	query := `
-		SELECT id, service_id, triggered_by, commit_sha, commit_message, status, logs, started_at, finished_at
-		FROM deployments WHERE id = $1
-	`
+	query := `SELECT ` + deploymentColumns + ` FROM deployments WHERE id = $1`
 	deployment := &entity.Deployment{}
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&deployment.ID, &deployment.ServiceID, &deployment.TriggeredBy, &deployment.CommitSHA,
-		&deployment.CommitMessage, &deployment.Status, &deployment.Logs, &deployment.StartedAt, &deployment.FinishedAt,
-	)
+	err := scanDeployment(r.pool.QueryRow(ctx, query, id), deployment)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
@@ -290,49 +440,33 @@ func (r *DeploymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*enti
 }
 
 func (r *DeploymentRepository) Update(ctx context.Context, deployment *entity.Deployment) error {
-	query := `UPDATE deployments SET status = $1, logs = $2, finished_at = $3 WHERE id = $4`
-	_, err := r.pool.Exec(ctx, query, deployment.Status, deployment.Logs, deployment.FinishedAt, deployment.ID)
+	query := `
+		UPDATE deployments SET status = $1, logs = $2, commit_sha = $3, commit_message = $4,
+			image_digest = $5, finished_at = $6
+		WHERE id = $7
+	`
+	_, err := r.pool.Exec(ctx, query,
+		deployment.Status, deployment.Logs, deployment.CommitSHA, deployment.CommitMessage,
+		deployment.ImageDigest, deployment.FinishedAt, deployment.ID,
+	)
 	return err
 }
 
 func (r *DeploymentRepository) ListByServiceID(ctx context.Context, serviceID uuid.UUID, limit, offset int) ([]entity.Deployment, error) {
 	query := `
-		SELECT id, service_id, triggered_by, commit_sha, commit_message, status, logs, started_at, finished_at
-		FROM deployments WHERE service_id = $1
+		SELECT ` + deploymentColumns + ` FROM deployments WHERE service_id = $1
 		ORDER BY started_at DESC LIMIT $2 OFFSET $3
 	`
-	rows, err := r.pool.Query(ctx, query, serviceID, limit, offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var deployments []entity.Deployment
-	for rows.Next() {
-		var d entity.Deployment
-		err := rows.Scan(
-			&d.ID, &d.ServiceID, &d.TriggeredBy, &d.CommitSHA,
-			&d.CommitMessage, &d.Status, &d.Logs, &d.StartedAt, &d.FinishedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		deployments = append(deployments, d)
-	}
-	return deployments, rows.Err()
+	return r.list(ctx, query, serviceID, limit, offset)
 }
 
 func (r *DeploymentRepository) GetLatestByServiceID(ctx context.Context, serviceID uuid.UUID) (*entity.Deployment, error) {
 	query := `
-		SELECT id, service_id, triggered_by, commit_sha, commit_message, status, logs, started_at, finished_at
-		FROM deployments WHERE service_id = $1
+		SELECT ` + deploymentColumns + ` FROM deployments WHERE service_id = $1
 		ORDER BY started_at DESC LIMIT 1
 	`
 	deployment := &entity.Deployment{}
-	err := r.pool.QueryRow(ctx, query, serviceID).Scan(
-		&deployment.ID, &deployment.ServiceID, &deployment.TriggeredBy, &deployment.CommitSHA,
-		&deployment.CommitMessage, &deployment.Status, &deployment.Logs, &deployment.StartedAt, &deployment.FinishedAt,
-	)
+	err := scanDeployment(r.pool.QueryRow(ctx, query, serviceID), deployment)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
@@ -341,3 +475,39 @@ func (r *DeploymentRepository) GetLatestByServiceID(ctx context.Context, service
 	}
 	return deployment, nil
 }
+
+func (r *DeploymentRepository) ListDue(ctx context.Context, before time.Time) ([]entity.Deployment, error) {
+	query := `
+		SELECT ` + deploymentColumns + ` FROM deployments
+		WHERE status = 'pending' AND scheduled_for IS NOT NULL AND scheduled_for <= $1
+		ORDER BY scheduled_for
+	`
+	return r.list(ctx, query, before)
+}
+
+func (r *DeploymentRepository) CancelScheduled(ctx context.Context, serviceID uuid.UUID) error {
+	query := `
+		UPDATE deployments SET status = 'cancelled', finished_at = NOW()
+		WHERE service_id = $1 AND status = 'pending' AND scheduled_for IS NOT NULL
+	`
+	_, err := r.pool.Exec(ctx, query, serviceID)
+	return err
+}
+
+func (r *DeploymentRepository) list(ctx context.Context, query string, args ...any) ([]entity.Deployment, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deployments []entity.Deployment
+	for rows.Next() {
+		var d entity.Deployment
+		if err := scanDeployment(rows, &d); err != nil {
+			return nil, err
+		}
+		deployments = append(deployments, d)
+	}
+	return deployments, rows.Err()
+}
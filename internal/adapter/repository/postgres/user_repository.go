@@ -19,28 +19,36 @@ func NewUserRepository(pool *pgxpool.Pool) *UserRepository {
 	return &UserRepository{pool: pool}
 }
 
+const userColumns = `
+	id, email, password_hash, name, avatar_url, role, is_active, created_at, updated_at,
+	oidc_issuer, oidc_subject`
+
+func scanUser(row rowScanner, u *entity.User) error {
+	return row.Scan(
+		&u.ID, &u.Email, &u.PasswordHash, &u.Name,
+		&u.AvatarURL, &u.Role, &u.IsActive, &u.CreatedAt, &u.UpdatedAt,
+		&u.OIDCIssuer, &u.OIDCSubject,
+	)
+}
+
 func (r *UserRepository) Create(ctx context.Context, user *entity.User) error {
 	query := `
-		INSERT INTO users (id, email, password_hash, name, avatar_url, role, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO users (id, email, password_hash, name, avatar_url, role, is_active, created_at, updated_at,
+			oidc_issuer, oidc_subject)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 	_, err := r.pool.Exec(ctx, query,
 		user.ID, user.Email, user.PasswordHash, user.Name,
 		user.AvatarURL, user.Role, user.IsActive, user.CreatedAt, user.UpdatedAt,
+		user.OIDCIssuer, user.OIDCSubject,
 	)
 	return err
 }
 
 func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
-	query := `
-		SELECT id, email, password_hash, name, avatar_url, role, is_active, created_at, updated_at
-		FROM users WHERE id = $1
-	`
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
 	user := &entity.User{}
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.Name,
-		&user.AvatarURL, &user.Role, &user.IsActive, &user.CreatedAt, &user.UpdatedAt,
-	)
+	err := scanUser(r.pool.QueryRow(ctx, query, id), user)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
@@ -51,15 +59,22 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Use
 }
 
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
-	query := `
-		SELECT id, email, password_hash, name, avatar_url, role, is_active, created_at, updated_at
-		FROM users WHERE email = $1
-	`
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
 	user := &entity.User{}
-	err := r.pool.QueryRow(ctx, query, email).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.Name,
-		&user.AvatarURL, &user.Role, &user.IsActive, &user.CreatedAt, &user.UpdatedAt,
-	)
+	err := scanUser(r.pool.QueryRow(ctx, query, email), user)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *UserRepository) GetByOIDCSubject(ctx context.Context, issuer, subject string) (*entity.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE oidc_issuer = $1 AND oidc_subject = $2`
+	user := &entity.User{}
+	err := scanUser(r.pool.QueryRow(ctx, query, issuer, subject), user)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
@@ -69,6 +84,12 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*entity.
 	return user, nil
 }
 
+func (r *UserRepository) LinkOIDCSubject(ctx context.Context, id uuid.UUID, issuer, subject string) error {
+	query := `UPDATE users SET oidc_issuer = $2, oidc_subject = $3, updated_at = NOW() WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, id, issuer, subject)
+	return err
+}
+
 func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
 	query := `
 		UPDATE users SET name = $1, avatar_url = $2, updated_at = $3
@@ -106,25 +127,41 @@ func NewRefreshTokenRepository(pool *pgxpool.Pool) *RefreshTokenRepository {
 	return &RefreshTokenRepository{pool: pool}
 }
 
-func (r *RefreshTokenRepository) Create(ctx context.Context, token *entity.RefreshToken) error {
+func (r *RefreshTokenRepository) CreateSession(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		query := `
+			INSERT INTO sessions (id, user_id, user_agent, ip_address, expires_at, last_used_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`
+		if _, err := tx.Exec(ctx, query,
+			session.ID, session.UserID, session.UserAgent, session.IPAddress,
+			session.ExpiresAt, session.LastUsedAt, session.CreatedAt,
+		); err != nil {
+			return err
+		}
+		return createRefreshToken(ctx, tx, token)
+	})
+}
+
+func createRefreshToken(ctx context.Context, tx pgx.Tx, token *entity.RefreshToken) error {
 	query := `
-		INSERT INTO refresh_tokens (id, user_id, token_hash, expires_at, created_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO refresh_tokens (id, user_id, session_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
 	`
-	_, err := r.pool.Exec(ctx, query,
-		token.ID, token.UserID, token.TokenHash, token.ExpiresAt, token.CreatedAt,
+	_, err := tx.Exec(ctx, query,
+		token.ID, token.UserID, token.SessionID, token.TokenHash, token.ExpiresAt, token.CreatedAt,
 	)
 	return err
 }
 
 func (r *RefreshTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
 	query := `
-		SELECT id, user_id, token_hash, expires_at, created_at
+		SELECT id, user_id, session_id, token_hash, used_at, expires_at, created_at
 		FROM refresh_tokens WHERE token_hash = $1
 	`
 	token := &entity.RefreshToken{}
 	err := r.pool.QueryRow(ctx, query, tokenHash).Scan(
-		&token.ID, &token.UserID, &token.TokenHash, &token.ExpiresAt, &token.CreatedAt,
+		&token.ID, &token.UserID, &token.SessionID, &token.TokenHash, &token.UsedAt, &token.ExpiresAt, &token.CreatedAt,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
@@ -135,20 +172,141 @@ func (r *RefreshTokenRepository) GetByTokenHash(ctx context.Context, tokenHash s
 	return token, nil
 }
 
+func (r *RefreshTokenRepository) Rotate(ctx context.Context, used, next *entity.RefreshToken, client entity.SessionClient) (bool, error) {
+	rotated := false
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		query := `UPDATE refresh_tokens SET used_at = $2 WHERE id = $1 AND used_at IS NULL`
+		tag, err := tx.Exec(ctx, query, used.ID, next.CreatedAt)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return nil
+		}
+
+		if err := createRefreshToken(ctx, tx, next); err != nil {
+			return err
+		}
+
+		query = `
+			UPDATE sessions SET expires_at = $2, last_used_at = $3, user_agent = $4, ip_address = $5
+			WHERE id = $1
+		`
+		if _, err := tx.Exec(ctx, query,
+			next.SessionID, next.ExpiresAt, next.CreatedAt, client.UserAgent, client.IPAddress,
+		); err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	return rotated, err
+}
+
+func (r *RefreshTokenRepository) GetSession(ctx context.Context, id uuid.UUID) (*entity.Session, error) {
+	query := `
+		SELECT id, user_id, user_agent, ip_address, expires_at, last_used_at, created_at
+		FROM sessions WHERE id = $1
+	`
+	session := &entity.Session{}
+	err := r.pool.QueryRow(ctx, query, id).Scan(
+		&session.ID, &session.UserID, &session.UserAgent, &session.IPAddress,
+		&session.ExpiresAt, &session.LastUsedAt, &session.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (r *RefreshTokenRepository) ListSessions(ctx context.Context, userID uuid.UUID) ([]entity.Session, error) {
+	query := `
+		SELECT id, user_id, user_agent, ip_address, expires_at, last_used_at, created_at
+		FROM sessions WHERE user_id = $1 AND expires_at > NOW()
+		ORDER BY last_used_at DESC
+	`
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []entity.Session
+	for rows.Next() {
+		var session entity.Session
+		if err := rows.Scan(
+			&session.ID, &session.UserID, &session.UserAgent, &session.IPAddress,
+			&session.ExpiresAt, &session.LastUsedAt, &session.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+func (r *RefreshTokenRepository) DeleteSession(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM sessions WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, id)
+	return err
+}
+
 func (r *RefreshTokenRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
-	query := `DELETE FROM refresh_tokens WHERE user_id = $1`
+	query := `DELETE FROM sessions WHERE user_id = $1`
 	_, err := r.pool.Exec(ctx, query, userID)
 	return err
 }
 
-func (r *RefreshTokenRepository) DeleteByTokenHash(ctx context.Context, tokenHash string) error {
-	query := `DELETE FROM refresh_tokens WHERE token_hash = $1`
-	_, err := r.pool.Exec(ctx, query, tokenHash)
+func (r *RefreshTokenRepository) DeleteExpired(ctx context.Context) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM sessions WHERE expires_at < NOW()`); err != nil {
+		return err
+	}
+	_, err := r.pool.Exec(ctx, `DELETE FROM refresh_tokens WHERE expires_at < NOW()`)
 	return err
 }
 
-func (r *RefreshTokenRepository) DeleteExpired(ctx context.Context) error {
-	query := `DELETE FROM refresh_tokens WHERE expires_at < NOW()`
+type OIDCStateRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewOIDCStateRepository(pool *pgxpool.Pool) *OIDCStateRepository {
+	return &OIDCStateRepository{pool: pool}
+}
+
+func (r *OIDCStateRepository) Create(ctx context.Context, state *entity.OIDCState) error {
+	query := `
+		INSERT INTO oidc_states (state, nonce, code_verifier, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := r.pool.Exec(ctx, query,
+		state.State, state.Nonce, state.CodeVerifier, state.ExpiresAt, state.CreatedAt,
+	)
+	return err
+}
+
+func (r *OIDCStateRepository) Consume(ctx context.Context, state string) (*entity.OIDCState, error) {
+	query := `
+		DELETE FROM oidc_states WHERE state = $1
+		RETURNING state, nonce, code_verifier, expires_at, created_at
+	`
+	s := &entity.OIDCState{}
+	err := r.pool.QueryRow(ctx, query, state).Scan(
+		&s.State, &s.Nonce, &s.CodeVerifier, &s.ExpiresAt, &s.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *OIDCStateRepository) DeleteExpired(ctx context.Context) error {
+	query := `DELETE FROM oidc_states WHERE expires_at < NOW()`
 	_, err := r.pool.Exec(ctx, query)
 	return err
 }
@@ -0,0 +1,9 @@
+package dns
+
+import "context"
+
+// Resolver interface for DNS lookups, used to verify domain ownership
+type Resolver interface {
+	// LookupTXT returns the TXT records of name, or none if it does not exist
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
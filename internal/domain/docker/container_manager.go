@@ -2,15 +2,20 @@ package docker
 
 import (
 	"context"
+	"errors"
 	"io"
 
 	"github.com/podoru/spinner-podoru/internal/domain/entity"
 )
 
+// ErrContainerNotFound is returned when inspecting a container that does not exist
+var ErrContainerNotFound = errors.New("container not found")
+
 // ContainerConfig holds configuration for creating a container
 type ContainerConfig struct {
 	Name          string
 	Image         string
+	Cmd           []string // overrides the image's CMD when set
 	Env           []string
 	PortMappings  []entity.PortMapping
 	Volumes       []entity.Volume
@@ -19,13 +24,63 @@ type ContainerConfig struct {
 	RestartPolicy entity.RestartPolicy
 	Labels        map[string]string
 	NetworkID     string
+	Aliases       []string // DNS aliases on NetworkID
+	ExtraHosts    []string // "host:ip" entries added to /etc/hosts
+}
+
+// NetworkConfig holds configuration for creating a network
+type NetworkConfig struct {
+	Name    string
+	Driver  string
+	Subnet  string
+	Gateway string
+	Labels  map[string]string
+}
+
+// ProjectNetworkConfig returns the configuration for the Docker side of a
+// project network, labelled so Podoru can recognise it on the host
+func ProjectNetworkConfig(network *entity.Network) *NetworkConfig {
+	cfg := &NetworkConfig{
+		Name:   entity.DockerNetworkName(*network.ProjectID, network.Name),
+		Driver: string(network.Driver),
+		Labels: map[string]string{
+			"podoru.project.id": network.ProjectID.String(),
+			"podoru.network.id": network.ID.String(),
+			"podoru.managed":    "true",
+		},
+	}
+	if network.Subnet != nil {
+		cfg.Subnet = *network.Subnet
+	}
+	if network.Gateway != nil {
+		cfg.Gateway = *network.Gateway
+	}
+	return cfg
+}
+
+// BuildOptions holds configuration for building an image from a source directory
+type BuildOptions struct {
+	ContextDir  string
+	Dockerfile  string // relative to ContextDir
+	Tags        []string
+	Labels      map[string]string
+	BuildArgs   map[string]string
+	Target      string
+	CacheFrom   []string
+	NoCache     bool
+	CPULimit    float64 // in CPUs, 0 for unlimited
+	MemoryLimit int64   // in bytes, 0 for unlimited
 }
 
 // ContainerInfo holds information about a container
 type ContainerInfo struct {
 	ID     string
+	Image  string
 	Status string
 	State  string
+	Labels map[string]string
+	// ExposedPorts are the container's TCP ports, including those its image exposes
+	ExposedPorts []int
 }
 
 // LogOptions for retrieving container logs
@@ -39,6 +94,9 @@ type LogOptions struct {
 type ContainerManager interface {
 	// Image operations
 	PullImage(ctx context.Context, imageName string) error
+	GetImageDigest(ctx context.Context, imageName string) (string, error)
+	GetRemoteImageDigest(ctx context.Context, imageName string) (string, error)
+	BuildImage(ctx context.Context, opts *BuildOptions, logs io.Writer) error
 
 	// Container operations
 	CreateContainer(ctx context.Context, config *ContainerConfig) (string, error)
@@ -50,6 +108,10 @@ type ContainerManager interface {
 
 	// Network operations
 	ValidateNetwork(ctx context.Context, networkName string) error
+	CreateNetwork(ctx context.Context, config *NetworkConfig) (string, error)
+	RemoveNetwork(ctx context.Context, networkID string) error
+	ConnectNetwork(ctx context.Context, networkID, containerID string, aliases []string) error
+	DisconnectNetwork(ctx context.Context, networkID, containerID string) error
 
 	// Logs
 	GetLogs(ctx context.Context, containerID string, opts *LogOptions) (io.ReadCloser, error)
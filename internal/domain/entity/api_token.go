@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APITokenPrefix starts every personal access token, telling it apart from a JWT
+const APITokenPrefix = "pod_"
+
+type APITokenScope string
+
+const (
+	// APITokenScopeRead allows GET requests that do not reveal secrets
+	APITokenScopeRead APITokenScope = "read"
+	// APITokenScopeDeploy also allows deploying, starting, stopping and restarting
+	APITokenScopeDeploy APITokenScope = "deploy"
+	// APITokenScopeAdmin allows everything the user can do
+	APITokenScopeAdmin APITokenScope = "admin"
+)
+
+// APIToken is a long-lived personal access token. It acts as its user,
+// limited to its scopes and, when set, to one team or one project.
+type APIToken struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+	Name   string    `json:"name"`
+	TokenState
+	Scopes    []APITokenScope `json:"scopes"`
+	TeamID    *uuid.UUID      `json:"team_id,omitempty"`
+	ProjectID *uuid.UUID      `json:"project_id,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type APITokenCreate struct {
+	Name   string          `json:"name" validate:"required,min=1,max=100"`
+	Scopes []APITokenScope `json:"scopes" validate:"required,min=1,dive,oneof=read deploy admin"`
+	// TeamID or ProjectID limit the token to one team or one project
+	TeamID    *uuid.UUID `json:"team_id,omitempty"`
+	ProjectID *uuid.UUID `json:"project_id,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Allows reports whether the token may make a request needing scope. Admin
+// includes deploy, which includes read.
+func (t *APIToken) Allows(scope APITokenScope) bool {
+	for _, s := range t.Scopes {
+		switch {
+		case s == scope, s == APITokenScopeAdmin:
+			return true
+		case s == APITokenScopeDeploy && scope == APITokenScopeRead:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+func TestAPITokenAllows(t *testing.T) {
+	testCases := []struct {
+		name   string
+		scopes []entity.APITokenScope
+		scope  entity.APITokenScope
+		want   bool
+	}{
+		{name: "read allows read", scopes: []entity.APITokenScope{entity.APITokenScopeRead}, scope: entity.APITokenScopeRead, want: true},
+		{name: "read denies deploy", scopes: []entity.APITokenScope{entity.APITokenScopeRead}, scope: entity.APITokenScopeDeploy, want: false},
+		{name: "deploy allows read", scopes: []entity.APITokenScope{entity.APITokenScopeDeploy}, scope: entity.APITokenScopeRead, want: true},
+		{name: "deploy denies admin", scopes: []entity.APITokenScope{entity.APITokenScopeDeploy}, scope: entity.APITokenScopeAdmin, want: false},
+		{name: "admin allows deploy", scopes: []entity.APITokenScope{entity.APITokenScopeAdmin}, scope: entity.APITokenScopeDeploy, want: true},
+		{name: "no scopes", scope: entity.APITokenScopeRead, want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			token := &entity.APIToken{Scopes: tc.scopes}
+			if got := token.Allows(tc.scope); got != tc.want {
+				t.Errorf("Allows(%s) = %v, want %v", tc.scope, got, tc.want)
+			}
+		})
+	}
+}
@@ -6,12 +6,40 @@ import (
 	"github.com/google/uuid"
 )
 
+// RefreshToken belongs to a session. Refreshing marks it used and adds
+// the next one to the session; a used token is kept to detect its reuse.
 type RefreshToken struct {
-	ID        uuid.UUID `json:"id"`
-	UserID    uuid.UUID `json:"user_id"`
-	TokenHash string    `json:"-"`
-	ExpiresAt time.Time `json:"expires_at"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	SessionID uuid.UUID  `json:"session_id"`
+	TokenHash string     `json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (t *RefreshToken) IsUsed() bool {
+	return t.UsedAt != nil
+}
+
+// Session is a login, kept alive by refreshing. It expires with its newest
+// refresh token.
+type Session struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"user_id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	// Current is set on the session of the request that lists sessions
+	Current bool `json:"current"`
+}
+
+// SessionClient describes the client that logged in or refreshed
+type SessionClient struct {
+	UserAgent string
+	IPAddress string
 }
 
 type LoginRequest struct {
@@ -33,4 +61,6 @@ type RefreshRequest struct {
 type JWTClaims struct {
 	UserID uuid.UUID `json:"user_id"`
 	Email  string    `json:"email"`
+	// SessionID is uuid.Nil for access tokens issued before sessions
+	SessionID uuid.UUID `json:"session_id"`
 }
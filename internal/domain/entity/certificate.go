@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Certificate is an uploaded TLS certificate that domains of the team can
+// use instead of Let's Encrypt
+type Certificate struct {
+	ID             uuid.UUID `json:"id"`
+	TeamID         uuid.UUID `json:"team_id"`
+	Name           string    `json:"name"`
+	CertificatePEM string    `json:"-"` // leaf first, then intermediates
+	KeyEncrypted   []byte    `json:"-"`
+	DNSNames       []string  `json:"dns_names"`
+	Subject        string    `json:"subject"`
+	Issuer         string    `json:"issuer"`
+	NotBefore      time.Time `json:"not_before"`
+	NotAfter       time.Time `json:"not_after"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type CertificateCreate struct {
+	Name        string `json:"name" validate:"required,min=2,max=100"`
+	Certificate string `json:"certificate" validate:"required,max=65536"`
+	PrivateKey  string `json:"private_key" validate:"required,max=16384"`
+}
+
+// CertificateUpdate renames a certificate or replaces it, e.g. on renewal.
+// A replacement needs both the new chain and its key.
+type CertificateUpdate struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Certificate *string `json:"certificate,omitempty" validate:"omitempty,max=65536"`
+	PrivateKey  *string `json:"private_key,omitempty" validate:"omitempty,max=16384"`
+}
+
+// Covers reports whether the certificate is valid for host. Wildcard names
+// match exactly one label, as in TLS.
+func (c *Certificate) Covers(host string) bool {
+	host = strings.ToLower(host)
+	for _, name := range c.DNSNames {
+		if name == host {
+			return true
+		}
+		if suffix, ok := strings.CutPrefix(name, "*."); ok {
+			if label, rest, found := strings.Cut(host, "."); found && label != "" && rest == suffix {
+				return true
+			}
+		}
+	}
+	return false
+}
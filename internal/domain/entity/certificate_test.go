@@ -0,0 +1,32 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+func TestCertificate_Covers(t *testing.T) {
+	cert := &entity.Certificate{DNSNames: []string{"example.com", "*.apps.example.com"}}
+
+	testCases := []struct {
+		host     string
+		expected bool
+	}{
+		{"example.com", true},
+		{"EXAMPLE.com", true},
+		{"www.example.com", false},
+		{"api.apps.example.com", true},
+		{"apps.example.com", false},
+		{"deep.api.apps.example.com", false},
+		{"other.com", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.host, func(t *testing.T) {
+			if got := cert.Covers(tc.host); got != tc.expected {
+				t.Errorf("Covers(%q) = %v, expected %v", tc.host, got, tc.expected)
+			}
+		})
+	}
+}
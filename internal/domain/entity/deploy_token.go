@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeployTokenPrefix starts every project deploy token, telling it apart from
+// JWTs and personal API tokens
+const DeployTokenPrefix = "pdk_"
+
+// DeployToken belongs to a project rather than a person. It may only deploy,
+// start, stop and restart the project's services and read their logs.
+type DeployToken struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	Name      string    `json:"name"`
+	TokenState
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type DeployTokenCreate struct {
+	Name      string     `json:"name" validate:"required,min=1,max=100"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
@@ -10,22 +10,28 @@ type DeploymentStatus string
 
 const (
 	DeploymentStatusPending   DeploymentStatus = "pending"
+	DeploymentStatusQueued    DeploymentStatus = "queued"
 	DeploymentStatusBuilding  DeploymentStatus = "building"
 	DeploymentStatusDeploying DeploymentStatus = "deploying"
 	DeploymentStatusSuccess   DeploymentStatus = "success"
 	DeploymentStatusFailed    DeploymentStatus = "failed"
+	DeploymentStatusCancelled DeploymentStatus = "cancelled"
 )
 
 type Deployment struct {
-	ID            uuid.UUID        `json:"id"`
-	ServiceID     uuid.UUID        `json:"service_id"`
-	TriggeredBy   *uuid.UUID       `json:"triggered_by,omitempty"`
-	CommitSHA     *string          `json:"commit_sha,omitempty"`
-	CommitMessage *string          `json:"commit_message,omitempty"`
-	Status        DeploymentStatus `json:"status"`
-	Logs          *string          `json:"logs,omitempty"`
-	StartedAt     time.Time        `json:"started_at"`
-	FinishedAt    *time.Time       `json:"finished_at,omitempty"`
+	ID               uuid.UUID        `json:"id"`
+	ServiceID        uuid.UUID        `json:"service_id"`
+	TriggeredBy      *uuid.UUID       `json:"triggered_by,omitempty"`
+	TriggeredByToken *uuid.UUID       `json:"triggered_by_token,omitempty"`
+	CommitSHA        *string          `json:"commit_sha,omitempty"`
+	CommitMessage    *string          `json:"commit_message,omitempty"`
+	ImageDigest      *string          `json:"image_digest,omitempty"`
+	Status           DeploymentStatus `json:"status"`
+	Logs             *string          `json:"logs,omitempty"`
+	NoCache          bool             `json:"no_cache"`
+	ScheduledFor     *time.Time       `json:"scheduled_for,omitempty"`
+	StartedAt        time.Time        `json:"started_at"`
+	FinishedAt       *time.Time       `json:"finished_at,omitempty"`
 }
 
 type DeploymentCreate struct {
@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,11 +13,121 @@ type Domain struct {
 	Domain     string    `json:"domain"`
 	SSLEnabled bool      `json:"ssl_enabled"`
 	SSLAuto    bool      `json:"ssl_auto"`
+	Port       *int      `json:"port,omitempty"` // container port, nil for the service default
 	CreatedAt  time.Time `json:"created_at"`
+
+	// CertificateID selects an uploaded certificate instead of Let's Encrypt
+	CertificateID *uuid.UUID `json:"certificate_id,omitempty"`
+
+	// Ownership, only verified domains are routed
+	Status            DomainStatus `json:"status"`
+	VerificationToken string       `json:"-"`
+	VerifiedAt        *time.Time   `json:"verified_at,omitempty"`
+
+	// Routing
+	RoutingMode    RoutingMode `json:"routing_mode"`
+	EntryPoint     string      `json:"entry_point,omitempty"` // Traefik entrypoint for tcp and udp
+	TLSPassthrough bool        `json:"tls_passthrough"`
+	PathPrefix     string      `json:"path_prefix,omitempty"`
+	StripPrefix    bool        `json:"strip_prefix"`
+	WWWRedirect    bool        `json:"www_redirect"`
+
+	// Middleware
+	BasicAuthUsers  []string          `json:"-"` // "user:bcrypt-hash" pairs
+	IPAllowList     []string          `json:"ip_allow_list,omitempty"`
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+	RateLimit       *RateLimit        `json:"rate_limit,omitempty"`
+}
+
+type DomainStatus string
+
+const (
+	DomainStatusPending  DomainStatus = "pending"
+	DomainStatusVerified DomainStatus = "verified"
+)
+
+// RoutingMode selects the Traefik router kind a domain gets
+type RoutingMode string
+
+const (
+	RoutingModeHTTP RoutingMode = "http"
+	RoutingModeTCP  RoutingMode = "tcp"
+	RoutingModeUDP  RoutingMode = "udp"
+)
+
+// DomainVerificationPrefix is prepended to a domain to name the TXT record
+// that proves ownership
+const DomainVerificationPrefix = "_podoru-challenge."
+
+// RateLimit caps requests per source IP, averaged over a second
+type RateLimit struct {
+	Average int `json:"average" validate:"required,min=1,max=100000"`
+	Burst   int `json:"burst,omitempty" validate:"omitempty,min=1,max=100000"`
+}
+
+type BasicAuthUser struct {
+	Username string `json:"username" validate:"required,max=100,excludes=:"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
 }
 
 type DomainCreate struct {
 	Domain     string `json:"domain" validate:"required,fqdn,max=255"`
 	SSLEnabled *bool  `json:"ssl_enabled,omitempty"`
 	SSLAuto    *bool  `json:"ssl_auto,omitempty"`
+	Port       *int   `json:"port,omitempty" validate:"omitempty,min=1,max=65535"`
+
+	CertificateID *uuid.UUID `json:"certificate_id,omitempty"`
+
+	RoutingMode    *RoutingMode `json:"routing_mode,omitempty" validate:"omitempty,oneof=http tcp udp"`
+	EntryPoint     *string      `json:"entry_point,omitempty" validate:"omitempty,max=100"`
+	TLSPassthrough *bool        `json:"tls_passthrough,omitempty"`
+
+	PathPrefix  *string `json:"path_prefix,omitempty" validate:"omitempty,startswith=/,max=255,excludesall= ?#"`
+	StripPrefix *bool   `json:"strip_prefix,omitempty"`
+	WWWRedirect *bool   `json:"www_redirect,omitempty"`
+
+	BasicAuth       []BasicAuthUser   `json:"basic_auth,omitempty" validate:"omitempty,max=20,dive"`
+	IPAllowList     []string          `json:"ip_allow_list,omitempty" validate:"omitempty,max=50,dive,cidr|ip"`
+	ResponseHeaders map[string]string `json:"response_headers,omitempty" validate:"omitempty,max=20,dive,keys,required,max=100,excludesall= :,endkeys,max=1000"`
+	RateLimit       *RateLimit        `json:"rate_limit,omitempty"`
+}
+
+// WWWCounterpart returns the host a www redirect answers on: the www-less
+// host for "www." domains and the "www." host otherwise
+func WWWCounterpart(host string) string {
+	if rest, ok := strings.CutPrefix(host, "www."); ok {
+		return rest
+	}
+	return "www." + host
+}
+
+// BasicAuthUsernames lists the users allowed through basic auth
+func (d *Domain) BasicAuthUsernames() []string {
+	names := make([]string, 0, len(d.BasicAuthUsers))
+	for _, user := range d.BasicAuthUsers {
+		name, _, _ := strings.Cut(user, ":")
+		names = append(names, name)
+	}
+	return names
+}
+
+// IsVerified reports whether the domain may be routed
+func (d *Domain) IsVerified() bool {
+	return d.Status == DomainStatusVerified
+}
+
+// VerificationRecord is the TXT record name that must hold VerificationToken
+func (d *Domain) VerificationRecord() string {
+	return DomainVerificationPrefix + d.Domain
+}
+
+// IsHTTP reports whether the domain gets HTTP routers, the default mode
+func (d *Domain) IsHTTP() bool {
+	return d.RoutingMode == "" || d.RoutingMode == RoutingModeHTTP
+}
+
+// MatchesSNI reports whether a TCP domain is routed on its TLS server name.
+// Plain TCP carries no host, so such a domain takes its whole entrypoint.
+func (d *Domain) MatchesSNI() bool {
+	return d.RoutingMode == RoutingModeTCP && (d.SSLEnabled || d.TLSPassthrough)
 }
@@ -0,0 +1,39 @@
+package entity_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+func TestWWWCounterpart(t *testing.T) {
+	testCases := []struct {
+		host     string
+		expected string
+	}{
+		{"example.com", "www.example.com"},
+		{"www.example.com", "example.com"},
+		{"app.example.com", "www.app.example.com"},
+		{"www.app.example.com", "app.example.com"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.host, func(t *testing.T) {
+			if got := entity.WWWCounterpart(tc.host); got != tc.expected {
+				t.Errorf("WWWCounterpart(%q) = %q, expected %q", tc.host, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDomain_BasicAuthUsernames(t *testing.T) {
+	domain := &entity.Domain{
+		BasicAuthUsers: []string{"admin:$2a$10$abc", "ops:$2a$10$def"},
+	}
+
+	expected := []string{"admin", "ops"}
+	if got := domain.BasicAuthUsernames(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,32 @@
+package entity
+
+import "github.com/google/uuid"
+
+// EncryptedColumn names a column holding data sealed by the encryptor
+type EncryptedColumn struct {
+	Table  string
+	Column string
+}
+
+func (c EncryptedColumn) String() string {
+	return c.Table + "." + c.Column
+}
+
+// EncryptedColumns lists every column rotating the encryption key has to
+// re-encrypt. Each table is keyed by a UUID id.
+var EncryptedColumns = []EncryptedColumn{
+	{Table: "projects", Column: "github_token_encrypted"},
+	{Table: "services", Column: "env_vars_encrypted"},
+	{Table: "services", Column: "build_args_encrypted"},
+	{Table: "services", Column: "exports_encrypted"},
+	{Table: "service_env_vars", Column: "value_encrypted"},
+	{Table: "env_groups", Column: "vars_encrypted"},
+	{Table: "certificates", Column: "private_key_encrypted"},
+	{Table: "user_totp", Column: "secret_encrypted"},
+}
+
+// Ciphertext is the encrypted value of one row of an EncryptedColumn
+type Ciphertext struct {
+	ID   uuid.UUID
+	Data []byte
+}
@@ -0,0 +1,113 @@
+package entity
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EnvLayer is where a service's env var comes from. A service's own vars
+// override its project's groups, which override its team's groups.
+type EnvLayer string
+
+const (
+	EnvLayerTeam    EnvLayer = "team"
+	EnvLayerProject EnvLayer = "project"
+	EnvLayerService EnvLayer = "service"
+)
+
+// EnvGroup is a set of env vars shared by the services it is attached to.
+// Team groups can be attached to any service of the team, project groups
+// to the services of their project.
+type EnvGroup struct {
+	ID            uuid.UUID  `json:"id"`
+	TeamID        uuid.UUID  `json:"team_id"`
+	ProjectID     *uuid.UUID `json:"project_id,omitempty"`
+	Name          string     `json:"name"`
+	VarsEncrypted []byte     `json:"-"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// Keys lists the group's variable names
+	Keys []string `json:"keys,omitempty"`
+}
+
+type EnvGroupCreate struct {
+	Name string            `json:"name" validate:"required,min=2,max=100"`
+	Vars map[string]string `json:"vars" validate:"required"`
+}
+
+// EnvGroupUpdate renames a group or replaces all of its vars
+type EnvGroupUpdate struct {
+	Name *string           `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Vars map[string]string `json:"vars,omitempty"`
+}
+
+// Layer reports whether the group belongs to a team or a project
+func (g *EnvGroup) Layer() EnvLayer {
+	if g.ProjectID != nil {
+		return EnvLayerProject
+	}
+	return EnvLayerTeam
+}
+
+// EnvSource identifies the layer, and the group within it, a var comes from
+type EnvSource struct {
+	Layer     EnvLayer   `json:"layer"`
+	GroupID   *uuid.UUID `json:"group_id,omitempty"`
+	GroupName string     `json:"group_name,omitempty"`
+}
+
+// EnvVarSet is the vars of one group, or of the service itself
+type EnvVarSet struct {
+	Source EnvSource
+	Vars   map[string]string
+}
+
+// ResolvedEnvVar is one key of a service's merged env, with the source it
+// comes from and the sources it overrides, highest precedence first
+type ResolvedEnvVar struct {
+	Key       string      `json:"key"`
+	Value     string      `json:"value"`
+	Source    EnvSource   `json:"source"`
+	Overrides []EnvSource `json:"overrides,omitempty"`
+}
+
+// MergeEnv merges sets given in increasing precedence, so a later set
+// overrides the keys of earlier ones. The result is sorted by key.
+func MergeEnv(sets []EnvVarSet) []ResolvedEnvVar {
+	merged := make(map[string]*ResolvedEnvVar)
+	for _, set := range sets {
+		for key, value := range set.Vars {
+			v, ok := merged[key]
+			if !ok {
+				merged[key] = &ResolvedEnvVar{Key: key, Value: value, Source: set.Source}
+				continue
+			}
+			v.Overrides = append([]EnvSource{v.Source}, v.Overrides...)
+			v.Value = value
+			v.Source = set.Source
+		}
+	}
+
+	resolved := make([]ResolvedEnvVar, 0, len(merged))
+	for _, v := range merged {
+		resolved = append(resolved, *v)
+	}
+	sort.Slice(resolved, func(i, j int) bool { return resolved[i].Key < resolved[j].Key })
+	return resolved
+}
+
+// MaskValue hides a secret for display. Long values keep their first three
+// characters so similar values can be told apart.
+func MaskValue(value string) string {
+	switch {
+	case value == "":
+		return ""
+	case len(value) < 12:
+		return SecretMask
+	default:
+		return value[:3] + SecretMask
+	}
+}
@@ -0,0 +1,74 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+func TestMergeEnv(t *testing.T) {
+	teamGroup := uuid.New()
+	projectGroup := uuid.New()
+
+	team := entity.EnvSource{Layer: entity.EnvLayerTeam, GroupID: &teamGroup, GroupName: "shared"}
+	project := entity.EnvSource{Layer: entity.EnvLayerProject, GroupID: &projectGroup, GroupName: "backend"}
+	service := entity.EnvSource{Layer: entity.EnvLayerService}
+
+	resolved := entity.MergeEnv([]entity.EnvVarSet{
+		{Source: team, Vars: map[string]string{"LOG_LEVEL": "info", "REGION": "eu", "SENTRY_DSN": "team"}},
+		{Source: project, Vars: map[string]string{"LOG_LEVEL": "debug", "SENTRY_DSN": "project"}},
+		{Source: service, Vars: map[string]string{"SENTRY_DSN": "service", "PORT": "8080"}},
+	})
+
+	testCases := []struct {
+		key       string
+		value     string
+		source    entity.EnvLayer
+		overrides []entity.EnvLayer
+	}{
+		{"LOG_LEVEL", "debug", entity.EnvLayerProject, []entity.EnvLayer{entity.EnvLayerTeam}},
+		{"PORT", "8080", entity.EnvLayerService, nil},
+		{"REGION", "eu", entity.EnvLayerTeam, nil},
+		{"SENTRY_DSN", "service", entity.EnvLayerService, []entity.EnvLayer{entity.EnvLayerProject, entity.EnvLayerTeam}},
+	}
+
+	if len(resolved) != len(testCases) {
+		t.Fatalf("MergeEnv() returned %d vars, expected %d", len(resolved), len(testCases))
+	}
+	for i, tc := range testCases {
+		t.Run(tc.key, func(t *testing.T) {
+			got := resolved[i]
+			if got.Key != tc.key || got.Value != tc.value || got.Source.Layer != tc.source {
+				t.Errorf("MergeEnv()[%d] = %s=%s from %s, expected %s=%s from %s",
+					i, got.Key, got.Value, got.Source.Layer, tc.key, tc.value, tc.source)
+			}
+			if len(got.Overrides) != len(tc.overrides) {
+				t.Fatalf("overrides = %v, expected %v", got.Overrides, tc.overrides)
+			}
+			for j, layer := range tc.overrides {
+				if got.Overrides[j].Layer != layer {
+					t.Errorf("overrides[%d] = %s, expected %s", j, got.Overrides[j].Layer, layer)
+				}
+			}
+		})
+	}
+}
+
+func TestMaskValue(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected string
+	}{
+		{"", ""},
+		{"short", "********"},
+		{"postgres://app:secret@db/app", "pos********"},
+	}
+
+	for _, tc := range testCases {
+		if got := entity.MaskValue(tc.value); got != tc.expected {
+			t.Errorf("MaskValue(%q) = %q, expected %q", tc.value, got, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package entity
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaintenanceWindow restricts when a project's deployments may run, e.g.
+// weekdays 02:00-04:00 UTC. An End before Start spans midnight, and Days
+// refers to the day the window opens.
+type MaintenanceWindow struct {
+	Days     []string `json:"days" validate:"omitempty,dive,oneof=mon tue wed thu fri sat sun"`
+	Start    string   `json:"start" validate:"required_with=Days,omitempty,datetime=15:04"`
+	End      string   `json:"end" validate:"required_with=Days,omitempty,datetime=15:04"`
+	Timezone string   `json:"timezone,omitempty" validate:"omitempty,timezone"`
+}
+
+var weekdayNames = map[time.Weekday]string{
+	time.Sunday:    "sun",
+	time.Monday:    "mon",
+	time.Tuesday:   "tue",
+	time.Wednesday: "wed",
+	time.Thursday:  "thu",
+	time.Friday:    "fri",
+	time.Saturday:  "sat",
+}
+
+// IsEmpty reports whether the window has no days, which lifts the restriction
+func (w *MaintenanceWindow) IsEmpty() bool {
+	return len(w.Days) == 0
+}
+
+// Contains reports whether t falls inside the window
+func (w *MaintenanceWindow) Contains(t time.Time) bool {
+	local := t.In(w.location())
+
+	// A window that opened yesterday may still be running past midnight
+	for _, offset := range []int{0, -1} {
+		start, end, ok := w.occurrence(local.AddDate(0, 0, offset))
+		if ok && !local.Before(start) && local.Before(end) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpen returns t if it is inside the window, otherwise the next time the
+// window opens
+func (w *MaintenanceWindow) NextOpen(t time.Time) time.Time {
+	if w.Contains(t) {
+		return t
+	}
+
+	local := t.In(w.location())
+	for offset := 0; offset <= 7; offset++ {
+		start, _, ok := w.occurrence(local.AddDate(0, 0, offset))
+		if ok && start.After(local) {
+			return start
+		}
+	}
+
+	// Unreachable for a valid window; never schedule into the past
+	return t
+}
+
+// occurrence returns the window opening on the given day, if it opens that day
+func (w *MaintenanceWindow) occurrence(day time.Time) (time.Time, time.Time, bool) {
+	if !w.opensOn(day.Weekday()) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	startHour, startMin := parseClock(w.Start)
+	endHour, endMin := parseClock(w.End)
+
+	y, m, d := day.Date()
+	start := time.Date(y, m, d, startHour, startMin, 0, 0, day.Location())
+	end := time.Date(y, m, d, endHour, endMin, 0, 0, day.Location())
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end, true
+}
+
+func (w *MaintenanceWindow) opensOn(day time.Weekday) bool {
+	name := weekdayNames[day]
+	for _, d := range w.Days {
+		if strings.EqualFold(d, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *MaintenanceWindow) location() *time.Location {
+	if w.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// parseClock splits a validated "15:04" string into hour and minute
+func parseClock(s string) (int, int) {
+	h, m, _ := strings.Cut(s, ":")
+	hour, _ := strconv.Atoi(h)
+	minute, _ := strconv.Atoi(m)
+	return hour, minute
+}
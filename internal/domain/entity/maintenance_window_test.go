@@ -0,0 +1,83 @@
+package entity_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+func TestMaintenanceWindow_Contains(t *testing.T) {
+	weekdays := &entity.MaintenanceWindow{
+		Days:  []string{"mon", "tue", "wed", "thu", "fri"},
+		Start: "02:00",
+		End:   "04:00",
+	}
+	overnight := &entity.MaintenanceWindow{
+		Days:  []string{"sat"},
+		Start: "22:00",
+		End:   "02:00",
+	}
+
+	testCases := []struct {
+		name     string
+		window   *entity.MaintenanceWindow
+		at       time.Time
+		expected bool
+	}{
+		{"inside weekday window", weekdays, time.Date(2026, 1, 5, 3, 0, 0, 0, time.UTC), true},
+		{"at window start", weekdays, time.Date(2026, 1, 5, 2, 0, 0, 0, time.UTC), true},
+		{"at window end", weekdays, time.Date(2026, 1, 5, 4, 0, 0, 0, time.UTC), false},
+		{"outside hours", weekdays, time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC), false},
+		{"weekend", weekdays, time.Date(2026, 1, 4, 3, 0, 0, 0, time.UTC), false},
+		{"overnight before midnight", overnight, time.Date(2026, 1, 3, 23, 0, 0, 0, time.UTC), true},
+		{"overnight after midnight", overnight, time.Date(2026, 1, 4, 1, 0, 0, 0, time.UTC), true},
+		{"overnight wrong day", overnight, time.Date(2026, 1, 4, 23, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.window.Contains(tc.at); got != tc.expected {
+			t.Errorf("%s: Contains(%s) = %v, expected %v", tc.name, tc.at, got, tc.expected)
+		}
+	}
+}
+
+func TestMaintenanceWindow_NextOpen(t *testing.T) {
+	window := &entity.MaintenanceWindow{
+		Days:  []string{"mon", "tue", "wed", "thu", "fri"},
+		Start: "02:00",
+		End:   "04:00",
+	}
+
+	testCases := []struct {
+		name     string
+		at       time.Time
+		expected time.Time
+	}{
+		{"inside window", time.Date(2026, 1, 5, 3, 0, 0, 0, time.UTC), time.Date(2026, 1, 5, 3, 0, 0, 0, time.UTC)},
+		{"later the same day", time.Date(2026, 1, 5, 1, 0, 0, 0, time.UTC), time.Date(2026, 1, 5, 2, 0, 0, 0, time.UTC)},
+		{"after window", time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC), time.Date(2026, 1, 6, 2, 0, 0, 0, time.UTC)},
+		{"friday evening", time.Date(2026, 1, 9, 18, 0, 0, 0, time.UTC), time.Date(2026, 1, 12, 2, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range testCases {
+		if got := window.NextOpen(tc.at); !got.Equal(tc.expected) {
+			t.Errorf("%s: NextOpen(%s) = %s, expected %s", tc.name, tc.at, got, tc.expected)
+		}
+	}
+}
+
+func TestMaintenanceWindow_Timezone(t *testing.T) {
+	window := &entity.MaintenanceWindow{
+		Days:     []string{"mon"},
+		Start:    "02:00",
+		End:      "04:00",
+		Timezone: "Asia/Jakarta",
+	}
+
+	// 02:30 in Jakarta (UTC+7) is 19:30 UTC on the Sunday before
+	at := time.Date(2026, 1, 4, 19, 30, 0, 0, time.UTC)
+	if !window.Contains(at) {
+		t.Errorf("expected %s to be inside the Jakarta window", at)
+	}
+}
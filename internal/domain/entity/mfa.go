@@ -0,0 +1,91 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MFARequirement says who has to use two-factor authentication
+type MFARequirement string
+
+const (
+	MFARequiredNone MFARequirement = "none"
+	// MFARequiredOwners requires it from users who own a team
+	MFARequiredOwners MFARequirement = "owners"
+	MFARequiredAll    MFARequirement = "all"
+)
+
+// MFAPolicy is the instance-wide two-factor policy set by a superadmin
+type MFAPolicy struct {
+	Required  MFARequirement `json:"required"`
+	UpdatedBy *uuid.UUID     `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time     `json:"updated_at,omitempty"`
+}
+
+type MFAPolicyUpdate struct {
+	Required MFARequirement `json:"required" validate:"required,oneof=none owners all"`
+}
+
+// UserTOTP is a user's authenticator. It is enrolled once confirmed with a
+// code; until then it is not asked for at login.
+type UserTOTP struct {
+	ID              uuid.UUID  `json:"id"`
+	UserID          uuid.UUID  `json:"user_id"`
+	SecretEncrypted []byte     `json:"-"`
+	ConfirmedAt     *time.Time `json:"confirmed_at,omitempty"`
+	// LastUsedStep is the time step of the last accepted code
+	LastUsedStep int64     `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (t *UserTOTP) IsConfirmed() bool {
+	return t.ConfirmedAt != nil
+}
+
+// MFARecoveryCode stands in for a TOTP code once, for when the
+// authenticator is lost. Only its hash is stored.
+type MFARecoveryCode struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	CodeHash  string     `json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// MFAChallenge is a login that passed the password check and waits for the
+// second factor. Enrollment challenges belong to users the policy requires
+// to enrol first.
+type MFAChallenge struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"user_id"`
+	TokenHash  string    `json:"-"`
+	Enrollment bool      `json:"enrollment"`
+	Attempts   int       `json:"attempts"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// MFAPendingLogin is returned by a login needing a second factor instead of
+// a token pair. Token is the only plaintext copy of the challenge token.
+type MFAPendingLogin struct {
+	Token              string    `json:"mfa_token"`
+	ExpiresAt          time.Time `json:"expires_at"`
+	EnrollmentRequired bool      `json:"enrollment_required"`
+}
+
+// TOTPEnrollment is what a user needs to set up an authenticator. The
+// recovery codes are shown this once and take effect with the authenticator.
+type TOTPEnrollment struct {
+	Secret          string   `json:"secret"`
+	ProvisioningURI string   `json:"provisioning_uri"`
+	RecoveryCodes   []string `json:"recovery_codes"`
+}
+
+type MFAStatus struct {
+	Enabled                bool       `json:"enabled"`
+	ConfirmedAt            *time.Time `json:"confirmed_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+	// Required is set when the policy requires two-factor from the user
+	Required bool `json:"required"`
+}
@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,6 +14,10 @@ const (
 	NetworkDriverOverlay NetworkDriver = "overlay"
 )
 
+// DefaultNetworkName is the network every project gets; all of its services
+// join it and can reach each other by slug
+const DefaultNetworkName = "default"
+
 type Network struct {
 	ID              uuid.UUID     `json:"id"`
 	ProjectID       *uuid.UUID    `json:"project_id,omitempty"`
@@ -23,11 +28,19 @@ type Network struct {
 	Gateway         *string       `json:"gateway,omitempty"`
 	IsDefault       bool          `json:"is_default"`
 	CreatedAt       time.Time     `json:"created_at"`
+
+	// ServiceIDs lists the services attached to the network
+	ServiceIDs []uuid.UUID `json:"service_ids,omitempty"`
 }
 
 type NetworkCreate struct {
-	Name    string         `json:"name" validate:"required,min=1,max=255"`
+	Name    string         `json:"name" validate:"required,min=2,max=63,slug,ne=default"`
 	Driver  *NetworkDriver `json:"driver,omitempty" validate:"omitempty,oneof=bridge overlay"`
 	Subnet  *string        `json:"subnet,omitempty" validate:"omitempty,cidr"`
 	Gateway *string        `json:"gateway,omitempty" validate:"omitempty,ip"`
 }
+
+// DockerNetworkName returns the host-wide Docker name for a project network
+func DockerNetworkName(projectID uuid.UUID, name string) string {
+	return fmt.Sprintf("podoru-%s-%s", projectID, name)
+}
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OIDCState is a single sign-on attempt waiting for the provider to
+// redirect the user back. It is used once.
+type OIDCState struct {
+	State        string    `json:"-"`
+	Nonce        string    `json:"-"`
+	CodeVerifier string    `json:"-"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// OIDCGroupMapping grants the members of an identity provider group a role
+// in a team. Like invitations, it grants admin or member; owners stay as they are.
+type OIDCGroupMapping struct {
+	Group    string
+	TeamSlug string
+	Role     TeamRole
+}
+
+// ParseOIDCGroupMapping parses a mapping written as "group=team-slug:role"
+func ParseOIDCGroupMapping(s string) (OIDCGroupMapping, error) {
+	group, target, ok := strings.Cut(strings.TrimSpace(s), "=")
+	if !ok {
+		return OIDCGroupMapping{}, fmt.Errorf("group mapping %q is not group=team-slug:role", s)
+	}
+	slug, role, ok := strings.Cut(target, ":")
+	if !ok || group == "" || slug == "" {
+		return OIDCGroupMapping{}, fmt.Errorf("group mapping %q is not group=team-slug:role", s)
+	}
+
+	switch r := TeamRole(role); r {
+	case TeamRoleAdmin, TeamRoleMember:
+		return OIDCGroupMapping{Group: group, TeamSlug: slug, Role: r}, nil
+	default:
+		return OIDCGroupMapping{}, fmt.Errorf("group mapping %q has unknown role %q", s, role)
+	}
+}
@@ -0,0 +1,42 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+func TestParseOIDCGroupMapping(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		want    entity.OIDCGroupMapping
+		wantErr bool
+	}{
+		{name: "member", input: "engineers=platform:member", want: entity.OIDCGroupMapping{Group: "engineers", TeamSlug: "platform", Role: entity.TeamRoleMember}},
+		{name: "admin with spaces", input: " platform-admins=platform:admin ", want: entity.OIDCGroupMapping{Group: "platform-admins", TeamSlug: "platform", Role: entity.TeamRoleAdmin}},
+		{name: "owner not allowed", input: "engineers=platform:owner", wantErr: true},
+		{name: "no role", input: "engineers=platform", wantErr: true},
+		{name: "no group", input: "=platform:member", wantErr: true},
+		{name: "no team", input: "engineers=:member", wantErr: true},
+		{name: "no separator", input: "engineers", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := entity.ParseOIDCGroupMapping(tc.input)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("ParseOIDCGroupMapping(%q) = %+v, want %+v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
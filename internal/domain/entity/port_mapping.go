@@ -13,6 +13,10 @@ type PortMapping struct {
 	HostPort      *int      `json:"host_port,omitempty"`
 	Protocol      string    `json:"protocol"`
 	CreatedAt     time.Time `json:"created_at"`
+
+	// HostIP limits a published port to one host address. Only Podoru's own
+	// containers set it; service mappings listen on every address.
+	HostIP string `json:"-"`
 }
 
 type PortMappingCreate struct {
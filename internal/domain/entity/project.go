@@ -7,18 +7,19 @@ import (
 )
 
 type Project struct {
-	ID                   uuid.UUID `json:"id"`
-	TeamID               uuid.UUID `json:"team_id"`
-	Name                 string    `json:"name"`
-	Slug                 string    `json:"slug"`
-	Description          *string   `json:"description,omitempty"`
-	GithubRepo           *string   `json:"github_repo,omitempty"`
-	GithubBranch         string    `json:"github_branch"`
-	GithubTokenEncrypted []byte    `json:"-"`
-	AutoDeploy           bool      `json:"auto_deploy"`
-	WebhookSecret        *string   `json:"-"`
-	CreatedAt            time.Time `json:"created_at"`
-	UpdatedAt            time.Time `json:"updated_at"`
+	ID                   uuid.UUID          `json:"id"`
+	TeamID               uuid.UUID          `json:"team_id"`
+	Name                 string             `json:"name"`
+	Slug                 string             `json:"slug"`
+	Description          *string            `json:"description,omitempty"`
+	GithubRepo           *string            `json:"github_repo,omitempty"`
+	GithubBranch         string             `json:"github_branch"`
+	GithubTokenEncrypted []byte             `json:"-"`
+	AutoDeploy           bool               `json:"auto_deploy"`
+	WebhookSecret        *string            `json:"-"`
+	MaintenanceWindow    *MaintenanceWindow `json:"maintenance_window,omitempty"`
+	CreatedAt            time.Time          `json:"created_at"`
+	UpdatedAt            time.Time          `json:"updated_at"`
 }
 
 type ProjectCreate struct {
@@ -29,6 +30,8 @@ type ProjectCreate struct {
 	GithubBranch *string `json:"github_branch,omitempty" validate:"omitempty,max=100"`
 	GithubToken  *string `json:"github_token,omitempty"`
 	AutoDeploy   *bool   `json:"auto_deploy,omitempty"`
+
+	MaintenanceWindow *MaintenanceWindow `json:"maintenance_window,omitempty"`
 }
 
 type ProjectUpdate struct {
@@ -38,6 +41,16 @@ type ProjectUpdate struct {
 	GithubBranch *string `json:"github_branch,omitempty" validate:"omitempty,max=100"`
 	GithubToken  *string `json:"github_token,omitempty"`
 	AutoDeploy   *bool   `json:"auto_deploy,omitempty"`
+
+	// An empty window (no days) removes the restriction
+	MaintenanceWindow *MaintenanceWindow `json:"maintenance_window,omitempty"`
+}
+
+// ProjectWebhook is what a repository host needs to deliver push events
+type ProjectWebhook struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	Path      string    `json:"path"`
+	Secret    string    `json:"secret"`
 }
 
 type ProjectWithServices struct {
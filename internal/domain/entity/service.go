@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,8 +13,15 @@ const (
 	DeployTypeImage      DeployType = "image"
 	DeployTypeDockerfile DeployType = "dockerfile"
 	DeployTypeCompose    DeployType = "compose"
+	DeployTypeAuto       DeployType = "auto"
 )
 
+// BuildsFromSource reports whether the deploy type builds an image from the
+// project repository rather than pulling one
+func (t DeployType) BuildsFromSource() bool {
+	return t == DeployTypeDockerfile || t == DeployTypeAuto
+}
+
 type ServiceStatus string
 
 const (
@@ -33,59 +41,148 @@ const (
 )
 
 type Service struct {
-	ID                  uuid.UUID      `json:"id"`
-	ProjectID           uuid.UUID      `json:"project_id"`
-	Name                string         `json:"name"`
-	Slug                string         `json:"slug"`
-	DeployType          DeployType     `json:"deploy_type"`
-	Image               *string        `json:"image,omitempty"`
-	DockerfilePath      string         `json:"dockerfile_path"`
-	BuildContext        string         `json:"build_context"`
-	ComposeFile         *string        `json:"compose_file,omitempty"`
-	EnvVarsEncrypted    []byte         `json:"-"`
-	Replicas            int            `json:"replicas"`
-	CPULimit            *float64       `json:"cpu_limit,omitempty"`
-	MemoryLimit         *int           `json:"memory_limit,omitempty"`
-	HealthCheckPath     *string        `json:"health_check_path,omitempty"`
-	HealthCheckInterval int            `json:"health_check_interval"`
-	RestartPolicy       RestartPolicy  `json:"restart_policy"`
-	Status              ServiceStatus  `json:"status"`
-	ContainerID         *string        `json:"container_id,omitempty"`
-	SwarmServiceID      *string        `json:"swarm_service_id,omitempty"`
-	CreatedAt           time.Time      `json:"created_at"`
-	UpdatedAt           time.Time      `json:"updated_at"`
+	ID                   uuid.UUID     `json:"id"`
+	ProjectID            uuid.UUID     `json:"project_id"`
+	Name                 string        `json:"name"`
+	Slug                 string        `json:"slug"`
+	DeployType           DeployType    `json:"deploy_type"`
+	Image                *string       `json:"image,omitempty"`
+	DockerfilePath       string        `json:"dockerfile_path"`
+	BuildContext         string        `json:"build_context"`
+	BuildArgsEncrypted   []byte        `json:"-"`
+	BuildTarget          *string       `json:"build_target,omitempty"`
+	ComposeFile          *string       `json:"compose_file,omitempty"`
+	EnvVarsEncrypted     []byte        `json:"-"` // before per-key env vars, imported at startup
+	ExportsEncrypted     []byte        `json:"-"`
+	Replicas             int           `json:"replicas"`
+	CPULimit             *float64      `json:"cpu_limit,omitempty"`
+	MemoryLimit          *int          `json:"memory_limit,omitempty"`
+	HealthCheckPath      *string       `json:"health_check_path,omitempty"`
+	HealthCheckInterval  int           `json:"health_check_interval"`
+	RestartPolicy        RestartPolicy `json:"restart_policy"`
+	Status               ServiceStatus `json:"status"`
+	ContainerID          *string       `json:"container_id,omitempty"`
+	SwarmServiceID       *string       `json:"swarm_service_id,omitempty"`
+	ImageDigest          *string       `json:"image_digest,omitempty"`
+	LatestImageDigest    *string       `json:"latest_image_digest,omitempty"`
+	ImageUpdateAvailable bool          `json:"image_update_available"`
+	ImageCheckedAt       *time.Time    `json:"image_checked_at,omitempty"`
+	AutoUpdate           bool          `json:"auto_update"`
+	AutoUpdateInterval   int           `json:"auto_update_interval"`
+	CreatedAt            time.Time     `json:"created_at"`
+	UpdatedAt            time.Time     `json:"updated_at"`
 }
 
 type ServiceCreate struct {
-	Name                string         `json:"name" validate:"required,min=2,max=100"`
-	Slug                string         `json:"slug" validate:"required,min=2,max=100,slug"`
-	DeployType          DeployType     `json:"deploy_type" validate:"required,oneof=image dockerfile compose"`
-	Image               *string        `json:"image,omitempty" validate:"omitempty,max=500"`
-	DockerfilePath      *string        `json:"dockerfile_path,omitempty" validate:"omitempty,max=500"`
-	BuildContext        *string        `json:"build_context,omitempty" validate:"omitempty,max=500"`
-	ComposeFile         *string        `json:"compose_file,omitempty" validate:"omitempty,max=500"`
+	Name                string            `json:"name" validate:"required,min=2,max=100"`
+	Slug                string            `json:"slug" validate:"required,min=2,max=100,slug"`
+	DeployType          DeployType        `json:"deploy_type" validate:"required,oneof=image dockerfile compose auto"`
+	Image               *string           `json:"image,omitempty" validate:"omitempty,max=500"`
+	DockerfilePath      *string           `json:"dockerfile_path,omitempty" validate:"omitempty,max=500"`
+	BuildContext        *string           `json:"build_context,omitempty" validate:"omitempty,max=500"`
+	BuildArgs           map[string]string `json:"build_args,omitempty"`
+	BuildTarget         *string           `json:"build_target,omitempty" validate:"omitempty,max=100"`
+	ComposeFile         *string           `json:"compose_file,omitempty" validate:"omitempty,max=500"`
 	EnvVars             map[string]string `json:"env_vars,omitempty"`
-	Replicas            *int           `json:"replicas,omitempty" validate:"omitempty,min=1,max=100"`
-	CPULimit            *float64       `json:"cpu_limit,omitempty" validate:"omitempty,min=0.1,max=128"`
-	MemoryLimit         *int           `json:"memory_limit,omitempty" validate:"omitempty,min=32,max=524288"`
-	HealthCheckPath     *string        `json:"health_check_path,omitempty" validate:"omitempty,max=255"`
-	HealthCheckInterval *int           `json:"health_check_interval,omitempty" validate:"omitempty,min=5,max=300"`
-	RestartPolicy       *RestartPolicy `json:"restart_policy,omitempty" validate:"omitempty,oneof=no always on-failure unless-stopped"`
+	Secrets             map[string]string `json:"secrets,omitempty"`
+	Exports             map[string]string `json:"exports,omitempty"`
+	Replicas            *int              `json:"replicas,omitempty" validate:"omitempty,min=1,max=100"`
+	CPULimit            *float64          `json:"cpu_limit,omitempty" validate:"omitempty,min=0.1,max=128"`
+	MemoryLimit         *int              `json:"memory_limit,omitempty" validate:"omitempty,min=32,max=524288"`
+	HealthCheckPath     *string           `json:"health_check_path,omitempty" validate:"omitempty,max=255"`
+	HealthCheckInterval *int              `json:"health_check_interval,omitempty" validate:"omitempty,min=5,max=300"`
+	RestartPolicy       *RestartPolicy    `json:"restart_policy,omitempty" validate:"omitempty,oneof=no always on-failure unless-stopped"`
+	AutoUpdate          *bool             `json:"auto_update,omitempty"`
+	AutoUpdateInterval  *int              `json:"auto_update_interval,omitempty" validate:"omitempty,min=300,max=604800"`
 }
 
 type ServiceUpdate struct {
-	Name                *string        `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
-	Image               *string        `json:"image,omitempty" validate:"omitempty,max=500"`
-	DockerfilePath      *string        `json:"dockerfile_path,omitempty" validate:"omitempty,max=500"`
-	BuildContext        *string        `json:"build_context,omitempty" validate:"omitempty,max=500"`
-	ComposeFile         *string        `json:"compose_file,omitempty" validate:"omitempty,max=500"`
+	Name                *string           `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Image               *string           `json:"image,omitempty" validate:"omitempty,max=500"`
+	DockerfilePath      *string           `json:"dockerfile_path,omitempty" validate:"omitempty,max=500"`
+	BuildContext        *string           `json:"build_context,omitempty" validate:"omitempty,max=500"`
+	BuildArgs           map[string]string `json:"build_args,omitempty"`
+	BuildTarget         *string           `json:"build_target,omitempty" validate:"omitempty,max=100"`
+	ComposeFile         *string           `json:"compose_file,omitempty" validate:"omitempty,max=500"`
 	EnvVars             map[string]string `json:"env_vars,omitempty"`
-	Replicas            *int           `json:"replicas,omitempty" validate:"omitempty,min=1,max=100"`
-	CPULimit            *float64       `json:"cpu_limit,omitempty" validate:"omitempty,min=0.1,max=128"`
-	MemoryLimit         *int           `json:"memory_limit,omitempty" validate:"omitempty,min=32,max=524288"`
-	HealthCheckPath     *string        `json:"health_check_path,omitempty" validate:"omitempty,max=255"`
-	HealthCheckInterval *int           `json:"health_check_interval,omitempty" validate:"omitempty,min=5,max=300"`
-	RestartPolicy       *RestartPolicy `json:"restart_policy,omitempty" validate:"omitempty,oneof=no always on-failure unless-stopped"`
+	Secrets             map[string]string `json:"secrets,omitempty"`
+	Exports             map[string]string `json:"exports,omitempty"`
+	Replicas            *int              `json:"replicas,omitempty" validate:"omitempty,min=1,max=100"`
+	CPULimit            *float64          `json:"cpu_limit,omitempty" validate:"omitempty,min=0.1,max=128"`
+	MemoryLimit         *int              `json:"memory_limit,omitempty" validate:"omitempty,min=32,max=524288"`
+	HealthCheckPath     *string           `json:"health_check_path,omitempty" validate:"omitempty,max=255"`
+	HealthCheckInterval *int              `json:"health_check_interval,omitempty" validate:"omitempty,min=5,max=300"`
+	RestartPolicy       *RestartPolicy    `json:"restart_policy,omitempty" validate:"omitempty,oneof=no always on-failure unless-stopped"`
+	AutoUpdate          *bool             `json:"auto_update,omitempty"`
+	AutoUpdateInterval  *int              `json:"auto_update_interval,omitempty" validate:"omitempty,min=300,max=604800"`
+}
+
+// ContainerName is the name of the service's container, which other
+// containers on a shared network can also resolve it by
+func (s *Service) ContainerName() string {
+	return "podoru-" + s.Slug
+}
+
+// PlatformHostname is the hostname a service gets under the instance's base
+// domain. The label is cut to the 63 characters DNS allows.
+func PlatformHostname(serviceSlug, projectSlug, baseDomain string) string {
+	label := serviceSlug + "-" + projectSlug
+	if len(label) > 63 {
+		label = strings.TrimRight(label[:63], "-")
+	}
+	return label + "." + baseDomain
+}
+
+// PinnedImageRef replaces the tag of an image reference with a digest,
+// e.g. "nginx:latest" + "sha256:abc" becomes "nginx@sha256:abc"
+func PinnedImageRef(image, digest string) string {
+	repo := image
+	if i := strings.LastIndex(repo, "@"); i >= 0 {
+		repo = repo[:i]
+	}
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo = repo[:i]
+	}
+	return repo + "@" + digest
+}
+
+// NeedsImageCheck reports whether the service's image tag is due to be
+// compared against the registry.
+func (s *Service) NeedsImageCheck(now time.Time) bool {
+	if s.DeployType != DeployTypeImage || s.Image == nil || *s.Image == "" {
+		return false
+	}
+	if s.ImageCheckedAt == nil {
+		return true
+	}
+	return now.Sub(*s.ImageCheckedAt) >= time.Duration(s.AutoUpdateInterval)*time.Second
+}
+
+// DeployOptions tweaks a single deployment without changing the service
+type DeployOptions struct {
+	NoCache      bool       `json:"no_cache"`
+	ScheduledFor *time.Time `json:"scheduled_for,omitempty"`
+
+	// IgnoreMaintenanceWindow deploys now even outside the project's window
+	IgnoreMaintenanceWindow bool `json:"ignore_maintenance_window"`
+}
+
+// ScheduleFor returns when a deployment requested at now should run, or nil
+// to run it immediately. An explicit time outside the maintenance window is
+// moved to the next opening unless the window is ignored.
+func (o *DeployOptions) ScheduleFor(window *MaintenanceWindow, now time.Time) *time.Time {
+	start := now
+	if o.ScheduledFor != nil && o.ScheduledFor.After(now) {
+		start = *o.ScheduledFor
+	}
+
+	if window != nil && !o.IgnoreMaintenanceWindow {
+		start = window.NextOpen(start)
+	}
+	if start.After(now) {
+		return &start
+	}
+	return nil
 }
 
 type ServiceScale struct {
@@ -94,8 +191,21 @@ type ServiceScale struct {
 
 type ServiceWithDetails struct {
 	Service
-	EnvVars      map[string]string `json:"env_vars,omitempty"`
-	Domains      []Domain          `json:"domains,omitempty"`
-	PortMappings []PortMapping     `json:"port_mappings,omitempty"`
-	Volumes      []Volume          `json:"volumes,omitempty"`
+	EnvVars      []ServiceEnvVar `json:"env_vars,omitempty"` // secrets masked
+	Domains      []Domain        `json:"domains,omitempty"`
+	PortMappings []PortMapping   `json:"port_mappings,omitempty"`
+	Volumes      []Volume        `json:"volumes,omitempty"`
+
+	// PlatformDomain is the generated hostname under the base domain, empty
+	// when no base domain is configured
+	PlatformDomain string `json:"platform_domain,omitempty"`
+}
+
+type ImageStatus struct {
+	ServiceID            uuid.UUID  `json:"service_id"`
+	Image                string     `json:"image"`
+	CurrentDigest        *string    `json:"current_digest,omitempty"`
+	LatestDigest         *string    `json:"latest_digest,omitempty"`
+	ImageUpdateAvailable bool       `json:"image_update_available"`
+	CheckedAt            *time.Time `json:"checked_at,omitempty"`
 }
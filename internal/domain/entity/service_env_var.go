@@ -0,0 +1,93 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SecretMask stands in for values the caller may not read
+const SecretMask = "********"
+
+// ServiceEnvVar is one env var of a service. Secret values are write-only:
+// they are masked everywhere except the admin reveal endpoint.
+type ServiceEnvVar struct {
+	ID             uuid.UUID `json:"id"`
+	ServiceID      uuid.UUID `json:"service_id"`
+	Key            string    `json:"key"`
+	ValueEncrypted []byte    `json:"-"`
+	Value          string    `json:"value"`
+	IsSecret       bool      `json:"is_secret"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ServiceEnvVarUpdate sets one env var. Value is required for a new key;
+// omitting it on an existing key only changes whether it is secret.
+type ServiceEnvVarUpdate struct {
+	Value    *string `json:"value,omitempty"`
+	IsSecret *bool   `json:"is_secret,omitempty"`
+}
+
+// Masked returns the variable as members may see it
+func (v ServiceEnvVar) Masked() ServiceEnvVar {
+	if v.IsSecret {
+		v.Value = SecretMask
+	}
+	return v
+}
+
+type EnvVarAction string
+
+const (
+	EnvVarActionCreated  EnvVarAction = "created"
+	EnvVarActionUpdated  EnvVarAction = "updated"
+	EnvVarActionDeleted  EnvVarAction = "deleted"
+	EnvVarActionRevealed EnvVarAction = "revealed"
+)
+
+// ServiceEnvVarChange records who changed or revealed an env var. The value
+// itself is never recorded.
+type ServiceEnvVarChange struct {
+	ID        uuid.UUID    `json:"id"`
+	ServiceID uuid.UUID    `json:"service_id"`
+	Key       string       `json:"key"`
+	Action    EnvVarAction `json:"action"`
+	IsSecret  bool         `json:"is_secret"`
+	UserID    *uuid.UUID   `json:"user_id,omitempty"`
+	UserEmail *string      `json:"user_email,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type EnvImportMode string
+
+const (
+	// EnvImportMerge adds and updates the file's keys and keeps the others
+	EnvImportMerge EnvImportMode = "merge"
+	// EnvImportReplace also deletes the keys missing from the file
+	EnvImportReplace EnvImportMode = "replace"
+)
+
+// EnvImport loads a .env file into a service's env vars
+type EnvImport struct {
+	Content string        `json:"content" validate:"required,max=1048576"`
+	Mode    EnvImportMode `json:"mode,omitempty" validate:"omitempty,oneof=merge replace"`
+	// Secret marks the keys the file adds as secrets
+	Secret bool `json:"secret,omitempty"`
+	// DryRun only computes the changes
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// EnvVarDiff is what an import does, or would do, to one key. Values are
+// left out so a preview does not reveal secrets.
+type EnvVarDiff struct {
+	Key      string       `json:"key"`
+	Action   EnvVarAction `json:"action"`
+	IsSecret bool         `json:"is_secret"`
+}
+
+type EnvImportResult struct {
+	Changes   []EnvVarDiff `json:"changes"`
+	Unchanged int          `json:"unchanged"`
+	Applied   bool         `json:"applied"`
+}
@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// ${{service.VAR}}, where service is the slug of a service in the project
+	serviceReferencePattern = regexp.MustCompile(`\$\{\{\s*([a-z0-9]+(?:-[a-z0-9]+)*)\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+	// ${VAR}, a variable of the service's own env
+	envReferencePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	varNamePattern      = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// Variables every service exports without declaring them
+const (
+	ExportHost = "HOST"
+	ExportPort = "PORT"
+)
+
+// ServiceReference is a ${{service.VAR}} reference to a variable another
+// service exports
+type ServiceReference struct {
+	Service  string
+	Variable string
+}
+
+func (r ServiceReference) String() string {
+	return "${{" + r.Service + "." + r.Variable + "}}"
+}
+
+// IsValidVarName reports whether name can be used as an env var or export name
+func IsValidVarName(name string) bool {
+	return varNamePattern.MatchString(name)
+}
+
+// ServiceReferences returns the service references in an env var value
+func ServiceReferences(value string) []ServiceReference {
+	var refs []ServiceReference
+	for _, m := range serviceReferencePattern.FindAllStringSubmatch(value, -1) {
+		refs = append(refs, ServiceReference{Service: m[1], Variable: m[2]})
+	}
+	return refs
+}
+
+// ReferencesService reports whether any of the env var values references the
+// service with the given slug
+func ReferencesService(env map[string]string, slug string) bool {
+	for _, value := range env {
+		for _, ref := range ServiceReferences(value) {
+			if ref.Service == slug {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ResolveServiceReferences replaces the service references in value with
+// what lookup returns for them. The first reference lookup cannot resolve is
+// returned with ok false.
+func ResolveServiceReferences(value string, lookup func(ServiceReference) (string, bool)) (resolved string, unresolved ServiceReference, ok bool) {
+	ok = true
+	resolved = serviceReferencePattern.ReplaceAllStringFunc(value, func(match string) string {
+		m := serviceReferencePattern.FindStringSubmatch(match)
+		ref := ServiceReference{Service: m[1], Variable: m[2]}
+		v, found := lookup(ref)
+		if !found {
+			if ok {
+				unresolved, ok = ref, false
+			}
+			return match
+		}
+		return v
+	})
+	return resolved, unresolved, ok
+}
+
+// ExpandEnvReferences replaces ${VAR} in an exported value with the
+// variable from the exporting service's env. Unknown variables and a bare
+// $VAR are left as they are, so values such as passwords keep their $.
+func ExpandEnvReferences(value string, env map[string]string) string {
+	if !strings.Contains(value, "${") {
+		return value
+	}
+	return envReferencePattern.ReplaceAllStringFunc(value, func(match string) string {
+		name := envReferencePattern.FindStringSubmatch(match)[1]
+		if v, ok := env[name]; ok {
+			return v
+		}
+		return match
+	})
+}
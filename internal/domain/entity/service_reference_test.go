@@ -0,0 +1,85 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+func TestResolveServiceReferences(t *testing.T) {
+	exports := map[string]map[string]string{
+		"postgres": {"HOST": "postgres", "PORT": "5432", "USER": "app"},
+	}
+	lookup := func(ref entity.ServiceReference) (string, bool) {
+		v, ok := exports[ref.Service][ref.Variable]
+		return v, ok
+	}
+
+	testCases := []struct {
+		name           string
+		value          string
+		expected       string
+		wantUnresolved string
+	}{
+		{"no references", "plain value", "plain value", ""},
+		{
+			"connection string",
+			"postgres://${{postgres.USER}}@${{postgres.HOST}}:${{ postgres.PORT }}/app",
+			"postgres://app@postgres:5432/app",
+			"",
+		},
+		{"single braces left alone", "${HOME}/data", "${HOME}/data", ""},
+		{"unknown variable", "${{postgres.PASSWORD}}", "${{postgres.PASSWORD}}", "${{postgres.PASSWORD}}"},
+		{"unknown service", "${{redis.HOST}}:${{postgres.PORT}}", "${{redis.HOST}}:5432", "${{redis.HOST}}"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, unresolved, ok := entity.ResolveServiceReferences(tc.value, lookup)
+			if got != tc.expected {
+				t.Errorf("ResolveServiceReferences() = %q, expected %q", got, tc.expected)
+			}
+			if ok != (tc.wantUnresolved == "") {
+				t.Errorf("ok = %v, expected %v", ok, tc.wantUnresolved == "")
+			}
+			if !ok && unresolved.String() != tc.wantUnresolved {
+				t.Errorf("unresolved = %s, expected %s", unresolved, tc.wantUnresolved)
+			}
+		})
+	}
+}
+
+func TestExpandEnvReferences(t *testing.T) {
+	env := map[string]string{"POSTGRES_USER": "app", "POSTGRES_PASSWORD": "s3cr$t"}
+
+	testCases := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"literal", "app", "app"},
+		{"own env var", "${POSTGRES_USER}", "app"},
+		{"value with dollar kept", "${POSTGRES_PASSWORD}", "s3cr$t"},
+		{"bare dollar kept", "pa$$word", "pa$$word"},
+		{"unknown var kept", "${MISSING}", "${MISSING}"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := entity.ExpandEnvReferences(tc.value, env); got != tc.expected {
+				t.Errorf("ExpandEnvReferences() = %q, expected %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestReferencesService(t *testing.T) {
+	env := map[string]string{"DATABASE_URL": "postgres://${{postgres.HOST}}/app", "MODE": "prod"}
+
+	if !entity.ReferencesService(env, "postgres") {
+		t.Error("expected a reference to postgres")
+	}
+	if entity.ReferencesService(env, "redis") {
+		t.Error("expected no reference to redis")
+	}
+}
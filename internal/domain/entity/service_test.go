@@ -0,0 +1,128 @@
+package entity_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+func TestPlatformHostname(t *testing.T) {
+	testCases := []struct {
+		name        string
+		serviceSlug string
+		projectSlug string
+		expected    string
+	}{
+		{"short slugs", "api", "shop", "api-shop.apps.example.com"},
+		{"label cut to 63 characters", strings.Repeat("a", 40), strings.Repeat("b", 40), strings.Repeat("a", 40) + "-" + strings.Repeat("b", 22) + ".apps.example.com"},
+		{"no trailing hyphen after cut", strings.Repeat("a", 62), "shop", strings.Repeat("a", 62) + ".apps.example.com"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := entity.PlatformHostname(tc.serviceSlug, tc.projectSlug, "apps.example.com")
+			if got != tc.expected {
+				t.Errorf("PlatformHostname() = %q, expected %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestPinnedImageRef(t *testing.T) {
+	const digest = "sha256:abc"
+
+	testCases := []struct {
+		name     string
+		image    string
+		expected string
+	}{
+		{"tag", "nginx:latest", "nginx@sha256:abc"},
+		{"no tag", "nginx", "nginx@sha256:abc"},
+		{"already pinned", "nginx@sha256:old", "nginx@sha256:abc"},
+		{"tag and digest", "nginx:1.27@sha256:old", "nginx@sha256:abc"},
+		{"namespaced", "library/nginx:1.27", "library/nginx@sha256:abc"},
+		{"registry with port", "registry.example.com:5000/team/api", "registry.example.com:5000/team/api@sha256:abc"},
+		{"registry with port and tag", "registry.example.com:5000/team/api:v2", "registry.example.com:5000/team/api@sha256:abc"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := entity.PinnedImageRef(tc.image, digest)
+			if got != tc.expected {
+				t.Errorf("PinnedImageRef() = %q, expected %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestNeedsImageCheck(t *testing.T) {
+	now := time.Now()
+	image := "nginx:latest"
+	empty := ""
+	checked := func(ago time.Duration) *time.Time {
+		at := now.Add(-ago)
+		return &at
+	}
+
+	testCases := []struct {
+		name     string
+		service  entity.Service
+		expected bool
+	}{
+		{"dockerfile service", entity.Service{DeployType: entity.DeployTypeDockerfile, Image: &image}, false},
+		{"no image", entity.Service{DeployType: entity.DeployTypeImage}, false},
+		{"empty image", entity.Service{DeployType: entity.DeployTypeImage, Image: &empty}, false},
+		{"never checked", entity.Service{DeployType: entity.DeployTypeImage, Image: &image, AutoUpdateInterval: 3600}, true},
+		{"checked recently", entity.Service{DeployType: entity.DeployTypeImage, Image: &image, AutoUpdateInterval: 3600, ImageCheckedAt: checked(time.Minute)}, false},
+		{"interval elapsed", entity.Service{DeployType: entity.DeployTypeImage, Image: &image, AutoUpdateInterval: 3600, ImageCheckedAt: checked(time.Hour)}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.service.NeedsImageCheck(now); got != tc.expected {
+				t.Errorf("NeedsImageCheck() = %v, expected %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDeployOptions_ScheduleFor(t *testing.T) {
+	// Mondays 02:00-04:00 UTC; now is Monday 10:00, outside the window
+	window := &entity.MaintenanceWindow{Days: []string{"mon"}, Start: "02:00", End: "04:00"}
+	now := time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC)
+	nextOpen := time.Date(2026, 1, 12, 2, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+	earlier := now.Add(-time.Hour)
+
+	testCases := []struct {
+		name     string
+		opts     entity.DeployOptions
+		window   *entity.MaintenanceWindow
+		expected *time.Time
+	}{
+		{"no window", entity.DeployOptions{}, nil, nil},
+		{"outside window", entity.DeployOptions{}, window, &nextOpen},
+		{"inside window", entity.DeployOptions{}, &entity.MaintenanceWindow{Days: []string{"mon"}, Start: "09:00", End: "11:00"}, nil},
+		{"window ignored", entity.DeployOptions{IgnoreMaintenanceWindow: true}, window, nil},
+		{"explicit time", entity.DeployOptions{ScheduledFor: &later}, nil, &later},
+		{"explicit time moved into window", entity.DeployOptions{ScheduledFor: &later}, window, &nextOpen},
+		{"explicit time inside window", entity.DeployOptions{ScheduledFor: &nextOpen}, window, &nextOpen},
+		{"explicit time with window ignored", entity.DeployOptions{ScheduledFor: &later, IgnoreMaintenanceWindow: true}, window, &later},
+		{"explicit time in the past", entity.DeployOptions{ScheduledFor: &earlier}, nil, nil},
+		{"explicit time in the past outside window", entity.DeployOptions{ScheduledFor: &earlier}, window, &nextOpen},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.opts.ScheduleFor(tc.window, now)
+			switch {
+			case tc.expected == nil && got != nil:
+				t.Errorf("expected to run now, got %v", *got)
+			case tc.expected != nil && (got == nil || !got.Equal(*tc.expected)):
+				t.Errorf("expected %v, got %v", *tc.expected, got)
+			}
+		})
+	}
+}
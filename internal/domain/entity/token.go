@@ -0,0 +1,55 @@
+package entity
+
+import "time"
+
+const (
+	// tokenShownLength is how many characters after its prefix a token's
+	// TokenPrefix keeps, enough to recognise it in a list
+	tokenShownLength = 8
+	// tokenUseInterval limits how often using a token is written to the database
+	tokenUseInterval = time.Minute
+)
+
+// TokenState is shared by personal API tokens and deploy tokens: the hash a
+// token is looked up by, its start shown in listings, and when it expires,
+// was last used or was revoked
+type TokenState struct {
+	TokenHash   string     `json:"-"`
+	TokenPrefix string     `json:"token_prefix"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+// NewTokenState describes a freshly issued token, whose plaintext starts with
+// prefix and hashes to hash
+func NewTokenState(prefix, plaintext, hash string, expiresAt *time.Time) TokenState {
+	return TokenState{
+		TokenHash:   hash,
+		TokenPrefix: plaintext[:len(prefix)+tokenShownLength],
+		ExpiresAt:   expiresAt,
+	}
+}
+
+// ValidTokenExpiry reports whether a requested expiry, if any, lies after now
+func ValidTokenExpiry(expiresAt *time.Time, now time.Time) bool {
+	return expiresAt == nil || expiresAt.After(now)
+}
+
+// IsActive reports whether the token is neither revoked nor expired at now
+func (s *TokenState) IsActive(now time.Time) bool {
+	if s.RevokedAt != nil {
+		return false
+	}
+	return s.ExpiresAt == nil || now.Before(*s.ExpiresAt)
+}
+
+// RecordUse sets LastUsedAt to now and reports whether the use should be
+// stored. Uses within a minute of the last stored one are not recorded.
+func (s *TokenState) RecordUse(now time.Time) bool {
+	if s.LastUsedAt != nil && now.Sub(*s.LastUsedAt) < tokenUseInterval {
+		return false
+	}
+	s.LastUsedAt = &now
+	return true
+}
@@ -0,0 +1,101 @@
+package entity_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+func TestTokenStateIsActive(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	testCases := []struct {
+		name  string
+		state entity.TokenState
+		want  bool
+	}{
+		{name: "no expiry", state: entity.TokenState{}, want: true},
+		{name: "not yet expired", state: entity.TokenState{ExpiresAt: &future}, want: true},
+		{name: "expired", state: entity.TokenState{ExpiresAt: &past}, want: false},
+		{name: "revoked", state: entity.TokenState{RevokedAt: &past}, want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.state.IsActive(now); got != tc.want {
+				t.Errorf("IsActive() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTokenStateRecordUse(t *testing.T) {
+	now := time.Now()
+	recent := now.Add(-30 * time.Second)
+	stale := now.Add(-2 * time.Minute)
+
+	testCases := []struct {
+		name     string
+		lastUsed *time.Time
+		want     bool
+	}{
+		{name: "never used", want: true},
+		{name: "used a while ago", lastUsed: &stale, want: true},
+		{name: "used recently", lastUsed: &recent, want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			state := entity.TokenState{LastUsedAt: tc.lastUsed}
+			if got := state.RecordUse(now); got != tc.want {
+				t.Errorf("RecordUse() = %v, want %v", got, tc.want)
+			}
+			if tc.want && !state.LastUsedAt.Equal(now) {
+				t.Errorf("expected LastUsedAt to be set to now, got %v", state.LastUsedAt)
+			}
+		})
+	}
+}
+
+func TestNewTokenState(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+
+	state := entity.NewTokenState("pod_", "pod_abcdefghijklmnop", "hash", &expiresAt)
+	if state.TokenPrefix != "pod_abcdefgh" {
+		t.Errorf("expected prefix pod_abcdefgh, got %q", state.TokenPrefix)
+	}
+	if state.TokenHash != "hash" {
+		t.Errorf("expected hash to be kept, got %q", state.TokenHash)
+	}
+	if state.ExpiresAt != &expiresAt {
+		t.Errorf("expected expiry to be kept")
+	}
+}
+
+func TestValidTokenExpiry(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	testCases := []struct {
+		name      string
+		expiresAt *time.Time
+		want      bool
+	}{
+		{name: "no expiry", want: true},
+		{name: "future", expiresAt: &future, want: true},
+		{name: "now", expiresAt: &now, want: false},
+		{name: "past", expiresAt: &past, want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := entity.ValidTokenExpiry(tc.expiresAt, now); got != tc.want {
+				t.Errorf("ValidTokenExpiry() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -23,6 +23,11 @@ type User struct {
 	IsActive     bool      `json:"is_active"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+
+	// OIDCIssuer and OIDCSubject link the user to their single sign-on
+	// identity; users created by single sign-on have no password
+	OIDCIssuer  *string `json:"-"`
+	OIDCSubject *string `json:"-"`
 }
 
 func (u *User) IsSuperAdmin() bool {
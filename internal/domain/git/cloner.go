@@ -0,0 +1,22 @@
+package git
+
+import "context"
+
+// CloneOptions holds the source to check out for a build
+type CloneOptions struct {
+	URL    string
+	Branch string
+	Token  string
+	Dir    string
+}
+
+// CommitInfo describes the commit a checkout ended up on
+type CommitInfo struct {
+	SHA     string
+	Message string
+}
+
+// Cloner interface for fetching source repositories
+type Cloner interface {
+	Clone(ctx context.Context, opts *CloneOptions) (*CommitInfo, error)
+}
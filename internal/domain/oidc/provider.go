@@ -0,0 +1,24 @@
+package oidc
+
+import "context"
+
+// Identity is what an OpenID Connect provider asserts about the user who
+// signed in, taken from a verified ID token
+type Identity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+}
+
+// Provider signs users in with the authorization code flow and PKCE
+type Provider interface {
+	// AuthCodeURL returns where to send the user to sign in. codeChallenge is
+	// the S256 challenge of the verifier later passed to Exchange.
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	// Exchange redeems an authorization code and returns the identity in the
+	// ID token, after checking its signature, issuer, audience, expiry and nonce
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error)
+}
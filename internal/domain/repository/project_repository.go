@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -17,3 +18,25 @@ type ProjectRepository interface {
 	ListByTeamID(ctx context.Context, teamID uuid.UUID) ([]entity.Project, error)
 	ExistsByTeamAndSlug(ctx context.Context, teamID uuid.UUID, slug string) (bool, error)
 }
+
+type EnvGroupRepository interface {
+	Create(ctx context.Context, group *entity.EnvGroup) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.EnvGroup, error)
+	Update(ctx context.Context, group *entity.EnvGroup) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	ListByTeamID(ctx context.Context, teamID uuid.UUID) ([]entity.EnvGroup, error)
+	ListByProjectID(ctx context.Context, projectID uuid.UUID) ([]entity.EnvGroup, error)
+	ListByServiceID(ctx context.Context, serviceID uuid.UUID) ([]entity.EnvGroup, error)
+	AttachService(ctx context.Context, groupID, serviceID uuid.UUID) error
+	DetachService(ctx context.Context, groupID, serviceID uuid.UUID) error
+	ListServiceIDs(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error)
+}
+
+type DeployTokenRepository interface {
+	Create(ctx context.Context, token *entity.DeployToken) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.DeployToken, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entity.DeployToken, error)
+	ListByProjectID(ctx context.Context, projectID uuid.UUID) ([]entity.DeployToken, error)
+	Revoke(ctx context.Context, id uuid.UUID, revokedAt time.Time) error
+	UpdateLastUsed(ctx context.Context, id uuid.UUID, lastUsedAt time.Time) error
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+// CiphertextRepository reads and rewrites encrypted columns directly, so the
+// encryption key can be rotated without loading whole entities
+type CiphertextRepository interface {
+	// List returns up to limit non-null values with an id after the given one, in id order
+	List(ctx context.Context, column entity.EncryptedColumn, after uuid.UUID, limit int) ([]entity.Ciphertext, error)
+	// Replace swaps the value of one row, unless it changed since it was read
+	Replace(ctx context.Context, column entity.EncryptedColumn, id uuid.UUID, from, to []byte) (bool, error)
+}
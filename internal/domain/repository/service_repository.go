@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -15,10 +16,24 @@ type ServiceRepository interface {
 	Update(ctx context.Context, service *entity.Service) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	ListByProjectID(ctx context.Context, projectID uuid.UUID) ([]entity.Service, error)
+	ListByDeployType(ctx context.Context, deployType entity.DeployType) ([]entity.Service, error)
+	ListDeployed(ctx context.Context) ([]entity.Service, error)
 	ExistsByProjectAndSlug(ctx context.Context, projectID uuid.UUID, slug string) (bool, error)
 	UpdateStatus(ctx context.Context, id uuid.UUID, status entity.ServiceStatus) error
 	UpdateContainerID(ctx context.Context, id uuid.UUID, containerID *string) error
 	UpdateSwarmServiceID(ctx context.Context, id uuid.UUID, swarmServiceID *string) error
+	UpdateImageDigest(ctx context.Context, id uuid.UUID, digest *string) error
+	UpdateImageCheck(ctx context.Context, id uuid.UUID, latestDigest *string, updateAvailable bool, checkedAt time.Time) error
+	ListWithLegacyEnvVars(ctx context.Context) ([]entity.Service, error)
+}
+
+type ServiceEnvVarRepository interface {
+	Upsert(ctx context.Context, envVar *entity.ServiceEnvVar) error
+	GetByServiceAndKey(ctx context.Context, serviceID uuid.UUID, key string) (*entity.ServiceEnvVar, error)
+	Delete(ctx context.Context, serviceID uuid.UUID, key string) error
+	ListByServiceID(ctx context.Context, serviceID uuid.UUID) ([]entity.ServiceEnvVar, error)
+	CreateChange(ctx context.Context, change *entity.ServiceEnvVarChange) error
+	ListChanges(ctx context.Context, serviceID uuid.UUID, limit int) ([]entity.ServiceEnvVarChange, error)
 }
 
 type DomainRepository interface {
@@ -27,6 +42,11 @@ type DomainRepository interface {
 	GetByDomain(ctx context.Context, domain string) (*entity.Domain, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 	ListByServiceID(ctx context.Context, serviceID uuid.UUID) ([]entity.Domain, error)
+	ListByDomains(ctx context.Context, domains []string) ([]entity.Domain, error)
+	ListByCertificateID(ctx context.Context, certificateID uuid.UUID) ([]entity.Domain, error)
+	ListByEntryPoint(ctx context.Context, entryPoint string) ([]entity.Domain, error)
+	ListAll(ctx context.Context) ([]entity.Domain, error)
+	MarkVerified(ctx context.Context, id uuid.UUID, verifiedAt time.Time) error
 	ExistsByDomain(ctx context.Context, domain string) (bool, error)
 }
 
@@ -49,10 +69,16 @@ type NetworkRepository interface {
 	Create(ctx context.Context, network *entity.Network) error
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Network, error)
 	GetByName(ctx context.Context, name string) (*entity.Network, error)
+	GetByProjectAndName(ctx context.Context, projectID uuid.UUID, name string) (*entity.Network, error)
+	GetDefaultByProjectID(ctx context.Context, projectID uuid.UUID) (*entity.Network, error)
 	Update(ctx context.Context, network *entity.Network) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	ListByProjectID(ctx context.Context, projectID uuid.UUID) ([]entity.Network, error)
+	ListByServiceID(ctx context.Context, serviceID uuid.UUID) ([]entity.Network, error)
 	ListAll(ctx context.Context) ([]entity.Network, error)
+	AttachService(ctx context.Context, networkID, serviceID uuid.UUID) error
+	DetachService(ctx context.Context, networkID, serviceID uuid.UUID) error
+	ListServiceIDs(ctx context.Context, networkID uuid.UUID) ([]uuid.UUID, error)
 }
 
 type DeploymentRepository interface {
@@ -61,4 +87,6 @@ type DeploymentRepository interface {
 	Update(ctx context.Context, deployment *entity.Deployment) error
 	ListByServiceID(ctx context.Context, serviceID uuid.UUID, limit, offset int) ([]entity.Deployment, error)
 	GetLatestByServiceID(ctx context.Context, serviceID uuid.UUID) (*entity.Deployment, error)
+	ListDue(ctx context.Context, before time.Time) ([]entity.Deployment, error)
+	CancelScheduled(ctx context.Context, serviceID uuid.UUID) error
 }
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -26,3 +27,13 @@ type TeamMemberRepository interface {
 	ListByTeamID(ctx context.Context, teamID uuid.UUID) ([]entity.TeamMember, error)
 	CountByTeamID(ctx context.Context, teamID uuid.UUID) (int, error)
 }
+
+type CertificateRepository interface {
+	Create(ctx context.Context, certificate *entity.Certificate) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Certificate, error)
+	Update(ctx context.Context, certificate *entity.Certificate) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	ListByTeamID(ctx context.Context, teamID uuid.UUID) ([]entity.Certificate, error)
+	ListExpiringByTeamID(ctx context.Context, teamID uuid.UUID, before time.Time) ([]entity.Certificate, error)
+	ListByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.Certificate, error)
+}
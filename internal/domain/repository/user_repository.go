@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -16,12 +17,74 @@ type UserRepository interface {
 	Delete(ctx context.Context, id uuid.UUID) error
 	ExistsByEmail(ctx context.Context, email string) (bool, error)
 	Count(ctx context.Context) (int64, error)
+	GetByOIDCSubject(ctx context.Context, issuer, subject string) (*entity.User, error)
+	LinkOIDCSubject(ctx context.Context, id uuid.UUID, issuer, subject string) error
 }
 
+// RefreshTokenRepository stores sessions and their refresh tokens.
+// Deleting a session deletes its tokens.
 type RefreshTokenRepository interface {
-	Create(ctx context.Context, token *entity.RefreshToken) error
+	// CreateSession starts a session with its first refresh token
+	CreateSession(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error
 	GetByTokenHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+	// Rotate marks used as used and adds next to its session, moving the
+	// session's expiry and client along. It reports false when used was
+	// used already.
+	Rotate(ctx context.Context, used, next *entity.RefreshToken, client entity.SessionClient) (bool, error)
+	GetSession(ctx context.Context, id uuid.UUID) (*entity.Session, error)
+	// ListSessions returns the user's unexpired sessions, most recently used first
+	ListSessions(ctx context.Context, userID uuid.UUID) ([]entity.Session, error)
+	DeleteSession(ctx context.Context, id uuid.UUID) error
 	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
-	DeleteByTokenHash(ctx context.Context, tokenHash string) error
+	// DeleteExpired removes expired sessions and refresh tokens
 	DeleteExpired(ctx context.Context) error
 }
+
+// OIDCStateRepository keeps single sign-on attempts until the provider
+// redirects back; Consume returns and removes one
+type OIDCStateRepository interface {
+	Create(ctx context.Context, state *entity.OIDCState) error
+	Consume(ctx context.Context, state string) (*entity.OIDCState, error)
+	DeleteExpired(ctx context.Context) error
+}
+
+// MFARepository stores users' authenticators and recovery codes
+type MFARepository interface {
+	GetTOTP(ctx context.Context, userID uuid.UUID) (*entity.UserTOTP, error)
+	// SaveTOTP replaces the user's authenticator and recovery codes
+	SaveTOTP(ctx context.Context, totp *entity.UserTOTP, codes []entity.MFARecoveryCode) error
+	ConfirmTOTP(ctx context.Context, userID uuid.UUID, confirmedAt time.Time) error
+	// UseTOTPStep records the step of an accepted code. It reports false
+	// when that step or a later one was used already.
+	UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+	// DeleteTOTP removes the user's authenticator and recovery codes
+	DeleteTOTP(ctx context.Context, userID uuid.UUID) error
+	// UseRecoveryCode marks an unused code as used, reporting false when
+	// the user has no such unused code
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string, usedAt time.Time) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []entity.MFARecoveryCode) error
+	CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error)
+}
+
+type MFAChallengeRepository interface {
+	Create(ctx context.Context, challenge *entity.MFAChallenge) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entity.MFAChallenge, error)
+	// AddAttempt counts a wrong code and returns the attempts so far
+	AddAttempt(ctx context.Context, id uuid.UUID) (int, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteExpired(ctx context.Context) error
+}
+
+type MFAPolicyRepository interface {
+	Get(ctx context.Context) (*entity.MFAPolicy, error)
+	Update(ctx context.Context, policy *entity.MFAPolicy) error
+}
+
+type APITokenRepository interface {
+	Create(ctx context.Context, token *entity.APIToken) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.APIToken, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entity.APIToken, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]entity.APIToken, error)
+	Revoke(ctx context.Context, id uuid.UUID, revokedAt time.Time) error
+	UpdateLastUsed(ctx context.Context, id uuid.UUID, lastUsedAt time.Time) error
+}
@@ -0,0 +1,87 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Schemes of the references providers resolve, as in
+// "${{vault:secret/data/app#key}}"
+const (
+	SchemeVault     = "vault"
+	SchemeFile      = "file"
+	SchemeEncrypted = "encrypted"
+)
+
+var (
+	ErrInvalidReference = errors.New("invalid secret reference")
+	ErrSecretNotFound   = errors.New("secret not found")
+	ErrOutOfScope       = errors.New("secret reference outside the project's scope")
+)
+
+// Placeholders of a scope template, filled in with the IDs of the project
+// whose env holds the reference. IDs are used rather than slugs, which can be
+// renamed and reused by another team.
+const (
+	ScopeTeamID    = "{team_id}"
+	ScopeProjectID = "{project_id}"
+)
+
+// Provider resolves references to secrets kept outside of Podoru's database
+type Provider interface {
+	// Scheme is the prefix of the references the provider resolves
+	Scheme() string
+	// Resolve returns the secret the reference points to, without its scheme
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// ParseReference splits an env value of the form "${{scheme:ref}}" when
+// scheme is one Podoru has a provider for. Any other value, such as
+// "file:./dev.db", is not a reference.
+func ParseReference(value string) (scheme, ref string, ok bool) {
+	inner, found := strings.CutPrefix(value, "${{")
+	if !found {
+		return "", "", false
+	}
+	inner, found = strings.CutSuffix(inner, "}}")
+	if !found {
+		return "", "", false
+	}
+	scheme, ref, found = strings.Cut(strings.TrimSpace(inner), ":")
+	if !found || ref == "" {
+		return "", "", false
+	}
+	switch scheme {
+	case SchemeVault, SchemeFile, SchemeEncrypted:
+		return scheme, ref, true
+	}
+	return "", "", false
+}
+
+// FormatReference is the env value referring to ref
+func FormatReference(scheme, ref string) string {
+	return "${{" + scheme + ":" + ref + "}}"
+}
+
+// Scope fills in the placeholders of a scope template
+func Scope(template string, teamID, projectID uuid.UUID) string {
+	scope := strings.ReplaceAll(template, ScopeTeamID, teamID.String())
+	return strings.ReplaceAll(scope, ScopeProjectID, projectID.String())
+}
+
+// InScope reports whether the path of a reference, the part before any
+// "#field", lies below scope. Paths with empty, "." or ".." segments are
+// never in scope.
+func InScope(ref, scope string) bool {
+	refPath, _, _ := strings.Cut(ref, "#")
+	refPath = strings.Trim(refPath, "/")
+	scope = strings.Trim(scope, "/")
+	if refPath == "" || scope == "" || path.Clean(refPath) != refPath || strings.HasPrefix(refPath, "..") {
+		return false
+	}
+	return strings.HasPrefix(refPath, scope+"/")
+}
@@ -0,0 +1,90 @@
+package secret_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/secret"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		value  string
+		scheme string
+		ref    string
+		ok     bool
+	}{
+		{value: "${{vault:secret/data/app#db_password}}", scheme: secret.SchemeVault, ref: "secret/data/app#db_password", ok: true},
+		{value: "${{file:db_password}}", scheme: secret.SchemeFile, ref: "db_password", ok: true},
+		{value: "${{ encrypted:AQID }}", scheme: secret.SchemeEncrypted, ref: "AQID", ok: true},
+		{value: "file:./dev.db", ok: false},
+		{value: "vault:secret/data/app#db_password", ok: false},
+		{value: "${{vault:secret/data/app#db_password}} extra", ok: false},
+		{value: "${{postgres.HOST}}", ok: false},
+		{value: "${{https://example.com}}", ok: false},
+		{value: "https://example.com", ok: false},
+		{value: "postgres://app@db:5432/app", ok: false},
+		{value: "${{vault:}}", ok: false},
+		{value: "plain value", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			scheme, ref, ok := secret.ParseReference(tt.value)
+			if ok != tt.ok || scheme != tt.scheme || ref != tt.ref {
+				t.Errorf("ParseReference(%q) = %q, %q, %v; want %q, %q, %v", tt.value, scheme, ref, ok, tt.scheme, tt.ref, tt.ok)
+			}
+		})
+	}
+}
+
+func TestFormatReference(t *testing.T) {
+	value := secret.FormatReference(secret.SchemeEncrypted, "AQID")
+	if scheme, ref, ok := secret.ParseReference(value); !ok || scheme != secret.SchemeEncrypted || ref != "AQID" {
+		t.Errorf("ParseReference(%q) = %q, %q, %v", value, scheme, ref, ok)
+	}
+}
+
+func TestScope(t *testing.T) {
+	teamID := uuid.New()
+	projectID := uuid.New()
+
+	got := secret.Scope("secret/data/podoru/{team_id}/{project_id}", teamID, projectID)
+	want := "secret/data/podoru/" + teamID.String() + "/" + projectID.String()
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestInScope(t *testing.T) {
+	scope := "secret/data/team"
+
+	tests := []struct {
+		ref  string
+		want bool
+	}{
+		{ref: "secret/data/team/db#password", want: true},
+		{ref: "/secret/data/team/app/db#password", want: true},
+		{ref: "secret/data/team#password", want: false},
+		{ref: "secret/data/teammate/db#password", want: false},
+		{ref: "secret/data/other/db#password", want: false},
+		{ref: "secret/data/team/../other/db#password", want: false},
+		{ref: "secret/data/team//db#password", want: false},
+		{ref: "secret/data/team/./db#password", want: false},
+		{ref: "auth/token/lookup-self#id", want: false},
+		{ref: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			if got := secret.InScope(tt.ref, scope); got != tt.want {
+				t.Errorf("InScope(%q, %q) = %v, want %v", tt.ref, scope, got, tt.want)
+			}
+		})
+	}
+
+	if secret.InScope("db_password", "") {
+		t.Error("expected an empty scope to refuse every reference")
+	}
+}
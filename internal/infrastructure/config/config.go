@@ -1,6 +1,9 @@
 package config
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -8,13 +11,20 @@ import (
 )
 
 type Config struct {
-	App        AppConfig        `mapstructure:"app"`
-	Database   DatabaseConfig   `mapstructure:"database"`
-	JWT        JWTConfig        `mapstructure:"jwt"`
-	Encryption EncryptionConfig `mapstructure:"encryption"`
-	Docker     DockerConfig     `mapstructure:"docker"`
-	Traefik    TraefikConfig    `mapstructure:"traefik"`
-	Logger     LoggerConfig     `mapstructure:"logger"`
+	App          AppConfig          `mapstructure:"app"`
+	Database     DatabaseConfig     `mapstructure:"database"`
+	JWT          JWTConfig          `mapstructure:"jwt"`
+	Session      SessionConfig      `mapstructure:"session"`
+	Encryption   EncryptionConfig   `mapstructure:"encryption"`
+	Secrets      SecretsConfig      `mapstructure:"secrets"`
+	OIDC         OIDCConfig         `mapstructure:"oidc"`
+	Docker       DockerConfig       `mapstructure:"docker"`
+	Traefik      TraefikConfig      `mapstructure:"traefik"`
+	Domains      DomainsConfig      `mapstructure:"domains"`
+	ImageWatcher ImageWatcherConfig `mapstructure:"image_watcher"`
+	Build        BuildConfig        `mapstructure:"build"`
+	Scheduler    SchedulerConfig    `mapstructure:"scheduler"`
+	Logger       LoggerConfig       `mapstructure:"logger"`
 }
 
 type AppConfig struct {
@@ -23,6 +33,8 @@ type AppConfig struct {
 	Port                int    `mapstructure:"port"`
 	Debug               bool   `mapstructure:"debug"`
 	RegistrationEnabled bool   `mapstructure:"registration_enabled"`
+	// PasswordLoginDisabled leaves single sign-on as the only way to log in
+	PasswordLoginDisabled bool `mapstructure:"password_login_disabled"`
 }
 
 type DatabaseConfig struct {
@@ -43,12 +55,60 @@ type JWTConfig struct {
 	RefreshExpiry time.Duration `mapstructure:"refresh_expiry"`
 }
 
+type SessionConfig struct {
+	// CleanupInterval is how often expired sessions are deleted
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+}
+
 type EncryptionConfig struct {
 	Key string `mapstructure:"key"`
+	// PreviousKeys still decrypt data sealed before the key was rotated
+	PreviousKeys []string `mapstructure:"previous_keys"`
+}
+
+// SecretsConfig enables the providers env values can reference instead of
+// storing a secret in the database. A project's references are confined to
+// the prefixes, where {team_id} and {project_id} are the project's IDs.
+type SecretsConfig struct {
+	// VaultAddress enables "vault:" references, e.g. https://vault.example.com:8200
+	VaultAddress   string `mapstructure:"vault_address"`
+	VaultToken     string `mapstructure:"vault_token"`
+	VaultNamespace string `mapstructure:"vault_namespace"`
+	// VaultPathPrefix is the Vault path a project's references must be below
+	VaultPathPrefix string `mapstructure:"vault_path_prefix"`
+	// FilesDir enables "file:" references to the files in it, e.g. /run/secrets
+	FilesDir string `mapstructure:"files_dir"`
+	// FilesPrefix is the subdirectory of FilesDir a project's references must be below
+	FilesPrefix string `mapstructure:"files_prefix"`
+}
+
+// OIDCConfig enables single sign-on through an OpenID Connect provider such
+// as Keycloak or Authentik
+type OIDCConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// IssuerURL is where the provider's discovery document lives, under
+	// /.well-known/openid-configuration
+	IssuerURL    string `mapstructure:"issuer_url"`
+	ClientID     string `mapstructure:"client_id"`
+	ClientSecret string `mapstructure:"client_secret"`
+	// RedirectURL is the callback registered with the provider, e.g.
+	// https://podoru.example.com/api/v1/auth/oidc/callback
+	RedirectURL string `mapstructure:"redirect_url"`
+	// FrontendURL is where the browser is sent after signing in, with the
+	// tokens in the URL fragment
+	FrontendURL string   `mapstructure:"frontend_url"`
+	Scopes      []string `mapstructure:"scopes"`
+	// GroupsClaim names the ID token claim that lists the user's groups
+	GroupsClaim string `mapstructure:"groups_claim"`
+	// GroupMappings grant the members of a provider group a role in a team,
+	// each as "group=team-slug:role"
+	GroupMappings []string `mapstructure:"group_mappings"`
 }
 
 type DockerConfig struct {
 	Host string `mapstructure:"host"`
+	// NetworkDriver is used for the network every project gets, bridge or overlay
+	NetworkDriver string `mapstructure:"network_driver"`
 }
 
 type TraefikConfig struct {
@@ -58,6 +118,57 @@ type TraefikConfig struct {
 	HTTPSPort     int    `mapstructure:"https_port"`
 	ACMEEmail     string `mapstructure:"acme_email"`
 	Network       string `mapstructure:"network"`
+	// ProviderToken enables the HTTP provider endpoint Traefik polls for
+	// routing; requests must send it as a bearer token
+	ProviderToken string `mapstructure:"provider_token"`
+	// ConfigFile, when set, is kept up to date for Traefik's file provider
+	ConfigFile       string        `mapstructure:"config_file"`
+	FileSyncInterval time.Duration `mapstructure:"file_sync_interval"`
+	// BaseDomain gives every deployed service a hostname of its own,
+	// <service>-<project>.<base domain>, served with a wildcard certificate
+	// from the DNS-01 resolver BaseDomainCertResolver
+	BaseDomain             string `mapstructure:"base_domain"`
+	BaseDomainCertResolver string `mapstructure:"base_domain_cert_resolver"`
+	// TCPEntryPoints and UDPEntryPoints name the entrypoints defined in
+	// Traefik's static configuration that tcp and udp domains may use
+	TCPEntryPoints []string `mapstructure:"tcp_entrypoints"`
+	UDPEntryPoints []string `mapstructure:"udp_entrypoints"`
+	// Managed has Podoru create the Traefik network and container at startup
+	Managed       bool   `mapstructure:"managed"`
+	Image         string `mapstructure:"image"`
+	ContainerName string `mapstructure:"container_name"`
+	// ProviderURL is where the managed container polls the HTTP provider
+	ProviderURL string `mapstructure:"provider_url"`
+	// DNSProvider enables the BaseDomainCertResolver DNS-01 resolver on the
+	// managed container; ContainerEnv carries the provider's credentials
+	DNSProvider  string   `mapstructure:"dns_provider"`
+	ContainerEnv []string `mapstructure:"container_env"`
+}
+
+type DomainsConfig struct {
+	// VerificationEnabled holds new domains back from routing until their
+	// DNS TXT record proves ownership
+	VerificationEnabled bool `mapstructure:"verification_enabled"`
+	// DNSResolver is the "host:port" queried for verification records,
+	// empty for the system resolver
+	DNSResolver string `mapstructure:"dns_resolver"`
+}
+
+type ImageWatcherConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+}
+
+type BuildConfig struct {
+	WorkDir              string  `mapstructure:"work_dir"`
+	MaxConcurrent        int     `mapstructure:"max_concurrent"`
+	MaxConcurrentPerTeam int     `mapstructure:"max_concurrent_per_team"`
+	CPULimit             float64 `mapstructure:"cpu_limit"`
+	MemoryLimit          int     `mapstructure:"memory_limit"` // in MB
+}
+
+type SchedulerConfig struct {
+	PollInterval time.Duration `mapstructure:"poll_interval"`
 }
 
 type LoggerConfig struct {
@@ -96,6 +207,7 @@ func bindEnvVariables() {
 	viper.BindEnv("app.port", "APP_PORT")
 	viper.BindEnv("app.debug", "APP_DEBUG")
 	viper.BindEnv("app.registration_enabled", "REGISTRATION_ENABLED")
+	viper.BindEnv("app.password_login_disabled", "PASSWORD_LOGIN_DISABLED")
 
 	viper.BindEnv("database.host", "DB_HOST")
 	viper.BindEnv("database.port", "DB_PORT")
@@ -108,9 +220,30 @@ func bindEnvVariables() {
 	viper.BindEnv("jwt.access_expiry", "JWT_ACCESS_EXPIRY")
 	viper.BindEnv("jwt.refresh_expiry", "JWT_REFRESH_EXPIRY")
 
+	viper.BindEnv("session.cleanup_interval", "SESSION_CLEANUP_INTERVAL")
+
 	viper.BindEnv("encryption.key", "ENCRYPTION_KEY")
+	viper.BindEnv("encryption.previous_keys", "ENCRYPTION_PREVIOUS_KEYS")
+
+	viper.BindEnv("secrets.vault_address", "VAULT_ADDR")
+	viper.BindEnv("secrets.vault_token", "VAULT_TOKEN")
+	viper.BindEnv("secrets.vault_namespace", "VAULT_NAMESPACE")
+	viper.BindEnv("secrets.vault_path_prefix", "VAULT_PATH_PREFIX")
+	viper.BindEnv("secrets.files_dir", "SECRETS_FILES_DIR")
+	viper.BindEnv("secrets.files_prefix", "SECRETS_FILES_PREFIX")
+
+	viper.BindEnv("oidc.enabled", "OIDC_ENABLED")
+	viper.BindEnv("oidc.issuer_url", "OIDC_ISSUER_URL")
+	viper.BindEnv("oidc.client_id", "OIDC_CLIENT_ID")
+	viper.BindEnv("oidc.client_secret", "OIDC_CLIENT_SECRET")
+	viper.BindEnv("oidc.redirect_url", "OIDC_REDIRECT_URL")
+	viper.BindEnv("oidc.frontend_url", "OIDC_FRONTEND_URL")
+	viper.BindEnv("oidc.scopes", "OIDC_SCOPES")
+	viper.BindEnv("oidc.groups_claim", "OIDC_GROUPS_CLAIM")
+	viper.BindEnv("oidc.group_mappings", "OIDC_GROUP_MAPPINGS")
 
 	viper.BindEnv("docker.host", "DOCKER_HOST")
+	viper.BindEnv("docker.network_driver", "DOCKER_NETWORK_DRIVER")
 
 	viper.BindEnv("traefik.enabled", "TRAEFIK_ENABLED")
 	viper.BindEnv("traefik.dashboard_port", "TRAEFIK_DASHBOARD_PORT")
@@ -118,6 +251,33 @@ func bindEnvVariables() {
 	viper.BindEnv("traefik.https_port", "TRAEFIK_HTTPS_PORT")
 	viper.BindEnv("traefik.acme_email", "TRAEFIK_ACME_EMAIL")
 	viper.BindEnv("traefik.network", "TRAEFIK_NETWORK")
+	viper.BindEnv("traefik.provider_token", "TRAEFIK_PROVIDER_TOKEN")
+	viper.BindEnv("traefik.config_file", "TRAEFIK_CONFIG_FILE")
+	viper.BindEnv("traefik.file_sync_interval", "TRAEFIK_FILE_SYNC_INTERVAL")
+	viper.BindEnv("traefik.base_domain", "TRAEFIK_BASE_DOMAIN")
+	viper.BindEnv("traefik.base_domain_cert_resolver", "TRAEFIK_BASE_DOMAIN_CERT_RESOLVER")
+	viper.BindEnv("traefik.tcp_entrypoints", "TRAEFIK_TCP_ENTRYPOINTS")
+	viper.BindEnv("traefik.udp_entrypoints", "TRAEFIK_UDP_ENTRYPOINTS")
+	viper.BindEnv("traefik.managed", "TRAEFIK_MANAGED")
+	viper.BindEnv("traefik.image", "TRAEFIK_IMAGE")
+	viper.BindEnv("traefik.container_name", "TRAEFIK_CONTAINER_NAME")
+	viper.BindEnv("traefik.provider_url", "TRAEFIK_PROVIDER_URL")
+	viper.BindEnv("traefik.dns_provider", "TRAEFIK_DNS_PROVIDER")
+	viper.BindEnv("traefik.container_env", "TRAEFIK_CONTAINER_ENV")
+
+	viper.BindEnv("domains.verification_enabled", "DOMAIN_VERIFICATION_ENABLED")
+	viper.BindEnv("domains.dns_resolver", "DOMAIN_DNS_RESOLVER")
+
+	viper.BindEnv("image_watcher.enabled", "IMAGE_WATCHER_ENABLED")
+	viper.BindEnv("image_watcher.poll_interval", "IMAGE_WATCHER_POLL_INTERVAL")
+
+	viper.BindEnv("build.work_dir", "BUILD_WORK_DIR")
+	viper.BindEnv("build.max_concurrent", "BUILD_MAX_CONCURRENT")
+	viper.BindEnv("build.max_concurrent_per_team", "BUILD_MAX_CONCURRENT_PER_TEAM")
+	viper.BindEnv("build.cpu_limit", "BUILD_CPU_LIMIT")
+	viper.BindEnv("build.memory_limit", "BUILD_MEMORY_LIMIT")
+
+	viper.BindEnv("scheduler.poll_interval", "SCHEDULER_POLL_INTERVAL")
 }
 
 func setDefaults(cfg *Config) {
@@ -139,9 +299,30 @@ func setDefaults(cfg *Config) {
 	if cfg.JWT.RefreshExpiry == 0 {
 		cfg.JWT.RefreshExpiry = 7 * 24 * time.Hour
 	}
+	if cfg.Session.CleanupInterval == 0 {
+		cfg.Session.CleanupInterval = time.Hour
+	}
+	if cfg.Secrets.VaultPathPrefix == "" {
+		cfg.Secrets.VaultPathPrefix = "secret/data/podoru/{team_id}/{project_id}"
+	}
+	if cfg.Secrets.FilesPrefix == "" {
+		cfg.Secrets.FilesPrefix = "{team_id}/{project_id}"
+	}
+	if len(cfg.OIDC.Scopes) == 0 {
+		cfg.OIDC.Scopes = []string{"openid", "profile", "email"}
+	}
+	if cfg.OIDC.GroupsClaim == "" {
+		cfg.OIDC.GroupsClaim = "groups"
+	}
+	if cfg.OIDC.FrontendURL == "" {
+		cfg.OIDC.FrontendURL = "/"
+	}
 	if cfg.Docker.Host == "" {
 		cfg.Docker.Host = "unix:///var/run/docker.sock"
 	}
+	if cfg.Docker.NetworkDriver == "" {
+		cfg.Docker.NetworkDriver = "bridge"
+	}
 	if cfg.Logger.Level == "" {
 		cfg.Logger.Level = "info"
 	}
@@ -151,6 +332,38 @@ func setDefaults(cfg *Config) {
 	if cfg.Traefik.Network == "" {
 		cfg.Traefik.Network = "podoru_traefik"
 	}
+	if cfg.Traefik.FileSyncInterval == 0 {
+		cfg.Traefik.FileSyncInterval = time.Minute
+	}
+	if cfg.Traefik.BaseDomainCertResolver == "" {
+		cfg.Traefik.BaseDomainCertResolver = "letsencrypt-dns"
+	}
+	if cfg.Traefik.Image == "" {
+		cfg.Traefik.Image = "traefik:v3.2"
+	}
+	if cfg.Traefik.ContainerName == "" {
+		cfg.Traefik.ContainerName = "podoru_traefik"
+	}
+	if cfg.Traefik.ProviderURL == "" {
+		cfg.Traefik.ProviderURL = fmt.Sprintf("http://host.docker.internal:%d/api/v1/traefik/config", cfg.App.Port)
+	}
+	if cfg.ImageWatcher.PollInterval == 0 {
+		cfg.ImageWatcher.PollInterval = time.Minute
+	}
+	if cfg.Build.WorkDir == "" {
+		cfg.Build.WorkDir = filepath.Join(os.TempDir(), "podoru-builds")
+	}
+	// Build limits left unset fall back to the defaults below; a negative
+	// value lifts the limit entirely
+	if cfg.Build.MaxConcurrent == 0 {
+		cfg.Build.MaxConcurrent = 2
+	}
+	if cfg.Build.MaxConcurrentPerTeam == 0 {
+		cfg.Build.MaxConcurrentPerTeam = 1
+	}
+	if cfg.Scheduler.PollInterval == 0 {
+		cfg.Scheduler.PollInterval = 30 * time.Second
+	}
 }
 
 func (c *AppConfig) IsDevelopment() bool {
@@ -0,0 +1,40 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+// ResolverImpl implements the Resolver interface using the Go resolver
+type ResolverImpl struct {
+	resolver *net.Resolver
+}
+
+// NewResolver creates a new Resolver. An empty server uses the system
+// configuration, otherwise every query goes to the given "host:port"
+func NewResolver(server string) *ResolverImpl {
+	resolver := &net.Resolver{}
+	if server != "" {
+		resolver.PreferGo = true
+		resolver.Dial = func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, server)
+		}
+	}
+	return &ResolverImpl{resolver: resolver}
+}
+
+// LookupTXT returns the TXT records of name. A missing name or record is not
+// an error, it simply has no records
+func (r *ResolverImpl) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	records, err := r.resolver.LookupTXT(ctx, name)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return records, nil
+}
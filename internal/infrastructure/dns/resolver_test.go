@@ -0,0 +1,106 @@
+package dns_test
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/podoru/spinner-podoru/internal/infrastructure/dns"
+)
+
+// serveTXT answers TXT queries from records over UDP and NXDOMAIN for
+// anything else, returning the stub's address
+func serveTXT(t *testing.T, records map[string][]string) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			var parser dnsmessage.Parser
+			header, err := parser.Start(buf[:n])
+			if err != nil {
+				continue
+			}
+			question, err := parser.Question()
+			if err != nil {
+				continue
+			}
+
+			builder := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: header.ID, Response: true, Authoritative: true})
+			builder.EnableCompression()
+			builder.StartQuestions()
+			builder.Question(question)
+
+			txt, ok := records[question.Name.String()]
+			if !ok && question.Type == dnsmessage.TypeTXT {
+				builder = dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: header.ID, Response: true, RCode: dnsmessage.RCodeNameError})
+				builder.StartQuestions()
+				builder.Question(question)
+			}
+			if ok && question.Type == dnsmessage.TypeTXT {
+				builder.StartAnswers()
+				for _, value := range txt {
+					builder.TXTResource(dnsmessage.ResourceHeader{
+						Name:  question.Name,
+						Class: dnsmessage.ClassINET,
+						TTL:   60,
+					}, dnsmessage.TXTResource{TXT: []string{value}})
+				}
+			}
+
+			msg, err := builder.Finish()
+			if err != nil {
+				continue
+			}
+			conn.WriteTo(msg, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestResolverLookupTXT(t *testing.T) {
+	server := serveTXT(t, map[string][]string{
+		"_podoru-challenge.example.com.": {"podoru-abc123", "other"},
+	})
+	resolver := dns.NewResolver(server)
+
+	tests := []struct {
+		name string
+		host string
+		want []string
+	}{
+		{name: "existing record", host: "_podoru-challenge.example.com", want: []string{"podoru-abc123", "other"}},
+		{name: "missing name", host: "_podoru-challenge.missing.com", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			got, err := resolver.LookupTXT(ctx, tt.host)
+			if err != nil {
+				t.Fatalf("LookupTXT() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LookupTXT() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/go-connections/nat"
 
 	domainDocker "github.com/podoru/spinner-podoru/internal/domain/docker"
@@ -38,6 +41,32 @@ func (m *ContainerManagerImpl) PullImage(ctx context.Context, imageName string)
 	return err
 }
 
+// GetImageDigest returns the registry digest of a locally pulled image
+func (m *ContainerManagerImpl) GetImageDigest(ctx context.Context, imageName string) (string, error) {
+	info, _, err := m.client.cli.ImageInspectWithRaw(ctx, imageName)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect image %s: %w", imageName, err)
+	}
+
+	for _, repoDigest := range info.RepoDigests {
+		if _, digest, ok := strings.Cut(repoDigest, "@"); ok {
+			return digest, nil
+		}
+	}
+
+	return "", fmt.Errorf("image %s has no repository digest", imageName)
+}
+
+// GetRemoteImageDigest asks the registry for the digest the image reference currently points to
+func (m *ContainerManagerImpl) GetRemoteImageDigest(ctx context.Context, imageName string) (string, error) {
+	info, err := m.client.cli.DistributionInspect(ctx, imageName, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to query registry for %s: %w", imageName, err)
+	}
+
+	return info.Descriptor.Digest.String(), nil
+}
+
 // CreateContainer creates a new container
 func (m *ContainerManagerImpl) CreateContainer(ctx context.Context, cfg *domainDocker.ContainerConfig) (string, error) {
 	// Build port bindings
@@ -54,6 +83,7 @@ func (m *ContainerManagerImpl) CreateContainer(ctx context.Context, cfg *domainD
 
 	containerConfig := &container.Config{
 		Image:        cfg.Image,
+		Cmd:          cfg.Cmd,
 		Env:          cfg.Env,
 		ExposedPorts: exposedPorts,
 		Labels:       cfg.Labels,
@@ -64,12 +94,13 @@ func (m *ContainerManagerImpl) CreateContainer(ctx context.Context, cfg *domainD
 		Mounts:        mounts,
 		Resources:     resources,
 		RestartPolicy: restartPolicy,
+		ExtraHosts:    cfg.ExtraHosts,
 	}
 
 	networkConfig := &network.NetworkingConfig{}
 	if cfg.NetworkID != "" {
 		networkConfig.EndpointsConfig = map[string]*network.EndpointSettings{
-			cfg.NetworkID: {},
+			cfg.NetworkID: {Aliases: cfg.Aliases},
 		}
 	}
 
@@ -101,17 +132,37 @@ func (m *ContainerManagerImpl) RemoveContainer(ctx context.Context, containerID
 	return m.client.cli.ContainerRemove(ctx, containerID, container.RemoveOptions{Force: force})
 }
 
-// InspectContainer inspects a container
+// InspectContainer inspects a container by ID or name
 func (m *ContainerManagerImpl) InspectContainer(ctx context.Context, containerID string) (*domainDocker.ContainerInfo, error) {
 	info, err := m.client.cli.ContainerInspect(ctx, containerID)
 	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s", domainDocker.ErrContainerNotFound, containerID)
+		}
 		return nil, err
 	}
 
+	var ports []int
+	var imageName string
+	var labels map[string]string
+	if info.Config != nil {
+		imageName = info.Config.Image
+		labels = info.Config.Labels
+		for port := range info.Config.ExposedPorts {
+			if port.Proto() == "tcp" {
+				ports = append(ports, port.Int())
+			}
+		}
+		sort.Ints(ports)
+	}
+
 	return &domainDocker.ContainerInfo{
-		ID:     info.ID,
-		Status: info.State.Status,
-		State:  info.State.Status,
+		ID:           info.ID,
+		Image:        imageName,
+		Status:       info.State.Status,
+		State:        info.State.Status,
+		Labels:       labels,
+		ExposedPorts: ports,
 	}, nil
 }
 
@@ -121,6 +172,60 @@ func (m *ContainerManagerImpl) ValidateNetwork(ctx context.Context, networkName
 	return err
 }
 
+// CreateNetwork creates a network, returning the existing one if a network
+// with the same name is already there
+func (m *ContainerManagerImpl) CreateNetwork(ctx context.Context, cfg *domainDocker.NetworkConfig) (string, error) {
+	if existing, err := m.client.cli.NetworkInspect(ctx, cfg.Name, network.InspectOptions{}); err == nil {
+		return existing.ID, nil
+	}
+
+	options := network.CreateOptions{
+		Driver: cfg.Driver,
+		Labels: cfg.Labels,
+		// Overlay networks must be attachable for standalone containers to join
+		Attachable: cfg.Driver == string(entity.NetworkDriverOverlay),
+	}
+	if cfg.Subnet != "" {
+		options.IPAM = &network.IPAM{
+			Config: []network.IPAMConfig{{Subnet: cfg.Subnet, Gateway: cfg.Gateway}},
+		}
+	}
+
+	resp, err := m.client.cli.NetworkCreate(ctx, cfg.Name, options)
+	if err != nil {
+		return "", fmt.Errorf("failed to create network %s: %w", cfg.Name, err)
+	}
+
+	return resp.ID, nil
+}
+
+// RemoveNetwork removes a network, ignoring networks that are already gone
+func (m *ContainerManagerImpl) RemoveNetwork(ctx context.Context, networkID string) error {
+	err := m.client.cli.NetworkRemove(ctx, networkID)
+	if err != nil && !errdefs.IsNotFound(err) {
+		return fmt.Errorf("failed to remove network %s: %w", networkID, err)
+	}
+	return nil
+}
+
+// ConnectNetwork attaches a container to a network under the given DNS aliases
+func (m *ContainerManagerImpl) ConnectNetwork(ctx context.Context, networkID, containerID string, aliases []string) error {
+	err := m.client.cli.NetworkConnect(ctx, networkID, containerID, &network.EndpointSettings{Aliases: aliases})
+	if err != nil {
+		return fmt.Errorf("failed to connect container to network %s: %w", networkID, err)
+	}
+	return nil
+}
+
+// DisconnectNetwork detaches a container from a network
+func (m *ContainerManagerImpl) DisconnectNetwork(ctx context.Context, networkID, containerID string) error {
+	err := m.client.cli.NetworkDisconnect(ctx, networkID, containerID, true)
+	if err != nil && !errdefs.IsNotFound(err) {
+		return fmt.Errorf("failed to disconnect container from network %s: %w", networkID, err)
+	}
+	return nil
+}
+
 // GetLogs retrieves container logs
 func (m *ContainerManagerImpl) GetLogs(ctx context.Context, containerID string, opts *domainDocker.LogOptions) (io.ReadCloser, error) {
 	options := container.LogsOptions{
@@ -154,7 +259,7 @@ func buildPortBindings(portMappings []entity.PortMapping) (nat.PortSet, nat.Port
 
 		if pm.HostPort != nil {
 			portBindings[port] = []nat.PortBinding{
-				{HostPort: fmt.Sprintf("%d", *pm.HostPort)},
+				{HostIP: pm.HostIP, HostPort: fmt.Sprintf("%d", *pm.HostPort)},
 			}
 		}
 	}
@@ -0,0 +1,144 @@
+package docker
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/api/types"
+
+	domainDocker "github.com/podoru/spinner-podoru/internal/domain/docker"
+)
+
+// buildMessage is a single line of the daemon's JSON build stream
+type buildMessage struct {
+	Stream      string `json:"stream"`
+	Status      string `json:"status"`
+	Error       string `json:"error"`
+	ErrorDetail *struct {
+		Message string `json:"message"`
+	} `json:"errorDetail"`
+}
+
+// BuildImage builds an image from a local directory, streaming build output to logs
+func (m *ContainerManagerImpl) BuildImage(ctx context.Context, opts *domainDocker.BuildOptions, logs io.Writer) error {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(tarDirectory(opts.ContextDir, pw))
+	}()
+	defer pr.Close()
+
+	buildArgs := make(map[string]*string, len(opts.BuildArgs))
+	for k, v := range opts.BuildArgs {
+		buildArgs[k] = &v
+	}
+
+	buildOptions := types.ImageBuildOptions{
+		Tags:        opts.Tags,
+		Dockerfile:  opts.Dockerfile,
+		Labels:      opts.Labels,
+		BuildArgs:   buildArgs,
+		Target:      opts.Target,
+		CacheFrom:   opts.CacheFrom,
+		NoCache:     opts.NoCache,
+		Remove:      true,
+		ForceRemove: true,
+	}
+	if opts.CPULimit > 0 {
+		buildOptions.CPUPeriod = 100000
+		buildOptions.CPUQuota = int64(opts.CPULimit * 100000)
+	}
+	if opts.MemoryLimit > 0 {
+		// Equal memory and swap limits keep build steps from swapping
+		buildOptions.Memory = opts.MemoryLimit
+		buildOptions.MemorySwap = opts.MemoryLimit
+	}
+
+	resp, err := m.client.cli.ImageBuild(ctx, pr, buildOptions)
+	if err != nil {
+		return fmt.Errorf("failed to start build: %w", err)
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var msg buildMessage
+		if err := decoder.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to read build output: %w", err)
+		}
+
+		if msg.ErrorDetail != nil && msg.ErrorDetail.Message != "" {
+			return errors.New(msg.ErrorDetail.Message)
+		}
+		if msg.Error != "" {
+			return errors.New(msg.Error)
+		}
+
+		if msg.Stream != "" {
+			io.WriteString(logs, msg.Stream)
+		} else if msg.Status != "" {
+			io.WriteString(logs, msg.Status+"\n")
+		}
+	}
+}
+
+// tarDirectory writes dir as an uncompressed tar stream, skipping the .git directory
+func tarDirectory(dir string, w io.Writer) error {
+	tw := tar.NewWriter(w)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return tw.Close()
+}
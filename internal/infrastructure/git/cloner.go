@@ -0,0 +1,99 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+
+	domainGit "github.com/podoru/spinner-podoru/internal/domain/git"
+)
+
+// ClonerImpl implements the Cloner interface using the git CLI
+type ClonerImpl struct{}
+
+// NewCloner creates a new Cloner
+func NewCloner() *ClonerImpl {
+	return &ClonerImpl{}
+}
+
+// Clone performs a shallow clone of a single branch into opts.Dir
+func (c *ClonerImpl) Clone(ctx context.Context, opts *domainGit.CloneOptions) (*domainGit.CommitInfo, error) {
+	cloneURL, err := authenticatedURL(opts.URL, opts.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"clone", "--depth", "1", "--single-branch"}
+	if opts.Branch != "" {
+		args = append(args, "--branch", opts.Branch)
+	}
+	args = append(args, cloneURL, opts.Dir)
+
+	if _, err := run(ctx, "", args...); err != nil {
+		// Never echo the URL back, it may carry the token
+		return nil, fmt.Errorf("failed to clone %s: %w", RepositoryURL(opts.URL), redact(err, opts.Token))
+	}
+
+	out, err := run(ctx, opts.Dir, "log", "-1", "--format=%H%n%B")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit: %w", err)
+	}
+
+	sha, message, _ := strings.Cut(out, "\n")
+	return &domainGit.CommitInfo{
+		SHA:     strings.TrimSpace(sha),
+		Message: strings.TrimSpace(message),
+	}, nil
+}
+
+// RepositoryURL expands the "owner/repo" shorthand stored on projects to a
+// GitHub clone URL; full URLs are returned unchanged
+func RepositoryURL(repo string) string {
+	if strings.Contains(repo, "://") || strings.HasPrefix(repo, "git@") {
+		return repo
+	}
+	return "https://github.com/" + strings.TrimSuffix(repo, ".git") + ".git"
+}
+
+func authenticatedURL(repo, token string) (string, error) {
+	raw := RepositoryURL(repo)
+	if token == "" {
+		return raw, nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return "", fmt.Errorf("access tokens require an http(s) repository URL")
+	}
+	u.User = url.UserPassword("x-access-token", token)
+	return u.String(), nil
+}
+
+func run(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	// Fail instead of hanging on a credential prompt
+	cmd.Env = append(cmd.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s", msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+func redact(err error, token string) error {
+	if token == "" {
+		return err
+	}
+	return fmt.Errorf("%s", strings.ReplaceAll(err.Error(), token, "***"))
+}
@@ -0,0 +1,179 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/podoru/spinner-podoru/internal/infrastructure/config"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/oidc"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/oidc/oidctest"
+)
+
+const verifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXkdBjftJeZ4CVP-mB92K27u"
+
+func challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func newProvider(t *testing.T) (*oidc.Provider, *oidctest.Server) {
+	t.Helper()
+	server, err := oidctest.NewServer("podoru", "secret")
+	if err != nil {
+		t.Fatalf("failed to start provider: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	server.SetUser(oidctest.User{
+		Subject:       "user-1",
+		Email:         "jane@example.com",
+		EmailVerified: true,
+		Name:          "Jane",
+		Groups:        []string{"platform", "ops"},
+	})
+
+	p := oidc.NewProvider(&config.OIDCConfig{
+		IssuerURL:    server.URL,
+		ClientID:     "podoru",
+		ClientSecret: "secret",
+		RedirectURL:  "http://podoru.test/api/v1/auth/oidc/callback",
+		Scopes:       []string{"openid", "email", "profile"},
+		GroupsClaim:  "groups",
+	})
+	return p, server
+}
+
+func authorize(t *testing.T, p *oidc.Provider, server *oidctest.Server, nonce string) string {
+	t.Helper()
+	authURL, err := p.AuthCodeURL(context.Background(), "state-1", nonce, challenge(verifier))
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code, state, err := server.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if state != "state-1" {
+		t.Fatalf("expected state to round-trip, got %q", state)
+	}
+	return code
+}
+
+func TestProvider_AuthCodeURL(t *testing.T) {
+	p, server := newProvider(t)
+
+	authURL, err := p.AuthCodeURL(context.Background(), "state-1", "nonce-1", "challenge-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("invalid URL: %v", err)
+	}
+	if got := u.Scheme + "://" + u.Host + u.Path; got != server.URL+"/authorize" {
+		t.Errorf("expected the discovered endpoint, got %s", got)
+	}
+
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             "podoru",
+		"redirect_uri":          "http://podoru.test/api/v1/auth/oidc/callback",
+		"scope":                 "openid email profile",
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        "challenge-1",
+		"code_challenge_method": "S256",
+	}
+	for k, v := range want {
+		if got := u.Query().Get(k); got != v {
+			t.Errorf("expected %s=%q, got %q", k, v, got)
+		}
+	}
+}
+
+func TestProvider_Exchange(t *testing.T) {
+	p, server := newProvider(t)
+	code := authorize(t, p, server, "nonce-1")
+
+	identity, err := p.Exchange(context.Background(), code, verifier, "nonce-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if identity.Issuer != server.URL || identity.Subject != "user-1" {
+		t.Errorf("unexpected identity %s %s", identity.Issuer, identity.Subject)
+	}
+	if identity.Email != "jane@example.com" || !identity.EmailVerified || identity.Name != "Jane" {
+		t.Errorf("unexpected profile %+v", identity)
+	}
+	if len(identity.Groups) != 2 || identity.Groups[0] != "platform" || identity.Groups[1] != "ops" {
+		t.Errorf("unexpected groups %v", identity.Groups)
+	}
+
+	if _, err := p.Exchange(context.Background(), code, verifier, "nonce-1"); err == nil {
+		t.Error("expected a redeemed code to be rejected")
+	}
+}
+
+func TestProvider_ExchangeRejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		claims   map[string]any
+		verifier string
+		nonce    string
+	}{
+		{name: "wrong nonce", nonce: "other"},
+		{name: "wrong verifier", verifier: "not-the-verifier-not-the-verifier-not-the-verifier"},
+		{name: "other audience", claims: map[string]any{"aud": "someone-else"}},
+		{name: "other issuer", claims: map[string]any{"iss": "https://evil.example.com"}},
+		{name: "expired", claims: map[string]any{"exp": time.Now().Add(-time.Hour).Unix()}},
+		{name: "no subject", claims: map[string]any{"sub": ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, server := newProvider(t)
+			server.SetClaims(tt.claims)
+			code := authorize(t, p, server, "nonce-1")
+
+			v, nonce := verifier, "nonce-1"
+			if tt.verifier != "" {
+				v = tt.verifier
+			}
+			if tt.nonce != "" {
+				nonce = tt.nonce
+			}
+			if _, err := p.Exchange(context.Background(), code, v, nonce); err == nil {
+				t.Error("expected the exchange to fail")
+			}
+		})
+	}
+}
+
+func TestProvider_DiscoveryIssuerMismatch(t *testing.T) {
+	server, err := oidctest.NewServer("podoru", "")
+	if err != nil {
+		t.Fatalf("failed to start provider: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	p := oidc.NewProvider(&config.OIDCConfig{
+		IssuerURL: "http://" + server.Listener.Addr().String() + "/",
+		ClientID:  "podoru",
+	})
+	// the trailing slash is trimmed, so this one matches
+	if _, err := p.AuthCodeURL(context.Background(), "s", "n", "c"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	p = oidc.NewProvider(&config.OIDCConfig{
+		IssuerURL: server.URL + "/tenant",
+		ClientID:  "podoru",
+	})
+	if _, err := p.AuthCodeURL(context.Background(), "s", "n", "c"); err == nil {
+		t.Error("expected discovery to fail for another issuer")
+	}
+}
@@ -0,0 +1,53 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	domainSecret "github.com/podoru/spinner-podoru/internal/domain/secret"
+)
+
+// FileProvider reads secrets from files in one directory, such as the
+// Docker secrets mounted at /run/secrets. References are file names.
+type FileProvider struct {
+	dir string
+}
+
+func NewFileProvider(dir string) *FileProvider {
+	return &FileProvider{dir: dir}
+}
+
+func (p *FileProvider) Scheme() string {
+	return domainSecret.SchemeFile
+}
+
+// Resolve returns the contents of the file without its trailing newline.
+// References cannot leave the directory, not even through symlinks.
+func (p *FileProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	root, err := os.OpenRoot(p.dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to open secrets directory: %w", err)
+	}
+	defer root.Close()
+
+	f, err := root.Open(ref)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("%w: %s", domainSecret.ErrSecretNotFound, ref)
+		}
+		return "", fmt.Errorf("%w: %v", domainSecret.ErrInvalidReference, err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimSuffix(string(data), "\n")
+	return strings.TrimSuffix(value, "\r"), nil
+}
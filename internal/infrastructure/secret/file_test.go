@@ -0,0 +1,45 @@
+package secret_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	domainSecret "github.com/podoru/spinner-podoru/internal/domain/secret"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/secret"
+)
+
+func TestFileProvider_Resolve(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "db_password"), []byte("s3cret\n"), 0o600)
+	os.WriteFile(filepath.Join(outside, "other"), []byte("not yours"), 0o600)
+	os.Symlink(filepath.Join(outside, "other"), filepath.Join(dir, "link"))
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr error
+	}{
+		{name: "file", ref: "db_password", want: "s3cret"},
+		{name: "missing", ref: "nope", wantErr: domainSecret.ErrSecretNotFound},
+		{name: "parent directory", ref: "../" + filepath.Base(outside) + "/other", wantErr: domainSecret.ErrInvalidReference},
+		{name: "symlink out", ref: "link", wantErr: domainSecret.ErrInvalidReference},
+	}
+
+	p := secret.NewFileProvider(dir)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.Resolve(context.Background(), tt.ref)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	domainSecret "github.com/podoru/spinner-podoru/internal/domain/secret"
+)
+
+// reservedMounts are Vault's own APIs rather than secrets engines; nothing
+// under them is handed out, whatever the token may read
+var reservedMounts = []string{"auth", "sys", "identity"}
+
+// VaultProvider reads fields of HashiCorp Vault KV secrets. References are
+// the API path of the secret and the field, as in "secret/data/app#db_password"
+// for KV version 2 or "kv/app#db_password" for version 1.
+type VaultProvider struct {
+	address   string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+func NewVaultProvider(address, token, namespace string) *VaultProvider {
+	return &VaultProvider{
+		address:   strings.TrimSuffix(address, "/"),
+		token:     token,
+		namespace: namespace,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *VaultProvider) Scheme() string {
+	return domainSecret.SchemeVault
+}
+
+func (p *VaultProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	path, field, _ := strings.Cut(ref, "#")
+	path = strings.Trim(path, "/")
+	if path == "" || field == "" {
+		return "", fmt.Errorf("%w: %q needs a path and a #field", domainSecret.ErrInvalidReference, ref)
+	}
+	if mount, _, _ := strings.Cut(path, "/"); slices.Contains(reservedMounts, mount) {
+		return "", fmt.Errorf("%w: %s/ is not a secrets engine", domainSecret.ErrInvalidReference, mount)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.address+"/v1/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach vault: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Data   map[string]any `json:"data"`
+		Errors []string       `json:"errors"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("%w: %s", domainSecret.ErrSecretNotFound, path)
+	case resp.StatusCode != http.StatusOK:
+		if len(body.Errors) > 0 {
+			return "", fmt.Errorf("vault returned %s: %s", resp.Status, strings.Join(body.Errors, "; "))
+		}
+		return "", fmt.Errorf("vault returned %s", resp.Status)
+	}
+
+	// KV version 2 nests the secret next to its metadata
+	data := body.Data
+	if inner, ok := data["data"].(map[string]any); ok && data["metadata"] != nil {
+		data = inner
+	}
+
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("%w: %s has no field %s", domainSecret.ErrSecretNotFound, path, field)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
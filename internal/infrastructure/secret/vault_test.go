@@ -0,0 +1,83 @@
+package secret_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	domainSecret "github.com/podoru/spinner-podoru/internal/domain/secret"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/secret"
+)
+
+// newVault stands in for Vault's HTTP API with a KV v2 mount at secret/ and
+// a KV v1 mount at kv/
+func newVault(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/app":
+			w.Write([]byte(`{"data":{"data":{"db_password":"s3cret","port":5432},"metadata":{"version":3}}}`))
+		case "/v1/kv/app":
+			w.Write([]byte(`{"data":{"api_key":"abc123"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVaultProvider_Resolve(t *testing.T) {
+	server := newVault(t)
+
+	tests := []struct {
+		name    string
+		token   string
+		ref     string
+		want    string
+		wantErr error
+	}{
+		{name: "kv v2", token: "root", ref: "secret/data/app#db_password", want: "s3cret"},
+		{name: "kv v2 number", token: "root", ref: "secret/data/app#port", want: "5432"},
+		{name: "kv v1", token: "root", ref: "kv/app#api_key", want: "abc123"},
+		{name: "missing field", token: "root", ref: "secret/data/app#nope", wantErr: domainSecret.ErrSecretNotFound},
+		{name: "missing secret", token: "root", ref: "secret/data/other#key", wantErr: domainSecret.ErrSecretNotFound},
+		{name: "no field", token: "root", ref: "secret/data/app", wantErr: domainSecret.ErrInvalidReference},
+		{name: "token lookup", token: "root", ref: "auth/token/lookup-self#id", wantErr: domainSecret.ErrInvalidReference},
+		{name: "system backend", token: "root", ref: "/sys/mounts#type", wantErr: domainSecret.ErrInvalidReference},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := secret.NewVaultProvider(server.URL+"/", tt.token, "")
+			got, err := p.Resolve(context.Background(), tt.ref)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestVaultProvider_PermissionDenied(t *testing.T) {
+	server := newVault(t)
+	p := secret.NewVaultProvider(server.URL, "wrong", "")
+
+	_, err := p.Resolve(context.Background(), "secret/data/app#db_password")
+	if err == nil {
+		t.Fatal("expected an error for a rejected token")
+	}
+	if errors.Is(err, domainSecret.ErrSecretNotFound) {
+		t.Errorf("a rejected token should not look like a missing secret: %v", err)
+	}
+}
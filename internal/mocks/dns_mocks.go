@@ -0,0 +1,15 @@
+package mocks
+
+import "context"
+
+// MockResolver is a mock implementation of Resolver
+type MockResolver struct {
+	LookupTXTFunc func(ctx context.Context, name string) ([]string, error)
+}
+
+func (m *MockResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if m.LookupTXTFunc != nil {
+		return m.LookupTXTFunc(ctx, name)
+	}
+	return nil, nil
+}
@@ -0,0 +1,140 @@
+package mocks
+
+import (
+	"context"
+	"io"
+
+	domainDocker "github.com/podoru/spinner-podoru/internal/domain/docker"
+)
+
+// MockContainerManager is a mock implementation of ContainerManager
+type MockContainerManager struct {
+	PullImageFunc            func(ctx context.Context, imageName string) error
+	GetImageDigestFunc       func(ctx context.Context, imageName string) (string, error)
+	GetRemoteImageDigestFunc func(ctx context.Context, imageName string) (string, error)
+	BuildImageFunc           func(ctx context.Context, opts *domainDocker.BuildOptions, logs io.Writer) error
+	CreateContainerFunc      func(ctx context.Context, config *domainDocker.ContainerConfig) (string, error)
+	StartContainerFunc       func(ctx context.Context, containerID string) error
+	StopContainerFunc        func(ctx context.Context, containerID string, timeout *int) error
+	RestartContainerFunc     func(ctx context.Context, containerID string, timeout *int) error
+	RemoveContainerFunc      func(ctx context.Context, containerID string, force bool) error
+	InspectContainerFunc     func(ctx context.Context, containerID string) (*domainDocker.ContainerInfo, error)
+	ValidateNetworkFunc      func(ctx context.Context, networkName string) error
+	CreateNetworkFunc        func(ctx context.Context, config *domainDocker.NetworkConfig) (string, error)
+	RemoveNetworkFunc        func(ctx context.Context, networkID string) error
+	ConnectNetworkFunc       func(ctx context.Context, networkID, containerID string, aliases []string) error
+	DisconnectNetworkFunc    func(ctx context.Context, networkID, containerID string) error
+	GetLogsFunc              func(ctx context.Context, containerID string, opts *domainDocker.LogOptions) (io.ReadCloser, error)
+}
+
+func (m *MockContainerManager) PullImage(ctx context.Context, imageName string) error {
+	if m.PullImageFunc != nil {
+		return m.PullImageFunc(ctx, imageName)
+	}
+	return nil
+}
+
+func (m *MockContainerManager) GetImageDigest(ctx context.Context, imageName string) (string, error) {
+	if m.GetImageDigestFunc != nil {
+		return m.GetImageDigestFunc(ctx, imageName)
+	}
+	return "", nil
+}
+
+func (m *MockContainerManager) GetRemoteImageDigest(ctx context.Context, imageName string) (string, error) {
+	if m.GetRemoteImageDigestFunc != nil {
+		return m.GetRemoteImageDigestFunc(ctx, imageName)
+	}
+	return "", nil
+}
+
+func (m *MockContainerManager) BuildImage(ctx context.Context, opts *domainDocker.BuildOptions, logs io.Writer) error {
+	if m.BuildImageFunc != nil {
+		return m.BuildImageFunc(ctx, opts, logs)
+	}
+	return nil
+}
+
+func (m *MockContainerManager) CreateContainer(ctx context.Context, config *domainDocker.ContainerConfig) (string, error) {
+	if m.CreateContainerFunc != nil {
+		return m.CreateContainerFunc(ctx, config)
+	}
+	return "", nil
+}
+
+func (m *MockContainerManager) StartContainer(ctx context.Context, containerID string) error {
+	if m.StartContainerFunc != nil {
+		return m.StartContainerFunc(ctx, containerID)
+	}
+	return nil
+}
+
+func (m *MockContainerManager) StopContainer(ctx context.Context, containerID string, timeout *int) error {
+	if m.StopContainerFunc != nil {
+		return m.StopContainerFunc(ctx, containerID, timeout)
+	}
+	return nil
+}
+
+func (m *MockContainerManager) RestartContainer(ctx context.Context, containerID string, timeout *int) error {
+	if m.RestartContainerFunc != nil {
+		return m.RestartContainerFunc(ctx, containerID, timeout)
+	}
+	return nil
+}
+
+func (m *MockContainerManager) RemoveContainer(ctx context.Context, containerID string, force bool) error {
+	if m.RemoveContainerFunc != nil {
+		return m.RemoveContainerFunc(ctx, containerID, force)
+	}
+	return nil
+}
+
+func (m *MockContainerManager) InspectContainer(ctx context.Context, containerID string) (*domainDocker.ContainerInfo, error) {
+	if m.InspectContainerFunc != nil {
+		return m.InspectContainerFunc(ctx, containerID)
+	}
+	return nil, domainDocker.ErrContainerNotFound
+}
+
+func (m *MockContainerManager) ValidateNetwork(ctx context.Context, networkName string) error {
+	if m.ValidateNetworkFunc != nil {
+		return m.ValidateNetworkFunc(ctx, networkName)
+	}
+	return nil
+}
+
+func (m *MockContainerManager) CreateNetwork(ctx context.Context, config *domainDocker.NetworkConfig) (string, error) {
+	if m.CreateNetworkFunc != nil {
+		return m.CreateNetworkFunc(ctx, config)
+	}
+	return "", nil
+}
+
+func (m *MockContainerManager) RemoveNetwork(ctx context.Context, networkID string) error {
+	if m.RemoveNetworkFunc != nil {
+		return m.RemoveNetworkFunc(ctx, networkID)
+	}
+	return nil
+}
+
+func (m *MockContainerManager) ConnectNetwork(ctx context.Context, networkID, containerID string, aliases []string) error {
+	if m.ConnectNetworkFunc != nil {
+		return m.ConnectNetworkFunc(ctx, networkID, containerID, aliases)
+	}
+	return nil
+}
+
+func (m *MockContainerManager) DisconnectNetwork(ctx context.Context, networkID, containerID string) error {
+	if m.DisconnectNetworkFunc != nil {
+		return m.DisconnectNetworkFunc(ctx, networkID, containerID)
+	}
+	return nil
+}
+
+func (m *MockContainerManager) GetLogs(ctx context.Context, containerID string, opts *domainDocker.LogOptions) (io.ReadCloser, error) {
+	if m.GetLogsFunc != nil {
+		return m.GetLogsFunc(ctx, containerID, opts)
+	}
+	return nil, nil
+}
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -10,13 +11,15 @@ import (
 
 // MockUserRepository is a mock implementation of UserRepository
 type MockUserRepository struct {
-	CreateFunc        func(ctx context.Context, user *entity.User) error
-	GetByIDFunc       func(ctx context.Context, id uuid.UUID) (*entity.User, error)
-	GetByEmailFunc    func(ctx context.Context, email string) (*entity.User, error)
-	UpdateFunc        func(ctx context.Context, user *entity.User) error
-	DeleteFunc        func(ctx context.Context, id uuid.UUID) error
-	ExistsByEmailFunc func(ctx context.Context, email string) (bool, error)
-	CountFunc         func(ctx context.Context) (int64, error)
+	CreateFunc           func(ctx context.Context, user *entity.User) error
+	GetByIDFunc          func(ctx context.Context, id uuid.UUID) (*entity.User, error)
+	GetByEmailFunc       func(ctx context.Context, email string) (*entity.User, error)
+	UpdateFunc           func(ctx context.Context, user *entity.User) error
+	DeleteFunc           func(ctx context.Context, id uuid.UUID) error
+	ExistsByEmailFunc    func(ctx context.Context, email string) (bool, error)
+	CountFunc            func(ctx context.Context) (int64, error)
+	GetByOIDCSubjectFunc func(ctx context.Context, issuer, subject string) (*entity.User, error)
+	LinkOIDCSubjectFunc  func(ctx context.Context, id uuid.UUID, issuer, subject string) error
 }
 
 func (m *MockUserRepository) Create(ctx context.Context, user *entity.User) error {
@@ -68,18 +71,63 @@ func (m *MockUserRepository) Count(ctx context.Context) (int64, error) {
 	return 0, nil
 }
 
+func (m *MockUserRepository) GetByOIDCSubject(ctx context.Context, issuer, subject string) (*entity.User, error) {
+	if m.GetByOIDCSubjectFunc != nil {
+		return m.GetByOIDCSubjectFunc(ctx, issuer, subject)
+	}
+	return nil, nil
+}
+
+func (m *MockUserRepository) LinkOIDCSubject(ctx context.Context, id uuid.UUID, issuer, subject string) error {
+	if m.LinkOIDCSubjectFunc != nil {
+		return m.LinkOIDCSubjectFunc(ctx, id, issuer, subject)
+	}
+	return nil
+}
+
+// MockOIDCStateRepository is a mock implementation of OIDCStateRepository
+type MockOIDCStateRepository struct {
+	CreateFunc        func(ctx context.Context, state *entity.OIDCState) error
+	ConsumeFunc       func(ctx context.Context, state string) (*entity.OIDCState, error)
+	DeleteExpiredFunc func(ctx context.Context) error
+}
+
+func (m *MockOIDCStateRepository) Create(ctx context.Context, state *entity.OIDCState) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, state)
+	}
+	return nil
+}
+
+func (m *MockOIDCStateRepository) Consume(ctx context.Context, state string) (*entity.OIDCState, error) {
+	if m.ConsumeFunc != nil {
+		return m.ConsumeFunc(ctx, state)
+	}
+	return nil, nil
+}
+
+func (m *MockOIDCStateRepository) DeleteExpired(ctx context.Context) error {
+	if m.DeleteExpiredFunc != nil {
+		return m.DeleteExpiredFunc(ctx)
+	}
+	return nil
+}
+
 // MockRefreshTokenRepository is a mock implementation of RefreshTokenRepository
 type MockRefreshTokenRepository struct {
-	CreateFunc            func(ctx context.Context, token *entity.RefreshToken) error
-	GetByTokenHashFunc    func(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
-	DeleteByUserIDFunc    func(ctx context.Context, userID uuid.UUID) error
-	DeleteByTokenHashFunc func(ctx context.Context, tokenHash string) error
-	DeleteExpiredFunc     func(ctx context.Context) error
+	CreateSessionFunc  func(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error
+	GetByTokenHashFunc func(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+	RotateFunc         func(ctx context.Context, used, next *entity.RefreshToken, client entity.SessionClient) (bool, error)
+	GetSessionFunc     func(ctx context.Context, id uuid.UUID) (*entity.Session, error)
+	ListSessionsFunc   func(ctx context.Context, userID uuid.UUID) ([]entity.Session, error)
+	DeleteSessionFunc  func(ctx context.Context, id uuid.UUID) error
+	DeleteByUserIDFunc func(ctx context.Context, userID uuid.UUID) error
+	DeleteExpiredFunc  func(ctx context.Context) error
 }
 
-func (m *MockRefreshTokenRepository) Create(ctx context.Context, token *entity.RefreshToken) error {
-	if m.CreateFunc != nil {
-		return m.CreateFunc(ctx, token)
+func (m *MockRefreshTokenRepository) CreateSession(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error {
+	if m.CreateSessionFunc != nil {
+		return m.CreateSessionFunc(ctx, session, token)
 	}
 	return nil
 }
@@ -91,6 +139,34 @@ func (m *MockRefreshTokenRepository) GetByTokenHash(ctx context.Context, tokenHa
 	return nil, nil
 }
 
+func (m *MockRefreshTokenRepository) Rotate(ctx context.Context, used, next *entity.RefreshToken, client entity.SessionClient) (bool, error) {
+	if m.RotateFunc != nil {
+		return m.RotateFunc(ctx, used, next, client)
+	}
+	return true, nil
+}
+
+func (m *MockRefreshTokenRepository) GetSession(ctx context.Context, id uuid.UUID) (*entity.Session, error) {
+	if m.GetSessionFunc != nil {
+		return m.GetSessionFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockRefreshTokenRepository) ListSessions(ctx context.Context, userID uuid.UUID) ([]entity.Session, error) {
+	if m.ListSessionsFunc != nil {
+		return m.ListSessionsFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (m *MockRefreshTokenRepository) DeleteSession(ctx context.Context, id uuid.UUID) error {
+	if m.DeleteSessionFunc != nil {
+		return m.DeleteSessionFunc(ctx, id)
+	}
+	return nil
+}
+
 func (m *MockRefreshTokenRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
 	if m.DeleteByUserIDFunc != nil {
 		return m.DeleteByUserIDFunc(ctx, userID)
@@ -98,20 +174,197 @@ func (m *MockRefreshTokenRepository) DeleteByUserID(ctx context.Context, userID
 	return nil
 }
 
-func (m *MockRefreshTokenRepository) DeleteByTokenHash(ctx context.Context, tokenHash string) error {
-	if m.DeleteByTokenHashFunc != nil {
-		return m.DeleteByTokenHashFunc(ctx, tokenHash)
+func (m *MockRefreshTokenRepository) DeleteExpired(ctx context.Context) error {
+	if m.DeleteExpiredFunc != nil {
+		return m.DeleteExpiredFunc(ctx)
 	}
 	return nil
 }
 
-func (m *MockRefreshTokenRepository) DeleteExpired(ctx context.Context) error {
+// MockMFARepository is a mock implementation of MFARepository
+type MockMFARepository struct {
+	GetTOTPFunc              func(ctx context.Context, userID uuid.UUID) (*entity.UserTOTP, error)
+	SaveTOTPFunc             func(ctx context.Context, totp *entity.UserTOTP, codes []entity.MFARecoveryCode) error
+	ConfirmTOTPFunc          func(ctx context.Context, userID uuid.UUID, confirmedAt time.Time) error
+	UseTOTPStepFunc          func(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+	DeleteTOTPFunc           func(ctx context.Context, userID uuid.UUID) error
+	UseRecoveryCodeFunc      func(ctx context.Context, userID uuid.UUID, codeHash string, usedAt time.Time) (bool, error)
+	ReplaceRecoveryCodesFunc func(ctx context.Context, userID uuid.UUID, codes []entity.MFARecoveryCode) error
+	CountRecoveryCodesFunc   func(ctx context.Context, userID uuid.UUID) (int, error)
+}
+
+func (m *MockMFARepository) GetTOTP(ctx context.Context, userID uuid.UUID) (*entity.UserTOTP, error) {
+	if m.GetTOTPFunc != nil {
+		return m.GetTOTPFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (m *MockMFARepository) SaveTOTP(ctx context.Context, totp *entity.UserTOTP, codes []entity.MFARecoveryCode) error {
+	if m.SaveTOTPFunc != nil {
+		return m.SaveTOTPFunc(ctx, totp, codes)
+	}
+	return nil
+}
+
+func (m *MockMFARepository) ConfirmTOTP(ctx context.Context, userID uuid.UUID, confirmedAt time.Time) error {
+	if m.ConfirmTOTPFunc != nil {
+		return m.ConfirmTOTPFunc(ctx, userID, confirmedAt)
+	}
+	return nil
+}
+
+func (m *MockMFARepository) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	if m.UseTOTPStepFunc != nil {
+		return m.UseTOTPStepFunc(ctx, userID, step)
+	}
+	return true, nil
+}
+
+func (m *MockMFARepository) DeleteTOTP(ctx context.Context, userID uuid.UUID) error {
+	if m.DeleteTOTPFunc != nil {
+		return m.DeleteTOTPFunc(ctx, userID)
+	}
+	return nil
+}
+
+func (m *MockMFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string, usedAt time.Time) (bool, error) {
+	if m.UseRecoveryCodeFunc != nil {
+		return m.UseRecoveryCodeFunc(ctx, userID, codeHash, usedAt)
+	}
+	return false, nil
+}
+
+func (m *MockMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []entity.MFARecoveryCode) error {
+	if m.ReplaceRecoveryCodesFunc != nil {
+		return m.ReplaceRecoveryCodesFunc(ctx, userID, codes)
+	}
+	return nil
+}
+
+func (m *MockMFARepository) CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error) {
+	if m.CountRecoveryCodesFunc != nil {
+		return m.CountRecoveryCodesFunc(ctx, userID)
+	}
+	return 0, nil
+}
+
+// MockMFAChallengeRepository is a mock implementation of MFAChallengeRepository
+type MockMFAChallengeRepository struct {
+	CreateFunc         func(ctx context.Context, challenge *entity.MFAChallenge) error
+	GetByTokenHashFunc func(ctx context.Context, tokenHash string) (*entity.MFAChallenge, error)
+	AddAttemptFunc     func(ctx context.Context, id uuid.UUID) (int, error)
+	DeleteFunc         func(ctx context.Context, id uuid.UUID) error
+	DeleteExpiredFunc  func(ctx context.Context) error
+}
+
+func (m *MockMFAChallengeRepository) Create(ctx context.Context, challenge *entity.MFAChallenge) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, challenge)
+	}
+	return nil
+}
+
+func (m *MockMFAChallengeRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.MFAChallenge, error) {
+	if m.GetByTokenHashFunc != nil {
+		return m.GetByTokenHashFunc(ctx, tokenHash)
+	}
+	return nil, nil
+}
+
+func (m *MockMFAChallengeRepository) AddAttempt(ctx context.Context, id uuid.UUID) (int, error) {
+	if m.AddAttemptFunc != nil {
+		return m.AddAttemptFunc(ctx, id)
+	}
+	return 0, nil
+}
+
+func (m *MockMFAChallengeRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *MockMFAChallengeRepository) DeleteExpired(ctx context.Context) error {
 	if m.DeleteExpiredFunc != nil {
 		return m.DeleteExpiredFunc(ctx)
 	}
 	return nil
 }
 
+// MockMFAPolicyRepository is a mock implementation of MFAPolicyRepository
+type MockMFAPolicyRepository struct {
+	GetFunc    func(ctx context.Context) (*entity.MFAPolicy, error)
+	UpdateFunc func(ctx context.Context, policy *entity.MFAPolicy) error
+}
+
+func (m *MockMFAPolicyRepository) Get(ctx context.Context) (*entity.MFAPolicy, error) {
+	if m.GetFunc != nil {
+		return m.GetFunc(ctx)
+	}
+	return &entity.MFAPolicy{Required: entity.MFARequiredNone}, nil
+}
+
+func (m *MockMFAPolicyRepository) Update(ctx context.Context, policy *entity.MFAPolicy) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, policy)
+	}
+	return nil
+}
+
+// MockAPITokenRepository is a mock implementation of APITokenRepository
+type MockAPITokenRepository struct {
+	CreateFunc         func(ctx context.Context, token *entity.APIToken) error
+	GetByIDFunc        func(ctx context.Context, id uuid.UUID) (*entity.APIToken, error)
+	GetByTokenHashFunc func(ctx context.Context, tokenHash string) (*entity.APIToken, error)
+	ListByUserIDFunc   func(ctx context.Context, userID uuid.UUID) ([]entity.APIToken, error)
+	RevokeFunc         func(ctx context.Context, id uuid.UUID, revokedAt time.Time) error
+	UpdateLastUsedFunc func(ctx context.Context, id uuid.UUID, lastUsedAt time.Time) error
+}
+
+func (m *MockAPITokenRepository) Create(ctx context.Context, token *entity.APIToken) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, token)
+	}
+	return nil
+}
+
+func (m *MockAPITokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.APIToken, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockAPITokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.APIToken, error) {
+	if m.GetByTokenHashFunc != nil {
+		return m.GetByTokenHashFunc(ctx, tokenHash)
+	}
+	return nil, nil
+}
+
+func (m *MockAPITokenRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]entity.APIToken, error) {
+	if m.ListByUserIDFunc != nil {
+		return m.ListByUserIDFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (m *MockAPITokenRepository) Revoke(ctx context.Context, id uuid.UUID, revokedAt time.Time) error {
+	if m.RevokeFunc != nil {
+		return m.RevokeFunc(ctx, id, revokedAt)
+	}
+	return nil
+}
+
+func (m *MockAPITokenRepository) UpdateLastUsed(ctx context.Context, id uuid.UUID, lastUsedAt time.Time) error {
+	if m.UpdateLastUsedFunc != nil {
+		return m.UpdateLastUsedFunc(ctx, id, lastUsedAt)
+	}
+	return nil
+}
+
 // MockTeamRepository is a mock implementation of TeamRepository
 type MockTeamRepository struct {
 	CreateFunc       func(ctx context.Context, team *entity.Team) error
@@ -224,6 +477,66 @@ func (m *MockTeamMemberRepository) CountByTeamID(ctx context.Context, teamID uui
 	return 0, nil
 }
 
+// MockCertificateRepository is a mock implementation of CertificateRepository
+type MockCertificateRepository struct {
+	CreateFunc               func(ctx context.Context, certificate *entity.Certificate) error
+	GetByIDFunc              func(ctx context.Context, id uuid.UUID) (*entity.Certificate, error)
+	UpdateFunc               func(ctx context.Context, certificate *entity.Certificate) error
+	DeleteFunc               func(ctx context.Context, id uuid.UUID) error
+	ListByTeamIDFunc         func(ctx context.Context, teamID uuid.UUID) ([]entity.Certificate, error)
+	ListExpiringByTeamIDFunc func(ctx context.Context, teamID uuid.UUID, before time.Time) ([]entity.Certificate, error)
+	ListByIDsFunc            func(ctx context.Context, ids []uuid.UUID) ([]entity.Certificate, error)
+}
+
+func (m *MockCertificateRepository) Create(ctx context.Context, certificate *entity.Certificate) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, certificate)
+	}
+	return nil
+}
+
+func (m *MockCertificateRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Certificate, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockCertificateRepository) Update(ctx context.Context, certificate *entity.Certificate) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, certificate)
+	}
+	return nil
+}
+
+func (m *MockCertificateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *MockCertificateRepository) ListByTeamID(ctx context.Context, teamID uuid.UUID) ([]entity.Certificate, error) {
+	if m.ListByTeamIDFunc != nil {
+		return m.ListByTeamIDFunc(ctx, teamID)
+	}
+	return nil, nil
+}
+
+func (m *MockCertificateRepository) ListExpiringByTeamID(ctx context.Context, teamID uuid.UUID, before time.Time) ([]entity.Certificate, error) {
+	if m.ListExpiringByTeamIDFunc != nil {
+		return m.ListExpiringByTeamIDFunc(ctx, teamID, before)
+	}
+	return nil, nil
+}
+
+func (m *MockCertificateRepository) ListByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.Certificate, error) {
+	if m.ListByIDsFunc != nil {
+		return m.ListByIDsFunc(ctx, ids)
+	}
+	return nil, nil
+}
+
 // MockProjectRepository is a mock implementation of ProjectRepository
 type MockProjectRepository struct {
 	CreateFunc              func(ctx context.Context, project *entity.Project) error
@@ -284,6 +597,58 @@ func (m *MockProjectRepository) ExistsByTeamAndSlug(ctx context.Context, teamID
 	return false, nil
 }
 
+// MockDeployTokenRepository is a mock implementation of DeployTokenRepository
+type MockDeployTokenRepository struct {
+	CreateFunc          func(ctx context.Context, token *entity.DeployToken) error
+	GetByIDFunc         func(ctx context.Context, id uuid.UUID) (*entity.DeployToken, error)
+	GetByTokenHashFunc  func(ctx context.Context, tokenHash string) (*entity.DeployToken, error)
+	ListByProjectIDFunc func(ctx context.Context, projectID uuid.UUID) ([]entity.DeployToken, error)
+	RevokeFunc          func(ctx context.Context, id uuid.UUID, revokedAt time.Time) error
+	UpdateLastUsedFunc  func(ctx context.Context, id uuid.UUID, lastUsedAt time.Time) error
+}
+
+func (m *MockDeployTokenRepository) Create(ctx context.Context, token *entity.DeployToken) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, token)
+	}
+	return nil
+}
+
+func (m *MockDeployTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.DeployToken, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockDeployTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.DeployToken, error) {
+	if m.GetByTokenHashFunc != nil {
+		return m.GetByTokenHashFunc(ctx, tokenHash)
+	}
+	return nil, nil
+}
+
+func (m *MockDeployTokenRepository) ListByProjectID(ctx context.Context, projectID uuid.UUID) ([]entity.DeployToken, error) {
+	if m.ListByProjectIDFunc != nil {
+		return m.ListByProjectIDFunc(ctx, projectID)
+	}
+	return nil, nil
+}
+
+func (m *MockDeployTokenRepository) Revoke(ctx context.Context, id uuid.UUID, revokedAt time.Time) error {
+	if m.RevokeFunc != nil {
+		return m.RevokeFunc(ctx, id, revokedAt)
+	}
+	return nil
+}
+
+func (m *MockDeployTokenRepository) UpdateLastUsed(ctx context.Context, id uuid.UUID, lastUsedAt time.Time) error {
+	if m.UpdateLastUsedFunc != nil {
+		return m.UpdateLastUsedFunc(ctx, id, lastUsedAt)
+	}
+	return nil
+}
+
 // MockServiceRepository is a mock implementation of ServiceRepository
 type MockServiceRepository struct {
 	CreateFunc                 func(ctx context.Context, service *entity.Service) error
@@ -292,10 +657,15 @@ type MockServiceRepository struct {
 	UpdateFunc                 func(ctx context.Context, service *entity.Service) error
 	DeleteFunc                 func(ctx context.Context, id uuid.UUID) error
 	ListByProjectIDFunc        func(ctx context.Context, projectID uuid.UUID) ([]entity.Service, error)
+	ListByDeployTypeFunc       func(ctx context.Context, deployType entity.DeployType) ([]entity.Service, error)
+	ListDeployedFunc           func(ctx context.Context) ([]entity.Service, error)
 	ExistsByProjectAndSlugFunc func(ctx context.Context, projectID uuid.UUID, slug string) (bool, error)
 	UpdateStatusFunc           func(ctx context.Context, id uuid.UUID, status entity.ServiceStatus) error
 	UpdateContainerIDFunc      func(ctx context.Context, id uuid.UUID, containerID *string) error
 	UpdateSwarmServiceIDFunc   func(ctx context.Context, id uuid.UUID, swarmServiceID *string) error
+	UpdateImageDigestFunc      func(ctx context.Context, id uuid.UUID, digest *string) error
+	UpdateImageCheckFunc       func(ctx context.Context, id uuid.UUID, latestDigest *string, updateAvailable bool, checkedAt time.Time) error
+	ListWithLegacyEnvVarsFunc  func(ctx context.Context) ([]entity.Service, error)
 }
 
 func (m *MockServiceRepository) Create(ctx context.Context, service *entity.Service) error {
@@ -340,6 +710,20 @@ func (m *MockServiceRepository) ListByProjectID(ctx context.Context, projectID u
 	return nil, nil
 }
 
+func (m *MockServiceRepository) ListByDeployType(ctx context.Context, deployType entity.DeployType) ([]entity.Service, error) {
+	if m.ListByDeployTypeFunc != nil {
+		return m.ListByDeployTypeFunc(ctx, deployType)
+	}
+	return nil, nil
+}
+
+func (m *MockServiceRepository) ListDeployed(ctx context.Context) ([]entity.Service, error) {
+	if m.ListDeployedFunc != nil {
+		return m.ListDeployedFunc(ctx)
+	}
+	return nil, nil
+}
+
 func (m *MockServiceRepository) ExistsByProjectAndSlug(ctx context.Context, projectID uuid.UUID, slug string) (bool, error) {
 	if m.ExistsByProjectAndSlugFunc != nil {
 		return m.ExistsByProjectAndSlugFunc(ctx, projectID, slug)
@@ -367,3 +751,476 @@ func (m *MockServiceRepository) UpdateSwarmServiceID(ctx context.Context, id uui
 	}
 	return nil
 }
+
+func (m *MockServiceRepository) UpdateImageDigest(ctx context.Context, id uuid.UUID, digest *string) error {
+	if m.UpdateImageDigestFunc != nil {
+		return m.UpdateImageDigestFunc(ctx, id, digest)
+	}
+	return nil
+}
+
+func (m *MockServiceRepository) UpdateImageCheck(ctx context.Context, id uuid.UUID, latestDigest *string, updateAvailable bool, checkedAt time.Time) error {
+	if m.UpdateImageCheckFunc != nil {
+		return m.UpdateImageCheckFunc(ctx, id, latestDigest, updateAvailable, checkedAt)
+	}
+	return nil
+}
+
+func (m *MockServiceRepository) ListWithLegacyEnvVars(ctx context.Context) ([]entity.Service, error) {
+	if m.ListWithLegacyEnvVarsFunc != nil {
+		return m.ListWithLegacyEnvVarsFunc(ctx)
+	}
+	return nil, nil
+}
+
+// MockServiceEnvVarRepository is a mock implementation of ServiceEnvVarRepository
+type MockServiceEnvVarRepository struct {
+	UpsertFunc             func(ctx context.Context, envVar *entity.ServiceEnvVar) error
+	GetByServiceAndKeyFunc func(ctx context.Context, serviceID uuid.UUID, key string) (*entity.ServiceEnvVar, error)
+	DeleteFunc             func(ctx context.Context, serviceID uuid.UUID, key string) error
+	ListByServiceIDFunc    func(ctx context.Context, serviceID uuid.UUID) ([]entity.ServiceEnvVar, error)
+	CreateChangeFunc       func(ctx context.Context, change *entity.ServiceEnvVarChange) error
+	ListChangesFunc        func(ctx context.Context, serviceID uuid.UUID, limit int) ([]entity.ServiceEnvVarChange, error)
+}
+
+func (m *MockServiceEnvVarRepository) Upsert(ctx context.Context, envVar *entity.ServiceEnvVar) error {
+	if m.UpsertFunc != nil {
+		return m.UpsertFunc(ctx, envVar)
+	}
+	return nil
+}
+
+func (m *MockServiceEnvVarRepository) GetByServiceAndKey(ctx context.Context, serviceID uuid.UUID, key string) (*entity.ServiceEnvVar, error) {
+	if m.GetByServiceAndKeyFunc != nil {
+		return m.GetByServiceAndKeyFunc(ctx, serviceID, key)
+	}
+	return nil, nil
+}
+
+func (m *MockServiceEnvVarRepository) Delete(ctx context.Context, serviceID uuid.UUID, key string) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, serviceID, key)
+	}
+	return nil
+}
+
+func (m *MockServiceEnvVarRepository) ListByServiceID(ctx context.Context, serviceID uuid.UUID) ([]entity.ServiceEnvVar, error) {
+	if m.ListByServiceIDFunc != nil {
+		return m.ListByServiceIDFunc(ctx, serviceID)
+	}
+	return nil, nil
+}
+
+func (m *MockServiceEnvVarRepository) CreateChange(ctx context.Context, change *entity.ServiceEnvVarChange) error {
+	if m.CreateChangeFunc != nil {
+		return m.CreateChangeFunc(ctx, change)
+	}
+	return nil
+}
+
+func (m *MockServiceEnvVarRepository) ListChanges(ctx context.Context, serviceID uuid.UUID, limit int) ([]entity.ServiceEnvVarChange, error) {
+	if m.ListChangesFunc != nil {
+		return m.ListChangesFunc(ctx, serviceID, limit)
+	}
+	return nil, nil
+}
+
+// MockDomainRepository is a mock implementation of DomainRepository
+type MockDomainRepository struct {
+	CreateFunc              func(ctx context.Context, domain *entity.Domain) error
+	GetByIDFunc             func(ctx context.Context, id uuid.UUID) (*entity.Domain, error)
+	GetByDomainFunc         func(ctx context.Context, domain string) (*entity.Domain, error)
+	DeleteFunc              func(ctx context.Context, id uuid.UUID) error
+	ListByServiceIDFunc     func(ctx context.Context, serviceID uuid.UUID) ([]entity.Domain, error)
+	ListByDomainsFunc       func(ctx context.Context, domains []string) ([]entity.Domain, error)
+	ListByCertificateIDFunc func(ctx context.Context, certificateID uuid.UUID) ([]entity.Domain, error)
+	ListByEntryPointFunc    func(ctx context.Context, entryPoint string) ([]entity.Domain, error)
+	ListAllFunc             func(ctx context.Context) ([]entity.Domain, error)
+	MarkVerifiedFunc        func(ctx context.Context, id uuid.UUID, verifiedAt time.Time) error
+	ExistsByDomainFunc      func(ctx context.Context, domain string) (bool, error)
+}
+
+func (m *MockDomainRepository) Create(ctx context.Context, domain *entity.Domain) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, domain)
+	}
+	return nil
+}
+
+func (m *MockDomainRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Domain, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockDomainRepository) GetByDomain(ctx context.Context, domain string) (*entity.Domain, error) {
+	if m.GetByDomainFunc != nil {
+		return m.GetByDomainFunc(ctx, domain)
+	}
+	return nil, nil
+}
+
+func (m *MockDomainRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *MockDomainRepository) ListByServiceID(ctx context.Context, serviceID uuid.UUID) ([]entity.Domain, error) {
+	if m.ListByServiceIDFunc != nil {
+		return m.ListByServiceIDFunc(ctx, serviceID)
+	}
+	return nil, nil
+}
+
+func (m *MockDomainRepository) ListByDomains(ctx context.Context, domains []string) ([]entity.Domain, error) {
+	if m.ListByDomainsFunc != nil {
+		return m.ListByDomainsFunc(ctx, domains)
+	}
+	return nil, nil
+}
+
+func (m *MockDomainRepository) ListByCertificateID(ctx context.Context, certificateID uuid.UUID) ([]entity.Domain, error) {
+	if m.ListByCertificateIDFunc != nil {
+		return m.ListByCertificateIDFunc(ctx, certificateID)
+	}
+	return nil, nil
+}
+
+func (m *MockDomainRepository) ListByEntryPoint(ctx context.Context, entryPoint string) ([]entity.Domain, error) {
+	if m.ListByEntryPointFunc != nil {
+		return m.ListByEntryPointFunc(ctx, entryPoint)
+	}
+	return nil, nil
+}
+
+func (m *MockDomainRepository) ListAll(ctx context.Context) ([]entity.Domain, error) {
+	if m.ListAllFunc != nil {
+		return m.ListAllFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *MockDomainRepository) MarkVerified(ctx context.Context, id uuid.UUID, verifiedAt time.Time) error {
+	if m.MarkVerifiedFunc != nil {
+		return m.MarkVerifiedFunc(ctx, id, verifiedAt)
+	}
+	return nil
+}
+
+func (m *MockDomainRepository) ExistsByDomain(ctx context.Context, domain string) (bool, error) {
+	if m.ExistsByDomainFunc != nil {
+		return m.ExistsByDomainFunc(ctx, domain)
+	}
+	return false, nil
+}
+
+// MockPortMappingRepository is a mock implementation of PortMappingRepository
+type MockPortMappingRepository struct {
+	CreateFunc            func(ctx context.Context, portMapping *entity.PortMapping) error
+	DeleteFunc            func(ctx context.Context, id uuid.UUID) error
+	ListByServiceIDFunc   func(ctx context.Context, serviceID uuid.UUID) ([]entity.PortMapping, error)
+	DeleteByServiceIDFunc func(ctx context.Context, serviceID uuid.UUID) error
+}
+
+func (m *MockPortMappingRepository) Create(ctx context.Context, portMapping *entity.PortMapping) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, portMapping)
+	}
+	return nil
+}
+
+func (m *MockPortMappingRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *MockPortMappingRepository) ListByServiceID(ctx context.Context, serviceID uuid.UUID) ([]entity.PortMapping, error) {
+	if m.ListByServiceIDFunc != nil {
+		return m.ListByServiceIDFunc(ctx, serviceID)
+	}
+	return nil, nil
+}
+
+func (m *MockPortMappingRepository) DeleteByServiceID(ctx context.Context, serviceID uuid.UUID) error {
+	if m.DeleteByServiceIDFunc != nil {
+		return m.DeleteByServiceIDFunc(ctx, serviceID)
+	}
+	return nil
+}
+
+// MockNetworkRepository is a mock implementation of NetworkRepository
+type MockNetworkRepository struct {
+	CreateFunc                func(ctx context.Context, network *entity.Network) error
+	GetByIDFunc               func(ctx context.Context, id uuid.UUID) (*entity.Network, error)
+	GetByNameFunc             func(ctx context.Context, name string) (*entity.Network, error)
+	GetByProjectAndNameFunc   func(ctx context.Context, projectID uuid.UUID, name string) (*entity.Network, error)
+	GetDefaultByProjectIDFunc func(ctx context.Context, projectID uuid.UUID) (*entity.Network, error)
+	UpdateFunc                func(ctx context.Context, network *entity.Network) error
+	DeleteFunc                func(ctx context.Context, id uuid.UUID) error
+	ListByProjectIDFunc       func(ctx context.Context, projectID uuid.UUID) ([]entity.Network, error)
+	ListByServiceIDFunc       func(ctx context.Context, serviceID uuid.UUID) ([]entity.Network, error)
+	ListAllFunc               func(ctx context.Context) ([]entity.Network, error)
+	AttachServiceFunc         func(ctx context.Context, networkID, serviceID uuid.UUID) error
+	DetachServiceFunc         func(ctx context.Context, networkID, serviceID uuid.UUID) error
+	ListServiceIDsFunc        func(ctx context.Context, networkID uuid.UUID) ([]uuid.UUID, error)
+}
+
+func (m *MockNetworkRepository) Create(ctx context.Context, network *entity.Network) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, network)
+	}
+	return nil
+}
+
+func (m *MockNetworkRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Network, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockNetworkRepository) GetByName(ctx context.Context, name string) (*entity.Network, error) {
+	if m.GetByNameFunc != nil {
+		return m.GetByNameFunc(ctx, name)
+	}
+	return nil, nil
+}
+
+func (m *MockNetworkRepository) GetByProjectAndName(ctx context.Context, projectID uuid.UUID, name string) (*entity.Network, error) {
+	if m.GetByProjectAndNameFunc != nil {
+		return m.GetByProjectAndNameFunc(ctx, projectID, name)
+	}
+	return nil, nil
+}
+
+func (m *MockNetworkRepository) GetDefaultByProjectID(ctx context.Context, projectID uuid.UUID) (*entity.Network, error) {
+	if m.GetDefaultByProjectIDFunc != nil {
+		return m.GetDefaultByProjectIDFunc(ctx, projectID)
+	}
+	return nil, nil
+}
+
+func (m *MockNetworkRepository) Update(ctx context.Context, network *entity.Network) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, network)
+	}
+	return nil
+}
+
+func (m *MockNetworkRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *MockNetworkRepository) ListByProjectID(ctx context.Context, projectID uuid.UUID) ([]entity.Network, error) {
+	if m.ListByProjectIDFunc != nil {
+		return m.ListByProjectIDFunc(ctx, projectID)
+	}
+	return nil, nil
+}
+
+func (m *MockNetworkRepository) ListByServiceID(ctx context.Context, serviceID uuid.UUID) ([]entity.Network, error) {
+	if m.ListByServiceIDFunc != nil {
+		return m.ListByServiceIDFunc(ctx, serviceID)
+	}
+	return nil, nil
+}
+
+func (m *MockNetworkRepository) ListAll(ctx context.Context) ([]entity.Network, error) {
+	if m.ListAllFunc != nil {
+		return m.ListAllFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *MockNetworkRepository) AttachService(ctx context.Context, networkID, serviceID uuid.UUID) error {
+	if m.AttachServiceFunc != nil {
+		return m.AttachServiceFunc(ctx, networkID, serviceID)
+	}
+	return nil
+}
+
+func (m *MockNetworkRepository) DetachService(ctx context.Context, networkID, serviceID uuid.UUID) error {
+	if m.DetachServiceFunc != nil {
+		return m.DetachServiceFunc(ctx, networkID, serviceID)
+	}
+	return nil
+}
+
+func (m *MockNetworkRepository) ListServiceIDs(ctx context.Context, networkID uuid.UUID) ([]uuid.UUID, error) {
+	if m.ListServiceIDsFunc != nil {
+		return m.ListServiceIDsFunc(ctx, networkID)
+	}
+	return nil, nil
+}
+
+// MockEnvGroupRepository is a mock implementation of EnvGroupRepository
+type MockEnvGroupRepository struct {
+	CreateFunc          func(ctx context.Context, group *entity.EnvGroup) error
+	GetByIDFunc         func(ctx context.Context, id uuid.UUID) (*entity.EnvGroup, error)
+	UpdateFunc          func(ctx context.Context, group *entity.EnvGroup) error
+	DeleteFunc          func(ctx context.Context, id uuid.UUID) error
+	ListByTeamIDFunc    func(ctx context.Context, teamID uuid.UUID) ([]entity.EnvGroup, error)
+	ListByProjectIDFunc func(ctx context.Context, projectID uuid.UUID) ([]entity.EnvGroup, error)
+	ListByServiceIDFunc func(ctx context.Context, serviceID uuid.UUID) ([]entity.EnvGroup, error)
+	AttachServiceFunc   func(ctx context.Context, groupID, serviceID uuid.UUID) error
+	DetachServiceFunc   func(ctx context.Context, groupID, serviceID uuid.UUID) error
+	ListServiceIDsFunc  func(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error)
+}
+
+func (m *MockEnvGroupRepository) Create(ctx context.Context, group *entity.EnvGroup) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, group)
+	}
+	return nil
+}
+
+func (m *MockEnvGroupRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.EnvGroup, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockEnvGroupRepository) Update(ctx context.Context, group *entity.EnvGroup) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, group)
+	}
+	return nil
+}
+
+func (m *MockEnvGroupRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *MockEnvGroupRepository) ListByTeamID(ctx context.Context, teamID uuid.UUID) ([]entity.EnvGroup, error) {
+	if m.ListByTeamIDFunc != nil {
+		return m.ListByTeamIDFunc(ctx, teamID)
+	}
+	return nil, nil
+}
+
+func (m *MockEnvGroupRepository) ListByProjectID(ctx context.Context, projectID uuid.UUID) ([]entity.EnvGroup, error) {
+	if m.ListByProjectIDFunc != nil {
+		return m.ListByProjectIDFunc(ctx, projectID)
+	}
+	return nil, nil
+}
+
+func (m *MockEnvGroupRepository) ListByServiceID(ctx context.Context, serviceID uuid.UUID) ([]entity.EnvGroup, error) {
+	if m.ListByServiceIDFunc != nil {
+		return m.ListByServiceIDFunc(ctx, serviceID)
+	}
+	return nil, nil
+}
+
+func (m *MockEnvGroupRepository) AttachService(ctx context.Context, groupID, serviceID uuid.UUID) error {
+	if m.AttachServiceFunc != nil {
+		return m.AttachServiceFunc(ctx, groupID, serviceID)
+	}
+	return nil
+}
+
+func (m *MockEnvGroupRepository) DetachService(ctx context.Context, groupID, serviceID uuid.UUID) error {
+	if m.DetachServiceFunc != nil {
+		return m.DetachServiceFunc(ctx, groupID, serviceID)
+	}
+	return nil
+}
+
+func (m *MockEnvGroupRepository) ListServiceIDs(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error) {
+	if m.ListServiceIDsFunc != nil {
+		return m.ListServiceIDsFunc(ctx, groupID)
+	}
+	return nil, nil
+}
+
+// MockCiphertextRepository is a mock implementation of CiphertextRepository
+type MockCiphertextRepository struct {
+	ListFunc    func(ctx context.Context, column entity.EncryptedColumn, after uuid.UUID, limit int) ([]entity.Ciphertext, error)
+	ReplaceFunc func(ctx context.Context, column entity.EncryptedColumn, id uuid.UUID, from, to []byte) (bool, error)
+}
+
+func (m *MockCiphertextRepository) List(ctx context.Context, column entity.EncryptedColumn, after uuid.UUID, limit int) ([]entity.Ciphertext, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, column, after, limit)
+	}
+	return nil, nil
+}
+
+func (m *MockCiphertextRepository) Replace(ctx context.Context, column entity.EncryptedColumn, id uuid.UUID, from, to []byte) (bool, error) {
+	if m.ReplaceFunc != nil {
+		return m.ReplaceFunc(ctx, column, id, from, to)
+	}
+	return true, nil
+}
+
+// MockDeploymentRepository is a mock implementation of DeploymentRepository
+type MockDeploymentRepository struct {
+	CreateFunc               func(ctx context.Context, deployment *entity.Deployment) error
+	GetByIDFunc              func(ctx context.Context, id uuid.UUID) (*entity.Deployment, error)
+	UpdateFunc               func(ctx context.Context, deployment *entity.Deployment) error
+	ListByServiceIDFunc      func(ctx context.Context, serviceID uuid.UUID, limit, offset int) ([]entity.Deployment, error)
+	GetLatestByServiceIDFunc func(ctx context.Context, serviceID uuid.UUID) (*entity.Deployment, error)
+	ListDueFunc              func(ctx context.Context, before time.Time) ([]entity.Deployment, error)
+	CancelScheduledFunc      func(ctx context.Context, serviceID uuid.UUID) error
+}
+
+func (m *MockDeploymentRepository) Create(ctx context.Context, deployment *entity.Deployment) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, deployment)
+	}
+	return nil
+}
+
+func (m *MockDeploymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Deployment, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockDeploymentRepository) Update(ctx context.Context, deployment *entity.Deployment) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, deployment)
+	}
+	return nil
+}
+
+func (m *MockDeploymentRepository) ListByServiceID(ctx context.Context, serviceID uuid.UUID, limit, offset int) ([]entity.Deployment, error) {
+	if m.ListByServiceIDFunc != nil {
+		return m.ListByServiceIDFunc(ctx, serviceID, limit, offset)
+	}
+	return nil, nil
+}
+
+func (m *MockDeploymentRepository) GetLatestByServiceID(ctx context.Context, serviceID uuid.UUID) (*entity.Deployment, error) {
+	if m.GetLatestByServiceIDFunc != nil {
+		return m.GetLatestByServiceIDFunc(ctx, serviceID)
+	}
+	return nil, nil
+}
+
+func (m *MockDeploymentRepository) ListDue(ctx context.Context, before time.Time) ([]entity.Deployment, error) {
+	if m.ListDueFunc != nil {
+		return m.ListDueFunc(ctx, before)
+	}
+	return nil, nil
+}
+
+func (m *MockDeploymentRepository) CancelScheduled(ctx context.Context, serviceID uuid.UUID) error {
+	if m.CancelScheduledFunc != nil {
+		return m.CancelScheduledFunc(ctx, serviceID)
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package mocks
+
+import "context"
+
+// MockSecretProvider is a mock implementation of secret.Provider
+type MockSecretProvider struct {
+	SchemeName  string
+	ResolveFunc func(ctx context.Context, ref string) (string, error)
+}
+
+func (m *MockSecretProvider) Scheme() string {
+	return m.SchemeName
+}
+
+func (m *MockSecretProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	if m.ResolveFunc != nil {
+		return m.ResolveFunc(ctx, ref)
+	}
+	return "", nil
+}
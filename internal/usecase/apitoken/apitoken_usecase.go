@@ -0,0 +1,142 @@
+package apitoken
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/domain/repository"
+	"github.com/podoru/spinner-podoru/pkg/crypto"
+)
+
+var (
+	ErrAPITokenNotFound = errors.New("API token not found")
+	ErrInvalidAPIToken  = errors.New("invalid, expired or revoked API token")
+	ErrProjectNotFound  = errors.New("project not found")
+	ErrNotTeamMember    = errors.New("not a team member")
+	ErrInvalidExpiry    = errors.New("expires_at must be in the future")
+	ErrTeamMismatch     = errors.New("project does not belong to the team")
+)
+
+type UseCase struct {
+	apiTokenRepo   repository.APITokenRepository
+	userRepo       repository.UserRepository
+	projectRepo    repository.ProjectRepository
+	teamMemberRepo repository.TeamMemberRepository
+}
+
+func NewUseCase(
+	apiTokenRepo repository.APITokenRepository,
+	userRepo repository.UserRepository,
+	projectRepo repository.ProjectRepository,
+	teamMemberRepo repository.TeamMemberRepository,
+) *UseCase {
+	return &UseCase{
+		apiTokenRepo:   apiTokenRepo,
+		userRepo:       userRepo,
+		projectRepo:    projectRepo,
+		teamMemberRepo: teamMemberRepo,
+	}
+}
+
+// List returns the user's tokens, revoked and expired ones included
+func (uc *UseCase) List(ctx context.Context, userID uuid.UUID) ([]entity.APIToken, error) {
+	return uc.apiTokenRepo.ListByUserID(ctx, userID)
+}
+
+// Create issues a token and returns it together with its only plaintext
+// copy; just its hash is stored. A token limited to a project is also
+// limited to the project's team.
+func (uc *UseCase) Create(ctx context.Context, userID uuid.UUID, input *entity.APITokenCreate) (*entity.APIToken, string, error) {
+	now := time.Now()
+	if !entity.ValidTokenExpiry(input.ExpiresAt, now) {
+		return nil, "", ErrInvalidExpiry
+	}
+
+	teamID := input.TeamID
+	if input.ProjectID != nil {
+		project, err := uc.projectRepo.GetByID(ctx, *input.ProjectID)
+		if err != nil {
+			return nil, "", err
+		}
+		if project == nil {
+			return nil, "", ErrProjectNotFound
+		}
+		if teamID != nil && *teamID != project.TeamID {
+			return nil, "", ErrTeamMismatch
+		}
+		teamID = &project.TeamID
+	}
+	if teamID != nil {
+		member, err := uc.teamMemberRepo.GetByTeamAndUser(ctx, *teamID, userID)
+		if err != nil {
+			return nil, "", err
+		}
+		if member == nil {
+			return nil, "", ErrNotTeamMember
+		}
+	}
+
+	plaintext, hash, err := crypto.GenerateToken(entity.APITokenPrefix)
+	if err != nil {
+		return nil, "", err
+	}
+
+	token := &entity.APIToken{
+		ID:         uuid.New(),
+		UserID:     userID,
+		Name:       input.Name,
+		TokenState: entity.NewTokenState(entity.APITokenPrefix, plaintext, hash, input.ExpiresAt),
+		Scopes:     input.Scopes,
+		TeamID:     teamID,
+		ProjectID:  input.ProjectID,
+		CreatedAt:  now,
+	}
+	if err := uc.apiTokenRepo.Create(ctx, token); err != nil {
+		return nil, "", err
+	}
+	return token, plaintext, nil
+}
+
+// Revoke stops one of the user's tokens from working
+func (uc *UseCase) Revoke(ctx context.Context, userID, tokenID uuid.UUID) error {
+	token, err := uc.apiTokenRepo.GetByID(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	if token == nil || token.UserID != userID {
+		return ErrAPITokenNotFound
+	}
+	return uc.apiTokenRepo.Revoke(ctx, tokenID, time.Now())
+}
+
+// Authenticate returns the active token and its user for a plaintext token,
+// recording that it was used
+func (uc *UseCase) Authenticate(ctx context.Context, plaintext string) (*entity.APIToken, *entity.User, error) {
+	token, err := uc.apiTokenRepo.GetByTokenHash(ctx, crypto.HashToken(plaintext))
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	if token == nil || !token.IsActive(now) {
+		return nil, nil, ErrInvalidAPIToken
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil || !user.IsActive {
+		return nil, nil, ErrInvalidAPIToken
+	}
+
+	if token.RecordUse(now) {
+		if err := uc.apiTokenRepo.UpdateLastUsed(ctx, token.ID, now); err != nil {
+			return nil, nil, err
+		}
+	}
+	return token, user, nil
+}
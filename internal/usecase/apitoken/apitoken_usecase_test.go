@@ -0,0 +1,314 @@
+package apitoken_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/mocks"
+	"github.com/podoru/spinner-podoru/internal/usecase/apitoken"
+	"github.com/podoru/spinner-podoru/pkg/crypto"
+)
+
+func TestCreate_Success(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	project := &entity.Project{ID: uuid.New(), TeamID: uuid.New()}
+
+	var stored *entity.APIToken
+	apiTokenRepo := &mocks.MockAPITokenRepository{
+		CreateFunc: func(ctx context.Context, token *entity.APIToken) error {
+			stored = token
+			return nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return project, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, uID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: uID, Role: entity.TeamRoleMember}, nil
+		},
+	}
+
+	uc := apitoken.NewUseCase(apiTokenRepo, &mocks.MockUserRepository{}, projectRepo, teamMemberRepo)
+
+	token, plaintext, err := uc.Create(ctx, userID, &entity.APITokenCreate{
+		Name:      "ci",
+		Scopes:    []entity.APITokenScope{entity.APITokenScopeDeploy},
+		ProjectID: &project.ID,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if stored == nil {
+		t.Fatal("expected token to be stored")
+	}
+	if !strings.HasPrefix(plaintext, entity.APITokenPrefix) {
+		t.Errorf("expected token to start with %s, got %q", entity.APITokenPrefix, plaintext)
+	}
+	if !strings.HasPrefix(plaintext, token.TokenPrefix) {
+		t.Errorf("expected prefix %q to start the token", token.TokenPrefix)
+	}
+	if token.TokenHash != crypto.HashToken(plaintext) {
+		t.Errorf("expected only the hash of the token to be stored")
+	}
+	if token.TeamID == nil || *token.TeamID != project.TeamID {
+		t.Errorf("expected project token to be limited to the project's team")
+	}
+}
+
+func TestCreate_Errors(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	teamID := uuid.New()
+	project := &entity.Project{ID: uuid.New(), TeamID: uuid.New()}
+	missingProject := uuid.New()
+
+	tests := []struct {
+		name    string
+		input   entity.APITokenCreate
+		wantErr error
+	}{
+		{name: "expired", input: entity.APITokenCreate{ExpiresAt: &past}, wantErr: apitoken.ErrInvalidExpiry},
+		{name: "not a team member", input: entity.APITokenCreate{TeamID: &teamID}, wantErr: apitoken.ErrNotTeamMember},
+		{name: "project not found", input: entity.APITokenCreate{ProjectID: &missingProject}, wantErr: apitoken.ErrProjectNotFound},
+		{name: "project of another team", input: entity.APITokenCreate{TeamID: &teamID, ProjectID: &project.ID}, wantErr: apitoken.ErrTeamMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiTokenRepo := &mocks.MockAPITokenRepository{
+				CreateFunc: func(ctx context.Context, token *entity.APIToken) error {
+					t.Errorf("expected no token to be stored")
+					return nil
+				},
+			}
+
+			projectRepo := &mocks.MockProjectRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+					if id == project.ID {
+						return project, nil
+					}
+					return nil, nil
+				},
+			}
+
+			teamMemberRepo := &mocks.MockTeamMemberRepository{
+				GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+					return nil, nil
+				},
+			}
+
+			uc := apitoken.NewUseCase(apiTokenRepo, &mocks.MockUserRepository{}, projectRepo, teamMemberRepo)
+
+			input := tt.input
+			input.Name = "ci"
+			input.Scopes = []entity.APITokenScope{entity.APITokenScopeRead}
+
+			_, _, err := uc.Create(context.Background(), uuid.New(), &input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestAuthenticate_Success(t *testing.T) {
+	ctx := context.Background()
+	plaintext := entity.APITokenPrefix + "secret"
+	user := &entity.User{ID: uuid.New(), IsActive: true}
+	token := &entity.APIToken{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		TokenState: entity.TokenState{TokenHash: crypto.HashToken(plaintext)},
+	}
+
+	var lastUsed *time.Time
+	apiTokenRepo := &mocks.MockAPITokenRepository{
+		GetByTokenHashFunc: func(ctx context.Context, tokenHash string) (*entity.APIToken, error) {
+			if tokenHash == token.TokenHash {
+				return token, nil
+			}
+			return nil, nil
+		},
+		UpdateLastUsedFunc: func(ctx context.Context, id uuid.UUID, lastUsedAt time.Time) error {
+			lastUsed = &lastUsedAt
+			return nil
+		},
+	}
+
+	userRepo := &mocks.MockUserRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+			if id == user.ID {
+				return user, nil
+			}
+			return nil, nil
+		},
+	}
+
+	uc := apitoken.NewUseCase(apiTokenRepo, userRepo, &mocks.MockProjectRepository{}, &mocks.MockTeamMemberRepository{})
+
+	result, resultUser, err := uc.Authenticate(ctx, plaintext)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.ID != token.ID {
+		t.Errorf("expected token %s, got %s", token.ID, result.ID)
+	}
+	if resultUser.ID != user.ID {
+		t.Errorf("expected user %s, got %s", user.ID, resultUser.ID)
+	}
+	if lastUsed == nil {
+		t.Errorf("expected last use to be recorded")
+	}
+}
+
+func TestAuthenticate_Invalid(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	active := &entity.User{ID: uuid.New(), IsActive: true}
+	inactive := &entity.User{ID: uuid.New()}
+
+	tests := []struct {
+		name  string
+		token *entity.APIToken
+		user  *entity.User
+	}{
+		{name: "unknown", user: active},
+		{name: "revoked", token: &entity.APIToken{TokenState: entity.TokenState{RevokedAt: &past}}, user: active},
+		{name: "expired", token: &entity.APIToken{TokenState: entity.TokenState{ExpiresAt: &past}}, user: active},
+		{name: "inactive user", token: &entity.APIToken{}, user: inactive},
+		{name: "deleted user", token: &entity.APIToken{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiTokenRepo := &mocks.MockAPITokenRepository{
+				GetByTokenHashFunc: func(ctx context.Context, tokenHash string) (*entity.APIToken, error) {
+					return tt.token, nil
+				},
+				UpdateLastUsedFunc: func(ctx context.Context, id uuid.UUID, lastUsedAt time.Time) error {
+					t.Errorf("expected an invalid token's use not to be recorded")
+					return nil
+				},
+			}
+
+			userRepo := &mocks.MockUserRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+					return tt.user, nil
+				},
+			}
+
+			uc := apitoken.NewUseCase(apiTokenRepo, userRepo, &mocks.MockProjectRepository{}, &mocks.MockTeamMemberRepository{})
+
+			_, _, err := uc.Authenticate(context.Background(), entity.APITokenPrefix+"secret")
+			if !errors.Is(err, apitoken.ErrInvalidAPIToken) {
+				t.Errorf("expected ErrInvalidAPIToken, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name        string
+		token       *entity.APIToken
+		wantErr     error
+		wantRevoked bool
+	}{
+		{name: "own token", token: &entity.APIToken{ID: uuid.New(), UserID: userID}, wantRevoked: true},
+		{name: "other user's token", token: &entity.APIToken{ID: uuid.New(), UserID: uuid.New()}, wantErr: apitoken.ErrAPITokenNotFound},
+		{name: "not found", wantErr: apitoken.ErrAPITokenNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revoked := false
+			apiTokenRepo := &mocks.MockAPITokenRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.APIToken, error) {
+					return tt.token, nil
+				},
+				RevokeFunc: func(ctx context.Context, id uuid.UUID, revokedAt time.Time) error {
+					revoked = true
+					return nil
+				},
+			}
+
+			uc := apitoken.NewUseCase(apiTokenRepo, &mocks.MockUserRepository{}, &mocks.MockProjectRepository{}, &mocks.MockTeamMemberRepository{})
+
+			var tokenID uuid.UUID
+			if tt.token != nil {
+				tokenID = tt.token.ID
+			}
+			err := uc.Revoke(context.Background(), userID, tokenID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if revoked != tt.wantRevoked {
+				t.Errorf("expected revoked %v, got %v", tt.wantRevoked, revoked)
+			}
+		})
+	}
+}
+
+func TestRestrictProjects(t *testing.T) {
+	allowed := &entity.Project{ID: uuid.New()}
+	other := &entity.Project{ID: uuid.New()}
+	projects := map[uuid.UUID]*entity.Project{allowed.ID: allowed, other.ID: other}
+
+	repo := apitoken.RestrictProjects(&mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return projects[id], nil
+		},
+		ListByTeamIDFunc: func(ctx context.Context, teamID uuid.UUID) ([]entity.Project, error) {
+			return []entity.Project{*allowed, *other}, nil
+		},
+	})
+
+	ctx := apitoken.WithToken(context.Background(), &entity.APIToken{ProjectID: &allowed.ID})
+
+	if project, _ := repo.GetByID(ctx, other.ID); project != nil {
+		t.Errorf("expected project outside the token to be hidden")
+	}
+	if project, _ := repo.GetByID(ctx, allowed.ID); project == nil {
+		t.Errorf("expected the token's project to be visible")
+	}
+	if list, _ := repo.ListByTeamID(ctx, uuid.New()); len(list) != 1 || list[0].ID != allowed.ID {
+		t.Errorf("expected only the token's project to be listed, got %+v", list)
+	}
+	if project, _ := repo.GetByID(context.Background(), other.ID); project == nil {
+		t.Errorf("expected every project to be visible without a token")
+	}
+}
+
+func TestRestrictTeamMembers(t *testing.T) {
+	userID := uuid.New()
+	allowed := uuid.New()
+
+	repo := apitoken.RestrictTeamMembers(&mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleOwner}, nil
+		},
+	})
+
+	ctx := apitoken.WithToken(context.Background(), &entity.APIToken{TeamID: &allowed})
+
+	if member, _ := repo.GetByTeamAndUser(ctx, uuid.New(), userID); member != nil {
+		t.Errorf("expected no membership outside the token's team")
+	}
+	if member, _ := repo.GetByTeamAndUser(ctx, allowed, userID); member == nil {
+		t.Errorf("expected membership in the token's team")
+	}
+}
@@ -0,0 +1,123 @@
+package apitoken
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/domain/repository"
+)
+
+type tokenKey struct{}
+
+// WithToken marks ctx as a request authenticated by token, so the
+// repositories wrapped below hide what lies outside its team or project
+func WithToken(ctx context.Context, token *entity.APIToken) context.Context {
+	return context.WithValue(ctx, tokenKey{}, token)
+}
+
+// TokenFrom returns the API token the request in ctx was authenticated by, if any
+func TokenFrom(ctx context.Context) *entity.APIToken {
+	token, _ := ctx.Value(tokenKey{}).(*entity.APIToken)
+	return token
+}
+
+// ProjectLimited reports whether the request in ctx was authenticated by a
+// token limited to one project. Such a token passes the team membership
+// checks of its project's team, so team-level operations refuse it.
+func ProjectLimited(ctx context.Context) bool {
+	token := TokenFrom(ctx)
+	return token != nil && token.ProjectID != nil
+}
+
+func outsideTeam(ctx context.Context, teamID uuid.UUID) bool {
+	token := TokenFrom(ctx)
+	return token != nil && token.TeamID != nil && *token.TeamID != teamID
+}
+
+func outsideProject(ctx context.Context, projectID uuid.UUID) bool {
+	token := TokenFrom(ctx)
+	return token != nil && token.ProjectID != nil && *token.ProjectID != projectID
+}
+
+// RestrictTeamMembers wraps repo so a user is not a member of the teams
+// outside the token in the context. Every use case checks membership
+// through it, which confines a team limited token.
+func RestrictTeamMembers(repo repository.TeamMemberRepository) repository.TeamMemberRepository {
+	return &restrictedTeamMembers{TeamMemberRepository: repo}
+}
+
+type restrictedTeamMembers struct {
+	repository.TeamMemberRepository
+}
+
+func (r *restrictedTeamMembers) GetByTeamAndUser(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+	if outsideTeam(ctx, teamID) {
+		return nil, nil
+	}
+	return r.TeamMemberRepository.GetByTeamAndUser(ctx, teamID, userID)
+}
+
+// RestrictTeams wraps repo so listing the user's teams leaves out those
+// outside the token in the context
+func RestrictTeams(repo repository.TeamRepository) repository.TeamRepository {
+	return &restrictedTeams{TeamRepository: repo}
+}
+
+type restrictedTeams struct {
+	repository.TeamRepository
+}
+
+func (r *restrictedTeams) ListByUserID(ctx context.Context, userID uuid.UUID) ([]entity.TeamWithRole, error) {
+	teams, err := r.TeamRepository.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	visible := teams[:0]
+	for _, t := range teams {
+		if !outsideTeam(ctx, t.ID) {
+			visible = append(visible, t)
+		}
+	}
+	return visible, nil
+}
+
+// RestrictProjects wraps repo so the projects outside the token in the
+// context do not exist, which confines a project limited token
+func RestrictProjects(repo repository.ProjectRepository) repository.ProjectRepository {
+	return &restrictedProjects{ProjectRepository: repo}
+}
+
+type restrictedProjects struct {
+	repository.ProjectRepository
+}
+
+func (r *restrictedProjects) GetByID(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+	if outsideProject(ctx, id) {
+		return nil, nil
+	}
+	return r.ProjectRepository.GetByID(ctx, id)
+}
+
+func (r *restrictedProjects) GetByTeamAndSlug(ctx context.Context, teamID uuid.UUID, slug string) (*entity.Project, error) {
+	project, err := r.ProjectRepository.GetByTeamAndSlug(ctx, teamID, slug)
+	if err != nil || project == nil || outsideProject(ctx, project.ID) {
+		return nil, err
+	}
+	return project, nil
+}
+
+func (r *restrictedProjects) ListByTeamID(ctx context.Context, teamID uuid.UUID) ([]entity.Project, error) {
+	projects, err := r.ProjectRepository.ListByTeamID(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	visible := projects[:0]
+	for _, p := range projects {
+		if !outsideProject(ctx, p.ID) {
+			visible = append(visible, p)
+		}
+	}
+	return visible, nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/podoru/spinner-podoru/internal/domain/entity"
 	"github.com/podoru/spinner-podoru/internal/domain/repository"
 	"github.com/podoru/spinner-podoru/internal/infrastructure/config"
+	"github.com/podoru/spinner-podoru/internal/usecase/mfa"
 	"github.com/podoru/spinner-podoru/pkg/crypto"
 )
 
@@ -19,9 +20,11 @@ var (
 	ErrEmailAlreadyExists    = errors.New("email already exists")
 	ErrInvalidToken          = errors.New("invalid token")
 	ErrTokenExpired          = errors.New("token expired")
+	ErrTokenReused           = errors.New("refresh token was already used, the session has been revoked")
 	ErrUserNotFound          = errors.New("user not found")
 	ErrUserInactive          = errors.New("user account is inactive")
 	ErrRegistrationDisabled  = errors.New("registration is disabled")
+	ErrPasswordLoginDisabled = errors.New("password login is disabled, sign in with single sign-on")
 )
 
 type UseCase struct {
@@ -31,6 +34,8 @@ type UseCase struct {
 	teamMemberRepo   repository.TeamMemberRepository
 	jwtConfig        *config.JWTConfig
 	appConfig        *config.AppConfig
+	// mfaUseCase asks for a second factor at login; nil turns that off
+	mfaUseCase *mfa.UseCase
 }
 
 func NewUseCase(
@@ -40,6 +45,7 @@ func NewUseCase(
 	teamMemberRepo repository.TeamMemberRepository,
 	jwtConfig *config.JWTConfig,
 	appConfig *config.AppConfig,
+	mfaUseCase *mfa.UseCase,
 ) *UseCase {
 	return &UseCase{
 		userRepo:         userRepo,
@@ -48,10 +54,15 @@ func NewUseCase(
 		teamMemberRepo:   teamMemberRepo,
 		jwtConfig:        jwtConfig,
 		appConfig:        appConfig,
+		mfaUseCase:       mfaUseCase,
 	}
 }
 
 func (uc *UseCase) Register(ctx context.Context, input *entity.UserCreate) (*entity.User, *entity.TokenPair, error) {
+	if uc.appConfig.PasswordLoginDisabled {
+		return nil, nil, ErrPasswordLoginDisabled
+	}
+
 	userCount, err := uc.userRepo.Count(ctx)
 	if err != nil {
 		return nil, nil, err
@@ -131,21 +142,48 @@ func (uc *UseCase) Register(ctx context.Context, input *entity.UserCreate) (*ent
 	return user, tokens, nil
 }
 
-func (uc *UseCase) Login(ctx context.Context, input *entity.LoginRequest) (*entity.User, *entity.TokenPair, error) {
+// Login checks the user's password. Users with two-factor authentication,
+// or whom the policy requires to set it up, get a pending login to finish
+// with VerifyMFA instead of tokens.
+func (uc *UseCase) Login(ctx context.Context, input *entity.LoginRequest) (*entity.User, *entity.TokenPair, *entity.MFAPendingLogin, error) {
+	if uc.appConfig.PasswordLoginDisabled {
+		return nil, nil, nil, ErrPasswordLoginDisabled
+	}
+
 	user, err := uc.userRepo.GetByEmail(ctx, input.Email)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	if user == nil {
-		return nil, nil, ErrInvalidCredentials
+		return nil, nil, nil, ErrInvalidCredentials
 	}
 
 	if !user.IsActive {
-		return nil, nil, ErrUserInactive
+		return nil, nil, nil, ErrUserInactive
 	}
 
 	if !crypto.CheckPassword(input.Password, user.PasswordHash) {
-		return nil, nil, ErrInvalidCredentials
+		return nil, nil, nil, ErrInvalidCredentials
+	}
+
+	return uc.completeLogin(ctx, user)
+}
+
+// VerifyMFA finishes a pending login with a code from the user's
+// authenticator or one of their recovery codes
+func (uc *UseCase) VerifyMFA(ctx context.Context, mfaToken, code, recoveryCode string) (*entity.User, *entity.TokenPair, error) {
+	if uc.mfaUseCase == nil {
+		return nil, nil, mfa.ErrInvalidChallenge
+	}
+
+	userID, err := uc.mfaUseCase.CompleteLogin(ctx, mfaToken, code, recoveryCode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := uc.activeUser(ctx, userID)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	tokens, err := uc.generateTokenPair(ctx, user)
@@ -156,6 +194,65 @@ func (uc *UseCase) Login(ctx context.Context, input *entity.LoginRequest) (*enti
 	return user, tokens, nil
 }
 
+// ResetMFA removes the authenticator of a user who lost it, given a pending
+// login and a recovery code. If the policy requires two-factor, the user
+// gets a new pending login to enrol again rather than tokens.
+func (uc *UseCase) ResetMFA(ctx context.Context, mfaToken, recoveryCode string) (*entity.User, *entity.TokenPair, *entity.MFAPendingLogin, error) {
+	if uc.mfaUseCase == nil {
+		return nil, nil, nil, mfa.ErrInvalidChallenge
+	}
+
+	userID, err := uc.mfaUseCase.ResetWithRecoveryCode(ctx, mfaToken, recoveryCode)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	user, err := uc.activeUser(ctx, userID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return uc.completeLogin(ctx, user)
+}
+
+// completeLogin issues tokens to a user who proved their password, unless
+// a second factor is needed
+func (uc *UseCase) completeLogin(ctx context.Context, user *entity.User) (*entity.User, *entity.TokenPair, *entity.MFAPendingLogin, error) {
+	if uc.mfaUseCase != nil {
+		pending, err := uc.mfaUseCase.BeginLogin(ctx, user)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if pending != nil {
+			return user, nil, pending, nil
+		}
+	}
+
+	tokens, err := uc.generateTokenPair(ctx, user)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return user, tokens, nil, nil
+}
+
+func (uc *UseCase) activeUser(ctx context.Context, userID uuid.UUID) (*entity.User, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+	return user, nil
+}
+
+// RefreshToken replaces a refresh token with the next one of its session.
+// A refresh token works once: presenting it again means it was copied, so
+// the whole session is revoked.
 func (uc *UseCase) RefreshToken(ctx context.Context, refreshToken string) (*entity.TokenPair, error) {
 	tokenHash := crypto.HashToken(refreshToken)
 
@@ -167,8 +264,15 @@ func (uc *UseCase) RefreshToken(ctx context.Context, refreshToken string) (*enti
 		return nil, ErrInvalidToken
 	}
 
+	if storedToken.IsUsed() {
+		if err := uc.refreshTokenRepo.DeleteSession(ctx, storedToken.SessionID); err != nil {
+			return nil, err
+		}
+		return nil, ErrTokenReused
+	}
+
 	if time.Now().After(storedToken.ExpiresAt) {
-		uc.refreshTokenRepo.DeleteByTokenHash(ctx, tokenHash)
+		uc.refreshTokenRepo.DeleteSession(ctx, storedToken.SessionID)
 		return nil, ErrTokenExpired
 	}
 
@@ -184,16 +288,38 @@ func (uc *UseCase) RefreshToken(ctx context.Context, refreshToken string) (*enti
 		return nil, ErrUserInactive
 	}
 
-	if err := uc.refreshTokenRepo.DeleteByTokenHash(ctx, tokenHash); err != nil {
+	next, tokens, err := uc.newTokenPair(user, storedToken.SessionID)
+	if err != nil {
 		return nil, err
 	}
 
-	return uc.generateTokenPair(ctx, user)
+	rotated, err := uc.refreshTokenRepo.Rotate(ctx, storedToken, next, clientFrom(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// Another request refreshed with the same token first
+		if err := uc.refreshTokenRepo.DeleteSession(ctx, storedToken.SessionID); err != nil {
+			return nil, err
+		}
+		return nil, ErrTokenReused
+	}
+
+	return tokens, nil
 }
 
+// Logout ends the session of a refresh token
 func (uc *UseCase) Logout(ctx context.Context, refreshToken string) error {
 	tokenHash := crypto.HashToken(refreshToken)
-	return uc.refreshTokenRepo.DeleteByTokenHash(ctx, tokenHash)
+
+	storedToken, err := uc.refreshTokenRepo.GetByTokenHash(ctx, tokenHash)
+	if err != nil {
+		return err
+	}
+	if storedToken == nil {
+		return nil
+	}
+	return uc.refreshTokenRepo.DeleteSession(ctx, storedToken.SessionID)
 }
 
 func (uc *UseCase) ValidateAccessToken(tokenString string) (*entity.JWTClaims, error) {
@@ -229,39 +355,76 @@ func (uc *UseCase) ValidateAccessToken(tokenString string) (*entity.JWTClaims, e
 
 	email, _ := claims["email"].(string)
 
+	// Tokens issued before sessions have no session ID
+	sessionIDStr, _ := claims["sid"].(string)
+	sessionID, _ := uuid.Parse(sessionIDStr)
+
 	return &entity.JWTClaims{
-		UserID: userID,
-		Email:  email,
+		UserID:    userID,
+		Email:     email,
+		SessionID: sessionID,
 	}, nil
 }
 
+// IssueTokens starts a session for a user who signed in another way, such
+// as single sign-on
+func (uc *UseCase) IssueTokens(ctx context.Context, user *entity.User) (*entity.TokenPair, error) {
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+	return uc.generateTokenPair(ctx, user)
+}
+
+// generateTokenPair starts a new session for the user
 func (uc *UseCase) generateTokenPair(ctx context.Context, user *entity.User) (*entity.TokenPair, error) {
-	accessToken, err := uc.generateAccessToken(user)
+	sessionID := uuid.New()
+	token, tokens, err := uc.newTokenPair(user, sessionID)
 	if err != nil {
 		return nil, err
 	}
 
-	refreshToken, err := crypto.GenerateRandomString(64)
-	if err != nil {
+	client := clientFrom(ctx)
+	session := &entity.Session{
+		ID:         sessionID,
+		UserID:     user.ID,
+		UserAgent:  client.UserAgent,
+		IPAddress:  client.IPAddress,
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.CreatedAt,
+		CreatedAt:  token.CreatedAt,
+	}
+
+	if err := uc.refreshTokenRepo.CreateSession(ctx, session, token); err != nil {
 		return nil, err
 	}
 
-	tokenHash := crypto.HashToken(refreshToken)
-	expiresAt := time.Now().Add(uc.jwtConfig.RefreshExpiry)
+	return tokens, nil
+}
 
+// newTokenPair creates an access token and the next refresh token of a
+// session, which the caller stores
+func (uc *UseCase) newTokenPair(user *entity.User, sessionID uuid.UUID) (*entity.RefreshToken, *entity.TokenPair, error) {
+	accessToken, err := uc.generateAccessToken(user, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refreshToken, err := crypto.GenerateRandomString(64)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
 	refreshTokenEntity := &entity.RefreshToken{
 		ID:        uuid.New(),
 		UserID:    user.ID,
-		TokenHash: tokenHash,
-		ExpiresAt: expiresAt,
-		CreatedAt: time.Now(),
-	}
-
-	if err := uc.refreshTokenRepo.Create(ctx, refreshTokenEntity); err != nil {
-		return nil, err
+		SessionID: sessionID,
+		TokenHash: crypto.HashToken(refreshToken),
+		ExpiresAt: now.Add(uc.jwtConfig.RefreshExpiry),
+		CreatedAt: now,
 	}
 
-	return &entity.TokenPair{
+	return refreshTokenEntity, &entity.TokenPair{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 		ExpiresIn:    int64(uc.jwtConfig.AccessExpiry.Seconds()),
@@ -269,10 +432,11 @@ func (uc *UseCase) generateTokenPair(ctx context.Context, user *entity.User) (*e
 	}, nil
 }
 
-func (uc *UseCase) generateAccessToken(user *entity.User) (string, error) {
+func (uc *UseCase) generateAccessToken(user *entity.User, sessionID uuid.UUID) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id": user.ID.String(),
 		"email":   user.Email,
+		"sid":     sessionID.String(),
 		"exp":     time.Now().Add(uc.jwtConfig.AccessExpiry).Unix(),
 		"iat":     time.Now().Unix(),
 	}
@@ -2,6 +2,7 @@ package auth_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/podoru/spinner-podoru/internal/infrastructure/config"
 	"github.com/podoru/spinner-podoru/internal/mocks"
 	"github.com/podoru/spinner-podoru/internal/usecase/auth"
+	"github.com/podoru/spinner-podoru/internal/usecase/mfa"
 	"github.com/podoru/spinner-podoru/pkg/crypto"
 )
 
@@ -33,7 +35,7 @@ func TestRegister_FirstUser_SuperAdmin(t *testing.T) {
 	}
 
 	refreshTokenRepo := &mocks.MockRefreshTokenRepository{
-		CreateFunc: func(ctx context.Context, token *entity.RefreshToken) error {
+		CreateSessionFunc: func(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error {
 			return nil
 		},
 	}
@@ -60,7 +62,7 @@ func TestRegister_FirstUser_SuperAdmin(t *testing.T) {
 		RegistrationEnabled: false,
 	}
 
-	uc := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig)
+	uc := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig, nil)
 
 	input := &entity.UserCreate{
 		Email:    "admin@example.com",
@@ -109,7 +111,7 @@ func TestRegister_SecondUser_RegistrationDisabled(t *testing.T) {
 		RegistrationEnabled: false,
 	}
 
-	uc := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig)
+	uc := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig, nil)
 
 	input := &entity.UserCreate{
 		Email:    "user@example.com",
@@ -142,7 +144,7 @@ func TestRegister_SecondUser_RegistrationEnabled(t *testing.T) {
 	}
 
 	refreshTokenRepo := &mocks.MockRefreshTokenRepository{
-		CreateFunc: func(ctx context.Context, token *entity.RefreshToken) error {
+		CreateSessionFunc: func(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error {
 			return nil
 		},
 	}
@@ -169,7 +171,7 @@ func TestRegister_SecondUser_RegistrationEnabled(t *testing.T) {
 		RegistrationEnabled: true,
 	}
 
-	uc := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig)
+	uc := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig, nil)
 
 	input := &entity.UserCreate{
 		Email:    "user@example.com",
@@ -221,7 +223,7 @@ func TestRegister_EmailAlreadyExists(t *testing.T) {
 		RegistrationEnabled: true,
 	}
 
-	uc := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig)
+	uc := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig, nil)
 
 	input := &entity.UserCreate{
 		Email:    "existing@example.com",
@@ -258,7 +260,7 @@ func TestLogin_Success(t *testing.T) {
 	}
 
 	refreshTokenRepo := &mocks.MockRefreshTokenRepository{
-		CreateFunc: func(ctx context.Context, token *entity.RefreshToken) error {
+		CreateSessionFunc: func(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error {
 			return nil
 		},
 	}
@@ -274,14 +276,14 @@ func TestLogin_Success(t *testing.T) {
 
 	appConfig := &config.AppConfig{}
 
-	uc := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig)
+	uc := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig, nil)
 
 	input := &entity.LoginRequest{
 		Email:    "test@example.com",
 		Password: "password123",
 	}
 
-	user, tokens, err := uc.Login(ctx, input)
+	user, tokens, _, err := uc.Login(ctx, input)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -327,14 +329,14 @@ func TestLogin_InvalidCredentials(t *testing.T) {
 
 	appConfig := &config.AppConfig{}
 
-	uc := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig)
+	uc := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig, nil)
 
 	input := &entity.LoginRequest{
 		Email:    "test@example.com",
 		Password: "wrongpassword",
 	}
 
-	_, _, err := uc.Login(ctx, input)
+	_, _, _, err := uc.Login(ctx, input)
 	if err != auth.ErrInvalidCredentials {
 		t.Errorf("expected ErrInvalidCredentials, got %v", err)
 	}
@@ -361,14 +363,14 @@ func TestLogin_UserNotFound(t *testing.T) {
 
 	appConfig := &config.AppConfig{}
 
-	uc := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig)
+	uc := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig, nil)
 
 	input := &entity.LoginRequest{
 		Email:    "nonexistent@example.com",
 		Password: "password123",
 	}
 
-	_, _, err := uc.Login(ctx, input)
+	_, _, _, err := uc.Login(ctx, input)
 	if err != auth.ErrInvalidCredentials {
 		t.Errorf("expected ErrInvalidCredentials, got %v", err)
 	}
@@ -406,19 +408,144 @@ func TestLogin_UserInactive(t *testing.T) {
 
 	appConfig := &config.AppConfig{}
 
-	uc := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig)
+	uc := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig, nil)
 
 	input := &entity.LoginRequest{
 		Email:    "test@example.com",
 		Password: "password123",
 	}
 
-	_, _, err := uc.Login(ctx, input)
+	_, _, _, err := uc.Login(ctx, input)
 	if err != auth.ErrUserInactive {
 		t.Errorf("expected ErrUserInactive, got %v", err)
 	}
 }
 
+func TestLogin_PasswordLoginDisabled(t *testing.T) {
+	ctx := context.Background()
+
+	passwordHash, _ := crypto.HashPassword("password123")
+
+	testUser := &entity.User{
+		ID:           uuid.New(),
+		Email:        "test@example.com",
+		PasswordHash: passwordHash,
+		Name:         "Test User",
+		Role:         entity.UserRoleSuperAdmin,
+		IsActive:     true,
+	}
+
+	userRepo := &mocks.MockUserRepository{
+		GetByEmailFunc: func(ctx context.Context, email string) (*entity.User, error) {
+			return testUser, nil
+		},
+	}
+
+	refreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+	teamRepo := &mocks.MockTeamRepository{}
+	teamMemberRepo := &mocks.MockTeamMemberRepository{}
+
+	jwtConfig := &config.JWTConfig{
+		Secret:        "test-secret",
+		AccessExpiry:  15 * time.Minute,
+		RefreshExpiry: 7 * 24 * time.Hour,
+	}
+
+	appConfig := &config.AppConfig{
+		RegistrationEnabled:   true,
+		PasswordLoginDisabled: true,
+	}
+
+	uc := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig, nil)
+
+	_, _, _, err := uc.Login(ctx, &entity.LoginRequest{
+		Email:    "test@example.com",
+		Password: "password123",
+	})
+	if err != auth.ErrPasswordLoginDisabled {
+		t.Errorf("expected ErrPasswordLoginDisabled from login, got %v", err)
+	}
+
+	_, _, err = uc.Register(ctx, &entity.UserCreate{
+		Email:    "new@example.com",
+		Password: "password123",
+		Name:     "New User",
+	})
+	if err != auth.ErrPasswordLoginDisabled {
+		t.Errorf("expected ErrPasswordLoginDisabled from register, got %v", err)
+	}
+}
+
+func TestLogin_MFARequired(t *testing.T) {
+	ctx := context.Background()
+
+	passwordHash, _ := crypto.HashPassword("password123")
+
+	testUser := &entity.User{
+		ID:           uuid.New(),
+		Email:        "test@example.com",
+		PasswordHash: passwordHash,
+		Name:         "Test User",
+		Role:         entity.UserRoleUser,
+		IsActive:     true,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+
+	userRepo := &mocks.MockUserRepository{
+		GetByEmailFunc: func(ctx context.Context, email string) (*entity.User, error) {
+			return testUser, nil
+		},
+	}
+
+	refreshTokenCreated := false
+	refreshTokenRepo := &mocks.MockRefreshTokenRepository{
+		CreateSessionFunc: func(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error {
+			refreshTokenCreated = true
+			return nil
+		},
+	}
+
+	teamRepo := &mocks.MockTeamRepository{}
+	teamMemberRepo := &mocks.MockTeamMemberRepository{}
+
+	policyRepo := &mocks.MockMFAPolicyRepository{
+		GetFunc: func(ctx context.Context) (*entity.MFAPolicy, error) {
+			return &entity.MFAPolicy{Required: entity.MFARequiredAll}, nil
+		},
+	}
+	encryptor, _ := crypto.NewEncryptor("test-key")
+	mfaUseCase := mfa.NewUseCase(&mocks.MockMFARepository{}, &mocks.MockMFAChallengeRepository{}, policyRepo, userRepo, teamRepo, encryptor, "Podoru")
+
+	jwtConfig := &config.JWTConfig{
+		Secret:        "test-secret",
+		AccessExpiry:  15 * time.Minute,
+		RefreshExpiry: 7 * 24 * time.Hour,
+	}
+
+	appConfig := &config.AppConfig{}
+
+	uc := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig, mfaUseCase)
+
+	input := &entity.LoginRequest{
+		Email:    "test@example.com",
+		Password: "password123",
+	}
+
+	_, tokens, pending, err := uc.Login(ctx, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if tokens != nil || refreshTokenCreated {
+		t.Error("expected no tokens before the second factor")
+	}
+
+	if pending == nil || pending.Token == "" || !pending.EnrollmentRequired {
+		t.Fatalf("expected a pending login requiring enrolment, got %+v", pending)
+	}
+}
+
 func TestValidateAccessToken_Success(t *testing.T) {
 	userRepo := &mocks.MockUserRepository{}
 	refreshTokenRepo := &mocks.MockRefreshTokenRepository{}
@@ -433,7 +560,7 @@ func TestValidateAccessToken_Success(t *testing.T) {
 
 	appConfig := &config.AppConfig{}
 
-	uc := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig)
+	uc := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig, nil)
 
 	testUser := &entity.User{
 		ID:    uuid.New(),
@@ -450,11 +577,13 @@ func TestValidateAccessToken_Success(t *testing.T) {
 	userRepo.GetByEmailFunc = func(ctx context.Context, email string) (*entity.User, error) {
 		return testUser, nil
 	}
-	refreshTokenRepo.CreateFunc = func(ctx context.Context, token *entity.RefreshToken) error {
+	var sessionID uuid.UUID
+	refreshTokenRepo.CreateSessionFunc = func(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error {
+		sessionID = session.ID
 		return nil
 	}
 
-	_, tokens, err := uc.Login(ctx, &entity.LoginRequest{
+	_, tokens, _, err := uc.Login(ctx, &entity.LoginRequest{
 		Email:    "test@example.com",
 		Password: "password123",
 	})
@@ -474,6 +603,10 @@ func TestValidateAccessToken_Success(t *testing.T) {
 	if claims.Email != testUser.Email {
 		t.Errorf("expected email %s, got %s", testUser.Email, claims.Email)
 	}
+
+	if claims.SessionID != sessionID {
+		t.Errorf("expected session ID %s, got %s", sessionID, claims.SessionID)
+	}
 }
 
 func TestValidateAccessToken_InvalidToken(t *testing.T) {
@@ -490,10 +623,370 @@ func TestValidateAccessToken_InvalidToken(t *testing.T) {
 
 	appConfig := &config.AppConfig{}
 
-	uc := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig)
+	uc := auth.NewUseCase(userRepo, refreshTokenRepo, teamRepo, teamMemberRepo, jwtConfig, appConfig, nil)
 
 	_, err := uc.ValidateAccessToken("invalid-token")
 	if err != auth.ErrInvalidToken {
 		t.Errorf("expected ErrInvalidToken, got %v", err)
 	}
 }
+
+func TestLogin_StartsSession(t *testing.T) {
+	passwordHash, _ := crypto.HashPassword("password123")
+	testUser := &entity.User{ID: uuid.New(), Email: "test@example.com", PasswordHash: passwordHash, Role: entity.UserRoleUser, IsActive: true}
+
+	userRepo := &mocks.MockUserRepository{
+		GetByEmailFunc: func(ctx context.Context, email string) (*entity.User, error) {
+			return testUser, nil
+		},
+	}
+
+	var sessions []*entity.Session
+	refreshTokenRepo := &mocks.MockRefreshTokenRepository{
+		CreateSessionFunc: func(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error {
+			sessions = append(sessions, session)
+			return nil
+		},
+	}
+
+	jwtConfig := &config.JWTConfig{
+		Secret:        "test-secret",
+		AccessExpiry:  15 * time.Minute,
+		RefreshExpiry: 7 * 24 * time.Hour,
+	}
+
+	uc := auth.NewUseCase(userRepo, refreshTokenRepo, &mocks.MockTeamRepository{}, &mocks.MockTeamMemberRepository{}, jwtConfig, &config.AppConfig{}, nil)
+
+	ctx := auth.WithClient(context.Background(), entity.SessionClient{UserAgent: "curl/8.5.0", IPAddress: "203.0.113.7"})
+	if _, _, _, err := uc.Login(ctx, &entity.LoginRequest{Email: "test@example.com", Password: "password123"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(sessions) != 1 {
+		t.Fatalf("expected 1 session, got %d", len(sessions))
+	}
+	if sessions[0].UserAgent != "curl/8.5.0" || sessions[0].IPAddress != "203.0.113.7" {
+		t.Errorf("expected the session to record the client, got %+v", sessions[0])
+	}
+}
+
+func TestRefreshToken_Rotates(t *testing.T) {
+	passwordHash, _ := crypto.HashPassword("password123")
+	testUser := &entity.User{ID: uuid.New(), Email: "test@example.com", PasswordHash: passwordHash, Role: entity.UserRoleUser, IsActive: true}
+
+	userRepo := &mocks.MockUserRepository{
+		GetByEmailFunc: func(ctx context.Context, email string) (*entity.User, error) {
+			return testUser, nil
+		},
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+			return testUser, nil
+		},
+	}
+
+	var session *entity.Session
+	tokens := make(map[string]*entity.RefreshToken)
+	refreshTokenRepo := &mocks.MockRefreshTokenRepository{
+		CreateSessionFunc: func(ctx context.Context, s *entity.Session, token *entity.RefreshToken) error {
+			session = s
+			tokens[token.TokenHash] = token
+			return nil
+		},
+		GetByTokenHashFunc: func(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+			token, ok := tokens[tokenHash]
+			if !ok {
+				return nil, nil
+			}
+			copied := *token
+			return &copied, nil
+		},
+		RotateFunc: func(ctx context.Context, used, next *entity.RefreshToken, client entity.SessionClient) (bool, error) {
+			stored := tokens[used.TokenHash]
+			if stored == nil || stored.IsUsed() {
+				return false, nil
+			}
+			stored.UsedAt = &next.CreatedAt
+			tokens[next.TokenHash] = next
+			session.UserAgent = client.UserAgent
+			session.IPAddress = client.IPAddress
+			return true, nil
+		},
+		DeleteSessionFunc: func(ctx context.Context, id uuid.UUID) error {
+			t.Errorf("expected the session to be kept")
+			return nil
+		},
+	}
+
+	jwtConfig := &config.JWTConfig{
+		Secret:        "test-secret",
+		AccessExpiry:  15 * time.Minute,
+		RefreshExpiry: 7 * 24 * time.Hour,
+	}
+
+	uc := auth.NewUseCase(userRepo, refreshTokenRepo, &mocks.MockTeamRepository{}, &mocks.MockTeamMemberRepository{}, jwtConfig, &config.AppConfig{}, nil)
+
+	_, pair, _, err := uc.Login(context.Background(), &entity.LoginRequest{Email: "test@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+
+	ctx := auth.WithClient(context.Background(), entity.SessionClient{UserAgent: "podoru-cli/1.0", IPAddress: "198.51.100.2"})
+	refreshed, err := uc.RefreshToken(ctx, pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if refreshed.RefreshToken == pair.RefreshToken {
+		t.Error("expected a new refresh token")
+	}
+
+	if _, err := uc.RefreshToken(ctx, refreshed.RefreshToken); err != nil {
+		t.Fatalf("refreshing with the new token failed: %v", err)
+	}
+
+	if session.UserAgent != "podoru-cli/1.0" {
+		t.Errorf("expected the session to record the last client, got %q", session.UserAgent)
+	}
+}
+
+func TestRefreshToken_ReuseRevokesSession(t *testing.T) {
+	ctx := context.Background()
+	passwordHash, _ := crypto.HashPassword("password123")
+	testUser := &entity.User{ID: uuid.New(), Email: "test@example.com", PasswordHash: passwordHash, Role: entity.UserRoleUser, IsActive: true}
+
+	userRepo := &mocks.MockUserRepository{
+		GetByEmailFunc: func(ctx context.Context, email string) (*entity.User, error) {
+			return testUser, nil
+		},
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+			return testUser, nil
+		},
+	}
+
+	tokens := make(map[string]*entity.RefreshToken)
+	refreshTokenRepo := &mocks.MockRefreshTokenRepository{
+		CreateSessionFunc: func(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error {
+			tokens[token.TokenHash] = token
+			return nil
+		},
+		GetByTokenHashFunc: func(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+			token, ok := tokens[tokenHash]
+			if !ok {
+				return nil, nil
+			}
+			copied := *token
+			return &copied, nil
+		},
+		RotateFunc: func(ctx context.Context, used, next *entity.RefreshToken, client entity.SessionClient) (bool, error) {
+			stored := tokens[used.TokenHash]
+			if stored == nil || stored.IsUsed() {
+				return false, nil
+			}
+			stored.UsedAt = &next.CreatedAt
+			tokens[next.TokenHash] = next
+			return true, nil
+		},
+		DeleteSessionFunc: func(ctx context.Context, id uuid.UUID) error {
+			for hash, token := range tokens {
+				if token.SessionID == id {
+					delete(tokens, hash)
+				}
+			}
+			return nil
+		},
+	}
+
+	jwtConfig := &config.JWTConfig{
+		Secret:        "test-secret",
+		AccessExpiry:  15 * time.Minute,
+		RefreshExpiry: 7 * 24 * time.Hour,
+	}
+
+	uc := auth.NewUseCase(userRepo, refreshTokenRepo, &mocks.MockTeamRepository{}, &mocks.MockTeamMemberRepository{}, jwtConfig, &config.AppConfig{}, nil)
+
+	_, pair, _, err := uc.Login(ctx, &entity.LoginRequest{Email: "test@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+
+	refreshed, err := uc.RefreshToken(ctx, pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := uc.RefreshToken(ctx, pair.RefreshToken); !errors.Is(err, auth.ErrTokenReused) {
+		t.Fatalf("expected ErrTokenReused, got %v", err)
+	}
+
+	if len(tokens) != 0 {
+		t.Error("expected the session and its tokens to be revoked")
+	}
+
+	if _, err := uc.RefreshToken(ctx, refreshed.RefreshToken); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("expected the newest token of the revoked session to fail with ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestRefreshToken_LostRace(t *testing.T) {
+	ctx := context.Background()
+	passwordHash, _ := crypto.HashPassword("password123")
+	testUser := &entity.User{ID: uuid.New(), Email: "test@example.com", PasswordHash: passwordHash, Role: entity.UserRoleUser, IsActive: true}
+
+	userRepo := &mocks.MockUserRepository{
+		GetByEmailFunc: func(ctx context.Context, email string) (*entity.User, error) {
+			return testUser, nil
+		},
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+			return testUser, nil
+		},
+	}
+
+	var stored *entity.RefreshToken
+	deleted := false
+	refreshTokenRepo := &mocks.MockRefreshTokenRepository{
+		CreateSessionFunc: func(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error {
+			stored = token
+			return nil
+		},
+		GetByTokenHashFunc: func(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+			copied := *stored
+			return &copied, nil
+		},
+		// Another request rotated the token between the lookup and the rotation
+		RotateFunc: func(ctx context.Context, used, next *entity.RefreshToken, client entity.SessionClient) (bool, error) {
+			return false, nil
+		},
+		DeleteSessionFunc: func(ctx context.Context, id uuid.UUID) error {
+			deleted = id == stored.SessionID
+			return nil
+		},
+	}
+
+	jwtConfig := &config.JWTConfig{
+		Secret:        "test-secret",
+		AccessExpiry:  15 * time.Minute,
+		RefreshExpiry: 7 * 24 * time.Hour,
+	}
+
+	uc := auth.NewUseCase(userRepo, refreshTokenRepo, &mocks.MockTeamRepository{}, &mocks.MockTeamMemberRepository{}, jwtConfig, &config.AppConfig{}, nil)
+
+	_, pair, _, err := uc.Login(ctx, &entity.LoginRequest{Email: "test@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+
+	if _, err := uc.RefreshToken(ctx, pair.RefreshToken); !errors.Is(err, auth.ErrTokenReused) {
+		t.Fatalf("expected ErrTokenReused, got %v", err)
+	}
+	if !deleted {
+		t.Error("expected the session to be revoked")
+	}
+}
+
+func TestRefreshToken_Expired(t *testing.T) {
+	ctx := context.Background()
+	passwordHash, _ := crypto.HashPassword("password123")
+	testUser := &entity.User{ID: uuid.New(), Email: "test@example.com", PasswordHash: passwordHash, Role: entity.UserRoleUser, IsActive: true}
+
+	userRepo := &mocks.MockUserRepository{
+		GetByEmailFunc: func(ctx context.Context, email string) (*entity.User, error) {
+			return testUser, nil
+		},
+	}
+
+	var stored *entity.RefreshToken
+	deleted := false
+	refreshTokenRepo := &mocks.MockRefreshTokenRepository{
+		CreateSessionFunc: func(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error {
+			stored = token
+			return nil
+		},
+		GetByTokenHashFunc: func(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+			copied := *stored
+			copied.ExpiresAt = time.Now().Add(-time.Minute)
+			return &copied, nil
+		},
+		RotateFunc: func(ctx context.Context, used, next *entity.RefreshToken, client entity.SessionClient) (bool, error) {
+			t.Errorf("expected an expired token not to be rotated")
+			return false, nil
+		},
+		DeleteSessionFunc: func(ctx context.Context, id uuid.UUID) error {
+			deleted = id == stored.SessionID
+			return nil
+		},
+	}
+
+	jwtConfig := &config.JWTConfig{
+		Secret:        "test-secret",
+		AccessExpiry:  15 * time.Minute,
+		RefreshExpiry: 7 * 24 * time.Hour,
+	}
+
+	uc := auth.NewUseCase(userRepo, refreshTokenRepo, &mocks.MockTeamRepository{}, &mocks.MockTeamMemberRepository{}, jwtConfig, &config.AppConfig{}, nil)
+
+	_, pair, _, err := uc.Login(ctx, &entity.LoginRequest{Email: "test@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+
+	if _, err := uc.RefreshToken(ctx, pair.RefreshToken); !errors.Is(err, auth.ErrTokenExpired) {
+		t.Fatalf("expected ErrTokenExpired, got %v", err)
+	}
+	if !deleted {
+		t.Error("expected the expired session to be deleted")
+	}
+}
+
+func TestLogout_EndsSession(t *testing.T) {
+	ctx := context.Background()
+	passwordHash, _ := crypto.HashPassword("password123")
+	testUser := &entity.User{ID: uuid.New(), Email: "test@example.com", PasswordHash: passwordHash, Role: entity.UserRoleUser, IsActive: true}
+
+	userRepo := &mocks.MockUserRepository{
+		GetByEmailFunc: func(ctx context.Context, email string) (*entity.User, error) {
+			return testUser, nil
+		},
+	}
+
+	tokens := make(map[string]*entity.RefreshToken)
+	refreshTokenRepo := &mocks.MockRefreshTokenRepository{
+		CreateSessionFunc: func(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error {
+			tokens[token.TokenHash] = token
+			return nil
+		},
+		GetByTokenHashFunc: func(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+			return tokens[tokenHash], nil
+		},
+		DeleteSessionFunc: func(ctx context.Context, id uuid.UUID) error {
+			for hash, token := range tokens {
+				if token.SessionID == id {
+					delete(tokens, hash)
+				}
+			}
+			return nil
+		},
+	}
+
+	jwtConfig := &config.JWTConfig{
+		Secret:        "test-secret",
+		AccessExpiry:  15 * time.Minute,
+		RefreshExpiry: 7 * 24 * time.Hour,
+	}
+
+	uc := auth.NewUseCase(userRepo, refreshTokenRepo, &mocks.MockTeamRepository{}, &mocks.MockTeamMemberRepository{}, jwtConfig, &config.AppConfig{}, nil)
+
+	_, pair, _, err := uc.Login(ctx, &entity.LoginRequest{Email: "test@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+
+	if err := uc.Logout(ctx, pair.RefreshToken); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tokens) != 0 {
+		t.Error("expected the session to be deleted")
+	}
+
+	if err := uc.Logout(ctx, pair.RefreshToken); err != nil {
+		t.Errorf("expected logging out twice to succeed, got %v", err)
+	}
+}
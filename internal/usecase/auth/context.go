@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+type clientKey struct{}
+
+// maxUserAgentLength matches the column sessions are stored in
+const maxUserAgentLength = 500
+
+// WithClient records the client of a login or refresh request in ctx. The
+// session the request starts or refreshes shows it.
+func WithClient(ctx context.Context, client entity.SessionClient) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+func clientFrom(ctx context.Context) entity.SessionClient {
+	client, _ := ctx.Value(clientKey{}).(entity.SessionClient)
+	if len(client.UserAgent) > maxUserAgentLength {
+		client.UserAgent = strings.ToValidUTF8(client.UserAgent[:maxUserAgentLength], "")
+	}
+	return client
+}
@@ -0,0 +1,233 @@
+package certificate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/domain/repository"
+	"github.com/podoru/spinner-podoru/internal/usecase/apitoken"
+	"github.com/podoru/spinner-podoru/internal/usecase/traefik"
+	"github.com/podoru/spinner-podoru/pkg/crypto"
+)
+
+var (
+	ErrCertificateNotFound   = errors.New("certificate not found")
+	ErrNotTeamMember         = errors.New("not a team member")
+	ErrNotTeamAdmin          = errors.New("requires admin or owner role")
+	ErrInvalidCertificate    = errors.New("invalid certificate")
+	ErrIncompleteReplacement = errors.New("replacing a certificate requires both certificate and private_key")
+	ErrCertificateInUse      = errors.New("certificate is used by domains")
+	ErrCertificateMismatch   = errors.New("certificate does not cover a domain using it")
+	ErrProjectLimitedToken   = errors.New("token is limited to a project")
+)
+
+type UseCase struct {
+	certificateRepo repository.CertificateRepository
+	domainRepo      repository.DomainRepository
+	teamMemberRepo  repository.TeamMemberRepository
+	encryptor       *crypto.Encryptor
+	routes          *traefik.UseCase
+}
+
+func NewUseCase(
+	certificateRepo repository.CertificateRepository,
+	domainRepo repository.DomainRepository,
+	teamMemberRepo repository.TeamMemberRepository,
+	encryptor *crypto.Encryptor,
+	routes *traefik.UseCase,
+) *UseCase {
+	return &UseCase{
+		certificateRepo: certificateRepo,
+		domainRepo:      domainRepo,
+		teamMemberRepo:  teamMemberRepo,
+		encryptor:       encryptor,
+		routes:          routes,
+	}
+}
+
+// Upload validates a PEM chain and key and stores them, the key encrypted
+func (uc *UseCase) Upload(ctx context.Context, userID, teamID uuid.UUID, input *entity.CertificateCreate) (*entity.Certificate, error) {
+	if err := uc.checkAdmin(ctx, userID, teamID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	certificate := &entity.Certificate{
+		ID:        uuid.New(),
+		TeamID:    teamID,
+		Name:      input.Name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := uc.setPEM(certificate, input.Certificate, input.PrivateKey, now); err != nil {
+		return nil, err
+	}
+
+	if err := uc.certificateRepo.Create(ctx, certificate); err != nil {
+		return nil, err
+	}
+
+	return certificate, nil
+}
+
+func (uc *UseCase) List(ctx context.Context, userID, teamID uuid.UUID) ([]entity.Certificate, error) {
+	if _, err := uc.getMember(ctx, userID, teamID); err != nil {
+		return nil, err
+	}
+
+	return uc.certificateRepo.ListByTeamID(ctx, teamID)
+}
+
+// ListExpiring lists the team's certificates that expire within the given
+// number of days, including those already expired
+func (uc *UseCase) ListExpiring(ctx context.Context, userID, teamID uuid.UUID, days int) ([]entity.Certificate, error) {
+	if _, err := uc.getMember(ctx, userID, teamID); err != nil {
+		return nil, err
+	}
+
+	before := time.Now().AddDate(0, 0, days)
+	return uc.certificateRepo.ListExpiringByTeamID(ctx, teamID, before)
+}
+
+func (uc *UseCase) Get(ctx context.Context, userID, teamID, certificateID uuid.UUID) (*entity.Certificate, error) {
+	if _, err := uc.getMember(ctx, userID, teamID); err != nil {
+		return nil, err
+	}
+
+	return uc.getCertificate(ctx, teamID, certificateID)
+}
+
+// Update renames a certificate or replaces its chain and key, e.g. with a
+// renewed certificate. A replacement must still cover every domain using it.
+func (uc *UseCase) Update(ctx context.Context, userID, teamID, certificateID uuid.UUID, input *entity.CertificateUpdate) (*entity.Certificate, error) {
+	if err := uc.checkAdmin(ctx, userID, teamID); err != nil {
+		return nil, err
+	}
+
+	certificate, err := uc.getCertificate(ctx, teamID, certificateID)
+	if err != nil {
+		return nil, err
+	}
+
+	if (input.Certificate == nil) != (input.PrivateKey == nil) {
+		return nil, ErrIncompleteReplacement
+	}
+
+	now := time.Now()
+	if input.Name != nil {
+		certificate.Name = *input.Name
+	}
+	if input.Certificate != nil {
+		if err := uc.setPEM(certificate, *input.Certificate, *input.PrivateKey, now); err != nil {
+			return nil, err
+		}
+
+		domains, err := uc.domainRepo.ListByCertificateID(ctx, certificate.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range domains {
+			if !certificate.Covers(d.Domain) {
+				return nil, fmt.Errorf("%w: %s", ErrCertificateMismatch, d.Domain)
+			}
+			if d.WWWRedirect && !certificate.Covers(entity.WWWCounterpart(d.Domain)) {
+				return nil, fmt.Errorf("%w: %s", ErrCertificateMismatch, entity.WWWCounterpart(d.Domain))
+			}
+		}
+	}
+	certificate.UpdatedAt = now
+
+	if err := uc.certificateRepo.Update(ctx, certificate); err != nil {
+		return nil, err
+	}
+	uc.routes.Refresh()
+
+	return certificate, nil
+}
+
+func (uc *UseCase) Delete(ctx context.Context, userID, teamID, certificateID uuid.UUID) error {
+	if err := uc.checkAdmin(ctx, userID, teamID); err != nil {
+		return err
+	}
+
+	if _, err := uc.getCertificate(ctx, teamID, certificateID); err != nil {
+		return err
+	}
+
+	domains, err := uc.domainRepo.ListByCertificateID(ctx, certificateID)
+	if err != nil {
+		return err
+	}
+	if len(domains) > 0 {
+		return ErrCertificateInUse
+	}
+
+	return uc.certificateRepo.Delete(ctx, certificateID)
+}
+
+// setPEM validates the chain and key and copies them with the leaf's details
+// onto the certificate
+func (uc *UseCase) setPEM(certificate *entity.Certificate, certPEM, keyPEM string, now time.Time) error {
+	info, err := crypto.ParseCertificate(certPEM, keyPEM, now)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCertificate, err)
+	}
+
+	keyEncrypted, err := uc.encryptor.Encrypt([]byte(keyPEM))
+	if err != nil {
+		return err
+	}
+
+	certificate.CertificatePEM = certPEM
+	certificate.KeyEncrypted = keyEncrypted
+	certificate.DNSNames = info.DNSNames
+	certificate.Subject = info.Subject
+	certificate.Issuer = info.Issuer
+	certificate.NotBefore = info.NotBefore
+	certificate.NotAfter = info.NotAfter
+	return nil
+}
+
+func (uc *UseCase) getCertificate(ctx context.Context, teamID, certificateID uuid.UUID) (*entity.Certificate, error) {
+	certificate, err := uc.certificateRepo.GetByID(ctx, certificateID)
+	if err != nil {
+		return nil, err
+	}
+	if certificate == nil || certificate.TeamID != teamID {
+		return nil, ErrCertificateNotFound
+	}
+	return certificate, nil
+}
+
+// checkAdmin guards changes to the team's certificates, which are shared by
+// all of its projects, so a project limited token cannot make them
+func (uc *UseCase) checkAdmin(ctx context.Context, userID, teamID uuid.UUID) error {
+	if apitoken.ProjectLimited(ctx) {
+		return ErrProjectLimitedToken
+	}
+
+	member, err := uc.getMember(ctx, userID, teamID)
+	if err != nil {
+		return err
+	}
+	if member.Role == entity.TeamRoleMember {
+		return ErrNotTeamAdmin
+	}
+	return nil
+}
+
+func (uc *UseCase) getMember(ctx context.Context, userID, teamID uuid.UUID) (*entity.TeamMember, error) {
+	member, err := uc.teamMemberRepo.GetByTeamAndUser(ctx, teamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrNotTeamMember
+	}
+	return member, nil
+}
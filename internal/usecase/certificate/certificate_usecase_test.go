@@ -0,0 +1,451 @@
+package certificate_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/config"
+	"github.com/podoru/spinner-podoru/internal/mocks"
+	"github.com/podoru/spinner-podoru/internal/usecase/apitoken"
+	"github.com/podoru/spinner-podoru/internal/usecase/certificate"
+	"github.com/podoru/spinner-podoru/internal/usecase/traefik"
+	"github.com/podoru/spinner-podoru/pkg/crypto"
+)
+
+// selfSigned returns a PEM certificate for dnsNames and its PEM key
+func selfSigned(t *testing.T, dnsNames ...string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(0, 3, 0),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func TestUpload_Success(t *testing.T) {
+	ctx := context.Background()
+	teamID := uuid.New()
+	certPEM, keyPEM := selfSigned(t, "example.com", "www.example.com")
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, tID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: tID, UserID: userID, Role: entity.TeamRoleAdmin}, nil
+		},
+	}
+
+	var stored *entity.Certificate
+	certificateRepo := &mocks.MockCertificateRepository{
+		CreateFunc: func(ctx context.Context, c *entity.Certificate) error {
+			stored = c
+			return nil
+		},
+	}
+
+	encryptor, err := crypto.NewEncryptor("test-key")
+	if err != nil {
+		t.Fatalf("failed to create encryptor: %v", err)
+	}
+
+	uc := certificate.NewUseCase(certificateRepo, &mocks.MockDomainRepository{}, teamMemberRepo, encryptor, nil)
+
+	result, err := uc.Upload(ctx, uuid.New(), teamID, &entity.CertificateCreate{
+		Name:        "example",
+		Certificate: certPEM,
+		PrivateKey:  keyPEM,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if stored == nil || stored.ID != result.ID {
+		t.Fatal("expected certificate to be stored")
+	}
+	if result.TeamID != teamID {
+		t.Errorf("expected team %s, got %s", teamID, result.TeamID)
+	}
+	if len(result.DNSNames) != 2 || result.DNSNames[0] != "example.com" {
+		t.Errorf("expected DNS names from the certificate, got %v", result.DNSNames)
+	}
+
+	key, err := encryptor.Decrypt(result.KeyEncrypted)
+	if err != nil {
+		t.Fatalf("failed to decrypt key: %v", err)
+	}
+	if string(key) != keyPEM {
+		t.Error("expected the stored key to decrypt to the uploaded key")
+	}
+}
+
+func TestUpload_KeyMismatch(t *testing.T) {
+	ctx := context.Background()
+	certPEM, _ := selfSigned(t, "example.com")
+	_, otherKeyPEM := selfSigned(t, "example.com")
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleOwner}, nil
+		},
+	}
+
+	certificateRepo := &mocks.MockCertificateRepository{
+		CreateFunc: func(ctx context.Context, c *entity.Certificate) error {
+			t.Error("expected no certificate to be stored")
+			return nil
+		},
+	}
+
+	encryptor, _ := crypto.NewEncryptor("test-key")
+	uc := certificate.NewUseCase(certificateRepo, &mocks.MockDomainRepository{}, teamMemberRepo, encryptor, nil)
+
+	_, err := uc.Upload(ctx, uuid.New(), uuid.New(), &entity.CertificateCreate{
+		Name:        "example",
+		Certificate: certPEM,
+		PrivateKey:  otherKeyPEM,
+	})
+	if !errors.Is(err, certificate.ErrInvalidCertificate) {
+		t.Errorf("expected ErrInvalidCertificate, got %v", err)
+	}
+}
+
+func TestUpload_NotAdmin(t *testing.T) {
+	ctx := context.Background()
+	certPEM, keyPEM := selfSigned(t, "example.com")
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleMember}, nil
+		},
+	}
+
+	encryptor, _ := crypto.NewEncryptor("test-key")
+	uc := certificate.NewUseCase(&mocks.MockCertificateRepository{}, &mocks.MockDomainRepository{}, teamMemberRepo, encryptor, nil)
+
+	_, err := uc.Upload(ctx, uuid.New(), uuid.New(), &entity.CertificateCreate{
+		Name:        "example",
+		Certificate: certPEM,
+		PrivateKey:  keyPEM,
+	})
+	if err != certificate.ErrNotTeamAdmin {
+		t.Errorf("expected ErrNotTeamAdmin, got %v", err)
+	}
+}
+
+func TestUpload_ProjectLimitedToken(t *testing.T) {
+	teamID := uuid.New()
+	projectID := uuid.New()
+	ctx := apitoken.WithToken(context.Background(), &entity.APIToken{ID: uuid.New(), TeamID: &teamID, ProjectID: &projectID})
+	certPEM, keyPEM := selfSigned(t, "example.com")
+
+	teamMemberRepo := apitoken.RestrictTeamMembers(&mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleOwner}, nil
+		},
+	})
+
+	certificateRepo := &mocks.MockCertificateRepository{
+		CreateFunc: func(ctx context.Context, c *entity.Certificate) error {
+			t.Error("expected no certificate to be stored")
+			return nil
+		},
+	}
+
+	encryptor, _ := crypto.NewEncryptor("test-key")
+	uc := certificate.NewUseCase(certificateRepo, &mocks.MockDomainRepository{}, teamMemberRepo, encryptor, nil)
+
+	_, err := uc.Upload(ctx, uuid.New(), teamID, &entity.CertificateCreate{
+		Name:        "example",
+		Certificate: certPEM,
+		PrivateKey:  keyPEM,
+	})
+	if err != certificate.ErrProjectLimitedToken {
+		t.Errorf("expected ErrProjectLimitedToken, got %v", err)
+	}
+}
+
+func TestUpdate_ReplacementCoversDomains(t *testing.T) {
+	tests := []struct {
+		name     string
+		dnsNames []string
+		domains  []entity.Domain
+		wantErr  error
+	}{
+		{
+			name:     "covers every domain",
+			dnsNames: []string{"example.com", "api.example.com"},
+			domains:  []entity.Domain{{Domain: "example.com"}, {Domain: "api.example.com"}},
+		},
+		{
+			name:     "drops a domain in use",
+			dnsNames: []string{"example.com"},
+			domains:  []entity.Domain{{Domain: "example.com"}, {Domain: "api.example.com"}},
+			wantErr:  certificate.ErrCertificateMismatch,
+		},
+		{
+			name:     "drops the www counterpart of a redirect",
+			dnsNames: []string{"example.com"},
+			domains:  []entity.Domain{{Domain: "example.com", WWWRedirect: true}},
+			wantErr:  certificate.ErrCertificateMismatch,
+		},
+		{
+			name:     "wildcard covers the www counterpart",
+			dnsNames: []string{"example.com", "*.example.com"},
+			domains:  []entity.Domain{{Domain: "example.com", WWWRedirect: true}},
+		},
+		{
+			name:     "unused certificate",
+			dnsNames: []string{"other.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			teamID := uuid.New()
+			certificateID := uuid.New()
+			certPEM, keyPEM := selfSigned(t, tt.dnsNames...)
+
+			teamMemberRepo := &mocks.MockTeamMemberRepository{
+				GetByTeamAndUserFunc: func(ctx context.Context, tID, userID uuid.UUID) (*entity.TeamMember, error) {
+					return &entity.TeamMember{TeamID: tID, UserID: userID, Role: entity.TeamRoleAdmin}, nil
+				},
+			}
+
+			var updated bool
+			certificateRepo := &mocks.MockCertificateRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Certificate, error) {
+					return &entity.Certificate{ID: id, TeamID: teamID, Name: "example", DNSNames: []string{"example.com", "*.example.com"}}, nil
+				},
+				UpdateFunc: func(ctx context.Context, c *entity.Certificate) error {
+					updated = true
+					return nil
+				},
+			}
+
+			domainRepo := &mocks.MockDomainRepository{
+				ListByCertificateIDFunc: func(ctx context.Context, id uuid.UUID) ([]entity.Domain, error) {
+					if id != certificateID {
+						t.Errorf("expected domains of certificate %s, got %s", certificateID, id)
+					}
+					return tt.domains, nil
+				},
+			}
+
+			encryptor, _ := crypto.NewEncryptor("test-key")
+			routes := traefik.NewUseCase(nil, nil, nil, nil, nil, nil, nil, &config.TraefikConfig{})
+			uc := certificate.NewUseCase(certificateRepo, domainRepo, teamMemberRepo, encryptor, routes)
+
+			_, err := uc.Update(ctx, uuid.New(), teamID, certificateID, &entity.CertificateUpdate{
+				Certificate: &certPEM,
+				PrivateKey:  &keyPEM,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if updated != (tt.wantErr == nil) {
+				t.Errorf("expected certificate updated %v, got %v", tt.wantErr == nil, updated)
+			}
+		})
+	}
+}
+
+func TestUpdate_IncompleteReplacement(t *testing.T) {
+	ctx := context.Background()
+	teamID := uuid.New()
+	certPEM, _ := selfSigned(t, "example.com")
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, tID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: tID, UserID: userID, Role: entity.TeamRoleOwner}, nil
+		},
+	}
+
+	certificateRepo := &mocks.MockCertificateRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Certificate, error) {
+			return &entity.Certificate{ID: id, TeamID: teamID}, nil
+		},
+	}
+
+	encryptor, _ := crypto.NewEncryptor("test-key")
+	uc := certificate.NewUseCase(certificateRepo, &mocks.MockDomainRepository{}, teamMemberRepo, encryptor, nil)
+
+	_, err := uc.Update(ctx, uuid.New(), teamID, uuid.New(), &entity.CertificateUpdate{Certificate: &certPEM})
+	if err != certificate.ErrIncompleteReplacement {
+		t.Errorf("expected ErrIncompleteReplacement, got %v", err)
+	}
+}
+
+func TestDelete_InUse(t *testing.T) {
+	ctx := context.Background()
+	teamID := uuid.New()
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, tID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: tID, UserID: userID, Role: entity.TeamRoleOwner}, nil
+		},
+	}
+
+	certificateRepo := &mocks.MockCertificateRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Certificate, error) {
+			return &entity.Certificate{ID: id, TeamID: teamID}, nil
+		},
+		DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
+			t.Error("expected certificate to be kept")
+			return nil
+		},
+	}
+
+	domainRepo := &mocks.MockDomainRepository{
+		ListByCertificateIDFunc: func(ctx context.Context, id uuid.UUID) ([]entity.Domain, error) {
+			return []entity.Domain{{ID: uuid.New(), Domain: "example.com", CertificateID: &id}}, nil
+		},
+	}
+
+	uc := certificate.NewUseCase(certificateRepo, domainRepo, teamMemberRepo, nil, nil)
+
+	if err := uc.Delete(ctx, uuid.New(), teamID, uuid.New()); err != certificate.ErrCertificateInUse {
+		t.Errorf("expected ErrCertificateInUse, got %v", err)
+	}
+}
+
+func TestDelete_Success(t *testing.T) {
+	ctx := context.Background()
+	teamID := uuid.New()
+	certificateID := uuid.New()
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, tID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: tID, UserID: userID, Role: entity.TeamRoleAdmin}, nil
+		},
+	}
+
+	var deleted uuid.UUID
+	certificateRepo := &mocks.MockCertificateRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Certificate, error) {
+			return &entity.Certificate{ID: id, TeamID: teamID}, nil
+		},
+		DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
+			deleted = id
+			return nil
+		},
+	}
+
+	uc := certificate.NewUseCase(certificateRepo, &mocks.MockDomainRepository{}, teamMemberRepo, nil, nil)
+
+	if err := uc.Delete(ctx, uuid.New(), teamID, certificateID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted != certificateID {
+		t.Errorf("expected certificate %s to be deleted, got %s", certificateID, deleted)
+	}
+}
+
+func TestDelete_OtherTeamsCertificate(t *testing.T) {
+	ctx := context.Background()
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, tID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: tID, UserID: userID, Role: entity.TeamRoleOwner}, nil
+		},
+	}
+
+	certificateRepo := &mocks.MockCertificateRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Certificate, error) {
+			return &entity.Certificate{ID: id, TeamID: uuid.New()}, nil
+		},
+		DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
+			t.Error("expected certificate to be kept")
+			return nil
+		},
+	}
+
+	uc := certificate.NewUseCase(certificateRepo, &mocks.MockDomainRepository{}, teamMemberRepo, nil, nil)
+
+	if err := uc.Delete(ctx, uuid.New(), uuid.New(), uuid.New()); err != certificate.ErrCertificateNotFound {
+		t.Errorf("expected ErrCertificateNotFound, got %v", err)
+	}
+}
+
+func TestListExpiring(t *testing.T) {
+	ctx := context.Background()
+	teamID := uuid.New()
+	expiring := []entity.Certificate{{ID: uuid.New(), TeamID: teamID, NotAfter: time.Now().AddDate(0, 0, 10)}}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, tID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: tID, UserID: userID, Role: entity.TeamRoleMember}, nil
+		},
+	}
+
+	var gotBefore time.Time
+	certificateRepo := &mocks.MockCertificateRepository{
+		ListExpiringByTeamIDFunc: func(ctx context.Context, tID uuid.UUID, before time.Time) ([]entity.Certificate, error) {
+			if tID != teamID {
+				t.Errorf("expected team %s, got %s", teamID, tID)
+			}
+			gotBefore = before
+			return expiring, nil
+		},
+	}
+
+	uc := certificate.NewUseCase(certificateRepo, &mocks.MockDomainRepository{}, teamMemberRepo, nil, nil)
+
+	result, err := uc.ListExpiring(ctx, uuid.New(), teamID, 30)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result) != 1 {
+		t.Errorf("expected 1 certificate, got %d", len(result))
+	}
+	want := time.Now().AddDate(0, 0, 30)
+	if diff := want.Sub(gotBefore); diff < 0 || diff > time.Minute {
+		t.Errorf("expected cutoff about 30 days ahead, got %v", gotBefore)
+	}
+}
+
+func TestListExpiring_NotTeamMember(t *testing.T) {
+	ctx := context.Background()
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return nil, nil
+		},
+	}
+
+	uc := certificate.NewUseCase(&mocks.MockCertificateRepository{}, &mocks.MockDomainRepository{}, teamMemberRepo, nil, nil)
+
+	if _, err := uc.ListExpiring(ctx, uuid.New(), uuid.New(), 30); err != certificate.ErrNotTeamMember {
+		t.Errorf("expected ErrNotTeamMember, got %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package deployment
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	domainDocker "github.com/podoru/spinner-podoru/internal/domain/docker"
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	domainGit "github.com/podoru/spinner-podoru/internal/domain/git"
+	"github.com/podoru/spinner-podoru/pkg/buildpack"
+)
+
+// generatedDockerfile is the name the auto deploy type writes its Dockerfile
+// under, chosen so it never clobbers a file from the repository
+const generatedDockerfile = ".podoru.Dockerfile"
+
+// buildImage checks out the project repository and builds the service image
+// from it, returning the local tag to run. Progress is written to logs.
+func (uc *UseCase) buildImage(ctx context.Context, service *entity.Service, deployment *entity.Deployment, logs io.Writer) (string, error) {
+	project, err := uc.projectRepo.GetByID(ctx, service.ProjectID)
+	if err != nil {
+		return "", err
+	}
+	if project == nil || project.GithubRepo == nil || *project.GithubRepo == "" {
+		return "", ErrNoRepository
+	}
+
+	// Wait for a build slot so a burst of deploys cannot starve the host
+	if !uc.builds.TryAcquire(project.TeamID) {
+		deployment.Status = entity.DeploymentStatusQueued
+		uc.deploymentRepo.Update(ctx, deployment)
+		fmt.Fprintf(logs, "==> Waiting for a free build slot\n")
+
+		if err := uc.builds.Acquire(ctx, project.TeamID); err != nil {
+			return "", err
+		}
+	}
+	defer uc.builds.Release(project.TeamID)
+
+	deployment.Status = entity.DeploymentStatusBuilding
+	uc.deploymentRepo.Update(ctx, deployment)
+
+	var token string
+	if len(project.GithubTokenEncrypted) > 0 {
+		decrypted, err := uc.encryptor.Decrypt(project.GithubTokenEncrypted)
+		if err != nil {
+			return "", fmt.Errorf("failed to decrypt repository token: %w", err)
+		}
+		token = string(decrypted)
+	}
+
+	buildArgs, err := uc.decryptVars(service.BuildArgsEncrypted)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt build args: %w", err)
+	}
+
+	if err := os.MkdirAll(uc.buildConfig.WorkDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create build directory: %w", err)
+	}
+	checkoutDir := filepath.Join(uc.buildConfig.WorkDir, deployment.ID.String())
+	defer os.RemoveAll(checkoutDir)
+
+	fmt.Fprintf(logs, "==> Cloning %s (branch %s)\n", *project.GithubRepo, project.GithubBranch)
+	commit, err := uc.cloner.Clone(ctx, &domainGit.CloneOptions{
+		URL:    *project.GithubRepo,
+		Branch: project.GithubBranch,
+		Token:  token,
+		Dir:    checkoutDir,
+	})
+	if err != nil {
+		return "", err
+	}
+	deployment.CommitSHA = &commit.SHA
+	deployment.CommitMessage = &commit.Message
+	fmt.Fprintf(logs, "==> Checked out %s\n", commit.SHA)
+
+	contextDir, err := resolveBuildPath(checkoutDir, service.BuildContext)
+	if err != nil {
+		return "", err
+	}
+
+	dockerfile := service.DockerfilePath
+	if service.DeployType == entity.DeployTypeAuto {
+		plan, err := buildpack.Detect(contextDir)
+		if err != nil {
+			return "", err
+		}
+
+		if err := os.WriteFile(filepath.Join(contextDir, generatedDockerfile), []byte(plan.Dockerfile), 0644); err != nil {
+			return "", fmt.Errorf("failed to write generated Dockerfile: %w", err)
+		}
+		dockerfile = generatedDockerfile
+
+		// Printed verbatim between markers so it can be copied into the repository
+		fmt.Fprintf(logs, "==> Detected %s project, generated Dockerfile:\n", plan.Language)
+		fmt.Fprintf(logs, "----- Dockerfile -----\n%s----- end Dockerfile -----\n", plan.Dockerfile)
+	} else if _, err := resolveBuildPath(contextDir, dockerfile); err != nil {
+		return "", err
+	}
+
+	tag := fmt.Sprintf("podoru/%s:%s", service.Slug, shortSHA(commit.SHA))
+
+	// Every build is also tagged into the project's cache repository so its
+	// layers survive image pruning and seed the next build of the service
+	cacheRef := fmt.Sprintf("podoru-cache/%s:%s", service.ProjectID, service.Slug)
+
+	buildOpts := &domainDocker.BuildOptions{
+		ContextDir: contextDir,
+		Dockerfile: dockerfile,
+		Tags:       []string{tag, cacheRef},
+		Labels: map[string]string{
+			"podoru.service.id": service.ID.String(),
+			"podoru.project.id": service.ProjectID.String(),
+			"podoru.managed":    "true",
+		},
+		BuildArgs:   buildArgs,
+		NoCache:     deployment.NoCache,
+		CPULimit:    uc.buildConfig.CPULimit,
+		MemoryLimit: int64(uc.buildConfig.MemoryLimit) * 1024 * 1024, // Convert MB to bytes
+	}
+	if service.BuildTarget != nil {
+		buildOpts.Target = *service.BuildTarget
+	}
+	if !deployment.NoCache {
+		buildOpts.CacheFrom = []string{cacheRef}
+	}
+
+	switch {
+	case deployment.NoCache:
+		fmt.Fprintf(logs, "==> Building %s without cache\n", tag)
+	case buildOpts.Target != "":
+		fmt.Fprintf(logs, "==> Building %s (target %s)\n", tag, buildOpts.Target)
+	default:
+		fmt.Fprintf(logs, "==> Building %s\n", tag)
+	}
+
+	err = uc.containerManager.BuildImage(ctx, buildOpts, logs)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Fprintf(logs, "==> Built %s\n", tag)
+	return tag, nil
+}
+
+// resolveBuildPath joins a user supplied path onto base, refusing paths that
+// escape it
+func resolveBuildPath(base, rel string) (string, error) {
+	path := filepath.Join(base, rel)
+	if path != base && !strings.HasPrefix(path, base+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %q is outside the repository", rel)
+	}
+	return path, nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
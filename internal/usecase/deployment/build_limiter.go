@@ -0,0 +1,85 @@
+package deployment
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// BuildLimiter caps how many builds run at once, both across the host and
+// per team. A limit below 1 means unlimited; config.setDefaults decides what
+// an unset limit becomes.
+type BuildLimiter struct {
+	mu         sync.Mutex
+	maxTotal   int
+	maxPerTeam int
+	running    int
+	perTeam    map[uuid.UUID]int
+	// released is closed and replaced every time a slot frees up, waking
+	// all waiters to re-check the limits
+	released chan struct{}
+}
+
+func NewBuildLimiter(maxTotal, maxPerTeam int) *BuildLimiter {
+	return &BuildLimiter{
+		maxTotal:   maxTotal,
+		maxPerTeam: maxPerTeam,
+		perTeam:    make(map[uuid.UUID]int),
+		released:   make(chan struct{}),
+	}
+}
+
+// TryAcquire takes a build slot for the team if one is free right now
+func (l *BuildLimiter) TryAcquire(teamID uuid.UUID) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.take(teamID)
+}
+
+// Acquire blocks until the team gets a build slot or ctx is cancelled
+func (l *BuildLimiter) Acquire(ctx context.Context, teamID uuid.UUID) error {
+	for {
+		l.mu.Lock()
+		if l.take(teamID) {
+			l.mu.Unlock()
+			return nil
+		}
+		wait := l.released
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+// Release frees a slot taken by TryAcquire or Acquire
+func (l *BuildLimiter) Release(teamID uuid.UUID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.running--
+	l.perTeam[teamID]--
+	if l.perTeam[teamID] <= 0 {
+		delete(l.perTeam, teamID)
+	}
+
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+// take must be called with mu held
+func (l *BuildLimiter) take(teamID uuid.UUID) bool {
+	if l.maxTotal > 0 && l.running >= l.maxTotal {
+		return false
+	}
+	if l.maxPerTeam > 0 && l.perTeam[teamID] >= l.maxPerTeam {
+		return false
+	}
+	l.running++
+	l.perTeam[teamID]++
+	return true
+}
@@ -0,0 +1,113 @@
+package deployment_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/usecase/deployment"
+)
+
+func TestBuildLimiter_TryAcquire(t *testing.T) {
+	teamA := uuid.New()
+	teamB := uuid.New()
+
+	tests := []struct {
+		name       string
+		maxTotal   int
+		maxPerTeam int
+		held       []uuid.UUID
+		team       uuid.UUID
+		want       bool
+	}{
+		{name: "free slot", maxTotal: 2, maxPerTeam: 1, team: teamA, want: true},
+		{name: "host limit reached", maxTotal: 2, maxPerTeam: 2, held: []uuid.UUID{teamA, teamB}, team: teamB, want: false},
+		{name: "team limit reached", maxTotal: 3, maxPerTeam: 1, held: []uuid.UUID{teamA}, team: teamA, want: false},
+		{name: "other team under its limit", maxTotal: 3, maxPerTeam: 1, held: []uuid.UUID{teamA}, team: teamB, want: true},
+		{name: "unlimited", maxTotal: -1, maxPerTeam: 0, held: []uuid.UUID{teamA, teamA, teamA}, team: teamA, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := deployment.NewBuildLimiter(tt.maxTotal, tt.maxPerTeam)
+			for _, teamID := range tt.held {
+				if !l.TryAcquire(teamID) {
+					t.Fatalf("expected setup slot for team %s", teamID)
+				}
+			}
+
+			if got := l.TryAcquire(tt.team); got != tt.want {
+				t.Errorf("expected TryAcquire %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBuildLimiter_Release(t *testing.T) {
+	teamID := uuid.New()
+	l := deployment.NewBuildLimiter(1, 1)
+
+	if !l.TryAcquire(teamID) {
+		t.Fatal("expected first slot to be free")
+	}
+	if l.TryAcquire(teamID) {
+		t.Fatal("expected second slot to be refused")
+	}
+
+	l.Release(teamID)
+
+	if !l.TryAcquire(teamID) {
+		t.Error("expected slot to be free after release")
+	}
+}
+
+func TestBuildLimiter_AcquireWaitsForRelease(t *testing.T) {
+	teamID := uuid.New()
+	l := deployment.NewBuildLimiter(1, 0)
+
+	if !l.TryAcquire(teamID) {
+		t.Fatal("expected first slot to be free")
+	}
+
+	acquired := make(chan error, 1)
+	go func() {
+		acquired <- l.Acquire(context.Background(), teamID)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("expected Acquire to wait while the slot is held")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	l.Release(teamID)
+
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Errorf("expected queued build to get the slot, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected queued build to be handed the released slot")
+	}
+
+	if l.TryAcquire(uuid.New()) {
+		t.Error("expected the handed-off slot to still count against the host limit")
+	}
+}
+
+func TestBuildLimiter_AcquireCancelled(t *testing.T) {
+	teamID := uuid.New()
+	l := deployment.NewBuildLimiter(1, 0)
+	l.TryAcquire(teamID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := l.Acquire(ctx, teamID); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
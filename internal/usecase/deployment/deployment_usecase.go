@@ -1,28 +1,42 @@
 package deployment
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
-	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	domainDocker "github.com/podoru/spinner-podoru/internal/domain/docker"
 	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	domainGit "github.com/podoru/spinner-podoru/internal/domain/git"
 	"github.com/podoru/spinner-podoru/internal/domain/repository"
+	domainSecret "github.com/podoru/spinner-podoru/internal/domain/secret"
 	"github.com/podoru/spinner-podoru/internal/infrastructure/config"
+	"github.com/podoru/spinner-podoru/internal/usecase/apitoken"
+	"github.com/podoru/spinner-podoru/internal/usecase/deploytoken"
+	"github.com/podoru/spinner-podoru/internal/usecase/envgroup"
+	"github.com/podoru/spinner-podoru/internal/usecase/traefik"
+	"github.com/podoru/spinner-podoru/pkg/crypto"
 )
 
 var (
 	ErrServiceNotFound    = errors.New("service not found")
 	ErrProjectNotFound    = errors.New("project not found")
 	ErrNotTeamMember      = errors.New("not a team member")
+	ErrNotTeamAdmin       = errors.New("requires admin or owner role")
 	ErrNoImageSpecified   = errors.New("no image specified for deployment")
 	ErrServiceNotDeployed = errors.New("service not deployed yet")
 	ErrAlreadyDeploying   = errors.New("deployment already in progress")
+	ErrNotImageService    = errors.New("service does not deploy a registry image")
+	ErrNoRepository       = errors.New("project has no repository to build from")
+
+	ErrWindowOverrideDenied = errors.New("only team admins can deploy outside the maintenance window")
+
+	ErrUnsupportedDeployType = errors.New("deploy type not supported yet")
 )
 
 // UseCase handles deployment operations
@@ -31,9 +45,19 @@ type UseCase struct {
 	projectRepo      repository.ProjectRepository
 	teamMemberRepo   repository.TeamMemberRepository
 	deploymentRepo   repository.DeploymentRepository
-	domainRepo       repository.DomainRepository
+	portRepo         repository.PortMappingRepository
+	networkRepo      repository.NetworkRepository
 	containerManager domainDocker.ContainerManager
+	cloner           domainGit.Cloner
+	encryptor        *crypto.Encryptor
+	dockerConfig     *config.DockerConfig
 	traefikConfig    *config.TraefikConfig
+	buildConfig      *config.BuildConfig
+	routes           *traefik.UseCase
+	envGroups        *envgroup.UseCase
+	secretProviders  map[string]domainSecret.Provider
+	secretsConfig    *config.SecretsConfig
+	builds           *BuildLimiter
 }
 
 // NewUseCase creates a new deployment use case
@@ -42,72 +66,165 @@ func NewUseCase(
 	projectRepo repository.ProjectRepository,
 	teamMemberRepo repository.TeamMemberRepository,
 	deploymentRepo repository.DeploymentRepository,
-	domainRepo repository.DomainRepository,
+	portRepo repository.PortMappingRepository,
+	networkRepo repository.NetworkRepository,
 	containerManager domainDocker.ContainerManager,
+	cloner domainGit.Cloner,
+	encryptor *crypto.Encryptor,
+	dockerConfig *config.DockerConfig,
 	traefikConfig *config.TraefikConfig,
+	buildConfig *config.BuildConfig,
+	routes *traefik.UseCase,
+	envGroups *envgroup.UseCase,
+	secretProviders []domainSecret.Provider,
+	secretsConfig *config.SecretsConfig,
 ) *UseCase {
+	providers := make(map[string]domainSecret.Provider, len(secretProviders))
+	for _, p := range secretProviders {
+		providers[p.Scheme()] = p
+	}
+
 	return &UseCase{
 		serviceRepo:      serviceRepo,
 		projectRepo:      projectRepo,
 		teamMemberRepo:   teamMemberRepo,
 		deploymentRepo:   deploymentRepo,
-		domainRepo:       domainRepo,
+		portRepo:         portRepo,
+		networkRepo:      networkRepo,
 		containerManager: containerManager,
+		cloner:           cloner,
+		encryptor:        encryptor,
+		dockerConfig:     dockerConfig,
 		traefikConfig:    traefikConfig,
+		buildConfig:      buildConfig,
+		routes:           routes,
+		envGroups:        envGroups,
+		secretProviders:  providers,
+		secretsConfig:    secretsConfig,
+		builds:           NewBuildLimiter(buildConfig.MaxConcurrent, buildConfig.MaxConcurrentPerTeam),
 	}
 }
 
 // Deploy deploys a service
-func (uc *UseCase) Deploy(ctx context.Context, userID, serviceID uuid.UUID) (*entity.Deployment, error) {
-	// 1. Validate access
+func (uc *UseCase) Deploy(ctx context.Context, userID, serviceID uuid.UUID, opts *entity.DeployOptions) (*entity.Deployment, error) {
 	service, err := uc.validateAccess(ctx, userID, serviceID)
 	if err != nil {
 		return nil, err
 	}
 
-	// 2. Check if already deploying
-	if service.Status == entity.ServiceStatusDeploying {
-		return nil, ErrAlreadyDeploying
+	if opts != nil && opts.IgnoreMaintenanceWindow {
+		if err := uc.authorizeWindowOverride(ctx, userID, service); err != nil {
+			return nil, err
+		}
 	}
 
-	// 3. Validate deploy type - only support image for now
-	if service.DeployType != entity.DeployTypeImage {
-		return nil, fmt.Errorf("deploy type %s not yet supported", service.DeployType)
+	triggeredBy := &userID
+	if deploytoken.TokenFrom(ctx) != nil {
+		triggeredBy = nil
 	}
+	return uc.startDeployment(ctx, service, triggeredBy, opts)
+}
 
-	if service.Image == nil || *service.Image == "" {
-		return nil, ErrNoImageSpecified
+// startDeployment records a deployment and runs it in the background, or
+// leaves it pending for the scheduler when it is due later.
+// triggeredBy is nil for deployments started by Podoru itself or by the
+// deploy token in ctx, which is recorded instead.
+func (uc *UseCase) startDeployment(ctx context.Context, service *entity.Service, triggeredBy *uuid.UUID, opts *entity.DeployOptions) (*entity.Deployment, error) {
+	if opts == nil {
+		opts = &entity.DeployOptions{}
+	}
+
+	project, err := uc.projectRepo.GetByID(ctx, service.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, ErrProjectNotFound
+	}
+
+	// 1. Validate the deploy type has something to deploy from
+	switch {
+	case service.DeployType == entity.DeployTypeImage:
+		if service.Image == nil || *service.Image == "" {
+			return nil, ErrNoImageSpecified
+		}
+	case service.DeployType.BuildsFromSource():
+		if project.GithubRepo == nil || *project.GithubRepo == "" {
+			return nil, ErrNoRepository
+		}
+	default:
+		return nil, ErrUnsupportedDeployType
 	}
 
-	// 4. Create deployment record
 	deployment := &entity.Deployment{
 		ID:          uuid.New(),
-		ServiceID:   serviceID,
-		TriggeredBy: &userID,
+		ServiceID:   service.ID,
+		TriggeredBy: triggeredBy,
 		Status:      entity.DeploymentStatusPending,
+		NoCache:     opts.NoCache,
 		StartedAt:   time.Now(),
 	}
+	if token := deploytoken.TokenFrom(ctx); token != nil {
+		deployment.TriggeredByToken = &token.ID
+	}
+
+	// 2. Hold the deployment back if it is due later
+	if scheduledFor := opts.ScheduleFor(project.MaintenanceWindow, deployment.StartedAt); scheduledFor != nil {
+		// Only the latest schedule per service matters; a newer push or
+		// request replaces whatever was waiting
+		if err := uc.deploymentRepo.CancelScheduled(ctx, service.ID); err != nil {
+			return nil, err
+		}
+		deployment.ScheduledFor = scheduledFor
+		if err := uc.deploymentRepo.Create(ctx, deployment); err != nil {
+			return nil, err
+		}
+		return deployment, nil
+	}
+
+	// 3. Check if already deploying
+	if service.Status == entity.ServiceStatusDeploying {
+		return nil, ErrAlreadyDeploying
+	}
+
+	// 4. Create deployment record
 	if err := uc.deploymentRepo.Create(ctx, deployment); err != nil {
 		return nil, err
 	}
 
 	// 5. Execute deployment in goroutine
-	go uc.executeDeployment(context.Background(), service, deployment)
+	uc.launch(ctx, service, deployment)
 
 	return deployment, nil
 }
 
+// launch marks the service as deploying before handing the deployment to a
+// goroutine, so a second request or scheduler tick sees it straight away
+func (uc *UseCase) launch(ctx context.Context, service *entity.Service, deployment *entity.Deployment) {
+	uc.serviceRepo.UpdateStatus(ctx, service.ID, entity.ServiceStatusDeploying)
+	service.Status = entity.ServiceStatusDeploying
+
+	go uc.executeDeployment(context.Background(), service, deployment)
+}
+
 func (uc *UseCase) executeDeployment(ctx context.Context, service *entity.Service, deployment *entity.Deployment) {
 	var deployErr error
+	var logs bytes.Buffer
 
 	defer func() {
 		now := time.Now()
 		deployment.FinishedAt = &now
 
+		if deployErr != nil {
+			logs.WriteString(deployErr.Error())
+		}
+		if logs.Len() > 0 {
+			output := logs.String()
+			deployment.Logs = &output
+		}
+
 		if deployErr != nil {
 			deployment.Status = entity.DeploymentStatusFailed
-			logs := deployErr.Error()
-			deployment.Logs = &logs
 			uc.serviceRepo.UpdateStatus(ctx, service.ID, entity.ServiceStatusFailed)
 		} else {
 			deployment.Status = entity.DeploymentStatusSuccess
@@ -115,12 +232,54 @@ func (uc *UseCase) executeDeployment(ctx context.Context, service *entity.Servic
 		}
 
 		uc.deploymentRepo.Update(ctx, deployment)
+		uc.routes.Refresh()
+
+		if deployErr == nil {
+			uc.redeployDependents(ctx, service)
+		}
 	}()
 
+	var image string
+	if service.DeployType.BuildsFromSource() {
+		// Build before touching the running container so a failed build
+		// leaves the current version serving
+		tag, err := uc.buildImage(ctx, service, deployment, &logs)
+		if err != nil {
+			deployErr = fmt.Errorf("build failed: %w", err)
+			return
+		}
+		image = tag
+	} else {
+		// Pull the image
+		if err := uc.containerManager.PullImage(ctx, *service.Image); err != nil {
+			deployErr = fmt.Errorf("failed to pull image: %w", err)
+			return
+		}
+
+		// Pin the container to the digest the tag resolved to, so a later push to
+		// the same tag cannot change what a restart or reschedule runs
+		image = *service.Image
+		if digest, err := uc.containerManager.GetImageDigest(ctx, image); err == nil {
+			deployment.ImageDigest = &digest
+			image = entity.PinnedImageRef(image, digest)
+			if err := uc.serviceRepo.UpdateImageDigest(ctx, service.ID, &digest); err != nil {
+				deployErr = fmt.Errorf("failed to record image digest: %w", err)
+				return
+			}
+		}
+	}
+
+	// Resolve env before touching the running container, so a reference to a
+	// missing service leaves the current version serving
+	env, err := uc.containerEnv(ctx, service)
+	if err != nil {
+		deployErr = err
+		return
+	}
+
 	// Update status to deploying
 	deployment.Status = entity.DeploymentStatusDeploying
 	uc.deploymentRepo.Update(ctx, deployment)
-	uc.serviceRepo.UpdateStatus(ctx, service.ID, entity.ServiceStatusDeploying)
 
 	// Stop and remove existing container if exists
 	if service.ContainerID != nil && *service.ContainerID != "" {
@@ -128,54 +287,69 @@ func (uc *UseCase) executeDeployment(ctx context.Context, service *entity.Servic
 		_ = uc.containerManager.RemoveContainer(ctx, *service.ContainerID, true)
 	}
 
-	// Pull the image
-	if err := uc.containerManager.PullImage(ctx, *service.Image); err != nil {
-		deployErr = fmt.Errorf("failed to pull image: %w", err)
-		return
-	}
-
-	// Fetch domains for the service
-	domains, err := uc.domainRepo.ListByServiceID(ctx, service.ID)
+	portMappings, err := uc.portRepo.ListByServiceID(ctx, service.ID)
 	if err != nil {
-		deployErr = fmt.Errorf("failed to fetch domains: %w", err)
+		deployErr = fmt.Errorf("failed to fetch port mappings: %w", err)
 		return
 	}
 
 	// Build container config
-	containerName := fmt.Sprintf("podoru-%s", service.Slug)
-
 	var memLimit *int64
 	if service.MemoryLimit != nil {
 		mem := int64(*service.MemoryLimit) * 1024 * 1024 // Convert MB to bytes
 		memLimit = &mem
 	}
 
-	// Build labels with Traefik configuration
-	labels := uc.buildContainerLabels(service, domains)
+	// Routing is served to Traefik by Podoru, so labels only identify the container
+	labels := map[string]string{
+		"podoru.service.id": service.ID.String(),
+		"podoru.project.id": service.ProjectID.String(),
+		"podoru.managed":    "true",
+		envHashLabel:        envHash(env),
+	}
 
-	// Determine network
-	var networkID string
-	if uc.traefikConfig != nil && uc.traefikConfig.Enabled && len(domains) > 0 {
-		networkID = uc.traefikConfig.Network
+	// Join the project network under the service slug so the other services
+	// in the project can reach it by name
+	project, err := uc.projectRepo.GetByID(ctx, service.ProjectID)
+	if err != nil {
+		deployErr = fmt.Errorf("failed to fetch project: %w", err)
+		return
+	}
+	if project == nil {
+		deployErr = ErrProjectNotFound
+		return
+	}
+	projectNetwork, err := uc.projectNetwork(ctx, project)
+	if err != nil {
+		deployErr = fmt.Errorf("failed to prepare project network: %w", err)
+		return
+	}
+
+	// Join the Traefik network even without domains, so domains added later
+	// route without a redeploy
+	var traefikNetwork string
+	if uc.traefikConfig != nil && uc.traefikConfig.Enabled {
+		traefikNetwork = uc.traefikConfig.Network
 
 		// Validate network exists before deployment
-		if err := uc.containerManager.ValidateNetwork(ctx, networkID); err != nil {
-			deployErr = fmt.Errorf("traefik network '%s' not found - ensure Traefik is running: %w", networkID, err)
+		if err := uc.containerManager.ValidateNetwork(ctx, traefikNetwork); err != nil {
+			deployErr = fmt.Errorf("traefik network '%s' not found - ensure Traefik is running: %w", traefikNetwork, err)
 			return
 		}
 	}
 
 	config := &domainDocker.ContainerConfig{
-		Name:          containerName,
-		Image:         *service.Image,
-		Env:           nil, // TODO: decrypt env vars
-		PortMappings:  nil, // TODO: add port mappings
+		Name:          service.ContainerName(),
+		Image:         image,
+		Env:           env,
+		PortMappings:  portMappings,
 		Volumes:       nil, // TODO: add volumes
 		CPULimit:      service.CPULimit,
 		MemoryLimit:   memLimit,
 		RestartPolicy: service.RestartPolicy,
 		Labels:        labels,
-		NetworkID:     networkID,
+		NetworkID:     *projectNetwork.DockerNetworkID,
+		Aliases:       []string{service.Slug},
 	}
 
 	// Create container
@@ -191,6 +365,18 @@ func (uc *UseCase) executeDeployment(ctx context.Context, service *entity.Servic
 		return
 	}
 
+	// Join custom networks and the Traefik network before the first start
+	if err := uc.connectNetworks(ctx, service, containerID); err != nil {
+		deployErr = fmt.Errorf("failed to connect networks: %w", err)
+		return
+	}
+	if traefikNetwork != "" {
+		if err := uc.containerManager.ConnectNetwork(ctx, traefikNetwork, containerID, nil); err != nil {
+			deployErr = err
+			return
+		}
+	}
+
 	// Start container
 	if err := uc.containerManager.StartContainer(ctx, containerID); err != nil {
 		deployErr = fmt.Errorf("failed to start container: %w", err)
@@ -213,7 +399,12 @@ func (uc *UseCase) Start(ctx context.Context, userID, serviceID uuid.UUID) error
 		return err
 	}
 
-	return uc.serviceRepo.UpdateStatus(ctx, serviceID, entity.ServiceStatusRunning)
+	if err := uc.serviceRepo.UpdateStatus(ctx, serviceID, entity.ServiceStatusRunning); err != nil {
+		return err
+	}
+	uc.routes.Refresh()
+
+	return nil
 }
 
 // Stop stops a running service
@@ -231,7 +422,12 @@ func (uc *UseCase) Stop(ctx context.Context, userID, serviceID uuid.UUID) error
 		return err
 	}
 
-	return uc.serviceRepo.UpdateStatus(ctx, serviceID, entity.ServiceStatusStopped)
+	if err := uc.serviceRepo.UpdateStatus(ctx, serviceID, entity.ServiceStatusStopped); err != nil {
+		return err
+	}
+	uc.routes.Refresh()
+
+	return nil
 }
 
 // Restart restarts a running service
@@ -284,81 +480,47 @@ func (uc *UseCase) GetLogs(ctx context.Context, userID, serviceID uuid.UUID, tai
 	return string(buf[:n]), nil
 }
 
-// buildContainerLabels builds Docker labels including Traefik configuration
-func (uc *UseCase) buildContainerLabels(service *entity.Service, domains []entity.Domain) map[string]string {
-	labels := map[string]string{
-		"podoru.service.id": service.ID.String(),
-		"podoru.project.id": service.ProjectID.String(),
-		"podoru.managed":    "true",
-	}
-
-	// Add Traefik labels if enabled and domains exist
-	if uc.traefikConfig == nil || !uc.traefikConfig.Enabled || len(domains) == 0 {
-		return labels
+// CheckImageUpdate compares the digest a service is running against the
+// digest its image tag currently resolves to in the registry
+func (uc *UseCase) CheckImageUpdate(ctx context.Context, userID, serviceID uuid.UUID) (*entity.ImageStatus, error) {
+	service, err := uc.validateAccess(ctx, userID, serviceID)
+	if err != nil {
+		return nil, err
 	}
 
-	routerName := fmt.Sprintf("podoru-%s", service.Slug)
-
-	// Enable Traefik for this container
-	labels["traefik.enable"] = "true"
-
-	// Build Host rules for all domains
-	var hostRules []string
-	for _, d := range domains {
-		hostRules = append(hostRules, fmt.Sprintf("Host(`%s`)", d.Domain))
+	if service.DeployType != entity.DeployTypeImage || service.Image == nil || *service.Image == "" {
+		return nil, ErrNotImageService
 	}
-	hostRule := strings.Join(hostRules, " || ")
 
-	// HTTP router
-	labels[fmt.Sprintf("traefik.http.routers.%s.rule", routerName)] = hostRule
-	labels[fmt.Sprintf("traefik.http.routers.%s.entrypoints", routerName)] = "web"
+	return uc.checkImage(ctx, service)
+}
 
-	// Check if any domain has SSL enabled
-	hasSSL := false
-	hasAutoSSL := false
-	for _, d := range domains {
-		if d.SSLEnabled {
-			hasSSL = true
-			if d.SSLAuto {
-				hasAutoSSL = true
-				break
-			}
-		}
+func (uc *UseCase) checkImage(ctx context.Context, service *entity.Service) (*entity.ImageStatus, error) {
+	latest, err := uc.containerManager.GetRemoteImageDigest(ctx, *service.Image)
+	if err != nil {
+		return nil, err
 	}
 
-	// HTTPS router with SSL
-	if hasSSL {
-		httpsRouterName := routerName + "-secure"
-		labels[fmt.Sprintf("traefik.http.routers.%s.rule", httpsRouterName)] = hostRule
-		labels[fmt.Sprintf("traefik.http.routers.%s.entrypoints", httpsRouterName)] = "websecure"
-		labels[fmt.Sprintf("traefik.http.routers.%s.tls", httpsRouterName)] = "true"
-
-		if hasAutoSSL {
-			labels[fmt.Sprintf("traefik.http.routers.%s.tls.certresolver", httpsRouterName)] = "letsencrypt"
-		}
-
-		// HTTP to HTTPS redirect
-		labels[fmt.Sprintf("traefik.http.routers.%s.middlewares", routerName)] = routerName + "-redirect"
-		labels[fmt.Sprintf("traefik.http.middlewares.%s-redirect.redirectscheme.scheme", routerName)] = "https"
-		labels[fmt.Sprintf("traefik.http.middlewares.%s-redirect.redirectscheme.permanent", routerName)] = "true"
-	}
+	// A service that was never deployed has nothing to compare against
+	updateAvailable := service.ImageDigest != nil && *service.ImageDigest != latest
+	checkedAt := time.Now()
 
-	// Service configuration - use first port mapping or default to 80
-	serviceName := routerName
-	labels[fmt.Sprintf("traefik.http.routers.%s.service", routerName)] = serviceName
-	if hasSSL {
-		labels[fmt.Sprintf("traefik.http.routers.%s-secure.service", routerName)] = serviceName
+	if err := uc.serviceRepo.UpdateImageCheck(ctx, service.ID, &latest, updateAvailable, checkedAt); err != nil {
+		return nil, err
 	}
 
-	// Default to port 80 if no health check path specified
-	port := "80"
-	if service.HealthCheckPath != nil && *service.HealthCheckPath != "" {
-		// Use port 80 as default, can be enhanced to use port mappings
-		port = "80"
-	}
-	labels[fmt.Sprintf("traefik.http.services.%s.loadbalancer.server.port", serviceName)] = port
+	service.LatestImageDigest = &latest
+	service.ImageUpdateAvailable = updateAvailable
+	service.ImageCheckedAt = &checkedAt
 
-	return labels
+	return &entity.ImageStatus{
+		ServiceID:            service.ID,
+		Image:                *service.Image,
+		CurrentDigest:        service.ImageDigest,
+		LatestDigest:         &latest,
+		ImageUpdateAvailable: updateAvailable,
+		CheckedAt:            &checkedAt,
+	}, nil
 }
 
 // Destroy stops and removes the container for a service (used before deletion)
@@ -381,11 +543,41 @@ func (uc *UseCase) Destroy(ctx context.Context, userID, serviceID uuid.UUID) err
 		return fmt.Errorf("failed to remove container: %w", err)
 	}
 
+	if err := uc.serviceRepo.UpdateContainerID(ctx, service.ID, nil); err != nil {
+		return err
+	}
+	uc.routes.Refresh()
+
 	return nil
 }
 
 // Helper methods
 
+// authorizeWindowOverride allows skipping the maintenance window only to team
+// admins and owners signed in themselves, never to API or deploy tokens
+func (uc *UseCase) authorizeWindowOverride(ctx context.Context, userID uuid.UUID, service *entity.Service) error {
+	if deploytoken.TokenFrom(ctx) != nil || apitoken.TokenFrom(ctx) != nil {
+		return ErrWindowOverrideDenied
+	}
+
+	project, err := uc.projectRepo.GetByID(ctx, service.ProjectID)
+	if err != nil {
+		return err
+	}
+	if project == nil {
+		return ErrProjectNotFound
+	}
+
+	member, err := uc.teamMemberRepo.GetByTeamAndUser(ctx, project.TeamID, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil || member.Role == entity.TeamRoleMember {
+		return ErrWindowOverrideDenied
+	}
+	return nil
+}
+
 func (uc *UseCase) validateAccess(ctx context.Context, userID, serviceID uuid.UUID) (*entity.Service, error) {
 	service, err := uc.serviceRepo.GetByID(ctx, serviceID)
 	if err != nil {
@@ -395,6 +587,14 @@ func (uc *UseCase) validateAccess(ctx context.Context, userID, serviceID uuid.UU
 		return nil, ErrServiceNotFound
 	}
 
+	// A deploy token stands in for a user, within its own project
+	if token := deploytoken.TokenFrom(ctx); token != nil {
+		if service.ProjectID != token.ProjectID {
+			return nil, ErrServiceNotFound
+		}
+		return service, nil
+	}
+
 	project, err := uc.projectRepo.GetByID(ctx, service.ProjectID)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,238 @@
+package deployment_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/config"
+	"github.com/podoru/spinner-podoru/internal/mocks"
+	"github.com/podoru/spinner-podoru/internal/usecase/apitoken"
+	"github.com/podoru/spinner-podoru/internal/usecase/deployment"
+	"github.com/podoru/spinner-podoru/internal/usecase/deploytoken"
+)
+
+func TestCheckImageUpdate(t *testing.T) {
+	image := "registry.example.com:5000/team/api:v2"
+	running := "sha256:running"
+
+	tests := []struct {
+		name            string
+		deployType      entity.DeployType
+		image           *string
+		currentDigest   *string
+		latestDigest    string
+		member          bool
+		wantErr         error
+		updateAvailable bool
+	}{
+		{name: "never deployed", deployType: entity.DeployTypeImage, image: &image, latestDigest: "sha256:new", member: true},
+		{name: "up to date", deployType: entity.DeployTypeImage, image: &image, currentDigest: &running, latestDigest: running, member: true},
+		{name: "update available", deployType: entity.DeployTypeImage, image: &image, currentDigest: &running, latestDigest: "sha256:new", member: true, updateAvailable: true},
+		{name: "not an image service", deployType: entity.DeployTypeDockerfile, member: true, wantErr: deployment.ErrNotImageService},
+		{name: "not a team member", deployType: entity.DeployTypeImage, image: &image, wantErr: deployment.ErrNotTeamMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			userID := uuid.New()
+			project := &entity.Project{ID: uuid.New(), TeamID: uuid.New()}
+			svc := &entity.Service{
+				ID:          uuid.New(),
+				ProjectID:   project.ID,
+				DeployType:  tt.deployType,
+				Image:       tt.image,
+				ImageDigest: tt.currentDigest,
+			}
+
+			var stored *string
+			var storedUpdate bool
+			serviceRepo := &mocks.MockServiceRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+					return svc, nil
+				},
+				UpdateImageCheckFunc: func(ctx context.Context, id uuid.UUID, latestDigest *string, updateAvailable bool, checkedAt time.Time) error {
+					stored = latestDigest
+					storedUpdate = updateAvailable
+					return nil
+				},
+			}
+
+			projectRepo := &mocks.MockProjectRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+					return project, nil
+				},
+			}
+
+			teamMemberRepo := &mocks.MockTeamMemberRepository{
+				GetByTeamAndUserFunc: func(ctx context.Context, teamID, uid uuid.UUID) (*entity.TeamMember, error) {
+					if !tt.member {
+						return nil, nil
+					}
+					return &entity.TeamMember{TeamID: teamID, UserID: uid, Role: entity.TeamRoleMember}, nil
+				},
+			}
+
+			containerManager := &mocks.MockContainerManager{
+				GetRemoteImageDigestFunc: func(ctx context.Context, imageName string) (string, error) {
+					if imageName != image {
+						t.Errorf("expected digest lookup for %q, got %q", image, imageName)
+					}
+					return tt.latestDigest, nil
+				},
+			}
+
+			uc := deployment.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, nil, nil, nil, containerManager, nil, nil,
+				&config.DockerConfig{}, &config.TraefikConfig{}, &config.BuildConfig{}, nil, nil, nil, &config.SecretsConfig{})
+
+			status, err := uc.CheckImageUpdate(ctx, userID, svc.ID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				if stored != nil {
+					t.Error("expected no image check to be stored")
+				}
+				return
+			}
+
+			if status.ImageUpdateAvailable != tt.updateAvailable {
+				t.Errorf("expected update available %v, got %v", tt.updateAvailable, status.ImageUpdateAvailable)
+			}
+			if stored == nil || *stored != tt.latestDigest || storedUpdate != tt.updateAvailable {
+				t.Errorf("expected the check to be stored with digest %s", tt.latestDigest)
+			}
+		})
+	}
+}
+
+func TestDeploy_IgnoreMaintenanceWindow(t *testing.T) {
+	image := "nginx:1.27"
+	project := &entity.Project{
+		ID:                uuid.New(),
+		TeamID:            uuid.New(),
+		MaintenanceWindow: &entity.MaintenanceWindow{Days: []string{"sun"}, Start: "02:00", End: "03:00"},
+	}
+
+	tests := []struct {
+		name    string
+		role    entity.TeamRole
+		withCtx func(ctx context.Context) context.Context
+		wantErr error
+	}{
+		// Passing the gate reaches the in-progress check, which stops the
+		// deployment before anything is recorded
+		{name: "owner", role: entity.TeamRoleOwner, wantErr: deployment.ErrAlreadyDeploying},
+		{name: "admin", role: entity.TeamRoleAdmin, wantErr: deployment.ErrAlreadyDeploying},
+		{name: "member", role: entity.TeamRoleMember, wantErr: deployment.ErrWindowOverrideDenied},
+		{
+			name: "api token of an owner",
+			role: entity.TeamRoleOwner,
+			withCtx: func(ctx context.Context) context.Context {
+				return apitoken.WithToken(ctx, &entity.APIToken{ID: uuid.New()})
+			},
+			wantErr: deployment.ErrWindowOverrideDenied,
+		},
+		{
+			name: "deploy token",
+			role: entity.TeamRoleOwner,
+			withCtx: func(ctx context.Context) context.Context {
+				return deploytoken.WithToken(ctx, &entity.DeployToken{ID: uuid.New(), ProjectID: project.ID})
+			},
+			wantErr: deployment.ErrWindowOverrideDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.withCtx != nil {
+				ctx = tt.withCtx(ctx)
+			}
+			userID := uuid.New()
+			svc := &entity.Service{
+				ID:         uuid.New(),
+				ProjectID:  project.ID,
+				DeployType: entity.DeployTypeImage,
+				Image:      &image,
+				Status:     entity.ServiceStatusDeploying,
+			}
+
+			serviceRepo := &mocks.MockServiceRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+					return svc, nil
+				},
+			}
+
+			projectRepo := &mocks.MockProjectRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+					return project, nil
+				},
+			}
+
+			teamMemberRepo := &mocks.MockTeamMemberRepository{
+				GetByTeamAndUserFunc: func(ctx context.Context, teamID, uid uuid.UUID) (*entity.TeamMember, error) {
+					return &entity.TeamMember{TeamID: teamID, UserID: uid, Role: tt.role}, nil
+				},
+			}
+
+			uc := deployment.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, nil, nil, nil, nil, nil, nil,
+				&config.DockerConfig{}, &config.TraefikConfig{}, &config.BuildConfig{}, nil, nil, nil, &config.SecretsConfig{})
+
+			_, err := uc.Deploy(ctx, userID, svc.ID, &entity.DeployOptions{IgnoreMaintenanceWindow: true})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestHandleDeployHook_SkipsServicesThatCannotDeploy(t *testing.T) {
+	image := "nginx:1.27"
+	// The window opens two days from now, so the deployable service is
+	// scheduled rather than run
+	day := strings.ToLower(time.Now().UTC().AddDate(0, 0, 2).Weekday().String()[:3])
+	project := &entity.Project{
+		ID:                uuid.New(),
+		TeamID:            uuid.New(),
+		MaintenanceWindow: &entity.MaintenanceWindow{Days: []string{day}, Start: "02:00", End: "03:00"},
+	}
+	web := entity.Service{ID: uuid.New(), ProjectID: project.ID, Slug: "web", DeployType: entity.DeployTypeImage, Image: &image}
+	services := []entity.Service{
+		{ID: uuid.New(), ProjectID: project.ID, Slug: "stack", DeployType: entity.DeployTypeCompose},
+		{ID: uuid.New(), ProjectID: project.ID, Slug: "worker", DeployType: entity.DeployTypeImage},
+		web,
+	}
+
+	serviceRepo := &mocks.MockServiceRepository{
+		ListByProjectIDFunc: func(ctx context.Context, projectID uuid.UUID) ([]entity.Service, error) {
+			return services, nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return project, nil
+		},
+	}
+
+	deploymentRepo := &mocks.MockDeploymentRepository{}
+
+	uc := deployment.NewUseCase(serviceRepo, projectRepo, &mocks.MockTeamMemberRepository{}, deploymentRepo, nil, nil, nil, nil, nil,
+		&config.DockerConfig{}, &config.TraefikConfig{}, &config.BuildConfig{}, nil, nil, nil, &config.SecretsConfig{})
+
+	ctx := deploytoken.WithToken(context.Background(), &entity.DeployToken{ID: uuid.New(), ProjectID: project.ID})
+	deployments, err := uc.HandleDeployHook(ctx, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(deployments) != 1 || deployments[0].ServiceID != web.ID {
+		t.Errorf("expected only %s to be deployed, got %+v", web.Slug, deployments)
+	}
+}
@@ -0,0 +1,233 @@
+package deployment
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	domainSecret "github.com/podoru/spinner-podoru/internal/domain/secret"
+)
+
+// envHashLabel records the resolved env a container was created with, so
+// dependents are only redeployed when the values they reference change
+const envHashLabel = "podoru.env.hash"
+
+var (
+	ErrUnresolvedReference         = errors.New("unresolved service reference")
+	ErrSecretProviderNotConfigured = errors.New("secret provider not configured")
+)
+
+// decryptVars opens a key/value map sealed by the service use case
+func (uc *UseCase) decryptVars(data []byte) (map[string]string, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	decrypted, err := uc.encryptor.Decrypt(data)
+	if err != nil {
+		return nil, err
+	}
+	var vars map[string]string
+	if err := json.Unmarshal(decrypted, &vars); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// containerEnv merges the service's env vars over its env groups and
+// resolves the ${{service.VAR}} references in them against the services of
+// its project, then the secret references. The result is sorted so the same
+// values always hash the same.
+func (uc *UseCase) containerEnv(ctx context.Context, service *entity.Service) ([]string, error) {
+	vars, err := uc.envGroups.ServiceEnv(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := uc.projectRepo.GetByID(ctx, service.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, ErrProjectNotFound
+	}
+
+	// Exports of each referenced service, looked up once per deployment
+	exports := make(map[string]map[string]string)
+	var lookupErr error
+	lookup := func(ref entity.ServiceReference) (string, bool) {
+		if _, ok := exports[ref.Service]; !ok {
+			e, err := uc.serviceExports(ctx, project, ref.Service)
+			if err != nil {
+				lookupErr = err
+				return "", false
+			}
+			exports[ref.Service] = e
+		}
+		v, ok := exports[ref.Service][ref.Variable]
+		return v, ok
+	}
+
+	env := make([]string, 0, len(vars))
+	for key, value := range vars {
+		resolved, unresolved, ok := entity.ResolveServiceReferences(value, lookup)
+		if lookupErr != nil {
+			return nil, lookupErr
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: %s in %s", ErrUnresolvedReference, unresolved, key)
+		}
+		resolved, err := uc.resolveSecret(ctx, project, key, resolved)
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, key+"="+resolved)
+	}
+	slices.Sort(env)
+	return env, nil
+}
+
+// serviceExports returns what a service exports to its project: HOST, its
+// alias on the project network, PORT, its first TCP port, and the variables
+// it declares. Declared values may use ${VAR} from the service's env.
+// A missing service exports nothing.
+func (uc *UseCase) serviceExports(ctx context.Context, project *entity.Project, slug string) (map[string]string, error) {
+	s, err := uc.serviceRepo.GetByProjectAndSlug(ctx, project.ID, slug)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, nil
+	}
+
+	exports := map[string]string{entity.ExportHost: s.Slug}
+	if port, err := uc.servicePort(ctx, s); err != nil {
+		return nil, err
+	} else if port > 0 {
+		exports[entity.ExportPort] = strconv.Itoa(port)
+	}
+
+	declared, err := uc.decryptVars(s.ExportsEncrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt exports of %s: %w", slug, err)
+	}
+	if len(declared) > 0 {
+		env, err := uc.envGroups.ServiceEnv(ctx, s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve env vars of %s: %w", slug, err)
+		}
+		for key, value := range env {
+			if env[key], err = uc.resolveSecret(ctx, project, key, value); err != nil {
+				return nil, fmt.Errorf("failed to resolve env vars of %s: %w", slug, err)
+			}
+		}
+		for name, value := range declared {
+			exports[name] = entity.ExpandEnvReferences(value, env)
+		}
+	}
+	return exports, nil
+}
+
+// resolveSecret returns the secret an env value such as
+// "${{vault:secret/data/app#db_password}}" refers to. Other values are
+// returned as they are. Vault and file references must lie in the project's
+// scope, so one team cannot read what the providers hold for another.
+func (uc *UseCase) resolveSecret(ctx context.Context, project *entity.Project, key, value string) (string, error) {
+	scheme, ref, ok := domainSecret.ParseReference(value)
+	if !ok {
+		return value, nil
+	}
+	provider, ok := uc.secretProviders[scheme]
+	if !ok {
+		return "", fmt.Errorf("%w: %s in %s", ErrSecretProviderNotConfigured, scheme, key)
+	}
+	if template, scoped := uc.secretScope(scheme); scoped {
+		if scope := domainSecret.Scope(template, project.TeamID, project.ID); !domainSecret.InScope(ref, scope) {
+			return "", fmt.Errorf("%w: %s in %s is not below %s", domainSecret.ErrOutOfScope, ref, key, scope)
+		}
+	}
+	secret, err := provider.Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", key, err)
+	}
+	return secret, nil
+}
+
+// secretScope returns the scope template of a scheme's references, and false
+// for sealed values, which hold the secret themselves
+func (uc *UseCase) secretScope(scheme string) (string, bool) {
+	switch scheme {
+	case domainSecret.SchemeVault:
+		return uc.secretsConfig.VaultPathPrefix, true
+	case domainSecret.SchemeFile:
+		return uc.secretsConfig.FilesPrefix, true
+	}
+	return "", false
+}
+
+// servicePort is the port other services reach a service on: its first TCP
+// port mapping, otherwise the lowest port its container exposes, or 0
+func (uc *UseCase) servicePort(ctx context.Context, s *entity.Service) (int, error) {
+	portMappings, err := uc.portRepo.ListByServiceID(ctx, s.ID)
+	if err != nil {
+		return 0, err
+	}
+	for _, pm := range portMappings {
+		if pm.Protocol == "tcp" {
+			return pm.ContainerPort, nil
+		}
+	}
+
+	if s.ContainerID != nil && *s.ContainerID != "" {
+		if info, err := uc.containerManager.InspectContainer(ctx, *s.ContainerID); err == nil && len(info.ExposedPorts) > 0 {
+			return info.ExposedPorts[0], nil
+		}
+	}
+	return 0, nil
+}
+
+// redeployDependents redeploys the running services of the project that
+// reference the service, when the values they resolve to have changed
+func (uc *UseCase) redeployDependents(ctx context.Context, service *entity.Service) {
+	services, err := uc.serviceRepo.ListByProjectID(ctx, service.ProjectID)
+	if err != nil {
+		return
+	}
+
+	for i := range services {
+		dependent := &services[i]
+		if dependent.ID == service.ID || dependent.Status != entity.ServiceStatusRunning ||
+			dependent.ContainerID == nil || *dependent.ContainerID == "" {
+			continue
+		}
+
+		vars, err := uc.envGroups.ServiceEnv(ctx, dependent)
+		if err != nil || !entity.ReferencesService(vars, service.Slug) {
+			continue
+		}
+		env, err := uc.containerEnv(ctx, dependent)
+		if err != nil {
+			continue
+		}
+		info, err := uc.containerManager.InspectContainer(ctx, *dependent.ContainerID)
+		if err == nil && info.Labels[envHashLabel] == envHash(env) {
+			continue
+		}
+
+		_, _ = uc.startDeployment(ctx, dependent, nil, &entity.DeployOptions{})
+	}
+}
+
+func envHash(env []string) string {
+	h := sha256.New()
+	for _, kv := range env {
+		h.Write([]byte(kv))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
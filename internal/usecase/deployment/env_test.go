@@ -0,0 +1,318 @@
+package deployment_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	domainDocker "github.com/podoru/spinner-podoru/internal/domain/docker"
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	domainSecret "github.com/podoru/spinner-podoru/internal/domain/secret"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/config"
+	"github.com/podoru/spinner-podoru/internal/mocks"
+	"github.com/podoru/spinner-podoru/internal/usecase/deployment"
+	"github.com/podoru/spinner-podoru/internal/usecase/envgroup"
+	"github.com/podoru/spinner-podoru/pkg/crypto"
+)
+
+func TestContainerEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "plain values",
+			env:  map[string]string{"MODE": "production", "DEBUG": "false"},
+			want: []string{"DEBUG=false", "MODE=production"},
+		},
+		{
+			name: "builtin exports",
+			env:  map[string]string{"DB_ADDR": "${{db.HOST}}:${{db.PORT}}"},
+			want: []string{"DB_ADDR=db:5432"},
+		},
+		{
+			name: "declared export expanded against the exporter's env",
+			env:  map[string]string{"DATABASE_URL": "${{db.URL}}"},
+			want: []string{"DATABASE_URL=postgres://app:s3cret@db:5432/app"},
+		},
+		{
+			name:    "unknown variable",
+			env:     map[string]string{"DB_USER": "${{db.USER}}"},
+			wantErr: deployment.ErrUnresolvedReference,
+		},
+		{
+			name:    "missing service",
+			env:     map[string]string{"CACHE": "${{cache.HOST}}"},
+			wantErr: deployment.ErrUnresolvedReference,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			encryptor, err := crypto.NewEncryptor("test-key")
+			if err != nil {
+				t.Fatalf("failed to create encryptor: %v", err)
+			}
+
+			projectID := uuid.New()
+			exports, _ := json.Marshal(map[string]string{"URL": "postgres://app:${PASSWORD}@db:5432/app"})
+			exportsEncrypted, _ := encryptor.Encrypt(exports)
+			db := &entity.Service{ID: uuid.New(), ProjectID: projectID, Slug: "db", ExportsEncrypted: exportsEncrypted}
+			api := &entity.Service{ID: uuid.New(), ProjectID: projectID, Slug: "api"}
+
+			envs := map[uuid.UUID]map[string]string{
+				db.ID:  {"PASSWORD": "s3cret"},
+				api.ID: tt.env,
+			}
+
+			serviceRepo := &mocks.MockServiceRepository{
+				GetByProjectAndSlugFunc: func(ctx context.Context, id uuid.UUID, slug string) (*entity.Service, error) {
+					if id == projectID && slug == db.Slug {
+						return db, nil
+					}
+					return nil, nil
+				},
+			}
+
+			portRepo := &mocks.MockPortMappingRepository{
+				ListByServiceIDFunc: func(ctx context.Context, id uuid.UUID) ([]entity.PortMapping, error) {
+					if id == db.ID {
+						return []entity.PortMapping{{ContainerPort: 5432, Protocol: "tcp"}}, nil
+					}
+					return nil, nil
+				},
+			}
+
+			envVarRepo := &mocks.MockServiceEnvVarRepository{
+				ListByServiceIDFunc: func(ctx context.Context, id uuid.UUID) ([]entity.ServiceEnvVar, error) {
+					var vars []entity.ServiceEnvVar
+					for key, value := range envs[id] {
+						sealed, _ := encryptor.Encrypt([]byte(value))
+						vars = append(vars, entity.ServiceEnvVar{ServiceID: id, Key: key, ValueEncrypted: sealed})
+					}
+					return vars, nil
+				},
+			}
+
+			projectRepo := &mocks.MockProjectRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+					return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+				},
+			}
+
+			envGroups := envgroup.NewUseCase(&mocks.MockEnvGroupRepository{}, envVarRepo, nil, nil, nil, encryptor)
+			uc := deployment.NewUseCase(serviceRepo, projectRepo, nil, nil, portRepo, nil, &mocks.MockContainerManager{}, nil, encryptor,
+				&config.DockerConfig{}, &config.TraefikConfig{}, &config.BuildConfig{}, nil, envGroups, nil, &config.SecretsConfig{})
+
+			env, err := uc.ContainerEnv(ctx, api)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if !slices.Equal(env, tt.want) {
+				t.Errorf("expected env %v, got %v", tt.want, env)
+			}
+		})
+	}
+}
+
+func TestContainerEnv_Secrets(t *testing.T) {
+	teamID := uuid.New()
+	projectID := uuid.New()
+	otherID := uuid.New()
+	scope := "secret/data/podoru/" + teamID.String() + "/" + projectID.String()
+	files := teamID.String() + "/" + projectID.String()
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantRef string
+		wantErr error
+	}{
+		{name: "vault in scope", value: "${{vault:" + scope + "/db#password}}", want: "DB=s3cret", wantRef: scope + "/db#password"},
+		{name: "file in scope", value: "${{ file:" + files + "/db_password }}", want: "DB=s3cret", wantRef: files + "/db_password"},
+		{name: "sealed value", value: "${{encrypted:AQID}}", want: "DB=s3cret", wantRef: "AQID"},
+		{name: "plain file url", value: "file:./dev.db", want: "DB=file:./dev.db"},
+		{name: "unmarked vault path", value: "vault:" + scope + "/db#password", want: "DB=vault:" + scope + "/db#password"},
+		{name: "vault of another project", value: "${{vault:secret/data/podoru/" + teamID.String() + "/" + otherID.String() + "/db#password}}", wantErr: domainSecret.ErrOutOfScope},
+		{name: "vault token lookup", value: "${{vault:auth/token/lookup-self#id}}", wantErr: domainSecret.ErrOutOfScope},
+		{name: "vault system backend", value: "${{vault:sys/mounts#type}}", wantErr: domainSecret.ErrOutOfScope},
+		{name: "vault path leaving the scope", value: "${{vault:" + scope + "/../" + otherID.String() + "/db#password}}", wantErr: domainSecret.ErrOutOfScope},
+		{name: "vault scope itself", value: "${{vault:" + scope + "#password}}", wantErr: domainSecret.ErrOutOfScope},
+		{name: "file of another team", value: "${{file:" + otherID.String() + "/" + projectID.String() + "/db_password}}", wantErr: domainSecret.ErrOutOfScope},
+		{name: "file at the top of the directory", value: "${{file:db_password}}", wantErr: domainSecret.ErrOutOfScope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			encryptor, err := crypto.NewEncryptor("test-key")
+			if err != nil {
+				t.Fatalf("failed to create encryptor: %v", err)
+			}
+
+			api := &entity.Service{ID: uuid.New(), ProjectID: projectID, Slug: "api"}
+
+			projectRepo := &mocks.MockProjectRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+					return &entity.Project{ID: id, TeamID: teamID}, nil
+				},
+			}
+
+			envVarRepo := &mocks.MockServiceEnvVarRepository{
+				ListByServiceIDFunc: func(ctx context.Context, id uuid.UUID) ([]entity.ServiceEnvVar, error) {
+					sealed, _ := encryptor.Encrypt([]byte(tt.value))
+					return []entity.ServiceEnvVar{{ServiceID: id, Key: "DB", ValueEncrypted: sealed}}, nil
+				},
+			}
+
+			var resolved string
+			var providers []domainSecret.Provider
+			for _, scheme := range []string{domainSecret.SchemeVault, domainSecret.SchemeFile, domainSecret.SchemeEncrypted} {
+				providers = append(providers, &mocks.MockSecretProvider{
+					SchemeName: scheme,
+					ResolveFunc: func(ctx context.Context, ref string) (string, error) {
+						resolved = ref
+						return "s3cret", nil
+					},
+				})
+			}
+
+			envGroups := envgroup.NewUseCase(&mocks.MockEnvGroupRepository{}, envVarRepo, nil, nil, nil, encryptor)
+			uc := deployment.NewUseCase(&mocks.MockServiceRepository{}, projectRepo, nil, nil, nil, nil, nil, nil, encryptor,
+				&config.DockerConfig{}, &config.TraefikConfig{}, &config.BuildConfig{}, nil, envGroups, providers,
+				&config.SecretsConfig{VaultPathPrefix: "secret/data/podoru/{team_id}/{project_id}", FilesPrefix: "{team_id}/{project_id}"})
+
+			env, err := uc.ContainerEnv(ctx, api)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if resolved != tt.wantRef {
+				t.Errorf("expected provider to resolve %q, got %q", tt.wantRef, resolved)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if len(env) != 1 || env[0] != tt.want {
+				t.Errorf("expected env [%s], got %v", tt.want, env)
+			}
+		})
+	}
+}
+
+func TestRedeployDependents(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       entity.ServiceStatus
+		env          map[string]string
+		sameHash     bool
+		wantRedeploy bool
+	}{
+		{name: "resolved value changed", status: entity.ServiceStatusRunning, env: map[string]string{"DB": "${{db.HOST}}"}, wantRedeploy: true},
+		{name: "resolved value unchanged", status: entity.ServiceStatusRunning, env: map[string]string{"DB": "${{db.HOST}}"}, sameHash: true},
+		{name: "no reference to the service", status: entity.ServiceStatusRunning, env: map[string]string{"DB": "db"}},
+		{name: "dependent not running", status: entity.ServiceStatusStopped, env: map[string]string{"DB": "${{db.HOST}}"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			encryptor, err := crypto.NewEncryptor("test-key")
+			if err != nil {
+				t.Fatalf("failed to create encryptor: %v", err)
+			}
+
+			projectID := uuid.New()
+			containerID := "api-container"
+			image := "api:latest"
+			db := &entity.Service{ID: uuid.New(), ProjectID: projectID, Slug: "db", Status: entity.ServiceStatusRunning}
+			api := entity.Service{
+				ID:          uuid.New(),
+				ProjectID:   projectID,
+				Slug:        "api",
+				DeployType:  entity.DeployTypeImage,
+				Image:       &image,
+				Status:      tt.status,
+				ContainerID: &containerID,
+			}
+
+			serviceRepo := &mocks.MockServiceRepository{
+				ListByProjectIDFunc: func(ctx context.Context, id uuid.UUID) ([]entity.Service, error) {
+					return []entity.Service{*db, api}, nil
+				},
+				GetByProjectAndSlugFunc: func(ctx context.Context, id uuid.UUID, slug string) (*entity.Service, error) {
+					if slug == db.Slug {
+						return db, nil
+					}
+					return nil, nil
+				},
+			}
+
+			projectRepo := &mocks.MockProjectRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+					return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+				},
+			}
+
+			// Failing to record the deployment stops the redeploy before
+			// anything runs in the background
+			var redeployed bool
+			deploymentRepo := &mocks.MockDeploymentRepository{
+				CreateFunc: func(ctx context.Context, d *entity.Deployment) error {
+					if d.ServiceID != api.ID {
+						t.Errorf("unexpected deployment of service %s", d.ServiceID)
+					}
+					redeployed = true
+					return errors.New("stop here")
+				},
+			}
+
+			envVarRepo := &mocks.MockServiceEnvVarRepository{
+				ListByServiceIDFunc: func(ctx context.Context, id uuid.UUID) ([]entity.ServiceEnvVar, error) {
+					if id != api.ID {
+						return nil, nil
+					}
+					var vars []entity.ServiceEnvVar
+					for key, value := range tt.env {
+						sealed, _ := encryptor.Encrypt([]byte(value))
+						vars = append(vars, entity.ServiceEnvVar{ServiceID: id, Key: key, ValueEncrypted: sealed})
+					}
+					return vars, nil
+				},
+			}
+
+			label := "stale"
+			if tt.sameHash {
+				label = deployment.EnvHash([]string{"DB=db"})
+			}
+			containerManager := &mocks.MockContainerManager{
+				InspectContainerFunc: func(ctx context.Context, id string) (*domainDocker.ContainerInfo, error) {
+					if id != containerID {
+						t.Errorf("unexpected inspect of container %s", id)
+					}
+					return &domainDocker.ContainerInfo{Labels: map[string]string{deployment.EnvHashLabel: label}}, nil
+				},
+			}
+
+			envGroups := envgroup.NewUseCase(&mocks.MockEnvGroupRepository{}, envVarRepo, nil, nil, nil, encryptor)
+			uc := deployment.NewUseCase(serviceRepo, projectRepo, nil, deploymentRepo, &mocks.MockPortMappingRepository{}, nil, containerManager, nil, encryptor,
+				&config.DockerConfig{}, &config.TraefikConfig{}, &config.BuildConfig{}, nil, envGroups, nil, &config.SecretsConfig{})
+
+			uc.RedeployDependents(ctx, db)
+
+			if redeployed != tt.wantRedeploy {
+				t.Errorf("expected redeploy %v, got %v", tt.wantRedeploy, redeployed)
+			}
+		})
+	}
+}
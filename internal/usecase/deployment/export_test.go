@@ -0,0 +1,22 @@
+package deployment
+
+import (
+	"context"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+// Env resolution runs inside the deployment goroutine; these expose it to
+// the external test package.
+
+func (uc *UseCase) ContainerEnv(ctx context.Context, service *entity.Service) ([]string, error) {
+	return uc.containerEnv(ctx, service)
+}
+
+func (uc *UseCase) RedeployDependents(ctx context.Context, service *entity.Service) {
+	uc.redeployDependents(ctx, service)
+}
+
+var EnvHash = envHash
+
+const EnvHashLabel = envHashLabel
@@ -0,0 +1,97 @@
+package deployment
+
+import (
+	"context"
+	"time"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/config"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/logger"
+)
+
+// ImageWatcher periodically checks image services for upstream changes to
+// their tag and redeploys the ones that opted into auto-update
+type ImageWatcher struct {
+	deploymentUseCase *UseCase
+	pollInterval      time.Duration
+	log               *logger.Logger
+}
+
+// NewImageWatcher creates a new image watcher
+func NewImageWatcher(deploymentUseCase *UseCase, cfg *config.ImageWatcherConfig, log *logger.Logger) *ImageWatcher {
+	return &ImageWatcher{
+		deploymentUseCase: deploymentUseCase,
+		pollInterval:      cfg.PollInterval,
+		log:               log,
+	}
+}
+
+// Run blocks until ctx is cancelled, checking due services on every tick.
+// Each service is only queried once its own auto_update_interval has elapsed.
+func (w *ImageWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.checkDue(ctx)
+		}
+	}
+}
+
+func (w *ImageWatcher) checkDue(ctx context.Context) {
+	uc := w.deploymentUseCase
+
+	services, err := uc.serviceRepo.ListByDeployType(ctx, entity.DeployTypeImage)
+	if err != nil {
+		w.log.Errorw("Failed to list image services", "error", err)
+		return
+	}
+
+	now := time.Now()
+	for i := range services {
+		service := &services[i]
+		if !service.NeedsImageCheck(now) {
+			continue
+		}
+
+		status, err := uc.checkImage(ctx, service)
+		if err != nil {
+			w.log.Warnw("Failed to check image for updates",
+				"service_id", service.ID,
+				"image", *service.Image,
+				"error", err,
+			)
+			continue
+		}
+
+		if !status.ImageUpdateAvailable || !service.AutoUpdate {
+			continue
+		}
+
+		// Only roll services that are meant to be up; a stopped service
+		// picks up the new image on its next manual deploy
+		if service.Status != entity.ServiceStatusRunning {
+			continue
+		}
+
+		deployment, err := uc.startDeployment(ctx, service, nil, nil)
+		if err != nil {
+			w.log.Warnw("Failed to start auto-update deployment",
+				"service_id", service.ID,
+				"error", err,
+			)
+			continue
+		}
+
+		w.log.Infow("Auto-update deployment started",
+			"service_id", service.ID,
+			"deployment_id", deployment.ID,
+			"image", *service.Image,
+			"digest", *status.LatestDigest,
+		)
+	}
+}
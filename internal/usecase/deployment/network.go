@@ -0,0 +1,142 @@
+package deployment
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	domainDocker "github.com/podoru/spinner-podoru/internal/domain/docker"
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+// projectNetwork returns the project's default network, creating it on first
+// use so projects that predate networks pick one up on their next deploy
+func (uc *UseCase) projectNetwork(ctx context.Context, project *entity.Project) (*entity.Network, error) {
+	network, err := uc.networkRepo.GetDefaultByProjectID(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if network == nil {
+		network = &entity.Network{
+			ID:        uuid.New(),
+			ProjectID: &project.ID,
+			Name:      entity.DefaultNetworkName,
+			Driver:    entity.NetworkDriver(uc.dockerConfig.NetworkDriver),
+			IsDefault: true,
+			CreatedAt: time.Now(),
+		}
+		if err := uc.ensureDockerNetwork(ctx, network); err != nil {
+			return nil, err
+		}
+		if err := uc.networkRepo.Create(ctx, network); err != nil {
+			return nil, err
+		}
+		return network, nil
+	}
+
+	if err := uc.ensureDockerNetwork(ctx, network); err != nil {
+		return nil, err
+	}
+	return network, uc.networkRepo.Update(ctx, network)
+}
+
+// ensureDockerNetwork creates the Docker side of a network when it has never
+// been created or has been removed from the host behind Podoru's back
+func (uc *UseCase) ensureDockerNetwork(ctx context.Context, network *entity.Network) error {
+	if network.DockerNetworkID != nil {
+		if err := uc.containerManager.ValidateNetwork(ctx, *network.DockerNetworkID); err == nil {
+			return nil
+		}
+	}
+
+	dockerID, err := uc.containerManager.CreateNetwork(ctx, domainDocker.ProjectNetworkConfig(network))
+	if err != nil {
+		return err
+	}
+	network.DockerNetworkID = &dockerID
+	return nil
+}
+
+// connectNetworks attaches a freshly created container to the custom project
+// networks the service has joined
+func (uc *UseCase) connectNetworks(ctx context.Context, service *entity.Service, containerID string) error {
+	networks, err := uc.networkRepo.ListByServiceID(ctx, service.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch networks: %w", err)
+	}
+
+	for i := range networks {
+		network := &networks[i]
+		if err := uc.ensureDockerNetwork(ctx, network); err != nil {
+			return err
+		}
+		if err := uc.networkRepo.Update(ctx, network); err != nil {
+			return err
+		}
+		if err := uc.containerManager.ConnectNetwork(ctx, *network.DockerNetworkID, containerID, []string{service.Slug}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DestroyProject removes every service container and network of a project
+// (used before deletion). Requires admin or owner role.
+func (uc *UseCase) DestroyProject(ctx context.Context, userID, projectID uuid.UUID) error {
+	project, err := uc.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if project == nil {
+		return ErrProjectNotFound
+	}
+
+	member, err := uc.teamMemberRepo.GetByTeamAndUser(ctx, project.TeamID, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return ErrNotTeamMember
+	}
+	if member.Role == entity.TeamRoleMember {
+		return ErrNotTeamAdmin
+	}
+
+	services, err := uc.serviceRepo.ListByProjectID(ctx, projectID)
+	if err != nil {
+		return err
+	}
+
+	// Containers go first, Docker refuses to remove networks with endpoints
+	for _, service := range services {
+		if service.ContainerID == nil || *service.ContainerID == "" {
+			continue
+		}
+		_ = uc.containerManager.StopContainer(ctx, *service.ContainerID, nil)
+		if err := uc.containerManager.RemoveContainer(ctx, *service.ContainerID, true); err != nil {
+			return fmt.Errorf("failed to remove container: %w", err)
+		}
+		if err := uc.serviceRepo.UpdateContainerID(ctx, service.ID, nil); err != nil {
+			return err
+		}
+	}
+	uc.routes.Refresh()
+
+	networks, err := uc.networkRepo.ListByProjectID(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	for _, network := range networks {
+		if network.DockerNetworkID == nil {
+			continue
+		}
+		if err := uc.containerManager.RemoveNetwork(ctx, *network.DockerNetworkID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package deployment
+
+import (
+	"context"
+	"time"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/config"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/logger"
+)
+
+// Scheduler starts deployments that were held back for a scheduled time or
+// a project maintenance window once they become due
+type Scheduler struct {
+	deploymentUseCase *UseCase
+	pollInterval      time.Duration
+	log               *logger.Logger
+}
+
+// NewScheduler creates a new deployment scheduler
+func NewScheduler(deploymentUseCase *UseCase, cfg *config.SchedulerConfig, log *logger.Logger) *Scheduler {
+	return &Scheduler{
+		deploymentUseCase: deploymentUseCase,
+		pollInterval:      cfg.PollInterval,
+		log:               log,
+	}
+}
+
+// Run blocks until ctx is cancelled, starting due deployments on every tick
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runDue(ctx)
+		}
+	}
+}
+
+func (s *Scheduler) runDue(ctx context.Context) {
+	uc := s.deploymentUseCase
+
+	deployments, err := uc.deploymentRepo.ListDue(ctx, time.Now())
+	if err != nil {
+		s.log.Errorw("Failed to list scheduled deployments", "error", err)
+		return
+	}
+
+	for i := range deployments {
+		deployment := &deployments[i]
+
+		service, err := uc.serviceRepo.GetByID(ctx, deployment.ServiceID)
+		if err != nil {
+			s.log.Errorw("Failed to load service for scheduled deployment",
+				"deployment_id", deployment.ID,
+				"error", err,
+			)
+			continue
+		}
+
+		// Services are deleted with their deployments, so this only happens
+		// if the row vanished between the two queries
+		if service == nil {
+			continue
+		}
+
+		// Leave it pending and try again on the next tick
+		if service.Status == entity.ServiceStatusDeploying {
+			continue
+		}
+
+		s.log.Infow("Starting scheduled deployment",
+			"service_id", service.ID,
+			"deployment_id", deployment.ID,
+			"scheduled_for", deployment.ScheduledFor,
+		)
+		uc.launch(ctx, service, deployment)
+	}
+}
@@ -0,0 +1,152 @@
+package deployment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/usecase/deploytoken"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrInvalidPayload   = errors.New("invalid webhook payload")
+)
+
+type githubPushEvent struct {
+	Ref     string `json:"ref"`
+	Deleted bool   `json:"deleted"`
+}
+
+// HandleGithubPush verifies a GitHub webhook delivery and, for pushes to the
+// project's tracked branch, deploys every service built from the repository.
+// Deployments respect the project's maintenance window. Other events are
+// acknowledged without doing anything.
+func (uc *UseCase) HandleGithubPush(ctx context.Context, projectID uuid.UUID, event, signature string, payload []byte) ([]entity.Deployment, error) {
+	project, err := uc.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, ErrProjectNotFound
+	}
+
+	if project.WebhookSecret == nil || !validSignature(*project.WebhookSecret, signature, payload) {
+		return nil, ErrInvalidSignature
+	}
+
+	if event != "push" || !project.AutoDeploy {
+		return nil, nil
+	}
+
+	var push githubPushEvent
+	if err := json.Unmarshal(payload, &push); err != nil {
+		return nil, ErrInvalidPayload
+	}
+	if push.Deleted || push.Ref != "refs/heads/"+project.GithubBranch {
+		return nil, nil
+	}
+
+	services, err := uc.serviceRepo.ListByProjectID(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var deployments []entity.Deployment
+	for i := range services {
+		service := &services[i]
+		if !service.DeployType.BuildsFromSource() {
+			continue
+		}
+
+		deployment, err := uc.startDeployment(ctx, service, nil, nil)
+		if errors.Is(err, ErrAlreadyDeploying) {
+			continue
+		}
+		if err != nil {
+			return deployments, fmt.Errorf("failed to deploy service %s: %w", service.Slug, err)
+		}
+		deployments = append(deployments, *deployment)
+	}
+
+	return deployments, nil
+}
+
+// HandleDeployHook deploys the services of the deploy token's project, or
+// only the one with serviceSlug when it is set, for CI systems that cannot
+// sign GitHub deliveries. The token must be in ctx (see deploytoken.WithToken).
+// Deployments respect the project's maintenance window. Without serviceSlug,
+// services that are already deploying or have nothing to deploy are skipped.
+func (uc *UseCase) HandleDeployHook(ctx context.Context, serviceSlug string) ([]entity.Deployment, error) {
+	token := deploytoken.TokenFrom(ctx)
+	if token == nil {
+		return nil, deploytoken.ErrInvalidDeployToken
+	}
+
+	var services []entity.Service
+	if serviceSlug != "" {
+		service, err := uc.serviceRepo.GetByProjectAndSlug(ctx, token.ProjectID, serviceSlug)
+		if err != nil {
+			return nil, err
+		}
+		if service == nil {
+			return nil, ErrServiceNotFound
+		}
+		services = append(services, *service)
+	} else {
+		var err error
+		services, err = uc.serviceRepo.ListByProjectID(ctx, token.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var deployments []entity.Deployment
+	for i := range services {
+		service := &services[i]
+
+		deployment, err := uc.startDeployment(ctx, service, nil, nil)
+		if serviceSlug == "" && cannotDeploy(err) {
+			continue
+		}
+		if err != nil {
+			return deployments, fmt.Errorf("failed to deploy service %s: %w", service.Slug, err)
+		}
+		deployments = append(deployments, *deployment)
+	}
+
+	return deployments, nil
+}
+
+// validSignature checks an X-Hub-Signature-256 header ("sha256=<hex hmac>")
+func validSignature(secret, signature string, payload []byte) bool {
+	sig, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// cannotDeploy reports whether err means a service was left alone rather
+// than that starting its deployment failed
+func cannotDeploy(err error) bool {
+	return errors.Is(err, ErrAlreadyDeploying) ||
+		errors.Is(err, ErrNoImageSpecified) ||
+		errors.Is(err, ErrNoRepository) ||
+		errors.Is(err, ErrUnsupportedDeployType)
+}
@@ -0,0 +1,21 @@
+package deploytoken
+
+import (
+	"context"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+type tokenKey struct{}
+
+// WithToken marks ctx as a request authenticated by a deploy token rather
+// than a user. The deployment use case then only allows the token's project.
+func WithToken(ctx context.Context, token *entity.DeployToken) context.Context {
+	return context.WithValue(ctx, tokenKey{}, token)
+}
+
+// TokenFrom returns the deploy token the request in ctx was authenticated by, if any
+func TokenFrom(ctx context.Context) *entity.DeployToken {
+	token, _ := ctx.Value(tokenKey{}).(*entity.DeployToken)
+	return token
+}
@@ -0,0 +1,145 @@
+package deploytoken
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/domain/repository"
+	"github.com/podoru/spinner-podoru/pkg/crypto"
+)
+
+var (
+	ErrDeployTokenNotFound = errors.New("deploy token not found")
+	ErrInvalidDeployToken  = errors.New("invalid, expired or revoked deploy token")
+	ErrProjectNotFound     = errors.New("project not found")
+	ErrNotTeamMember       = errors.New("not a team member")
+	ErrNotTeamAdmin        = errors.New("requires admin or owner role")
+	ErrInvalidExpiry       = errors.New("expires_at must be in the future")
+)
+
+type UseCase struct {
+	deployTokenRepo repository.DeployTokenRepository
+	projectRepo     repository.ProjectRepository
+	teamMemberRepo  repository.TeamMemberRepository
+}
+
+func NewUseCase(
+	deployTokenRepo repository.DeployTokenRepository,
+	projectRepo repository.ProjectRepository,
+	teamMemberRepo repository.TeamMemberRepository,
+) *UseCase {
+	return &UseCase{
+		deployTokenRepo: deployTokenRepo,
+		projectRepo:     projectRepo,
+		teamMemberRepo:  teamMemberRepo,
+	}
+}
+
+// List returns the project's deploy tokens, revoked and expired ones included
+func (uc *UseCase) List(ctx context.Context, userID, projectID uuid.UUID) ([]entity.DeployToken, error) {
+	if _, err := uc.projectMember(ctx, userID, projectID); err != nil {
+		return nil, err
+	}
+	return uc.deployTokenRepo.ListByProjectID(ctx, projectID)
+}
+
+// Create issues a deploy token for the project and returns it together with
+// its only plaintext copy; just its hash is stored
+func (uc *UseCase) Create(ctx context.Context, userID, projectID uuid.UUID, input *entity.DeployTokenCreate) (*entity.DeployToken, string, error) {
+	if err := uc.requireAdmin(ctx, userID, projectID); err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	if !entity.ValidTokenExpiry(input.ExpiresAt, now) {
+		return nil, "", ErrInvalidExpiry
+	}
+
+	plaintext, hash, err := crypto.GenerateToken(entity.DeployTokenPrefix)
+	if err != nil {
+		return nil, "", err
+	}
+
+	token := &entity.DeployToken{
+		ID:         uuid.New(),
+		ProjectID:  projectID,
+		Name:       input.Name,
+		TokenState: entity.NewTokenState(entity.DeployTokenPrefix, plaintext, hash, input.ExpiresAt),
+		CreatedBy:  &userID,
+		CreatedAt:  now,
+	}
+	if err := uc.deployTokenRepo.Create(ctx, token); err != nil {
+		return nil, "", err
+	}
+	return token, plaintext, nil
+}
+
+// Revoke stops one of the project's deploy tokens from working
+func (uc *UseCase) Revoke(ctx context.Context, userID, projectID, tokenID uuid.UUID) error {
+	if err := uc.requireAdmin(ctx, userID, projectID); err != nil {
+		return err
+	}
+
+	token, err := uc.deployTokenRepo.GetByID(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	if token == nil || token.ProjectID != projectID {
+		return ErrDeployTokenNotFound
+	}
+	return uc.deployTokenRepo.Revoke(ctx, tokenID, time.Now())
+}
+
+// Authenticate returns the active deploy token for a plaintext token,
+// recording that it was used
+func (uc *UseCase) Authenticate(ctx context.Context, plaintext string) (*entity.DeployToken, error) {
+	token, err := uc.deployTokenRepo.GetByTokenHash(ctx, crypto.HashToken(plaintext))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if token == nil || !token.IsActive(now) {
+		return nil, ErrInvalidDeployToken
+	}
+
+	if token.RecordUse(now) {
+		if err := uc.deployTokenRepo.UpdateLastUsed(ctx, token.ID, now); err != nil {
+			return nil, err
+		}
+	}
+	return token, nil
+}
+
+func (uc *UseCase) projectMember(ctx context.Context, userID, projectID uuid.UUID) (*entity.TeamMember, error) {
+	project, err := uc.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, ErrProjectNotFound
+	}
+
+	member, err := uc.teamMemberRepo.GetByTeamAndUser(ctx, project.TeamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrNotTeamMember
+	}
+	return member, nil
+}
+
+func (uc *UseCase) requireAdmin(ctx context.Context, userID, projectID uuid.UUID) error {
+	member, err := uc.projectMember(ctx, userID, projectID)
+	if err != nil {
+		return err
+	}
+	if member.Role == entity.TeamRoleMember {
+		return ErrNotTeamAdmin
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package deploytoken_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/mocks"
+	"github.com/podoru/spinner-podoru/internal/usecase/deploytoken"
+	"github.com/podoru/spinner-podoru/pkg/crypto"
+)
+
+func TestCreate_Success(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	project := &entity.Project{ID: uuid.New(), TeamID: uuid.New()}
+
+	var stored *entity.DeployToken
+	deployTokenRepo := &mocks.MockDeployTokenRepository{
+		CreateFunc: func(ctx context.Context, token *entity.DeployToken) error {
+			stored = token
+			return nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return project, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, uID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: uID, Role: entity.TeamRoleAdmin}, nil
+		},
+	}
+
+	uc := deploytoken.NewUseCase(deployTokenRepo, projectRepo, teamMemberRepo)
+
+	token, plaintext, err := uc.Create(ctx, userID, project.ID, &entity.DeployTokenCreate{Name: "ci"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if stored == nil {
+		t.Fatal("expected token to be stored")
+	}
+	if !strings.HasPrefix(plaintext, entity.DeployTokenPrefix) {
+		t.Errorf("expected token to start with %s, got %q", entity.DeployTokenPrefix, plaintext)
+	}
+	if !strings.HasPrefix(plaintext, token.TokenPrefix) {
+		t.Errorf("expected prefix %q to start the token", token.TokenPrefix)
+	}
+	if token.TokenHash != crypto.HashToken(plaintext) {
+		t.Errorf("expected only the hash of the token to be stored")
+	}
+	if token.ProjectID != project.ID {
+		t.Errorf("expected project ID %s, got %s", project.ID, token.ProjectID)
+	}
+}
+
+func TestCreate_Errors(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		project   *entity.Project
+		member    *entity.TeamMember
+		expiresAt *time.Time
+		wantErr   error
+	}{
+		{name: "project not found", wantErr: deploytoken.ErrProjectNotFound},
+		{name: "not a team member", project: &entity.Project{}, wantErr: deploytoken.ErrNotTeamMember},
+		{name: "member", project: &entity.Project{}, member: &entity.TeamMember{Role: entity.TeamRoleMember}, wantErr: deploytoken.ErrNotTeamAdmin},
+		{name: "expired", project: &entity.Project{}, member: &entity.TeamMember{Role: entity.TeamRoleOwner}, expiresAt: &past, wantErr: deploytoken.ErrInvalidExpiry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployTokenRepo := &mocks.MockDeployTokenRepository{
+				CreateFunc: func(ctx context.Context, token *entity.DeployToken) error {
+					t.Errorf("expected no token to be stored")
+					return nil
+				},
+			}
+
+			projectRepo := &mocks.MockProjectRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+					return tt.project, nil
+				},
+			}
+
+			teamMemberRepo := &mocks.MockTeamMemberRepository{
+				GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+					return tt.member, nil
+				},
+			}
+
+			uc := deploytoken.NewUseCase(deployTokenRepo, projectRepo, teamMemberRepo)
+
+			_, _, err := uc.Create(context.Background(), uuid.New(), uuid.New(), &entity.DeployTokenCreate{
+				Name:      "ci",
+				ExpiresAt: tt.expiresAt,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestAuthenticate_Success(t *testing.T) {
+	ctx := context.Background()
+	plaintext := entity.DeployTokenPrefix + "secret"
+	token := &entity.DeployToken{
+		ID:         uuid.New(),
+		ProjectID:  uuid.New(),
+		TokenState: entity.TokenState{TokenHash: crypto.HashToken(plaintext)},
+	}
+
+	var lastUsed *time.Time
+	deployTokenRepo := &mocks.MockDeployTokenRepository{
+		GetByTokenHashFunc: func(ctx context.Context, tokenHash string) (*entity.DeployToken, error) {
+			if tokenHash == token.TokenHash {
+				return token, nil
+			}
+			return nil, nil
+		},
+		UpdateLastUsedFunc: func(ctx context.Context, id uuid.UUID, lastUsedAt time.Time) error {
+			lastUsed = &lastUsedAt
+			return nil
+		},
+	}
+
+	uc := deploytoken.NewUseCase(deployTokenRepo, &mocks.MockProjectRepository{}, &mocks.MockTeamMemberRepository{})
+
+	result, err := uc.Authenticate(ctx, plaintext)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.ID != token.ID {
+		t.Errorf("expected token %s, got %s", token.ID, result.ID)
+	}
+	if lastUsed == nil {
+		t.Errorf("expected last use to be recorded")
+	}
+}
+
+func TestAuthenticate_RecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	plaintext := entity.DeployTokenPrefix + "secret"
+	recent := time.Now().Add(-10 * time.Second)
+
+	deployTokenRepo := &mocks.MockDeployTokenRepository{
+		GetByTokenHashFunc: func(ctx context.Context, tokenHash string) (*entity.DeployToken, error) {
+			return &entity.DeployToken{
+				ID:         uuid.New(),
+				TokenState: entity.TokenState{TokenHash: tokenHash, LastUsedAt: &recent},
+			}, nil
+		},
+		UpdateLastUsedFunc: func(ctx context.Context, id uuid.UUID, lastUsedAt time.Time) error {
+			t.Errorf("expected a recent use not to be recorded again")
+			return nil
+		},
+	}
+
+	uc := deploytoken.NewUseCase(deployTokenRepo, &mocks.MockProjectRepository{}, &mocks.MockTeamMemberRepository{})
+
+	if _, err := uc.Authenticate(ctx, plaintext); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAuthenticate_Invalid(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name  string
+		token *entity.DeployToken
+	}{
+		{name: "unknown"},
+		{name: "revoked", token: &entity.DeployToken{TokenState: entity.TokenState{RevokedAt: &past}}},
+		{name: "expired", token: &entity.DeployToken{TokenState: entity.TokenState{ExpiresAt: &past}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployTokenRepo := &mocks.MockDeployTokenRepository{
+				GetByTokenHashFunc: func(ctx context.Context, tokenHash string) (*entity.DeployToken, error) {
+					return tt.token, nil
+				},
+			}
+
+			uc := deploytoken.NewUseCase(deployTokenRepo, &mocks.MockProjectRepository{}, &mocks.MockTeamMemberRepository{})
+
+			_, err := uc.Authenticate(context.Background(), entity.DeployTokenPrefix+"secret")
+			if !errors.Is(err, deploytoken.ErrInvalidDeployToken) {
+				t.Errorf("expected ErrInvalidDeployToken, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	projectID := uuid.New()
+
+	tests := []struct {
+		name        string
+		token       *entity.DeployToken
+		wantErr     error
+		wantRevoked bool
+	}{
+		{name: "own project", token: &entity.DeployToken{ID: uuid.New(), ProjectID: projectID}, wantRevoked: true},
+		{name: "other project", token: &entity.DeployToken{ID: uuid.New(), ProjectID: uuid.New()}, wantErr: deploytoken.ErrDeployTokenNotFound},
+		{name: "not found", wantErr: deploytoken.ErrDeployTokenNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revoked := false
+			deployTokenRepo := &mocks.MockDeployTokenRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.DeployToken, error) {
+					return tt.token, nil
+				},
+				RevokeFunc: func(ctx context.Context, id uuid.UUID, revokedAt time.Time) error {
+					revoked = true
+					return nil
+				},
+			}
+
+			projectRepo := &mocks.MockProjectRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+					return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+				},
+			}
+
+			teamMemberRepo := &mocks.MockTeamMemberRepository{
+				GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+					return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleAdmin}, nil
+				},
+			}
+
+			uc := deploytoken.NewUseCase(deployTokenRepo, projectRepo, teamMemberRepo)
+
+			var tokenID uuid.UUID
+			if tt.token != nil {
+				tokenID = tt.token.ID
+			}
+			err := uc.Revoke(context.Background(), uuid.New(), projectID, tokenID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if revoked != tt.wantRevoked {
+				t.Errorf("expected revoked %v, got %v", tt.wantRevoked, revoked)
+			}
+		})
+	}
+}
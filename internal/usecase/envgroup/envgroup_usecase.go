@@ -0,0 +1,488 @@
+package envgroup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/domain/repository"
+	"github.com/podoru/spinner-podoru/internal/usecase/apitoken"
+	"github.com/podoru/spinner-podoru/pkg/crypto"
+)
+
+var (
+	ErrEnvGroupNotFound  = errors.New("env group not found")
+	ErrProjectNotFound   = errors.New("project not found")
+	ErrServiceNotFound   = errors.New("service not found")
+	ErrNotTeamMember     = errors.New("not a team member")
+	ErrNotTeamAdmin      = errors.New("requires admin or owner role")
+	ErrEnvGroupNameTaken = errors.New("env group name already exists")
+	ErrEnvGroupInUse     = errors.New("env group is attached to services")
+	ErrInvalidVarName    = errors.New("variable names must be letters, digits and underscores, not starting with a digit")
+
+	ErrProjectLimitedToken = errors.New("token is limited to a project")
+)
+
+type UseCase struct {
+	envGroupRepo   repository.EnvGroupRepository
+	envVarRepo     repository.ServiceEnvVarRepository
+	projectRepo    repository.ProjectRepository
+	serviceRepo    repository.ServiceRepository
+	teamMemberRepo repository.TeamMemberRepository
+	encryptor      *crypto.Encryptor
+}
+
+func NewUseCase(
+	envGroupRepo repository.EnvGroupRepository,
+	envVarRepo repository.ServiceEnvVarRepository,
+	projectRepo repository.ProjectRepository,
+	serviceRepo repository.ServiceRepository,
+	teamMemberRepo repository.TeamMemberRepository,
+	encryptor *crypto.Encryptor,
+) *UseCase {
+	return &UseCase{
+		envGroupRepo:   envGroupRepo,
+		envVarRepo:     envVarRepo,
+		projectRepo:    projectRepo,
+		serviceRepo:    serviceRepo,
+		teamMemberRepo: teamMemberRepo,
+		encryptor:      encryptor,
+	}
+}
+
+// ListByTeam returns the team-level groups of a team
+func (uc *UseCase) ListByTeam(ctx context.Context, userID, teamID uuid.UUID) ([]entity.EnvGroup, error) {
+	if _, err := uc.getMember(ctx, teamID, userID); err != nil {
+		return nil, err
+	}
+
+	groups, err := uc.envGroupRepo.ListByTeamID(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.withKeys(groups)
+}
+
+// ListByProject returns the groups of a project
+func (uc *UseCase) ListByProject(ctx context.Context, userID, projectID uuid.UUID) ([]entity.EnvGroup, error) {
+	if _, _, err := uc.getProjectWithTeamCheck(ctx, userID, projectID); err != nil {
+		return nil, err
+	}
+
+	groups, err := uc.envGroupRepo.ListByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.withKeys(groups)
+}
+
+// CreateForTeam adds a group any service of the team can be attached to.
+// Requires admin or owner role.
+func (uc *UseCase) CreateForTeam(ctx context.Context, userID, teamID uuid.UUID, input *entity.EnvGroupCreate) (*entity.EnvGroup, error) {
+	if apitoken.ProjectLimited(ctx) {
+		return nil, ErrProjectLimitedToken
+	}
+
+	member, err := uc.getMember(ctx, teamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role == entity.TeamRoleMember {
+		return nil, ErrNotTeamAdmin
+	}
+
+	existing, err := uc.envGroupRepo.ListByTeamID(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.create(ctx, teamID, nil, existing, input)
+}
+
+// CreateForProject adds a group the services of a project can be attached
+// to. Requires admin or owner role.
+func (uc *UseCase) CreateForProject(ctx context.Context, userID, projectID uuid.UUID, input *entity.EnvGroupCreate) (*entity.EnvGroup, error) {
+	project, member, err := uc.getProjectWithTeamCheck(ctx, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role == entity.TeamRoleMember {
+		return nil, ErrNotTeamAdmin
+	}
+
+	existing, err := uc.envGroupRepo.ListByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.create(ctx, project.TeamID, &project.ID, existing, input)
+}
+
+func (uc *UseCase) create(ctx context.Context, teamID uuid.UUID, projectID *uuid.UUID, existing []entity.EnvGroup, input *entity.EnvGroupCreate) (*entity.EnvGroup, error) {
+	if err := checkName(existing, uuid.Nil, input.Name); err != nil {
+		return nil, err
+	}
+
+	encrypted, err := uc.encryptVars(input.Vars)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	group := &entity.EnvGroup{
+		ID:            uuid.New(),
+		TeamID:        teamID,
+		ProjectID:     projectID,
+		Name:          input.Name,
+		VarsEncrypted: encrypted,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Keys:          sortedKeys(input.Vars),
+	}
+
+	if err := uc.envGroupRepo.Create(ctx, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// Get returns a group with its variable names
+func (uc *UseCase) Get(ctx context.Context, userID, groupID uuid.UUID) (*entity.EnvGroup, error) {
+	group, _, err := uc.getGroupWithTeamCheck(ctx, userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.withGroupKeys(group)
+}
+
+// Update renames a group or replaces its vars. Attached services pick the
+// change up on their next deploy. Requires admin or owner role.
+func (uc *UseCase) Update(ctx context.Context, userID, groupID uuid.UUID, input *entity.EnvGroupUpdate) (*entity.EnvGroup, error) {
+	group, member, err := uc.getGroupWithTeamCheck(ctx, userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role == entity.TeamRoleMember {
+		return nil, ErrNotTeamAdmin
+	}
+	if group.ProjectID == nil && apitoken.ProjectLimited(ctx) {
+		return nil, ErrProjectLimitedToken
+	}
+
+	if input.Name != nil && *input.Name != group.Name {
+		var siblings []entity.EnvGroup
+		if group.ProjectID != nil {
+			siblings, err = uc.envGroupRepo.ListByProjectID(ctx, *group.ProjectID)
+		} else {
+			siblings, err = uc.envGroupRepo.ListByTeamID(ctx, group.TeamID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := checkName(siblings, group.ID, *input.Name); err != nil {
+			return nil, err
+		}
+		group.Name = *input.Name
+	}
+
+	if input.Vars != nil {
+		encrypted, err := uc.encryptVars(input.Vars)
+		if err != nil {
+			return nil, err
+		}
+		group.VarsEncrypted = encrypted
+	}
+
+	group.UpdatedAt = time.Now()
+	if err := uc.envGroupRepo.Update(ctx, group); err != nil {
+		return nil, err
+	}
+	return uc.withGroupKeys(group)
+}
+
+// Delete removes a group that no service is attached to. Requires admin or
+// owner role.
+func (uc *UseCase) Delete(ctx context.Context, userID, groupID uuid.UUID) error {
+	group, member, err := uc.getGroupWithTeamCheck(ctx, userID, groupID)
+	if err != nil {
+		return err
+	}
+	if member.Role == entity.TeamRoleMember {
+		return ErrNotTeamAdmin
+	}
+	if group.ProjectID == nil && apitoken.ProjectLimited(ctx) {
+		return ErrProjectLimitedToken
+	}
+
+	serviceIDs, err := uc.envGroupRepo.ListServiceIDs(ctx, group.ID)
+	if err != nil {
+		return err
+	}
+	if len(serviceIDs) > 0 {
+		return ErrEnvGroupInUse
+	}
+
+	return uc.envGroupRepo.Delete(ctx, group.ID)
+}
+
+// ListByService returns the groups attached to a service in increasing
+// precedence
+func (uc *UseCase) ListByService(ctx context.Context, userID, serviceID uuid.UUID) ([]entity.EnvGroup, error) {
+	if _, _, err := uc.getServiceWithTeamCheck(ctx, userID, serviceID); err != nil {
+		return nil, err
+	}
+
+	groups, err := uc.envGroupRepo.ListByServiceID(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.withKeys(groups)
+}
+
+// AttachService adds a group's vars to a service from its next deploy. Team
+// groups attach to any service of the team, project groups to the services
+// of their project.
+func (uc *UseCase) AttachService(ctx context.Context, userID, serviceID, groupID uuid.UUID) error {
+	service, group, err := uc.getServiceAndGroup(ctx, userID, serviceID, groupID)
+	if err != nil {
+		return err
+	}
+	return uc.envGroupRepo.AttachService(ctx, group.ID, service.ID)
+}
+
+// DetachService removes a group's vars from a service from its next deploy
+func (uc *UseCase) DetachService(ctx context.Context, userID, serviceID, groupID uuid.UUID) error {
+	service, group, err := uc.getServiceAndGroup(ctx, userID, serviceID, groupID)
+	if err != nil {
+		return err
+	}
+	return uc.envGroupRepo.DetachService(ctx, group.ID, service.ID)
+}
+
+// Preview resolves the env a service would be deployed with, showing which
+// layer each key comes from. Values are masked.
+func (uc *UseCase) Preview(ctx context.Context, userID, serviceID uuid.UUID) ([]entity.ResolvedEnvVar, error) {
+	service, _, err := uc.getServiceWithTeamCheck(ctx, userID, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	sets, err := uc.ServiceEnvSets(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := entity.MergeEnv(sets)
+	for i := range resolved {
+		resolved[i].Value = entity.MaskValue(resolved[i].Value)
+	}
+	return resolved, nil
+}
+
+// ServiceEnvSets returns the vars of the service's team groups, project
+// groups and its own vars, in increasing precedence
+func (uc *UseCase) ServiceEnvSets(ctx context.Context, service *entity.Service) ([]entity.EnvVarSet, error) {
+	groups, err := uc.envGroupRepo.ListByServiceID(ctx, service.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sets := make([]entity.EnvVarSet, 0, len(groups)+1)
+	for _, g := range groups {
+		vars, err := uc.decryptVars(g.VarsEncrypted)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt env group %s: %w", g.Name, err)
+		}
+		sets = append(sets, entity.EnvVarSet{
+			Source: entity.EnvSource{Layer: g.Layer(), GroupID: &g.ID, GroupName: g.Name},
+			Vars:   vars,
+		})
+	}
+
+	envVars, err := uc.envVarRepo.ListByServiceID(ctx, service.ID)
+	if err != nil {
+		return nil, err
+	}
+	vars := make(map[string]string, len(envVars))
+	for _, v := range envVars {
+		value, err := uc.encryptor.Decrypt(v.ValueEncrypted)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt env var %s: %w", v.Key, err)
+		}
+		vars[v.Key] = string(value)
+	}
+	sets = append(sets, entity.EnvVarSet{Source: entity.EnvSource{Layer: entity.EnvLayerService}, Vars: vars})
+	return sets, nil
+}
+
+// ServiceEnv returns a service's env vars merged with its attached groups
+func (uc *UseCase) ServiceEnv(ctx context.Context, service *entity.Service) (map[string]string, error) {
+	sets, err := uc.ServiceEnvSets(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+
+	env := make(map[string]string)
+	for _, v := range entity.MergeEnv(sets) {
+		env[v.Key] = v.Value
+	}
+	return env, nil
+}
+
+func (uc *UseCase) getServiceAndGroup(ctx context.Context, userID, serviceID, groupID uuid.UUID) (*entity.Service, *entity.EnvGroup, error) {
+	service, project, err := uc.getServiceWithTeamCheck(ctx, userID, serviceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	group, err := uc.envGroupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if group == nil || group.TeamID != project.TeamID ||
+		(group.ProjectID != nil && *group.ProjectID != project.ID) {
+		return nil, nil, ErrEnvGroupNotFound
+	}
+	return service, group, nil
+}
+
+// getGroupWithTeamCheck returns a group of the user's team. A project
+// group must also be of a project the request can see, which keeps a
+// project limited token to its own project's groups.
+func (uc *UseCase) getGroupWithTeamCheck(ctx context.Context, userID, groupID uuid.UUID) (*entity.EnvGroup, *entity.TeamMember, error) {
+	group, err := uc.envGroupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if group == nil {
+		return nil, nil, ErrEnvGroupNotFound
+	}
+	if group.ProjectID != nil {
+		project, err := uc.projectRepo.GetByID(ctx, *group.ProjectID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if project == nil || project.TeamID != group.TeamID {
+			return nil, nil, ErrEnvGroupNotFound
+		}
+	}
+
+	member, err := uc.getMember(ctx, group.TeamID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return group, member, nil
+}
+
+func (uc *UseCase) getServiceWithTeamCheck(ctx context.Context, userID, serviceID uuid.UUID) (*entity.Service, *entity.Project, error) {
+	service, err := uc.serviceRepo.GetByID(ctx, serviceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if service == nil {
+		return nil, nil, ErrServiceNotFound
+	}
+
+	project, _, err := uc.getProjectWithTeamCheck(ctx, userID, service.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return service, project, nil
+}
+
+func (uc *UseCase) getProjectWithTeamCheck(ctx context.Context, userID, projectID uuid.UUID) (*entity.Project, *entity.TeamMember, error) {
+	project, err := uc.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if project == nil {
+		return nil, nil, ErrProjectNotFound
+	}
+
+	member, err := uc.getMember(ctx, project.TeamID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return project, member, nil
+}
+
+func (uc *UseCase) getMember(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+	member, err := uc.teamMemberRepo.GetByTeamAndUser(ctx, teamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrNotTeamMember
+	}
+	return member, nil
+}
+
+// checkName rejects a name another group of the same team or project uses
+func checkName(groups []entity.EnvGroup, self uuid.UUID, name string) error {
+	for _, g := range groups {
+		if g.ID != self && g.Name == name {
+			return ErrEnvGroupNameTaken
+		}
+	}
+	return nil
+}
+
+func (uc *UseCase) withKeys(groups []entity.EnvGroup) ([]entity.EnvGroup, error) {
+	for i := range groups {
+		if _, err := uc.withGroupKeys(&groups[i]); err != nil {
+			return nil, err
+		}
+	}
+	return groups, nil
+}
+
+func (uc *UseCase) withGroupKeys(group *entity.EnvGroup) (*entity.EnvGroup, error) {
+	vars, err := uc.decryptVars(group.VarsEncrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt env group %s: %w", group.Name, err)
+	}
+	group.Keys = sortedKeys(vars)
+	return group, nil
+}
+
+func sortedKeys(vars map[string]string) []string {
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// encryptVars seals a group's vars for storage after checking their names
+func (uc *UseCase) encryptVars(vars map[string]string) ([]byte, error) {
+	for name := range vars {
+		if !entity.IsValidVarName(name) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidVarName, name)
+		}
+	}
+	data, err := json.Marshal(vars)
+	if err != nil {
+		return nil, err
+	}
+	return uc.encryptor.Encrypt(data)
+}
+
+func (uc *UseCase) decryptVars(data []byte) (map[string]string, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	decrypted, err := uc.encryptor.Decrypt(data)
+	if err != nil {
+		return nil, err
+	}
+	var vars map[string]string
+	if err := json.Unmarshal(decrypted, &vars); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
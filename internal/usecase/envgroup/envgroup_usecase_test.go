@@ -0,0 +1,346 @@
+package envgroup_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/mocks"
+	"github.com/podoru/spinner-podoru/internal/usecase/apitoken"
+	"github.com/podoru/spinner-podoru/internal/usecase/envgroup"
+	"github.com/podoru/spinner-podoru/pkg/crypto"
+)
+
+func seal(t *testing.T, encryptor *crypto.Encryptor, vars map[string]string) []byte {
+	data, _ := json.Marshal(vars)
+	sealed, err := encryptor.Encrypt(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return sealed
+}
+
+func TestPreview_Precedence(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	teamID := uuid.New()
+	project := &entity.Project{ID: uuid.New(), TeamID: teamID}
+	service := &entity.Service{ID: uuid.New(), ProjectID: project.ID}
+
+	encryptor, err := crypto.NewEncryptor("test-key")
+	if err != nil {
+		t.Fatalf("failed to create encryptor: %v", err)
+	}
+	dbURL, err := encryptor.Encrypt([]byte("postgres://app:secret@db/app"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	envGroupRepo := &mocks.MockEnvGroupRepository{
+		ListByServiceIDFunc: func(ctx context.Context, serviceID uuid.UUID) ([]entity.EnvGroup, error) {
+			return []entity.EnvGroup{
+				{ID: uuid.New(), TeamID: teamID, Name: "shared", VarsEncrypted: seal(t, encryptor, map[string]string{"LOG_LEVEL": "info", "DATABASE_URL": "team"})},
+				{ID: uuid.New(), TeamID: teamID, ProjectID: &project.ID, Name: "backend", VarsEncrypted: seal(t, encryptor, map[string]string{"LOG_LEVEL": "debug"})},
+			}, nil
+		},
+	}
+
+	envVarRepo := &mocks.MockServiceEnvVarRepository{
+		ListByServiceIDFunc: func(ctx context.Context, serviceID uuid.UUID) ([]entity.ServiceEnvVar, error) {
+			return []entity.ServiceEnvVar{{ServiceID: service.ID, Key: "DATABASE_URL", ValueEncrypted: dbURL, IsSecret: true}}, nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return project, nil
+		},
+	}
+
+	serviceRepo := &mocks.MockServiceRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+			return service, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, tmID, uID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: tmID, UserID: uID, Role: entity.TeamRoleMember}, nil
+		},
+	}
+
+	uc := envgroup.NewUseCase(envGroupRepo, envVarRepo, projectRepo, serviceRepo, teamMemberRepo, encryptor)
+
+	resolved, err := uc.Preview(ctx, userID, service.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resolved) != 2 {
+		t.Fatalf("expected 2 vars, got %d", len(resolved))
+	}
+
+	db, logLevel := resolved[0], resolved[1]
+	if db.Key != "DATABASE_URL" || db.Source.Layer != entity.EnvLayerService || db.Value != "pos********" {
+		t.Errorf("DATABASE_URL = %+v", db)
+	}
+	if logLevel.Source.Layer != entity.EnvLayerProject || logLevel.Source.GroupName != "backend" {
+		t.Errorf("LOG_LEVEL source = %+v, expected project group backend", logLevel.Source)
+	}
+	if logLevel.Value != "********" {
+		t.Errorf("LOG_LEVEL value = %q, expected it masked", logLevel.Value)
+	}
+
+	env, err := uc.ServiceEnv(ctx, service)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if env["LOG_LEVEL"] != "debug" || env["DATABASE_URL"] != "postgres://app:secret@db/app" {
+		t.Errorf("ServiceEnv() = %v", env)
+	}
+}
+
+func TestAttachService_GroupScope(t *testing.T) {
+	userID := uuid.New()
+	teamID := uuid.New()
+	project := &entity.Project{ID: uuid.New(), TeamID: teamID}
+	service := &entity.Service{ID: uuid.New(), ProjectID: project.ID}
+	otherProject := uuid.New()
+
+	testCases := []struct {
+		name    string
+		group   *entity.EnvGroup
+		wantErr error
+	}{
+		{"team group", &entity.EnvGroup{ID: uuid.New(), TeamID: teamID, Name: "shared"}, nil},
+		{"project group", &entity.EnvGroup{ID: uuid.New(), TeamID: teamID, ProjectID: &project.ID, Name: "backend"}, nil},
+		{"group of another project", &entity.EnvGroup{ID: uuid.New(), TeamID: teamID, ProjectID: &otherProject, Name: "other"}, envgroup.ErrEnvGroupNotFound},
+		{"group of another team", &entity.EnvGroup{ID: uuid.New(), TeamID: uuid.New(), Name: "foreign"}, envgroup.ErrEnvGroupNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encryptor, err := crypto.NewEncryptor("test-key")
+			if err != nil {
+				t.Fatalf("failed to create encryptor: %v", err)
+			}
+
+			attached := false
+			envGroupRepo := &mocks.MockEnvGroupRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.EnvGroup, error) {
+					return tc.group, nil
+				},
+				AttachServiceFunc: func(ctx context.Context, groupID, serviceID uuid.UUID) error {
+					attached = true
+					return nil
+				},
+			}
+
+			projectRepo := &mocks.MockProjectRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+					return project, nil
+				},
+			}
+
+			serviceRepo := &mocks.MockServiceRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+					return service, nil
+				},
+			}
+
+			teamMemberRepo := &mocks.MockTeamMemberRepository{
+				GetByTeamAndUserFunc: func(ctx context.Context, tmID, uID uuid.UUID) (*entity.TeamMember, error) {
+					return &entity.TeamMember{TeamID: tmID, UserID: uID, Role: entity.TeamRoleAdmin}, nil
+				},
+			}
+
+			uc := envgroup.NewUseCase(envGroupRepo, &mocks.MockServiceEnvVarRepository{}, projectRepo, serviceRepo, teamMemberRepo, encryptor)
+
+			err = uc.AttachService(context.Background(), userID, service.ID, tc.group.ID)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("AttachService() error = %v, expected %v", err, tc.wantErr)
+			}
+			if attached != (tc.wantErr == nil) {
+				t.Errorf("expected attached %v, got %v", tc.wantErr == nil, attached)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	teamID := uuid.New()
+
+	testCases := []struct {
+		name     string
+		role     entity.TeamRole
+		services []uuid.UUID
+		wantErr  error
+	}{
+		{name: "attached group", role: entity.TeamRoleAdmin, services: []uuid.UUID{uuid.New()}, wantErr: envgroup.ErrEnvGroupInUse},
+		{name: "by a member", role: entity.TeamRoleMember, wantErr: envgroup.ErrNotTeamAdmin},
+		{name: "by the owner", role: entity.TeamRoleOwner},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encryptor, err := crypto.NewEncryptor("test-key")
+			if err != nil {
+				t.Fatalf("failed to create encryptor: %v", err)
+			}
+
+			deleted := false
+			envGroupRepo := &mocks.MockEnvGroupRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.EnvGroup, error) {
+					return &entity.EnvGroup{ID: id, TeamID: teamID, Name: "shared"}, nil
+				},
+				ListServiceIDsFunc: func(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error) {
+					return tc.services, nil
+				},
+				DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
+					deleted = true
+					return nil
+				},
+			}
+
+			teamMemberRepo := &mocks.MockTeamMemberRepository{
+				GetByTeamAndUserFunc: func(ctx context.Context, tmID, uID uuid.UUID) (*entity.TeamMember, error) {
+					return &entity.TeamMember{TeamID: tmID, UserID: uID, Role: tc.role}, nil
+				},
+			}
+
+			uc := envgroup.NewUseCase(envGroupRepo, &mocks.MockServiceEnvVarRepository{}, &mocks.MockProjectRepository{}, &mocks.MockServiceRepository{}, teamMemberRepo, encryptor)
+
+			err = uc.Delete(context.Background(), uuid.New(), uuid.New())
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Delete() error = %v, expected %v", err, tc.wantErr)
+			}
+			if deleted != (tc.wantErr == nil) {
+				t.Errorf("expected deleted %v, got %v", tc.wantErr == nil, deleted)
+			}
+		})
+	}
+}
+
+func TestProjectLimitedToken(t *testing.T) {
+	userID := uuid.New()
+	teamID := uuid.New()
+	ownProject := uuid.New()
+	otherProject := uuid.New()
+	teamGroup := &entity.EnvGroup{ID: uuid.New(), TeamID: teamID, Name: "shared"}
+	foreignGroup := &entity.EnvGroup{ID: uuid.New(), TeamID: teamID, ProjectID: &otherProject, Name: "billing"}
+	ownGroup := &entity.EnvGroup{ID: uuid.New(), TeamID: teamID, ProjectID: &ownProject, Name: "api"}
+	name := "renamed"
+
+	tests := []struct {
+		name    string
+		call    func(ctx context.Context, uc *envgroup.UseCase) error
+		wantErr error
+	}{
+		{
+			name: "get a foreign project's group",
+			call: func(ctx context.Context, uc *envgroup.UseCase) error {
+				_, err := uc.Get(ctx, userID, foreignGroup.ID)
+				return err
+			},
+			wantErr: envgroup.ErrEnvGroupNotFound,
+		},
+		{
+			name: "update a foreign project's group",
+			call: func(ctx context.Context, uc *envgroup.UseCase) error {
+				_, err := uc.Update(ctx, userID, foreignGroup.ID, &entity.EnvGroupUpdate{Name: &name})
+				return err
+			},
+			wantErr: envgroup.ErrEnvGroupNotFound,
+		},
+		{
+			name: "delete a foreign project's group",
+			call: func(ctx context.Context, uc *envgroup.UseCase) error {
+				return uc.Delete(ctx, userID, foreignGroup.ID)
+			},
+			wantErr: envgroup.ErrEnvGroupNotFound,
+		},
+		{
+			name: "update a team group",
+			call: func(ctx context.Context, uc *envgroup.UseCase) error {
+				_, err := uc.Update(ctx, userID, teamGroup.ID, &entity.EnvGroupUpdate{Name: &name})
+				return err
+			},
+			wantErr: envgroup.ErrProjectLimitedToken,
+		},
+		{
+			name: "create a team group",
+			call: func(ctx context.Context, uc *envgroup.UseCase) error {
+				_, err := uc.CreateForTeam(ctx, userID, teamID, &entity.EnvGroupCreate{Name: "new"})
+				return err
+			},
+			wantErr: envgroup.ErrProjectLimitedToken,
+		},
+		{
+			name: "update its own project's group",
+			call: func(ctx context.Context, uc *envgroup.UseCase) error {
+				_, err := uc.Update(ctx, userID, ownGroup.ID, &entity.EnvGroupUpdate{Name: &name})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := apitoken.WithToken(context.Background(), &entity.APIToken{ID: uuid.New(), TeamID: &teamID, ProjectID: &ownProject})
+			encryptor, err := crypto.NewEncryptor("test-key")
+			if err != nil {
+				t.Fatalf("failed to create encryptor: %v", err)
+			}
+
+			var changed bool
+			envGroupRepo := &mocks.MockEnvGroupRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.EnvGroup, error) {
+					for _, g := range []*entity.EnvGroup{teamGroup, foreignGroup, ownGroup} {
+						if g.ID == id {
+							group := *g
+							return &group, nil
+						}
+					}
+					return nil, nil
+				},
+				CreateFunc: func(ctx context.Context, group *entity.EnvGroup) error {
+					changed = true
+					return nil
+				},
+				UpdateFunc: func(ctx context.Context, group *entity.EnvGroup) error {
+					changed = true
+					return nil
+				},
+				DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
+					changed = true
+					return nil
+				},
+			}
+
+			projectRepo := apitoken.RestrictProjects(&mocks.MockProjectRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+					return &entity.Project{ID: id, TeamID: teamID}, nil
+				},
+			})
+
+			teamMemberRepo := apitoken.RestrictTeamMembers(&mocks.MockTeamMemberRepository{
+				GetByTeamAndUserFunc: func(ctx context.Context, tmID, uID uuid.UUID) (*entity.TeamMember, error) {
+					return &entity.TeamMember{TeamID: tmID, UserID: uID, Role: entity.TeamRoleOwner}, nil
+				},
+			})
+
+			uc := envgroup.NewUseCase(envGroupRepo, &mocks.MockServiceEnvVarRepository{}, projectRepo, &mocks.MockServiceRepository{}, teamMemberRepo, encryptor)
+
+			err = tt.call(ctx, uc)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if changed != (tt.wantErr == nil) {
+				t.Errorf("expected group changed %v, got %v", tt.wantErr == nil, changed)
+			}
+		})
+	}
+}
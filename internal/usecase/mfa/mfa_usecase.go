@@ -0,0 +1,493 @@
+package mfa
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/domain/repository"
+	"github.com/podoru/spinner-podoru/pkg/crypto"
+	"github.com/podoru/spinner-podoru/pkg/totp"
+)
+
+var (
+	ErrUserNotFound     = errors.New("user not found")
+	ErrNotEnrolled      = errors.New("two-factor authentication is not set up")
+	ErrAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrInvalidCode      = errors.New("invalid two-factor code")
+	ErrInvalidChallenge = errors.New("invalid or expired MFA token")
+	ErrRequiredByPolicy = errors.New("two-factor authentication is required for this account")
+	ErrNotSuperAdmin    = errors.New("requires superadmin role")
+)
+
+const (
+	// challengeExpiry is how long a login waits for the second factor
+	challengeExpiry = 5 * time.Minute
+	// maxAttempts wrong codes end a challenge, so codes cannot be guessed
+	maxAttempts = 5
+	// challengeTokenLength is the number of random characters in an MFA token
+	challengeTokenLength = 48
+	recoveryCodeCount    = 10
+	// recoveryCodeAlphabet leaves out the letters i, l and o, which are easily
+	// misread, and has 32 characters so each random byte maps to one evenly
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz023456789"
+)
+
+type UseCase struct {
+	mfaRepo       repository.MFARepository
+	challengeRepo repository.MFAChallengeRepository
+	policyRepo    repository.MFAPolicyRepository
+	userRepo      repository.UserRepository
+	teamRepo      repository.TeamRepository
+	encryptor     *crypto.Encryptor
+	// issuer names Podoru in authenticator apps
+	issuer string
+}
+
+func NewUseCase(
+	mfaRepo repository.MFARepository,
+	challengeRepo repository.MFAChallengeRepository,
+	policyRepo repository.MFAPolicyRepository,
+	userRepo repository.UserRepository,
+	teamRepo repository.TeamRepository,
+	encryptor *crypto.Encryptor,
+	issuer string,
+) *UseCase {
+	return &UseCase{
+		mfaRepo:       mfaRepo,
+		challengeRepo: challengeRepo,
+		policyRepo:    policyRepo,
+		userRepo:      userRepo,
+		teamRepo:      teamRepo,
+		encryptor:     encryptor,
+		issuer:        issuer,
+	}
+}
+
+// Status reports whether the user has two-factor authentication and
+// whether the policy requires it from them
+func (uc *UseCase) Status(ctx context.Context, userID uuid.UUID) (*entity.MFAStatus, error) {
+	user, err := uc.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &entity.MFAStatus{}
+	if status.Required, err = uc.required(ctx, user); err != nil {
+		return nil, err
+	}
+
+	t, err := uc.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if t != nil && t.IsConfirmed() {
+		status.Enabled = true
+		status.ConfirmedAt = t.ConfirmedAt
+		if status.RecoveryCodesRemaining, err = uc.mfaRepo.CountRecoveryCodes(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// StartEnrollment creates a new authenticator secret and recovery codes. It
+// replaces an enrolment that was never confirmed.
+func (uc *UseCase) StartEnrollment(ctx context.Context, userID uuid.UUID) (*entity.TOTPEnrollment, error) {
+	user, err := uc.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := uc.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.IsConfirmed() {
+		return nil, ErrAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := uc.encryptor.Encrypt([]byte(secret))
+	if err != nil {
+		return nil, err
+	}
+
+	codes, records, err := newRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.mfaRepo.SaveTOTP(ctx, &entity.UserTOTP{
+		ID:              uuid.New(),
+		UserID:          userID,
+		SecretEncrypted: encrypted,
+		CreatedAt:       time.Now(),
+	}, records); err != nil {
+		return nil, err
+	}
+
+	return &entity.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(uc.issuer, user.Email, secret),
+		RecoveryCodes:   codes,
+	}, nil
+}
+
+// ConfirmEnrollment turns two-factor authentication on once the user shows
+// a code from their authenticator
+func (uc *UseCase) ConfirmEnrollment(ctx context.Context, userID uuid.UUID, code string) error {
+	t, err := uc.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return ErrNotEnrolled
+	}
+	if t.IsConfirmed() {
+		return ErrAlreadyEnabled
+	}
+
+	if err := uc.checkTOTP(ctx, t, code); err != nil {
+		return err
+	}
+	return uc.mfaRepo.ConfirmTOTP(ctx, userID, time.Now())
+}
+
+// Disable turns two-factor authentication off, given a current code or a
+// recovery code. Users the policy covers cannot turn it off.
+func (uc *UseCase) Disable(ctx context.Context, userID uuid.UUID, code, recoveryCode string) error {
+	user, err := uc.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	required, err := uc.required(ctx, user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrRequiredByPolicy
+	}
+
+	if err := uc.verify(ctx, userID, code, recoveryCode); err != nil {
+		return err
+	}
+	return uc.mfaRepo.DeleteTOTP(ctx, userID)
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes, given a
+// current code
+func (uc *UseCase) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	if err := uc.verify(ctx, userID, code, ""); err != nil {
+		return nil, err
+	}
+
+	codes, records, err := newRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.mfaRepo.ReplaceRecoveryCodes(ctx, userID, records); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// GetPolicy returns the two-factor policy. Requires superadmin.
+func (uc *UseCase) GetPolicy(ctx context.Context, actorID uuid.UUID) (*entity.MFAPolicy, error) {
+	if err := uc.requireSuperAdmin(ctx, actorID); err != nil {
+		return nil, err
+	}
+	return uc.policyRepo.Get(ctx)
+}
+
+// UpdatePolicy sets who must use two-factor authentication. Users it newly
+// covers are asked to enrol at their next login. Requires superadmin.
+func (uc *UseCase) UpdatePolicy(ctx context.Context, actorID uuid.UUID, input *entity.MFAPolicyUpdate) (*entity.MFAPolicy, error) {
+	if err := uc.requireSuperAdmin(ctx, actorID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	policy := &entity.MFAPolicy{
+		Required:  input.Required,
+		UpdatedBy: &actorID,
+		UpdatedAt: &now,
+	}
+	if err := uc.policyRepo.Update(ctx, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// BeginLogin decides whether a user who passed the password check needs a
+// second step. It returns nil when they do not: they have no authenticator
+// and the policy does not require one.
+func (uc *UseCase) BeginLogin(ctx context.Context, user *entity.User) (*entity.MFAPendingLogin, error) {
+	t, err := uc.mfaRepo.GetTOTP(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollment := t == nil || !t.IsConfirmed()
+	if enrollment {
+		required, err := uc.required(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		if !required {
+			return nil, nil
+		}
+	}
+
+	// Abandoned logins are cleaned up as new ones start
+	_ = uc.challengeRepo.DeleteExpired(ctx)
+
+	token, err := crypto.GenerateRandomString(challengeTokenLength)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	challenge := &entity.MFAChallenge{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		TokenHash:  crypto.HashToken(token),
+		Enrollment: enrollment,
+		ExpiresAt:  now.Add(challengeExpiry),
+		CreatedAt:  now,
+	}
+	if err := uc.challengeRepo.Create(ctx, challenge); err != nil {
+		return nil, err
+	}
+
+	return &entity.MFAPendingLogin{
+		Token:              token,
+		ExpiresAt:          challenge.ExpiresAt,
+		EnrollmentRequired: enrollment,
+	}, nil
+}
+
+// EnrollForLogin starts enrolment for a user the policy stopped at login
+func (uc *UseCase) EnrollForLogin(ctx context.Context, token string) (*entity.TOTPEnrollment, error) {
+	challenge, err := uc.challenge(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !challenge.Enrollment {
+		return nil, ErrAlreadyEnabled
+	}
+	return uc.StartEnrollment(ctx, challenge.UserID)
+}
+
+// CompleteLogin checks the second factor of a login and returns who logged
+// in. For an enrolment challenge the code also confirms the authenticator.
+func (uc *UseCase) CompleteLogin(ctx context.Context, token, code, recoveryCode string) (uuid.UUID, error) {
+	challenge, err := uc.challenge(ctx, token)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if challenge.Enrollment {
+		err = uc.ConfirmEnrollment(ctx, challenge.UserID, code)
+	} else {
+		err = uc.verify(ctx, challenge.UserID, code, recoveryCode)
+	}
+	if err != nil {
+		return uuid.Nil, uc.failAttempt(ctx, challenge, err)
+	}
+
+	if err := uc.challengeRepo.Delete(ctx, challenge.ID); err != nil {
+		return uuid.Nil, err
+	}
+	return challenge.UserID, nil
+}
+
+// ResetWithRecoveryCode removes the authenticator of a user who lost it,
+// given one of their recovery codes, and returns who it was. The login then
+// continues as for a user without two-factor authentication.
+func (uc *UseCase) ResetWithRecoveryCode(ctx context.Context, token, recoveryCode string) (uuid.UUID, error) {
+	challenge, err := uc.challenge(ctx, token)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if challenge.Enrollment {
+		return uuid.Nil, ErrNotEnrolled
+	}
+
+	if err := uc.verify(ctx, challenge.UserID, "", recoveryCode); err != nil {
+		return uuid.Nil, uc.failAttempt(ctx, challenge, err)
+	}
+
+	if err := uc.mfaRepo.DeleteTOTP(ctx, challenge.UserID); err != nil {
+		return uuid.Nil, err
+	}
+	if err := uc.challengeRepo.Delete(ctx, challenge.ID); err != nil {
+		return uuid.Nil, err
+	}
+	return challenge.UserID, nil
+}
+
+func (uc *UseCase) challenge(ctx context.Context, token string) (*entity.MFAChallenge, error) {
+	if token == "" {
+		return nil, ErrInvalidChallenge
+	}
+	challenge, err := uc.challengeRepo.GetByTokenHash(ctx, crypto.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if challenge == nil || time.Now().After(challenge.ExpiresAt) || challenge.Attempts >= maxAttempts {
+		return nil, ErrInvalidChallenge
+	}
+	return challenge, nil
+}
+
+// failAttempt counts a wrong code against the challenge, ending it after
+// maxAttempts, and passes other errors through
+func (uc *UseCase) failAttempt(ctx context.Context, challenge *entity.MFAChallenge, err error) error {
+	if !errors.Is(err, ErrInvalidCode) {
+		return err
+	}
+	attempts, aerr := uc.challengeRepo.AddAttempt(ctx, challenge.ID)
+	if aerr != nil {
+		return aerr
+	}
+	if attempts >= maxAttempts {
+		if derr := uc.challengeRepo.Delete(ctx, challenge.ID); derr != nil {
+			return derr
+		}
+		return ErrInvalidChallenge
+	}
+	return err
+}
+
+// verify checks a code from the user's authenticator, or uses up one of
+// their recovery codes
+func (uc *UseCase) verify(ctx context.Context, userID uuid.UUID, code, recoveryCode string) error {
+	t, err := uc.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if t == nil || !t.IsConfirmed() {
+		return ErrNotEnrolled
+	}
+
+	if recoveryCode != "" {
+		used, err := uc.mfaRepo.UseRecoveryCode(ctx, userID, hashRecoveryCode(recoveryCode), time.Now())
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidCode
+		}
+		return nil
+	}
+	return uc.checkTOTP(ctx, t, code)
+}
+
+// checkTOTP accepts a code once; the same code cannot be replayed
+func (uc *UseCase) checkTOTP(ctx context.Context, t *entity.UserTOTP, code string) error {
+	secret, err := uc.encryptor.Decrypt(t.SecretEncrypted)
+	if err != nil {
+		return err
+	}
+
+	step, ok := totp.Validate(string(secret), code, time.Now())
+	if !ok || step <= t.LastUsedStep {
+		return ErrInvalidCode
+	}
+	fresh, err := uc.mfaRepo.UseTOTPStep(ctx, t.UserID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return ErrInvalidCode
+	}
+	return nil
+}
+
+// required reports whether the policy requires two-factor from the user
+func (uc *UseCase) required(ctx context.Context, user *entity.User) (bool, error) {
+	policy, err := uc.policyRepo.Get(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	switch policy.Required {
+	case entity.MFARequiredAll:
+		return true, nil
+	case entity.MFARequiredOwners:
+		teams, err := uc.teamRepo.ListByUserID(ctx, user.ID)
+		if err != nil {
+			return false, err
+		}
+		for _, team := range teams {
+			if team.Role == entity.TeamRoleOwner {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (uc *UseCase) getUser(ctx context.Context, userID uuid.UUID) (*entity.User, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (uc *UseCase) requireSuperAdmin(ctx context.Context, userID uuid.UUID) error {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsSuperAdmin() {
+		return ErrNotSuperAdmin
+	}
+	return nil
+}
+
+// newRecoveryCodes returns recoveryCodeCount codes, formatted like
+// "k7m2p-x9qrt", and the records holding their hashes
+func newRecoveryCodes(userID uuid.UUID) ([]string, []entity.MFARecoveryCode, error) {
+	codes := make([]string, recoveryCodeCount)
+	records := make([]entity.MFARecoveryCode, recoveryCodeCount)
+	now := time.Now()
+
+	buf := make([]byte, 10)
+	for i := range codes {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		for j, b := range buf {
+			buf[j] = recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)]
+		}
+		codes[i] = string(buf[:5]) + "-" + string(buf[5:])
+		records[i] = entity.MFARecoveryCode{
+			ID:        uuid.New(),
+			UserID:    userID,
+			CodeHash:  hashRecoveryCode(codes[i]),
+			CreatedAt: now,
+		}
+	}
+	return codes, records, nil
+}
+
+// hashRecoveryCode ignores case, spaces and dashes, as codes are typed by hand
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	return crypto.HashToken(code)
+}
@@ -0,0 +1,733 @@
+package mfa_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/mocks"
+	"github.com/podoru/spinner-podoru/internal/usecase/mfa"
+	"github.com/podoru/spinner-podoru/pkg/crypto"
+	"github.com/podoru/spinner-podoru/pkg/totp"
+)
+
+// challengeToken is the plaintext MFA token of the challenges tests store
+const challengeToken = "mfa-token"
+
+// recoveryCode is a recovery code tests store, hashed as the use case does
+const recoveryCode = "abcde-fghjk"
+
+var recoveryCodeHash = crypto.HashToken("abcdefghjk")
+
+// code returns the authenticator code offset steps from now
+func code(t *testing.T, secret string, offset int64) string {
+	t.Helper()
+	c, err := totp.Code(secret, totp.Step(time.Now())+offset)
+	if err != nil {
+		t.Fatalf("failed to generate code: %v", err)
+	}
+	return c
+}
+
+// confirmedTOTP returns a confirmed authenticator for userID and its secret
+func confirmedTOTP(t *testing.T, encryptor *crypto.Encryptor, userID uuid.UUID) (*entity.UserTOTP, string) {
+	t.Helper()
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatalf("failed to generate secret: %v", err)
+	}
+	encrypted, err := encryptor.Encrypt([]byte(secret))
+	if err != nil {
+		t.Fatalf("failed to encrypt secret: %v", err)
+	}
+	confirmedAt := time.Now().Add(-time.Hour)
+	return &entity.UserTOTP{ID: uuid.New(), UserID: userID, SecretEncrypted: encrypted, ConfirmedAt: &confirmedAt}, secret
+}
+
+func TestStartEnrollment(t *testing.T) {
+	ctx := context.Background()
+	user := &entity.User{ID: uuid.New(), Email: "jane@example.com", IsActive: true}
+
+	encryptor, err := crypto.NewEncryptor("test-key")
+	if err != nil {
+		t.Fatalf("failed to create encryptor: %v", err)
+	}
+
+	var stored *entity.UserTOTP
+	var records []entity.MFARecoveryCode
+	mfaRepo := &mocks.MockMFARepository{
+		SaveTOTPFunc: func(ctx context.Context, record *entity.UserTOTP, codes []entity.MFARecoveryCode) error {
+			stored, records = record, codes
+			return nil
+		},
+	}
+
+	userRepo := &mocks.MockUserRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+			return user, nil
+		},
+	}
+
+	uc := mfa.NewUseCase(mfaRepo, &mocks.MockMFAChallengeRepository{}, &mocks.MockMFAPolicyRepository{}, userRepo, &mocks.MockTeamRepository{}, encryptor, "Podoru")
+
+	enrollment, err := uc.StartEnrollment(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("StartEnrollment() error = %v", err)
+	}
+	if len(enrollment.RecoveryCodes) != 10 || len(records) != 10 {
+		t.Errorf("got %d recovery codes and %d stored, want 10", len(enrollment.RecoveryCodes), len(records))
+	}
+	if want := "otpauth://totp/Podoru:jane@example.com?"; !strings.HasPrefix(enrollment.ProvisioningURI, want) {
+		t.Errorf("ProvisioningURI = %q, want prefix %q", enrollment.ProvisioningURI, want)
+	}
+	if stored == nil || stored.IsConfirmed() {
+		t.Fatal("expected an unconfirmed authenticator to be stored")
+	}
+	if string(stored.SecretEncrypted) == enrollment.Secret {
+		t.Error("expected the secret to be stored encrypted")
+	}
+	if records[0].CodeHash == enrollment.RecoveryCodes[0] {
+		t.Error("expected only hashes of the recovery codes to be stored")
+	}
+}
+
+func TestStartEnrollment_AlreadyEnabled(t *testing.T) {
+	encryptor, err := crypto.NewEncryptor("test-key")
+	if err != nil {
+		t.Fatalf("failed to create encryptor: %v", err)
+	}
+	userID := uuid.New()
+	existing, _ := confirmedTOTP(t, encryptor, userID)
+
+	mfaRepo := &mocks.MockMFARepository{
+		GetTOTPFunc: func(ctx context.Context, userID uuid.UUID) (*entity.UserTOTP, error) {
+			return existing, nil
+		},
+		SaveTOTPFunc: func(ctx context.Context, record *entity.UserTOTP, codes []entity.MFARecoveryCode) error {
+			t.Error("expected the authenticator not to be replaced")
+			return nil
+		},
+	}
+
+	userRepo := &mocks.MockUserRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+			return &entity.User{ID: id, IsActive: true}, nil
+		},
+	}
+
+	uc := mfa.NewUseCase(mfaRepo, &mocks.MockMFAChallengeRepository{}, &mocks.MockMFAPolicyRepository{}, userRepo, &mocks.MockTeamRepository{}, encryptor, "Podoru")
+
+	if _, err := uc.StartEnrollment(context.Background(), userID); !errors.Is(err, mfa.ErrAlreadyEnabled) {
+		t.Errorf("StartEnrollment() error = %v, want %v", err, mfa.ErrAlreadyEnabled)
+	}
+}
+
+func TestConfirmEnrollment(t *testing.T) {
+	tests := []struct {
+		name      string
+		enrolled  bool
+		confirmed bool
+		code      func(secret string) string
+		wantErr   error
+	}{
+		{name: "current code", enrolled: true, code: func(secret string) string { return code(t, secret, 0) }},
+		{name: "previous step", enrolled: true, code: func(secret string) string { return code(t, secret, -1) }},
+		{name: "wrong code", enrolled: true, code: func(string) string { return "000000" }, wantErr: mfa.ErrInvalidCode},
+		{name: "not enrolled", code: func(string) string { return "000000" }, wantErr: mfa.ErrNotEnrolled},
+		{name: "already confirmed", enrolled: true, confirmed: true, code: func(secret string) string { return code(t, secret, 0) }, wantErr: mfa.ErrAlreadyEnabled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := uuid.New()
+			encryptor, err := crypto.NewEncryptor("test-key")
+			if err != nil {
+				t.Fatalf("failed to create encryptor: %v", err)
+			}
+
+			stored, secret := confirmedTOTP(t, encryptor, userID)
+			if !tt.confirmed {
+				stored.ConfirmedAt = nil
+			}
+			if !tt.enrolled {
+				stored = nil
+			}
+
+			confirmed := false
+			mfaRepo := &mocks.MockMFARepository{
+				GetTOTPFunc: func(ctx context.Context, userID uuid.UUID) (*entity.UserTOTP, error) {
+					return stored, nil
+				},
+				UseTOTPStepFunc: func(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+					return true, nil
+				},
+				ConfirmTOTPFunc: func(ctx context.Context, userID uuid.UUID, confirmedAt time.Time) error {
+					confirmed = true
+					return nil
+				},
+			}
+
+			uc := mfa.NewUseCase(mfaRepo, &mocks.MockMFAChallengeRepository{}, &mocks.MockMFAPolicyRepository{}, &mocks.MockUserRepository{}, &mocks.MockTeamRepository{}, encryptor, "Podoru")
+
+			err = uc.ConfirmEnrollment(context.Background(), userID, tt.code(secret))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ConfirmEnrollment() error = %v, want %v", err, tt.wantErr)
+			}
+			if confirmed != (tt.wantErr == nil) {
+				t.Errorf("expected confirmed %v, got %v", tt.wantErr == nil, confirmed)
+			}
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	encryptor, err := crypto.NewEncryptor("test-key")
+	if err != nil {
+		t.Fatalf("failed to create encryptor: %v", err)
+	}
+	stored, _ := confirmedTOTP(t, encryptor, userID)
+
+	mfaRepo := &mocks.MockMFARepository{
+		GetTOTPFunc: func(ctx context.Context, userID uuid.UUID) (*entity.UserTOTP, error) {
+			return stored, nil
+		},
+		CountRecoveryCodesFunc: func(ctx context.Context, userID uuid.UUID) (int, error) {
+			return 7, nil
+		},
+	}
+
+	policyRepo := &mocks.MockMFAPolicyRepository{
+		GetFunc: func(ctx context.Context) (*entity.MFAPolicy, error) {
+			return &entity.MFAPolicy{Required: entity.MFARequiredAll}, nil
+		},
+	}
+
+	userRepo := &mocks.MockUserRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+			return &entity.User{ID: id, IsActive: true}, nil
+		},
+	}
+
+	uc := mfa.NewUseCase(mfaRepo, &mocks.MockMFAChallengeRepository{}, policyRepo, userRepo, &mocks.MockTeamRepository{}, encryptor, "Podoru")
+
+	status, err := uc.Status(ctx, userID)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if !status.Enabled || !status.Required || status.RecoveryCodesRemaining != 7 {
+		t.Errorf("Status() = %+v, want enabled and required with 7 recovery codes", status)
+	}
+
+	stored.ConfirmedAt = nil
+	status, err = uc.Status(ctx, userID)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if status.Enabled {
+		t.Error("expected two-factor to stay off until confirmed")
+	}
+}
+
+func TestBeginLogin(t *testing.T) {
+	tests := []struct {
+		name           string
+		enrolled       bool
+		policy         entity.MFARequirement
+		ownsTeam       bool
+		wantChallenge  bool
+		wantEnrollment bool
+	}{
+		{name: "not enrolled, none", policy: entity.MFARequiredNone, ownsTeam: true},
+		{name: "not enrolled, owners, member", policy: entity.MFARequiredOwners},
+		{name: "not enrolled, owners, owner", policy: entity.MFARequiredOwners, ownsTeam: true, wantChallenge: true, wantEnrollment: true},
+		{name: "not enrolled, all", policy: entity.MFARequiredAll, wantChallenge: true, wantEnrollment: true},
+		{name: "enrolled", enrolled: true, policy: entity.MFARequiredNone, wantChallenge: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &entity.User{ID: uuid.New(), IsActive: true}
+			encryptor, err := crypto.NewEncryptor("test-key")
+			if err != nil {
+				t.Fatalf("failed to create encryptor: %v", err)
+			}
+
+			var stored *entity.UserTOTP
+			if tt.enrolled {
+				stored, _ = confirmedTOTP(t, encryptor, user.ID)
+			}
+
+			mfaRepo := &mocks.MockMFARepository{
+				GetTOTPFunc: func(ctx context.Context, userID uuid.UUID) (*entity.UserTOTP, error) {
+					return stored, nil
+				},
+			}
+
+			var challenge *entity.MFAChallenge
+			challengeRepo := &mocks.MockMFAChallengeRepository{
+				CreateFunc: func(ctx context.Context, c *entity.MFAChallenge) error {
+					challenge = c
+					return nil
+				},
+			}
+
+			policyRepo := &mocks.MockMFAPolicyRepository{
+				GetFunc: func(ctx context.Context) (*entity.MFAPolicy, error) {
+					return &entity.MFAPolicy{Required: tt.policy}, nil
+				},
+			}
+
+			teamRepo := &mocks.MockTeamRepository{
+				ListByUserIDFunc: func(ctx context.Context, userID uuid.UUID) ([]entity.TeamWithRole, error) {
+					role := entity.TeamRoleMember
+					if tt.ownsTeam {
+						role = entity.TeamRoleOwner
+					}
+					return []entity.TeamWithRole{{Team: entity.Team{ID: uuid.New()}, Role: role}}, nil
+				},
+			}
+
+			uc := mfa.NewUseCase(mfaRepo, challengeRepo, policyRepo, &mocks.MockUserRepository{}, teamRepo, encryptor, "Podoru")
+
+			pending, err := uc.BeginLogin(context.Background(), user)
+			if err != nil {
+				t.Fatalf("BeginLogin() error = %v", err)
+			}
+			if (pending != nil) != tt.wantChallenge {
+				t.Fatalf("BeginLogin() = %+v, want challenge %v", pending, tt.wantChallenge)
+			}
+			if pending == nil {
+				return
+			}
+
+			if pending.EnrollmentRequired != tt.wantEnrollment {
+				t.Errorf("EnrollmentRequired = %v, want %v", pending.EnrollmentRequired, tt.wantEnrollment)
+			}
+			if challenge == nil || challenge.TokenHash != crypto.HashToken(pending.Token) {
+				t.Error("expected only the hash of the MFA token to be stored")
+			}
+		})
+	}
+}
+
+func TestCompleteLogin(t *testing.T) {
+	tests := []struct {
+		name         string
+		attempts     int
+		expired      bool
+		token        string
+		replayed     bool
+		code         func(secret string) string
+		recoveryCode string
+		wantErr      error
+		wantAttempt  bool
+		wantDeleted  bool
+	}{
+		{name: "current code", code: func(secret string) string { return code(t, secret, 0) }, wantDeleted: true},
+		{name: "recovery code", recoveryCode: recoveryCode, wantDeleted: true},
+		// Recovery codes are accepted in upper case and with a space for the dash
+		{name: "recovery code as typed", recoveryCode: "ABCDE FGHJK", wantDeleted: true},
+		{name: "replayed code", replayed: true, code: func(secret string) string { return code(t, secret, 0) }, wantErr: mfa.ErrInvalidCode, wantAttempt: true},
+		{name: "wrong code", code: func(string) string { return "000000" }, wantErr: mfa.ErrInvalidCode, wantAttempt: true},
+		{name: "unknown recovery code", recoveryCode: "zzzzz-zzzzz", wantErr: mfa.ErrInvalidCode, wantAttempt: true},
+		{name: "last attempt", attempts: 4, code: func(string) string { return "000000" }, wantErr: mfa.ErrInvalidChallenge, wantAttempt: true, wantDeleted: true},
+		{name: "attempts used up", attempts: 5, code: func(secret string) string { return code(t, secret, 0) }, wantErr: mfa.ErrInvalidChallenge},
+		{name: "expired", expired: true, code: func(secret string) string { return code(t, secret, 0) }, wantErr: mfa.ErrInvalidChallenge},
+		{name: "unknown token", token: "forged", code: func(secret string) string { return code(t, secret, 0) }, wantErr: mfa.ErrInvalidChallenge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := uuid.New()
+			encryptor, err := crypto.NewEncryptor("test-key")
+			if err != nil {
+				t.Fatalf("failed to create encryptor: %v", err)
+			}
+			stored, secret := confirmedTOTP(t, encryptor, userID)
+			if tt.replayed {
+				stored.LastUsedStep = totp.Step(time.Now())
+			}
+
+			mfaRepo := &mocks.MockMFARepository{
+				GetTOTPFunc: func(ctx context.Context, userID uuid.UUID) (*entity.UserTOTP, error) {
+					return stored, nil
+				},
+				UseTOTPStepFunc: func(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+					return step > stored.LastUsedStep, nil
+				},
+				UseRecoveryCodeFunc: func(ctx context.Context, userID uuid.UUID, codeHash string, usedAt time.Time) (bool, error) {
+					return codeHash == recoveryCodeHash, nil
+				},
+			}
+
+			challenge := &entity.MFAChallenge{
+				ID:        uuid.New(),
+				UserID:    userID,
+				TokenHash: crypto.HashToken(challengeToken),
+				Attempts:  tt.attempts,
+				ExpiresAt: time.Now().Add(time.Minute),
+			}
+			if tt.expired {
+				challenge.ExpiresAt = time.Now().Add(-time.Second)
+			}
+
+			attempted, deleted := false, false
+			challengeRepo := &mocks.MockMFAChallengeRepository{
+				GetByTokenHashFunc: func(ctx context.Context, tokenHash string) (*entity.MFAChallenge, error) {
+					if tokenHash != challenge.TokenHash {
+						return nil, nil
+					}
+					return challenge, nil
+				},
+				AddAttemptFunc: func(ctx context.Context, id uuid.UUID) (int, error) {
+					attempted = true
+					return challenge.Attempts + 1, nil
+				},
+				DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
+					deleted = true
+					return nil
+				},
+			}
+
+			uc := mfa.NewUseCase(mfaRepo, challengeRepo, &mocks.MockMFAPolicyRepository{}, &mocks.MockUserRepository{}, &mocks.MockTeamRepository{}, encryptor, "Podoru")
+
+			token := challengeToken
+			if tt.token != "" {
+				token = tt.token
+			}
+			var totpCode string
+			if tt.code != nil {
+				totpCode = tt.code(secret)
+			}
+
+			got, err := uc.CompleteLogin(context.Background(), token, totpCode, tt.recoveryCode)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CompleteLogin() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got != userID {
+				t.Errorf("CompleteLogin() = %v, want %v", got, userID)
+			}
+			if attempted != tt.wantAttempt {
+				t.Errorf("expected attempt counted %v, got %v", tt.wantAttempt, attempted)
+			}
+			if deleted != tt.wantDeleted {
+				t.Errorf("expected challenge deleted %v, got %v", tt.wantDeleted, deleted)
+			}
+		})
+	}
+}
+
+func TestCompleteLogin_Enrollment(t *testing.T) {
+	userID := uuid.New()
+	encryptor, err := crypto.NewEncryptor("test-key")
+	if err != nil {
+		t.Fatalf("failed to create encryptor: %v", err)
+	}
+	stored, secret := confirmedTOTP(t, encryptor, userID)
+	stored.ConfirmedAt = nil
+
+	confirmed := false
+	mfaRepo := &mocks.MockMFARepository{
+		GetTOTPFunc: func(ctx context.Context, userID uuid.UUID) (*entity.UserTOTP, error) {
+			return stored, nil
+		},
+		UseTOTPStepFunc: func(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+			return true, nil
+		},
+		ConfirmTOTPFunc: func(ctx context.Context, userID uuid.UUID, confirmedAt time.Time) error {
+			confirmed = true
+			return nil
+		},
+	}
+
+	challengeRepo := &mocks.MockMFAChallengeRepository{
+		GetByTokenHashFunc: func(ctx context.Context, tokenHash string) (*entity.MFAChallenge, error) {
+			return &entity.MFAChallenge{ID: uuid.New(), UserID: userID, TokenHash: tokenHash, Enrollment: true, ExpiresAt: time.Now().Add(time.Minute)}, nil
+		},
+	}
+
+	uc := mfa.NewUseCase(mfaRepo, challengeRepo, &mocks.MockMFAPolicyRepository{}, &mocks.MockUserRepository{}, &mocks.MockTeamRepository{}, encryptor, "Podoru")
+
+	got, err := uc.CompleteLogin(context.Background(), challengeToken, code(t, secret, 0), "")
+	if err != nil {
+		t.Fatalf("CompleteLogin() error = %v", err)
+	}
+	if got != userID || !confirmed {
+		t.Error("expected the login to confirm the new authenticator")
+	}
+}
+
+func TestEnrollForLogin(t *testing.T) {
+	tests := []struct {
+		name       string
+		enrollment bool
+		wantErr    error
+	}{
+		{name: "enrolment challenge", enrollment: true},
+		{name: "verification challenge", wantErr: mfa.ErrAlreadyEnabled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := uuid.New()
+			encryptor, err := crypto.NewEncryptor("test-key")
+			if err != nil {
+				t.Fatalf("failed to create encryptor: %v", err)
+			}
+
+			saved := false
+			mfaRepo := &mocks.MockMFARepository{
+				SaveTOTPFunc: func(ctx context.Context, record *entity.UserTOTP, codes []entity.MFARecoveryCode) error {
+					saved = true
+					return nil
+				},
+			}
+
+			challengeRepo := &mocks.MockMFAChallengeRepository{
+				GetByTokenHashFunc: func(ctx context.Context, tokenHash string) (*entity.MFAChallenge, error) {
+					return &entity.MFAChallenge{ID: uuid.New(), UserID: userID, TokenHash: tokenHash, Enrollment: tt.enrollment, ExpiresAt: time.Now().Add(time.Minute)}, nil
+				},
+			}
+
+			userRepo := &mocks.MockUserRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+					return &entity.User{ID: id, Email: "jane@example.com", IsActive: true}, nil
+				},
+			}
+
+			uc := mfa.NewUseCase(mfaRepo, challengeRepo, &mocks.MockMFAPolicyRepository{}, userRepo, &mocks.MockTeamRepository{}, encryptor, "Podoru")
+
+			_, err = uc.EnrollForLogin(context.Background(), challengeToken)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("EnrollForLogin() error = %v, want %v", err, tt.wantErr)
+			}
+			if saved != (tt.wantErr == nil) {
+				t.Errorf("expected an authenticator saved %v, got %v", tt.wantErr == nil, saved)
+			}
+		})
+	}
+}
+
+func TestResetWithRecoveryCode(t *testing.T) {
+	tests := []struct {
+		name         string
+		enrollment   bool
+		recoveryCode string
+		wantErr      error
+	}{
+		{name: "recovery code", recoveryCode: recoveryCode},
+		{name: "wrong recovery code", recoveryCode: "aaaaa-aaaaa", wantErr: mfa.ErrInvalidCode},
+		{name: "during enrolment", enrollment: true, recoveryCode: recoveryCode, wantErr: mfa.ErrNotEnrolled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := uuid.New()
+			encryptor, err := crypto.NewEncryptor("test-key")
+			if err != nil {
+				t.Fatalf("failed to create encryptor: %v", err)
+			}
+			stored, _ := confirmedTOTP(t, encryptor, userID)
+
+			removed := false
+			mfaRepo := &mocks.MockMFARepository{
+				GetTOTPFunc: func(ctx context.Context, userID uuid.UUID) (*entity.UserTOTP, error) {
+					return stored, nil
+				},
+				UseRecoveryCodeFunc: func(ctx context.Context, userID uuid.UUID, codeHash string, usedAt time.Time) (bool, error) {
+					return codeHash == recoveryCodeHash, nil
+				},
+				DeleteTOTPFunc: func(ctx context.Context, userID uuid.UUID) error {
+					removed = true
+					return nil
+				},
+			}
+
+			challengeRepo := &mocks.MockMFAChallengeRepository{
+				GetByTokenHashFunc: func(ctx context.Context, tokenHash string) (*entity.MFAChallenge, error) {
+					return &entity.MFAChallenge{ID: uuid.New(), UserID: userID, TokenHash: tokenHash, Enrollment: tt.enrollment, ExpiresAt: time.Now().Add(time.Minute)}, nil
+				},
+				AddAttemptFunc: func(ctx context.Context, id uuid.UUID) (int, error) {
+					return 1, nil
+				},
+			}
+
+			uc := mfa.NewUseCase(mfaRepo, challengeRepo, &mocks.MockMFAPolicyRepository{}, &mocks.MockUserRepository{}, &mocks.MockTeamRepository{}, encryptor, "Podoru")
+
+			got, err := uc.ResetWithRecoveryCode(context.Background(), challengeToken, tt.recoveryCode)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ResetWithRecoveryCode() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got != userID {
+				t.Errorf("ResetWithRecoveryCode() = %v, want %v", got, userID)
+			}
+			if removed != (tt.wantErr == nil) {
+				t.Errorf("expected the authenticator removed %v, got %v", tt.wantErr == nil, removed)
+			}
+		})
+	}
+}
+
+func TestDisable(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  entity.MFARequirement
+		code    func(secret string) string
+		wantErr error
+	}{
+		{name: "current code", policy: entity.MFARequiredNone, code: func(secret string) string { return code(t, secret, 0) }},
+		{name: "wrong code", policy: entity.MFARequiredNone, code: func(string) string { return "000000" }, wantErr: mfa.ErrInvalidCode},
+		{name: "required by policy", policy: entity.MFARequiredAll, code: func(secret string) string { return code(t, secret, 0) }, wantErr: mfa.ErrRequiredByPolicy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := uuid.New()
+			encryptor, err := crypto.NewEncryptor("test-key")
+			if err != nil {
+				t.Fatalf("failed to create encryptor: %v", err)
+			}
+			stored, secret := confirmedTOTP(t, encryptor, userID)
+
+			removed := false
+			mfaRepo := &mocks.MockMFARepository{
+				GetTOTPFunc: func(ctx context.Context, userID uuid.UUID) (*entity.UserTOTP, error) {
+					return stored, nil
+				},
+				UseTOTPStepFunc: func(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+					return true, nil
+				},
+				DeleteTOTPFunc: func(ctx context.Context, userID uuid.UUID) error {
+					removed = true
+					return nil
+				},
+			}
+
+			policyRepo := &mocks.MockMFAPolicyRepository{
+				GetFunc: func(ctx context.Context) (*entity.MFAPolicy, error) {
+					return &entity.MFAPolicy{Required: tt.policy}, nil
+				},
+			}
+
+			userRepo := &mocks.MockUserRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+					return &entity.User{ID: id, IsActive: true}, nil
+				},
+			}
+
+			uc := mfa.NewUseCase(mfaRepo, &mocks.MockMFAChallengeRepository{}, policyRepo, userRepo, &mocks.MockTeamRepository{}, encryptor, "Podoru")
+
+			err = uc.Disable(context.Background(), userID, tt.code(secret), "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Disable() error = %v, want %v", err, tt.wantErr)
+			}
+			if removed != (tt.wantErr == nil) {
+				t.Errorf("expected the authenticator removed %v, got %v", tt.wantErr == nil, removed)
+			}
+		})
+	}
+}
+
+func TestRegenerateRecoveryCodes(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	encryptor, err := crypto.NewEncryptor("test-key")
+	if err != nil {
+		t.Fatalf("failed to create encryptor: %v", err)
+	}
+	stored, secret := confirmedTOTP(t, encryptor, userID)
+
+	var records []entity.MFARecoveryCode
+	mfaRepo := &mocks.MockMFARepository{
+		GetTOTPFunc: func(ctx context.Context, userID uuid.UUID) (*entity.UserTOTP, error) {
+			return stored, nil
+		},
+		UseTOTPStepFunc: func(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+			return true, nil
+		},
+		ReplaceRecoveryCodesFunc: func(ctx context.Context, userID uuid.UUID, codes []entity.MFARecoveryCode) error {
+			records = codes
+			return nil
+		},
+	}
+
+	uc := mfa.NewUseCase(mfaRepo, &mocks.MockMFAChallengeRepository{}, &mocks.MockMFAPolicyRepository{}, &mocks.MockUserRepository{}, &mocks.MockTeamRepository{}, encryptor, "Podoru")
+
+	if _, err := uc.RegenerateRecoveryCodes(ctx, userID, "000000"); !errors.Is(err, mfa.ErrInvalidCode) {
+		t.Errorf("RegenerateRecoveryCodes() with a wrong code error = %v, want %v", err, mfa.ErrInvalidCode)
+	}
+	if records != nil {
+		t.Fatal("expected the recovery codes to stay after a wrong code")
+	}
+
+	codes, err := uc.RegenerateRecoveryCodes(ctx, userID, code(t, secret, 0))
+	if err != nil {
+		t.Fatalf("RegenerateRecoveryCodes() error = %v", err)
+	}
+	if len(codes) != 10 || len(records) != 10 {
+		t.Fatalf("got %d recovery codes and %d stored, want 10", len(codes), len(records))
+	}
+	if records[0].CodeHash != crypto.HashToken(strings.ReplaceAll(codes[0], "-", "")) {
+		t.Error("expected the new recovery codes to replace the stored ones")
+	}
+}
+
+func TestUpdatePolicy(t *testing.T) {
+	user := &entity.User{ID: uuid.New(), Role: entity.UserRoleUser, IsActive: true}
+	admin := &entity.User{ID: uuid.New(), Role: entity.UserRoleSuperAdmin, IsActive: true}
+
+	tests := []struct {
+		name    string
+		actor   *entity.User
+		wantErr error
+	}{
+		{name: "user", actor: user, wantErr: mfa.ErrNotSuperAdmin},
+		{name: "superadmin", actor: admin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored *entity.MFAPolicy
+			policyRepo := &mocks.MockMFAPolicyRepository{
+				UpdateFunc: func(ctx context.Context, policy *entity.MFAPolicy) error {
+					stored = policy
+					return nil
+				},
+			}
+
+			userRepo := &mocks.MockUserRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+					return tt.actor, nil
+				},
+			}
+
+			uc := mfa.NewUseCase(&mocks.MockMFARepository{}, &mocks.MockMFAChallengeRepository{}, policyRepo, userRepo, &mocks.MockTeamRepository{}, nil, "Podoru")
+
+			policy, err := uc.UpdatePolicy(context.Background(), tt.actor.ID, &entity.MFAPolicyUpdate{Required: entity.MFARequiredOwners})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdatePolicy() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if stored != nil {
+					t.Error("expected the policy not to be stored")
+				}
+				return
+			}
+
+			if policy.Required != entity.MFARequiredOwners || policy.UpdatedBy == nil || *policy.UpdatedBy != admin.ID {
+				t.Errorf("UpdatePolicy() = %+v", policy)
+			}
+			if stored == nil || stored.Required != entity.MFARequiredOwners {
+				t.Errorf("stored policy = %+v, want %q", stored, entity.MFARequiredOwners)
+			}
+		})
+	}
+}
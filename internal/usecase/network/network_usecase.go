@@ -0,0 +1,281 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	domainDocker "github.com/podoru/spinner-podoru/internal/domain/docker"
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/domain/repository"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/config"
+)
+
+var (
+	ErrNetworkNotFound      = errors.New("network not found")
+	ErrProjectNotFound      = errors.New("project not found")
+	ErrServiceNotFound      = errors.New("service not found")
+	ErrNotTeamMember        = errors.New("not a team member")
+	ErrNotTeamAdmin         = errors.New("requires admin or owner role")
+	ErrNetworkNameTaken     = errors.New("network name already exists in project")
+	ErrDefaultNetwork       = errors.New("the default project network cannot be changed")
+	ErrGatewayWithoutSubnet = errors.New("gateway requires a subnet")
+)
+
+type UseCase struct {
+	networkRepo      repository.NetworkRepository
+	projectRepo      repository.ProjectRepository
+	serviceRepo      repository.ServiceRepository
+	teamMemberRepo   repository.TeamMemberRepository
+	containerManager domainDocker.ContainerManager
+	dockerConfig     *config.DockerConfig
+}
+
+func NewUseCase(
+	networkRepo repository.NetworkRepository,
+	projectRepo repository.ProjectRepository,
+	serviceRepo repository.ServiceRepository,
+	teamMemberRepo repository.TeamMemberRepository,
+	containerManager domainDocker.ContainerManager,
+	dockerConfig *config.DockerConfig,
+) *UseCase {
+	return &UseCase{
+		networkRepo:      networkRepo,
+		projectRepo:      projectRepo,
+		serviceRepo:      serviceRepo,
+		teamMemberRepo:   teamMemberRepo,
+		containerManager: containerManager,
+		dockerConfig:     dockerConfig,
+	}
+}
+
+// List returns the networks of a project with the services attached to each.
+// Every service is on the default network.
+func (uc *UseCase) List(ctx context.Context, userID, projectID uuid.UUID) ([]entity.Network, error) {
+	if _, err := uc.getProjectWithTeamCheck(ctx, userID, projectID); err != nil {
+		return nil, err
+	}
+
+	networks, err := uc.networkRepo.ListByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range networks {
+		if networks[i].IsDefault {
+			services, err := uc.serviceRepo.ListByProjectID(ctx, projectID)
+			if err != nil {
+				return nil, err
+			}
+			for _, s := range services {
+				networks[i].ServiceIDs = append(networks[i].ServiceIDs, s.ID)
+			}
+			continue
+		}
+
+		ids, err := uc.networkRepo.ListServiceIDs(ctx, networks[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		networks[i].ServiceIDs = ids
+	}
+
+	return networks, nil
+}
+
+// Create adds a custom network to a project. Requires admin or owner role.
+func (uc *UseCase) Create(ctx context.Context, userID, projectID uuid.UUID, input *entity.NetworkCreate) (*entity.Network, error) {
+	member, err := uc.getProjectWithTeamCheck(ctx, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role == entity.TeamRoleMember {
+		return nil, ErrNotTeamAdmin
+	}
+
+	if input.Gateway != nil && input.Subnet == nil {
+		return nil, ErrGatewayWithoutSubnet
+	}
+
+	existing, err := uc.networkRepo.GetByProjectAndName(ctx, projectID, input.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrNetworkNameTaken
+	}
+
+	driver := entity.NetworkDriver(uc.dockerConfig.NetworkDriver)
+	if input.Driver != nil {
+		driver = *input.Driver
+	}
+
+	network := &entity.Network{
+		ID:        uuid.New(),
+		ProjectID: &projectID,
+		Name:      input.Name,
+		Driver:    driver,
+		Subnet:    input.Subnet,
+		Gateway:   input.Gateway,
+		CreatedAt: time.Now(),
+	}
+
+	dockerID, err := uc.containerManager.CreateNetwork(ctx, domainDocker.ProjectNetworkConfig(network))
+	if err != nil {
+		return nil, err
+	}
+	network.DockerNetworkID = &dockerID
+
+	if err := uc.networkRepo.Create(ctx, network); err != nil {
+		_ = uc.containerManager.RemoveNetwork(ctx, dockerID)
+		return nil, err
+	}
+
+	return network, nil
+}
+
+// Delete removes a custom network, detaching any running containers first.
+// Requires admin or owner role.
+func (uc *UseCase) Delete(ctx context.Context, userID, projectID, networkID uuid.UUID) error {
+	member, err := uc.getProjectWithTeamCheck(ctx, userID, projectID)
+	if err != nil {
+		return err
+	}
+	if member.Role == entity.TeamRoleMember {
+		return ErrNotTeamAdmin
+	}
+
+	network, err := uc.getNetwork(ctx, projectID, networkID)
+	if err != nil {
+		return err
+	}
+	if network.IsDefault {
+		return ErrDefaultNetwork
+	}
+
+	if network.DockerNetworkID != nil {
+		serviceIDs, err := uc.networkRepo.ListServiceIDs(ctx, network.ID)
+		if err != nil {
+			return err
+		}
+		for _, serviceID := range serviceIDs {
+			service, err := uc.serviceRepo.GetByID(ctx, serviceID)
+			if err != nil {
+				return err
+			}
+			if service == nil || service.ContainerID == nil || *service.ContainerID == "" {
+				continue
+			}
+			if err := uc.containerManager.DisconnectNetwork(ctx, *network.DockerNetworkID, *service.ContainerID); err != nil {
+				return err
+			}
+		}
+
+		if err := uc.containerManager.RemoveNetwork(ctx, *network.DockerNetworkID); err != nil {
+			return err
+		}
+	}
+
+	return uc.networkRepo.Delete(ctx, network.ID)
+}
+
+// AttachService joins a service to a custom network under its slug. A running
+// container is connected straight away, otherwise on its next deploy.
+func (uc *UseCase) AttachService(ctx context.Context, userID, projectID, networkID, serviceID uuid.UUID) error {
+	network, service, err := uc.getNetworkAndService(ctx, userID, projectID, networkID, serviceID)
+	if err != nil {
+		return err
+	}
+
+	attached, err := uc.networkRepo.ListServiceIDs(ctx, network.ID)
+	if err != nil {
+		return err
+	}
+	for _, id := range attached {
+		if id == service.ID {
+			return nil
+		}
+	}
+
+	if err := uc.networkRepo.AttachService(ctx, network.ID, service.ID); err != nil {
+		return err
+	}
+
+	if network.DockerNetworkID != nil && service.ContainerID != nil && *service.ContainerID != "" {
+		return uc.containerManager.ConnectNetwork(ctx, *network.DockerNetworkID, *service.ContainerID, []string{service.Slug})
+	}
+	return nil
+}
+
+// DetachService removes a service from a custom network
+func (uc *UseCase) DetachService(ctx context.Context, userID, projectID, networkID, serviceID uuid.UUID) error {
+	network, service, err := uc.getNetworkAndService(ctx, userID, projectID, networkID, serviceID)
+	if err != nil {
+		return err
+	}
+
+	if network.DockerNetworkID != nil && service.ContainerID != nil && *service.ContainerID != "" {
+		if err := uc.containerManager.DisconnectNetwork(ctx, *network.DockerNetworkID, *service.ContainerID); err != nil {
+			return err
+		}
+	}
+
+	return uc.networkRepo.DetachService(ctx, network.ID, service.ID)
+}
+
+func (uc *UseCase) getNetworkAndService(ctx context.Context, userID, projectID, networkID, serviceID uuid.UUID) (*entity.Network, *entity.Service, error) {
+	if _, err := uc.getProjectWithTeamCheck(ctx, userID, projectID); err != nil {
+		return nil, nil, err
+	}
+
+	network, err := uc.getNetwork(ctx, projectID, networkID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if network.IsDefault {
+		return nil, nil, ErrDefaultNetwork
+	}
+
+	service, err := uc.serviceRepo.GetByID(ctx, serviceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if service == nil || service.ProjectID != projectID {
+		return nil, nil, ErrServiceNotFound
+	}
+
+	return network, service, nil
+}
+
+func (uc *UseCase) getNetwork(ctx context.Context, projectID, networkID uuid.UUID) (*entity.Network, error) {
+	network, err := uc.networkRepo.GetByID(ctx, networkID)
+	if err != nil {
+		return nil, err
+	}
+	if network == nil || network.ProjectID == nil || *network.ProjectID != projectID {
+		return nil, ErrNetworkNotFound
+	}
+	return network, nil
+}
+
+func (uc *UseCase) getProjectWithTeamCheck(ctx context.Context, userID, projectID uuid.UUID) (*entity.TeamMember, error) {
+	project, err := uc.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, ErrProjectNotFound
+	}
+
+	member, err := uc.teamMemberRepo.GetByTeamAndUser(ctx, project.TeamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrNotTeamMember
+	}
+
+	return member, nil
+}
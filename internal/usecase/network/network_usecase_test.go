@@ -0,0 +1,596 @@
+package network_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	domainDocker "github.com/podoru/spinner-podoru/internal/domain/docker"
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/config"
+	"github.com/podoru/spinner-podoru/internal/mocks"
+	"github.com/podoru/spinner-podoru/internal/usecase/network"
+)
+
+func TestCreate_Success(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+	subnet := "10.10.0.0/24"
+
+	var created *entity.Network
+	networkRepo := &mocks.MockNetworkRepository{
+		CreateFunc: func(ctx context.Context, n *entity.Network) error {
+			created = n
+			return nil
+		},
+	}
+
+	containerManager := &mocks.MockContainerManager{
+		CreateNetworkFunc: func(ctx context.Context, cfg *domainDocker.NetworkConfig) (string, error) {
+			if cfg.Name != entity.DockerNetworkName(projectID, "backend") {
+				t.Errorf("unexpected docker network name %s", cfg.Name)
+			}
+			if cfg.Driver != "bridge" {
+				t.Errorf("expected default driver bridge, got %s", cfg.Driver)
+			}
+			if cfg.Subnet != subnet {
+				t.Errorf("expected subnet %s, got %s", subnet, cfg.Subnet)
+			}
+			if cfg.Labels["podoru.project.id"] != projectID.String() || cfg.Labels["podoru.managed"] != "true" {
+				t.Errorf("unexpected labels %v", cfg.Labels)
+			}
+			return "docker-net-1", nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleAdmin}, nil
+		},
+	}
+
+	uc := network.NewUseCase(networkRepo, projectRepo, &mocks.MockServiceRepository{},
+		teamMemberRepo, containerManager, &config.DockerConfig{NetworkDriver: "bridge"})
+
+	result, err := uc.Create(ctx, userID, projectID, &entity.NetworkCreate{Name: "backend", Subnet: &subnet})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if created == nil || created.ID != result.ID {
+		t.Fatal("expected network to be stored")
+	}
+	if result.DockerNetworkID == nil || *result.DockerNetworkID != "docker-net-1" {
+		t.Errorf("expected docker network ID docker-net-1, got %v", result.DockerNetworkID)
+	}
+}
+
+func TestCreate_NotAdmin(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	containerManager := &mocks.MockContainerManager{
+		CreateNetworkFunc: func(ctx context.Context, cfg *domainDocker.NetworkConfig) (string, error) {
+			t.Error("expected no docker network to be created")
+			return "", nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleMember}, nil
+		},
+	}
+
+	uc := network.NewUseCase(&mocks.MockNetworkRepository{}, projectRepo, &mocks.MockServiceRepository{},
+		teamMemberRepo, containerManager, &config.DockerConfig{})
+
+	_, err := uc.Create(ctx, uuid.New(), projectID, &entity.NetworkCreate{Name: "backend"})
+	if err != network.ErrNotTeamAdmin {
+		t.Errorf("expected ErrNotTeamAdmin, got %v", err)
+	}
+}
+
+func TestCreate_GatewayWithoutSubnet(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	gateway := "10.10.0.1"
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleOwner}, nil
+		},
+	}
+
+	uc := network.NewUseCase(&mocks.MockNetworkRepository{}, projectRepo, &mocks.MockServiceRepository{},
+		teamMemberRepo, &mocks.MockContainerManager{}, &config.DockerConfig{})
+
+	_, err := uc.Create(ctx, uuid.New(), projectID, &entity.NetworkCreate{Name: "backend", Gateway: &gateway})
+	if err != network.ErrGatewayWithoutSubnet {
+		t.Errorf("expected ErrGatewayWithoutSubnet, got %v", err)
+	}
+}
+
+func TestCreate_NameTaken(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	networkRepo := &mocks.MockNetworkRepository{
+		GetByProjectAndNameFunc: func(ctx context.Context, pID uuid.UUID, name string) (*entity.Network, error) {
+			return &entity.Network{ID: uuid.New(), ProjectID: &pID, Name: name}, nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleOwner}, nil
+		},
+	}
+
+	uc := network.NewUseCase(networkRepo, projectRepo, &mocks.MockServiceRepository{},
+		teamMemberRepo, &mocks.MockContainerManager{}, &config.DockerConfig{})
+
+	_, err := uc.Create(ctx, uuid.New(), projectID, &entity.NetworkCreate{Name: "backend"})
+	if err != network.ErrNetworkNameTaken {
+		t.Errorf("expected ErrNetworkNameTaken, got %v", err)
+	}
+}
+
+func TestCreate_StoreFailureRemovesDockerNetwork(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	storeErr := errors.New("insert failed")
+
+	networkRepo := &mocks.MockNetworkRepository{
+		CreateFunc: func(ctx context.Context, n *entity.Network) error {
+			return storeErr
+		},
+	}
+
+	var removed string
+	containerManager := &mocks.MockContainerManager{
+		CreateNetworkFunc: func(ctx context.Context, cfg *domainDocker.NetworkConfig) (string, error) {
+			return "docker-net-1", nil
+		},
+		RemoveNetworkFunc: func(ctx context.Context, networkID string) error {
+			removed = networkID
+			return nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleOwner}, nil
+		},
+	}
+
+	uc := network.NewUseCase(networkRepo, projectRepo, &mocks.MockServiceRepository{},
+		teamMemberRepo, containerManager, &config.DockerConfig{})
+
+	_, err := uc.Create(ctx, uuid.New(), projectID, &entity.NetworkCreate{Name: "backend"})
+	if !errors.Is(err, storeErr) {
+		t.Fatalf("expected store error, got %v", err)
+	}
+	if removed != "docker-net-1" {
+		t.Errorf("expected docker network to be removed, got %q", removed)
+	}
+}
+
+func TestDelete_Success(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	networkID := uuid.New()
+	dockerID := "docker-net-1"
+	containerID := "container-1"
+	running := uuid.New()
+	stopped := uuid.New()
+
+	var deleted uuid.UUID
+	networkRepo := &mocks.MockNetworkRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Network, error) {
+			return &entity.Network{ID: id, ProjectID: &projectID, Name: "backend", DockerNetworkID: &dockerID}, nil
+		},
+		ListServiceIDsFunc: func(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+			return []uuid.UUID{running, stopped}, nil
+		},
+		DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
+			deleted = id
+			return nil
+		},
+	}
+
+	serviceRepo := &mocks.MockServiceRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+			if id == running {
+				return &entity.Service{ID: id, ProjectID: projectID, ContainerID: &containerID}, nil
+			}
+			return &entity.Service{ID: id, ProjectID: projectID}, nil
+		},
+	}
+
+	var disconnected []string
+	var removed string
+	containerManager := &mocks.MockContainerManager{
+		DisconnectNetworkFunc: func(ctx context.Context, netID, cID string) error {
+			disconnected = append(disconnected, cID)
+			return nil
+		},
+		RemoveNetworkFunc: func(ctx context.Context, netID string) error {
+			removed = netID
+			return nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleAdmin}, nil
+		},
+	}
+
+	uc := network.NewUseCase(networkRepo, projectRepo, serviceRepo,
+		teamMemberRepo, containerManager, &config.DockerConfig{})
+
+	if err := uc.Delete(ctx, uuid.New(), projectID, networkID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(disconnected) != 1 || disconnected[0] != containerID {
+		t.Errorf("expected only the running container to be disconnected, got %v", disconnected)
+	}
+	if removed != dockerID {
+		t.Errorf("expected docker network %s to be removed, got %q", dockerID, removed)
+	}
+	if deleted != networkID {
+		t.Errorf("expected network %s to be deleted, got %s", networkID, deleted)
+	}
+}
+
+func TestDelete_DefaultNetwork(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	networkRepo := &mocks.MockNetworkRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Network, error) {
+			return &entity.Network{ID: id, ProjectID: &projectID, Name: "default", IsDefault: true}, nil
+		},
+		DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
+			t.Error("expected the default network to be kept")
+			return nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleOwner}, nil
+		},
+	}
+
+	uc := network.NewUseCase(networkRepo, projectRepo, &mocks.MockServiceRepository{},
+		teamMemberRepo, &mocks.MockContainerManager{}, &config.DockerConfig{})
+
+	if err := uc.Delete(ctx, uuid.New(), projectID, uuid.New()); err != network.ErrDefaultNetwork {
+		t.Errorf("expected ErrDefaultNetwork, got %v", err)
+	}
+}
+
+func TestDelete_NotAdmin(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	networkRepo := &mocks.MockNetworkRepository{
+		DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
+			t.Error("expected network to be kept")
+			return nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleMember}, nil
+		},
+	}
+
+	uc := network.NewUseCase(networkRepo, projectRepo, &mocks.MockServiceRepository{},
+		teamMemberRepo, &mocks.MockContainerManager{}, &config.DockerConfig{})
+
+	if err := uc.Delete(ctx, uuid.New(), projectID, uuid.New()); err != network.ErrNotTeamAdmin {
+		t.Errorf("expected ErrNotTeamAdmin, got %v", err)
+	}
+}
+
+func TestDelete_NetworkOfAnotherProject(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	otherProjectID := uuid.New()
+
+	networkRepo := &mocks.MockNetworkRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Network, error) {
+			return &entity.Network{ID: id, ProjectID: &otherProjectID, Name: "backend"}, nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleOwner}, nil
+		},
+	}
+
+	uc := network.NewUseCase(networkRepo, projectRepo, &mocks.MockServiceRepository{},
+		teamMemberRepo, &mocks.MockContainerManager{}, &config.DockerConfig{})
+
+	if err := uc.Delete(ctx, uuid.New(), projectID, uuid.New()); err != network.ErrNetworkNotFound {
+		t.Errorf("expected ErrNetworkNotFound, got %v", err)
+	}
+}
+
+func TestAttachService_Success(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	serviceID := uuid.New()
+	dockerID := "docker-net-1"
+	containerID := "container-1"
+
+	var attached bool
+	networkRepo := &mocks.MockNetworkRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Network, error) {
+			return &entity.Network{ID: id, ProjectID: &projectID, Name: "backend", DockerNetworkID: &dockerID}, nil
+		},
+		AttachServiceFunc: func(ctx context.Context, networkID, sID uuid.UUID) error {
+			attached = sID == serviceID
+			return nil
+		},
+	}
+
+	serviceRepo := &mocks.MockServiceRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+			return &entity.Service{ID: id, ProjectID: projectID, Slug: "api", ContainerID: &containerID}, nil
+		},
+	}
+
+	var aliases []string
+	containerManager := &mocks.MockContainerManager{
+		ConnectNetworkFunc: func(ctx context.Context, netID, cID string, a []string) error {
+			if netID != dockerID || cID != containerID {
+				t.Errorf("unexpected connect %s to %s", cID, netID)
+			}
+			aliases = a
+			return nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleMember}, nil
+		},
+	}
+
+	uc := network.NewUseCase(networkRepo, projectRepo, serviceRepo,
+		teamMemberRepo, containerManager, &config.DockerConfig{})
+
+	if err := uc.AttachService(ctx, uuid.New(), projectID, uuid.New(), serviceID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !attached {
+		t.Error("expected service to be attached")
+	}
+	if len(aliases) != 1 || aliases[0] != "api" {
+		t.Errorf("expected alias api, got %v", aliases)
+	}
+}
+
+func TestAttachService_DefaultNetwork(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	networkRepo := &mocks.MockNetworkRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Network, error) {
+			return &entity.Network{ID: id, ProjectID: &projectID, Name: "default", IsDefault: true}, nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleMember}, nil
+		},
+	}
+
+	uc := network.NewUseCase(networkRepo, projectRepo, &mocks.MockServiceRepository{},
+		teamMemberRepo, &mocks.MockContainerManager{}, &config.DockerConfig{})
+
+	if err := uc.AttachService(ctx, uuid.New(), projectID, uuid.New(), uuid.New()); err != network.ErrDefaultNetwork {
+		t.Errorf("expected ErrDefaultNetwork, got %v", err)
+	}
+}
+
+func TestAttachService_ServiceOfAnotherProject(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	networkRepo := &mocks.MockNetworkRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Network, error) {
+			return &entity.Network{ID: id, ProjectID: &projectID, Name: "backend"}, nil
+		},
+		AttachServiceFunc: func(ctx context.Context, networkID, serviceID uuid.UUID) error {
+			t.Error("expected service not to be attached")
+			return nil
+		},
+	}
+
+	serviceRepo := &mocks.MockServiceRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+			return &entity.Service{ID: id, ProjectID: uuid.New()}, nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleMember}, nil
+		},
+	}
+
+	uc := network.NewUseCase(networkRepo, projectRepo, serviceRepo,
+		teamMemberRepo, &mocks.MockContainerManager{}, &config.DockerConfig{})
+
+	if err := uc.AttachService(ctx, uuid.New(), projectID, uuid.New(), uuid.New()); err != network.ErrServiceNotFound {
+		t.Errorf("expected ErrServiceNotFound, got %v", err)
+	}
+}
+
+func TestDetachService_Success(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	serviceID := uuid.New()
+	dockerID := "docker-net-1"
+	containerID := "container-1"
+
+	var detached bool
+	networkRepo := &mocks.MockNetworkRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Network, error) {
+			return &entity.Network{ID: id, ProjectID: &projectID, Name: "backend", DockerNetworkID: &dockerID}, nil
+		},
+		DetachServiceFunc: func(ctx context.Context, networkID, sID uuid.UUID) error {
+			detached = sID == serviceID
+			return nil
+		},
+	}
+
+	serviceRepo := &mocks.MockServiceRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+			return &entity.Service{ID: id, ProjectID: projectID, ContainerID: &containerID}, nil
+		},
+	}
+
+	var disconnected string
+	containerManager := &mocks.MockContainerManager{
+		DisconnectNetworkFunc: func(ctx context.Context, netID, cID string) error {
+			disconnected = cID
+			return nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleMember}, nil
+		},
+	}
+
+	uc := network.NewUseCase(networkRepo, projectRepo, serviceRepo,
+		teamMemberRepo, containerManager, &config.DockerConfig{})
+
+	if err := uc.DetachService(ctx, uuid.New(), projectID, uuid.New(), serviceID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if disconnected != containerID {
+		t.Errorf("expected container %s to be disconnected, got %q", containerID, disconnected)
+	}
+	if !detached {
+		t.Error("expected service to be detached")
+	}
+}
+
+func TestDetachService_NotTeamMember(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return nil, nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+		},
+	}
+
+	uc := network.NewUseCase(&mocks.MockNetworkRepository{}, projectRepo, &mocks.MockServiceRepository{},
+		teamMemberRepo, &mocks.MockContainerManager{}, &config.DockerConfig{})
+
+	if err := uc.DetachService(ctx, uuid.New(), projectID, uuid.New(), uuid.New()); err != network.ErrNotTeamMember {
+		t.Errorf("expected ErrNotTeamMember, got %v", err)
+	}
+}
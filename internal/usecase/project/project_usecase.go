@@ -75,6 +75,9 @@ func (uc *UseCase) Create(ctx context.Context, userID, teamID uuid.UUID, input *
 	if input.AutoDeploy != nil {
 		project.AutoDeploy = *input.AutoDeploy
 	}
+	if input.MaintenanceWindow != nil && !input.MaintenanceWindow.IsEmpty() {
+		project.MaintenanceWindow = input.MaintenanceWindow
+	}
 
 	if input.GithubToken != nil && *input.GithubToken != "" {
 		encrypted, err := uc.encryptor.Encrypt([]byte(*input.GithubToken))
@@ -164,6 +167,13 @@ func (uc *UseCase) Update(ctx context.Context, userID, projectID uuid.UUID, inpu
 	if input.AutoDeploy != nil {
 		project.AutoDeploy = *input.AutoDeploy
 	}
+	if input.MaintenanceWindow != nil {
+		if input.MaintenanceWindow.IsEmpty() {
+			project.MaintenanceWindow = nil
+		} else {
+			project.MaintenanceWindow = input.MaintenanceWindow
+		}
+	}
 	if input.GithubToken != nil {
 		if *input.GithubToken == "" {
 			project.GithubTokenEncrypted = nil
@@ -207,6 +217,27 @@ func (uc *UseCase) Delete(ctx context.Context, userID, projectID uuid.UUID) erro
 	return uc.projectRepo.Delete(ctx, projectID)
 }
 
+// GetWebhook returns the push webhook endpoint and signing secret for a
+// project. Only admins and owners may read the secret.
+func (uc *UseCase) GetWebhook(ctx context.Context, userID, projectID uuid.UUID) (*entity.ProjectWebhook, error) {
+	project, member, err := uc.GetProjectWithTeamCheck(ctx, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role == entity.TeamRoleMember {
+		return nil, ErrNotTeamAdmin
+	}
+
+	webhook := &entity.ProjectWebhook{
+		ProjectID: project.ID,
+		Path:      "/api/v1/webhooks/github/" + project.ID.String(),
+	}
+	if project.WebhookSecret != nil {
+		webhook.Secret = *project.WebhookSecret
+	}
+	return webhook, nil
+}
+
 func (uc *UseCase) GetProjectWithTeamCheck(ctx context.Context, userID, projectID uuid.UUID) (*entity.Project, *entity.TeamMember, error) {
 	project, err := uc.projectRepo.GetByID(ctx, projectID)
 	if err != nil {
@@ -0,0 +1,93 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/domain/repository"
+	"github.com/podoru/spinner-podoru/pkg/crypto"
+)
+
+// DefaultBatchSize is how many rows Rotate reads at a time unless told otherwise
+const DefaultBatchSize = 100
+
+// ColumnRotation counts what rotating the key did to one encrypted column
+type ColumnRotation struct {
+	Column entity.EncryptedColumn
+	// Rotated rows were re-encrypted with the current key
+	Rotated int
+	// Current rows already used the current key
+	Current int
+	// Changed rows were written to while rotating and keep their new value
+	Changed int
+}
+
+type UseCase struct {
+	ciphertextRepo repository.CiphertextRepository
+	encryptor      *crypto.Encryptor
+}
+
+func NewUseCase(ciphertextRepo repository.CiphertextRepository, encryptor *crypto.Encryptor) *UseCase {
+	return &UseCase{
+		ciphertextRepo: ciphertextRepo,
+		encryptor:      encryptor,
+	}
+}
+
+// Rotate re-encrypts every encrypted column with the current key, batchSize
+// rows at a time. Afterwards the previous keys are no longer needed.
+func (uc *UseCase) Rotate(ctx context.Context, batchSize int) ([]ColumnRotation, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	results := make([]ColumnRotation, 0, len(entity.EncryptedColumns))
+	for _, column := range entity.EncryptedColumns {
+		result, err := uc.rotateColumn(ctx, column, batchSize)
+		if err != nil {
+			return results, fmt.Errorf("failed to rotate %s: %w", column, err)
+		}
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+func (uc *UseCase) rotateColumn(ctx context.Context, column entity.EncryptedColumn, batchSize int) (*ColumnRotation, error) {
+	result := &ColumnRotation{Column: column}
+	after := uuid.Nil
+	for {
+		batch, err := uc.ciphertextRepo.List(ctx, column, after, batchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range batch {
+			if uc.encryptor.IsCurrent(c.Data) {
+				result.Current++
+				continue
+			}
+
+			rotated, err := uc.encryptor.Reencrypt(c.Data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt row %s: %w", c.ID, err)
+			}
+			replaced, err := uc.ciphertextRepo.Replace(ctx, column, c.ID, c.Data, rotated)
+			if err != nil {
+				return nil, err
+			}
+			if replaced {
+				result.Rotated++
+			} else {
+				result.Changed++
+			}
+		}
+
+		if len(batch) < batchSize {
+			return result, nil
+		}
+		after = batch[len(batch)-1].ID
+	}
+}
@@ -0,0 +1,123 @@
+package secrets_test
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/mocks"
+	"github.com/podoru/spinner-podoru/internal/usecase/secrets"
+	"github.com/podoru/spinner-podoru/pkg/crypto"
+)
+
+// table is an in-memory encrypted column keyed by row id
+type table map[uuid.UUID][]byte
+
+func (t table) repo(column entity.EncryptedColumn, onReplace func(id uuid.UUID) bool) *mocks.MockCiphertextRepository {
+	return &mocks.MockCiphertextRepository{
+		ListFunc: func(ctx context.Context, c entity.EncryptedColumn, after uuid.UUID, limit int) ([]entity.Ciphertext, error) {
+			if c != column {
+				return nil, nil
+			}
+			var ids []uuid.UUID
+			for id := range t {
+				if bytes.Compare(id[:], after[:]) > 0 {
+					ids = append(ids, id)
+				}
+			}
+			sort.Slice(ids, func(i, j int) bool { return bytes.Compare(ids[i][:], ids[j][:]) < 0 })
+			if len(ids) > limit {
+				ids = ids[:limit]
+			}
+			var rows []entity.Ciphertext
+			for _, id := range ids {
+				rows = append(rows, entity.Ciphertext{ID: id, Data: t[id]})
+			}
+			return rows, nil
+		},
+		ReplaceFunc: func(ctx context.Context, c entity.EncryptedColumn, id uuid.UUID, from, to []byte) (bool, error) {
+			if onReplace != nil && !onReplace(id) {
+				return false, nil
+			}
+			t[id] = to
+			return true, nil
+		},
+	}
+}
+
+func TestRotate(t *testing.T) {
+	ctx := context.Background()
+	old, _ := crypto.NewEncryptor("old-key")
+	encryptor, _ := crypto.NewEncryptor("new-key", "old-key")
+
+	column := entity.EncryptedColumns[0]
+	rows := table{}
+	for i := 0; i < 5; i++ {
+		data, err := old.Encrypt([]byte("token"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		rows[uuid.New()] = data
+	}
+	current, _ := encryptor.Encrypt([]byte("token"))
+	rows[uuid.New()] = current
+
+	uc := secrets.NewUseCase(rows.repo(column, nil), encryptor)
+	results, err := uc.Rotate(ctx, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(results) != len(entity.EncryptedColumns) {
+		t.Fatalf("expected a result per column, got %d", len(results))
+	}
+	if results[0].Rotated != 5 || results[0].Current != 1 {
+		t.Errorf("expected 5 rotated and 1 current, got %+v", results[0])
+	}
+
+	newOnly, _ := crypto.NewEncryptor("new-key")
+	for id, data := range rows {
+		if !encryptor.IsCurrent(data) {
+			t.Errorf("row %s was not rotated", id)
+		}
+		if plaintext, err := newOnly.Decrypt(data); err != nil || string(plaintext) != "token" {
+			t.Errorf("row %s does not decrypt with the new key alone: %v", id, err)
+		}
+	}
+}
+
+func TestRotate_ConcurrentWrite(t *testing.T) {
+	old, _ := crypto.NewEncryptor("old-key")
+	encryptor, _ := crypto.NewEncryptor("new-key", "old-key")
+
+	column := entity.EncryptedColumns[0]
+	data, _ := old.Encrypt([]byte("token"))
+	rows := table{uuid.New(): data}
+
+	uc := secrets.NewUseCase(rows.repo(column, func(uuid.UUID) bool { return false }), encryptor)
+	results, err := uc.Rotate(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results[0].Changed != 1 || results[0].Rotated != 0 {
+		t.Errorf("expected the row to count as changed, got %+v", results[0])
+	}
+}
+
+func TestRotate_UnknownKey(t *testing.T) {
+	other, _ := crypto.NewEncryptor("other-key")
+	encryptor, _ := crypto.NewEncryptor("new-key", "old-key")
+
+	column := entity.EncryptedColumns[0]
+	data, _ := other.Encrypt([]byte("token"))
+	rows := table{uuid.New(): data}
+
+	uc := secrets.NewUseCase(rows.repo(column, nil), encryptor)
+	if _, err := uc.Rotate(context.Background(), 10); err == nil {
+		t.Error("expected rotating data sealed with an unknown key to fail")
+	}
+}
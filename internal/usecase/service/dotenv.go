@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/pkg/dotenv"
+)
+
+// ImportEnvVars loads a .env file into the service's env vars, merging it
+// with the existing ones or replacing them. Existing keys keep whether they
+// are secret. A dry run returns the changes without applying them; secret
+// keys in the file always count as updated.
+func (uc *UseCase) ImportEnvVars(ctx context.Context, userID, serviceID uuid.UUID, input *entity.EnvImport) (*entity.EnvImportResult, error) {
+	service, member, err := uc.getServiceWithMember(ctx, userID, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	vars, err := dotenv.Parse(input.Content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDotenv, err)
+	}
+	if err := validateEnvVarNames(vars); err != nil {
+		return nil, err
+	}
+
+	existing, err := uc.envVarRepo.ListByServiceID(ctx, service.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entity.EnvImportResult{Changes: []entity.EnvVarDiff{}}
+	current := make(map[string]*entity.ServiceEnvVar, len(existing))
+	for i := range existing {
+		v := &existing[i]
+		current[v.Key] = v
+		if _, ok := vars[v.Key]; !ok && input.Mode == entity.EnvImportReplace {
+			result.Changes = append(result.Changes, entity.EnvVarDiff{Key: v.Key, Action: entity.EnvVarActionDeleted, IsSecret: v.IsSecret})
+		}
+	}
+	for key, value := range vars {
+		v, ok := current[key]
+		if !ok {
+			result.Changes = append(result.Changes, entity.EnvVarDiff{Key: key, Action: entity.EnvVarActionCreated, IsSecret: input.Secret})
+			continue
+		}
+		// Secret values are never compared, or a dry run would tell a member
+		// whether a guessed value is right
+		if v.IsSecret || input.Secret {
+			result.Changes = append(result.Changes, entity.EnvVarDiff{Key: key, Action: entity.EnvVarActionUpdated, IsSecret: v.IsSecret})
+			continue
+		}
+		if err := uc.decryptEnvVar(v); err != nil {
+			return nil, err
+		}
+		if v.Value == value {
+			result.Unchanged++
+			continue
+		}
+		result.Changes = append(result.Changes, entity.EnvVarDiff{Key: key, Action: entity.EnvVarActionUpdated, IsSecret: v.IsSecret})
+	}
+	sort.Slice(result.Changes, func(i, j int) bool { return result.Changes[i].Key < result.Changes[j].Key })
+
+	if input.DryRun {
+		return result, nil
+	}
+
+	for _, change := range result.Changes {
+		switch change.Action {
+		case entity.EnvVarActionDeleted:
+			if err := uc.envVarRepo.Delete(ctx, service.ID, change.Key); err != nil {
+				return nil, err
+			}
+			if err := uc.recordEnvVarChange(ctx, service.ID, change.Key, entity.EnvVarActionDeleted, change.IsSecret, &userID); err != nil {
+				return nil, err
+			}
+		case entity.EnvVarActionCreated:
+			value := vars[change.Key]
+			if _, err := uc.setEnvVar(ctx, service.ID, member, change.Key, &value, &input.Secret); err != nil {
+				return nil, err
+			}
+		default:
+			value := vars[change.Key]
+			if _, err := uc.setEnvVar(ctx, service.ID, member, change.Key, &value, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	result.Applied = true
+	return result, nil
+}
+
+// ExportEnvVars returns the service's env vars as a .env file, secrets
+// included. Requires admin or owner role, and every exported secret is
+// recorded as revealed.
+func (uc *UseCase) ExportEnvVars(ctx context.Context, userID, serviceID uuid.UUID) (*entity.Service, string, error) {
+	service, member, err := uc.getServiceWithMember(ctx, userID, serviceID)
+	if err != nil {
+		return nil, "", err
+	}
+	if member.Role == entity.TeamRoleMember {
+		return nil, "", ErrNotTeamAdmin
+	}
+
+	envVars, err := uc.envVarRepo.ListByServiceID(ctx, service.ID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	vars := make(map[string]string, len(envVars))
+	for i := range envVars {
+		v := &envVars[i]
+		if err := uc.decryptEnvVar(v); err != nil {
+			return nil, "", err
+		}
+		vars[v.Key] = v.Value
+		if v.IsSecret {
+			if err := uc.recordEnvVarChange(ctx, service.ID, v.Key, entity.EnvVarActionRevealed, true, &userID); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	return service, dotenv.Format(vars), nil
+}
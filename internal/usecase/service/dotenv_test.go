@@ -0,0 +1,372 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/mocks"
+	"github.com/podoru/spinner-podoru/internal/usecase/service"
+	"github.com/podoru/spinner-podoru/pkg/crypto"
+)
+
+const importFile = `# app settings
+export LOG_LEVEL=info
+API_KEY="sk_live_new"
+NEW_VAR='hello world'
+`
+
+func TestImportEnvVars(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   entity.EnvImport
+		changes map[string]entity.EnvVarAction
+	}{
+		{
+			name:    "merge",
+			input:   entity.EnvImport{Content: importFile},
+			changes: map[string]entity.EnvVarAction{"API_KEY": entity.EnvVarActionUpdated, "NEW_VAR": entity.EnvVarActionCreated},
+		},
+		{
+			name:  "replace",
+			input: entity.EnvImport{Content: importFile, Mode: entity.EnvImportReplace},
+			changes: map[string]entity.EnvVarAction{
+				"API_KEY": entity.EnvVarActionUpdated, "NEW_VAR": entity.EnvVarActionCreated, "OLD_VAR": entity.EnvVarActionDeleted,
+			},
+		},
+		{
+			name:    "dry run",
+			input:   entity.EnvImport{Content: importFile, Mode: entity.EnvImportReplace, DryRun: true},
+			changes: map[string]entity.EnvVarAction{"API_KEY": entity.EnvVarActionUpdated, "NEW_VAR": entity.EnvVarActionCreated, "OLD_VAR": entity.EnvVarActionDeleted},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := &entity.Project{ID: uuid.New(), TeamID: uuid.New()}
+			svc := &entity.Service{ID: uuid.New(), ProjectID: project.ID}
+
+			encryptor, err := crypto.NewEncryptor("test-key")
+			if err != nil {
+				t.Fatalf("failed to create encryptor: %v", err)
+			}
+			logLevel, _ := encryptor.Encrypt([]byte("info"))
+			apiKey, _ := encryptor.Encrypt([]byte("sk_live_old"))
+			oldVar, _ := encryptor.Encrypt([]byte("gone"))
+			existing := []entity.ServiceEnvVar{
+				{ID: uuid.New(), ServiceID: svc.ID, Key: "LOG_LEVEL", ValueEncrypted: logLevel},
+				{ID: uuid.New(), ServiceID: svc.ID, Key: "API_KEY", ValueEncrypted: apiKey, IsSecret: true},
+				{ID: uuid.New(), ServiceID: svc.ID, Key: "OLD_VAR", ValueEncrypted: oldVar},
+			}
+
+			written := make(map[string]entity.EnvVarAction)
+			envVarRepo := &mocks.MockServiceEnvVarRepository{
+				ListByServiceIDFunc: func(ctx context.Context, serviceID uuid.UUID) ([]entity.ServiceEnvVar, error) {
+					return append([]entity.ServiceEnvVar(nil), existing...), nil
+				},
+				GetByServiceAndKeyFunc: func(ctx context.Context, serviceID uuid.UUID, key string) (*entity.ServiceEnvVar, error) {
+					for _, v := range existing {
+						if v.Key == key {
+							return &v, nil
+						}
+					}
+					return nil, nil
+				},
+				UpsertFunc: func(ctx context.Context, envVar *entity.ServiceEnvVar) error {
+					written[envVar.Key] = entity.EnvVarActionUpdated
+					if envVar.Key == "API_KEY" && !envVar.IsSecret {
+						t.Error("expected API_KEY to stay secret")
+					}
+					return nil
+				},
+				DeleteFunc: func(ctx context.Context, serviceID uuid.UUID, key string) error {
+					written[key] = entity.EnvVarActionDeleted
+					return nil
+				},
+				CreateChangeFunc: func(ctx context.Context, change *entity.ServiceEnvVarChange) error {
+					return nil
+				},
+			}
+
+			serviceRepo := &mocks.MockServiceRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+					return svc, nil
+				},
+			}
+
+			projectRepo := &mocks.MockProjectRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+					return project, nil
+				},
+			}
+
+			teamMemberRepo := &mocks.MockTeamMemberRepository{
+				GetByTeamAndUserFunc: func(ctx context.Context, teamID, uID uuid.UUID) (*entity.TeamMember, error) {
+					return &entity.TeamMember{TeamID: teamID, UserID: uID, Role: entity.TeamRoleAdmin}, nil
+				},
+			}
+
+			uc := service.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, nil, nil, nil, envVarRepo, encryptor, nil, nil, nil)
+
+			result, err := uc.ImportEnvVars(context.Background(), uuid.New(), svc.ID, &tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(result.Changes) != len(tt.changes) {
+				t.Fatalf("expected %d changes, got %+v", len(tt.changes), result.Changes)
+			}
+			for _, c := range result.Changes {
+				if tt.changes[c.Key] != c.Action {
+					t.Errorf("expected %s to be %s, got %s", c.Key, tt.changes[c.Key], c.Action)
+				}
+			}
+			if result.Unchanged != 1 {
+				t.Errorf("expected LOG_LEVEL unchanged, got %d unchanged", result.Unchanged)
+			}
+			if result.Applied == tt.input.DryRun {
+				t.Errorf("expected applied to be %v", !tt.input.DryRun)
+			}
+
+			wantWritten := len(tt.changes)
+			if tt.input.DryRun {
+				wantWritten = 0
+			}
+			if len(written) != wantWritten {
+				t.Errorf("expected %d keys to be written, got %v", wantWritten, written)
+			}
+			if _, ok := written["LOG_LEVEL"]; ok {
+				t.Error("expected unchanged LOG_LEVEL not to be written")
+			}
+		})
+	}
+}
+
+func TestImportEnvVars_SecretGuess(t *testing.T) {
+	project := &entity.Project{ID: uuid.New(), TeamID: uuid.New()}
+	svc := &entity.Service{ID: uuid.New(), ProjectID: project.ID}
+
+	encryptor, err := crypto.NewEncryptor("test-key")
+	if err != nil {
+		t.Fatalf("failed to create encryptor: %v", err)
+	}
+	apiKey, _ := encryptor.Encrypt([]byte("sk_live_old"))
+
+	envVarRepo := &mocks.MockServiceEnvVarRepository{
+		ListByServiceIDFunc: func(ctx context.Context, serviceID uuid.UUID) ([]entity.ServiceEnvVar, error) {
+			return []entity.ServiceEnvVar{{ID: uuid.New(), ServiceID: svc.ID, Key: "API_KEY", ValueEncrypted: apiKey, IsSecret: true}}, nil
+		},
+	}
+
+	serviceRepo := &mocks.MockServiceRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+			return svc, nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return project, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, uID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: uID, Role: entity.TeamRoleMember}, nil
+		},
+	}
+
+	uc := service.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, nil, nil, nil, envVarRepo, encryptor, nil, nil, nil)
+
+	result, err := uc.ImportEnvVars(context.Background(), uuid.New(), svc.ID, &entity.EnvImport{Content: "API_KEY=sk_live_old", DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Unchanged != 0 {
+		t.Errorf("expected a matching guess of a secret not to be reported as unchanged")
+	}
+	if len(result.Changes) != 1 || result.Changes[0].Action != entity.EnvVarActionUpdated {
+		t.Errorf("expected the secret to be reported as updated, got %+v", result.Changes)
+	}
+}
+
+func TestImportEnvVars_NewKeysAsSecrets(t *testing.T) {
+	project := &entity.Project{ID: uuid.New(), TeamID: uuid.New()}
+	svc := &entity.Service{ID: uuid.New(), ProjectID: project.ID}
+
+	encryptor, err := crypto.NewEncryptor("test-key")
+	if err != nil {
+		t.Fatalf("failed to create encryptor: %v", err)
+	}
+
+	var stored *entity.ServiceEnvVar
+	envVarRepo := &mocks.MockServiceEnvVarRepository{
+		ListByServiceIDFunc: func(ctx context.Context, serviceID uuid.UUID) ([]entity.ServiceEnvVar, error) {
+			return nil, nil
+		},
+		GetByServiceAndKeyFunc: func(ctx context.Context, serviceID uuid.UUID, key string) (*entity.ServiceEnvVar, error) {
+			return nil, nil
+		},
+		UpsertFunc: func(ctx context.Context, envVar *entity.ServiceEnvVar) error {
+			stored = envVar
+			return nil
+		},
+		CreateChangeFunc: func(ctx context.Context, change *entity.ServiceEnvVarChange) error {
+			return nil
+		},
+	}
+
+	serviceRepo := &mocks.MockServiceRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+			return svc, nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return project, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, uID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: uID, Role: entity.TeamRoleMember}, nil
+		},
+	}
+
+	uc := service.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, nil, nil, nil, envVarRepo, encryptor, nil, nil, nil)
+
+	_, err = uc.ImportEnvVars(context.Background(), uuid.New(), svc.ID, &entity.EnvImport{Content: "TOKEN=abc", Secret: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stored == nil || stored.Key != "TOKEN" || !stored.IsSecret {
+		t.Errorf("expected TOKEN to be imported as a secret, got %+v", stored)
+	}
+}
+
+func TestImportEnvVars_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr error
+	}{
+		{name: "syntax", content: "KEY=\"unterminated", wantErr: service.ErrInvalidDotenv},
+		{name: "name", content: "1KEY=value", wantErr: service.ErrInvalidEnvVarName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := &entity.Project{ID: uuid.New(), TeamID: uuid.New()}
+
+			envVarRepo := &mocks.MockServiceEnvVarRepository{
+				UpsertFunc: func(ctx context.Context, envVar *entity.ServiceEnvVar) error {
+					t.Errorf("expected no env var to be stored")
+					return nil
+				},
+			}
+
+			serviceRepo := &mocks.MockServiceRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+					return &entity.Service{ID: id, ProjectID: project.ID}, nil
+				},
+			}
+
+			projectRepo := &mocks.MockProjectRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+					return project, nil
+				},
+			}
+
+			teamMemberRepo := &mocks.MockTeamMemberRepository{
+				GetByTeamAndUserFunc: func(ctx context.Context, teamID, uID uuid.UUID) (*entity.TeamMember, error) {
+					return &entity.TeamMember{TeamID: teamID, UserID: uID, Role: entity.TeamRoleAdmin}, nil
+				},
+			}
+
+			uc := service.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, nil, nil, nil, envVarRepo, nil, nil, nil, nil)
+
+			_, err := uc.ImportEnvVars(context.Background(), uuid.New(), uuid.New(), &entity.EnvImport{Content: tt.content})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestExportEnvVars(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    entity.TeamRole
+		wantErr error
+	}{
+		{name: "member", role: entity.TeamRoleMember, wantErr: service.ErrNotTeamAdmin},
+		{name: "admin", role: entity.TeamRoleAdmin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := &entity.Project{ID: uuid.New(), TeamID: uuid.New()}
+			svc := &entity.Service{ID: uuid.New(), ProjectID: project.ID}
+
+			encryptor, err := crypto.NewEncryptor("test-key")
+			if err != nil {
+				t.Fatalf("failed to create encryptor: %v", err)
+			}
+			logLevel, _ := encryptor.Encrypt([]byte("info"))
+			apiKey, _ := encryptor.Encrypt([]byte("sk live"))
+
+			var changes []entity.ServiceEnvVarChange
+			envVarRepo := &mocks.MockServiceEnvVarRepository{
+				ListByServiceIDFunc: func(ctx context.Context, serviceID uuid.UUID) ([]entity.ServiceEnvVar, error) {
+					return []entity.ServiceEnvVar{
+						{ID: uuid.New(), ServiceID: svc.ID, Key: "LOG_LEVEL", ValueEncrypted: logLevel},
+						{ID: uuid.New(), ServiceID: svc.ID, Key: "API_KEY", ValueEncrypted: apiKey, IsSecret: true},
+					}, nil
+				},
+				CreateChangeFunc: func(ctx context.Context, change *entity.ServiceEnvVarChange) error {
+					changes = append(changes, *change)
+					return nil
+				},
+			}
+
+			serviceRepo := &mocks.MockServiceRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+					return svc, nil
+				},
+			}
+
+			projectRepo := &mocks.MockProjectRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+					return project, nil
+				},
+			}
+
+			teamMemberRepo := &mocks.MockTeamMemberRepository{
+				GetByTeamAndUserFunc: func(ctx context.Context, teamID, uID uuid.UUID) (*entity.TeamMember, error) {
+					return &entity.TeamMember{TeamID: teamID, UserID: uID, Role: tt.role}, nil
+				},
+			}
+
+			uc := service.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, nil, nil, nil, envVarRepo, encryptor, nil, nil, nil)
+
+			_, content, err := uc.ExportEnvVars(context.Background(), uuid.New(), svc.ID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if want := "API_KEY='sk live'\nLOG_LEVEL=info\n"; content != want {
+				t.Errorf("expected %q, got %q", want, content)
+			}
+			if len(changes) != 1 || changes[0].Key != "API_KEY" || changes[0].Action != entity.EnvVarActionRevealed {
+				t.Errorf("expected the exported secret to be recorded as revealed, got %+v", changes)
+			}
+		})
+	}
+}
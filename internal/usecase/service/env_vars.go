@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+// envVarHistoryLimit caps how many changes the history endpoint returns
+const envVarHistoryLimit = 200
+
+// ListEnvVars returns the service's env vars with secret values masked
+func (uc *UseCase) ListEnvVars(ctx context.Context, userID, serviceID uuid.UUID) ([]entity.ServiceEnvVar, error) {
+	if _, err := uc.getServiceWithTeamCheck(ctx, userID, serviceID); err != nil {
+		return nil, err
+	}
+	return uc.maskedEnvVars(ctx, serviceID)
+}
+
+// SetEnvVar creates or changes one env var of the service. Turning a secret
+// back into a plain var exposes its value, so it requires admin or owner role.
+// Takes effect on the next deploy.
+func (uc *UseCase) SetEnvVar(ctx context.Context, userID, serviceID uuid.UUID, key string, input *entity.ServiceEnvVarUpdate) (*entity.ServiceEnvVar, error) {
+	service, member, err := uc.getServiceWithMember(ctx, userID, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if !entity.IsValidVarName(key) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidEnvVarName, key)
+	}
+
+	envVar, err := uc.setEnvVar(ctx, service.ID, member, key, input.Value, input.IsSecret)
+	if err != nil {
+		return nil, err
+	}
+	masked := envVar.Masked()
+	return &masked, nil
+}
+
+// DeleteEnvVar removes one env var of the service from its next deploy
+func (uc *UseCase) DeleteEnvVar(ctx context.Context, userID, serviceID uuid.UUID, key string) error {
+	if _, err := uc.getServiceWithTeamCheck(ctx, userID, serviceID); err != nil {
+		return err
+	}
+
+	existing, err := uc.envVarRepo.GetByServiceAndKey(ctx, serviceID, key)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrEnvVarNotFound
+	}
+
+	if err := uc.envVarRepo.Delete(ctx, serviceID, key); err != nil {
+		return err
+	}
+	return uc.recordEnvVarChange(ctx, serviceID, key, entity.EnvVarActionDeleted, existing.IsSecret, &userID)
+}
+
+// RevealEnvVar returns the value of one env var, secret or not. Requires
+// admin or owner role, and each reveal is recorded in the history.
+func (uc *UseCase) RevealEnvVar(ctx context.Context, userID, serviceID uuid.UUID, key string) (*entity.ServiceEnvVar, error) {
+	_, member, err := uc.getServiceWithMember(ctx, userID, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role == entity.TeamRoleMember {
+		return nil, ErrNotTeamAdmin
+	}
+
+	envVar, err := uc.envVarRepo.GetByServiceAndKey(ctx, serviceID, key)
+	if err != nil {
+		return nil, err
+	}
+	if envVar == nil {
+		return nil, ErrEnvVarNotFound
+	}
+	if err := uc.decryptEnvVar(envVar); err != nil {
+		return nil, err
+	}
+
+	if envVar.IsSecret {
+		if err := uc.recordEnvVarChange(ctx, serviceID, key, entity.EnvVarActionRevealed, true, &userID); err != nil {
+			return nil, err
+		}
+	}
+	return envVar, nil
+}
+
+// ListEnvVarHistory returns who changed or revealed which env var, latest
+// first. Values are never recorded.
+func (uc *UseCase) ListEnvVarHistory(ctx context.Context, userID, serviceID uuid.UUID) ([]entity.ServiceEnvVarChange, error) {
+	if _, err := uc.getServiceWithTeamCheck(ctx, userID, serviceID); err != nil {
+		return nil, err
+	}
+	return uc.envVarRepo.ListChanges(ctx, serviceID, envVarHistoryLimit)
+}
+
+// ImportLegacyEnvVars moves env vars stored as one encrypted map on the
+// service into per-key records. Their values are unknown, so they are
+// imported as secrets.
+func (uc *UseCase) ImportLegacyEnvVars(ctx context.Context) (int, error) {
+	services, err := uc.serviceRepo.ListWithLegacyEnvVars(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	secret := true
+	for i := range services {
+		service := &services[i]
+		vars, err := uc.decryptVars(service.EnvVarsEncrypted)
+		if err != nil {
+			return i, fmt.Errorf("failed to decrypt env vars of service %s: %w", service.ID, err)
+		}
+		for key, value := range vars {
+			if _, err := uc.setEnvVar(ctx, service.ID, nil, key, &value, &secret); err != nil {
+				return i, err
+			}
+		}
+
+		service.EnvVarsEncrypted = nil
+		if err := uc.serviceRepo.Update(ctx, service); err != nil {
+			return i, err
+		}
+	}
+	return len(services), nil
+}
+
+// replaceEnvVars makes the service's env vars exactly the given plain vars and
+// secrets. Existing keys keep whether they are secret unless listed in secrets.
+func (uc *UseCase) replaceEnvVars(ctx context.Context, serviceID uuid.UUID, member *entity.TeamMember, plain, secrets map[string]string) error {
+	if err := validateEnvVarNames(plain, secrets); err != nil {
+		return err
+	}
+
+	existing, err := uc.envVarRepo.ListByServiceID(ctx, serviceID)
+	if err != nil {
+		return err
+	}
+	for _, v := range existing {
+		_, inPlain := plain[v.Key]
+		_, inSecrets := secrets[v.Key]
+		if inPlain || inSecrets {
+			continue
+		}
+		if err := uc.envVarRepo.Delete(ctx, serviceID, v.Key); err != nil {
+			return err
+		}
+		if err := uc.recordEnvVarChange(ctx, serviceID, v.Key, entity.EnvVarActionDeleted, v.IsSecret, &member.UserID); err != nil {
+			return err
+		}
+	}
+
+	for key, value := range plain {
+		if _, ok := secrets[key]; ok {
+			continue
+		}
+		if _, err := uc.setEnvVar(ctx, serviceID, member, key, &value, nil); err != nil {
+			return err
+		}
+	}
+	secret := true
+	for key, value := range secrets {
+		if _, err := uc.setEnvVar(ctx, serviceID, member, key, &value, &secret); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setEnvVar writes one env var and records the change. A nil member is the
+// system, as when importing.
+func (uc *UseCase) setEnvVar(ctx context.Context, serviceID uuid.UUID, member *entity.TeamMember, key string, value *string, isSecret *bool) (*entity.ServiceEnvVar, error) {
+	existing, err := uc.envVarRepo.GetByServiceAndKey(ctx, serviceID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	envVar := existing
+	action := entity.EnvVarActionUpdated
+	if envVar == nil {
+		if value == nil {
+			return nil, ErrEnvVarValueRequired
+		}
+		envVar = &entity.ServiceEnvVar{ID: uuid.New(), ServiceID: serviceID, Key: key, CreatedAt: now}
+		action = entity.EnvVarActionCreated
+	} else if err := uc.decryptEnvVar(envVar); err != nil {
+		return nil, err
+	}
+
+	changed := existing == nil
+	if isSecret != nil && *isSecret != envVar.IsSecret {
+		if envVar.IsSecret && member != nil && member.Role == entity.TeamRoleMember {
+			return nil, ErrNotTeamAdmin
+		}
+		envVar.IsSecret = *isSecret
+		changed = true
+	}
+	if value != nil && (existing == nil || *value != envVar.Value) {
+		envVar.Value = *value
+		changed = true
+	}
+	if !changed {
+		return envVar, nil
+	}
+
+	envVar.ValueEncrypted, err = uc.encryptor.Encrypt([]byte(envVar.Value))
+	if err != nil {
+		return nil, err
+	}
+	envVar.UpdatedAt = now
+	if err := uc.envVarRepo.Upsert(ctx, envVar); err != nil {
+		return nil, err
+	}
+
+	var userID *uuid.UUID
+	if member != nil {
+		userID = &member.UserID
+	}
+	if err := uc.recordEnvVarChange(ctx, serviceID, key, action, envVar.IsSecret, userID); err != nil {
+		return nil, err
+	}
+	return envVar, nil
+}
+
+func validateEnvVarNames(sets ...map[string]string) error {
+	for _, vars := range sets {
+		for key := range vars {
+			if !entity.IsValidVarName(key) {
+				return fmt.Errorf("%w: %q", ErrInvalidEnvVarName, key)
+			}
+		}
+	}
+	return nil
+}
+
+func (uc *UseCase) recordEnvVarChange(ctx context.Context, serviceID uuid.UUID, key string, action entity.EnvVarAction, isSecret bool, userID *uuid.UUID) error {
+	return uc.envVarRepo.CreateChange(ctx, &entity.ServiceEnvVarChange{
+		ID:        uuid.New(),
+		ServiceID: serviceID,
+		Key:       key,
+		Action:    action,
+		IsSecret:  isSecret,
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (uc *UseCase) maskedEnvVars(ctx context.Context, serviceID uuid.UUID) ([]entity.ServiceEnvVar, error) {
+	envVars, err := uc.envVarRepo.ListByServiceID(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range envVars {
+		if !envVars[i].IsSecret {
+			if err := uc.decryptEnvVar(&envVars[i]); err != nil {
+				return nil, err
+			}
+		}
+		envVars[i] = envVars[i].Masked()
+	}
+	return envVars, nil
+}
+
+func (uc *UseCase) decryptEnvVar(envVar *entity.ServiceEnvVar) error {
+	value, err := uc.encryptor.Decrypt(envVar.ValueEncrypted)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt env var %s: %w", envVar.Key, err)
+	}
+	envVar.Value = string(value)
+	return nil
+}
+
+func (uc *UseCase) getServiceWithMember(ctx context.Context, userID, serviceID uuid.UUID) (*entity.Service, *entity.TeamMember, error) {
+	service, err := uc.serviceRepo.GetByID(ctx, serviceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if service == nil {
+		return nil, nil, ErrServiceNotFound
+	}
+
+	project, err := uc.projectRepo.GetByID(ctx, service.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if project == nil {
+		return nil, nil, ErrProjectNotFound
+	}
+
+	member, err := uc.teamMemberRepo.GetByTeamAndUser(ctx, project.TeamID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if member == nil {
+		return nil, nil, ErrNotTeamMember
+	}
+
+	return service, member, nil
+}
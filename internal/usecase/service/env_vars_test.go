@@ -0,0 +1,317 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/mocks"
+	"github.com/podoru/spinner-podoru/internal/usecase/service"
+	"github.com/podoru/spinner-podoru/pkg/crypto"
+)
+
+func TestListEnvVars_MasksSecrets(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	project := &entity.Project{ID: uuid.New(), TeamID: uuid.New()}
+	svc := &entity.Service{ID: uuid.New(), ProjectID: project.ID}
+
+	encryptor, err := crypto.NewEncryptor("test-key")
+	if err != nil {
+		t.Fatalf("failed to create encryptor: %v", err)
+	}
+	logLevel, _ := encryptor.Encrypt([]byte("debug"))
+	apiKey, _ := encryptor.Encrypt([]byte("sk_live_0123456789"))
+
+	envVarRepo := &mocks.MockServiceEnvVarRepository{
+		ListByServiceIDFunc: func(ctx context.Context, serviceID uuid.UUID) ([]entity.ServiceEnvVar, error) {
+			return []entity.ServiceEnvVar{
+				{ID: uuid.New(), ServiceID: svc.ID, Key: "LOG_LEVEL", ValueEncrypted: logLevel},
+				{ID: uuid.New(), ServiceID: svc.ID, Key: "API_KEY", ValueEncrypted: apiKey, IsSecret: true},
+			}, nil
+		},
+	}
+
+	serviceRepo := &mocks.MockServiceRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+			return svc, nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return project, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, uID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: uID, Role: entity.TeamRoleMember}, nil
+		},
+	}
+
+	uc := service.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, nil, nil, nil, envVarRepo, encryptor, nil, nil, nil)
+
+	envVars, err := uc.ListEnvVars(ctx, userID, svc.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	values := make(map[string]string)
+	for _, v := range envVars {
+		values[v.Key] = v.Value
+	}
+	if values["LOG_LEVEL"] != "debug" {
+		t.Errorf("expected plain value debug, got %q", values["LOG_LEVEL"])
+	}
+	if values["API_KEY"] != entity.SecretMask {
+		t.Errorf("expected masked secret, got %q", values["API_KEY"])
+	}
+}
+
+func TestSetEnvVar(t *testing.T) {
+	value := "value"
+	plain := false
+	secret := true
+
+	tests := []struct {
+		name     string
+		role     entity.TeamRole
+		existing bool
+		input    entity.ServiceEnvVarUpdate
+		wantErr  error
+		action   entity.EnvVarAction
+	}{
+		{name: "create secret", role: entity.TeamRoleMember, input: entity.ServiceEnvVarUpdate{Value: &value, IsSecret: &secret}, action: entity.EnvVarActionCreated},
+		{name: "create without value", role: entity.TeamRoleMember, input: entity.ServiceEnvVarUpdate{IsSecret: &secret}, wantErr: service.ErrEnvVarValueRequired},
+		{name: "member unmarks secret", role: entity.TeamRoleMember, existing: true, input: entity.ServiceEnvVarUpdate{IsSecret: &plain}, wantErr: service.ErrNotTeamAdmin},
+		{name: "admin unmarks secret", role: entity.TeamRoleAdmin, existing: true, input: entity.ServiceEnvVarUpdate{IsSecret: &plain}, action: entity.EnvVarActionUpdated},
+		{name: "member updates secret value", role: entity.TeamRoleMember, existing: true, input: entity.ServiceEnvVarUpdate{Value: &value}, action: entity.EnvVarActionUpdated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := uuid.New()
+			project := &entity.Project{ID: uuid.New(), TeamID: uuid.New()}
+			svc := &entity.Service{ID: uuid.New(), ProjectID: project.ID}
+
+			encryptor, err := crypto.NewEncryptor("test-key")
+			if err != nil {
+				t.Fatalf("failed to create encryptor: %v", err)
+			}
+
+			var existing *entity.ServiceEnvVar
+			if tt.existing {
+				old, _ := encryptor.Encrypt([]byte("old"))
+				existing = &entity.ServiceEnvVar{ID: uuid.New(), ServiceID: svc.ID, Key: "API_KEY", ValueEncrypted: old, IsSecret: true}
+			}
+
+			var changes []entity.ServiceEnvVarChange
+			envVarRepo := &mocks.MockServiceEnvVarRepository{
+				GetByServiceAndKeyFunc: func(ctx context.Context, serviceID uuid.UUID, key string) (*entity.ServiceEnvVar, error) {
+					return existing, nil
+				},
+				UpsertFunc: func(ctx context.Context, envVar *entity.ServiceEnvVar) error {
+					return nil
+				},
+				CreateChangeFunc: func(ctx context.Context, change *entity.ServiceEnvVarChange) error {
+					changes = append(changes, *change)
+					return nil
+				},
+			}
+
+			serviceRepo := &mocks.MockServiceRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+					return svc, nil
+				},
+			}
+
+			projectRepo := &mocks.MockProjectRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+					return project, nil
+				},
+			}
+
+			teamMemberRepo := &mocks.MockTeamMemberRepository{
+				GetByTeamAndUserFunc: func(ctx context.Context, teamID, uID uuid.UUID) (*entity.TeamMember, error) {
+					return &entity.TeamMember{TeamID: teamID, UserID: uID, Role: tt.role}, nil
+				},
+			}
+
+			uc := service.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, nil, nil, nil, envVarRepo, encryptor, nil, nil, nil)
+
+			envVar, err := uc.SetEnvVar(context.Background(), userID, svc.ID, "API_KEY", &tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				if len(changes) != 0 {
+					t.Errorf("expected no recorded changes, got %d", len(changes))
+				}
+				return
+			}
+
+			if envVar.IsSecret && envVar.Value == value {
+				t.Errorf("expected secret value to be masked")
+			}
+			if len(changes) != 1 || changes[0].Action != tt.action {
+				t.Fatalf("expected one %s change, got %+v", tt.action, changes)
+			}
+			if changes[0].UserID == nil || *changes[0].UserID != userID {
+				t.Errorf("expected change to record the user")
+			}
+		})
+	}
+}
+
+func TestSetEnvVar_InvalidName(t *testing.T) {
+	value := "value"
+
+	envVarRepo := &mocks.MockServiceEnvVarRepository{
+		UpsertFunc: func(ctx context.Context, envVar *entity.ServiceEnvVar) error {
+			t.Errorf("expected no env var to be stored")
+			return nil
+		},
+	}
+
+	project := &entity.Project{ID: uuid.New(), TeamID: uuid.New()}
+
+	serviceRepo := &mocks.MockServiceRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+			return &entity.Service{ID: id, ProjectID: project.ID}, nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return project, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, uID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: uID, Role: entity.TeamRoleAdmin}, nil
+		},
+	}
+
+	uc := service.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, nil, nil, nil, envVarRepo, nil, nil, nil, nil)
+
+	_, err := uc.SetEnvVar(context.Background(), uuid.New(), uuid.New(), "1BAD-NAME", &entity.ServiceEnvVarUpdate{Value: &value})
+	if !errors.Is(err, service.ErrInvalidEnvVarName) {
+		t.Errorf("expected ErrInvalidEnvVarName, got %v", err)
+	}
+}
+
+func TestRevealEnvVar(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    entity.TeamRole
+		wantErr error
+	}{
+		{name: "member", role: entity.TeamRoleMember, wantErr: service.ErrNotTeamAdmin},
+		{name: "admin", role: entity.TeamRoleAdmin},
+		{name: "owner", role: entity.TeamRoleOwner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := &entity.Project{ID: uuid.New(), TeamID: uuid.New()}
+			svc := &entity.Service{ID: uuid.New(), ProjectID: project.ID}
+
+			encryptor, err := crypto.NewEncryptor("test-key")
+			if err != nil {
+				t.Fatalf("failed to create encryptor: %v", err)
+			}
+			sealed, _ := encryptor.Encrypt([]byte("sk_live_0123456789"))
+
+			var changes []entity.ServiceEnvVarChange
+			envVarRepo := &mocks.MockServiceEnvVarRepository{
+				GetByServiceAndKeyFunc: func(ctx context.Context, serviceID uuid.UUID, key string) (*entity.ServiceEnvVar, error) {
+					return &entity.ServiceEnvVar{ID: uuid.New(), ServiceID: serviceID, Key: key, ValueEncrypted: sealed, IsSecret: true}, nil
+				},
+				CreateChangeFunc: func(ctx context.Context, change *entity.ServiceEnvVarChange) error {
+					changes = append(changes, *change)
+					return nil
+				},
+			}
+
+			serviceRepo := &mocks.MockServiceRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+					return svc, nil
+				},
+			}
+
+			projectRepo := &mocks.MockProjectRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+					return project, nil
+				},
+			}
+
+			teamMemberRepo := &mocks.MockTeamMemberRepository{
+				GetByTeamAndUserFunc: func(ctx context.Context, teamID, uID uuid.UUID) (*entity.TeamMember, error) {
+					return &entity.TeamMember{TeamID: teamID, UserID: uID, Role: tt.role}, nil
+				},
+			}
+
+			uc := service.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, nil, nil, nil, envVarRepo, encryptor, nil, nil, nil)
+
+			envVar, err := uc.RevealEnvVar(context.Background(), uuid.New(), svc.ID, "API_KEY")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if envVar.Value != "sk_live_0123456789" {
+				t.Errorf("expected revealed value, got %q", envVar.Value)
+			}
+			if len(changes) != 1 || changes[0].Action != entity.EnvVarActionRevealed {
+				t.Errorf("expected reveal to be recorded, got %+v", changes)
+			}
+		})
+	}
+}
+
+func TestDeleteEnvVar_NotFound(t *testing.T) {
+	project := &entity.Project{ID: uuid.New(), TeamID: uuid.New()}
+	svc := &entity.Service{ID: uuid.New(), ProjectID: project.ID}
+
+	envVarRepo := &mocks.MockServiceEnvVarRepository{
+		GetByServiceAndKeyFunc: func(ctx context.Context, serviceID uuid.UUID, key string) (*entity.ServiceEnvVar, error) {
+			return nil, nil
+		},
+		DeleteFunc: func(ctx context.Context, serviceID uuid.UUID, key string) error {
+			t.Errorf("expected nothing to be deleted")
+			return nil
+		},
+	}
+
+	serviceRepo := &mocks.MockServiceRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+			return svc, nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return project, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, uID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: uID, Role: entity.TeamRoleAdmin}, nil
+		},
+	}
+
+	uc := service.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, nil, nil, nil, envVarRepo, nil, nil, nil, nil)
+
+	err := uc.DeleteEnvVar(context.Background(), uuid.New(), svc.ID, "MISSING")
+	if !errors.Is(err, service.ErrEnvVarNotFound) {
+		t.Errorf("expected ErrEnvVarNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+// AddPort exposes a container port of the service. The first mapping also
+// becomes the port Traefik routes to for domains without their own port.
+func (uc *UseCase) AddPort(ctx context.Context, userID, serviceID uuid.UUID, input *entity.PortMappingCreate) (*entity.PortMapping, error) {
+	if _, err := uc.getServiceWithTeamCheck(ctx, userID, serviceID); err != nil {
+		return nil, err
+	}
+
+	protocol := input.Protocol
+	if protocol == "" {
+		protocol = "tcp"
+	}
+
+	existing, err := uc.portRepo.ListByServiceID(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, pm := range existing {
+		if pm.ContainerPort == input.ContainerPort && pm.Protocol == protocol {
+			return nil, ErrPortAlreadyMapped
+		}
+	}
+
+	portMapping := &entity.PortMapping{
+		ID:            uuid.New(),
+		ServiceID:     serviceID,
+		ContainerPort: input.ContainerPort,
+		HostPort:      input.HostPort,
+		Protocol:      protocol,
+		CreatedAt:     time.Now(),
+	}
+
+	if err := uc.portRepo.Create(ctx, portMapping); err != nil {
+		return nil, err
+	}
+	uc.routes.Refresh()
+
+	return portMapping, nil
+}
+
+func (uc *UseCase) ListPorts(ctx context.Context, userID, serviceID uuid.UUID) ([]entity.PortMapping, error) {
+	if _, err := uc.getServiceWithTeamCheck(ctx, userID, serviceID); err != nil {
+		return nil, err
+	}
+
+	return uc.portRepo.ListByServiceID(ctx, serviceID)
+}
+
+func (uc *UseCase) DeletePort(ctx context.Context, userID, serviceID, portID uuid.UUID) error {
+	if _, err := uc.getServiceWithTeamCheck(ctx, userID, serviceID); err != nil {
+		return err
+	}
+
+	portMappings, err := uc.portRepo.ListByServiceID(ctx, serviceID)
+	if err != nil {
+		return err
+	}
+	for _, pm := range portMappings {
+		if pm.ID == portID {
+			if err := uc.portRepo.Delete(ctx, portID); err != nil {
+				return err
+			}
+			uc.routes.Refresh()
+			return nil
+		}
+	}
+
+	return ErrPortNotFound
+}
+
+func (uc *UseCase) getServiceWithTeamCheck(ctx context.Context, userID, serviceID uuid.UUID) (*entity.Service, error) {
+	service, err := uc.serviceRepo.GetByID(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if service == nil {
+		return nil, ErrServiceNotFound
+	}
+
+	project, err := uc.projectRepo.GetByID(ctx, service.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, ErrProjectNotFound
+	}
+
+	member, err := uc.teamMemberRepo.GetByTeamAndUser(ctx, project.TeamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrNotTeamMember
+	}
+
+	return service, nil
+}
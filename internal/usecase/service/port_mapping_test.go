@@ -0,0 +1,214 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/config"
+	"github.com/podoru/spinner-podoru/internal/mocks"
+	"github.com/podoru/spinner-podoru/internal/usecase/service"
+	"github.com/podoru/spinner-podoru/internal/usecase/traefik"
+)
+
+func TestAddPort(t *testing.T) {
+	existing := []entity.PortMapping{
+		{ID: uuid.New(), ContainerPort: 8080, Protocol: "tcp"},
+		{ID: uuid.New(), ContainerPort: 53, Protocol: "udp"},
+	}
+
+	tests := []struct {
+		name         string
+		input        entity.PortMappingCreate
+		wantErr      error
+		wantProtocol string
+	}{
+		{name: "new port", input: entity.PortMappingCreate{ContainerPort: 9090, Protocol: "tcp"}, wantProtocol: "tcp"},
+		{name: "protocol defaults to tcp", input: entity.PortMappingCreate{ContainerPort: 9090}, wantProtocol: "tcp"},
+		{name: "same port and protocol", input: entity.PortMappingCreate{ContainerPort: 8080, Protocol: "tcp"}, wantErr: service.ErrPortAlreadyMapped},
+		{name: "same port with the default protocol", input: entity.PortMappingCreate{ContainerPort: 8080}, wantErr: service.ErrPortAlreadyMapped},
+		{name: "same port on another protocol", input: entity.PortMappingCreate{ContainerPort: 8080, Protocol: "udp"}, wantProtocol: "udp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			serviceID := uuid.New()
+
+			serviceRepo := &mocks.MockServiceRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+					return &entity.Service{ID: id, ProjectID: uuid.New()}, nil
+				},
+			}
+
+			projectRepo := &mocks.MockProjectRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+					return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+				},
+			}
+
+			teamMemberRepo := &mocks.MockTeamMemberRepository{
+				GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+					return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleMember}, nil
+				},
+			}
+
+			var created *entity.PortMapping
+			portRepo := &mocks.MockPortMappingRepository{
+				ListByServiceIDFunc: func(ctx context.Context, id uuid.UUID) ([]entity.PortMapping, error) {
+					return existing, nil
+				},
+				CreateFunc: func(ctx context.Context, pm *entity.PortMapping) error {
+					created = pm
+					return nil
+				},
+			}
+
+			routes := traefik.NewUseCase(nil, nil, nil, nil, nil, nil, nil, &config.TraefikConfig{})
+			uc := service.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, nil, portRepo, nil, nil, nil, routes, nil, nil)
+
+			input := tt.input
+			result, err := uc.AddPort(ctx, uuid.New(), serviceID, &input)
+			if err != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				if created != nil {
+					t.Error("expected no port mapping to be stored")
+				}
+				return
+			}
+
+			if created == nil || created.ID != result.ID {
+				t.Fatal("expected port mapping to be stored")
+			}
+			if result.Protocol != tt.wantProtocol {
+				t.Errorf("expected protocol %s, got %s", tt.wantProtocol, result.Protocol)
+			}
+			if result.ServiceID != serviceID {
+				t.Errorf("expected service ID %s, got %s", serviceID, result.ServiceID)
+			}
+		})
+	}
+}
+
+func TestAddPort_NotTeamMember(t *testing.T) {
+	ctx := context.Background()
+
+	serviceRepo := &mocks.MockServiceRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+			return &entity.Service{ID: id, ProjectID: uuid.New()}, nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return nil, nil
+		},
+	}
+
+	portRepo := &mocks.MockPortMappingRepository{
+		CreateFunc: func(ctx context.Context, pm *entity.PortMapping) error {
+			t.Error("expected no port mapping to be stored")
+			return nil
+		},
+	}
+
+	uc := service.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, nil, portRepo, nil, nil, nil, nil, nil, nil)
+
+	_, err := uc.AddPort(ctx, uuid.New(), uuid.New(), &entity.PortMappingCreate{ContainerPort: 8080})
+	if err != service.ErrNotTeamMember {
+		t.Errorf("expected ErrNotTeamMember, got %v", err)
+	}
+}
+
+func TestDeletePort_Success(t *testing.T) {
+	ctx := context.Background()
+	portID := uuid.New()
+
+	serviceRepo := &mocks.MockServiceRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+			return &entity.Service{ID: id, ProjectID: uuid.New()}, nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleMember}, nil
+		},
+	}
+
+	var deleted uuid.UUID
+	portRepo := &mocks.MockPortMappingRepository{
+		ListByServiceIDFunc: func(ctx context.Context, id uuid.UUID) ([]entity.PortMapping, error) {
+			return []entity.PortMapping{{ID: portID, ServiceID: id, ContainerPort: 8080, Protocol: "tcp"}}, nil
+		},
+		DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
+			deleted = id
+			return nil
+		},
+	}
+
+	routes := traefik.NewUseCase(nil, nil, nil, nil, nil, nil, nil, &config.TraefikConfig{})
+	uc := service.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, nil, portRepo, nil, nil, nil, routes, nil, nil)
+
+	if err := uc.DeletePort(ctx, uuid.New(), uuid.New(), portID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted != portID {
+		t.Errorf("expected port mapping %s to be deleted, got %s", portID, deleted)
+	}
+}
+
+func TestDeletePort_NotFound(t *testing.T) {
+	ctx := context.Background()
+
+	serviceRepo := &mocks.MockServiceRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+			return &entity.Service{ID: id, ProjectID: uuid.New()}, nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleMember}, nil
+		},
+	}
+
+	portRepo := &mocks.MockPortMappingRepository{
+		ListByServiceIDFunc: func(ctx context.Context, id uuid.UUID) ([]entity.PortMapping, error) {
+			return []entity.PortMapping{{ID: uuid.New(), ServiceID: id, ContainerPort: 8080, Protocol: "tcp"}}, nil
+		},
+		DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
+			t.Error("expected no port mapping to be deleted")
+			return nil
+		},
+	}
+
+	routes := traefik.NewUseCase(nil, nil, nil, nil, nil, nil, nil, &config.TraefikConfig{})
+	uc := service.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, nil, portRepo, nil, nil, nil, routes, nil, nil)
+
+	if err := uc.DeletePort(ctx, uuid.New(), uuid.New(), uuid.New()); err != service.ErrPortNotFound {
+		t.Errorf("expected ErrPortNotFound, got %v", err)
+	}
+}
@@ -4,12 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
+	domainDNS "github.com/podoru/spinner-podoru/internal/domain/dns"
 	"github.com/podoru/spinner-podoru/internal/domain/entity"
 	"github.com/podoru/spinner-podoru/internal/domain/repository"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/config"
+	"github.com/podoru/spinner-podoru/internal/usecase/traefik"
 	"github.com/podoru/spinner-podoru/pkg/crypto"
 )
 
@@ -20,16 +25,49 @@ var (
 	ErrNotTeamMember      = errors.New("not a team member")
 	ErrNotTeamAdmin       = errors.New("requires admin or owner role")
 	ErrImageRequired      = errors.New("image is required for image deploy type")
+	ErrRepositoryRequired = errors.New("project repository is required for source builds")
 	ErrDomainNotFound     = errors.New("domain not found")
 	ErrDomainAlreadyInUse = errors.New("domain already in use")
+	ErrDomainConflict     = errors.New("domain conflicts with an existing www redirect")
+	ErrStripWithoutPath   = errors.New("strip_prefix requires a path_prefix")
+	ErrPortNotFound       = errors.New("port mapping not found")
+	ErrPortAlreadyMapped  = errors.New("container port already mapped")
+
+	ErrCertificateNotFound = errors.New("certificate not found")
+	ErrCertificateExpired  = errors.New("certificate has expired")
+	ErrCertificateMismatch = errors.New("certificate does not cover the domain")
+
+	ErrUnsupportedOption  = errors.New("option not supported by the routing mode")
+	ErrEntryPointRequired = errors.New("tcp and udp routing require an entry_point and a port")
+	ErrUnknownEntryPoint  = errors.New("entry point is not configured for the routing mode")
+	ErrEntryPointInUse    = errors.New("entry point already routed to another domain")
+
+	ErrInvalidExportName = errors.New("export names must be letters, digits and underscores, not starting with a digit")
+
+	ErrEnvVarNotFound      = errors.New("env var not found")
+	ErrEnvVarValueRequired = errors.New("value is required for a new env var")
+	ErrInvalidEnvVarName   = errors.New("env var names must be letters, digits and underscores, not starting with a digit")
+	ErrInvalidDotenv       = errors.New("invalid .env file")
+
+	ErrVerificationRecordMissing = errors.New("verification TXT record not found")
+	ErrDNSLookupFailed           = errors.New("DNS lookup failed")
 )
 
+// verificationTokenPrefix marks Podoru's value among a host's TXT records
+const verificationTokenPrefix = "podoru-verification="
+
 type UseCase struct {
-	serviceRepo    repository.ServiceRepository
-	projectRepo    repository.ProjectRepository
-	teamMemberRepo repository.TeamMemberRepository
-	domainRepo     repository.DomainRepository
-	encryptor      *crypto.Encryptor
+	serviceRepo     repository.ServiceRepository
+	projectRepo     repository.ProjectRepository
+	teamMemberRepo  repository.TeamMemberRepository
+	domainRepo      repository.DomainRepository
+	portRepo        repository.PortMappingRepository
+	certificateRepo repository.CertificateRepository
+	envVarRepo      repository.ServiceEnvVarRepository
+	encryptor       *crypto.Encryptor
+	routes          *traefik.UseCase
+	resolver        domainDNS.Resolver
+	domainsConfig   *config.DomainsConfig
 }
 
 func NewUseCase(
@@ -37,14 +75,26 @@ func NewUseCase(
 	projectRepo repository.ProjectRepository,
 	teamMemberRepo repository.TeamMemberRepository,
 	domainRepo repository.DomainRepository,
+	portRepo repository.PortMappingRepository,
+	certificateRepo repository.CertificateRepository,
+	envVarRepo repository.ServiceEnvVarRepository,
 	encryptor *crypto.Encryptor,
+	routes *traefik.UseCase,
+	resolver domainDNS.Resolver,
+	domainsConfig *config.DomainsConfig,
 ) *UseCase {
 	return &UseCase{
-		serviceRepo:    serviceRepo,
-		projectRepo:    projectRepo,
-		teamMemberRepo: teamMemberRepo,
-		domainRepo:     domainRepo,
-		encryptor:      encryptor,
+		serviceRepo:     serviceRepo,
+		projectRepo:     projectRepo,
+		teamMemberRepo:  teamMemberRepo,
+		domainRepo:      domainRepo,
+		portRepo:        portRepo,
+		certificateRepo: certificateRepo,
+		envVarRepo:      envVarRepo,
+		encryptor:       encryptor,
+		routes:          routes,
+		resolver:        resolver,
+		domainsConfig:   domainsConfig,
 	}
 }
 
@@ -77,6 +127,17 @@ func (uc *UseCase) Create(ctx context.Context, userID, projectID uuid.UUID, inpu
 		return nil, ErrImageRequired
 	}
 
+	if input.DeployType.BuildsFromSource() && (project.GithubRepo == nil || *project.GithubRepo == "") {
+		return nil, ErrRepositoryRequired
+	}
+
+	if err := validateExports(input.Exports); err != nil {
+		return nil, err
+	}
+	if err := validateEnvVarNames(input.EnvVars, input.Secrets); err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
 	service := &entity.Service{
 		ID:                  uuid.New(),
@@ -91,6 +152,7 @@ func (uc *UseCase) Create(ctx context.Context, userID, projectID uuid.UUID, inpu
 		Replicas:            1,
 		HealthCheckInterval: 30,
 		RestartPolicy:       entity.RestartPolicyUnlessStopped,
+		AutoUpdateInterval:  3600,
 		Status:              entity.ServiceStatusStopped,
 		CreatedAt:           now,
 		UpdatedAt:           now,
@@ -102,6 +164,9 @@ func (uc *UseCase) Create(ctx context.Context, userID, projectID uuid.UUID, inpu
 	if input.BuildContext != nil {
 		service.BuildContext = *input.BuildContext
 	}
+	if input.BuildTarget != nil && *input.BuildTarget != "" {
+		service.BuildTarget = input.BuildTarget
+	}
 	if input.Replicas != nil {
 		service.Replicas = *input.Replicas
 	}
@@ -120,27 +185,45 @@ func (uc *UseCase) Create(ctx context.Context, userID, projectID uuid.UUID, inpu
 	if input.RestartPolicy != nil {
 		service.RestartPolicy = *input.RestartPolicy
 	}
+	if input.AutoUpdate != nil {
+		service.AutoUpdate = *input.AutoUpdate
+	}
+	if input.AutoUpdateInterval != nil {
+		service.AutoUpdateInterval = *input.AutoUpdateInterval
+	}
 
-	if input.EnvVars != nil && len(input.EnvVars) > 0 {
-		envJSON, err := json.Marshal(input.EnvVars)
+	if len(input.Exports) > 0 {
+		encrypted, err := uc.encryptVars(input.Exports)
 		if err != nil {
 			return nil, err
 		}
-		encrypted, err := uc.encryptor.Encrypt(envJSON)
+		service.ExportsEncrypted = encrypted
+	}
+
+	if len(input.BuildArgs) > 0 {
+		encrypted, err := uc.encryptVars(input.BuildArgs)
 		if err != nil {
 			return nil, err
 		}
-		service.EnvVarsEncrypted = encrypted
+		service.BuildArgsEncrypted = encrypted
 	}
 
 	if err := uc.serviceRepo.Create(ctx, service); err != nil {
 		return nil, err
 	}
 
+	if len(input.EnvVars) > 0 || len(input.Secrets) > 0 {
+		if err := uc.replaceEnvVars(ctx, service.ID, member, input.EnvVars, input.Secrets); err != nil {
+			return nil, err
+		}
+	}
+
 	return service, nil
 }
 
-func (uc *UseCase) GetByID(ctx context.Context, userID, serviceID uuid.UUID) (*entity.Service, error) {
+// GetByID returns the service with its env vars, secrets masked, domains,
+// port mappings and generated platform hostname
+func (uc *UseCase) GetByID(ctx context.Context, userID, serviceID uuid.UUID) (*entity.ServiceWithDetails, error) {
 	service, err := uc.serviceRepo.GetByID(ctx, serviceID)
 	if err != nil {
 		return nil, err
@@ -165,7 +248,28 @@ func (uc *UseCase) GetByID(ctx context.Context, userID, serviceID uuid.UUID) (*e
 		return nil, ErrNotTeamMember
 	}
 
-	return service, nil
+	domains, err := uc.domainRepo.ListByServiceID(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	portMappings, err := uc.portRepo.ListByServiceID(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	envVars, err := uc.maskedEnvVars(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.ServiceWithDetails{
+		Service:        *service,
+		EnvVars:        envVars,
+		Domains:        domains,
+		PortMappings:   portMappings,
+		PlatformDomain: uc.routes.PlatformDomain(service, project),
+	}, nil
 }
 
 func (uc *UseCase) ListByProject(ctx context.Context, userID, projectID uuid.UUID) ([]entity.Service, error) {
@@ -225,6 +329,14 @@ func (uc *UseCase) Update(ctx context.Context, userID, serviceID uuid.UUID, inpu
 	if input.BuildContext != nil {
 		service.BuildContext = *input.BuildContext
 	}
+	if input.BuildTarget != nil {
+		// An empty target clears it and builds the final stage again
+		if *input.BuildTarget == "" {
+			service.BuildTarget = nil
+		} else {
+			service.BuildTarget = input.BuildTarget
+		}
+	}
 	if input.ComposeFile != nil {
 		service.ComposeFile = input.ComposeFile
 	}
@@ -246,20 +358,44 @@ func (uc *UseCase) Update(ctx context.Context, userID, serviceID uuid.UUID, inpu
 	if input.RestartPolicy != nil {
 		service.RestartPolicy = *input.RestartPolicy
 	}
+	if input.AutoUpdate != nil {
+		service.AutoUpdate = *input.AutoUpdate
+	}
+	if input.AutoUpdateInterval != nil {
+		service.AutoUpdateInterval = *input.AutoUpdateInterval
+	}
+
+	// Given together, env_vars and secrets replace all of the service's vars
+	if input.EnvVars != nil || input.Secrets != nil {
+		if err := uc.replaceEnvVars(ctx, service.ID, member, input.EnvVars, input.Secrets); err != nil {
+			return nil, err
+		}
+	}
 
-	if input.EnvVars != nil {
-		if len(input.EnvVars) > 0 {
-			envJSON, err := json.Marshal(input.EnvVars)
+	if input.Exports != nil {
+		if err := validateExports(input.Exports); err != nil {
+			return nil, err
+		}
+		if len(input.Exports) > 0 {
+			encrypted, err := uc.encryptVars(input.Exports)
 			if err != nil {
 				return nil, err
 			}
-			encrypted, err := uc.encryptor.Encrypt(envJSON)
+			service.ExportsEncrypted = encrypted
+		} else {
+			service.ExportsEncrypted = nil
+		}
+	}
+
+	if input.BuildArgs != nil {
+		if len(input.BuildArgs) > 0 {
+			encrypted, err := uc.encryptVars(input.BuildArgs)
 			if err != nil {
 				return nil, err
 			}
-			service.EnvVarsEncrypted = encrypted
+			service.BuildArgsEncrypted = encrypted
 		} else {
-			service.EnvVarsEncrypted = nil
+			service.BuildArgsEncrypted = nil
 		}
 	}
 
@@ -300,7 +436,12 @@ func (uc *UseCase) Delete(ctx context.Context, userID, serviceID uuid.UUID) erro
 		return ErrNotTeamAdmin
 	}
 
-	return uc.serviceRepo.Delete(ctx, serviceID)
+	if err := uc.serviceRepo.Delete(ctx, serviceID); err != nil {
+		return err
+	}
+	uc.routes.Refresh()
+
+	return nil
 }
 
 func (uc *UseCase) Scale(ctx context.Context, userID, serviceID uuid.UUID, replicas int) (*entity.Service, error) {
@@ -362,16 +503,38 @@ func (uc *UseCase) AddDomain(ctx context.Context, userID, serviceID uuid.UUID, i
 		return nil, ErrNotTeamMember
 	}
 
-	// Check if domain already exists
-	exists, err := uc.domainRepo.ExistsByDomain(ctx, input.Domain)
-	if err != nil {
-		return nil, err
+	domain := &entity.Domain{
+		ID:        uuid.New(),
+		ServiceID: serviceID,
+		Domain:    strings.ToLower(input.Domain),
+		Port:      input.Port,
+		// "/" and "/api/" route the same as "" and "/api"
+		PathPrefix:      strings.TrimRight(derefString(input.PathPrefix), "/"),
+		StripPrefix:     input.StripPrefix != nil && *input.StripPrefix,
+		RoutingMode:     entity.RoutingModeHTTP,
+		EntryPoint:      derefString(input.EntryPoint),
+		TLSPassthrough:  input.TLSPassthrough != nil && *input.TLSPassthrough,
+		WWWRedirect:     input.WWWRedirect != nil && *input.WWWRedirect,
+		IPAllowList:     input.IPAllowList,
+		ResponseHeaders: input.ResponseHeaders,
+		RateLimit:       input.RateLimit,
+		CreatedAt:       time.Now(),
 	}
-	if exists {
-		return nil, ErrDomainAlreadyInUse
+
+	if input.RoutingMode != nil {
+		domain.RoutingMode = *input.RoutingMode
+	}
+
+	if domain.StripPrefix && domain.PathPrefix == "" {
+		return nil, ErrStripWithoutPath
 	}
 
-	sslEnabled := true
+	if err := uc.checkRoutingMode(domain, input); err != nil {
+		return nil, err
+	}
+
+	// TLS is opt-in for tcp, where most protocols bring their own
+	sslEnabled := domain.IsHTTP()
 	sslAuto := true
 	if input.SSLEnabled != nil {
 		sslEnabled = *input.SSLEnabled
@@ -380,18 +543,47 @@ func (uc *UseCase) AddDomain(ctx context.Context, userID, serviceID uuid.UUID, i
 		sslAuto = *input.SSLAuto
 	}
 
-	domain := &entity.Domain{
-		ID:         uuid.New(),
-		ServiceID:  serviceID,
-		Domain:     input.Domain,
-		SSLEnabled: sslEnabled,
-		SSLAuto:    sslAuto,
-		CreatedAt:  time.Now(),
+	domain.SSLEnabled = sslEnabled
+	domain.SSLAuto = sslAuto
+
+	// An uploaded certificate replaces Let's Encrypt for the domain
+	if input.CertificateID != nil {
+		if err := uc.checkCertificate(ctx, project.TeamID, *input.CertificateID, domain); err != nil {
+			return nil, err
+		}
+		domain.CertificateID = input.CertificateID
+		domain.SSLEnabled = true
+		domain.SSLAuto = false
+	}
+
+	if err := uc.checkDomainConflicts(ctx, domain); err != nil {
+		return nil, err
+	}
+
+	for _, user := range input.BasicAuth {
+		hash, err := crypto.HashPassword(user.Password)
+		if err != nil {
+			return nil, err
+		}
+		domain.BasicAuthUsers = append(domain.BasicAuthUsers, user.Username+":"+hash)
+	}
+
+	if uc.domainsConfig.VerificationEnabled {
+		token, err := crypto.GenerateRandomString(32)
+		if err != nil {
+			return nil, err
+		}
+		domain.Status = entity.DomainStatusPending
+		domain.VerificationToken = verificationTokenPrefix + token
+	} else {
+		domain.Status = entity.DomainStatusVerified
+		domain.VerifiedAt = &domain.CreatedAt
 	}
 
 	if err := uc.domainRepo.Create(ctx, domain); err != nil {
 		return nil, err
 	}
+	uc.routes.Refresh()
 
 	return domain, nil
 }
@@ -458,5 +650,263 @@ func (uc *UseCase) DeleteDomain(ctx context.Context, userID, serviceID, domainID
 		return ErrDomainNotFound
 	}
 
-	return uc.domainRepo.Delete(ctx, domainID)
+	if err := uc.domainRepo.Delete(ctx, domainID); err != nil {
+		return err
+	}
+	uc.routes.Refresh()
+
+	return nil
+}
+
+// VerifyDomain looks up the domain's TXT record and starts routing the domain
+// once it holds the verification token
+func (uc *UseCase) VerifyDomain(ctx context.Context, userID, serviceID, domainID uuid.UUID) (*entity.Domain, error) {
+	service, err := uc.serviceRepo.GetByID(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if service == nil {
+		return nil, ErrServiceNotFound
+	}
+
+	project, err := uc.projectRepo.GetByID(ctx, service.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, ErrProjectNotFound
+	}
+
+	member, err := uc.teamMemberRepo.GetByTeamAndUser(ctx, project.TeamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrNotTeamMember
+	}
+
+	domain, err := uc.domainRepo.GetByID(ctx, domainID)
+	if err != nil {
+		return nil, err
+	}
+	if domain == nil || domain.ServiceID != serviceID {
+		return nil, ErrDomainNotFound
+	}
+	if domain.IsVerified() {
+		return domain, nil
+	}
+
+	records, err := uc.resolver.LookupTXT(ctx, domain.VerificationRecord())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDNSLookupFailed, err)
+	}
+	found := false
+	for _, record := range records {
+		if strings.TrimSpace(record) == domain.VerificationToken {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, ErrVerificationRecordMissing
+	}
+
+	// Another claim on the host may have been verified in the meantime
+	if err := uc.checkDomainConflicts(ctx, domain); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := uc.domainRepo.MarkVerified(ctx, domain.ID, now); err != nil {
+		return nil, err
+	}
+	uc.routes.Refresh()
+
+	domain.Status = entity.DomainStatusVerified
+	domain.VerificationToken = ""
+	domain.VerifiedAt = &now
+	return domain, nil
+}
+
+// checkRoutingMode rejects options the domain's routing mode cannot honour.
+// HTTP features need a request to act on, tcp and udp need an entrypoint of
+// their own, and udp has no TLS at all.
+func (uc *UseCase) checkRoutingMode(domain *entity.Domain, input *entity.DomainCreate) error {
+	unsupported := func(option string) error {
+		return fmt.Errorf("%w: %s", ErrUnsupportedOption, option)
+	}
+
+	if domain.IsHTTP() {
+		switch {
+		case domain.EntryPoint != "":
+			return unsupported("entry_point")
+		case domain.TLSPassthrough:
+			return unsupported("tls_passthrough")
+		}
+		return nil
+	}
+
+	switch {
+	case domain.PathPrefix != "":
+		return unsupported("path_prefix")
+	case domain.StripPrefix:
+		return unsupported("strip_prefix")
+	case domain.WWWRedirect:
+		return unsupported("www_redirect")
+	case len(input.BasicAuth) > 0:
+		return unsupported("basic_auth")
+	case len(domain.ResponseHeaders) > 0:
+		return unsupported("response_headers")
+	case domain.RateLimit != nil:
+		return unsupported("rate_limit")
+	}
+
+	if domain.EntryPoint == "" || domain.Port == nil {
+		return ErrEntryPointRequired
+	}
+	if !uc.routes.HasEntryPoint(domain.RoutingMode, domain.EntryPoint) {
+		return ErrUnknownEntryPoint
+	}
+
+	if domain.RoutingMode == entity.RoutingModeUDP {
+		switch {
+		case input.SSLEnabled != nil && *input.SSLEnabled:
+			return unsupported("ssl_enabled")
+		case input.CertificateID != nil:
+			return unsupported("certificate_id")
+		case domain.TLSPassthrough:
+			return unsupported("tls_passthrough")
+		case len(domain.IPAllowList) > 0:
+			return unsupported("ip_allow_list")
+		}
+	}
+
+	// Passthrough leaves TLS to the container, Traefik holds no certificate
+	if domain.TLSPassthrough && (input.SSLEnabled != nil && *input.SSLEnabled || input.CertificateID != nil) {
+		return unsupported("ssl_enabled with tls_passthrough")
+	}
+	return nil
+}
+
+// checkCertificate makes sure a team certificate is usable for the domain,
+// including the www counterpart the domain may redirect from
+func (uc *UseCase) checkCertificate(ctx context.Context, teamID, certificateID uuid.UUID, domain *entity.Domain) error {
+	certificate, err := uc.certificateRepo.GetByID(ctx, certificateID)
+	if err != nil {
+		return err
+	}
+	if certificate == nil || certificate.TeamID != teamID {
+		return ErrCertificateNotFound
+	}
+	if time.Now().After(certificate.NotAfter) {
+		return ErrCertificateExpired
+	}
+
+	if !certificate.Covers(domain.Domain) {
+		return ErrCertificateMismatch
+	}
+	if domain.WWWRedirect && !certificate.Covers(entity.WWWCounterpart(domain.Domain)) {
+		return fmt.Errorf("%w: %s", ErrCertificateMismatch, entity.WWWCounterpart(domain.Domain))
+	}
+	return nil
+}
+
+// checkDomainConflicts rejects a route whose host and path are already taken
+// or that clashes with a www redirect, whichever service owns the other route.
+// Pending claims are ignored so nobody can hold a host they do not control
+func (uc *UseCase) checkDomainConflicts(ctx context.Context, domain *entity.Domain) error {
+	if !domain.IsHTTP() {
+		return uc.checkEntryPointConflicts(ctx, domain)
+	}
+
+	counterpart := entity.WWWCounterpart(domain.Domain)
+
+	existing, err := uc.domainRepo.ListByDomains(ctx, []string{domain.Domain, counterpart})
+	if err != nil {
+		return err
+	}
+
+	for _, d := range existing {
+		if !d.IsVerified() || d.ID == domain.ID || !d.IsHTTP() {
+			continue
+		}
+		switch {
+		case d.Domain == domain.Domain && d.PathPrefix == domain.PathPrefix:
+			return ErrDomainAlreadyInUse
+		case d.Domain == domain.Domain && d.WWWRedirect && domain.WWWRedirect:
+			// Another path on the host already redirects the counterpart
+			return ErrDomainConflict
+		case d.Domain == counterpart && domain.WWWRedirect:
+			// The redirect would hijack a host that is already served
+			return ErrDomainConflict
+		case d.Domain == counterpart && d.WWWRedirect:
+			// The host is already redirected to its counterpart
+			return ErrDomainConflict
+		}
+	}
+	return nil
+}
+
+// checkEntryPointConflicts keeps tcp and udp routes on one entrypoint apart.
+// SNI routes may share an entrypoint as long as their hosts differ, while a
+// plain TCP or UDP route needs the entrypoint to itself.
+func (uc *UseCase) checkEntryPointConflicts(ctx context.Context, domain *entity.Domain) error {
+	existing, err := uc.domainRepo.ListByEntryPoint(ctx, domain.EntryPoint)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range existing {
+		if !d.IsVerified() || d.ID == domain.ID {
+			continue
+		}
+		switch {
+		case !d.MatchesSNI() || !domain.MatchesSNI():
+			return ErrEntryPointInUse
+		case d.Domain == domain.Domain:
+			return ErrDomainAlreadyInUse
+		}
+	}
+	return nil
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// validateExports checks export names can be referenced as ${{service.NAME}}
+func validateExports(exports map[string]string) error {
+	for name := range exports {
+		if !entity.IsValidVarName(name) {
+			return fmt.Errorf("%w: %q", ErrInvalidExportName, name)
+		}
+	}
+	return nil
+}
+
+// encryptVars seals a key/value map, such as env vars or build args, for storage
+func (uc *UseCase) encryptVars(vars map[string]string) ([]byte, error) {
+	data, err := json.Marshal(vars)
+	if err != nil {
+		return nil, err
+	}
+	return uc.encryptor.Encrypt(data)
+}
+
+func (uc *UseCase) decryptVars(data []byte) (map[string]string, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	decrypted, err := uc.encryptor.Decrypt(data)
+	if err != nil {
+		return nil, err
+	}
+	var vars map[string]string
+	if err := json.Unmarshal(decrypted, &vars); err != nil {
+		return nil, err
+	}
+	return vars, nil
 }
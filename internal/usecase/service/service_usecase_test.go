@@ -0,0 +1,275 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/config"
+	"github.com/podoru/spinner-podoru/internal/mocks"
+	"github.com/podoru/spinner-podoru/internal/usecase/service"
+	"github.com/podoru/spinner-podoru/internal/usecase/traefik"
+)
+
+func TestAddDomain_Conflicts(t *testing.T) {
+	redirect := true
+	apiPath := "/api"
+	adminPath := "/admin"
+
+	tests := []struct {
+		name     string
+		existing entity.Domain
+		input    entity.DomainCreate
+		wantErr  error
+	}{
+		{
+			name:     "same host and path",
+			existing: entity.Domain{Domain: "example.com", PathPrefix: "/api"},
+			input:    entity.DomainCreate{Domain: "example.com", PathPrefix: &apiPath},
+			wantErr:  service.ErrDomainAlreadyInUse,
+		},
+		{
+			name:     "same host on another path",
+			existing: entity.Domain{Domain: "example.com", PathPrefix: "/api"},
+			input:    entity.DomainCreate{Domain: "example.com", PathPrefix: &adminPath},
+		},
+		{
+			name:     "redirect onto a served host",
+			existing: entity.Domain{Domain: "www.example.com"},
+			input:    entity.DomainCreate{Domain: "example.com", WWWRedirect: &redirect},
+			wantErr:  service.ErrDomainConflict,
+		},
+		{
+			name:     "host already redirected",
+			existing: entity.Domain{Domain: "www.example.com", WWWRedirect: true},
+			input:    entity.DomainCreate{Domain: "example.com"},
+			wantErr:  service.ErrDomainConflict,
+		},
+		{
+			name:     "second redirect on the same host",
+			existing: entity.Domain{Domain: "example.com", PathPrefix: "/api", WWWRedirect: true},
+			input:    entity.DomainCreate{Domain: "example.com", PathPrefix: &adminPath, WWWRedirect: &redirect},
+			wantErr:  service.ErrDomainConflict,
+		},
+		{
+			name:     "redirect beside a path without one",
+			existing: entity.Domain{Domain: "example.com", PathPrefix: "/api"},
+			input:    entity.DomainCreate{Domain: "example.com", PathPrefix: &adminPath, WWWRedirect: &redirect},
+		},
+		{
+			name:     "pending claim is ignored",
+			existing: entity.Domain{Domain: "example.com", Status: entity.DomainStatusPending},
+			input:    entity.DomainCreate{Domain: "example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			serviceRepo := &mocks.MockServiceRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+					return &entity.Service{ID: id, ProjectID: uuid.New()}, nil
+				},
+			}
+
+			projectRepo := &mocks.MockProjectRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+					return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+				},
+			}
+
+			teamMemberRepo := &mocks.MockTeamMemberRepository{
+				GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+					return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleMember}, nil
+				},
+			}
+
+			existing := tt.existing
+			existing.ID = uuid.New()
+			existing.ServiceID = uuid.New()
+			existing.RoutingMode = entity.RoutingModeHTTP
+			if existing.Status == "" {
+				existing.Status = entity.DomainStatusVerified
+			}
+
+			var created *entity.Domain
+			domainRepo := &mocks.MockDomainRepository{
+				ListByDomainsFunc: func(ctx context.Context, domains []string) ([]entity.Domain, error) {
+					for _, d := range domains {
+						if d == existing.Domain {
+							return []entity.Domain{existing}, nil
+						}
+					}
+					return nil, nil
+				},
+				CreateFunc: func(ctx context.Context, d *entity.Domain) error {
+					created = d
+					return nil
+				},
+			}
+
+			routes := traefik.NewUseCase(nil, nil, nil, nil, nil, nil, nil, &config.TraefikConfig{})
+			uc := service.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, domainRepo, nil, nil, nil, nil, routes, nil, &config.DomainsConfig{})
+
+			input := tt.input
+			_, err := uc.AddDomain(ctx, uuid.New(), uuid.New(), &input)
+			if err != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if (created != nil) != (tt.wantErr == nil) {
+				t.Errorf("expected domain stored %v, got %v", tt.wantErr == nil, created != nil)
+			}
+		})
+	}
+}
+
+func TestVerifyDomain(t *testing.T) {
+	token := "podoru-verification=abc123"
+
+	tests := []struct {
+		name      string
+		records   []string
+		lookupErr error
+		claimed   bool
+		wantErr   error
+	}{
+		{name: "token found", records: []string{"v=spf1 -all", " " + token + " "}},
+		{name: "record missing", records: []string{"v=spf1 -all"}, wantErr: service.ErrVerificationRecordMissing},
+		{name: "other token", records: []string{"podoru-verification=other"}, wantErr: service.ErrVerificationRecordMissing},
+		{name: "lookup fails", lookupErr: errors.New("SERVFAIL"), wantErr: service.ErrDNSLookupFailed},
+		{name: "host verified meanwhile by another claim", records: []string{token}, claimed: true, wantErr: service.ErrDomainAlreadyInUse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			serviceID := uuid.New()
+			domain := &entity.Domain{
+				ID:                uuid.New(),
+				ServiceID:         serviceID,
+				Domain:            "example.com",
+				RoutingMode:       entity.RoutingModeHTTP,
+				Status:            entity.DomainStatusPending,
+				VerificationToken: token,
+			}
+
+			serviceRepo := &mocks.MockServiceRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+					return &entity.Service{ID: id, ProjectID: uuid.New()}, nil
+				},
+			}
+
+			projectRepo := &mocks.MockProjectRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+					return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+				},
+			}
+
+			teamMemberRepo := &mocks.MockTeamMemberRepository{
+				GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+					return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleMember}, nil
+				},
+			}
+
+			var marked bool
+			domainRepo := &mocks.MockDomainRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Domain, error) {
+					return domain, nil
+				},
+				ListByDomainsFunc: func(ctx context.Context, domains []string) ([]entity.Domain, error) {
+					if !tt.claimed {
+						return []entity.Domain{*domain}, nil
+					}
+					other := entity.Domain{
+						ID:          uuid.New(),
+						ServiceID:   uuid.New(),
+						Domain:      "example.com",
+						RoutingMode: entity.RoutingModeHTTP,
+						Status:      entity.DomainStatusVerified,
+					}
+					return []entity.Domain{*domain, other}, nil
+				},
+				MarkVerifiedFunc: func(ctx context.Context, id uuid.UUID, at time.Time) error {
+					marked = id == domain.ID
+					return nil
+				},
+			}
+
+			resolver := &mocks.MockResolver{
+				LookupTXTFunc: func(ctx context.Context, name string) ([]string, error) {
+					if name != entity.DomainVerificationPrefix+"example.com" {
+						t.Errorf("unexpected TXT lookup for %s", name)
+					}
+					return tt.records, tt.lookupErr
+				},
+			}
+
+			routes := traefik.NewUseCase(nil, nil, nil, nil, nil, nil, nil, &config.TraefikConfig{})
+			uc := service.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, domainRepo, nil, nil, nil, nil, routes, resolver, &config.DomainsConfig{VerificationEnabled: true})
+
+			result, err := uc.VerifyDomain(ctx, uuid.New(), serviceID, domain.ID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if marked != (tt.wantErr == nil) {
+				t.Errorf("expected domain marked verified %v, got %v", tt.wantErr == nil, marked)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if !result.IsVerified() || result.VerifiedAt == nil {
+				t.Error("expected domain to be verified")
+			}
+			if result.VerificationToken != "" {
+				t.Error("expected verification token to be cleared")
+			}
+		})
+	}
+}
+
+func TestVerifyDomain_OtherServicesDomain(t *testing.T) {
+	ctx := context.Background()
+
+	serviceRepo := &mocks.MockServiceRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+			return &entity.Service{ID: id, ProjectID: uuid.New()}, nil
+		},
+	}
+
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return &entity.Project{ID: id, TeamID: uuid.New()}, nil
+		},
+	}
+
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{TeamID: teamID, UserID: userID, Role: entity.TeamRoleMember}, nil
+		},
+	}
+
+	domainRepo := &mocks.MockDomainRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Domain, error) {
+			return &entity.Domain{ID: id, ServiceID: uuid.New(), Domain: "example.com", Status: entity.DomainStatusPending}, nil
+		},
+	}
+
+	resolver := &mocks.MockResolver{
+		LookupTXTFunc: func(ctx context.Context, name string) ([]string, error) {
+			t.Error("expected no DNS lookup")
+			return nil, nil
+		},
+	}
+
+	uc := service.NewUseCase(serviceRepo, projectRepo, teamMemberRepo, domainRepo, nil, nil, nil, nil, nil, resolver, &config.DomainsConfig{VerificationEnabled: true})
+
+	if _, err := uc.VerifyDomain(ctx, uuid.New(), uuid.New(), uuid.New()); err != service.ErrDomainNotFound {
+		t.Errorf("expected ErrDomainNotFound, got %v", err)
+	}
+}
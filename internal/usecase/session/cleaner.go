@@ -0,0 +1,50 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/podoru/spinner-podoru/internal/domain/repository"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/config"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/logger"
+)
+
+// Cleaner removes expired sessions and refresh tokens. Used refresh tokens
+// are kept until they expire, so their reuse can be detected until then.
+type Cleaner struct {
+	refreshTokenRepo repository.RefreshTokenRepository
+	interval         time.Duration
+	log              *logger.Logger
+}
+
+// NewCleaner creates a new session cleaner
+func NewCleaner(refreshTokenRepo repository.RefreshTokenRepository, cfg *config.SessionConfig, log *logger.Logger) *Cleaner {
+	return &Cleaner{
+		refreshTokenRepo: refreshTokenRepo,
+		interval:         cfg.CleanupInterval,
+		log:              log,
+	}
+}
+
+// Run blocks until ctx is cancelled, cleaning up once at the start and then
+// on every tick
+func (c *Cleaner) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	c.cleanup(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.cleanup(ctx)
+		}
+	}
+}
+
+func (c *Cleaner) cleanup(ctx context.Context) {
+	if err := c.refreshTokenRepo.DeleteExpired(ctx); err != nil && ctx.Err() == nil {
+		c.log.Errorw("Failed to delete expired sessions", "error", err)
+	}
+}
@@ -0,0 +1,52 @@
+package session
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/domain/repository"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+type UseCase struct {
+	refreshTokenRepo repository.RefreshTokenRepository
+}
+
+func NewUseCase(refreshTokenRepo repository.RefreshTokenRepository) *UseCase {
+	return &UseCase{
+		refreshTokenRepo: refreshTokenRepo,
+	}
+}
+
+// List returns the user's active sessions, marking currentID as the current one
+func (uc *UseCase) List(ctx context.Context, userID, currentID uuid.UUID) ([]entity.Session, error) {
+	sessions, err := uc.refreshTokenRepo.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = currentID != uuid.Nil && sessions[i].ID == currentID
+	}
+	return sessions, nil
+}
+
+// Revoke ends one of the user's sessions. Its refresh token stops working
+// at once; access tokens already issued to it run until they expire.
+func (uc *UseCase) Revoke(ctx context.Context, userID, sessionID uuid.UUID) error {
+	session, err := uc.refreshTokenRepo.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.UserID != userID {
+		return ErrSessionNotFound
+	}
+
+	return uc.refreshTokenRepo.DeleteSession(ctx, sessionID)
+}
@@ -0,0 +1,97 @@
+package session_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/mocks"
+	"github.com/podoru/spinner-podoru/internal/usecase/session"
+)
+
+func TestList_MarksCurrent(t *testing.T) {
+	userID := uuid.New()
+	current := uuid.New()
+
+	repo := &mocks.MockRefreshTokenRepository{
+		ListSessionsFunc: func(ctx context.Context, id uuid.UUID) ([]entity.Session, error) {
+			return []entity.Session{
+				{ID: uuid.New(), UserID: userID},
+				{ID: current, UserID: userID},
+			}, nil
+		},
+	}
+
+	tests := []struct {
+		name      string
+		currentID uuid.UUID
+		want      []bool
+	}{
+		{name: "session token", currentID: current, want: []bool{false, true}},
+		{name: "no session", currentID: uuid.Nil, want: []bool{false, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions, err := session.NewUseCase(repo).List(context.Background(), userID, tt.currentID)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			for i, s := range sessions {
+				if s.Current != tt.want[i] {
+					t.Errorf("sessions[%d].Current = %v, want %v", i, s.Current, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	userID := uuid.New()
+	own := &entity.Session{ID: uuid.New(), UserID: userID}
+	other := &entity.Session{ID: uuid.New(), UserID: uuid.New()}
+
+	tests := []struct {
+		name      string
+		sessionID uuid.UUID
+		wantErr   error
+	}{
+		{name: "own session", sessionID: own.ID},
+		{name: "another user's session", sessionID: other.ID, wantErr: session.ErrSessionNotFound},
+		{name: "unknown session", sessionID: uuid.New(), wantErr: session.ErrSessionNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deleted uuid.UUID
+			repo := &mocks.MockRefreshTokenRepository{
+				GetSessionFunc: func(ctx context.Context, id uuid.UUID) (*entity.Session, error) {
+					for _, s := range []*entity.Session{own, other} {
+						if s.ID == id {
+							return s, nil
+						}
+					}
+					return nil, nil
+				},
+				DeleteSessionFunc: func(ctx context.Context, id uuid.UUID) error {
+					deleted = id
+					return nil
+				},
+			}
+
+			err := session.NewUseCase(repo).Revoke(context.Background(), userID, tt.sessionID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Revoke() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && deleted != tt.sessionID {
+				t.Errorf("expected session %s to be deleted", tt.sessionID)
+			}
+			if tt.wantErr != nil && deleted != uuid.Nil {
+				t.Error("expected no session to be deleted")
+			}
+		})
+	}
+}
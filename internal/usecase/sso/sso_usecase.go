@@ -0,0 +1,279 @@
+package sso
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	domainOIDC "github.com/podoru/spinner-podoru/internal/domain/oidc"
+	"github.com/podoru/spinner-podoru/internal/domain/repository"
+	"github.com/podoru/spinner-podoru/internal/usecase/auth"
+	"github.com/podoru/spinner-podoru/pkg/crypto"
+)
+
+var (
+	ErrInvalidState = errors.New("sign-in attempt is unknown or has expired")
+	ErrSignInFailed = errors.New("single sign-on failed")
+	ErrNoEmail      = errors.New("identity provider did not return an email address")
+	ErrEmailInUse   = errors.New("email belongs to an account that cannot be linked")
+	ErrUserInactive = errors.New("user account is inactive")
+)
+
+const (
+	// StateExpiry is how long the user has to sign in at the provider
+	StateExpiry = 10 * time.Minute
+	// verifierLength is within the 43 to 128 characters PKCE allows
+	verifierLength = 64
+)
+
+type UseCase struct {
+	provider       domainOIDC.Provider
+	stateRepo      repository.OIDCStateRepository
+	userRepo       repository.UserRepository
+	teamRepo       repository.TeamRepository
+	teamMemberRepo repository.TeamMemberRepository
+	authUseCase    *auth.UseCase
+	mappings       []entity.OIDCGroupMapping
+}
+
+func NewUseCase(
+	provider domainOIDC.Provider,
+	stateRepo repository.OIDCStateRepository,
+	userRepo repository.UserRepository,
+	teamRepo repository.TeamRepository,
+	teamMemberRepo repository.TeamMemberRepository,
+	authUseCase *auth.UseCase,
+	mappings []entity.OIDCGroupMapping,
+) *UseCase {
+	return &UseCase{
+		provider:       provider,
+		stateRepo:      stateRepo,
+		userRepo:       userRepo,
+		teamRepo:       teamRepo,
+		teamMemberRepo: teamMemberRepo,
+		authUseCase:    authUseCase,
+		mappings:       mappings,
+	}
+}
+
+// LoginURL starts a sign-in and returns the provider URL to send the user to,
+// along with its state. The browser must keep the state, such as in a cookie,
+// and hand it back to Callback.
+func (uc *UseCase) LoginURL(ctx context.Context) (authURL, state string, err error) {
+	// Abandoned attempts are cleaned up as new ones start
+	_ = uc.stateRepo.DeleteExpired(ctx)
+
+	state, err = crypto.GenerateRandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := crypto.GenerateRandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := crypto.GenerateRandomString(verifierLength)
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	if err := uc.stateRepo.Create(ctx, &entity.OIDCState{
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    now.Add(StateExpiry),
+		CreatedAt:    now,
+	}); err != nil {
+		return "", "", err
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	authURL, err = uc.provider.AuthCodeURL(ctx, state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		return "", "", err
+	}
+	return authURL, state, nil
+}
+
+// Callback finishes a sign-in when the provider redirects back. browserState
+// is the state kept by the browser the sign-in started in; a callback opened
+// in any other browser is refused. The user is found by their provider
+// identity, linked by verified email, or created.
+func (uc *UseCase) Callback(ctx context.Context, code, state, browserState string) (*entity.User, *entity.TokenPair, error) {
+	if code == "" || state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return nil, nil, ErrInvalidState
+	}
+
+	attempt, err := uc.stateRepo.Consume(ctx, state)
+	if err != nil {
+		return nil, nil, err
+	}
+	if attempt == nil || time.Now().After(attempt.ExpiresAt) {
+		return nil, nil, ErrInvalidState
+	}
+
+	identity, err := uc.provider.Exchange(ctx, code, attempt.CodeVerifier, attempt.Nonce)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrSignInFailed, err)
+	}
+
+	user, err := uc.resolveUser(ctx, identity)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !user.IsActive {
+		return nil, nil, ErrUserInactive
+	}
+
+	if err := uc.syncTeams(ctx, user.ID, identity.Groups); err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := uc.authUseCase.IssueTokens(ctx, user)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, tokens, nil
+}
+
+func (uc *UseCase) resolveUser(ctx context.Context, identity *domainOIDC.Identity) (*entity.User, error) {
+	user, err := uc.userRepo.GetByOIDCSubject(ctx, identity.Issuer, identity.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		return user, nil
+	}
+
+	if identity.Email == "" {
+		return nil, ErrNoEmail
+	}
+
+	user, err = uc.userRepo.GetByEmail(ctx, identity.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		// Linking to an unverified address would let anyone who can set
+		// that email at the provider take over the account
+		if !identity.EmailVerified || user.OIDCSubject != nil {
+			return nil, ErrEmailInUse
+		}
+		if err := uc.userRepo.LinkOIDCSubject(ctx, user.ID, identity.Issuer, identity.Subject); err != nil {
+			return nil, err
+		}
+		user.OIDCIssuer = &identity.Issuer
+		user.OIDCSubject = &identity.Subject
+		return user, nil
+	}
+
+	return uc.createUser(ctx, identity)
+}
+
+// createUser provisions a user on first sign-in. They get no password and
+// no personal team; mapped groups decide which teams they join.
+func (uc *UseCase) createUser(ctx context.Context, identity *domainOIDC.Identity) (*entity.User, error) {
+	userCount, err := uc.userRepo.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	role := entity.UserRoleUser
+	if userCount == 0 {
+		role = entity.UserRoleSuperAdmin
+	}
+
+	name := identity.Name
+	if name == "" {
+		name, _, _ = strings.Cut(identity.Email, "@")
+	}
+
+	now := time.Now()
+	user := &entity.User{
+		ID:          uuid.New(),
+		Email:       identity.Email,
+		Name:        name,
+		Role:        role,
+		IsActive:    true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		OIDCIssuer:  &identity.Issuer,
+		OIDCSubject: &identity.Subject,
+	}
+
+	if err := uc.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// syncTeams makes the user's membership of each mapped team match their
+// groups: the highest mapped role is granted, and membership is removed
+// when no group maps to the team any more. Team owners are left alone.
+func (uc *UseCase) syncTeams(ctx context.Context, userID uuid.UUID, groups []string) error {
+	if len(uc.mappings) == 0 {
+		return nil
+	}
+
+	inGroup := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		inGroup[g] = true
+	}
+
+	var slugs []string
+	wanted := make(map[string]entity.TeamRole)
+	for _, m := range uc.mappings {
+		if _, seen := wanted[m.TeamSlug]; !seen {
+			slugs = append(slugs, m.TeamSlug)
+			wanted[m.TeamSlug] = ""
+		}
+		if inGroup[m.Group] && wanted[m.TeamSlug] != entity.TeamRoleAdmin {
+			wanted[m.TeamSlug] = m.Role
+		}
+	}
+
+	for _, slug := range slugs {
+		team, err := uc.teamRepo.GetBySlug(ctx, slug)
+		if err != nil {
+			return err
+		}
+		if team == nil {
+			continue
+		}
+
+		member, err := uc.teamMemberRepo.GetByTeamAndUser(ctx, team.ID, userID)
+		if err != nil {
+			return err
+		}
+		role := wanted[slug]
+
+		switch {
+		case member != nil && member.Role == entity.TeamRoleOwner:
+		case member == nil && role != "":
+			err = uc.teamMemberRepo.Create(ctx, &entity.TeamMember{
+				ID:        uuid.New(),
+				TeamID:    team.ID,
+				UserID:    userID,
+				Role:      role,
+				CreatedAt: time.Now(),
+			})
+		case member != nil && role == "":
+			err = uc.teamMemberRepo.Delete(ctx, team.ID, userID)
+		case member != nil && member.Role != role:
+			member.Role = role
+			err = uc.teamMemberRepo.Update(ctx, member)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
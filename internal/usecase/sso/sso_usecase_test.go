@@ -0,0 +1,400 @@
+package sso_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/domain/repository"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/config"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/oidc"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/oidc/oidctest"
+	"github.com/podoru/spinner-podoru/internal/mocks"
+	"github.com/podoru/spinner-podoru/internal/usecase/auth"
+	"github.com/podoru/spinner-podoru/internal/usecase/sso"
+)
+
+// startProvider runs a local OpenID provider signing in as user
+func startProvider(t *testing.T, user oidctest.User) *oidctest.Server {
+	t.Helper()
+	server, err := oidctest.NewServer("podoru", "secret")
+	if err != nil {
+		t.Fatalf("failed to start provider: %v", err)
+	}
+	t.Cleanup(server.Close)
+	server.SetUser(user)
+	return server
+}
+
+func newUseCase(
+	server *oidctest.Server,
+	stateRepo repository.OIDCStateRepository,
+	userRepo repository.UserRepository,
+	teamRepo repository.TeamRepository,
+	teamMemberRepo repository.TeamMemberRepository,
+	mappings ...entity.OIDCGroupMapping,
+) *sso.UseCase {
+	provider := oidc.NewProvider(&config.OIDCConfig{
+		IssuerURL:    server.URL,
+		ClientID:     "podoru",
+		ClientSecret: "secret",
+		RedirectURL:  "http://podoru.test/api/v1/auth/oidc/callback",
+		Scopes:       []string{"openid", "email", "profile"},
+		GroupsClaim:  "groups",
+	})
+	authUseCase := auth.NewUseCase(userRepo, &mocks.MockRefreshTokenRepository{}, teamRepo, teamMemberRepo,
+		&config.JWTConfig{Secret: "test-secret", AccessExpiry: 15 * time.Minute, RefreshExpiry: time.Hour},
+		&config.AppConfig{PasswordLoginDisabled: true}, nil,
+	)
+	return sso.NewUseCase(provider, stateRepo, userRepo, teamRepo, teamMemberRepo, authUseCase, mappings)
+}
+
+// signIn runs the whole flow as the browser would
+func signIn(t *testing.T, server *oidctest.Server, uc *sso.UseCase) (*entity.User, *entity.TokenPair, error) {
+	t.Helper()
+	ctx := context.Background()
+	authURL, browserState, err := uc.LoginURL(ctx)
+	if err != nil {
+		t.Fatalf("LoginURL: %v", err)
+	}
+	code, state, err := server.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	return uc.Callback(ctx, code, state, browserState)
+}
+
+func TestCallback_ProvisionsUser(t *testing.T) {
+	server := startProvider(t, oidctest.User{Subject: "user-1", Email: "jane@example.com", EmailVerified: true, Name: "Jane"})
+
+	var attempt *entity.OIDCState
+	stateRepo := &mocks.MockOIDCStateRepository{
+		CreateFunc: func(ctx context.Context, state *entity.OIDCState) error {
+			attempt = state
+			return nil
+		},
+		ConsumeFunc: func(ctx context.Context, state string) (*entity.OIDCState, error) {
+			consumed := attempt
+			attempt = nil
+			return consumed, nil
+		},
+	}
+
+	var users []*entity.User
+	userRepo := &mocks.MockUserRepository{
+		CreateFunc: func(ctx context.Context, user *entity.User) error {
+			users = append(users, user)
+			return nil
+		},
+		GetByOIDCSubjectFunc: func(ctx context.Context, issuer, subject string) (*entity.User, error) {
+			for _, u := range users {
+				if u.OIDCIssuer != nil && *u.OIDCIssuer == issuer && *u.OIDCSubject == subject {
+					return u, nil
+				}
+			}
+			return nil, nil
+		},
+		CountFunc: func(ctx context.Context) (int64, error) {
+			return int64(len(users)), nil
+		},
+	}
+
+	uc := newUseCase(server, stateRepo, userRepo, &mocks.MockTeamRepository{}, &mocks.MockTeamMemberRepository{})
+
+	user, tokens, err := signIn(t, server, uc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tokens == nil || tokens.AccessToken == "" {
+		t.Fatal("expected tokens")
+	}
+	if user.Email != "jane@example.com" || user.Name != "Jane" {
+		t.Errorf("unexpected user %s %s", user.Email, user.Name)
+	}
+	if user.Role != entity.UserRoleSuperAdmin {
+		t.Errorf("expected the first user to be superadmin, got %s", user.Role)
+	}
+	if user.PasswordHash != "" {
+		t.Error("expected a provisioned user to have no password")
+	}
+	if user.OIDCSubject == nil || *user.OIDCSubject != "user-1" || *user.OIDCIssuer != server.URL {
+		t.Error("expected the user to be linked to their identity")
+	}
+
+	again, _, err := signIn(t, server, uc)
+	if err != nil {
+		t.Fatalf("unexpected error signing in again: %v", err)
+	}
+	if again.ID != user.ID || len(users) != 1 {
+		t.Error("expected the second sign-in to find the same user")
+	}
+}
+
+func TestCallback_LinksByEmail(t *testing.T) {
+	tests := []struct {
+		name          string
+		emailVerified bool
+		linked        bool
+		wantErr       error
+	}{
+		{name: "verified email", emailVerified: true},
+		{name: "unverified email", emailVerified: false, wantErr: sso.ErrEmailInUse},
+		{name: "linked to another identity", emailVerified: true, linked: true, wantErr: sso.ErrEmailInUse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startProvider(t, oidctest.User{Subject: "user-1", Email: "jane@example.com", EmailVerified: tt.emailVerified})
+
+			existing := &entity.User{ID: uuid.New(), Email: "jane@example.com", Role: entity.UserRoleUser, IsActive: true}
+			if tt.linked {
+				issuer, subject := server.URL, "someone-else"
+				existing.OIDCIssuer, existing.OIDCSubject = &issuer, &subject
+			}
+
+			var attempt *entity.OIDCState
+			stateRepo := &mocks.MockOIDCStateRepository{
+				CreateFunc: func(ctx context.Context, state *entity.OIDCState) error {
+					attempt = state
+					return nil
+				},
+				ConsumeFunc: func(ctx context.Context, state string) (*entity.OIDCState, error) {
+					return attempt, nil
+				},
+			}
+
+			linked := false
+			userRepo := &mocks.MockUserRepository{
+				GetByEmailFunc: func(ctx context.Context, email string) (*entity.User, error) {
+					return existing, nil
+				},
+				LinkOIDCSubjectFunc: func(ctx context.Context, id uuid.UUID, issuer, subject string) error {
+					linked = true
+					return nil
+				},
+				CreateFunc: func(ctx context.Context, user *entity.User) error {
+					t.Error("expected no user to be created")
+					return nil
+				},
+			}
+
+			uc := newUseCase(server, stateRepo, userRepo, &mocks.MockTeamRepository{}, &mocks.MockTeamMemberRepository{})
+
+			user, _, err := signIn(t, server, uc)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if linked != (tt.wantErr == nil) {
+				t.Errorf("expected linked %v, got %v", tt.wantErr == nil, linked)
+			}
+			if err == nil && user.ID != existing.ID {
+				t.Error("expected the existing user to be signed in")
+			}
+		})
+	}
+}
+
+func TestCallback_InactiveUser(t *testing.T) {
+	server := startProvider(t, oidctest.User{Subject: "user-1", Email: "jane@example.com", EmailVerified: true})
+
+	var attempt *entity.OIDCState
+	stateRepo := &mocks.MockOIDCStateRepository{
+		CreateFunc: func(ctx context.Context, state *entity.OIDCState) error {
+			attempt = state
+			return nil
+		},
+		ConsumeFunc: func(ctx context.Context, state string) (*entity.OIDCState, error) {
+			return attempt, nil
+		},
+	}
+
+	userRepo := &mocks.MockUserRepository{
+		GetByOIDCSubjectFunc: func(ctx context.Context, issuer, subject string) (*entity.User, error) {
+			return &entity.User{ID: uuid.New(), Email: "jane@example.com", OIDCIssuer: &issuer, OIDCSubject: &subject}, nil
+		},
+	}
+
+	uc := newUseCase(server, stateRepo, userRepo, &mocks.MockTeamRepository{}, &mocks.MockTeamMemberRepository{})
+
+	if _, _, err := signIn(t, server, uc); !errors.Is(err, sso.ErrUserInactive) {
+		t.Errorf("expected ErrUserInactive, got %v", err)
+	}
+}
+
+func TestCallback_InvalidState(t *testing.T) {
+	ctx := context.Background()
+	server := startProvider(t, oidctest.User{Subject: "user-1", Email: "jane@example.com", EmailVerified: true})
+
+	var attempt *entity.OIDCState
+	stateRepo := &mocks.MockOIDCStateRepository{
+		CreateFunc: func(ctx context.Context, state *entity.OIDCState) error {
+			attempt = state
+			return nil
+		},
+		ConsumeFunc: func(ctx context.Context, state string) (*entity.OIDCState, error) {
+			if attempt == nil || attempt.State != state {
+				return nil, nil
+			}
+			consumed := attempt
+			attempt = nil
+			return consumed, nil
+		},
+	}
+
+	uc := newUseCase(server, stateRepo, &mocks.MockUserRepository{}, &mocks.MockTeamRepository{}, &mocks.MockTeamMemberRepository{})
+
+	authURL, _, err := uc.LoginURL(ctx)
+	if err != nil {
+		t.Fatalf("LoginURL: %v", err)
+	}
+	code, state, err := server.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+
+	if _, _, err := uc.Callback(ctx, code, "forged", "forged"); !errors.Is(err, sso.ErrInvalidState) {
+		t.Errorf("expected ErrInvalidState for an unknown state, got %v", err)
+	}
+
+	// A callback opened in another browser, as a victim of login CSRF would
+	for _, browserState := range []string{"", "other"} {
+		if _, _, err := uc.Callback(ctx, code, state, browserState); !errors.Is(err, sso.ErrInvalidState) {
+			t.Errorf("expected ErrInvalidState for browser state %q, got %v", browserState, err)
+		}
+	}
+
+	attempt.ExpiresAt = time.Now().Add(-time.Second)
+	if _, _, err := uc.Callback(ctx, code, state, state); !errors.Is(err, sso.ErrInvalidState) {
+		t.Errorf("expected ErrInvalidState for an expired state, got %v", err)
+	}
+	if _, _, err := uc.Callback(ctx, code, state, state); !errors.Is(err, sso.ErrInvalidState) {
+		t.Errorf("expected a state to be usable once, got %v", err)
+	}
+}
+
+func TestCallback_RejectedToken(t *testing.T) {
+	server := startProvider(t, oidctest.User{Subject: "user-1", Email: "jane@example.com", EmailVerified: true})
+	server.SetClaims(map[string]any{"aud": "someone-else"})
+
+	var attempt *entity.OIDCState
+	stateRepo := &mocks.MockOIDCStateRepository{
+		CreateFunc: func(ctx context.Context, state *entity.OIDCState) error {
+			attempt = state
+			return nil
+		},
+		ConsumeFunc: func(ctx context.Context, state string) (*entity.OIDCState, error) {
+			return attempt, nil
+		},
+	}
+
+	userRepo := &mocks.MockUserRepository{
+		CreateFunc: func(ctx context.Context, user *entity.User) error {
+			t.Error("expected no user to be created")
+			return nil
+		},
+	}
+
+	uc := newUseCase(server, stateRepo, userRepo, &mocks.MockTeamRepository{}, &mocks.MockTeamMemberRepository{})
+
+	if _, _, err := signIn(t, server, uc); !errors.Is(err, sso.ErrSignInFailed) {
+		t.Errorf("expected ErrSignInFailed, got %v", err)
+	}
+}
+
+func TestCallback_SyncsTeams(t *testing.T) {
+	server := startProvider(t, oidctest.User{Subject: "user-1", Email: "jane@example.com", EmailVerified: true})
+	user := &entity.User{ID: uuid.New(), Email: "jane@example.com", IsActive: true}
+	platform := &entity.Team{ID: uuid.New(), Slug: "platform"}
+	web := &entity.Team{ID: uuid.New(), Slug: "web"}
+
+	var attempt *entity.OIDCState
+	stateRepo := &mocks.MockOIDCStateRepository{
+		CreateFunc: func(ctx context.Context, state *entity.OIDCState) error {
+			attempt = state
+			return nil
+		},
+		ConsumeFunc: func(ctx context.Context, state string) (*entity.OIDCState, error) {
+			return attempt, nil
+		},
+	}
+
+	userRepo := &mocks.MockUserRepository{
+		GetByOIDCSubjectFunc: func(ctx context.Context, issuer, subject string) (*entity.User, error) {
+			return user, nil
+		},
+	}
+
+	teamRepo := &mocks.MockTeamRepository{
+		GetBySlugFunc: func(ctx context.Context, slug string) (*entity.Team, error) {
+			for _, team := range []*entity.Team{platform, web} {
+				if team.Slug == slug {
+					return team, nil
+				}
+			}
+			return nil, nil
+		},
+	}
+
+	members := make(map[uuid.UUID]*entity.TeamMember)
+	teamMemberRepo := &mocks.MockTeamMemberRepository{
+		CreateFunc: func(ctx context.Context, member *entity.TeamMember) error {
+			members[member.TeamID] = member
+			return nil
+		},
+		GetByTeamAndUserFunc: func(ctx context.Context, teamID, userID uuid.UUID) (*entity.TeamMember, error) {
+			return members[teamID], nil
+		},
+		UpdateFunc: func(ctx context.Context, member *entity.TeamMember) error {
+			members[member.TeamID] = member
+			return nil
+		},
+		DeleteFunc: func(ctx context.Context, teamID, userID uuid.UUID) error {
+			delete(members, teamID)
+			return nil
+		},
+	}
+	role := func(team *entity.Team) entity.TeamRole {
+		if m := members[team.ID]; m != nil {
+			return m.Role
+		}
+		return ""
+	}
+
+	uc := newUseCase(server, stateRepo, userRepo, teamRepo, teamMemberRepo,
+		entity.OIDCGroupMapping{Group: "platform-admins", TeamSlug: "platform", Role: entity.TeamRoleAdmin},
+		entity.OIDCGroupMapping{Group: "engineers", TeamSlug: "platform", Role: entity.TeamRoleMember},
+		entity.OIDCGroupMapping{Group: "engineers", TeamSlug: "web", Role: entity.TeamRoleMember},
+		entity.OIDCGroupMapping{Group: "engineers", TeamSlug: "missing", Role: entity.TeamRoleMember},
+	)
+
+	server.SetUser(oidctest.User{Subject: "user-1", Email: "jane@example.com", EmailVerified: true, Groups: []string{"engineers", "platform-admins"}})
+	if _, _, err := signIn(t, server, uc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := role(platform); got != entity.TeamRoleAdmin {
+		t.Errorf("expected the highest mapped role on platform, got %q", got)
+	}
+	if got := role(web); got != entity.TeamRoleMember {
+		t.Errorf("expected member on web, got %q", got)
+	}
+
+	server.SetUser(oidctest.User{Subject: "user-1", Email: "jane@example.com", EmailVerified: true, Groups: []string{"platform-admins"}})
+	if _, _, err := signIn(t, server, uc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := role(web); got != "" {
+		t.Errorf("expected membership to be removed after leaving the group, got %q", got)
+	}
+
+	members[web.ID] = &entity.TeamMember{ID: uuid.New(), TeamID: web.ID, UserID: user.ID, Role: entity.TeamRoleOwner}
+	if _, _, err := signIn(t, server, uc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := role(web); got != entity.TeamRoleOwner {
+		t.Errorf("expected an owner to stay owner, got %q", got)
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/podoru/spinner-podoru/internal/domain/entity"
 	"github.com/podoru/spinner-podoru/internal/domain/repository"
+	"github.com/podoru/spinner-podoru/internal/usecase/apitoken"
 )
 
 var (
@@ -20,6 +21,8 @@ var (
 	ErrCannotRemoveOwner = errors.New("cannot remove team owner")
 	ErrUserNotFound      = errors.New("user not found")
 	ErrAlreadyMember     = errors.New("user is already a team member")
+
+	ErrProjectLimitedToken = errors.New("token is limited to a project")
 )
 
 type UseCase struct {
@@ -41,6 +44,10 @@ func NewUseCase(
 }
 
 func (uc *UseCase) Create(ctx context.Context, userID uuid.UUID, input *entity.TeamCreate) (*entity.Team, error) {
+	if apitoken.ProjectLimited(ctx) {
+		return nil, ErrProjectLimitedToken
+	}
+
 	exists, err := uc.teamRepo.ExistsBySlug(ctx, input.Slug)
 	if err != nil {
 		return nil, err
@@ -104,6 +111,10 @@ func (uc *UseCase) List(ctx context.Context, userID uuid.UUID) ([]entity.TeamWit
 }
 
 func (uc *UseCase) Update(ctx context.Context, userID, teamID uuid.UUID, input *entity.TeamUpdate) (*entity.Team, error) {
+	if apitoken.ProjectLimited(ctx) {
+		return nil, ErrProjectLimitedToken
+	}
+
 	member, err := uc.teamMemberRepo.GetByTeamAndUser(ctx, teamID, userID)
 	if err != nil {
 		return nil, err
@@ -139,6 +150,10 @@ func (uc *UseCase) Update(ctx context.Context, userID, teamID uuid.UUID, input *
 }
 
 func (uc *UseCase) Delete(ctx context.Context, userID, teamID uuid.UUID) error {
+	if apitoken.ProjectLimited(ctx) {
+		return ErrProjectLimitedToken
+	}
+
 	member, err := uc.teamMemberRepo.GetByTeamAndUser(ctx, teamID, userID)
 	if err != nil {
 		return err
@@ -166,6 +181,10 @@ func (uc *UseCase) ListMembers(ctx context.Context, userID, teamID uuid.UUID) ([
 }
 
 func (uc *UseCase) AddMember(ctx context.Context, userID, teamID uuid.UUID, input *entity.TeamMemberCreate) (*entity.TeamMember, error) {
+	if apitoken.ProjectLimited(ctx) {
+		return nil, ErrProjectLimitedToken
+	}
+
 	member, err := uc.teamMemberRepo.GetByTeamAndUser(ctx, teamID, userID)
 	if err != nil {
 		return nil, err
@@ -210,6 +229,10 @@ func (uc *UseCase) AddMember(ctx context.Context, userID, teamID uuid.UUID, inpu
 }
 
 func (uc *UseCase) UpdateMember(ctx context.Context, userID, teamID, targetUserID uuid.UUID, input *entity.TeamMemberUpdate) (*entity.TeamMember, error) {
+	if apitoken.ProjectLimited(ctx) {
+		return nil, ErrProjectLimitedToken
+	}
+
 	member, err := uc.teamMemberRepo.GetByTeamAndUser(ctx, teamID, userID)
 	if err != nil {
 		return nil, err
@@ -243,6 +266,10 @@ func (uc *UseCase) UpdateMember(ctx context.Context, userID, teamID, targetUserI
 }
 
 func (uc *UseCase) RemoveMember(ctx context.Context, userID, teamID, targetUserID uuid.UUID) error {
+	if apitoken.ProjectLimited(ctx) {
+		return ErrProjectLimitedToken
+	}
+
 	member, err := uc.teamMemberRepo.GetByTeamAndUser(ctx, teamID, userID)
 	if err != nil {
 		return err
@@ -9,6 +9,7 @@ import (
 
 	"github.com/podoru/spinner-podoru/internal/domain/entity"
 	"github.com/podoru/spinner-podoru/internal/mocks"
+	"github.com/podoru/spinner-podoru/internal/usecase/apitoken"
 	"github.com/podoru/spinner-podoru/internal/usecase/team"
 )
 
@@ -315,6 +316,74 @@ func TestDelete_NotOwner(t *testing.T) {
 	}
 }
 
+func TestDelete_ProjectLimitedToken(t *testing.T) {
+	userID := uuid.New()
+	teamID := uuid.New()
+	projectID := uuid.New()
+	ctx := apitoken.WithToken(context.Background(), &entity.APIToken{ID: uuid.New(), TeamID: &teamID, ProjectID: &projectID})
+
+	teamRepo := &mocks.MockTeamRepository{
+		DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
+			t.Error("expected team not to be deleted")
+			return nil
+		},
+	}
+
+	teamMemberRepo := apitoken.RestrictTeamMembers(&mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, tmID, uID uuid.UUID) (*entity.TeamMember, error) {
+			return &entity.TeamMember{
+				TeamID: tmID,
+				UserID: uID,
+				Role:   entity.TeamRoleOwner,
+			}, nil
+		},
+	})
+
+	userRepo := &mocks.MockUserRepository{}
+
+	uc := team.NewUseCase(teamRepo, teamMemberRepo, userRepo)
+
+	err := uc.Delete(ctx, userID, teamID)
+	if err != team.ErrProjectLimitedToken {
+		t.Errorf("expected ErrProjectLimitedToken, got %v", err)
+	}
+}
+
+func TestAddMember_ProjectLimitedToken(t *testing.T) {
+	userID := uuid.New()
+	teamID := uuid.New()
+	projectID := uuid.New()
+	ctx := apitoken.WithToken(context.Background(), &entity.APIToken{ID: uuid.New(), TeamID: &teamID, ProjectID: &projectID})
+
+	teamRepo := &mocks.MockTeamRepository{}
+
+	teamMemberRepo := apitoken.RestrictTeamMembers(&mocks.MockTeamMemberRepository{
+		GetByTeamAndUserFunc: func(ctx context.Context, tmID, uID uuid.UUID) (*entity.TeamMember, error) {
+			if uID != userID {
+				return nil, nil
+			}
+			return &entity.TeamMember{TeamID: tmID, UserID: uID, Role: entity.TeamRoleOwner}, nil
+		},
+		CreateFunc: func(ctx context.Context, member *entity.TeamMember) error {
+			t.Error("expected no member to be added")
+			return nil
+		},
+	})
+
+	userRepo := &mocks.MockUserRepository{
+		GetByEmailFunc: func(ctx context.Context, email string) (*entity.User, error) {
+			return &entity.User{ID: uuid.New(), Email: email}, nil
+		},
+	}
+
+	uc := team.NewUseCase(teamRepo, teamMemberRepo, userRepo)
+
+	_, err := uc.AddMember(ctx, userID, teamID, &entity.TeamMemberCreate{Email: "new@example.com", Role: entity.TeamRoleAdmin})
+	if err != team.ErrProjectLimitedToken {
+		t.Errorf("expected ErrProjectLimitedToken, got %v", err)
+	}
+}
+
 func TestAddMember_Success(t *testing.T) {
 	ctx := context.Background()
 	ownerID := uuid.New()
@@ -0,0 +1,164 @@
+package traefik
+
+// DynamicConfig is the Traefik dynamic configuration Podoru generates, in the
+// shape the HTTP and file providers read
+type DynamicConfig struct {
+	HTTP *HTTPConfig `json:"http" yaml:"http"`
+	TCP  *TCPConfig  `json:"tcp,omitempty" yaml:"tcp,omitempty"`
+	UDP  *UDPConfig  `json:"udp,omitempty" yaml:"udp,omitempty"`
+	TLS  *TLSConfig  `json:"tls,omitempty" yaml:"tls,omitempty"`
+}
+
+type HTTPConfig struct {
+	Routers     map[string]*Router     `json:"routers,omitempty" yaml:"routers,omitempty"`
+	Services    map[string]*Service    `json:"services,omitempty" yaml:"services,omitempty"`
+	Middlewares map[string]*Middleware `json:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+}
+
+type Router struct {
+	Rule        string     `json:"rule" yaml:"rule"`
+	EntryPoints []string   `json:"entryPoints" yaml:"entryPoints"`
+	Service     string     `json:"service" yaml:"service"`
+	Middlewares []string   `json:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+	TLS         *RouterTLS `json:"tls,omitempty" yaml:"tls,omitempty"`
+}
+
+// TLSConfig adds uploaded certificates to Traefik's TLS stores, where routers
+// without a cert resolver find them by SNI
+type TLSConfig struct {
+	Certificates []TLSCertificate `json:"certificates" yaml:"certificates"`
+}
+
+// TLSCertificate carries the PEM content itself; Traefik accepts it in place
+// of file paths
+type TLSCertificate struct {
+	CertFile string   `json:"certFile" yaml:"certFile"`
+	KeyFile  string   `json:"keyFile" yaml:"keyFile"`
+	Stores   []string `json:"stores" yaml:"stores"`
+}
+
+type RouterTLS struct {
+	CertResolver string      `json:"certResolver,omitempty" yaml:"certResolver,omitempty"`
+	Domains      []TLSDomain `json:"domains,omitempty" yaml:"domains,omitempty"`
+}
+
+// TLSDomain names the certificate a resolver requests, e.g. a wildcard
+type TLSDomain struct {
+	Main string   `json:"main" yaml:"main"`
+	SANs []string `json:"sans,omitempty" yaml:"sans,omitempty"`
+}
+
+type Service struct {
+	LoadBalancer *LoadBalancer `json:"loadBalancer" yaml:"loadBalancer"`
+}
+
+type LoadBalancer struct {
+	Servers []Server `json:"servers" yaml:"servers"`
+}
+
+type Server struct {
+	URL string `json:"url" yaml:"url"`
+}
+
+// Middleware holds exactly one of the middleware kinds Podoru uses
+type Middleware struct {
+	IPAllowList    *IPAllowList    `json:"ipAllowList,omitempty" yaml:"ipAllowList,omitempty"`
+	RateLimit      *RateLimit      `json:"rateLimit,omitempty" yaml:"rateLimit,omitempty"`
+	BasicAuth      *BasicAuth      `json:"basicAuth,omitempty" yaml:"basicAuth,omitempty"`
+	Headers        *Headers        `json:"headers,omitempty" yaml:"headers,omitempty"`
+	StripPrefix    *StripPrefix    `json:"stripPrefix,omitempty" yaml:"stripPrefix,omitempty"`
+	RedirectScheme *RedirectScheme `json:"redirectScheme,omitempty" yaml:"redirectScheme,omitempty"`
+	RedirectRegex  *RedirectRegex  `json:"redirectRegex,omitempty" yaml:"redirectRegex,omitempty"`
+}
+
+type IPAllowList struct {
+	SourceRange []string `json:"sourceRange" yaml:"sourceRange"`
+}
+
+type RateLimit struct {
+	Average int `json:"average" yaml:"average"`
+	Burst   int `json:"burst,omitempty" yaml:"burst,omitempty"`
+}
+
+type BasicAuth struct {
+	Users []string `json:"users" yaml:"users"`
+}
+
+type Headers struct {
+	CustomResponseHeaders map[string]string `json:"customResponseHeaders" yaml:"customResponseHeaders"`
+}
+
+type StripPrefix struct {
+	Prefixes []string `json:"prefixes" yaml:"prefixes"`
+}
+
+type RedirectScheme struct {
+	Scheme    string `json:"scheme" yaml:"scheme"`
+	Permanent bool   `json:"permanent" yaml:"permanent"`
+}
+
+type RedirectRegex struct {
+	Regex       string `json:"regex" yaml:"regex"`
+	Replacement string `json:"replacement" yaml:"replacement"`
+	Permanent   bool   `json:"permanent" yaml:"permanent"`
+}
+
+type TCPConfig struct {
+	Routers     map[string]*TCPRouter     `json:"routers,omitempty" yaml:"routers,omitempty"`
+	Services    map[string]*TCPService    `json:"services,omitempty" yaml:"services,omitempty"`
+	Middlewares map[string]*TCPMiddleware `json:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+}
+
+type TCPRouter struct {
+	Rule        string        `json:"rule" yaml:"rule"`
+	EntryPoints []string      `json:"entryPoints" yaml:"entryPoints"`
+	Service     string        `json:"service" yaml:"service"`
+	Middlewares []string      `json:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+	TLS         *TCPRouterTLS `json:"tls,omitempty" yaml:"tls,omitempty"`
+}
+
+// TCPRouterTLS either terminates TLS in Traefik or, with Passthrough, hands
+// the encrypted stream to the container after reading the SNI
+type TCPRouterTLS struct {
+	Passthrough  bool   `json:"passthrough,omitempty" yaml:"passthrough,omitempty"`
+	CertResolver string `json:"certResolver,omitempty" yaml:"certResolver,omitempty"`
+}
+
+type TCPService struct {
+	LoadBalancer *TCPLoadBalancer `json:"loadBalancer" yaml:"loadBalancer"`
+}
+
+type TCPLoadBalancer struct {
+	Servers []TCPServer `json:"servers" yaml:"servers"`
+}
+
+type TCPServer struct {
+	Address string `json:"address" yaml:"address"`
+}
+
+type TCPMiddleware struct {
+	IPAllowList *IPAllowList `json:"ipAllowList,omitempty" yaml:"ipAllowList,omitempty"`
+}
+
+// UDPConfig routes whole UDP entrypoints, UDP has no host to route on
+type UDPConfig struct {
+	Routers  map[string]*UDPRouter  `json:"routers,omitempty" yaml:"routers,omitempty"`
+	Services map[string]*UDPService `json:"services,omitempty" yaml:"services,omitempty"`
+}
+
+type UDPRouter struct {
+	EntryPoints []string `json:"entryPoints" yaml:"entryPoints"`
+	Service     string   `json:"service" yaml:"service"`
+}
+
+type UDPService struct {
+	LoadBalancer *UDPLoadBalancer `json:"loadBalancer" yaml:"loadBalancer"`
+}
+
+type UDPLoadBalancer struct {
+	Servers []UDPServer `json:"servers" yaml:"servers"`
+}
+
+type UDPServer struct {
+	Address string `json:"address" yaml:"address"`
+}
@@ -0,0 +1,108 @@
+package traefik
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/podoru/spinner-podoru/internal/infrastructure/config"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/logger"
+)
+
+// FileWriter keeps a dynamic configuration file for Traefik's file provider
+// up to date. It rewrites the file whenever routing changes and on every
+// tick, so container port changes are picked up too.
+type FileWriter struct {
+	traefikUseCase *UseCase
+	path           string
+	syncInterval   time.Duration
+	log            *logger.Logger
+	last           []byte
+}
+
+// NewFileWriter creates a new file writer
+func NewFileWriter(traefikUseCase *UseCase, cfg *config.TraefikConfig, log *logger.Logger) *FileWriter {
+	return &FileWriter{
+		traefikUseCase: traefikUseCase,
+		path:           cfg.ConfigFile,
+		syncInterval:   cfg.FileSyncInterval,
+		log:            log,
+	}
+}
+
+// Run writes the file once, then blocks until ctx is cancelled, rewriting it
+// on every change and tick
+func (w *FileWriter) Run(ctx context.Context) {
+	w.sync(ctx)
+
+	ticker := time.NewTicker(w.syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.traefikUseCase.changes:
+			w.sync(ctx)
+		case <-ticker.C:
+			w.sync(ctx)
+		}
+	}
+}
+
+func (w *FileWriter) sync(ctx context.Context) {
+	cfg, err := w.traefikUseCase.Config(ctx)
+	if err != nil {
+		w.log.Errorw("Failed to build Traefik configuration", "error", err)
+		return
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		w.log.Errorw("Failed to encode Traefik configuration", "error", err)
+		return
+	}
+
+	// Traefik reloads on every write, so skip unchanged configurations
+	if bytes.Equal(data, w.last) {
+		return
+	}
+
+	if err := writeFileAtomic(w.path, data); err != nil {
+		w.log.Errorw("Failed to write Traefik configuration", "path", w.path, "error", err)
+		return
+	}
+	w.last = data
+}
+
+// writeFileAtomic replaces the file in one rename so Traefik never reads a
+// partly written configuration
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".podoru-*.yml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,331 @@
+package traefik
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	domainDocker "github.com/podoru/spinner-podoru/internal/domain/docker"
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/domain/repository"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/config"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/logger"
+)
+
+const (
+	managedLabel    = "podoru.managed"
+	configHashLabel = "podoru.traefik.config-hash"
+
+	acmeVolume      = "podoru_traefik_acme"
+	dynamicDir      = "/etc/traefik/dynamic"
+	dashboardPort   = 8080
+	dashboardHostIP = "127.0.0.1"
+	containerState  = "running"
+)
+
+var ErrNotSuperAdmin = errors.New("requires superadmin role")
+
+// ProxyStatus reports the Traefik network and container Podoru routes through
+type ProxyStatus struct {
+	Managed       bool   `json:"managed"`
+	Network       string `json:"network"`
+	NetworkReady  bool   `json:"network_ready"`
+	ContainerName string `json:"container_name"`
+	ContainerID   string `json:"container_id,omitempty"`
+	Image         string `json:"image,omitempty"`
+	// State is the Docker container state, or "missing"
+	State   string `json:"state"`
+	Running bool   `json:"running"`
+	// Adopted is set for a container Podoru did not create, which it starts
+	// but never reconfigures
+	Adopted       bool       `json:"adopted"`
+	HTTPPort      int        `json:"http_port"`
+	HTTPSPort     int        `json:"https_port"`
+	DashboardPort int        `json:"dashboard_port,omitempty"`
+	LastEnsuredAt *time.Time `json:"last_ensured_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+}
+
+// Manager keeps the Traefik network and container in place, so services can
+// be routed without the operator starting Traefik by hand
+type Manager struct {
+	containerManager domainDocker.ContainerManager
+	userRepo         repository.UserRepository
+	traefikConfig    *config.TraefikConfig
+	log              *logger.Logger
+
+	mu            sync.Mutex
+	lastEnsuredAt *time.Time
+	lastErr       error
+}
+
+// NewManager creates a new Traefik manager
+func NewManager(
+	containerManager domainDocker.ContainerManager,
+	userRepo repository.UserRepository,
+	traefikConfig *config.TraefikConfig,
+	log *logger.Logger,
+) *Manager {
+	return &Manager{
+		containerManager: containerManager,
+		userRepo:         userRepo,
+		traefikConfig:    traefikConfig,
+		log:              log,
+	}
+}
+
+// Ensure creates the Traefik network and container when they are missing and
+// starts the container when it is stopped. A container Podoru created with
+// other settings is replaced; one the operator created is left as it is.
+func (m *Manager) Ensure(ctx context.Context) error {
+	err := m.ensure(ctx)
+
+	now := time.Now()
+	m.mu.Lock()
+	m.lastEnsuredAt = &now
+	m.lastErr = err
+	m.mu.Unlock()
+
+	return err
+}
+
+func (m *Manager) ensure(ctx context.Context) error {
+	cfg := m.traefikConfig
+
+	if err := m.containerManager.ValidateNetwork(ctx, cfg.Network); err != nil {
+		_, err := m.containerManager.CreateNetwork(ctx, &domainDocker.NetworkConfig{
+			Name:   cfg.Network,
+			Driver: string(entity.NetworkDriverBridge),
+			Labels: map[string]string{managedLabel: "true"},
+		})
+		if err != nil {
+			return err
+		}
+		m.log.Infow("Created Traefik network", "network", cfg.Network)
+	}
+
+	for _, ep := range append(parseEntryPoints(cfg.TCPEntryPoints), parseEntryPoints(cfg.UDPEntryPoints)...) {
+		if ep.port == 0 {
+			m.log.Warnw("Entrypoint has no port, the managed Traefik container leaves it out", "entrypoint", ep.name)
+		}
+	}
+
+	containerConfig := m.containerConfig()
+
+	info, err := m.containerManager.InspectContainer(ctx, cfg.ContainerName)
+	if errors.Is(err, domainDocker.ErrContainerNotFound) {
+		return m.create(ctx, containerConfig)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to inspect container %s: %w", cfg.ContainerName, err)
+	}
+
+	if info.Labels[managedLabel] == "true" && info.Labels[configHashLabel] != containerConfig.Labels[configHashLabel] {
+		m.log.Infow("Traefik settings changed, recreating container", "container", cfg.ContainerName)
+		if err := m.containerManager.RemoveContainer(ctx, info.ID, true); err != nil {
+			return fmt.Errorf("failed to remove container %s: %w", cfg.ContainerName, err)
+		}
+		return m.create(ctx, containerConfig)
+	}
+
+	if info.State != containerState {
+		if err := m.containerManager.StartContainer(ctx, info.ID); err != nil {
+			return fmt.Errorf("failed to start container %s: %w", cfg.ContainerName, err)
+		}
+		m.log.Infow("Started Traefik container", "container", cfg.ContainerName)
+	}
+	return nil
+}
+
+func (m *Manager) create(ctx context.Context, containerConfig *domainDocker.ContainerConfig) error {
+	if err := m.containerManager.PullImage(ctx, containerConfig.Image); err != nil {
+		return err
+	}
+
+	containerID, err := m.containerManager.CreateContainer(ctx, containerConfig)
+	if err != nil {
+		return err
+	}
+	if err := m.containerManager.StartContainer(ctx, containerID); err != nil {
+		return fmt.Errorf("failed to start container %s: %w", containerConfig.Name, err)
+	}
+
+	m.log.Infow("Created Traefik container", "container", containerConfig.Name, "image", containerConfig.Image)
+	return nil
+}
+
+// containerConfig builds the Traefik container from the configured ports,
+// ACME settings and providers. The settings are hashed into a label so a
+// change is noticed on the next start.
+func (m *Manager) containerConfig() *domainDocker.ContainerConfig {
+	cfg := m.traefikConfig
+
+	cmd := []string{
+		"--entrypoints.web.address=:80",
+		"--entrypoints.websecure.address=:443",
+		"--log.level=INFO",
+	}
+	var portMappings []entity.PortMapping
+	publish := func(containerPort, hostPort int, protocol, hostIP string) {
+		if hostPort > 0 {
+			portMappings = append(portMappings, entity.PortMapping{
+				ContainerPort: containerPort,
+				HostPort:      &hostPort,
+				Protocol:      protocol,
+				HostIP:        hostIP,
+			})
+		}
+	}
+	publish(80, cfg.HTTPPort, "tcp", "")
+	publish(443, cfg.HTTPSPort, "tcp", "")
+
+	// tcp and udp entrypoints listen on the same port inside and outside
+	for _, ep := range parseEntryPoints(cfg.TCPEntryPoints) {
+		if ep.port > 0 {
+			cmd = append(cmd, fmt.Sprintf("--entrypoints.%s.address=:%d", ep.name, ep.port))
+			publish(ep.port, ep.port, "tcp", "")
+		}
+	}
+	for _, ep := range parseEntryPoints(cfg.UDPEntryPoints) {
+		if ep.port > 0 {
+			cmd = append(cmd, fmt.Sprintf("--entrypoints.%s.address=:%d/udp", ep.name, ep.port))
+			publish(ep.port, ep.port, "udp", "")
+		}
+	}
+
+	// The dashboard has no authentication, so it is only published on the
+	// host's loopback address; reach it through an SSH tunnel
+	if cfg.DashboardPort > 0 {
+		cmd = append(cmd,
+			"--api.dashboard=true",
+			"--api.insecure=true",
+			fmt.Sprintf("--entrypoints.traefik.address=:%d", dashboardPort),
+		)
+		publish(dashboardPort, cfg.DashboardPort, "tcp", dashboardHostIP)
+	}
+
+	if cfg.ACMEEmail != "" {
+		cmd = append(cmd,
+			"--certificatesresolvers.letsencrypt.acme.email="+cfg.ACMEEmail,
+			"--certificatesresolvers.letsencrypt.acme.storage=/etc/traefik/acme/acme.json",
+			"--certificatesresolvers.letsencrypt.acme.httpchallenge=true",
+			"--certificatesresolvers.letsencrypt.acme.httpchallenge.entrypoint=web",
+		)
+		if cfg.DNSProvider != "" {
+			resolver := "--certificatesresolvers." + cfg.BaseDomainCertResolver + ".acme."
+			cmd = append(cmd,
+				resolver+"email="+cfg.ACMEEmail,
+				resolver+"storage=/etc/traefik/acme/acme-dns.json",
+				resolver+"dnschallenge.provider="+cfg.DNSProvider,
+			)
+		}
+	}
+
+	volumes := []entity.Volume{{Name: acmeVolume, MountPath: "/etc/traefik/acme"}}
+
+	if cfg.ProviderToken != "" {
+		cmd = append(cmd,
+			"--providers.http.endpoint="+cfg.ProviderURL,
+			"--providers.http.pollInterval=5s",
+			"--providers.http.headers.Authorization=Bearer "+cfg.ProviderToken,
+		)
+	}
+	if cfg.ConfigFile != "" {
+		hostDir := filepath.Dir(cfg.ConfigFile)
+		volumes = append(volumes, entity.Volume{Name: "dynamic", MountPath: dynamicDir, HostPath: &hostDir})
+		cmd = append(cmd,
+			"--providers.file.directory="+dynamicDir,
+			"--providers.file.watch=true",
+		)
+	}
+
+	containerConfig := &domainDocker.ContainerConfig{
+		Name:          cfg.ContainerName,
+		Image:         cfg.Image,
+		Cmd:           cmd,
+		Env:           cfg.ContainerEnv,
+		PortMappings:  portMappings,
+		Volumes:       volumes,
+		RestartPolicy: entity.RestartPolicyUnlessStopped,
+		NetworkID:     cfg.Network,
+		// Lets the HTTP provider reach Podoru running on the host
+		ExtraHosts: []string{"host.docker.internal:host-gateway"},
+	}
+	containerConfig.Labels = map[string]string{
+		managedLabel:    "true",
+		configHashLabel: hashContainerConfig(containerConfig),
+	}
+	return containerConfig
+}
+
+func hashContainerConfig(c *domainDocker.ContainerConfig) string {
+	h := sha256.New()
+	fmt.Fprintln(h, c.Image, c.NetworkID)
+	fmt.Fprintln(h, strings.Join(c.Cmd, "\n"))
+	fmt.Fprintln(h, strings.Join(c.Env, "\n"))
+	for _, pm := range c.PortMappings {
+		fmt.Fprintln(h, pm.ContainerPort, *pm.HostPort, pm.Protocol, pm.HostIP)
+	}
+	for _, v := range c.Volumes {
+		fmt.Fprintln(h, v.Name, v.MountPath)
+		if v.HostPath != nil {
+			fmt.Fprintln(h, *v.HostPath)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Status reports the Traefik network and container as Docker sees them now,
+// along with the outcome of the last Ensure. Only superadmins may read it.
+func (m *Manager) Status(ctx context.Context, userID uuid.UUID) (*ProxyStatus, error) {
+	user, err := m.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.IsSuperAdmin() {
+		return nil, ErrNotSuperAdmin
+	}
+
+	cfg := m.traefikConfig
+	status := &ProxyStatus{
+		Managed:       cfg.Managed,
+		Network:       cfg.Network,
+		ContainerName: cfg.ContainerName,
+		State:         "missing",
+		HTTPPort:      cfg.HTTPPort,
+		HTTPSPort:     cfg.HTTPSPort,
+		DashboardPort: cfg.DashboardPort,
+	}
+
+	m.mu.Lock()
+	status.LastEnsuredAt = m.lastEnsuredAt
+	if m.lastErr != nil {
+		status.LastError = m.lastErr.Error()
+	}
+	m.mu.Unlock()
+
+	status.NetworkReady = m.containerManager.ValidateNetwork(ctx, cfg.Network) == nil
+
+	info, err := m.containerManager.InspectContainer(ctx, cfg.ContainerName)
+	if errors.Is(err, domainDocker.ErrContainerNotFound) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container %s: %w", cfg.ContainerName, err)
+	}
+
+	status.ContainerID = info.ID
+	status.Image = info.Image
+	status.State = info.State
+	status.Running = info.State == containerState
+	status.Adopted = info.Labels[managedLabel] != "true"
+	return status, nil
+}
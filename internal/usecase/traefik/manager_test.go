@@ -0,0 +1,246 @@
+package traefik_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	domainDocker "github.com/podoru/spinner-podoru/internal/domain/docker"
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/config"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/logger"
+	"github.com/podoru/spinner-podoru/internal/mocks"
+	"github.com/podoru/spinner-podoru/internal/usecase/traefik"
+)
+
+func managedConfig() *config.TraefikConfig {
+	return &config.TraefikConfig{
+		Enabled:       true,
+		Managed:       true,
+		HTTPPort:      8000,
+		HTTPSPort:     8443,
+		DashboardPort: 8081,
+		ACMEEmail:     "ops@example.com",
+		Network:       "podoru_traefik",
+		ProviderToken: "secret",
+		ProviderURL:   "http://host.docker.internal:8080/api/v1/traefik/config",
+		Image:         "traefik:v3.2",
+		ContainerName: "podoru_traefik",
+	}
+}
+
+func newManager(containerManager *mocks.MockContainerManager, traefikConfig *config.TraefikConfig) *traefik.Manager {
+	users := &mocks.MockUserRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+			return &entity.User{ID: id, Role: entity.UserRoleSuperAdmin}, nil
+		},
+	}
+	return traefik.NewManager(containerManager, users, traefikConfig, &logger.Logger{SugaredLogger: zap.NewNop().Sugar()})
+}
+
+func TestManager_EnsureCreatesNetworkAndContainer(t *testing.T) {
+	var network *domainDocker.NetworkConfig
+	var created *domainDocker.ContainerConfig
+	var started []string
+
+	containerManager := &mocks.MockContainerManager{
+		ValidateNetworkFunc: func(ctx context.Context, networkName string) error {
+			return errors.New("network not found")
+		},
+		CreateNetworkFunc: func(ctx context.Context, cfg *domainDocker.NetworkConfig) (string, error) {
+			network = cfg
+			return "net-1", nil
+		},
+		CreateContainerFunc: func(ctx context.Context, cfg *domainDocker.ContainerConfig) (string, error) {
+			created = cfg
+			return "traefik-1", nil
+		},
+		StartContainerFunc: func(ctx context.Context, containerID string) error {
+			started = append(started, containerID)
+			return nil
+		},
+	}
+
+	if err := newManager(containerManager, managedConfig()).Ensure(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if network == nil || network.Name != "podoru_traefik" {
+		t.Fatalf("network = %+v, want podoru_traefik", network)
+	}
+	if created == nil {
+		t.Fatal("container was not created")
+	}
+	if created.Name != "podoru_traefik" || created.Image != "traefik:v3.2" || created.NetworkID != "podoru_traefik" {
+		t.Errorf("container = %s %s on %s", created.Name, created.Image, created.NetworkID)
+	}
+	if !slices.Equal(started, []string{"traefik-1"}) {
+		t.Errorf("started = %v, want [traefik-1]", started)
+	}
+
+	ports := make(map[int]int)
+	for _, pm := range created.PortMappings {
+		ports[pm.ContainerPort] = *pm.HostPort
+	}
+	for containerPort, hostPort := range map[int]int{80: 8000, 443: 8443, 8080: 8081} {
+		if ports[containerPort] != hostPort {
+			t.Errorf("container port %d published on %d, want %d", containerPort, ports[containerPort], hostPort)
+		}
+	}
+
+	for _, arg := range []string{
+		"--certificatesresolvers.letsencrypt.acme.email=ops@example.com",
+		"--providers.http.endpoint=http://host.docker.internal:8080/api/v1/traefik/config",
+		"--providers.http.headers.Authorization=Bearer secret",
+	} {
+		if !slices.Contains(created.Cmd, arg) {
+			t.Errorf("command is missing %q", arg)
+		}
+	}
+}
+
+func TestManager_EntryPointsAndDashboard(t *testing.T) {
+	var created *domainDocker.ContainerConfig
+	containerManager := &mocks.MockContainerManager{
+		InspectContainerFunc: func(ctx context.Context, containerID string) (*domainDocker.ContainerInfo, error) {
+			return nil, domainDocker.ErrContainerNotFound
+		},
+		CreateContainerFunc: func(ctx context.Context, cfg *domainDocker.ContainerConfig) (string, error) {
+			created = cfg
+			return "traefik-1", nil
+		},
+	}
+
+	traefikConfig := managedConfig()
+	traefikConfig.TCPEntryPoints = []string{"postgres:5432", "redis"}
+	traefikConfig.UDPEntryPoints = []string{"game:27015"}
+
+	if err := newManager(containerManager, traefikConfig).Ensure(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, arg := range []string{
+		"--entrypoints.postgres.address=:5432",
+		"--entrypoints.game.address=:27015/udp",
+	} {
+		if !slices.Contains(created.Cmd, arg) {
+			t.Errorf("command is missing %q", arg)
+		}
+	}
+	for _, arg := range created.Cmd {
+		if strings.HasPrefix(arg, "--entrypoints.redis.") {
+			t.Errorf("entrypoint without a port was defined: %q", arg)
+		}
+	}
+
+	type binding struct {
+		protocol string
+		hostIP   string
+	}
+	published := make(map[int]binding)
+	for _, pm := range created.PortMappings {
+		published[*pm.HostPort] = binding{pm.Protocol, pm.HostIP}
+	}
+	want := map[int]binding{
+		8000:  {"tcp", ""},
+		8443:  {"tcp", ""},
+		5432:  {"tcp", ""},
+		27015: {"udp", ""},
+		8081:  {"tcp", "127.0.0.1"},
+	}
+	for hostPort, b := range want {
+		if got, ok := published[hostPort]; !ok || got != b {
+			t.Errorf("port %d published as %+v, want %+v", hostPort, got, b)
+		}
+	}
+}
+
+func TestManager_EnsureExistingContainer(t *testing.T) {
+	// The hash label of a container created from the current settings
+	var current map[string]string
+	_ = newManager(&mocks.MockContainerManager{
+		CreateContainerFunc: func(ctx context.Context, cfg *domainDocker.ContainerConfig) (string, error) {
+			current = cfg.Labels
+			return "traefik-1", nil
+		},
+	}, managedConfig()).Ensure(context.Background())
+
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		state       string
+		wantStarted bool
+		wantRemoved bool
+	}{
+		{name: "running and current", labels: current, state: "running"},
+		{name: "stopped", labels: current, state: "exited", wantStarted: true},
+		{
+			name:        "settings changed",
+			labels:      map[string]string{"podoru.managed": "true", "podoru.traefik.config-hash": "stale"},
+			state:       "running",
+			wantStarted: true,
+			wantRemoved: true,
+		},
+		{name: "run by the operator", labels: nil, state: "running"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var started, removed bool
+			containerManager := &mocks.MockContainerManager{
+				InspectContainerFunc: func(ctx context.Context, containerID string) (*domainDocker.ContainerInfo, error) {
+					return &domainDocker.ContainerInfo{ID: "traefik-0", State: tt.state, Labels: tt.labels}, nil
+				},
+				StartContainerFunc: func(ctx context.Context, containerID string) error {
+					started = true
+					return nil
+				},
+				RemoveContainerFunc: func(ctx context.Context, containerID string, force bool) error {
+					removed = true
+					return nil
+				},
+			}
+
+			if err := newManager(containerManager, managedConfig()).Ensure(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if started != tt.wantStarted {
+				t.Errorf("started = %v, want %v", started, tt.wantStarted)
+			}
+			if removed != tt.wantRemoved {
+				t.Errorf("removed = %v, want %v", removed, tt.wantRemoved)
+			}
+		})
+	}
+}
+
+func TestManager_Status(t *testing.T) {
+	containerManager := &mocks.MockContainerManager{
+		InspectContainerFunc: func(ctx context.Context, containerID string) (*domainDocker.ContainerInfo, error) {
+			return &domainDocker.ContainerInfo{ID: "traefik-0", Image: "traefik:v3.2", State: "running"}, nil
+		},
+	}
+
+	status, err := newManager(containerManager, managedConfig()).Status(context.Background(), uuid.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !status.NetworkReady || !status.Running || !status.Adopted || status.ContainerID != "traefik-0" {
+		t.Errorf("status = %+v", status)
+	}
+
+	users := &mocks.MockUserRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+			return &entity.User{ID: id, Role: entity.UserRoleUser}, nil
+		},
+	}
+	manager := traefik.NewManager(containerManager, users, managedConfig(), nil)
+	if _, err := manager.Status(context.Background(), uuid.New()); !errors.Is(err, traefik.ErrNotSuperAdmin) {
+		t.Errorf("err = %v, want ErrNotSuperAdmin", err)
+	}
+}
@@ -0,0 +1,228 @@
+package traefik
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+)
+
+// addServiceRoutes routes the domains of one service to its container. Every
+// domain gets its own routers and middlewares, while domains sharing a
+// container port share one Traefik service, so one container can serve an
+// API and an admin UI on different domains or paths.
+func addServiceRoutes(cfg *DynamicConfig, service *entity.Service, domains []entity.Domain, defaultPort int) {
+	for i := range domains {
+		d := &domains[i]
+
+		port := defaultPort
+		if d.Port != nil {
+			port = *d.Port
+		}
+		routerName := fmt.Sprintf("podoru-%s-%s", service.Slug, d.ID.String()[:8])
+
+		switch d.RoutingMode {
+		case entity.RoutingModeTCP:
+			addTCPRoute(cfg.TCP, routerName, service, port, d)
+		case entity.RoutingModeUDP:
+			addUDPRoute(cfg.UDP, routerName, service, port, d)
+		default:
+			serviceName := addLoadBalancer(cfg.HTTP, service, port)
+			addDomainRouters(cfg.HTTP, routerName, serviceName, d)
+		}
+	}
+}
+
+// addTCPRoute routes a TCP domain on its entrypoint. With TLS the router
+// matches the SNI, so several hosts can share the entrypoint; plain TCP has
+// no host to match and catches every connection.
+func addTCPRoute(cfg *TCPConfig, routerName string, service *entity.Service, port int, d *entity.Domain) {
+	serviceName := fmt.Sprintf("podoru-%s-tcp-%d", service.Slug, port)
+	cfg.Services[serviceName] = &TCPService{
+		LoadBalancer: &TCPLoadBalancer{
+			Servers: []TCPServer{{Address: fmt.Sprintf("%s:%d", service.ContainerName(), port)}},
+		},
+	}
+
+	router := &TCPRouter{
+		Rule:        "HostSNI(`*`)",
+		EntryPoints: []string{d.EntryPoint},
+		Service:     serviceName,
+	}
+	switch {
+	case d.TLSPassthrough:
+		router.Rule = fmt.Sprintf("HostSNI(`%s`)", d.Domain)
+		router.TLS = &TCPRouterTLS{Passthrough: true}
+	case d.SSLEnabled:
+		router.Rule = fmt.Sprintf("HostSNI(`%s`)", d.Domain)
+		router.TLS = &TCPRouterTLS{CertResolver: routerTLS(d).CertResolver}
+	}
+
+	if len(d.IPAllowList) > 0 {
+		cfg.Middlewares[routerName+"-allow"] = &TCPMiddleware{IPAllowList: &IPAllowList{SourceRange: d.IPAllowList}}
+		router.Middlewares = []string{routerName + "-allow"}
+	}
+	cfg.Routers[routerName] = router
+}
+
+// addUDPRoute hands a whole UDP entrypoint to the service
+func addUDPRoute(cfg *UDPConfig, routerName string, service *entity.Service, port int, d *entity.Domain) {
+	serviceName := fmt.Sprintf("podoru-%s-udp-%d", service.Slug, port)
+	cfg.Services[serviceName] = &UDPService{
+		LoadBalancer: &UDPLoadBalancer{
+			Servers: []UDPServer{{Address: fmt.Sprintf("%s:%d", service.ContainerName(), port)}},
+		},
+	}
+	cfg.Routers[routerName] = &UDPRouter{
+		EntryPoints: []string{d.EntryPoint},
+		Service:     serviceName,
+	}
+}
+
+// addPlatformRoute routes the hostname a service gets under the base domain.
+// It is served over HTTPS with the shared wildcard certificate, so services
+// never wait for a certificate of their own.
+func addPlatformRoute(cfg *HTTPConfig, service *entity.Service, host string, port int, tls *RouterTLS) {
+	serviceName := addLoadBalancer(cfg, service, port)
+
+	label, _, _ := strings.Cut(host, ".")
+	routerName := "podoru-platform-" + label
+	addDomainRouters(cfg, routerName, serviceName, &entity.Domain{Domain: host, SSLEnabled: true})
+	cfg.Routers[routerName+"-secure"].TLS = tls
+}
+
+// addLoadBalancer defines the Traefik service sending traffic to one port of
+// the service's container and returns its name
+func addLoadBalancer(cfg *HTTPConfig, service *entity.Service, port int) string {
+	serviceName := fmt.Sprintf("podoru-%s-%d", service.Slug, port)
+	cfg.Services[serviceName] = &Service{
+		LoadBalancer: &LoadBalancer{
+			Servers: []Server{{URL: fmt.Sprintf("http://%s:%d", service.ContainerName(), port)}},
+		},
+	}
+	return serviceName
+}
+
+// addDomainRouters adds the HTTP (and HTTPS) routers sending one domain to a
+// Traefik service, with the domain's middlewares attached
+func addDomainRouters(cfg *HTTPConfig, routerName, serviceName string, d *entity.Domain) {
+	rule := fmt.Sprintf("Host(`%s`)", d.Domain)
+	if d.PathPrefix != "" {
+		rule += fmt.Sprintf(" && PathPrefix(`%s`)", d.PathPrefix)
+	}
+	middlewares := addDomainMiddlewares(cfg, routerName, d)
+
+	// HTTP router
+	router := &Router{
+		Rule:        rule,
+		EntryPoints: []string{"web"},
+		Service:     serviceName,
+	}
+	cfg.Routers[routerName] = router
+
+	if !d.SSLEnabled {
+		router.Middlewares = middlewares
+	} else {
+		// HTTPS router with SSL
+		cfg.Routers[routerName+"-secure"] = &Router{
+			Rule:        rule,
+			EntryPoints: []string{"websecure"},
+			Service:     serviceName,
+			Middlewares: middlewares,
+			TLS:         routerTLS(d),
+		}
+
+		// HTTP to HTTPS redirect
+		cfg.Middlewares[routerName+"-redirect"] = &Middleware{
+			RedirectScheme: &RedirectScheme{Scheme: "https", Permanent: true},
+		}
+		router.Middlewares = []string{routerName + "-redirect"}
+	}
+
+	if d.WWWRedirect {
+		addWWWRedirect(cfg, routerName, d)
+	}
+}
+
+// addDomainMiddlewares defines the middlewares a domain asks for and returns
+// their names in the order requests pass through them: access checks first,
+// then response headers, then the prefix strip just before the container
+func addDomainMiddlewares(cfg *HTTPConfig, routerName string, d *entity.Domain) []string {
+	var names []string
+	add := func(suffix string, m *Middleware) {
+		name := routerName + suffix
+		cfg.Middlewares[name] = m
+		names = append(names, name)
+	}
+
+	if len(d.IPAllowList) > 0 {
+		add("-allow", &Middleware{IPAllowList: &IPAllowList{SourceRange: d.IPAllowList}})
+	}
+
+	if d.RateLimit != nil {
+		add("-ratelimit", &Middleware{RateLimit: &RateLimit{Average: d.RateLimit.Average, Burst: d.RateLimit.Burst}})
+	}
+
+	if len(d.BasicAuthUsers) > 0 {
+		add("-auth", &Middleware{BasicAuth: &BasicAuth{Users: d.BasicAuthUsers}})
+	}
+
+	if len(d.ResponseHeaders) > 0 {
+		add("-headers", &Middleware{Headers: &Headers{CustomResponseHeaders: d.ResponseHeaders}})
+	}
+
+	if d.StripPrefix && d.PathPrefix != "" {
+		add("-strip", &Middleware{StripPrefix: &StripPrefix{Prefixes: []string{d.PathPrefix}}})
+	}
+
+	return names
+}
+
+// addWWWRedirect answers on the www (or www-less) counterpart of the domain
+// and permanently redirects it to the domain, keeping the path
+func addWWWRedirect(cfg *HTTPConfig, routerName string, d *entity.Domain) {
+	from := entity.WWWCounterpart(d.Domain)
+	name := routerName + "-www"
+
+	scheme := "http"
+	if d.SSLEnabled {
+		scheme = "https"
+	}
+	cfg.Middlewares[name] = &Middleware{
+		RedirectRegex: &RedirectRegex{
+			Regex:       fmt.Sprintf("^https?://%s/(.*)", regexp.QuoteMeta(from)),
+			Replacement: fmt.Sprintf("%s://%s/${1}", scheme, d.Domain),
+			Permanent:   true,
+		},
+	}
+
+	rule := fmt.Sprintf("Host(`%s`)", from)
+	cfg.Routers[name] = &Router{
+		Rule:        rule,
+		EntryPoints: []string{"web"},
+		Service:     "noop@internal",
+		Middlewares: []string{name},
+	}
+
+	// The counterpart needs its own certificate to redirect HTTPS requests
+	if d.SSLEnabled {
+		cfg.Routers[name+"-secure"] = &Router{
+			Rule:        rule,
+			EntryPoints: []string{"websecure"},
+			Service:     "noop@internal",
+			Middlewares: []string{name},
+			TLS:         routerTLS(d),
+		}
+	}
+}
+
+// routerTLS uses Let's Encrypt for auto SSL domains. Domains with an uploaded
+// certificate leave the resolver out so Traefik picks it from the store.
+func routerTLS(d *entity.Domain) *RouterTLS {
+	tls := &RouterTLS{}
+	if d.SSLAuto && d.CertificateID == nil {
+		tls.CertResolver = "letsencrypt"
+	}
+	return tls
+}
@@ -0,0 +1,308 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	domainDocker "github.com/podoru/spinner-podoru/internal/domain/docker"
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/domain/repository"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/config"
+	"github.com/podoru/spinner-podoru/pkg/crypto"
+)
+
+// UseCase generates Traefik's routing from the domains in the database, so
+// domain changes apply without recreating containers
+type UseCase struct {
+	serviceRepo      repository.ServiceRepository
+	projectRepo      repository.ProjectRepository
+	domainRepo       repository.DomainRepository
+	portRepo         repository.PortMappingRepository
+	certificateRepo  repository.CertificateRepository
+	containerManager domainDocker.ContainerManager
+	encryptor        *crypto.Encryptor
+	traefikConfig    *config.TraefikConfig
+	changes          chan struct{}
+}
+
+// NewUseCase creates a new Traefik use case
+func NewUseCase(
+	serviceRepo repository.ServiceRepository,
+	projectRepo repository.ProjectRepository,
+	domainRepo repository.DomainRepository,
+	portRepo repository.PortMappingRepository,
+	certificateRepo repository.CertificateRepository,
+	containerManager domainDocker.ContainerManager,
+	encryptor *crypto.Encryptor,
+	traefikConfig *config.TraefikConfig,
+) *UseCase {
+	return &UseCase{
+		serviceRepo:      serviceRepo,
+		projectRepo:      projectRepo,
+		domainRepo:       domainRepo,
+		portRepo:         portRepo,
+		certificateRepo:  certificateRepo,
+		containerManager: containerManager,
+		encryptor:        encryptor,
+		traefikConfig:    traefikConfig,
+		changes:          make(chan struct{}, 1),
+	}
+}
+
+// Refresh signals that routing changed. Traefik polling the HTTP provider
+// picks changes up by itself; this lets the file writer rewrite right away.
+func (uc *UseCase) Refresh() {
+	select {
+	case uc.changes <- struct{}{}:
+	default:
+	}
+}
+
+// PlatformDomain returns the hostname the service gets under the base domain,
+// or "" when no base domain is configured
+func (uc *UseCase) PlatformDomain(service *entity.Service, project *entity.Project) string {
+	if !uc.traefikConfig.Enabled || uc.traefikConfig.BaseDomain == "" {
+		return ""
+	}
+	return entity.PlatformHostname(service.Slug, project.Slug, uc.traefikConfig.BaseDomain)
+}
+
+// Config builds the dynamic configuration for every deployed service with
+// domains, or every deployed service at all when a base domain is set.
+// Stopped services are left out so Traefik stops routing to them.
+func (uc *UseCase) Config(ctx context.Context) (*DynamicConfig, error) {
+	cfg := &DynamicConfig{
+		HTTP: &HTTPConfig{
+			Routers:     make(map[string]*Router),
+			Services:    make(map[string]*Service),
+			Middlewares: make(map[string]*Middleware),
+		},
+		TCP: &TCPConfig{
+			Routers:     make(map[string]*TCPRouter),
+			Services:    make(map[string]*TCPService),
+			Middlewares: make(map[string]*TCPMiddleware),
+		},
+		UDP: &UDPConfig{
+			Routers:  make(map[string]*UDPRouter),
+			Services: make(map[string]*UDPService),
+		},
+	}
+	if !uc.traefikConfig.Enabled {
+		return &DynamicConfig{HTTP: cfg.HTTP}, nil
+	}
+
+	domains, err := uc.domainRepo.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var serviceIDs []uuid.UUID
+	byService := make(map[uuid.UUID][]entity.Domain)
+	for _, d := range domains {
+		// Claimed hosts stay unrouted until their owner proves control
+		if !d.IsVerified() {
+			continue
+		}
+		if _, ok := byService[d.ServiceID]; !ok {
+			serviceIDs = append(serviceIDs, d.ServiceID)
+		}
+		byService[d.ServiceID] = append(byService[d.ServiceID], d)
+	}
+
+	if uc.traefikConfig.BaseDomain != "" {
+		deployed, err := uc.serviceRepo.ListDeployed(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range deployed {
+			if _, ok := byService[s.ID]; !ok {
+				serviceIDs = append(serviceIDs, s.ID)
+				byService[s.ID] = nil
+			}
+		}
+	}
+
+	var certificateIDs []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	projects := make(map[uuid.UUID]*entity.Project)
+	for _, serviceID := range serviceIDs {
+		service, err := uc.serviceRepo.GetByID(ctx, serviceID)
+		if err != nil {
+			return nil, err
+		}
+		if service == nil || service.ContainerID == nil || *service.ContainerID == "" ||
+			service.Status == entity.ServiceStatusStopped {
+			continue
+		}
+
+		host, err := uc.platformHost(ctx, service, projects)
+		if err != nil {
+			return nil, err
+		}
+
+		serviceDomains := byService[serviceID]
+		port := 0
+		if host != "" || needsDefaultPort(serviceDomains) {
+			if port, err = uc.defaultPort(ctx, service); err != nil {
+				return nil, err
+			}
+		}
+		addServiceRoutes(cfg, service, serviceDomains, port)
+		if host != "" {
+			addPlatformRoute(cfg.HTTP, service, host, port, uc.platformTLS())
+		}
+
+		for _, d := range serviceDomains {
+			if d.SSLEnabled && d.CertificateID != nil && !seen[*d.CertificateID] {
+				seen[*d.CertificateID] = true
+				certificateIDs = append(certificateIDs, *d.CertificateID)
+			}
+		}
+	}
+
+	if cfg.TLS, err = uc.tlsConfig(ctx, certificateIDs); err != nil {
+		return nil, err
+	}
+
+	// Traefik only needs the sections in use
+	if len(cfg.TCP.Routers) == 0 {
+		cfg.TCP = nil
+	}
+	if len(cfg.UDP.Routers) == 0 {
+		cfg.UDP = nil
+	}
+	return cfg, nil
+}
+
+// HasEntryPoint reports whether the entrypoint is configured for the routing
+// mode, so domains can only bind entrypoints Traefik listens on. The managed
+// container only defines entrypoints configured with a port.
+func (uc *UseCase) HasEntryPoint(mode entity.RoutingMode, name string) bool {
+	var entryPoints []entryPoint
+	switch mode {
+	case entity.RoutingModeTCP:
+		entryPoints = parseEntryPoints(uc.traefikConfig.TCPEntryPoints)
+	case entity.RoutingModeUDP:
+		entryPoints = parseEntryPoints(uc.traefikConfig.UDPEntryPoints)
+	}
+	for _, ep := range entryPoints {
+		if ep.name == name {
+			return !uc.traefikConfig.Managed || ep.port > 0
+		}
+	}
+	return false
+}
+
+// entryPoint is a tcp or udp entrypoint from the configuration, written as
+// "name", or as "name:port" for the managed container to define and publish
+type entryPoint struct {
+	name string
+	port int
+}
+
+func parseEntryPoints(list []string) []entryPoint {
+	entryPoints := make([]entryPoint, 0, len(list))
+	for _, item := range list {
+		name, portStr, _ := strings.Cut(strings.TrimSpace(item), ":")
+		if name == "" {
+			continue
+		}
+		ep := entryPoint{name: name}
+		if port, err := strconv.Atoi(portStr); err == nil && port > 0 && port <= 65535 {
+			ep.port = port
+		}
+		entryPoints = append(entryPoints, ep)
+	}
+	return entryPoints
+}
+
+// tlsConfig loads the uploaded certificates routed domains use, so only
+// certificates in use are handed to Traefik
+func (uc *UseCase) tlsConfig(ctx context.Context, certificateIDs []uuid.UUID) (*TLSConfig, error) {
+	if len(certificateIDs) == 0 {
+		return nil, nil
+	}
+
+	certificates, err := uc.certificateRepo.ListByIDs(ctx, certificateIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	tls := &TLSConfig{}
+	for _, c := range certificates {
+		key, err := uc.encryptor.Decrypt(c.KeyEncrypted)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt key of certificate %s: %w", c.ID, err)
+		}
+		tls.Certificates = append(tls.Certificates, TLSCertificate{
+			CertFile: c.CertificatePEM,
+			KeyFile:  string(key),
+			Stores:   []string{"default"},
+		})
+	}
+	return tls, nil
+}
+
+// platformHost returns the service's hostname under the base domain, looking
+// projects up once per Config call
+func (uc *UseCase) platformHost(ctx context.Context, service *entity.Service, projects map[uuid.UUID]*entity.Project) (string, error) {
+	if uc.traefikConfig.BaseDomain == "" {
+		return "", nil
+	}
+
+	project, ok := projects[service.ProjectID]
+	if !ok {
+		var err error
+		if project, err = uc.projectRepo.GetByID(ctx, service.ProjectID); err != nil {
+			return "", err
+		}
+		projects[service.ProjectID] = project
+	}
+	if project == nil {
+		return "", nil
+	}
+	return uc.PlatformDomain(service, project), nil
+}
+
+// platformTLS requests one wildcard certificate for the base domain through
+// the DNS-01 resolver, the only challenge able to issue wildcards
+func (uc *UseCase) platformTLS() *RouterTLS {
+	base := uc.traefikConfig.BaseDomain
+	return &RouterTLS{
+		CertResolver: uc.traefikConfig.BaseDomainCertResolver,
+		Domains:      []TLSDomain{{Main: base, SANs: []string{"*." + base}}},
+	}
+}
+
+func needsDefaultPort(domains []entity.Domain) bool {
+	for _, d := range domains {
+		if d.Port == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultPort picks the port for domains without their own: the first TCP
+// port mapping, then the first port the container exposes, then 80
+func (uc *UseCase) defaultPort(ctx context.Context, service *entity.Service) (int, error) {
+	portMappings, err := uc.portRepo.ListByServiceID(ctx, service.ID)
+	if err != nil {
+		return 0, err
+	}
+	for _, pm := range portMappings {
+		if pm.Protocol == "tcp" {
+			return pm.ContainerPort, nil
+		}
+	}
+
+	if info, err := uc.containerManager.InspectContainer(ctx, *service.ContainerID); err == nil && len(info.ExposedPorts) > 0 {
+		return info.ExposedPorts[0], nil
+	}
+
+	return 80, nil
+}
@@ -0,0 +1,527 @@
+package traefik_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	domainDocker "github.com/podoru/spinner-podoru/internal/domain/docker"
+	"github.com/podoru/spinner-podoru/internal/domain/entity"
+	"github.com/podoru/spinner-podoru/internal/infrastructure/config"
+	"github.com/podoru/spinner-podoru/internal/mocks"
+	"github.com/podoru/spinner-podoru/internal/usecase/traefik"
+)
+
+func newUseCase(services []*entity.Service, domains []entity.Domain, portMappings []entity.PortMapping, traefikConfig *config.TraefikConfig) *traefik.UseCase {
+	serviceRepo := &mocks.MockServiceRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+			for _, s := range services {
+				if s.ID == id {
+					return s, nil
+				}
+			}
+			return nil, nil
+		},
+		ListDeployedFunc: func(ctx context.Context) ([]entity.Service, error) {
+			var deployed []entity.Service
+			for _, s := range services {
+				if s.ContainerID != nil && s.Status != entity.ServiceStatusStopped {
+					deployed = append(deployed, *s)
+				}
+			}
+			return deployed, nil
+		},
+	}
+	projectRepo := &mocks.MockProjectRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+			return &entity.Project{ID: id, Slug: "shop"}, nil
+		},
+	}
+	domainRepo := &mocks.MockDomainRepository{
+		ListAllFunc: func(ctx context.Context) ([]entity.Domain, error) {
+			return domains, nil
+		},
+	}
+	portRepo := &mocks.MockPortMappingRepository{
+		ListByServiceIDFunc: func(ctx context.Context, serviceID uuid.UUID) ([]entity.PortMapping, error) {
+			return portMappings, nil
+		},
+	}
+
+	return traefik.NewUseCase(serviceRepo, projectRepo, domainRepo, portRepo, nil, nil, nil, traefikConfig)
+}
+
+func deployedService(slug string, status entity.ServiceStatus) *entity.Service {
+	containerID := "container-" + slug
+	return &entity.Service{
+		ID:          uuid.New(),
+		Slug:        slug,
+		Status:      status,
+		ContainerID: &containerID,
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestConfig_RoutesDomains(t *testing.T) {
+	service := deployedService("myapp", entity.ServiceStatusRunning)
+	api := entity.Domain{
+		ID:             uuid.New(),
+		ServiceID:      service.ID,
+		Domain:         "example.com",
+		Status:         entity.DomainStatusVerified,
+		SSLEnabled:     true,
+		SSLAuto:        true,
+		PathPrefix:     "/api",
+		StripPrefix:    true,
+		BasicAuthUsers: []string{"admin:$2a$10$hash"},
+		IPAllowList:    []string{"10.0.0.0/8"},
+	}
+	admin := entity.Domain{
+		ID:        uuid.New(),
+		ServiceID: service.ID,
+		Domain:    "admin.example.com",
+		Status:    entity.DomainStatusVerified,
+		Port:      intPtr(3000),
+	}
+	portMappings := []entity.PortMapping{
+		{ContainerPort: 53, Protocol: "udp"},
+		{ContainerPort: 8080, Protocol: "tcp"},
+	}
+
+	uc := newUseCase([]*entity.Service{service}, []entity.Domain{api, admin}, portMappings, &config.TraefikConfig{Enabled: true})
+
+	cfg, err := uc.Config(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	apiRouter := "podoru-myapp-" + api.ID.String()[:8]
+	adminRouter := "podoru-myapp-" + admin.ID.String()[:8]
+
+	tests := []struct {
+		name        string
+		router      string
+		rule        string
+		service     string
+		middlewares []string
+		tls         bool
+	}{
+		{"http redirects to https", apiRouter, "Host(`example.com`) && PathPrefix(`/api`)", "podoru-myapp-8080", []string{apiRouter + "-redirect"}, false},
+		{"https carries middlewares", apiRouter + "-secure", "Host(`example.com`) && PathPrefix(`/api`)", "podoru-myapp-8080", []string{apiRouter + "-allow", apiRouter + "-auth", apiRouter + "-strip"}, true},
+		{"own port without ssl", adminRouter, "Host(`admin.example.com`)", "podoru-myapp-3000", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, ok := cfg.HTTP.Routers[tt.router]
+			if !ok {
+				t.Fatalf("router %s missing", tt.router)
+			}
+			if router.Rule != tt.rule {
+				t.Errorf("expected rule %s, got %s", tt.rule, router.Rule)
+			}
+			if router.Service != tt.service {
+				t.Errorf("expected service %s, got %s", tt.service, router.Service)
+			}
+			if !reflect.DeepEqual(router.Middlewares, tt.middlewares) {
+				t.Errorf("expected middlewares %v, got %v", tt.middlewares, router.Middlewares)
+			}
+			if (router.TLS != nil) != tt.tls {
+				t.Errorf("expected tls %v, got %v", tt.tls, router.TLS != nil)
+			}
+		})
+	}
+
+	if got := cfg.HTTP.Routers[apiRouter+"-secure"].TLS.CertResolver; got != "letsencrypt" {
+		t.Errorf("expected cert resolver letsencrypt, got %q", got)
+	}
+
+	servers := map[string]string{
+		"podoru-myapp-8080": "http://podoru-myapp:8080",
+		"podoru-myapp-3000": "http://podoru-myapp:3000",
+	}
+	for name, url := range servers {
+		service, ok := cfg.HTTP.Services[name]
+		if !ok {
+			t.Errorf("service %s missing", name)
+			continue
+		}
+		if got := service.LoadBalancer.Servers[0].URL; got != url {
+			t.Errorf("expected %s to point at %s, got %s", name, url, got)
+		}
+	}
+}
+
+func TestConfig_WWWRedirect(t *testing.T) {
+	service := deployedService("site", entity.ServiceStatusRunning)
+	domain := entity.Domain{
+		ID:          uuid.New(),
+		ServiceID:   service.ID,
+		Domain:      "example.com",
+		Status:      entity.DomainStatusVerified,
+		Port:        intPtr(80),
+		WWWRedirect: true,
+	}
+
+	uc := newUseCase([]*entity.Service{service}, []entity.Domain{domain}, nil, &config.TraefikConfig{Enabled: true})
+
+	cfg, err := uc.Config(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	name := "podoru-site-" + domain.ID.String()[:8] + "-www"
+	router, ok := cfg.HTTP.Routers[name]
+	if !ok {
+		t.Fatalf("router %s missing", name)
+	}
+	if router.Rule != "Host(`www.example.com`)" {
+		t.Errorf("expected www host rule, got %s", router.Rule)
+	}
+	if router.Service != "noop@internal" {
+		t.Errorf("expected noop@internal service, got %s", router.Service)
+	}
+
+	redirect := cfg.HTTP.Middlewares[name].RedirectRegex
+	if redirect == nil {
+		t.Fatal("expected redirect middleware")
+	}
+	if redirect.Replacement != "http://example.com/${1}" || !redirect.Permanent {
+		t.Errorf("unexpected redirect %+v", redirect)
+	}
+	if _, ok := cfg.HTTP.Routers[name+"-secure"]; ok {
+		t.Error("expected no https redirect router without ssl")
+	}
+}
+
+func TestConfig_MiddlewareOrder(t *testing.T) {
+	service := deployedService("api", entity.ServiceStatusRunning)
+	domain := entity.Domain{
+		ID:              uuid.New(),
+		ServiceID:       service.ID,
+		Domain:          "api.example.com",
+		Status:          entity.DomainStatusVerified,
+		Port:            intPtr(8080),
+		PathPrefix:      "/v1",
+		StripPrefix:     true,
+		IPAllowList:     []string{"10.0.0.0/8"},
+		RateLimit:       &entity.RateLimit{Average: 100, Burst: 50},
+		BasicAuthUsers:  []string{"admin:$2a$10$hash"},
+		ResponseHeaders: map[string]string{"X-Frame-Options": "DENY"},
+	}
+
+	uc := newUseCase([]*entity.Service{service}, []entity.Domain{domain}, nil, &config.TraefikConfig{Enabled: true})
+
+	cfg, err := uc.Config(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Access checks run before headers, and the prefix is stripped last
+	name := "podoru-api-" + domain.ID.String()[:8]
+	expected := []string{name + "-allow", name + "-ratelimit", name + "-auth", name + "-headers", name + "-strip"}
+	if got := cfg.HTTP.Routers[name].Middlewares; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected middlewares %v, got %v", expected, got)
+	}
+	for _, m := range expected {
+		if _, ok := cfg.HTTP.Middlewares[m]; !ok {
+			t.Errorf("middleware %s missing", m)
+		}
+	}
+}
+
+func TestConfig_SkipsUnroutedServices(t *testing.T) {
+	stopped := deployedService("stopped", entity.ServiceStatusStopped)
+	undeployed := &entity.Service{ID: uuid.New(), Slug: "new", Status: entity.ServiceStatusStopped}
+	domains := []entity.Domain{
+		{ID: uuid.New(), ServiceID: stopped.ID, Domain: "stopped.example.com", Status: entity.DomainStatusVerified, Port: intPtr(80)},
+		{ID: uuid.New(), ServiceID: undeployed.ID, Domain: "new.example.com", Status: entity.DomainStatusVerified, Port: intPtr(80)},
+		{ID: uuid.New(), ServiceID: uuid.New(), Domain: "gone.example.com", Status: entity.DomainStatusVerified, Port: intPtr(80)},
+	}
+
+	tests := []struct {
+		name    string
+		enabled bool
+	}{
+		{"traefik enabled", true},
+		{"traefik disabled", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := newUseCase([]*entity.Service{stopped, undeployed}, domains, nil, &config.TraefikConfig{Enabled: tt.enabled, BaseDomain: "apps.example.com"})
+
+			cfg, err := uc.Config(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(cfg.HTTP.Routers) != 0 || len(cfg.HTTP.Services) != 0 {
+				t.Errorf("expected no routes, got %d routers and %d services", len(cfg.HTTP.Routers), len(cfg.HTTP.Services))
+			}
+		})
+	}
+}
+
+func TestConfig_SkipsPendingDomains(t *testing.T) {
+	service := deployedService("site", entity.ServiceStatusRunning)
+	verified := entity.Domain{ID: uuid.New(), ServiceID: service.ID, Domain: "example.com", Status: entity.DomainStatusVerified, Port: intPtr(80)}
+	pending := entity.Domain{ID: uuid.New(), ServiceID: service.ID, Domain: "claimed.example.com", Status: entity.DomainStatusPending, Port: intPtr(80)}
+
+	uc := newUseCase([]*entity.Service{service}, []entity.Domain{verified, pending}, nil, &config.TraefikConfig{Enabled: true})
+
+	cfg, err := uc.Config(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := cfg.HTTP.Routers["podoru-site-"+verified.ID.String()[:8]]; !ok {
+		t.Error("expected router for the verified domain")
+	}
+	if _, ok := cfg.HTTP.Routers["podoru-site-"+pending.ID.String()[:8]]; ok {
+		t.Error("expected no router for the pending domain")
+	}
+}
+
+func TestConfig_PlatformDomain(t *testing.T) {
+	service := deployedService("api", entity.ServiceStatusRunning)
+	service.ProjectID = uuid.New()
+	portMappings := []entity.PortMapping{{ServiceID: service.ID, ContainerPort: 8080, Protocol: "tcp"}}
+
+	uc := newUseCase([]*entity.Service{service}, nil, portMappings, &config.TraefikConfig{
+		Enabled:                true,
+		BaseDomain:             "apps.example.com",
+		BaseDomainCertResolver: "letsencrypt-dns",
+	})
+
+	cfg, err := uc.Config(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	router, ok := cfg.HTTP.Routers["podoru-platform-api-shop-secure"]
+	if !ok {
+		t.Fatal("expected platform router")
+	}
+	if router.Rule != "Host(`api-shop.apps.example.com`)" {
+		t.Errorf("unexpected rule %s", router.Rule)
+	}
+	if router.Service != "podoru-api-8080" {
+		t.Errorf("expected service podoru-api-8080, got %s", router.Service)
+	}
+	wantTLS := &traefik.RouterTLS{
+		CertResolver: "letsencrypt-dns",
+		Domains:      []traefik.TLSDomain{{Main: "apps.example.com", SANs: []string{"*.apps.example.com"}}},
+	}
+	if !reflect.DeepEqual(router.TLS, wantTLS) {
+		t.Errorf("TLS = %+v, want %+v", router.TLS, wantTLS)
+	}
+	if _, ok := cfg.HTTP.Routers["podoru-platform-api-shop"]; !ok {
+		t.Error("expected http redirect router")
+	}
+}
+
+func TestConfig_TCPAndUDPRoutes(t *testing.T) {
+	db := deployedService("db", entity.ServiceStatusRunning)
+	game := deployedService("game", entity.ServiceStatusRunning)
+
+	postgres := entity.Domain{
+		ID: uuid.New(), ServiceID: db.ID, Domain: "db.example.com", Status: entity.DomainStatusVerified,
+		RoutingMode: entity.RoutingModeTCP, EntryPoint: "postgres", Port: intPtr(5432), SSLEnabled: true, SSLAuto: true,
+		IPAllowList: []string{"10.0.0.0/8"},
+	}
+	redis := entity.Domain{
+		ID: uuid.New(), ServiceID: db.ID, Domain: "cache.example.com", Status: entity.DomainStatusVerified,
+		RoutingMode: entity.RoutingModeTCP, EntryPoint: "redis", Port: intPtr(6379),
+	}
+	passthrough := entity.Domain{
+		ID: uuid.New(), ServiceID: db.ID, Domain: "mq.example.com", Status: entity.DomainStatusVerified,
+		RoutingMode: entity.RoutingModeTCP, EntryPoint: "postgres", Port: intPtr(5671), TLSPassthrough: true,
+	}
+	udp := entity.Domain{
+		ID: uuid.New(), ServiceID: game.ID, Domain: "play.example.com", Status: entity.DomainStatusVerified,
+		RoutingMode: entity.RoutingModeUDP, EntryPoint: "game", Port: intPtr(27015),
+	}
+
+	uc := newUseCase([]*entity.Service{db, game}, []entity.Domain{postgres, redis, passthrough, udp}, nil, &config.TraefikConfig{Enabled: true})
+
+	cfg, err := uc.Config(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.HTTP.Routers) != 0 {
+		t.Errorf("expected no http routers, got %d", len(cfg.HTTP.Routers))
+	}
+	if cfg.TCP == nil || cfg.UDP == nil {
+		t.Fatal("expected tcp and udp sections")
+	}
+
+	tests := []struct {
+		name    string
+		domain  entity.Domain
+		rule    string
+		tls     *traefik.TCPRouterTLS
+		service string
+	}{
+		{"tls terminated", postgres, "HostSNI(`db.example.com`)", &traefik.TCPRouterTLS{CertResolver: "letsencrypt"}, "podoru-db-tcp-5432"},
+		{"plain tcp", redis, "HostSNI(`*`)", nil, "podoru-db-tcp-6379"},
+		{"passthrough", passthrough, "HostSNI(`mq.example.com`)", &traefik.TCPRouterTLS{Passthrough: true}, "podoru-db-tcp-5671"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, ok := cfg.TCP.Routers["podoru-db-"+tt.domain.ID.String()[:8]]
+			if !ok {
+				t.Fatal("router missing")
+			}
+			if router.Rule != tt.rule {
+				t.Errorf("rule = %s, want %s", router.Rule, tt.rule)
+			}
+			if !reflect.DeepEqual(router.EntryPoints, []string{tt.domain.EntryPoint}) {
+				t.Errorf("entrypoints = %v, want [%s]", router.EntryPoints, tt.domain.EntryPoint)
+			}
+			if !reflect.DeepEqual(router.TLS, tt.tls) {
+				t.Errorf("tls = %+v, want %+v", router.TLS, tt.tls)
+			}
+			if router.Service != tt.service {
+				t.Errorf("service = %s, want %s", router.Service, tt.service)
+			}
+		})
+	}
+
+	allow := cfg.TCP.Middlewares["podoru-db-"+postgres.ID.String()[:8]+"-allow"]
+	if allow == nil || !reflect.DeepEqual(allow.IPAllowList.SourceRange, postgres.IPAllowList) {
+		t.Errorf("expected tcp ip allow list middleware, got %+v", allow)
+	}
+
+	router, ok := cfg.UDP.Routers["podoru-game-"+udp.ID.String()[:8]]
+	if !ok {
+		t.Fatal("udp router missing")
+	}
+	if router.Service != "podoru-game-udp-27015" || !reflect.DeepEqual(router.EntryPoints, []string{"game"}) {
+		t.Errorf("unexpected udp router %+v", router)
+	}
+	if addr := cfg.UDP.Services["podoru-game-udp-27015"].LoadBalancer.Servers[0].Address; addr != "podoru-game:27015" {
+		t.Errorf("udp server = %s, want podoru-game:27015", addr)
+	}
+}
+
+func TestConfig_DefaultPort(t *testing.T) {
+	tests := []struct {
+		name         string
+		portMappings []entity.PortMapping
+		exposed      []int
+		wantPort     int
+	}{
+		{
+			name: "first tcp port mapping",
+			portMappings: []entity.PortMapping{
+				{ContainerPort: 53, Protocol: "udp"},
+				{ContainerPort: 8080, Protocol: "tcp"},
+				{ContainerPort: 9090, Protocol: "tcp"},
+			},
+			exposed:  []int{3000},
+			wantPort: 8080,
+		},
+		{
+			name:         "exposed port without a tcp mapping",
+			portMappings: []entity.PortMapping{{ContainerPort: 53, Protocol: "udp"}},
+			exposed:      []int{3000, 4000},
+			wantPort:     3000,
+		},
+		{name: "nothing mapped or exposed", wantPort: 80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			service := deployedService("web", entity.ServiceStatusRunning)
+
+			serviceRepo := &mocks.MockServiceRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Service, error) {
+					return service, nil
+				},
+			}
+
+			projectRepo := &mocks.MockProjectRepository{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+					return &entity.Project{ID: id, Slug: "shop"}, nil
+				},
+			}
+
+			domainRepo := &mocks.MockDomainRepository{
+				ListAllFunc: func(ctx context.Context) ([]entity.Domain, error) {
+					return []entity.Domain{{
+						ID:        uuid.New(),
+						ServiceID: service.ID,
+						Domain:    "example.com",
+						Status:    entity.DomainStatusVerified,
+					}}, nil
+				},
+			}
+
+			portRepo := &mocks.MockPortMappingRepository{
+				ListByServiceIDFunc: func(ctx context.Context, serviceID uuid.UUID) ([]entity.PortMapping, error) {
+					return tt.portMappings, nil
+				},
+			}
+
+			containerManager := &mocks.MockContainerManager{
+				InspectContainerFunc: func(ctx context.Context, containerID string) (*domainDocker.ContainerInfo, error) {
+					return &domainDocker.ContainerInfo{ID: containerID, ExposedPorts: tt.exposed}, nil
+				},
+			}
+
+			uc := traefik.NewUseCase(serviceRepo, projectRepo, domainRepo, portRepo, nil, containerManager, nil,
+				&config.TraefikConfig{Enabled: true})
+
+			cfg, err := uc.Config(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			name := fmt.Sprintf("podoru-web-%d", tt.wantPort)
+			if _, ok := cfg.HTTP.Services[name]; !ok || len(cfg.HTTP.Services) != 1 {
+				t.Errorf("expected only service %s, got %v", name, reflect.ValueOf(cfg.HTTP.Services).MapKeys())
+			}
+		})
+	}
+}
+
+func TestHasEntryPoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		managed bool
+		mode    entity.RoutingMode
+		entry   string
+		want    bool
+	}{
+		{name: "listed tcp entrypoint", mode: entity.RoutingModeTCP, entry: "redis", want: true},
+		{name: "listed with a port", mode: entity.RoutingModeTCP, entry: "postgres", want: true},
+		{name: "udp entrypoint", mode: entity.RoutingModeUDP, entry: "game", want: true},
+		{name: "tcp name used for udp", mode: entity.RoutingModeUDP, entry: "postgres", want: false},
+		{name: "unknown entrypoint", mode: entity.RoutingModeTCP, entry: "mysql", want: false},
+		{name: "managed with a port", managed: true, mode: entity.RoutingModeTCP, entry: "postgres", want: true},
+		{name: "managed without a port", managed: true, mode: entity.RoutingModeTCP, entry: "redis", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := traefik.NewUseCase(nil, nil, nil, nil, nil, nil, nil, &config.TraefikConfig{
+				Enabled:        true,
+				Managed:        tt.managed,
+				TCPEntryPoints: []string{"postgres:5432", "redis"},
+				UDPEntryPoints: []string{"game:27015"},
+			})
+
+			if got := uc.HasEntryPoint(tt.mode, tt.entry); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_services_deploy_type;
+
+ALTER TABLE services
+    DROP COLUMN IF EXISTS auto_update_interval,
+    DROP COLUMN IF EXISTS auto_update,
+    DROP COLUMN IF EXISTS image_checked_at,
+    DROP COLUMN IF EXISTS image_update_available,
+    DROP COLUMN IF EXISTS latest_image_digest,
+    DROP COLUMN IF EXISTS image_digest;
+
+ALTER TABLE deployments DROP COLUMN IF EXISTS image_digest;
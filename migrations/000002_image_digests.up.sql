@@ -0,0 +1,13 @@
+-- Resolved image digests per deployment
+ALTER TABLE deployments ADD COLUMN image_digest VARCHAR(255);
+
+-- Image update tracking for image services
+ALTER TABLE services
+    ADD COLUMN image_digest VARCHAR(255),
+    ADD COLUMN latest_image_digest VARCHAR(255),
+    ADD COLUMN image_update_available BOOLEAN DEFAULT false,
+    ADD COLUMN image_checked_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN auto_update BOOLEAN DEFAULT false,
+    ADD COLUMN auto_update_interval INTEGER DEFAULT 3600;
+
+CREATE INDEX idx_services_deploy_type ON services(deploy_type);
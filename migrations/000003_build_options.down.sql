@@ -0,0 +1,3 @@
+ALTER TABLE services
+    DROP COLUMN IF EXISTS build_target,
+    DROP COLUMN IF EXISTS build_args_encrypted;
@@ -0,0 +1,4 @@
+-- Build configuration for dockerfile and auto services
+ALTER TABLE services
+    ADD COLUMN build_args_encrypted BYTEA,
+    ADD COLUMN build_target VARCHAR(100);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_deployments_scheduled_for;
+
+ALTER TABLE deployments
+    DROP COLUMN IF EXISTS no_cache,
+    DROP COLUMN IF EXISTS scheduled_for;
+
+ALTER TABLE projects DROP COLUMN IF EXISTS maintenance_window;
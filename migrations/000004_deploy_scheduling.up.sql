@@ -0,0 +1,9 @@
+-- Per-project maintenance windows for deployments
+ALTER TABLE projects ADD COLUMN maintenance_window JSONB;
+
+-- Deployments held back until a scheduled time
+ALTER TABLE deployments
+    ADD COLUMN scheduled_for TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN no_cache BOOLEAN DEFAULT false;
+
+CREATE INDEX idx_deployments_scheduled_for ON deployments(scheduled_for) WHERE status = 'pending';
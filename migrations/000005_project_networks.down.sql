@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_networks_project_default;
+DROP INDEX IF EXISTS idx_networks_project_name;
@@ -0,0 +1,5 @@
+-- Network names are unique within a project
+CREATE UNIQUE INDEX idx_networks_project_name ON networks(project_id, name);
+
+-- At most one default network per project
+CREATE UNIQUE INDEX idx_networks_project_default ON networks(project_id) WHERE is_default;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_port_mappings_service_port;
+
+ALTER TABLE domains DROP COLUMN IF EXISTS port;
@@ -0,0 +1,5 @@
+-- Container port each domain routes to; NULL uses the service default
+ALTER TABLE domains ADD COLUMN port INTEGER;
+
+-- A container port is mapped at most once per protocol
+CREATE UNIQUE INDEX idx_port_mappings_service_port ON port_mappings(service_id, container_port, protocol);
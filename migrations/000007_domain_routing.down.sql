@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_domains_domain_path;
+
+ALTER TABLE domains
+    DROP COLUMN IF EXISTS rate_limit,
+    DROP COLUMN IF EXISTS response_headers,
+    DROP COLUMN IF EXISTS ip_allow_list,
+    DROP COLUMN IF EXISTS basic_auth_users,
+    DROP COLUMN IF EXISTS www_redirect,
+    DROP COLUMN IF EXISTS strip_prefix,
+    DROP COLUMN IF EXISTS path_prefix;
+
+ALTER TABLE domains ADD CONSTRAINT domains_domain_key UNIQUE (domain);
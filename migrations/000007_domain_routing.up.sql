@@ -0,0 +1,13 @@
+-- Several routes may share a host as long as their paths differ
+ALTER TABLE domains DROP CONSTRAINT IF EXISTS domains_domain_key;
+
+ALTER TABLE domains
+    ADD COLUMN path_prefix VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN strip_prefix BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN www_redirect BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN basic_auth_users TEXT[],
+    ADD COLUMN ip_allow_list TEXT[],
+    ADD COLUMN response_headers JSONB,
+    ADD COLUMN rate_limit JSONB;
+
+CREATE UNIQUE INDEX idx_domains_domain_path ON domains(domain, path_prefix);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_domains_certificate;
+
+ALTER TABLE domains DROP COLUMN IF EXISTS certificate_id;
+
+DROP TABLE IF EXISTS certificates;
@@ -0,0 +1,22 @@
+-- Uploaded TLS certificates, shared by the domains of a team
+CREATE TABLE certificates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    certificate_pem TEXT NOT NULL,
+    private_key_encrypted BYTEA NOT NULL,
+    dns_names TEXT[] NOT NULL,
+    subject VARCHAR(255) NOT NULL DEFAULT '',
+    issuer VARCHAR(255) NOT NULL DEFAULT '',
+    not_before TIMESTAMP WITH TIME ZONE NOT NULL,
+    not_after TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_certificates_team_not_after ON certificates(team_id, not_after);
+
+-- A certificate cannot be deleted while a domain uses it
+ALTER TABLE domains ADD COLUMN certificate_id UUID REFERENCES certificates(id) ON DELETE RESTRICT;
+
+CREATE INDEX idx_domains_certificate ON domains(certificate_id);
@@ -0,0 +1,10 @@
+DELETE FROM domains WHERE verification_status <> 'verified';
+
+DROP INDEX IF EXISTS idx_domains_domain;
+DROP INDEX IF EXISTS idx_domains_domain_path;
+CREATE UNIQUE INDEX idx_domains_domain_path ON domains(domain, path_prefix);
+
+ALTER TABLE domains
+    DROP COLUMN IF EXISTS verified_at,
+    DROP COLUMN IF EXISTS verification_token,
+    DROP COLUMN IF EXISTS verification_status;
@@ -0,0 +1,13 @@
+-- Domains may have to prove ownership through a DNS TXT record before they
+-- are routed; existing domains keep working as verified
+ALTER TABLE domains
+    ADD COLUMN verification_status VARCHAR(20) NOT NULL DEFAULT 'verified',
+    ADD COLUMN verification_token VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN verified_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE domains SET verified_at = created_at;
+
+-- Pending claims must not lock the real owner out of a host
+DROP INDEX IF EXISTS idx_domains_domain_path;
+CREATE UNIQUE INDEX idx_domains_domain_path ON domains(domain, path_prefix) WHERE verification_status = 'verified';
+CREATE INDEX idx_domains_domain ON domains(domain);
@@ -0,0 +1,10 @@
+DELETE FROM domains WHERE routing_mode <> 'http';
+
+DROP INDEX IF EXISTS idx_domains_entry_point;
+DROP INDEX IF EXISTS idx_domains_domain_path;
+CREATE UNIQUE INDEX idx_domains_domain_path ON domains(domain, path_prefix) WHERE verification_status = 'verified';
+
+ALTER TABLE domains
+    DROP COLUMN IF EXISTS tls_passthrough,
+    DROP COLUMN IF EXISTS entry_point,
+    DROP COLUMN IF EXISTS routing_mode;
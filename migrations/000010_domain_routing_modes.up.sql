@@ -0,0 +1,11 @@
+-- Domains may route raw TCP (by SNI) or UDP through a Traefik entrypoint
+ALTER TABLE domains
+    ADD COLUMN routing_mode VARCHAR(10) NOT NULL DEFAULT 'http',
+    ADD COLUMN entry_point VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN tls_passthrough BOOLEAN NOT NULL DEFAULT false;
+
+-- The same host may be served over HTTP and on TCP/UDP entrypoints
+DROP INDEX IF EXISTS idx_domains_domain_path;
+CREATE UNIQUE INDEX idx_domains_domain_path ON domains(domain, path_prefix, routing_mode, entry_point)
+    WHERE verification_status = 'verified';
+CREATE INDEX idx_domains_entry_point ON domains(entry_point) WHERE entry_point <> '';
@@ -0,0 +1,3 @@
+-- Variables a service exports to the other services of its project, which
+-- reference them as ${{service.VAR}} in their env vars
+ALTER TABLE services ADD COLUMN exports_encrypted BYTEA;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS service_env_groups;
+DROP TABLE IF EXISTS env_groups;
@@ -0,0 +1,23 @@
+-- Shared env vars, owned by a team or by one of its projects
+CREATE TABLE env_groups (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    project_id UUID REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    vars_encrypted BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Names are unique among the team's groups and among each project's groups
+CREATE UNIQUE INDEX idx_env_groups_team_name ON env_groups(team_id, name) WHERE project_id IS NULL;
+CREATE UNIQUE INDEX idx_env_groups_project_name ON env_groups(project_id, name) WHERE project_id IS NOT NULL;
+
+CREATE TABLE service_env_groups (
+    service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    env_group_id UUID NOT NULL REFERENCES env_groups(id),
+    attached_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (service_id, env_group_id)
+);
+
+CREATE INDEX idx_service_env_groups_group ON service_env_groups(env_group_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS service_env_var_history;
+DROP TABLE IF EXISTS service_env_vars;
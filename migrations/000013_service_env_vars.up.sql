@@ -0,0 +1,25 @@
+-- One row per service env var, so secrets can be masked and changed key by key.
+-- services.env_vars_encrypted is imported into this table at startup.
+CREATE TABLE service_env_vars (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    key VARCHAR(255) NOT NULL,
+    value_encrypted BYTEA NOT NULL,
+    is_secret BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (service_id, key)
+);
+
+-- Who changed or revealed which key, and when. Values are never recorded.
+CREATE TABLE service_env_var_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    key VARCHAR(255) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    is_secret BOOLEAN NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_service_env_var_history_service ON service_env_var_history(service_id, created_at DESC);
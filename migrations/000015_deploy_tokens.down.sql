@@ -0,0 +1,3 @@
+ALTER TABLE deployments DROP COLUMN IF EXISTS triggered_by_token;
+
+DROP TABLE IF EXISTS deploy_tokens;
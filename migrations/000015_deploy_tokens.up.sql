@@ -0,0 +1,19 @@
+-- Deploy tokens belong to a project rather than a person, so CI keeps
+-- working when whoever created them leaves. Only a hash of each token is stored.
+CREATE TABLE deploy_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(255) NOT NULL UNIQUE,
+    token_prefix VARCHAR(20) NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_deploy_tokens_project ON deploy_tokens(project_id);
+
+ALTER TABLE deployments
+    ADD COLUMN triggered_by_token UUID REFERENCES deploy_tokens(id) ON DELETE SET NULL;
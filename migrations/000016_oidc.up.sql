@@ -0,0 +1,17 @@
+-- Single sign-on identities. Users created by single sign-on have an empty
+-- password hash, which never matches a password.
+ALTER TABLE users
+    ADD COLUMN oidc_issuer VARCHAR(500),
+    ADD COLUMN oidc_subject VARCHAR(255);
+
+CREATE UNIQUE INDEX idx_users_oidc_subject ON users(oidc_issuer, oidc_subject)
+    WHERE oidc_subject IS NOT NULL;
+
+-- Sign-in attempts waiting for the identity provider to redirect back
+CREATE TABLE oidc_states (
+    state VARCHAR(64) PRIMARY KEY,
+    nonce VARCHAR(64) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_refresh_tokens_hash;
+DROP INDEX IF EXISTS idx_refresh_tokens_session;
+
+-- Used refresh tokens were only kept to detect reuse
+DELETE FROM refresh_tokens WHERE used_at IS NOT NULL;
+
+ALTER TABLE refresh_tokens
+    DROP COLUMN IF EXISTS used_at,
+    DROP COLUMN IF EXISTS session_id;
+
+DROP TABLE IF EXISTS sessions;
@@ -0,0 +1,30 @@
+-- Sessions are the token families of logins. Each refresh marks the
+-- presented refresh token used and adds the next one to its session;
+-- presenting a used token again ends the session.
+CREATE TABLE sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_agent VARCHAR(500) NOT NULL DEFAULT '',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_used_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_sessions_user ON sessions(user_id);
+CREATE INDEX idx_sessions_expires ON sessions(expires_at);
+
+-- Existing refresh tokens each become a session of their own
+INSERT INTO sessions (id, user_id, expires_at, last_used_at, created_at)
+SELECT id, user_id, expires_at, created_at, created_at FROM refresh_tokens;
+
+ALTER TABLE refresh_tokens
+    ADD COLUMN session_id UUID REFERENCES sessions(id) ON DELETE CASCADE,
+    ADD COLUMN used_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE refresh_tokens SET session_id = id;
+
+ALTER TABLE refresh_tokens ALTER COLUMN session_id SET NOT NULL;
+
+CREATE INDEX idx_refresh_tokens_session ON refresh_tokens(session_id);
+CREATE INDEX idx_refresh_tokens_hash ON refresh_tokens(token_hash);
@@ -0,0 +1,254 @@
+package buildpack
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrUnsupportedProject = errors.New("could not detect project language")
+)
+
+type Language string
+
+const (
+	LanguageGo     Language = "go"
+	LanguageNode   Language = "node"
+	LanguagePython Language = "python"
+	LanguageStatic Language = "static"
+)
+
+// Plan describes how a detected project is built and run
+type Plan struct {
+	Language   Language
+	Port       int
+	Dockerfile string
+}
+
+// Detect inspects a source directory and generates a Dockerfile for it.
+// Checks run from most to least specific, so a Node project that ships an
+// index.html is still built as Node.
+func Detect(dir string) (*Plan, error) {
+	switch {
+	case fileExists(dir, "go.mod"):
+		return planGo(dir)
+	case fileExists(dir, "package.json"):
+		return planNode(dir)
+	case fileExists(dir, "requirements.txt"):
+		return planPython(dir)
+	case fileExists(dir, "index.html"):
+		return planStatic(), nil
+	default:
+		return nil, ErrUnsupportedProject
+	}
+}
+
+func planGo(dir string) (*Plan, error) {
+	version := "1.24"
+	if v := goVersion(filepath.Join(dir, "go.mod")); v != "" {
+		version = v
+	}
+
+	// Build the module root when it has a main package, otherwise the
+	// first command under cmd/
+	pkg := "."
+	if !fileExists(dir, "main.go") {
+		if entries, err := os.ReadDir(filepath.Join(dir, "cmd")); err == nil {
+			for _, e := range entries {
+				if e.IsDir() && fileExists(filepath.Join(dir, "cmd", e.Name()), "main.go") {
+					pkg = "./cmd/" + e.Name()
+					break
+				}
+			}
+		}
+	}
+
+	dockerfile := fmt.Sprintf(`FROM golang:%s-alpine AS builder
+WORKDIR /src
+COPY go.mod go.sum* ./
+RUN go mod download
+COPY . .
+RUN CGO_ENABLED=0 go build -ldflags="-s -w" -o /out/app %s
+
+FROM alpine:3.20
+RUN apk add --no-cache ca-certificates tzdata
+WORKDIR /app
+COPY --from=builder /out/app /app/app
+ENV PORT=8080
+EXPOSE 8080
+CMD ["/app/app"]
+`, version, pkg)
+
+	return &Plan{Language: LanguageGo, Port: 8080, Dockerfile: dockerfile}, nil
+}
+
+type packageJSON struct {
+	Main    string            `json:"main"`
+	Scripts map[string]string `json:"scripts"`
+	Engines struct {
+		Node string `json:"node"`
+	} `json:"engines"`
+}
+
+func planNode(dir string) (*Plan, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var pkg packageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("invalid package.json: %w", err)
+	}
+
+	version := "20"
+	if v := nodeMajor(pkg.Engines.Node); v != "" {
+		version = v
+	}
+
+	var install, run string
+	switch {
+	case fileExists(dir, "pnpm-lock.yaml"):
+		install = "corepack enable && pnpm install --frozen-lockfile"
+		run = "pnpm"
+	case fileExists(dir, "yarn.lock"):
+		install = "yarn install --frozen-lockfile"
+		run = "yarn"
+	case fileExists(dir, "package-lock.json"):
+		install = "npm ci"
+		run = "npm run"
+	default:
+		install = "npm install"
+		run = "npm run"
+	}
+
+	build := ""
+	if _, ok := pkg.Scripts["build"]; ok {
+		build = fmt.Sprintf("RUN %s build\n", run)
+	}
+
+	var cmd string
+	switch {
+	case procfileWeb(dir) != "":
+		cmd = shellCmd(procfileWeb(dir))
+	case pkg.Scripts["start"] != "":
+		cmd = shellCmd(run + " start")
+	case pkg.Main != "":
+		cmd = fmt.Sprintf(`["node", %q]`, pkg.Main)
+	default:
+		cmd = `["node", "index.js"]`
+	}
+
+	dockerfile := fmt.Sprintf(`FROM node:%s-alpine
+WORKDIR /app
+COPY . .
+RUN %s
+%sENV NODE_ENV=production
+ENV PORT=3000
+EXPOSE 3000
+CMD %s
+`, version, install, build, cmd)
+
+	return &Plan{Language: LanguageNode, Port: 3000, Dockerfile: dockerfile}, nil
+}
+
+func planPython(dir string) (*Plan, error) {
+	var cmd string
+	switch {
+	case procfileWeb(dir) != "":
+		cmd = shellCmd(procfileWeb(dir))
+	case fileExists(dir, "manage.py"):
+		cmd = `["python", "manage.py", "runserver", "0.0.0.0:8000"]`
+	case fileExists(dir, "main.py"):
+		cmd = `["python", "main.py"]`
+	case fileExists(dir, "app.py"):
+		cmd = `["python", "app.py"]`
+	default:
+		return nil, fmt.Errorf("%w: python project needs a Procfile, main.py or app.py", ErrUnsupportedProject)
+	}
+
+	dockerfile := fmt.Sprintf(`FROM python:3.12-slim
+WORKDIR /app
+ENV PYTHONDONTWRITEBYTECODE=1 PYTHONUNBUFFERED=1
+COPY requirements.txt .
+RUN pip install --no-cache-dir -r requirements.txt
+COPY . .
+ENV PORT=8000
+EXPOSE 8000
+CMD %s
+`, cmd)
+
+	return &Plan{Language: LanguagePython, Port: 8000, Dockerfile: dockerfile}, nil
+}
+
+func planStatic() *Plan {
+	dockerfile := `FROM nginx:alpine
+COPY . /usr/share/nginx/html
+EXPOSE 80
+`
+	return &Plan{Language: LanguageStatic, Port: 80, Dockerfile: dockerfile}
+}
+
+// goVersion reads the major.minor toolchain version from a go.mod file
+func goVersion(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "go" {
+			parts := strings.SplitN(fields[1], ".", 3)
+			if len(parts) >= 2 {
+				return parts[0] + "." + parts[1]
+			}
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// nodeMajor extracts the major version from an engines.node range such as ">=18" or "18.x"
+func nodeMajor(constraint string) string {
+	v := strings.TrimLeft(constraint, "^~>=v ")
+	if i := strings.IndexAny(v, ". |<"); i >= 0 {
+		v = v[:i]
+	}
+	for _, r := range v {
+		if r < '0' || r > '9' {
+			return ""
+		}
+	}
+	return v
+}
+
+// procfileWeb returns the command of the "web" process in a Procfile, if any
+func procfileWeb(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "Procfile"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if name, cmd, ok := strings.Cut(line, ":"); ok && strings.TrimSpace(name) == "web" {
+			return strings.TrimSpace(cmd)
+		}
+	}
+	return ""
+}
+
+func shellCmd(cmd string) string {
+	return fmt.Sprintf(`["sh", "-c", %q]`, cmd)
+}
+
+func fileExists(dir, name string) bool {
+	info, err := os.Stat(filepath.Join(dir, name))
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,134 @@
+package buildpack_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/podoru/spinner-podoru/pkg/buildpack"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestDetect_Go(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"go.mod":             "module example.com/app\n\ngo 1.22.3\n",
+		"cmd/server/main.go": "package main\n",
+	})
+
+	plan, err := buildpack.Detect(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if plan.Language != buildpack.LanguageGo {
+		t.Errorf("expected language %s, got %s", buildpack.LanguageGo, plan.Language)
+	}
+	if !strings.Contains(plan.Dockerfile, "FROM golang:1.22-alpine") {
+		t.Errorf("expected go version from go.mod, got:\n%s", plan.Dockerfile)
+	}
+	if !strings.Contains(plan.Dockerfile, "./cmd/server") {
+		t.Errorf("expected cmd/server to be built, got:\n%s", plan.Dockerfile)
+	}
+	if plan.Port != 8080 {
+		t.Errorf("expected port 8080, got %d", plan.Port)
+	}
+}
+
+func TestDetect_Node(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"package.json":      `{"scripts": {"build": "tsc", "start": "node dist/index.js"}, "engines": {"node": ">=18.0.0"}}`,
+		"package-lock.json": "{}",
+		"index.html":        "<html></html>",
+	})
+
+	plan, err := buildpack.Detect(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if plan.Language != buildpack.LanguageNode {
+		t.Errorf("expected language %s, got %s", buildpack.LanguageNode, plan.Language)
+	}
+
+	expected := []string{"FROM node:18-alpine", "RUN npm ci", "RUN npm run build", `"npm run start"`}
+	for _, e := range expected {
+		if !strings.Contains(plan.Dockerfile, e) {
+			t.Errorf("expected Dockerfile to contain %q, got:\n%s", e, plan.Dockerfile)
+		}
+	}
+}
+
+func TestDetect_PythonProcfile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"requirements.txt": "flask\ngunicorn\n",
+		"Procfile":         "web: gunicorn app:app --bind 0.0.0.0:$PORT\nworker: python worker.py\n",
+	})
+
+	plan, err := buildpack.Detect(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if plan.Language != buildpack.LanguagePython {
+		t.Errorf("expected language %s, got %s", buildpack.LanguagePython, plan.Language)
+	}
+	if !strings.Contains(plan.Dockerfile, "gunicorn app:app") {
+		t.Errorf("expected Procfile web command, got:\n%s", plan.Dockerfile)
+	}
+}
+
+func TestDetect_PythonWithoutEntrypoint(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"requirements.txt": "requests\n",
+	})
+
+	_, err := buildpack.Detect(dir)
+	if !errors.Is(err, buildpack.ErrUnsupportedProject) {
+		t.Errorf("expected ErrUnsupportedProject, got %v", err)
+	}
+}
+
+func TestDetect_Static(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"index.html": "<html></html>",
+	})
+
+	plan, err := buildpack.Detect(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if plan.Language != buildpack.LanguageStatic {
+		t.Errorf("expected language %s, got %s", buildpack.LanguageStatic, plan.Language)
+	}
+	if plan.Port != 80 {
+		t.Errorf("expected port 80, got %d", plan.Port)
+	}
+}
+
+func TestDetect_Unsupported(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"README.md": "# nothing to build",
+	})
+
+	_, err := buildpack.Detect(dir)
+	if !errors.Is(err, buildpack.ErrUnsupportedProject) {
+		t.Errorf("expected ErrUnsupportedProject, got %v", err)
+	}
+}
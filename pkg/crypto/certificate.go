@@ -0,0 +1,94 @@
+package crypto
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidCertificate     = errors.New("invalid certificate")
+	ErrInvalidPrivateKey      = errors.New("invalid private key")
+	ErrKeyMismatch            = errors.New("private key does not match certificate")
+	ErrBrokenChain            = errors.New("certificate chain is out of order or incomplete")
+	ErrCertificateExpired     = errors.New("certificate has expired")
+	ErrCertificateNotYetValid = errors.New("certificate is not valid yet")
+)
+
+// CertificateInfo describes the leaf of a validated PEM certificate chain
+type CertificateInfo struct {
+	DNSNames  []string
+	Subject   string
+	Issuer    string
+	NotBefore time.Time
+	NotAfter  time.Time
+}
+
+// ParseCertificate validates a PEM bundle, leaf first and followed by its
+// intermediates, against its private key. Every certificate must be signed
+// by the next one and be valid at now. The root is not required, so
+// certificates from private CAs are accepted.
+func ParseCertificate(certPEM, keyPEM string, now time.Time) (*CertificateInfo, error) {
+	var chain []*x509.Certificate
+	rest := []byte(certPEM)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("%w: unexpected %s block", ErrInvalidCertificate, block.Type)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCertificate, err)
+		}
+		chain = append(chain, cert)
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("%w: no PEM certificate found", ErrInvalidCertificate)
+	}
+
+	if block, _ := pem.Decode([]byte(keyPEM)); block == nil || !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+		return nil, fmt.Errorf("%w: no PEM private key found", ErrInvalidPrivateKey)
+	}
+	if _, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM)); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyMismatch, err)
+	}
+
+	for i, cert := range chain {
+		if now.After(cert.NotAfter) {
+			return nil, fmt.Errorf("%w: %s expired on %s", ErrCertificateExpired, cert.Subject.CommonName, cert.NotAfter.Format(time.DateOnly))
+		}
+		if now.Before(cert.NotBefore) {
+			return nil, fmt.Errorf("%w: %s is valid from %s", ErrCertificateNotYetValid, cert.Subject.CommonName, cert.NotBefore.Format(time.DateOnly))
+		}
+		if i+1 < len(chain) {
+			if err := cert.CheckSignatureFrom(chain[i+1]); err != nil {
+				return nil, fmt.Errorf("%w: %s is not signed by %s", ErrBrokenChain, cert.Subject.CommonName, chain[i+1].Subject.CommonName)
+			}
+		}
+	}
+
+	leaf := chain[0]
+	if len(leaf.DNSNames) == 0 {
+		return nil, fmt.Errorf("%w: no DNS subject alternative names", ErrInvalidCertificate)
+	}
+	names := make([]string, len(leaf.DNSNames))
+	for i, name := range leaf.DNSNames {
+		names[i] = strings.ToLower(name)
+	}
+
+	return &CertificateInfo{
+		DNSNames:  names,
+		Subject:   leaf.Subject.CommonName,
+		Issuer:    leaf.Issuer.CommonName,
+		NotBefore: leaf.NotBefore,
+		NotAfter:  leaf.NotAfter,
+	}, nil
+}
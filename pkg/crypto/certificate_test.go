@@ -0,0 +1,127 @@
+package crypto_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/podoru/spinner-podoru/pkg/crypto"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  string
+}
+
+func issue(t *testing.T, name string, dnsNames []string, notAfter time.Time, parent *testCert, isCA bool) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	if isCA {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+
+	return &testCert{
+		cert: cert,
+		key:  key,
+		pem:  string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+	}
+}
+
+func keyPEM(t *testing.T, key *ecdsa.PrivateKey) string {
+	t.Helper()
+
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+}
+
+func TestParseCertificate(t *testing.T) {
+	now := time.Now()
+	year := now.Add(365 * 24 * time.Hour)
+
+	ca := issue(t, "Corp Root", nil, year, nil, true)
+	intermediate := issue(t, "Corp Intermediate", nil, year, ca, true)
+	leaf := issue(t, "example.com", []string{"example.com", "*.Example.com"}, now.Add(30*24*time.Hour), intermediate, false)
+	other := issue(t, "other.com", []string{"other.com"}, year, ca, false)
+	noSAN := issue(t, "legacy.com", nil, year, ca, false)
+
+	tests := []struct {
+		name    string
+		cert    string
+		key     string
+		now     time.Time
+		wantErr error
+	}{
+		{"leaf with intermediate", leaf.pem + intermediate.pem, keyPEM(t, leaf.key), now, nil},
+		{"leaf only", leaf.pem, keyPEM(t, leaf.key), now, nil},
+		{"chain out of order", leaf.pem + ca.pem, keyPEM(t, leaf.key), now, crypto.ErrBrokenChain},
+		{"key of another certificate", leaf.pem, keyPEM(t, other.key), now, crypto.ErrKeyMismatch},
+		{"missing key", leaf.pem, "", now, crypto.ErrInvalidPrivateKey},
+		{"not a certificate", "hello", keyPEM(t, leaf.key), now, crypto.ErrInvalidCertificate},
+		{"no dns names", noSAN.pem, keyPEM(t, noSAN.key), now, crypto.ErrInvalidCertificate},
+		{"expired", leaf.pem, keyPEM(t, leaf.key), now.Add(60 * 24 * time.Hour), crypto.ErrCertificateExpired},
+		{"not yet valid", leaf.pem, keyPEM(t, leaf.key), now.Add(-24 * time.Hour), crypto.ErrCertificateNotYetValid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := crypto.ParseCertificate(tt.cert, tt.key, tt.now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if info.Subject != "example.com" || info.Issuer != "Corp Intermediate" {
+				t.Errorf("unexpected subject %q or issuer %q", info.Subject, info.Issuer)
+			}
+			if len(info.DNSNames) != 2 || info.DNSNames[1] != "*.example.com" {
+				t.Errorf("expected lowercased dns names, got %v", info.DNSNames)
+			}
+			if !info.NotAfter.Equal(leaf.cert.NotAfter) {
+				t.Errorf("expected not after %s, got %s", leaf.cert.NotAfter, info.NotAfter)
+			}
+		})
+	}
+}
@@ -1,11 +1,14 @@
 package crypto
 
 import (
+	"bytes"
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"io"
 
@@ -17,22 +20,82 @@ var (
 	ErrInvalidCiphertext = errors.New("invalid ciphertext")
 )
 
-type Encryptor struct {
+// envelopeVersion is the first byte of ciphertext sealed with a key ID.
+// Ciphertext from before envelopes is just nonce and sealed data.
+const envelopeVersion byte = 1
+
+// keyIDSize is the length of the key ID following the version byte
+const keyIDSize = 4
+
+// referencePurpose is authenticated with every "${{encrypted:...}}" reference
+var referencePurpose = []byte("podoru:env-ref")
+
+type encryptionKey struct {
+	id  []byte
 	key []byte
 }
 
-func NewEncryptor(key string) (*Encryptor, error) {
+// Encryptor seals data with its current key and opens data sealed with the
+// current key or any of its previous keys, so keys can be rotated.
+type Encryptor struct {
+	current  encryptionKey
+	previous []encryptionKey
+}
+
+func NewEncryptor(key string, previousKeys ...string) (*Encryptor, error) {
+	e := &Encryptor{current: deriveKey(key)}
+	for _, k := range previousKeys {
+		if k == "" || k == key {
+			continue
+		}
+		e.previous = append(e.previous, deriveKey(k))
+	}
+	return e, nil
+}
+
+// deriveKey turns a configured key into an AES-256 key. Its ID is a hash of
+// the derived key, so it identifies the key without revealing it.
+func deriveKey(key string) encryptionKey {
 	hash := sha256.Sum256([]byte(key))
-	return &Encryptor{key: hash[:]}, nil
+	id := sha256.Sum256(hash[:])
+	return encryptionKey{id: id[:keyIDSize], key: hash[:]}
 }
 
-func (e *Encryptor) Encrypt(plaintext []byte) ([]byte, error) {
-	block, err := aes.NewCipher(e.key)
+// KeyID returns the hex ID of the current key
+func (e *Encryptor) KeyID() string {
+	return hex.EncodeToString(e.current.id)
+}
+
+// IsCurrent reports whether ciphertext is sealed with the current key in a
+// versioned envelope, so rotating it would not change anything.
+func (e *Encryptor) IsCurrent(ciphertext []byte) bool {
+	return len(ciphertext) > 1+keyIDSize && ciphertext[0] == envelopeVersion &&
+		bytes.Equal(ciphertext[1:1+keyIDSize], e.current.id)
+}
+
+// Reencrypt opens ciphertext with whichever key sealed it and seals it again
+// with the current key.
+func (e *Encryptor) Reencrypt(ciphertext []byte) ([]byte, error) {
+	plaintext, err := e.Decrypt(ciphertext)
 	if err != nil {
 		return nil, err
 	}
+	return e.Encrypt(plaintext)
+}
+
+func (e *Encryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	return e.seal(plaintext, nil)
+}
+
+func (e *Encryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	return e.unseal(ciphertext, nil)
+}
 
-	gcm, err := cipher.NewGCM(block)
+// seal encrypts plaintext with the current key in a versioned envelope. The
+// envelope header and purpose are authenticated, so the ciphertext only opens
+// for the same purpose.
+func (e *Encryptor) seal(plaintext, purpose []byte) ([]byte, error) {
+	gcm, err := newGCM(e.current.key)
 	if err != nil {
 		return nil, err
 	}
@@ -42,17 +105,64 @@ func (e *Encryptor) Encrypt(plaintext []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	ciphertext := gcm.Seal(nonce, nonce, plaintext, nil)
-	return ciphertext, nil
+	header := make([]byte, 0, 1+keyIDSize)
+	header = append(header, envelopeVersion)
+	header = append(header, e.current.id...)
+
+	ciphertext := make([]byte, 0, len(header)+len(nonce)+len(plaintext)+gcm.Overhead())
+	ciphertext = append(ciphertext, header...)
+	ciphertext = append(ciphertext, nonce...)
+	return gcm.Seal(ciphertext, nonce, plaintext, additionalData(header, purpose)), nil
 }
 
-func (e *Encryptor) Decrypt(ciphertext []byte) ([]byte, error) {
-	block, err := aes.NewCipher(e.key)
+func (e *Encryptor) unseal(ciphertext, purpose []byte) ([]byte, error) {
+	if len(ciphertext) > 1+keyIDSize && ciphertext[0] == envelopeVersion {
+		header := ciphertext[:1+keyIDSize]
+		for _, k := range e.keys() {
+			if bytes.Equal(ciphertext[1:1+keyIDSize], k.id) {
+				return open(k.key, ciphertext[1+keyIDSize:], additionalData(header, purpose))
+			}
+		}
+	}
+	if purpose != nil {
+		return nil, ErrInvalidCiphertext
+	}
+
+	// Ciphertext without an envelope does not say which key sealed it
+	var firstErr error
+	for _, k := range e.keys() {
+		plaintext, err := open(k.key, ciphertext, nil)
+		if err == nil {
+			return plaintext, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+func additionalData(header, purpose []byte) []byte {
+	if purpose == nil {
+		return header
+	}
+	return append(append([]byte{}, header...), purpose...)
+}
+
+func (e *Encryptor) keys() []encryptionKey {
+	return append([]encryptionKey{e.current}, e.previous...)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
+	return cipher.NewGCM(block)
+}
 
-	gcm, err := cipher.NewGCM(block)
+func open(key, ciphertext, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
 	if err != nil {
 		return nil, err
 	}
@@ -63,7 +173,7 @@ func (e *Encryptor) Decrypt(ciphertext []byte) ([]byte, error) {
 	}
 
 	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, additionalData)
 	if err != nil {
 		return nil, err
 	}
@@ -91,6 +201,36 @@ func (e *Encryptor) DecryptString(ciphertext string) (string, error) {
 	return string(decrypted), nil
 }
 
+// EncryptReference seals plaintext into the ref of an "${{encrypted:...}}"
+// env value. References are bound to their purpose, so neither do they open
+// as other ciphertext nor does other stored ciphertext resolve as one.
+func (e *Encryptor) EncryptReference(plaintext string) (string, error) {
+	sealed, err := e.seal([]byte(plaintext), referencePurpose)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Scheme makes the Encryptor a secret provider for "${{encrypted:...}}" env values,
+// which hold the output of EncryptReference
+func (e *Encryptor) Scheme() string {
+	return "encrypted"
+}
+
+// Resolve decrypts a value sealed by EncryptReference
+func (e *Encryptor) Resolve(ctx context.Context, ref string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(ref)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := e.unseal(data, referencePurpose)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
 func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -112,6 +252,17 @@ func GenerateRandomString(length int) (string, error) {
 	return base64.URLEncoding.EncodeToString(bytes)[:length], nil
 }
 
+// GenerateToken returns a new access token starting with prefix, followed
+// by 40 random characters, together with the hash to store for it
+func GenerateToken(prefix string) (plaintext, hash string, err error) {
+	random, err := GenerateRandomString(40)
+	if err != nil {
+		return "", "", err
+	}
+	plaintext = prefix + random
+	return plaintext, HashToken(plaintext), nil
+}
+
 func HashToken(token string) string {
 	hash := sha256.Sum256([]byte(token))
 	return base64.StdEncoding.EncodeToString(hash[:])
@@ -1,6 +1,11 @@
 package crypto_test
 
 import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"strings"
 	"testing"
 
 	"github.com/podoru/spinner-podoru/pkg/crypto"
@@ -153,6 +158,23 @@ func TestHashToken(t *testing.T) {
 	}
 }
 
+func TestGenerateToken(t *testing.T) {
+	plaintext, hash, err := crypto.GenerateToken("pod_")
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+
+	if !strings.HasPrefix(plaintext, "pod_") {
+		t.Errorf("expected token to start with pod_, got %q", plaintext)
+	}
+	if len(plaintext) != len("pod_")+40 {
+		t.Errorf("expected 40 random characters, got %d", len(plaintext)-len("pod_"))
+	}
+	if hash != crypto.HashToken(plaintext) {
+		t.Error("hash should be the token's hash")
+	}
+}
+
 func TestDifferentKeys(t *testing.T) {
 	encryptor1, _ := crypto.NewEncryptor("key1")
 	encryptor2, _ := crypto.NewEncryptor("key2")
@@ -169,3 +191,127 @@ func TestDifferentKeys(t *testing.T) {
 		t.Error("decryption with different key should fail")
 	}
 }
+
+func TestDecrypt_PreviousKey(t *testing.T) {
+	old, _ := crypto.NewEncryptor("old-key")
+	rotated, _ := crypto.NewEncryptor("new-key", "old-key")
+
+	ciphertext, err := old.Encrypt([]byte("secret message"))
+	if err != nil {
+		t.Fatalf("encryption failed: %v", err)
+	}
+
+	if rotated.IsCurrent(ciphertext) {
+		t.Error("ciphertext sealed with a previous key should not be current")
+	}
+
+	reencrypted, err := rotated.Reencrypt(ciphertext)
+	if err != nil {
+		t.Fatalf("re-encryption failed: %v", err)
+	}
+	if !rotated.IsCurrent(reencrypted) {
+		t.Error("re-encrypted ciphertext should be current")
+	}
+
+	current, _ := crypto.NewEncryptor("new-key")
+	decrypted, err := current.Decrypt(reencrypted)
+	if err != nil {
+		t.Fatalf("decryption failed: %v", err)
+	}
+	if string(decrypted) != "secret message" {
+		t.Errorf("expected secret message, got %s", decrypted)
+	}
+}
+
+func TestDecrypt_LegacyCiphertext(t *testing.T) {
+	// Ciphertext from before key IDs: nonce followed by sealed data, no header
+	key := sha256.Sum256([]byte("old-key"))
+	block, _ := aes.NewCipher(key[:])
+	gcm, _ := cipher.NewGCM(block)
+	nonce := make([]byte, gcm.NonceSize())
+	legacy := gcm.Seal(nonce, nonce, []byte("github token"), nil)
+
+	tests := []struct {
+		name    string
+		key     string
+		prev    []string
+		wantErr bool
+	}{
+		{name: "current key", key: "old-key"},
+		{name: "previous key", key: "new-key", prev: []string{"old-key"}},
+		{name: "unknown key", key: "new-key", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encryptor, _ := crypto.NewEncryptor(tt.key, tt.prev...)
+
+			decrypted, err := encryptor.Decrypt(legacy)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected decryption to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decryption failed: %v", err)
+			}
+			if string(decrypted) != "github token" {
+				t.Errorf("expected github token, got %s", decrypted)
+			}
+			if encryptor.IsCurrent(legacy) {
+				t.Error("legacy ciphertext should never be current")
+			}
+		})
+	}
+}
+
+func TestEncrypt_TamperedKeyID(t *testing.T) {
+	encryptor, _ := crypto.NewEncryptor("key1", "key2")
+	other, _ := crypto.NewEncryptor("key2")
+
+	ciphertext, err := encryptor.Encrypt([]byte("secret message"))
+	if err != nil {
+		t.Fatalf("encryption failed: %v", err)
+	}
+
+	// Claiming the data was sealed with another known key must not open it
+	otherCiphertext, _ := other.Encrypt(nil)
+	copy(ciphertext[1:5], otherCiphertext[1:5])
+	if _, err := encryptor.Decrypt(ciphertext); err == nil {
+		t.Error("decryption with a swapped key ID should fail")
+	}
+}
+
+func TestEncryptReference(t *testing.T) {
+	ctx := context.Background()
+	old, _ := crypto.NewEncryptor("old-key")
+	encryptor, _ := crypto.NewEncryptor("new-key", "old-key")
+
+	ref, err := encryptor.EncryptReference("s3cret")
+	if err != nil {
+		t.Fatalf("encryption failed: %v", err)
+	}
+	if got, err := encryptor.Resolve(ctx, ref); err != nil || got != "s3cret" {
+		t.Errorf("expected the reference to resolve to s3cret, got %q, %v", got, err)
+	}
+	if _, err := encryptor.DecryptString(ref); err == nil {
+		t.Error("expected a reference not to decrypt as a stored value")
+	}
+
+	oldRef, _ := old.EncryptReference("s3cret")
+	if got, err := encryptor.Resolve(ctx, oldRef); err != nil || got != "s3cret" {
+		t.Errorf("expected a reference sealed with a previous key to resolve, got %q, %v", got, err)
+	}
+}
+
+func TestResolve_StoredCiphertext(t *testing.T) {
+	encryptor, _ := crypto.NewEncryptor("test-secret-key")
+
+	// Stored columns, such as a GitHub token, must not resolve when pasted
+	// into an env var as a reference
+	stored, _ := encryptor.EncryptString("ghp_token")
+	if _, err := encryptor.Resolve(context.Background(), stored); err == nil {
+		t.Error("expected stored ciphertext not to resolve as a reference")
+	}
+}
@@ -0,0 +1,123 @@
+// Package dotenv reads and writes .env files. Values are taken literally:
+// ${VAR} is never expanded, so references survive a round trip.
+package dotenv
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var ErrSyntax = errors.New("invalid dotenv syntax")
+
+// Parse reads KEY=value lines. Blank lines, # comments and an export prefix
+// are ignored. Unquoted values end at a " #" comment. Single quoted values
+// are literal, double quoted values understand \n, \r, \t, \", \\ and \$;
+// both may span lines. A key defined twice keeps its last value.
+func Parse(content string) (map[string]string, error) {
+	vars := make(map[string]string)
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("%w: line %d: expected KEY=value", ErrSyntax, lineNo)
+		}
+		value = strings.TrimLeft(value, " \t")
+
+		if value == "" || (value[0] != '"' && value[0] != '\'') {
+			if idx := strings.Index(value, " #"); idx >= 0 {
+				value = value[:idx]
+			}
+			vars[key] = strings.TrimSpace(value)
+			continue
+		}
+
+		// A quoted value runs until its closing quote, on this line or a later one
+		quote := value[0]
+		raw := value[1:]
+		end := closingQuote(raw, quote)
+		for end < 0 && i+1 < len(lines) {
+			i++
+			raw += "\n" + lines[i]
+			end = closingQuote(raw, quote)
+		}
+		if end < 0 {
+			return nil, fmt.Errorf("%w: line %d: unterminated quoted value of %s", ErrSyntax, lineNo, key)
+		}
+		if rest := strings.TrimSpace(raw[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return nil, fmt.Errorf("%w: line %d: unexpected text after quoted value of %s", ErrSyntax, lineNo, key)
+		}
+
+		raw = raw[:end]
+		if quote == '"' {
+			raw = unescape(raw)
+		}
+		vars[key] = raw
+	}
+	return vars, nil
+}
+
+// closingQuote returns the index of the quote ending s, skipping quotes
+// escaped with a backslash inside double quotes, or -1
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		if quote == '"' && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+var unescaper = strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\\`, `\`, `\$`, `$`)
+
+func unescape(s string) string {
+	return unescaper.Replace(s)
+}
+
+var escaper = strings.NewReplacer("\\", `\\`, "\n", `\n`, "\r", `\r`, "\t", `\t`, `"`, `\"`)
+
+// Format writes vars as a .env file sorted by key. Values that Parse would
+// not read back as they are get quoted.
+func Format(vars map[string]string) string {
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(quote(vars[key]))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func quote(value string) string {
+	if value == "" {
+		return value
+	}
+	if !strings.ContainsAny(value, " \t\r\n#'\"\\") {
+		return value
+	}
+	if !strings.ContainsAny(value, "'\r\n") {
+		return "'" + value + "'"
+	}
+	return `"` + escaper.Replace(value) + `"`
+}
@@ -0,0 +1,88 @@
+package dotenv_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/podoru/spinner-podoru/pkg/dotenv"
+)
+
+func TestParse(t *testing.T) {
+	content := `# Database
+export DATABASE_URL=postgres://app@db:5432/app
+PORT = 3000 # web port
+EMPTY=
+HASH=abc#def
+SINGLE='literal ${HOME} \n'
+DOUBLE="tab\there \"quoted\""
+PRIVATE_KEY="-----BEGIN KEY-----
+line two
+-----END KEY-----"
+MULTI_SINGLE='a
+b' # trailing comment
+REF=${{postgres.HOST}}
+PORT=4000
+`
+
+	want := map[string]string{
+		"DATABASE_URL": "postgres://app@db:5432/app",
+		"PORT":         "4000",
+		"EMPTY":        "",
+		"HASH":         "abc#def",
+		"SINGLE":       `literal ${HOME} \n`,
+		"DOUBLE":       "tab\there \"quoted\"",
+		"PRIVATE_KEY":  "-----BEGIN KEY-----\nline two\n-----END KEY-----",
+		"MULTI_SINGLE": "a\nb",
+		"REF":          "${{postgres.HOST}}",
+	}
+
+	got, err := dotenv.Parse(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "no equals", content: "JUST_A_KEY"},
+		{name: "space in key", content: "MY KEY=value"},
+		{name: "unterminated quote", content: "KEY=\"never closed\nOTHER=1"},
+		{name: "text after quote", content: "KEY='value' extra"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := dotenv.Parse(tt.content); !errors.Is(err, dotenv.ErrSyntax) {
+				t.Errorf("expected ErrSyntax, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFormat_RoundTrip(t *testing.T) {
+	vars := map[string]string{
+		"PLAIN":     "value",
+		"EMPTY":     "",
+		"SPACES":    "hello world",
+		"HASH":      "a #b",
+		"QUOTES":    `it's "quoted"`,
+		"MULTILINE": "line one\nline two",
+		"BACKSLASH": `C:\path`,
+		"DOLLAR":    "${{postgres.HOST}}:$PORT",
+	}
+
+	got, err := dotenv.Parse(dotenv.Format(vars))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, vars) {
+		t.Errorf("expected %v, got %v", vars, got)
+	}
+}
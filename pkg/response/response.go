@@ -40,6 +40,13 @@ func Created(c *gin.Context, data interface{}) {
 	})
 }
 
+func Accepted(c *gin.Context, data interface{}) {
+	c.JSON(http.StatusAccepted, Response{
+		Success: true,
+		Data:    data,
+	})
+}
+
 func NoContent(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
@@ -93,6 +100,10 @@ func Conflict(c *gin.Context, message string) {
 	Error(c, http.StatusConflict, "CONFLICT", message)
 }
 
+func BadGateway(c *gin.Context, message string) {
+	Error(c, http.StatusBadGateway, "BAD_GATEWAY", message)
+}
+
 func ValidationError(c *gin.Context, details map[string]string) {
 	ErrorWithDetails(c, http.StatusBadRequest, "VALIDATION_ERROR", "Validation failed", details)
 }
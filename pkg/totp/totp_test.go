@@ -0,0 +1,98 @@
+package totp_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/podoru/spinner-podoru/pkg/totp"
+)
+
+// rfcSecret is the RFC 6238 test key "12345678901234567890" in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode_RFC6238(t *testing.T) {
+	// The RFC lists 8 digit codes; these are their last 6 digits
+	testCases := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+		{unix: 20000000000, want: "353130"},
+	}
+
+	for _, tc := range testCases {
+		got, err := totp.Code(rfcSecret, totp.Step(time.Unix(tc.unix, 0)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tc.want {
+			t.Errorf("Code at %d = %s, want %s", tc.unix, got, tc.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	step := totp.Step(now)
+
+	code := func(step int64) string {
+		c, err := totp.Code(secret, step)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return c
+	}
+
+	testCases := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current", code: code(step), wantStep: step, wantOK: true},
+		{name: "previous step", code: code(step - 1), wantStep: step - 1, wantOK: true},
+		{name: "next step", code: code(step + 1), wantStep: step + 1, wantOK: true},
+		{name: "spaces", code: code(step)[:3] + " " + code(step)[3:], wantStep: step, wantOK: true},
+		{name: "too old", code: code(step - 2), wantOK: false},
+		{name: "too short", code: "123", wantOK: false},
+		{name: "empty", code: "", wantOK: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotStep, ok := totp.Validate(secret, tc.code, now)
+			if ok != tc.wantOK {
+				t.Fatalf("Validate(%q) ok = %v, want %v", tc.code, ok, tc.wantOK)
+			}
+			if ok && gotStep != tc.wantStep {
+				t.Errorf("Validate(%q) step = %d, want %d", tc.code, gotStep, tc.wantStep)
+			}
+		})
+	}
+
+	if _, ok := totp.Validate("not base32!", "123456", now); ok {
+		t.Error("expected an invalid secret to fail")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := totp.ProvisioningURI("Podoru", "jane@example.com", rfcSecret)
+
+	if !strings.HasPrefix(uri, "otpauth://totp/Podoru:jane@example.com?") {
+		t.Errorf("unexpected label in %s", uri)
+	}
+	for _, param := range []string{"secret=" + rfcSecret, "issuer=Podoru", "digits=6", "period=30", "algorithm=SHA1"} {
+		if !strings.Contains(uri, param) {
+			t.Errorf("expected %s in %s", param, uri)
+		}
+	}
+}